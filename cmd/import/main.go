@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"go-video/ddd/app"
+)
+
+func main() {
+	os.Exit(app.RunImport())
+}
@@ -4,8 +4,12 @@ import (
 	"go-video/ddd/app"
 
 	// 导入模块以触发插件注册
+	_ "go-video/ddd/eventbus"
+	_ "go-video/ddd/export"
+	_ "go-video/ddd/report"
 	_ "go-video/ddd/user"
 	_ "go-video/ddd/video"
+	_ "go-video/ddd/webhook"
 )
 
 func main() {
@@ -18,9 +18,13 @@ import (
 	"go-video/pkg/utils"
 
 	// 导入资源和模块包以触发init函数
+	_ "go-video/ddd/eventbus"
+	_ "go-video/ddd/export"
 	_ "go-video/ddd/internal/resource"
+	_ "go-video/ddd/report"
 	_ "go-video/ddd/user"
 	_ "go-video/ddd/video"
+	_ "go-video/ddd/webhook"
 )
 
 func Run() {
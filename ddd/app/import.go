@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-video/ddd/video/adapter/cli"
+	videoApp "go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// RunImport 从本地目录和清单批量导入已有视频库，返回进程退出码：
+//
+//	go run ./cmd/import -dir /data/archive -manifest /data/archive/manifest.csv
+//
+// 中断后以相同参数重新执行会跳过检查点中已导入的文件，所有者已有相同内容的视频也会被跳过
+func RunImport() int {
+	configPath := flag.String("config", "configs/config.dev.yaml", "配置文件路径")
+	dir := flag.String("dir", "", "视频文件所在目录，清单中的路径相对于该目录")
+	manifest := flag.String("manifest", "", "CSV或JSON清单（path, owner, title, description, tags, created_at）")
+	checkpoint := flag.String("checkpoint", "", "检查点文件，默认为清单路径加 .checkpoint")
+	report := flag.String("report", "", "结果报告（CSV），默认为 import-report-<时间>.csv")
+	concurrency := flag.Int("concurrency", 4, "同时上传的文件数")
+	flag.Parse()
+
+	if *dir == "" || *manifest == "" {
+		flag.Usage()
+		return 2
+	}
+	if *checkpoint == "" {
+		*checkpoint = *manifest + ".checkpoint"
+	}
+	if *report == "" {
+		*report = fmt.Sprintf("import-report-%s.csv", time.Now().Format("20060102-150405"))
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("[ERROR] 加载配置失败: %v\n", err)
+		return 1
+	}
+	config.SetGlobalConfig(cfg)
+	logger.SetGlobalLogger(logger.NewLogger(cfg))
+
+	manager.MustInitResources()
+	defer manager.CloseResources()
+
+	// 收到中断信号后不再开始新的文件，已开始的文件处理完后写出报告
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	importer := cli.NewLibraryImporter(videoApp.DefaultVideoApp(), cli.LibraryImportOptions{
+		Dir:         *dir,
+		Manifest:    *manifest,
+		Checkpoint:  *checkpoint,
+		Report:      *report,
+		Concurrency: *concurrency,
+	})
+	summary, err := importer.Run(ctx)
+	if summary != nil {
+		fmt.Printf("imported: %d, duplicate: %d, checkpointed: %d, failed: %d, pending: %d, unlisted: %d\n",
+			summary.Counts[cli.ResultImported], summary.Counts[cli.ResultDuplicate], summary.Counts[cli.ResultCheckpointed],
+			summary.Counts[cli.ResultFailed], summary.Counts[cli.ResultPending], summary.Counts[cli.ResultUnlisted])
+		fmt.Printf("report: %s\n", *report)
+	}
+	if err != nil {
+		fmt.Printf("[ERROR] 导入失败: %v\n", err)
+		return 1
+	}
+	if summary.Failed() {
+		return 1
+	}
+	return 0
+}
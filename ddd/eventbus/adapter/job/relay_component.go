@@ -0,0 +1,95 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/eventbus/application/app"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultRelayPollInterval 未配置时扫描发件箱的间隔
+const defaultRelayPollInterval = time.Second
+
+// RelayComponentPlugin 领域事件分发组件插件
+type RelayComponentPlugin struct {
+}
+
+func (p *RelayComponentPlugin) Name() string {
+	return "eventRelayComponentPlugin"
+}
+
+func (p *RelayComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var interval time.Duration
+	if deps != nil && deps.Config != nil {
+		interval = deps.Config.EventBus.PollInterval
+	}
+	if interval <= 0 {
+		interval = defaultRelayPollInterval
+	}
+	return &relayComponent{
+		interval: interval,
+		relayApp: app.DefaultRelayApp(),
+	}
+}
+
+// relayComponent 定时把发件箱中到期的事件分发给处理器，多实例部署时通过领取避免重复分发
+type relayComponent struct {
+	interval time.Duration
+	relayApp app.RelayApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *relayComponent) GetName() string {
+	return "eventRelayComponent"
+}
+
+// Start 启动定时分发
+func (c *relayComponent) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx)
+	logger.Info(fmt.Sprintf("event relay component started, interval: %s", c.interval))
+	return nil
+}
+
+// Stop 停止定时分发，等待正在处理的事件退出
+func (c *relayComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *relayComponent) loop(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 一轮领满时说明还有积压，立即继续
+			for {
+				dispatched, err := c.relayApp.DispatchDue(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.Error(fmt.Sprintf("event relay failed after %d events: %v", dispatched, err))
+					}
+					break
+				}
+				if dispatched < app.RelayBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
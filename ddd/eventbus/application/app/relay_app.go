@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/eventbus/domain/entity"
+	"go-video/ddd/eventbus/domain/repo"
+	"go-video/ddd/eventbus/domain/vo"
+	"go-video/ddd/eventbus/infrastructure/database/persistence"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/event"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultEventMaxAttempts 未配置时标记为failed前的最多分发次数
+	defaultEventMaxAttempts = 10
+	// defaultEventRetryBaseDelay 未配置时第一次失败后的重试间隔
+	defaultEventRetryBaseDelay = 5 * time.Second
+	// defaultEventRetryMaxDelay 未配置时重试间隔的上限
+	defaultEventRetryMaxDelay = 10 * time.Minute
+	// defaultEventHandlerTimeout 未配置时单个处理器处理一个事件的超时
+	defaultEventHandlerTimeout = 30 * time.Second
+	// RelayBatchSize 每轮最多领取的事件数
+	RelayBatchSize = 100
+)
+
+var (
+	onceRelayApp      sync.Once
+	singletonRelayApp RelayApp
+)
+
+// RelayApp 把发件箱中的领域事件分发给进程内注册的处理器
+type RelayApp interface {
+	// DispatchDue 领取并分发到期的事件，返回本轮分发的数量
+	DispatchDue(ctx context.Context) (int, error)
+}
+
+type relayApp struct {
+	outboxRepo repo.OutboxRepository
+	handlers   map[string]manager.EventHandler
+	names      []string
+}
+
+// DefaultRelayApp 获取分发应用服务单例，首次调用时创建全部已注册的事件处理器
+func DefaultRelayApp() RelayApp {
+	assert.NotCircular()
+	onceRelayApp.Do(func() {
+		singletonRelayApp = NewRelayApp(persistence.NewOutboxRepository(), manager.MustCreateEventHandlers())
+	})
+	assert.NotNil(singletonRelayApp)
+	return singletonRelayApp
+}
+
+// NewRelayApp 创建分发应用服务（支持依赖注入），handlers 的键为处理器名称
+func NewRelayApp(outboxRepo repo.OutboxRepository, handlers map[string]manager.EventHandler) RelayApp {
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &relayApp{
+		outboxRepo: outboxRepo,
+		handlers:   handlers,
+		names:      names,
+	}
+}
+
+// DispatchDue 按发生顺序逐个分发，同一轮中前一个事件失败不影响后面的事件
+func (a *relayApp) DispatchDue(ctx context.Context) (int, error) {
+	cfg := eventBusConfig()
+	now := time.Now()
+	entries, err := a.outboxRepo.FindDue(ctx, now, RelayBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	dispatched := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return dispatched, nil
+		}
+		// 租约覆盖全部处理器的超时，进程在分发中退出时租约到期后重新分发
+		lease := time.Now().Add(time.Duration(len(a.handlers)+1) * cfg.HandlerTimeout)
+		claimed, err := a.outboxRepo.Claim(ctx, entry, lease)
+		if err != nil {
+			return dispatched, err
+		}
+		if !claimed {
+			continue
+		}
+		dispatched++
+		a.dispatch(ctx, entry, cfg)
+	}
+	return dispatched, nil
+}
+
+// dispatch 把事件交给每个订阅了该类型且尚未处理过的处理器，全部成功后标记为已分发，否则安排重试
+func (a *relayApp) dispatch(ctx context.Context, entry *entity.OutboxEntry, cfg config.EventBusConfig) {
+	e := entry.Event()
+	consumed, err := a.outboxRepo.FindConsumedHandlers(ctx, e.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("event %s (%s): find consumed handlers failed: %v", e.ID, e.Type, err))
+		return
+	}
+
+	var failures []string
+	for _, name := range a.names {
+		handler := a.handlers[name]
+		if _, done := consumed[name]; done || !subscribes(handler, e.Type) {
+			continue
+		}
+		if err := handle(ctx, handler, e, cfg.HandlerTimeout); err != nil {
+			if ctx.Err() != nil {
+				// 服务停止时中断的处理不计入分发次数，租约到期后重新分发
+				return
+			}
+			logger.Error(fmt.Sprintf("event %s (%s): handler %s failed: %v", e.ID, e.Type, name, err))
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+		if err := a.outboxRepo.SaveConsumption(ctx, name, e.ID); err != nil {
+			// 处理记录写入失败时该处理器会再次收到这个事件，由处理器自行去重
+			logger.Error(fmt.Sprintf("event %s (%s): save consumption for %s failed: %v", e.ID, e.Type, name, err))
+		}
+	}
+
+	if len(failures) == 0 {
+		entry.MarkDispatched()
+	} else {
+		entry.RecordFailure(strings.Join(failures, "; "), cfg.MaxAttempts, cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+		if entry.Status() == vo.OutboxStatusFailed {
+			logger.Error(fmt.Sprintf("event %s (%s) failed after %d attempts: %s", e.ID, e.Type, entry.Attempts(), entry.LastError()))
+		}
+	}
+	if err := a.outboxRepo.Update(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Error(fmt.Sprintf("event %s (%s): update outbox failed: %v", e.ID, e.Type, err))
+	}
+}
+
+// handle 调用处理器，处理器panic时视为处理失败
+func handle(ctx context.Context, handler manager.EventHandler, e *event.Event, timeout time.Duration) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler.Handle(ctx, e)
+}
+
+func subscribes(handler manager.EventHandler, eventType event.Type) bool {
+	for _, subscribed := range handler.EventTypes() {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// eventBusConfig 读取分发配置，未配置的项使用默认值
+func eventBusConfig() config.EventBusConfig {
+	cfg := config.EventBusConfig{
+		MaxAttempts:    defaultEventMaxAttempts,
+		RetryBaseDelay: defaultEventRetryBaseDelay,
+		RetryMaxDelay:  defaultEventRetryMaxDelay,
+		HandlerTimeout: defaultEventHandlerTimeout,
+	}
+	global := config.GetGlobalConfig()
+	if global == nil {
+		return cfg
+	}
+	if global.EventBus.MaxAttempts > 0 {
+		cfg.MaxAttempts = global.EventBus.MaxAttempts
+	}
+	if global.EventBus.RetryBaseDelay > 0 {
+		cfg.RetryBaseDelay = global.EventBus.RetryBaseDelay
+	}
+	if global.EventBus.RetryMaxDelay > 0 {
+		cfg.RetryMaxDelay = global.EventBus.RetryMaxDelay
+	}
+	if global.EventBus.HandlerTimeout > 0 {
+		cfg.HandlerTimeout = global.EventBus.HandlerTimeout
+	}
+	return cfg
+}
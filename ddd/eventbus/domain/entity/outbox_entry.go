@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"go-video/ddd/eventbus/domain/vo"
+	"go-video/pkg/event"
+	"time"
+)
+
+// maxOutboxErrorLength 记录的失败原因最大长度
+const maxOutboxErrorLength = 500
+
+// OutboxEntry 发件箱中的一个待分发事件
+type OutboxEntry struct {
+	event         *event.Event
+	status        string
+	attempts      int
+	nextAttemptAt *time.Time
+	lastError     string
+	dispatchedAt  *time.Time
+}
+
+// NewOutboxEntry 创建发件箱记录（用于从数据库加载）
+func NewOutboxEntry(e *event.Event, status string, attempts int, nextAttemptAt *time.Time, lastError string,
+	dispatchedAt *time.Time) *OutboxEntry {
+	return &OutboxEntry{
+		event:         e,
+		status:        status,
+		attempts:      attempts,
+		nextAttemptAt: nextAttemptAt,
+		lastError:     lastError,
+		dispatchedAt:  dispatchedAt,
+	}
+}
+
+// Event 获取事件
+func (o *OutboxEntry) Event() *event.Event {
+	return o.event
+}
+
+// Status 获取分发状态
+func (o *OutboxEntry) Status() string {
+	return o.status
+}
+
+// Attempts 获取已分发次数
+func (o *OutboxEntry) Attempts() int {
+	return o.attempts
+}
+
+// NextAttemptAt 获取下一次分发时间，分发完成或失败后为nil
+func (o *OutboxEntry) NextAttemptAt() *time.Time {
+	return o.nextAttemptAt
+}
+
+// LastError 获取最近一次分发的失败原因
+func (o *OutboxEntry) LastError() string {
+	return o.lastError
+}
+
+// DispatchedAt 获取分发完成时间
+func (o *OutboxEntry) DispatchedAt() *time.Time {
+	return o.dispatchedAt
+}
+
+// MarkDispatched 全部处理器都已处理
+func (o *OutboxEntry) MarkDispatched() {
+	now := time.Now()
+	o.attempts++
+	o.status = vo.OutboxStatusDispatched
+	o.nextAttemptAt = nil
+	o.lastError = ""
+	o.dispatchedAt = &now
+}
+
+// RecordFailure 记录一次分发失败：按 baseDelay * 2^(n-1)（不超过 maxDelay）安排重试，次数用尽后标记为failed
+func (o *OutboxEntry) RecordFailure(reason string, maxAttempts int, baseDelay, maxDelay time.Duration) {
+	o.attempts++
+	if runes := []rune(reason); len(runes) > maxOutboxErrorLength {
+		reason = string(runes[:maxOutboxErrorLength])
+	}
+	o.lastError = reason
+	if o.attempts >= maxAttempts {
+		o.status = vo.OutboxStatusFailed
+		o.nextAttemptAt = nil
+		return
+	}
+	delay := baseDelay
+	for i := 1; i < o.attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	next := time.Now().Add(delay)
+	o.nextAttemptAt = &next
+}
@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/eventbus/domain/entity"
+	"time"
+)
+
+// OutboxRepository 事件发件箱仓储接口，事件本身由产生事件的仓储在其事务中写入
+type OutboxRepository interface {
+	// FindDue 查找到期待分发的事件，最早到期的在前
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxEntry, error)
+	// Claim 以比较并交换的方式把下一次分发时间推迟到 leaseUntil，成功返回true。
+	// 多个实例同时扫描时只有一个能领取；领取后进程退出，租约到期后会被重新分发
+	Claim(ctx context.Context, entry *entity.OutboxEntry, leaseUntil time.Time) (bool, error)
+	// Update 更新分发状态
+	Update(ctx context.Context, entry *entity.OutboxEntry) error
+	// FindConsumedHandlers 查找已成功处理该事件的处理器
+	FindConsumedHandlers(ctx context.Context, eventUUID string) (map[string]struct{}, error)
+	// SaveConsumption 记录处理器已成功处理该事件，重复记录时忽略
+	SaveConsumption(ctx context.Context, handler, eventUUID string) error
+}
@@ -0,0 +1,11 @@
+package vo
+
+// 发件箱状态
+const (
+	// OutboxStatusPending 等待分发或等待重试
+	OutboxStatusPending = "pending"
+	// OutboxStatusDispatched 全部处理器都已处理
+	OutboxStatusDispatched = "dispatched"
+	// OutboxStatusFailed 重试次数用尽，需要人工处理
+	OutboxStatusFailed = "failed"
+)
@@ -0,0 +1,55 @@
+package convertor
+
+import (
+	"go-video/ddd/eventbus/domain/entity"
+	"go-video/ddd/eventbus/domain/vo"
+	"go-video/ddd/eventbus/infrastructure/database/po"
+	"go-video/pkg/event"
+)
+
+// OutboxConvertor 发件箱转换器
+type OutboxConvertor struct{}
+
+// NewOutboxConvertor 创建发件箱转换器实例
+func NewOutboxConvertor() *OutboxConvertor {
+	return &OutboxConvertor{}
+}
+
+// EventToPO 新产生的事件转PO，立即可以分发
+func (c *OutboxConvertor) EventToPO(e *event.Event) *po.OutboxPo {
+	occurredAt := e.OccurredAt
+	return &po.OutboxPo{
+		EventUUID:     e.ID,
+		EventType:     string(e.Type),
+		AggregateID:   e.AggregateID,
+		Payload:       string(e.Payload),
+		OccurredAt:    occurredAt,
+		Status:        vo.OutboxStatusPending,
+		NextAttemptAt: &occurredAt,
+	}
+}
+
+// EntityToPO 发件箱记录转PO，只包含分发状态
+func (c *OutboxConvertor) EntityToPO(entry *entity.OutboxEntry) *po.OutboxPo {
+	return &po.OutboxPo{
+		EventUUID:     entry.Event().ID,
+		Status:        entry.Status(),
+		Attempts:      entry.Attempts(),
+		NextAttemptAt: entry.NextAttemptAt(),
+		LastError:     entry.LastError(),
+		DispatchedAt:  entry.DispatchedAt(),
+	}
+}
+
+// POToEntity 发件箱PO转实体
+func (c *OutboxConvertor) POToEntity(outboxPo *po.OutboxPo) *entity.OutboxEntry {
+	e := &event.Event{
+		ID:          outboxPo.EventUUID,
+		Type:        event.Type(outboxPo.EventType),
+		AggregateID: outboxPo.AggregateID,
+		OccurredAt:  outboxPo.OccurredAt,
+		Payload:     []byte(outboxPo.Payload),
+	}
+	return entity.NewOutboxEntry(e, outboxPo.Status, outboxPo.Attempts, outboxPo.NextAttemptAt, outboxPo.LastError,
+		outboxPo.DispatchedAt)
+}
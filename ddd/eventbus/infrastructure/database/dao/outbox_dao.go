@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"go-video/ddd/eventbus/domain/vo"
+	"go-video/ddd/eventbus/infrastructure/database/po"
+	"go-video/ddd/internal/resource"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxDao struct {
+	db *gorm.DB
+}
+
+func NewOutboxDao() *OutboxDao {
+	return &OutboxDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// GetDue 获取到期待分发的事件
+func (d *OutboxDao) GetDue(ctx context.Context, now time.Time, limit int) ([]*po.OutboxPo, error) {
+	var outboxPos []*po.OutboxPo
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ? AND is_deleted = 0", vo.OutboxStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&outboxPos).Error
+	return outboxPos, err
+}
+
+// Claim 仅当下一次分发时间未被其他实例修改时推迟到 leaseUntil
+func (d *OutboxDao) Claim(ctx context.Context, eventUUID string, nextAttemptAt *time.Time, leaseUntil time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.OutboxPo{}).
+		Where("event_uuid = ? AND status = ? AND next_attempt_at = ? AND is_deleted = 0", eventUUID, vo.OutboxStatusPending, nextAttemptAt).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Update 更新分发状态、次数、下一次分发时间、失败原因和完成时间
+func (d *OutboxDao) Update(ctx context.Context, outboxPo *po.OutboxPo) error {
+	return d.db.WithContext(ctx).Model(&po.OutboxPo{}).
+		Where("event_uuid = ? AND is_deleted = 0", outboxPo.EventUUID).
+		Updates(map[string]interface{}{
+			"status":          outboxPo.Status,
+			"attempts":        outboxPo.Attempts,
+			"next_attempt_at": outboxPo.NextAttemptAt,
+			"last_error":      outboxPo.LastError,
+			"dispatched_at":   outboxPo.DispatchedAt,
+		}).Error
+}
+
+// GetConsumptions 获取事件的处理记录
+func (d *OutboxDao) GetConsumptions(ctx context.Context, eventUUID string) ([]*po.ConsumptionPo, error) {
+	var consumptionPos []*po.ConsumptionPo
+	err := d.db.WithContext(ctx).
+		Where("event_uuid = ?", eventUUID).
+		Find(&consumptionPos).Error
+	return consumptionPos, err
+}
+
+// CreateConsumption 写入处理记录，已存在时忽略
+func (d *OutboxDao) CreateConsumption(ctx context.Context, consumptionPo *po.ConsumptionPo) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(consumptionPo).Error
+}
+
+// CreateOutbox 在调用方的事务中写入发件箱，同一事件重复写入时忽略
+func CreateOutbox(tx *gorm.DB, outboxPos []*po.OutboxPo) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&outboxPos).Error
+}
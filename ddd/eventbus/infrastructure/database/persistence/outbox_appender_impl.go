@@ -0,0 +1,31 @@
+package persistence
+
+import (
+	"go-video/ddd/eventbus/infrastructure/database/convertor"
+	"go-video/ddd/eventbus/infrastructure/database/dao"
+	"go-video/ddd/eventbus/infrastructure/database/po"
+	"go-video/pkg/event"
+
+	"gorm.io/gorm"
+)
+
+// outboxAppenderImpl 发件箱写入实现，使用调用方的事务，不持有数据库连接
+type outboxAppenderImpl struct {
+	outboxConvertor *convertor.OutboxConvertor
+}
+
+// NewOutboxAppender 创建发件箱写入实例，可以在数据库连接建立前注册
+func NewOutboxAppender() event.Appender {
+	return &outboxAppenderImpl{
+		outboxConvertor: convertor.NewOutboxConvertor(),
+	}
+}
+
+// Append 在调用方的事务中写入事件
+func (a *outboxAppenderImpl) Append(tx *gorm.DB, events ...*event.Event) error {
+	outboxPos := make([]*po.OutboxPo, 0, len(events))
+	for _, e := range events {
+		outboxPos = append(outboxPos, a.outboxConvertor.EventToPO(e))
+	}
+	return dao.CreateOutbox(tx, outboxPos)
+}
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/eventbus/domain/entity"
+	"go-video/ddd/eventbus/domain/repo"
+	"go-video/ddd/eventbus/infrastructure/database/convertor"
+	"go-video/ddd/eventbus/infrastructure/database/dao"
+	"go-video/ddd/eventbus/infrastructure/database/po"
+	"time"
+)
+
+// outboxRepositoryImpl 事件发件箱仓储实现
+type outboxRepositoryImpl struct {
+	outboxDao       *dao.OutboxDao
+	outboxConvertor *convertor.OutboxConvertor
+}
+
+// NewOutboxRepository 创建事件发件箱仓储实例（支持依赖注入）
+func NewOutboxRepository() repo.OutboxRepository {
+	return &outboxRepositoryImpl{
+		outboxDao:       dao.NewOutboxDao(),
+		outboxConvertor: convertor.NewOutboxConvertor(),
+	}
+}
+
+// FindDue 查找到期待分发的事件
+func (r *outboxRepositoryImpl) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxEntry, error) {
+	outboxPos, err := r.outboxDao.GetDue(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*entity.OutboxEntry, 0, len(outboxPos))
+	for _, outboxPo := range outboxPos {
+		entries = append(entries, r.outboxConvertor.POToEntity(outboxPo))
+	}
+	return entries, nil
+}
+
+// Claim 领取事件
+func (r *outboxRepositoryImpl) Claim(ctx context.Context, entry *entity.OutboxEntry, leaseUntil time.Time) (bool, error) {
+	return r.outboxDao.Claim(ctx, entry.Event().ID, entry.NextAttemptAt(), leaseUntil)
+}
+
+// Update 更新分发状态
+func (r *outboxRepositoryImpl) Update(ctx context.Context, entry *entity.OutboxEntry) error {
+	return r.outboxDao.Update(ctx, r.outboxConvertor.EntityToPO(entry))
+}
+
+// FindConsumedHandlers 查找已成功处理该事件的处理器
+func (r *outboxRepositoryImpl) FindConsumedHandlers(ctx context.Context, eventUUID string) (map[string]struct{}, error) {
+	consumptionPos, err := r.outboxDao.GetConsumptions(ctx, eventUUID)
+	if err != nil {
+		return nil, err
+	}
+	handlers := make(map[string]struct{}, len(consumptionPos))
+	for _, consumptionPo := range consumptionPos {
+		handlers[consumptionPo.Handler] = struct{}{}
+	}
+	return handlers, nil
+}
+
+// SaveConsumption 记录处理器已成功处理该事件
+func (r *outboxRepositoryImpl) SaveConsumption(ctx context.Context, handler, eventUUID string) error {
+	return r.outboxDao.CreateConsumption(ctx, &po.ConsumptionPo{
+		Handler:   handler,
+		EventUUID: eventUUID,
+	})
+}
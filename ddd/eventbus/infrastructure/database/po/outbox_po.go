@@ -0,0 +1,37 @@
+package po
+
+import "time"
+
+// OutboxPo 事件发件箱，与产生事件的状态变更在同一事务中写入
+type OutboxPo struct {
+	Id            uint64     `gorm:"primary_key;AUTO_INCREMENT;column:id" json:"-"`
+	CreatedAt     *time.Time `gorm:"column:created_at" json:"-"`
+	UpdatedAt     *time.Time `gorm:"column:updated_at" json:"-"`
+	IsDeleted     uint64     `gorm:"column:is_deleted" json:"-"`
+	EventUUID     string     `gorm:"uniqueIndex;size:36;not null;column:event_uuid" json:"event_uuid"`
+	EventType     string     `gorm:"size:50;not null;column:event_type" json:"event_type"`
+	AggregateID   string     `gorm:"index;size:36;not null;column:aggregate_id" json:"aggregate_id"`
+	Payload       string     `gorm:"type:text;not null;column:payload" json:"payload"`
+	OccurredAt    time.Time  `gorm:"not null;column:occurred_at" json:"occurred_at"`
+	Status        string     `gorm:"index;size:20;not null;column:status" json:"status"`
+	Attempts      int        `gorm:"not null;column:attempts" json:"attempts"`
+	NextAttemptAt *time.Time `gorm:"index;column:next_attempt_at" json:"next_attempt_at"`
+	LastError     string     `gorm:"size:500;column:last_error" json:"last_error"`
+	DispatchedAt  *time.Time `gorm:"column:dispatched_at" json:"dispatched_at"`
+}
+
+func (o *OutboxPo) TableName() string {
+	return "domain_event_outbox"
+}
+
+// ConsumptionPo 处理器已成功处理的事件，事件重新分发时跳过已处理的处理器
+type ConsumptionPo struct {
+	Id        uint64     `gorm:"primary_key;AUTO_INCREMENT;column:id" json:"-"`
+	CreatedAt *time.Time `gorm:"column:created_at" json:"-"`
+	Handler   string     `gorm:"uniqueIndex:uk_handler_event;size:100;not null;column:handler" json:"handler"`
+	EventUUID string     `gorm:"uniqueIndex:uk_handler_event;size:36;not null;column:event_uuid" json:"event_uuid"`
+}
+
+func (c *ConsumptionPo) TableName() string {
+	return "domain_event_consumption"
+}
@@ -0,0 +1,14 @@
+package eventbus
+
+import (
+	"go-video/ddd/eventbus/adapter/job"
+	"go-video/ddd/eventbus/infrastructure/database/persistence"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// init 包初始化函数，注册事件发件箱的写入实现和领域事件分发组件；事件处理器由各模块通过 manager.RegisterEventHandlerPlugin 注册
+func init() {
+	event.RegisterAppender(persistence.NewOutboxAppender())
+	manager.RegisterComponentPlugin(&job.RelayComponentPlugin{})
+}
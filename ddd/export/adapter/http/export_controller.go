@@ -0,0 +1,139 @@
+package http
+
+import (
+	"sync"
+
+	"go-video/ddd/export/application/app"
+	"go-video/ddd/export/application/cqe"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/manager"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	exportControllerOnce      sync.Once
+	singletonExportController ExportController
+)
+
+// ExportControllerPlugin 个人数据导出控制器插件
+type ExportControllerPlugin struct {
+}
+
+func (p *ExportControllerPlugin) Name() string {
+	return "exportControllerPlugin"
+}
+
+func (p *ExportControllerPlugin) MustCreateController() manager.Controller {
+	return DefaultExportController()
+}
+
+type ExportController interface {
+	manager.Controller
+	RequestExport(ctx *gin.Context)
+	GetExport(ctx *gin.Context)
+}
+
+type exportControllerImpl struct {
+	exportApp app.ExportApp
+}
+
+func DefaultExportController() ExportController {
+	assert.NotCircular()
+	exportControllerOnce.Do(func() {
+		singletonExportController = &exportControllerImpl{
+			exportApp: app.DefaultExportApp(),
+		}
+	})
+	assert.NotNil(singletonExportController)
+	return singletonExportController
+}
+
+// RegisterOpenApi 注册开放API
+func (c *exportControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+	v1 := router.Group("/v1", middleware.AuthRequired())
+	{
+		v1.POST("/exports", c.RequestExport)
+		v1.GET("/exports/:id", c.GetExport)
+	}
+}
+
+// RegisterInnerApi 注册内部API
+func (c *exportControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+}
+
+// RegisterDebugApi 注册调试API
+func (c *exportControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+}
+
+// RegisterOpsApi 注册运维API
+func (c *exportControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+	// 导出他人的个人数据只对管理员开放
+	exports := router.Group("/v1/exports", middleware.AuthRequired(), middleware.AdminRequired())
+	{
+		exports.POST("/users/:user_uuid", c.TriggerExport)
+		exports.GET("/:id", c.GetExportForOps)
+	}
+}
+
+// RequestExport 导出当前用户的个人数据
+func (c *exportControllerImpl) RequestExport(ctx *gin.Context) {
+	userUUID := middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.exportApp.RequestExport(ctx.Request.Context(), &cqe.RequestExportCommand{
+		OperatorUUID: userUUID,
+		UserUUID:     userUUID,
+	})
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetExport 查询当前用户的导出状态和下载链接
+func (c *exportControllerImpl) GetExport(ctx *gin.Context) {
+	var query cqe.GetExportQuery
+	if err := ctx.ShouldBindUri(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.exportApp.GetExport(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// TriggerExport 为指定用户发起导出
+func (c *exportControllerImpl) TriggerExport(ctx *gin.Context) {
+	result, err := c.exportApp.TriggerExport(ctx.Request.Context(), &cqe.RequestExportCommand{
+		OperatorUUID: middleware.MustGetCurrentUserUUID(ctx),
+		UserUUID:     ctx.Param("user_uuid"),
+	})
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetExportForOps 查询任意导出的状态和下载链接
+func (c *exportControllerImpl) GetExportForOps(ctx *gin.Context) {
+	var query cqe.GetExportQuery
+	if err := ctx.ShouldBindUri(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.exportApp.GetExportForOps(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
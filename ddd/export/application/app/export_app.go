@@ -0,0 +1,347 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"go-video/ddd/export/application/cqe"
+	"go-video/ddd/export/application/dto"
+	"go-video/ddd/export/domain/entity"
+	"go-video/ddd/export/domain/gateway"
+	"go-video/ddd/export/domain/repo"
+	"go-video/ddd/export/domain/vo"
+	"go-video/ddd/export/infrastructure/database/persistence"
+	exportGateway "go-video/ddd/export/infrastructure/gateway"
+	"go-video/ddd/export/infrastructure/storage"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExportRateWindow 未配置时同一用户两次自助导出的最小间隔
+	defaultExportRateWindow = 24 * time.Hour
+	// defaultExportLinkTTL 未配置时导出包下载链接的有效期
+	defaultExportLinkTTL = 15 * time.Minute
+	// defaultExportInlineMaxSize 未配置时打包进导出包的源文件大小上限
+	defaultExportInlineMaxSize = 1 << 30
+	// defaultExportSourceLinkTTL 未配置时源文件预签名链接的有效期
+	defaultExportSourceLinkTTL = 7 * 24 * time.Hour
+	// defaultExportTimeout 未配置时单次导出的总超时
+	defaultExportTimeout = 2 * time.Hour
+)
+
+var (
+	onceExportApp      sync.Once
+	singletonExportApp ExportApp
+)
+
+// ExportApp 个人数据导出应用服务
+type ExportApp interface {
+	RequestExport(ctx context.Context, cmd *cqe.RequestExportCommand) (*dto.ExportDto, error)
+	TriggerExport(ctx context.Context, cmd *cqe.RequestExportCommand) (*dto.ExportDto, error)
+	GetExport(ctx context.Context, query *cqe.GetExportQuery) (*dto.ExportDto, error)
+	GetExportForOps(ctx context.Context, query *cqe.GetExportQuery) (*dto.ExportDto, error)
+}
+
+type exportApp struct {
+	exportRepo    repo.ExportRepository
+	archiveStore  gateway.ArchiveStore
+	userGateway   gateway.UserGateway
+	videoGateway  gateway.VideoGateway
+	reportGateway gateway.ReportGateway
+}
+
+func DefaultExportApp() ExportApp {
+	assert.NotCircular()
+	onceExportApp.Do(func() {
+		singletonExportApp = &exportApp{
+			exportRepo:    persistence.NewExportRepository(),
+			archiveStore:  storage.NewArchiveStore(),
+			userGateway:   exportGateway.NewUserGateway(),
+			videoGateway:  exportGateway.NewVideoGateway(),
+			reportGateway: exportGateway.NewReportGateway(),
+		}
+	})
+	assert.NotNil(singletonExportApp)
+	return singletonExportApp
+}
+
+// RequestExport 用户导出自己的数据，按配置的间隔限流（失败的导出不计入）
+func (a *exportApp) RequestExport(ctx context.Context, cmd *cqe.RequestExportCommand) (*dto.ExportDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	count, err := a.exportRepo.CountByOwnerSince(ctx, cmd.UserUUID, time.Now().Add(-exportConfig().RateWindow))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if count > 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrExportRateLimited, nil)
+	}
+	return a.startExport(ctx, cmd)
+}
+
+// TriggerExport 管理员为任意用户发起导出，不受限流约束；为他人发起时记录审计
+func (a *exportApp) TriggerExport(ctx context.Context, cmd *cqe.RequestExportCommand) (*dto.ExportDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	return a.startExport(ctx, cmd)
+}
+
+// GetExport 用户查询自己的导出，完成后返回新签发的下载链接
+func (a *exportApp) GetExport(ctx context.Context, query *cqe.GetExportQuery) (*dto.ExportDto, error) {
+	export, err := a.findExport(ctx, query.ExportUUID)
+	if err != nil {
+		return nil, err
+	}
+	if export.UserUuid() != query.OperatorUUID {
+		return nil, errno.NewSimpleBizError(errno.ErrExportNotFound, nil)
+	}
+	return a.toExportDto(ctx, export)
+}
+
+// GetExportForOps 管理员查询任意导出；查看他人的导出时记录审计
+func (a *exportApp) GetExportForOps(ctx context.Context, query *cqe.GetExportQuery) (*dto.ExportDto, error) {
+	if !config.IsAdmin(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	export, err := a.findExport(ctx, query.ExportUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.audit(ctx, export, query.OperatorUUID, vo.ExportAuditActionView); err != nil {
+		return nil, err
+	}
+	return a.toExportDto(ctx, export)
+}
+
+// startExport 保存导出记录并在后台打包
+func (a *exportApp) startExport(ctx context.Context, cmd *cqe.RequestExportCommand) (*dto.ExportDto, error) {
+	exists, err := a.userGateway.Exists(ctx, cmd.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errno.NewSimpleBizError(errno.ErrNotFound, nil)
+	}
+	export := entity.DefaultExport(cmd.UserUUID, cmd.OperatorUUID)
+	if err := a.exportRepo.Save(ctx, export); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if err := a.audit(ctx, export, cmd.OperatorUUID, vo.ExportAuditActionTrigger); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("export %s of user %s requested by %s", export.UUID(), export.UserUuid(), export.RequestedBy()))
+
+	go a.exportInBackground(context.WithoutCancel(ctx), export)
+
+	return a.toExportDto(ctx, export)
+}
+
+// audit 操作人不是数据所属用户时追加审计记录，审计写入失败则拒绝本次操作
+func (a *exportApp) audit(ctx context.Context, export *entity.Export, operatorUUID string, action vo.ExportAuditAction) error {
+	if operatorUUID == export.UserUuid() {
+		return nil
+	}
+	if err := a.exportRepo.SaveAudit(ctx, entity.NewExportAudit(export, operatorUUID, action)); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	logger.Info(fmt.Sprintf("export audit: %s %s export %s of user %s", operatorUUID, action.Value(), export.UUID(), export.UserUuid()))
+	return nil
+}
+
+// exportInBackground 打包并写入存储，根据结果更新导出状态
+func (a *exportApp) exportInBackground(ctx context.Context, export *entity.Export) {
+	cfg := exportConfig()
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	export.Start()
+	if err := a.exportRepo.Update(ctx, export); err != nil {
+		logger.Error(fmt.Sprintf("export %s update status error: %v", export.UUID(), err))
+	}
+	size, err := a.buildArchive(ctx, export, cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("export %s of user %s failed: %v", export.UUID(), export.UserUuid(), err))
+		export.Fail(err.Error())
+	} else {
+		logger.Info(fmt.Sprintf("export %s of user %s completed, %d bytes", export.UUID(), export.UserUuid(), size))
+		export.Complete(size)
+	}
+	// 打包超时后 ctx 已取消，状态仍需写入
+	if err := a.exportRepo.Update(context.WithoutCancel(ctx), export); err != nil {
+		logger.Error(fmt.Sprintf("export %s update status error: %v", export.UUID(), err))
+	}
+}
+
+// buildArchive 收集各上下文的数据后，边生成ZIP边写入存储，不落本地磁盘。返回导出包大小
+func (a *exportApp) buildArchive(ctx context.Context, export *entity.Export, cfg config.ExportConfig) (int64, error) {
+	profile, err := a.userGateway.Profile(ctx, export.UserUuid())
+	if err != nil {
+		return 0, err
+	}
+	sources, err := a.videoGateway.ListSources(ctx, export.UserUuid())
+	if err != nil {
+		return 0, err
+	}
+	var inline []*vo.SourceFile
+	for _, source := range sources {
+		if !source.ShouldLink(cfg.InlineMaxSize) {
+			source.Inline()
+			inline = append(inline, source)
+			continue
+		}
+		url, err := a.videoGateway.SourceURL(ctx, source, cfg.SourceLinkTTL)
+		if err != nil {
+			return 0, err
+		}
+		source.Link(url, time.Now().Add(cfg.SourceLinkTTL))
+	}
+	videoDocuments, err := a.videoGateway.Documents(ctx, export.UserUuid(), sources)
+	if err != nil {
+		return 0, err
+	}
+	reportDocuments, err := a.reportGateway.Documents(ctx, export.UserUuid())
+	if err != nil {
+		return 0, err
+	}
+	documents := append([]vo.Document{profile}, videoDocuments...)
+	documents = append(documents, reportDocuments...)
+
+	reader, writer := io.Pipe()
+	counter := &countingWriter{writer: writer}
+	go func() {
+		writer.CloseWithError(a.writeArchive(ctx, counter, documents, inline))
+	}()
+	err = a.archiveStore.Put(ctx, export.ObjectName(), reader)
+	// 写入存储失败时让打包协程退出
+	reader.CloseWithError(err)
+	if err != nil {
+		return 0, err
+	}
+	return counter.count, nil
+}
+
+// writeArchive 写出ZIP：JSON文档压缩存储，视频本身已压缩，直接存储
+func (a *exportApp) writeArchive(ctx context.Context, w io.Writer, documents []vo.Document, inline []*vo.SourceFile) error {
+	archive := zip.NewWriter(w)
+	now := time.Now()
+	for _, document := range documents {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: document.Name(), Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(document.Content()); err != nil {
+			return err
+		}
+	}
+	for _, source := range inline {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: source.ArchivePath(), Method: zip.Store, Modified: now})
+		if err != nil {
+			return err
+		}
+		if err := a.copySource(ctx, file, source); err != nil {
+			return fmt.Errorf("video %s: %w", source.VideoUUID(), err)
+		}
+	}
+	return archive.Close()
+}
+
+func (a *exportApp) copySource(ctx context.Context, w io.Writer, source *vo.SourceFile) error {
+	reader, err := a.videoGateway.OpenSource(ctx, source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+func (a *exportApp) findExport(ctx context.Context, exportUUID string) (*entity.Export, error) {
+	export, err := a.exportRepo.FindByUUID(ctx, exportUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if export == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrExportNotFound, nil)
+	}
+	return export, nil
+}
+
+// toExportDto 导出已完成时签发短时有效的下载链接
+func (a *exportApp) toExportDto(ctx context.Context, export *entity.Export) (*dto.ExportDto, error) {
+	exportDto := &dto.ExportDto{
+		ExportUUID:  export.UUID(),
+		UserUUID:    export.UserUuid(),
+		RequestedBy: export.RequestedBy(),
+		Status:      export.Status().Value(),
+		FileSize:    export.FileSize(),
+		Error:       export.ErrorMsg(),
+		CreatedAt:   export.CreatedAt(),
+		CompletedAt: export.CompletedAt(),
+	}
+	if export.Status() != vo.ExportStatusCompleted {
+		return exportDto, nil
+	}
+	ttl := exportConfig().LinkTTL
+	url, err := a.archiveStore.URL(ctx, export.ObjectName(), ttl)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	expiresAt := time.Now().Add(ttl)
+	exportDto.DownloadURL = url
+	exportDto.URLExpiresAt = &expiresAt
+	return exportDto, nil
+}
+
+// countingWriter 统计写出的字节数
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count += int64(n)
+	return n, err
+}
+
+// exportConfig 导出配置，未配置的项使用默认值
+func exportConfig() config.ExportConfig {
+	cfg := config.ExportConfig{
+		RateWindow:    defaultExportRateWindow,
+		LinkTTL:       defaultExportLinkTTL,
+		InlineMaxSize: defaultExportInlineMaxSize,
+		SourceLinkTTL: defaultExportSourceLinkTTL,
+		Timeout:       defaultExportTimeout,
+	}
+	global := config.GetGlobalConfig()
+	if global == nil {
+		return cfg
+	}
+	if global.Export.RateWindow > 0 {
+		cfg.RateWindow = global.Export.RateWindow
+	}
+	if global.Export.LinkTTL > 0 {
+		cfg.LinkTTL = global.Export.LinkTTL
+	}
+	if global.Export.InlineMaxSize > 0 {
+		cfg.InlineMaxSize = global.Export.InlineMaxSize
+	}
+	if global.Export.SourceLinkTTL > 0 {
+		cfg.SourceLinkTTL = global.Export.SourceLinkTTL
+	}
+	if global.Export.Timeout > 0 {
+		cfg.Timeout = global.Export.Timeout
+	}
+	return cfg
+}
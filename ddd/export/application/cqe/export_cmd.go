@@ -0,0 +1,23 @@
+package cqe
+
+import "go-video/pkg/errno"
+
+// RequestExportCommand 发起个人数据导出的命令，用户本人发起时 OperatorUUID 与 UserUUID 相同
+type RequestExportCommand struct {
+	OperatorUUID string `json:"-"`
+	UserUUID     string `json:"-"`
+}
+
+// Validate 校验导出参数
+func (c *RequestExportCommand) Validate() error {
+	if len(c.OperatorUUID) == 0 || len(c.UserUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
+
+// GetExportQuery 查询导出状态
+type GetExportQuery struct {
+	OperatorUUID string `uri:"-"`
+	ExportUUID   string `uri:"id" binding:"required"`
+}
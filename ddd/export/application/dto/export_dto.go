@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// ExportDto 个人数据导出
+type ExportDto struct {
+	ExportUUID   string     `json:"export_uuid"`
+	UserUUID     string     `json:"user_uuid"`
+	RequestedBy  string     `json:"requested_by"`
+	Status       string     `json:"status"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
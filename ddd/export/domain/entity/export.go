@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"fmt"
+	"go-video/ddd/export/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export 个人数据导出
+type Export struct {
+	uuid        string
+	userUuid    string
+	requestedBy string
+	status      vo.ExportStatus
+	objectName  string
+	fileSize    int64
+	errorMsg    string
+	createdAt   *time.Time
+	completedAt *time.Time
+}
+
+// DefaultExport 创建新的导出，requestedBy 为发起人UUID（本人或运维人员）
+func DefaultExport(userUuid, requestedBy string) *Export {
+	exportUuid := uuid.New().String()
+	return &Export{
+		uuid:        exportUuid,
+		userUuid:    userUuid,
+		requestedBy: requestedBy,
+		status:      vo.ExportStatusPending,
+		objectName:  fmt.Sprintf("exports/%s/%s.zip", userUuid, exportUuid),
+	}
+}
+
+// NewExport 创建导出（用于从数据库加载）
+func NewExport(uuid, userUuid, requestedBy string, status vo.ExportStatus, objectName string, fileSize int64, errorMsg string,
+	createdAt, completedAt *time.Time) *Export {
+	return &Export{
+		uuid:        uuid,
+		userUuid:    userUuid,
+		requestedBy: requestedBy,
+		status:      status,
+		objectName:  objectName,
+		fileSize:    fileSize,
+		errorMsg:    errorMsg,
+		createdAt:   createdAt,
+		completedAt: completedAt,
+	}
+}
+
+// UUID 获取导出UUID
+func (e *Export) UUID() string {
+	return e.uuid
+}
+
+// UserUuid 获取被导出数据的用户UUID
+func (e *Export) UserUuid() string {
+	return e.userUuid
+}
+
+// RequestedBy 获取发起人UUID
+func (e *Export) RequestedBy() string {
+	return e.requestedBy
+}
+
+// Status 获取导出状态
+func (e *Export) Status() vo.ExportStatus {
+	return e.status
+}
+
+// ObjectName 获取导出包在存储中的对象名
+func (e *Export) ObjectName() string {
+	return e.objectName
+}
+
+// FileSize 获取导出包大小
+func (e *Export) FileSize() int64 {
+	return e.fileSize
+}
+
+// ErrorMsg 获取失败原因
+func (e *Export) ErrorMsg() string {
+	return e.errorMsg
+}
+
+// CreatedAt 获取提交时间
+func (e *Export) CreatedAt() *time.Time {
+	return e.createdAt
+}
+
+// CompletedAt 获取完成（或失败）时间
+func (e *Export) CompletedAt() *time.Time {
+	return e.completedAt
+}
+
+// IsRequestedByOwner 是否由用户本人发起
+func (e *Export) IsRequestedByOwner() bool {
+	return e.requestedBy == e.userUuid
+}
+
+// Start 开始打包
+func (e *Export) Start() {
+	e.status = vo.ExportStatusRunning
+}
+
+// Complete 导出包已写入存储
+func (e *Export) Complete(fileSize int64) {
+	now := time.Now()
+	e.status = vo.ExportStatusCompleted
+	e.fileSize = fileSize
+	e.errorMsg = ""
+	e.completedAt = &now
+}
+
+// Fail 打包失败
+func (e *Export) Fail(errorMsg string) {
+	now := time.Now()
+	e.status = vo.ExportStatusFailed
+	e.errorMsg = errorMsg
+	e.completedAt = &now
+}
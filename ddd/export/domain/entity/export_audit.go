@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"go-video/ddd/export/domain/vo"
+	"time"
+)
+
+// ExportAudit 运维人员接触他人个人数据的审计记录，只追加不修改
+type ExportAudit struct {
+	exportUuid   string
+	userUuid     string
+	operatorUuid string
+	action       vo.ExportAuditAction
+	createdAt    time.Time
+}
+
+// NewExportAudit 为导出记录一次审计
+func NewExportAudit(export *Export, operatorUuid string, action vo.ExportAuditAction) *ExportAudit {
+	return &ExportAudit{
+		exportUuid:   export.UUID(),
+		userUuid:     export.UserUuid(),
+		operatorUuid: operatorUuid,
+		action:       action,
+		createdAt:    time.Now(),
+	}
+}
+
+// ExportUuid 获取导出UUID
+func (a *ExportAudit) ExportUuid() string {
+	return a.exportUuid
+}
+
+// UserUuid 获取被导出数据的用户UUID
+func (a *ExportAudit) UserUuid() string {
+	return a.userUuid
+}
+
+// OperatorUuid 获取操作人UUID
+func (a *ExportAudit) OperatorUuid() string {
+	return a.operatorUuid
+}
+
+// Action 获取操作
+func (a *ExportAudit) Action() vo.ExportAuditAction {
+	return a.action
+}
+
+// CreatedAt 获取操作时间
+func (a *ExportAudit) CreatedAt() time.Time {
+	return a.createdAt
+}
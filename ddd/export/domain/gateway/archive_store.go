@@ -0,0 +1,15 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ArchiveStore 导出包存储
+type ArchiveStore interface {
+	// Put 以流的方式写入导出包，长度未知
+	Put(ctx context.Context, objectName string, reader io.Reader) error
+	// URL 获取导出包指定有效期的下载链接
+	URL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
+}
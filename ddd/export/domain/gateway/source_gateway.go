@@ -0,0 +1,34 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/export/domain/vo"
+	"io"
+	"time"
+)
+
+// UserGateway 用户上下文防腐层
+type UserGateway interface {
+	// Exists 检查用户是否存在
+	Exists(ctx context.Context, userUUID string) (bool, error)
+	// Profile 用户资料文档
+	Profile(ctx context.Context, userUUID string) (vo.Document, error)
+}
+
+// VideoGateway 视频上下文防腐层
+type VideoGateway interface {
+	// ListSources 列出用户全部视频的源文件
+	ListSources(ctx context.Context, userUUID string) ([]*vo.SourceFile, error)
+	// Documents 用户的视频元数据和上传、状态变更记录，元数据中以导出包内路径或预签名链接引用 sources 中的源文件
+	Documents(ctx context.Context, userUUID string, sources []*vo.SourceFile) ([]vo.Document, error)
+	// OpenSource 读取源文件明文
+	OpenSource(ctx context.Context, source *vo.SourceFile) (io.ReadCloser, error)
+	// SourceURL 获取源文件的预签名链接
+	SourceURL(ctx context.Context, source *vo.SourceFile, expiry time.Duration) (string, error)
+}
+
+// ReportGateway 举报上下文防腐层
+type ReportGateway interface {
+	// Documents 用户提交的举报记录
+	Documents(ctx context.Context, userUUID string) ([]vo.Document, error)
+}
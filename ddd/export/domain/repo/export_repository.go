@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/export/domain/entity"
+	"time"
+)
+
+// ExportRepository 个人数据导出仓储接口
+type ExportRepository interface {
+	// Save 保存新的导出
+	Save(ctx context.Context, export *entity.Export) error
+	// Update 更新导出的状态、导出包大小和失败原因
+	Update(ctx context.Context, export *entity.Export) error
+	// FindByUUID 根据UUID查找导出，不存在时返回nil
+	FindByUUID(ctx context.Context, exportUUID string) (*entity.Export, error)
+	// CountByOwnerSince 统计用户本人在指定时间之后发起且未失败的导出数
+	CountByOwnerSince(ctx context.Context, userUUID string, since time.Time) (int64, error)
+	// SaveAudit 追加运维人员接触他人数据的审计记录
+	SaveAudit(ctx context.Context, audit *entity.ExportAudit) error
+}
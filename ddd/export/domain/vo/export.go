@@ -0,0 +1,164 @@
+package vo
+
+import (
+	"path"
+	"time"
+)
+
+// ExportStatus 导出状态
+type ExportStatus struct {
+	value string
+}
+
+var (
+	// ExportStatusPending 已提交，等待打包
+	ExportStatusPending = ExportStatus{
+		"pending",
+	}
+	// ExportStatusRunning 正在打包
+	ExportStatusRunning = ExportStatus{
+		"running",
+	}
+	// ExportStatusCompleted 导出包已写入存储，可以下载
+	ExportStatusCompleted = ExportStatus{
+		"completed",
+	}
+	// ExportStatusFailed 打包失败
+	ExportStatusFailed = ExportStatus{
+		"failed",
+	}
+)
+
+var ExportStatuses = []ExportStatus{
+	ExportStatusPending,
+	ExportStatusRunning,
+	ExportStatusCompleted,
+	ExportStatusFailed,
+}
+
+// NewExportStatus 根据字符串创建导出状态，未知值视为等待打包
+func NewExportStatus(value string) ExportStatus {
+	for _, status := range ExportStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return ExportStatusPending
+}
+
+// Value 返回状态的字符串值
+func (s ExportStatus) Value() string {
+	return s.value
+}
+
+// Document 导出包中的一个JSON文档，由数据所属的上下文生成
+type Document struct {
+	name    string
+	content []byte
+}
+
+// NewDocument 创建导出文档，name 为导出包内的路径
+func NewDocument(name string, content []byte) Document {
+	return Document{
+		name:    name,
+		content: content,
+	}
+}
+
+// Name 获取导出包内的路径
+func (d Document) Name() string {
+	return d.name
+}
+
+// Content 获取文档内容
+func (d Document) Content() []byte {
+	return d.content
+}
+
+// SourceFile 用户视频的源文件。小文件打包进导出包，大文件附带预签名链接
+type SourceFile struct {
+	videoUUID    string
+	filename     string
+	size         int64
+	encrypted    bool
+	archivePath  string
+	downloadURL  string
+	urlExpiresAt *time.Time
+}
+
+// NewSourceFile 创建源文件，size 为明文大小
+func NewSourceFile(videoUUID, filename string, size int64, encrypted bool) *SourceFile {
+	return &SourceFile{
+		videoUUID: videoUUID,
+		filename:  filename,
+		size:      size,
+		encrypted: encrypted,
+	}
+}
+
+// VideoUUID 获取视频UUID
+func (f *SourceFile) VideoUUID() string {
+	return f.videoUUID
+}
+
+// Filename 获取上传时的文件名
+func (f *SourceFile) Filename() string {
+	return f.filename
+}
+
+// Size 获取明文大小
+func (f *SourceFile) Size() int64 {
+	return f.size
+}
+
+// ShouldLink 超过打包上限的文件改为提供预签名链接；加密对象的预签名链接只能拿到密文，始终打包
+func (f *SourceFile) ShouldLink(inlineMaxSize int64) bool {
+	return !f.encrypted && inlineMaxSize > 0 && f.size > inlineMaxSize
+}
+
+// Inline 打包进导出包，路径为 videos/<视频UUID><扩展名>
+func (f *SourceFile) Inline() {
+	f.archivePath = "videos/" + f.videoUUID + path.Ext(f.filename)
+}
+
+// Link 以预签名链接提供
+func (f *SourceFile) Link(url string, expiresAt time.Time) {
+	f.downloadURL = url
+	f.urlExpiresAt = &expiresAt
+}
+
+// ArchivePath 获取导出包内的路径，以链接提供时为空
+func (f *SourceFile) ArchivePath() string {
+	return f.archivePath
+}
+
+// DownloadURL 获取预签名链接，打包时为空
+func (f *SourceFile) DownloadURL() string {
+	return f.downloadURL
+}
+
+// URLExpiresAt 获取预签名链接的过期时间
+func (f *SourceFile) URLExpiresAt() *time.Time {
+	return f.urlExpiresAt
+}
+
+// ExportAuditAction 运维人员对他人导出执行的操作
+type ExportAuditAction struct {
+	value string
+}
+
+var (
+	// ExportAuditActionTrigger 为他人发起导出
+	ExportAuditActionTrigger = ExportAuditAction{
+		"trigger",
+	}
+	// ExportAuditActionView 查看他人的导出，完成后会签发下载链接
+	ExportAuditActionView = ExportAuditAction{
+		"view",
+	}
+)
+
+// Value 返回操作的字符串值
+func (a ExportAuditAction) Value() string {
+	return a.value
+}
@@ -0,0 +1,56 @@
+package convertor
+
+import (
+	"go-video/ddd/export/domain/entity"
+	"go-video/ddd/export/domain/vo"
+	"go-video/ddd/export/infrastructure/database/po"
+)
+
+// ExportConvertor 导出转换器
+type ExportConvertor struct{}
+
+// NewExportConvertor 创建导出转换器实例
+func NewExportConvertor() *ExportConvertor {
+	return &ExportConvertor{}
+}
+
+// EntityToPO 导出实体转PO
+func (c *ExportConvertor) EntityToPO(export *entity.Export) *po.ExportPo {
+	if export == nil {
+		return nil
+	}
+	return &po.ExportPo{
+		UUID:        export.UUID(),
+		UserUUID:    export.UserUuid(),
+		RequestedBy: export.RequestedBy(),
+		Status:      export.Status().Value(),
+		ObjectName:  export.ObjectName(),
+		FileSize:    export.FileSize(),
+		ErrorMsg:    export.ErrorMsg(),
+		CompletedAt: export.CompletedAt(),
+	}
+}
+
+// POToEntity 导出PO转实体
+func (c *ExportConvertor) POToEntity(exportPo *po.ExportPo) *entity.Export {
+	if exportPo == nil {
+		return nil
+	}
+	return entity.NewExport(exportPo.UUID, exportPo.UserUUID, exportPo.RequestedBy, vo.NewExportStatus(exportPo.Status),
+		exportPo.ObjectName, exportPo.FileSize, exportPo.ErrorMsg, exportPo.CreatedAt, exportPo.CompletedAt)
+}
+
+// AuditEntityToPO 审计记录实体转PO
+func (c *ExportConvertor) AuditEntityToPO(audit *entity.ExportAudit) *po.ExportAuditPo {
+	if audit == nil {
+		return nil
+	}
+	createdAt := audit.CreatedAt()
+	return &po.ExportAuditPo{
+		BaseModel:    po.BaseModel{CreatedAt: &createdAt},
+		ExportUUID:   audit.ExportUuid(),
+		UserUUID:     audit.UserUuid(),
+		OperatorUUID: audit.OperatorUuid(),
+		Action:       audit.Action().Value(),
+	}
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/export/infrastructure/database/po"
+	"go-video/ddd/internal/resource"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExportDao struct {
+	db *gorm.DB
+}
+
+func NewExportDao() *ExportDao {
+	return &ExportDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// Create 创建导出
+func (d *ExportDao) Create(ctx context.Context, exportPo *po.ExportPo) error {
+	return d.db.WithContext(ctx).Create(exportPo).Error
+}
+
+// Update 更新导出的状态、导出包大小、失败原因和完成时间
+func (d *ExportDao) Update(ctx context.Context, exportPo *po.ExportPo) error {
+	return d.db.WithContext(ctx).Model(&po.ExportPo{}).
+		Where("uuid = ? AND is_deleted = 0", exportPo.UUID).
+		Updates(map[string]interface{}{
+			"status":       exportPo.Status,
+			"file_size":    exportPo.FileSize,
+			"error_msg":    exportPo.ErrorMsg,
+			"completed_at": exportPo.CompletedAt,
+		}).Error
+}
+
+// GetByUUID 根据UUID获取导出
+func (d *ExportDao) GetByUUID(ctx context.Context, uuid string) (*po.ExportPo, error) {
+	var exportPo po.ExportPo
+	err := d.db.WithContext(ctx).First(&exportPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &exportPo, nil
+}
+
+// CountByOwnerSince 统计用户本人在指定时间之后发起且未失败的导出数
+func (d *ExportDao) CountByOwnerSince(ctx context.Context, userUUID string, since time.Time) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&po.ExportPo{}).
+		Where("user_uuid = ? AND requested_by = ? AND status <> ? AND created_at >= ? AND is_deleted = 0", userUUID, userUUID, "failed", since).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateAudit 追加审计记录
+func (d *ExportDao) CreateAudit(ctx context.Context, auditPo *po.ExportAuditPo) error {
+	return d.db.WithContext(ctx).Create(auditPo).Error
+}
@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/export/domain/entity"
+	"go-video/ddd/export/domain/repo"
+	"go-video/ddd/export/infrastructure/database/convertor"
+	"go-video/ddd/export/infrastructure/database/dao"
+	"time"
+)
+
+// exportRepositoryImpl 导出仓储实现
+type exportRepositoryImpl struct {
+	exportDao       *dao.ExportDao
+	exportConvertor *convertor.ExportConvertor
+}
+
+// NewExportRepository 创建导出仓储实例（支持依赖注入）
+func NewExportRepository() repo.ExportRepository {
+	return &exportRepositoryImpl{
+		exportDao:       dao.NewExportDao(),
+		exportConvertor: convertor.NewExportConvertor(),
+	}
+}
+
+// Save 保存新的导出
+func (r *exportRepositoryImpl) Save(ctx context.Context, export *entity.Export) error {
+	return r.exportDao.Create(ctx, r.exportConvertor.EntityToPO(export))
+}
+
+// Update 更新导出的状态、导出包大小和失败原因
+func (r *exportRepositoryImpl) Update(ctx context.Context, export *entity.Export) error {
+	return r.exportDao.Update(ctx, r.exportConvertor.EntityToPO(export))
+}
+
+// FindByUUID 根据UUID查找导出
+func (r *exportRepositoryImpl) FindByUUID(ctx context.Context, exportUUID string) (*entity.Export, error) {
+	exportPo, err := r.exportDao.GetByUUID(ctx, exportUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.exportConvertor.POToEntity(exportPo), nil
+}
+
+// CountByOwnerSince 统计用户本人在指定时间之后发起且未失败的导出数
+func (r *exportRepositoryImpl) CountByOwnerSince(ctx context.Context, userUUID string, since time.Time) (int64, error) {
+	return r.exportDao.CountByOwnerSince(ctx, userUUID, since)
+}
+
+// SaveAudit 追加运维人员接触他人数据的审计记录
+func (r *exportRepositoryImpl) SaveAudit(ctx context.Context, audit *entity.ExportAudit) error {
+	return r.exportDao.CreateAudit(ctx, r.exportConvertor.AuditEntityToPO(audit))
+}
@@ -0,0 +1,14 @@
+package po
+
+type ExportAuditPo struct {
+	BaseModel
+
+	ExportUUID   string `gorm:"index;size:36;not null;column:export_uuid" json:"export_uuid"`
+	UserUUID     string `gorm:"index;size:36;not null;column:user_uuid" json:"user_uuid"`
+	OperatorUUID string `gorm:"index;size:36;not null;column:operator_uuid" json:"operator_uuid"`
+	Action       string `gorm:"size:20;not null;column:action" json:"action"`
+}
+
+func (e *ExportAuditPo) TableName() string {
+	return "user_export_audit"
+}
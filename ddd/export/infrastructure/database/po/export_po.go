@@ -0,0 +1,20 @@
+package po
+
+import "time"
+
+type ExportPo struct {
+	BaseModel
+
+	UUID        string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	UserUUID    string     `gorm:"index;size:36;not null;column:user_uuid" json:"user_uuid"`
+	RequestedBy string     `gorm:"size:36;not null;column:requested_by" json:"requested_by"`
+	Status      string     `gorm:"size:20;not null;column:status" json:"status"`
+	ObjectName  string     `gorm:"size:255;not null;column:object_name" json:"object_name"`
+	FileSize    int64      `gorm:"column:file_size" json:"file_size"`
+	ErrorMsg    string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	CompletedAt *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+func (e *ExportPo) TableName() string {
+	return "user_export"
+}
@@ -0,0 +1,33 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/export/domain/gateway"
+	"go-video/ddd/export/domain/vo"
+	reportApp "go-video/ddd/report/application/app"
+)
+
+// reportGatewayImpl 通过举报应用服务访问举报上下文
+type reportGatewayImpl struct {
+	reportApp reportApp.ReportApp
+}
+
+// NewReportGateway 创建举报防腐层实例
+func NewReportGateway() gateway.ReportGateway {
+	return &reportGatewayImpl{
+		reportApp: reportApp.DefaultReportApp(),
+	}
+}
+
+// Documents 用户提交的举报 activity/reports.json
+func (g *reportGatewayImpl) Documents(ctx context.Context, userUUID string) ([]vo.Document, error) {
+	reports, err := g.reportApp.ListReportsByReporter(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	document, err := jsonDocument("activity/reports.json", reports)
+	if err != nil {
+		return nil, err
+	}
+	return []vo.Document{document}, nil
+}
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"go-video/ddd/export/domain/gateway"
+	"go-video/ddd/export/domain/vo"
+	userApp "go-video/ddd/user/application/app"
+	userService "go-video/ddd/user/domain/service"
+	"go-video/pkg/errno"
+)
+
+// userGatewayImpl 通过用户领域服务访问用户上下文，资料以用户上下文对外的 UserInfo 结构导出
+type userGatewayImpl struct {
+	userService *userService.UserService
+	userApp     *userApp.UserApp
+}
+
+// NewUserGateway 创建用户防腐层实例
+func NewUserGateway() gateway.UserGateway {
+	return &userGatewayImpl{
+		userService: userService.DefaultUserService(),
+		userApp:     userApp.DefaultUserApp(),
+	}
+}
+
+// Exists 检查用户是否存在
+func (g *userGatewayImpl) Exists(ctx context.Context, userUUID string) (bool, error) {
+	_, err := g.userService.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		if errno.AssertBizError(err).Code() == errno.ErrNotFound.Code {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Profile 用户资料文档 profile.json，用户应用服务以自增ID查询，这里先按UUID查出用户
+func (g *userGatewayImpl) Profile(ctx context.Context, userUUID string) (vo.Document, error) {
+	user, err := g.userService.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return vo.Document{}, err
+	}
+	userInfo, err := g.userApp.GetUserInfo(ctx, user.ID())
+	if err != nil {
+		return vo.Document{}, err
+	}
+	return jsonDocument("profile.json", userInfo)
+}
+
+// jsonDocument 以缩进格式序列化导出文档
+func jsonDocument(name string, value interface{}) (vo.Document, error) {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return vo.Document{}, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return vo.NewDocument(name, content), nil
+}
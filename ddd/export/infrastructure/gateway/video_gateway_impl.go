@@ -0,0 +1,212 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/export/domain/gateway"
+	"go-video/ddd/export/domain/vo"
+	"go-video/ddd/video/domain/entity"
+	videoService "go-video/ddd/video/domain/service"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"io"
+	"time"
+)
+
+// exportedVideo videos.json 中的一条视频元数据
+type exportedVideo struct {
+	UUID            string          `json:"uuid"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	Filename        string          `json:"filename"`
+	FileSize        int64           `json:"file_size"`
+	Format          string          `json:"format"`
+	Status          string          `json:"status"`
+	DurationSeconds float64         `json:"duration_seconds,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
+	ReviewStatus    string          `json:"review_status"`
+	ReviewReason    string          `json:"review_reason,omitempty"`
+	ChecksumSHA256  string          `json:"checksum_sha256,omitempty"`
+	CreatedAt       *time.Time      `json:"created_at,omitempty"`
+	Source          *exportedSource `json:"source,omitempty"`
+}
+
+// exportedSource 源文件在导出包内的路径，或大文件的预签名链接
+type exportedSource struct {
+	ArchivePath  string     `json:"archive_path,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+}
+
+// exportedUploadTask activity/uploads.json 中的一条上传记录
+type exportedUploadTask struct {
+	UUID        string     `json:"uuid"`
+	VideoUUID   string     `json:"video_uuid"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	SourceURL   string     `json:"source_url,omitempty"`
+	BatchUUID   string     `json:"batch_uuid,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// exportedTransition activity/status_history.json 中的一条状态变更
+type exportedTransition struct {
+	VideoUUID string     `json:"video_uuid"`
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Cause     string     `json:"cause"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// videoGatewayImpl 通过视频领域服务访问视频上下文
+type videoGatewayImpl struct {
+	videoService videoService.VideoService
+}
+
+// NewVideoGateway 创建视频防腐层实例
+func NewVideoGateway() gateway.VideoGateway {
+	return &videoGatewayImpl{
+		videoService: videoService.DefaultVideoService(),
+	}
+}
+
+// ListSources 列出用户全部视频的源文件，存储中已缺失的源文件跳过，只导出元数据
+func (g *videoGatewayImpl) ListSources(ctx context.Context, userUUID string) ([]*vo.SourceFile, error) {
+	videos, err := g.videoService.ListUserVideos(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]*vo.SourceFile, 0, len(videos))
+	for _, video := range videos {
+		if video.StoragePath() == "" {
+			continue
+		}
+		stat, err := g.videoService.StatSource(ctx, video)
+		if err != nil {
+			logger.Error(fmt.Sprintf("export stat source video_uuid: %s, object: %s, error: %v", video.UUID(), video.StoragePath(), err))
+			continue
+		}
+		sources = append(sources, vo.NewSourceFile(video.UUID(), video.Filename(), stat.Size(), stat.Encrypted()))
+	}
+	return sources, nil
+}
+
+// Documents 视频元数据 videos.json、上传记录 activity/uploads.json 和状态变更 activity/status_history.json
+func (g *videoGatewayImpl) Documents(ctx context.Context, userUUID string, sources []*vo.SourceFile) ([]vo.Document, error) {
+	videos, err := g.videoService.ListUserVideos(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	sourceByVideo := make(map[string]*vo.SourceFile, len(sources))
+	for _, source := range sources {
+		sourceByVideo[source.VideoUUID()] = source
+	}
+
+	exportedVideos := make([]*exportedVideo, 0, len(videos))
+	transitions := make([]*exportedTransition, 0)
+	for _, video := range videos {
+		exportedVideos = append(exportedVideos, toExportedVideo(video, sourceByVideo[video.UUID()]))
+		history, err := g.videoService.StatusHistory(ctx, video.UUID())
+		if err != nil {
+			return nil, err
+		}
+		for _, transition := range history {
+			transitions = append(transitions, &exportedTransition{
+				VideoUUID: transition.VideoUuid(),
+				From:      transition.From().Value(),
+				To:        transition.To().Value(),
+				Cause:     transition.Cause(),
+				CreatedAt: transition.CreatedAt(),
+			})
+		}
+	}
+
+	tasks, err := g.videoService.ListUserUploadTasks(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	uploads := make([]*exportedUploadTask, 0, len(tasks))
+	for _, task := range tasks {
+		uploads = append(uploads, &exportedUploadTask{
+			UUID:        task.UUID(),
+			VideoUUID:   task.VideoUuid(),
+			Status:      task.Status().Value(),
+			Error:       task.ErrorMsg(),
+			SourceURL:   task.SourceURL(),
+			BatchUUID:   task.BatchUuid(),
+			CompletedAt: task.CompletedAt(),
+		})
+	}
+
+	documents := make([]vo.Document, 0, 3)
+	for _, item := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"videos.json", exportedVideos},
+		{"activity/uploads.json", uploads},
+		{"activity/status_history.json", transitions},
+	} {
+		document, err := jsonDocument(item.name, item.value)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// OpenSource 读取源文件明文
+func (g *videoGatewayImpl) OpenSource(ctx context.Context, source *vo.SourceFile) (io.ReadCloser, error) {
+	video, err := g.findVideo(ctx, source.VideoUUID())
+	if err != nil {
+		return nil, err
+	}
+	return g.videoService.OpenSource(ctx, video)
+}
+
+// SourceURL 获取源文件的预签名链接
+func (g *videoGatewayImpl) SourceURL(ctx context.Context, source *vo.SourceFile, expiry time.Duration) (string, error) {
+	video, err := g.findVideo(ctx, source.VideoUUID())
+	if err != nil {
+		return "", err
+	}
+	return g.videoService.SourceURL(ctx, video, expiry)
+}
+
+func (g *videoGatewayImpl) findVideo(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	video, err := g.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	return video, nil
+}
+
+func toExportedVideo(video *entity.Video, source *vo.SourceFile) *exportedVideo {
+	exported := &exportedVideo{
+		UUID:            video.UUID(),
+		Title:           video.Title(),
+		Description:     video.Description(),
+		Filename:        video.Filename(),
+		FileSize:        video.FileSize(),
+		Format:          video.Format(),
+		Status:          video.Status().Value(),
+		DurationSeconds: video.Duration().Seconds(),
+		Tags:            video.Tags(),
+		ReviewStatus:    video.ReviewStatus().Value(),
+		ReviewReason:    video.ReviewReason(),
+		ChecksumSHA256:  video.Checksum().SHA256(),
+		CreatedAt:       video.CreatedAt(),
+	}
+	if source != nil {
+		exported.Source = &exportedSource{
+			ArchivePath:  source.ArchivePath(),
+			DownloadURL:  source.DownloadURL(),
+			URLExpiresAt: source.URLExpiresAt(),
+		}
+	}
+	return exported
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"go-video/ddd/export/domain/gateway"
+	"go-video/ddd/internal/resource"
+	"io"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// archivePartSize 导出包长度未知，按该大小分片上传
+const archivePartSize = 16 << 20
+
+// archiveStoreImpl 导出包存储在视频所用的MinIO存储桶中，位于 exports/ 前缀下
+type archiveStoreImpl struct {
+	minioClient *resource.MinioResource
+}
+
+// NewArchiveStore 创建导出包存储实例
+func NewArchiveStore() gateway.ArchiveStore {
+	return &archiveStoreImpl{
+		minioClient: resource.DefaultMinioResource(),
+	}
+}
+
+// Put 以流的方式写入导出包
+func (s *archiveStoreImpl) Put(ctx context.Context, objectName string, reader io.Reader) error {
+	s.minioClient.MustOpen()
+	_, err := s.minioClient.GetClient().PutObject(ctx, s.minioClient.GetBucketName(), objectName, reader, -1, minio.PutObjectOptions{
+		ContentType: "application/zip",
+		PartSize:    archivePartSize,
+	})
+	return err
+}
+
+// URL 获取导出包的下载链接，浏览器打开时以附件形式下载
+func (s *archiveStoreImpl) URL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
+	s.minioClient.MustOpen()
+	params := url.Values{}
+	params.Set("response-content-disposition", `attachment; filename="`+path.Base(objectName)+`"`)
+	presigned, err := s.minioClient.GetClient().PresignedGetObject(ctx, s.minioClient.GetBucketName(), objectName, expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return presigned.String(), nil
+}
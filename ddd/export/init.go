@@ -0,0 +1,12 @@
+package export
+
+import (
+	"go-video/ddd/export/adapter/http"
+	"go-video/pkg/manager"
+)
+
+// init 包初始化函数，注册个人数据导出控制器插件
+func init() {
+	// 注册导出控制器插件到管理器
+	manager.RegisterControllerPlugin(&http.ExportControllerPlugin{})
+}
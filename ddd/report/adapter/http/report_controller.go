@@ -0,0 +1,145 @@
+package http
+
+import (
+	"sync"
+
+	"go-video/ddd/report/application/app"
+	"go-video/ddd/report/application/cqe"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/manager"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	reportControllerOnce      sync.Once
+	singletonReportController ReportController
+)
+
+// ReportControllerPlugin 举报控制器插件
+type ReportControllerPlugin struct {
+}
+
+func (p *ReportControllerPlugin) Name() string {
+	return "reportControllerPlugin"
+}
+
+func (p *ReportControllerPlugin) MustCreateController() manager.Controller {
+	return DefaultReportController()
+}
+
+type ReportController interface {
+	manager.Controller
+	SubmitReport(ctx *gin.Context)
+}
+
+type reportControllerImpl struct {
+	reportApp app.ReportApp
+}
+
+func DefaultReportController() ReportController {
+	assert.NotCircular()
+	reportControllerOnce.Do(func() {
+		singletonReportController = &reportControllerImpl{
+			reportApp: app.DefaultReportApp(),
+		}
+	})
+	assert.NotNil(singletonReportController)
+	return singletonReportController
+}
+
+// RegisterOpenApi 注册开放API
+func (c *reportControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+	v1 := router.Group("/v1", middleware.AuthRequired())
+	{
+		v1.POST("/reports", c.SubmitReport)
+	}
+}
+
+// RegisterInnerApi 注册内部API
+func (c *reportControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+}
+
+// RegisterDebugApi 注册调试API
+func (c *reportControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+}
+
+// RegisterOpsApi 注册运维API
+func (c *reportControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+	// 举报处理允许审核员和管理员，禁用账号在应用层另外要求管理员
+	reports := router.Group("/v1/reports", middleware.AuthRequired(), middleware.ModeratorRequired())
+	{
+		reports.GET("", c.ListOpenReports)
+		reports.GET("/:target_type/:target_uuid", c.GetTargetReports)
+		reports.POST("/:target_type/:target_uuid/resolve", c.ResolveReports)
+	}
+}
+
+// SubmitReport 举报视频或用户
+func (c *reportControllerImpl) SubmitReport(ctx *gin.Context) {
+	var cmd cqe.SubmitReportCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.ReporterUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.reportApp.SubmitReport(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ListOpenReports 按对象聚合的待处理举报列表
+func (c *reportControllerImpl) ListOpenReports(ctx *gin.Context) {
+	var query cqe.ListReportGroupsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "query"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.reportApp.ListOpenReports(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetTargetReports 获取对象的举报和处理历史
+func (c *reportControllerImpl) GetTargetReports(ctx *gin.Context) {
+	var query cqe.GetTargetReportsQuery
+	if err := ctx.ShouldBindUri(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "target"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.reportApp.GetTargetReports(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ResolveReports 处理对象下所有待处理的举报
+func (c *reportControllerImpl) ResolveReports(ctx *gin.Context) {
+	var cmd cqe.ResolveReportsCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.TargetType = ctx.Param("target_type")
+	cmd.TargetUUID = ctx.Param("target_uuid")
+	result, err := c.reportApp.ResolveReports(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
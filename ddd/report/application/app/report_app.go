@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/report/application/cqe"
+	"go-video/ddd/report/application/dto"
+	"go-video/ddd/report/domain/entity"
+	"go-video/ddd/report/domain/gateway"
+	"go-video/ddd/report/domain/repo"
+	"go-video/ddd/report/domain/vo"
+	"go-video/ddd/report/infrastructure/database/persistence"
+	reportGateway "go-video/ddd/report/infrastructure/gateway"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultReportRateLimit 未配置时单个用户在时间窗口内最多提交的举报数
+	defaultReportRateLimit = 10
+	// defaultReportRateWindow 未配置时的限流时间窗口
+	defaultReportRateWindow = time.Hour
+)
+
+var (
+	onceReportApp      sync.Once
+	singletonReportApp ReportApp
+)
+
+// ReportApp 举报应用服务
+type ReportApp interface {
+	SubmitReport(ctx context.Context, cmd *cqe.SubmitReportCommand) (*dto.ReportDto, error)
+	ListOpenReports(ctx context.Context, query *cqe.ListReportGroupsQuery) (*dto.ReportGroupListDto, error)
+	GetTargetReports(ctx context.Context, query *cqe.GetTargetReportsQuery) (*dto.TargetReportsDto, error)
+	ResolveReports(ctx context.Context, cmd *cqe.ResolveReportsCommand) (*dto.ReportActionDto, error)
+	ListReportsByReporter(ctx context.Context, reporterUUID string) ([]*dto.ReportDto, error)
+}
+
+type reportApp struct {
+	reportRepo   repo.ReportRepository
+	videoGateway gateway.VideoGateway
+	userGateway  gateway.UserGateway
+}
+
+func DefaultReportApp() ReportApp {
+	assert.NotCircular()
+	onceReportApp.Do(func() {
+		singletonReportApp = &reportApp{
+			reportRepo:   persistence.NewReportRepository(),
+			videoGateway: reportGateway.NewVideoGateway(),
+			userGateway:  reportGateway.NewUserGateway(),
+		}
+	})
+	assert.NotNil(singletonReportApp)
+	return singletonReportApp
+}
+
+// SubmitReport 提交举报：按用户限流，同一用户对同一对象只能有一条待处理举报
+func (a *reportApp) SubmitReport(ctx context.Context, cmd *cqe.SubmitReportCommand) (*dto.ReportDto, error) {
+	targetType, reasonCode, err := cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	limit, window := reportRateLimit()
+	count, err := a.reportRepo.CountByReporterSince(ctx, cmd.ReporterUUID, time.Now().Add(-window))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if count >= int64(limit) {
+		return nil, errno.NewSimpleBizError(errno.ErrReportRateLimited, nil)
+	}
+
+	exists, err := a.reportRepo.ExistsOpen(ctx, cmd.ReporterUUID, targetType, cmd.TargetUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if exists {
+		return nil, errno.NewSimpleBizError(errno.ErrReportDuplicate, nil)
+	}
+
+	found, err := a.targetExists(ctx, targetType, cmd.TargetUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errno.NewSimpleBizError(errno.ErrReportTarget, nil)
+	}
+
+	report := entity.DefaultReport(cmd.ReporterUUID, targetType, cmd.TargetUUID, reasonCode, cmd.Description)
+	if err := a.reportRepo.Save(ctx, report); err != nil {
+		// 并发提交时由 open_key 唯一索引兜底，这里再确认一次是否为重复举报
+		if exists, existsErr := a.reportRepo.ExistsOpen(ctx, cmd.ReporterUUID, targetType, cmd.TargetUUID); existsErr == nil && exists {
+			return nil, errno.NewSimpleBizError(errno.ErrReportDuplicate, nil)
+		}
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toReportDto(report), nil
+}
+
+// ListOpenReports 按对象聚合的待处理举报列表
+func (a *reportApp) ListOpenReports(ctx context.Context, query *cqe.ListReportGroupsQuery) (*dto.ReportGroupListDto, error) {
+	if !config.IsModerator(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	var typeFilter *vo.TargetType
+	if query.TargetType != "" {
+		targetType, ok := vo.NewTargetType(query.TargetType)
+		if !ok {
+			return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "target_type")
+		}
+		typeFilter = &targetType
+	}
+
+	page := vo.NewPage(query.Page, query.PageSize)
+	groups, total, err := a.reportRepo.FindOpenGroups(ctx, typeFilter, page)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	groupDtos := make([]*dto.ReportGroupDto, 0, len(groups))
+	for _, group := range groups {
+		groupDtos = append(groupDtos, &dto.ReportGroupDto{
+			TargetType:  group.TargetType().Value(),
+			TargetUUID:  group.TargetUuid(),
+			ReportCount: group.ReportCount(),
+			FirstAt:     group.FirstAt(),
+			LastAt:      group.LastAt(),
+		})
+	}
+	return &dto.ReportGroupListDto{
+		Groups:   groupDtos,
+		Total:    total,
+		Page:     page.Page(),
+		PageSize: page.PageSize(),
+	}, nil
+}
+
+// GetTargetReports 获取对象的全部举报和处理历史
+func (a *reportApp) GetTargetReports(ctx context.Context, query *cqe.GetTargetReportsQuery) (*dto.TargetReportsDto, error) {
+	if !config.IsModerator(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	targetType, ok := vo.NewTargetType(query.TargetType)
+	if !ok {
+		return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "target_type")
+	}
+
+	reports, err := a.reportRepo.FindByTarget(ctx, targetType, query.TargetUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	actions, err := a.reportRepo.FindActionsByTarget(ctx, targetType, query.TargetUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+
+	result := &dto.TargetReportsDto{
+		TargetType: targetType.Value(),
+		TargetUUID: query.TargetUUID,
+		Reports:    make([]*dto.ReportDto, 0, len(reports)),
+		History:    make([]*dto.ReportActionDto, 0, len(actions)),
+	}
+	for _, report := range reports {
+		result.Reports = append(result.Reports, toReportDto(report))
+	}
+	for _, action := range actions {
+		result.History = append(result.History, toReportActionDto(action))
+	}
+	return result, nil
+}
+
+// ResolveReports 处理对象下所有待处理的举报：先执行处理动作，再关闭举报并记录历史
+func (a *reportApp) ResolveReports(ctx context.Context, cmd *cqe.ResolveReportsCommand) (*dto.ReportActionDto, error) {
+	targetType, action, err := cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !config.IsModerator(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	// 禁用账号影响用户的全部内容，只有管理员可以执行
+	if action == vo.ResolutionActionDisableUser && !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+
+	reports, err := a.reportRepo.FindByTarget(ctx, targetType, cmd.TargetUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !hasOpenReport(reports) {
+		return nil, errno.NewSimpleBizError(errno.ErrReportNotFound, nil)
+	}
+
+	switch action {
+	case vo.ResolutionActionHideVideo:
+		if err := a.videoGateway.HideVideo(ctx, cmd.TargetUUID, cmd.Note); err != nil {
+			return nil, err
+		}
+	case vo.ResolutionActionDisableUser:
+		if err := a.userGateway.DisableUser(ctx, cmd.TargetUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	reportAction := entity.DefaultReportAction(targetType, cmd.TargetUUID, action, cmd.OperatorUUID, cmd.Note)
+	resolved, err := a.reportRepo.Resolve(ctx, reportAction)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if resolved == 0 {
+		// 并发处理时举报已被他人关闭
+		return nil, errno.NewSimpleBizError(errno.ErrReportNotFound, nil)
+	}
+	logger.Info(fmt.Sprintf("reports on %s %s resolved by %s: %s (%d reports)",
+		targetType.Value(), cmd.TargetUUID, cmd.OperatorUUID, action.Value(), resolved))
+	return toReportActionDto(reportAction), nil
+}
+
+// ListReportsByReporter 获取用户提交的全部举报，用于个人数据导出
+func (a *reportApp) ListReportsByReporter(ctx context.Context, reporterUUID string) ([]*dto.ReportDto, error) {
+	reports, err := a.reportRepo.FindByReporter(ctx, reporterUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	reportDtos := make([]*dto.ReportDto, 0, len(reports))
+	for _, report := range reports {
+		reportDtos = append(reportDtos, toReportDto(report))
+	}
+	return reportDtos, nil
+}
+
+func (a *reportApp) targetExists(ctx context.Context, targetType vo.TargetType, targetUUID string) (bool, error) {
+	if targetType == vo.TargetTypeUser {
+		return a.userGateway.Exists(ctx, targetUUID)
+	}
+	return a.videoGateway.Exists(ctx, targetUUID)
+}
+
+func hasOpenReport(reports []*entity.Report) bool {
+	for _, report := range reports {
+		if report.Status() == vo.ReportStatusOpen {
+			return true
+		}
+	}
+	return false
+}
+
+// reportRateLimit 举报限流配置
+func reportRateLimit() (int, time.Duration) {
+	limit, window := defaultReportRateLimit, defaultReportRateWindow
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return limit, window
+	}
+	if cfg.Report.RateLimit > 0 {
+		limit = cfg.Report.RateLimit
+	}
+	if cfg.Report.RateWindow > 0 {
+		window = cfg.Report.RateWindow
+	}
+	return limit, window
+}
+
+func toReportDto(report *entity.Report) *dto.ReportDto {
+	return &dto.ReportDto{
+		ReportUUID:   report.UUID(),
+		ReporterUUID: report.ReporterUuid(),
+		TargetType:   report.TargetType().Value(),
+		TargetUUID:   report.TargetUuid(),
+		ReasonCode:   report.ReasonCode().Value(),
+		Description:  report.Description(),
+		Status:       report.Status().Value(),
+		ActionUUID:   report.ActionUuid(),
+		CreatedAt:    report.CreatedAt(),
+	}
+}
+
+func toReportActionDto(action *entity.ReportAction) *dto.ReportActionDto {
+	return &dto.ReportActionDto{
+		ActionUUID:   action.UUID(),
+		TargetType:   action.TargetType().Value(),
+		TargetUUID:   action.TargetUuid(),
+		Action:       action.Action().Value(),
+		OperatorUUID: action.OperatorUuid(),
+		Note:         action.Note(),
+		ReportCount:  action.ReportCount(),
+		CreatedAt:    action.CreatedAt(),
+	}
+}
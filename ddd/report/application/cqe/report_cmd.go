@@ -0,0 +1,89 @@
+package cqe
+
+import (
+	"go-video/ddd/report/domain/vo"
+	"go-video/pkg/errno"
+)
+
+// maxReportDescriptionLength 举报描述最大长度
+const maxReportDescriptionLength = 1000
+
+// SubmitReportCommand 提交举报命令
+type SubmitReportCommand struct {
+	ReporterUUID string `json:"-"`
+	TargetType   string `json:"target_type"` // video/user
+	TargetUUID   string `json:"target_uuid"`
+	ReasonCode   string `json:"reason_code"` // 举报原因，见 vo.ReasonCodes
+	Description  string `json:"description"` // 补充说明
+}
+
+// Validate 校验举报参数
+func (c *SubmitReportCommand) Validate() (vo.TargetType, vo.ReasonCode, error) {
+	if len(c.ReporterUUID) == 0 || len(c.TargetUUID) == 0 {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	targetType, ok := vo.NewTargetType(c.TargetType)
+	if !ok {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "target_type")
+	}
+	reasonCode, ok := vo.NewReasonCode(c.ReasonCode)
+	if !ok {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "reason_code")
+	}
+	if reasonCode == vo.ReasonCodeOther && len(c.Description) == 0 {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len([]rune(c.Description)) > maxReportDescriptionLength {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	if targetType == vo.TargetTypeUser && c.TargetUUID == c.ReporterUUID {
+		return vo.TargetType{}, vo.ReasonCode{}, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "target_uuid")
+	}
+	return targetType, reasonCode, nil
+}
+
+// ListReportGroupsQuery 按对象聚合的待处理举报查询
+type ListReportGroupsQuery struct {
+	OperatorUUID string `form:"-"`
+	TargetType   string `form:"target_type"` // 为空表示全部类型
+	Page         int    `form:"page"`
+	PageSize     int    `form:"page_size"`
+}
+
+// GetTargetReportsQuery 获取对象举报详情查询
+type GetTargetReportsQuery struct {
+	OperatorUUID string `uri:"-"`
+	TargetType   string `uri:"target_type" binding:"required"`
+	TargetUUID   string `uri:"target_uuid" binding:"required"`
+}
+
+// ResolveReportsCommand 处理对象下所有待处理举报的命令
+type ResolveReportsCommand struct {
+	OperatorUUID string `json:"-"`
+	TargetType   string `json:"-"`
+	TargetUUID   string `json:"-"`
+	Action       string `json:"action"` // dismiss/hide_video/disable_user
+	Note         string `json:"note"`   // 处理备注，下架视频时会作为原因展示给上传者
+}
+
+// Validate 校验处理参数
+func (c *ResolveReportsCommand) Validate() (vo.TargetType, vo.ResolutionAction, error) {
+	if len(c.OperatorUUID) == 0 || len(c.TargetUUID) == 0 {
+		return vo.TargetType{}, vo.ResolutionAction{}, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	targetType, ok := vo.NewTargetType(c.TargetType)
+	if !ok {
+		return vo.TargetType{}, vo.ResolutionAction{}, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "target_type")
+	}
+	action, ok := vo.NewResolutionAction(c.Action)
+	if !ok {
+		return vo.TargetType{}, vo.ResolutionAction{}, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "action")
+	}
+	if !action.AppliesTo(targetType) {
+		return vo.TargetType{}, vo.ResolutionAction{}, errno.NewSimpleBizError(errno.ErrReportAction, nil, action.Value())
+	}
+	if len([]rune(c.Note)) > 500 {
+		return vo.TargetType{}, vo.ResolutionAction{}, errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	return targetType, action, nil
+}
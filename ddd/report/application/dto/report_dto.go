@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// ReportDto 举报
+type ReportDto struct {
+	ReportUUID   string     `json:"report_uuid"`
+	ReporterUUID string     `json:"reporter_uuid"`
+	TargetType   string     `json:"target_type"`
+	TargetUUID   string     `json:"target_uuid"`
+	ReasonCode   string     `json:"reason_code"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`
+	ActionUUID   string     `json:"action_uuid,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// ReportGroupDto 按对象聚合的待处理举报
+type ReportGroupDto struct {
+	TargetType  string     `json:"target_type"`
+	TargetUUID  string     `json:"target_uuid"`
+	ReportCount int64      `json:"report_count"`
+	FirstAt     *time.Time `json:"first_reported_at"`
+	LastAt      *time.Time `json:"last_reported_at"`
+}
+
+// ReportGroupListDto 待处理举报列表
+type ReportGroupListDto struct {
+	Groups   []*ReportGroupDto `json:"groups"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// ReportActionDto 举报处理记录
+type ReportActionDto struct {
+	ActionUUID   string     `json:"action_uuid"`
+	TargetType   string     `json:"target_type"`
+	TargetUUID   string     `json:"target_uuid"`
+	Action       string     `json:"action"`
+	OperatorUUID string     `json:"operator_uuid"`
+	Note         string     `json:"note"`
+	ReportCount  int64      `json:"report_count"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// TargetReportsDto 对象的举报及处理历史
+type TargetReportsDto struct {
+	TargetType string             `json:"target_type"`
+	TargetUUID string             `json:"target_uuid"`
+	Reports    []*ReportDto       `json:"reports"`
+	History    []*ReportActionDto `json:"history"`
+}
@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"go-video/ddd/report/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report 举报实体
+type Report struct {
+	uuid         string
+	reporterUuid string
+	targetType   vo.TargetType
+	targetUuid   string
+	reasonCode   vo.ReasonCode
+	description  string
+	status       vo.ReportStatus
+	actionUuid   string
+	createdAt    *time.Time
+}
+
+// DefaultReport 创建新的举报
+func DefaultReport(reporterUuid string, targetType vo.TargetType, targetUuid string, reasonCode vo.ReasonCode, description string) *Report {
+	return &Report{
+		uuid:         uuid.New().String(),
+		reporterUuid: reporterUuid,
+		targetType:   targetType,
+		targetUuid:   targetUuid,
+		reasonCode:   reasonCode,
+		description:  description,
+		status:       vo.ReportStatusOpen,
+	}
+}
+
+// NewReport 创建举报（用于从数据库加载）
+func NewReport(uuid, reporterUuid string, targetType vo.TargetType, targetUuid string, reasonCode vo.ReasonCode, description string,
+	status vo.ReportStatus, actionUuid string, createdAt *time.Time) *Report {
+	return &Report{
+		uuid:         uuid,
+		reporterUuid: reporterUuid,
+		targetType:   targetType,
+		targetUuid:   targetUuid,
+		reasonCode:   reasonCode,
+		description:  description,
+		status:       status,
+		actionUuid:   actionUuid,
+		createdAt:    createdAt,
+	}
+}
+
+// UUID 获取举报UUID
+func (r *Report) UUID() string {
+	return r.uuid
+}
+
+// ReporterUuid 获取举报人UUID
+func (r *Report) ReporterUuid() string {
+	return r.reporterUuid
+}
+
+// TargetType 获取举报对象类型
+func (r *Report) TargetType() vo.TargetType {
+	return r.targetType
+}
+
+// TargetUuid 获取举报对象UUID
+func (r *Report) TargetUuid() string {
+	return r.targetUuid
+}
+
+// ReasonCode 获取举报原因
+func (r *Report) ReasonCode() vo.ReasonCode {
+	return r.reasonCode
+}
+
+// Description 获取举报描述
+func (r *Report) Description() string {
+	return r.description
+}
+
+// Status 获取举报状态
+func (r *Report) Status() vo.ReportStatus {
+	return r.status
+}
+
+// ActionUuid 获取处理记录UUID，未处理为空
+func (r *Report) ActionUuid() string {
+	return r.actionUuid
+}
+
+// CreatedAt 获取举报时间
+func (r *Report) CreatedAt() *time.Time {
+	return r.createdAt
+}
+
+// ReportGroup 按举报对象聚合的待处理举报
+type ReportGroup struct {
+	targetType  vo.TargetType
+	targetUuid  string
+	reportCount int64
+	firstAt     *time.Time
+	lastAt      *time.Time
+}
+
+// NewReportGroup 创建举报聚合
+func NewReportGroup(targetType vo.TargetType, targetUuid string, reportCount int64, firstAt, lastAt *time.Time) *ReportGroup {
+	return &ReportGroup{
+		targetType:  targetType,
+		targetUuid:  targetUuid,
+		reportCount: reportCount,
+		firstAt:     firstAt,
+		lastAt:      lastAt,
+	}
+}
+
+// TargetType 获取举报对象类型
+func (g *ReportGroup) TargetType() vo.TargetType {
+	return g.targetType
+}
+
+// TargetUuid 获取举报对象UUID
+func (g *ReportGroup) TargetUuid() string {
+	return g.targetUuid
+}
+
+// ReportCount 获取待处理举报数
+func (g *ReportGroup) ReportCount() int64 {
+	return g.reportCount
+}
+
+// FirstAt 获取最早举报时间
+func (g *ReportGroup) FirstAt() *time.Time {
+	return g.firstAt
+}
+
+// LastAt 获取最近举报时间
+func (g *ReportGroup) LastAt() *time.Time {
+	return g.lastAt
+}
+
+// ReportAction 举报处理记录，一次处理会关闭该对象下所有待处理的举报
+type ReportAction struct {
+	uuid         string
+	targetType   vo.TargetType
+	targetUuid   string
+	action       vo.ResolutionAction
+	operatorUuid string
+	note         string
+	reportCount  int64
+	createdAt    *time.Time
+}
+
+// DefaultReportAction 创建新的处理记录
+func DefaultReportAction(targetType vo.TargetType, targetUuid string, action vo.ResolutionAction, operatorUuid, note string) *ReportAction {
+	return &ReportAction{
+		uuid:         uuid.New().String(),
+		targetType:   targetType,
+		targetUuid:   targetUuid,
+		action:       action,
+		operatorUuid: operatorUuid,
+		note:         note,
+	}
+}
+
+// NewReportAction 创建处理记录（用于从数据库加载）
+func NewReportAction(uuid string, targetType vo.TargetType, targetUuid string, action vo.ResolutionAction, operatorUuid, note string,
+	reportCount int64, createdAt *time.Time) *ReportAction {
+	return &ReportAction{
+		uuid:         uuid,
+		targetType:   targetType,
+		targetUuid:   targetUuid,
+		action:       action,
+		operatorUuid: operatorUuid,
+		note:         note,
+		reportCount:  reportCount,
+		createdAt:    createdAt,
+	}
+}
+
+// UUID 获取处理记录UUID
+func (a *ReportAction) UUID() string {
+	return a.uuid
+}
+
+// TargetType 获取举报对象类型
+func (a *ReportAction) TargetType() vo.TargetType {
+	return a.targetType
+}
+
+// TargetUuid 获取举报对象UUID
+func (a *ReportAction) TargetUuid() string {
+	return a.targetUuid
+}
+
+// Action 获取处理动作
+func (a *ReportAction) Action() vo.ResolutionAction {
+	return a.action
+}
+
+// OperatorUuid 获取处理人UUID
+func (a *ReportAction) OperatorUuid() string {
+	return a.operatorUuid
+}
+
+// Note 获取处理备注
+func (a *ReportAction) Note() string {
+	return a.note
+}
+
+// ReportCount 获取本次关闭的举报数
+func (a *ReportAction) ReportCount() int64 {
+	return a.reportCount
+}
+
+// SetReportCount 设置本次关闭的举报数
+func (a *ReportAction) SetReportCount(count int64) {
+	a.reportCount = count
+}
+
+// CreatedAt 获取处理时间
+func (a *ReportAction) CreatedAt() *time.Time {
+	return a.createdAt
+}
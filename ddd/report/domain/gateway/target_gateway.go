@@ -0,0 +1,19 @@
+package gateway
+
+import "context"
+
+// VideoGateway 视频上下文防腐层
+type VideoGateway interface {
+	// Exists 检查视频是否存在
+	Exists(ctx context.Context, videoUUID string) (bool, error)
+	// HideVideo 下架视频
+	HideVideo(ctx context.Context, videoUUID string, reason string) error
+}
+
+// UserGateway 用户上下文防腐层
+type UserGateway interface {
+	// Exists 检查用户是否存在
+	Exists(ctx context.Context, userUUID string) (bool, error)
+	// DisableUser 禁用用户
+	DisableUser(ctx context.Context, userUUID string) error
+}
@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/report/domain/entity"
+	"go-video/ddd/report/domain/vo"
+	"time"
+)
+
+// ReportRepository 举报仓储接口
+type ReportRepository interface {
+	// Save 保存举报
+	Save(ctx context.Context, report *entity.Report) error
+	// CountByReporterSince 统计举报人在指定时间之后提交的举报数
+	CountByReporterSince(ctx context.Context, reporterUUID string, since time.Time) (int64, error)
+	// ExistsOpen 检查举报人对该对象是否已有待处理的举报
+	ExistsOpen(ctx context.Context, reporterUUID string, targetType vo.TargetType, targetUUID string) (bool, error)
+	// FindOpenGroups 分页查找按对象聚合的待处理举报，举报数多的在前
+	FindOpenGroups(ctx context.Context, targetType *vo.TargetType, page *vo.Page) ([]*entity.ReportGroup, int64, error)
+	// FindByTarget 查找对象的举报（包括已处理的）
+	FindByTarget(ctx context.Context, targetType vo.TargetType, targetUUID string) ([]*entity.Report, error)
+	// FindByReporter 查找举报人提交的全部举报
+	FindByReporter(ctx context.Context, reporterUUID string) ([]*entity.Report, error)
+	// Resolve 关闭对象下所有待处理的举报并写入处理记录，返回关闭的举报数
+	Resolve(ctx context.Context, action *entity.ReportAction) (int64, error)
+	// FindActionsByTarget 查找对象的处理历史
+	FindActionsByTarget(ctx context.Context, targetType vo.TargetType, targetUUID string) ([]*entity.ReportAction, error)
+}
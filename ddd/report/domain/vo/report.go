@@ -0,0 +1,161 @@
+package vo
+
+// TargetType 举报对象类型
+type TargetType struct {
+	value string
+}
+
+var (
+	// TargetTypeVideo 视频
+	TargetTypeVideo = TargetType{
+		"video",
+	}
+	// TargetTypeUser 用户
+	TargetTypeUser = TargetType{
+		"user",
+	}
+)
+
+var TargetTypes = []TargetType{
+	TargetTypeVideo,
+	TargetTypeUser,
+}
+
+// NewTargetType 根据字符串创建举报对象类型
+func NewTargetType(value string) (TargetType, bool) {
+	for _, targetType := range TargetTypes {
+		if targetType.value == value {
+			return targetType, true
+		}
+	}
+	return TargetType{}, false
+}
+
+// Value 返回类型的字符串值
+func (t TargetType) Value() string {
+	return t.value
+}
+
+// ReasonCode 举报原因
+type ReasonCode struct {
+	value string
+}
+
+var (
+	ReasonCodeSpam          = ReasonCode{"spam"}
+	ReasonCodeHarassment    = ReasonCode{"harassment"}
+	ReasonCodeHateSpeech    = ReasonCode{"hate_speech"}
+	ReasonCodeViolence      = ReasonCode{"violence"}
+	ReasonCodeSexualContent = ReasonCode{"sexual_content"}
+	ReasonCodeCopyright     = ReasonCode{"copyright"}
+	ReasonCodeImpersonation = ReasonCode{"impersonation"}
+	ReasonCodeOther         = ReasonCode{"other"}
+)
+
+var ReasonCodes = []ReasonCode{
+	ReasonCodeSpam,
+	ReasonCodeHarassment,
+	ReasonCodeHateSpeech,
+	ReasonCodeViolence,
+	ReasonCodeSexualContent,
+	ReasonCodeCopyright,
+	ReasonCodeImpersonation,
+	ReasonCodeOther,
+}
+
+// NewReasonCode 根据字符串创建举报原因
+func NewReasonCode(value string) (ReasonCode, bool) {
+	for _, code := range ReasonCodes {
+		if code.value == value {
+			return code, true
+		}
+	}
+	return ReasonCode{}, false
+}
+
+// Value 返回原因的字符串值
+func (c ReasonCode) Value() string {
+	return c.value
+}
+
+// ReportStatus 举报状态
+type ReportStatus struct {
+	value string
+}
+
+var (
+	// ReportStatusOpen 待处理
+	ReportStatusOpen = ReportStatus{
+		"open",
+	}
+	// ReportStatusResolved 已处理
+	ReportStatusResolved = ReportStatus{
+		"resolved",
+	}
+)
+
+// NewReportStatus 根据字符串创建举报状态
+func NewReportStatus(value string) ReportStatus {
+	if value == ReportStatusResolved.value {
+		return ReportStatusResolved
+	}
+	return ReportStatusOpen
+}
+
+// Value 返回状态的字符串值
+func (s ReportStatus) Value() string {
+	return s.value
+}
+
+// ResolutionAction 举报处理动作
+type ResolutionAction struct {
+	value string
+}
+
+var (
+	// ResolutionActionDismiss 驳回举报，不做处理
+	ResolutionActionDismiss = ResolutionAction{
+		"dismiss",
+	}
+	// ResolutionActionHideVideo 下架被举报的视频
+	ResolutionActionHideVideo = ResolutionAction{
+		"hide_video",
+	}
+	// ResolutionActionDisableUser 禁用被举报的用户
+	ResolutionActionDisableUser = ResolutionAction{
+		"disable_user",
+	}
+)
+
+var ResolutionActions = []ResolutionAction{
+	ResolutionActionDismiss,
+	ResolutionActionHideVideo,
+	ResolutionActionDisableUser,
+}
+
+// NewResolutionAction 根据字符串创建处理动作
+func NewResolutionAction(value string) (ResolutionAction, bool) {
+	for _, action := range ResolutionActions {
+		if action.value == value {
+			return action, true
+		}
+	}
+	return ResolutionAction{}, false
+}
+
+// Value 返回动作的字符串值
+func (a ResolutionAction) Value() string {
+	return a.value
+}
+
+// AppliesTo 检查处理动作是否适用于该举报对象类型
+func (a ResolutionAction) AppliesTo(targetType TargetType) bool {
+	switch a {
+	case ResolutionActionHideVideo:
+		return targetType == TargetTypeVideo
+	case ResolutionActionDisableUser:
+		return targetType == TargetTypeUser
+	default:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+package convertor
+
+import (
+	"fmt"
+	"go-video/ddd/report/domain/entity"
+	"go-video/ddd/report/domain/vo"
+	"go-video/ddd/report/infrastructure/database/po"
+)
+
+// ReportConvertor 举报转换器
+type ReportConvertor struct{}
+
+// NewReportConvertor 创建举报转换器实例
+func NewReportConvertor() *ReportConvertor {
+	return &ReportConvertor{}
+}
+
+// OpenKey 待处理举报的唯一键
+func (c *ReportConvertor) OpenKey(reporterUUID string, targetType vo.TargetType, targetUUID string) string {
+	return fmt.Sprintf("%s:%s:%s", reporterUUID, targetType.Value(), targetUUID)
+}
+
+// EntityToPO 举报实体转PO
+func (c *ReportConvertor) EntityToPO(report *entity.Report) *po.ReportPo {
+	if report == nil {
+		return nil
+	}
+	reportPo := &po.ReportPo{
+		UUID:         report.UUID(),
+		ReporterUUID: report.ReporterUuid(),
+		TargetType:   report.TargetType().Value(),
+		TargetUUID:   report.TargetUuid(),
+		ReasonCode:   report.ReasonCode().Value(),
+		Description:  report.Description(),
+		Status:       report.Status().Value(),
+		ActionUUID:   report.ActionUuid(),
+	}
+	if report.Status() == vo.ReportStatusOpen {
+		openKey := c.OpenKey(report.ReporterUuid(), report.TargetType(), report.TargetUuid())
+		reportPo.OpenKey = &openKey
+	}
+	return reportPo
+}
+
+// POToEntity 举报PO转实体
+func (c *ReportConvertor) POToEntity(reportPo *po.ReportPo) *entity.Report {
+	if reportPo == nil {
+		return nil
+	}
+	targetType, _ := vo.NewTargetType(reportPo.TargetType)
+	reasonCode, _ := vo.NewReasonCode(reportPo.ReasonCode)
+	return entity.NewReport(reportPo.UUID, reportPo.ReporterUUID, targetType, reportPo.TargetUUID, reasonCode,
+		reportPo.Description, vo.NewReportStatus(reportPo.Status), reportPo.ActionUUID, reportPo.CreatedAt)
+}
+
+// GroupRowToEntity 聚合查询结果转实体
+func (c *ReportConvertor) GroupRowToEntity(row *po.ReportGroupRow) *entity.ReportGroup {
+	targetType, _ := vo.NewTargetType(row.TargetType)
+	return entity.NewReportGroup(targetType, row.TargetUUID, row.ReportCount, row.FirstAt, row.LastAt)
+}
+
+// ActionEntityToPO 处理记录实体转PO
+func (c *ReportConvertor) ActionEntityToPO(action *entity.ReportAction) *po.ReportActionPo {
+	if action == nil {
+		return nil
+	}
+	return &po.ReportActionPo{
+		UUID:         action.UUID(),
+		TargetType:   action.TargetType().Value(),
+		TargetUUID:   action.TargetUuid(),
+		Action:       action.Action().Value(),
+		OperatorUUID: action.OperatorUuid(),
+		Note:         action.Note(),
+		ReportCount:  action.ReportCount(),
+	}
+}
+
+// ActionPOToEntity 处理记录PO转实体
+func (c *ReportConvertor) ActionPOToEntity(actionPo *po.ReportActionPo) *entity.ReportAction {
+	if actionPo == nil {
+		return nil
+	}
+	targetType, _ := vo.NewTargetType(actionPo.TargetType)
+	action, _ := vo.NewResolutionAction(actionPo.Action)
+	return entity.NewReportAction(actionPo.UUID, targetType, actionPo.TargetUUID, action, actionPo.OperatorUUID,
+		actionPo.Note, actionPo.ReportCount, actionPo.CreatedAt)
+}
@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/report/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportDao struct {
+	db *gorm.DB
+}
+
+func NewReportDao() *ReportDao {
+	return &ReportDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// Create 创建举报
+func (d *ReportDao) Create(ctx context.Context, reportPo *po.ReportPo) error {
+	return d.db.WithContext(ctx).Create(reportPo).Error
+}
+
+// CountByReporterSince 统计举报人在指定时间之后提交的举报数
+func (d *ReportDao) CountByReporterSince(ctx context.Context, reporterUUID string, since time.Time) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&po.ReportPo{}).
+		Where("reporter_uuid = ? AND created_at >= ? AND is_deleted = 0", reporterUUID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// ExistsOpen 检查举报人对该对象是否已有待处理的举报
+func (d *ReportDao) ExistsOpen(ctx context.Context, openKey string) (bool, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&po.ReportPo{}).
+		Where("open_key = ? AND is_deleted = 0", openKey).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (d *ReportDao) openQuery(ctx context.Context, targetType string) *gorm.DB {
+	query := d.db.WithContext(ctx).Model(&po.ReportPo{}).Where("status = ? AND is_deleted = 0", "open")
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	return query
+}
+
+// GetOpenGroups 分页获取按对象聚合的待处理举报
+func (d *ReportDao) GetOpenGroups(ctx context.Context, targetType string, offset, limit int) ([]*po.ReportGroupRow, error) {
+	var rows []*po.ReportGroupRow
+	err := d.openQuery(ctx, targetType).
+		Select("target_type, target_uuid, COUNT(*) AS report_count, MIN(created_at) AS first_at, MAX(created_at) AS last_at").
+		Group("target_type, target_uuid").
+		Order("report_count DESC, first_at ASC").
+		Offset(offset).Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountOpenGroups 统计有待处理举报的对象数
+func (d *ReportDao) CountOpenGroups(ctx context.Context, targetType string) (int64, error) {
+	var count int64
+	err := d.openQuery(ctx, targetType).Distinct("target_type", "target_uuid").Count(&count).Error
+	return count, err
+}
+
+// GetByTarget 获取对象的举报，最新的在前
+func (d *ReportDao) GetByTarget(ctx context.Context, targetType, targetUUID string) ([]*po.ReportPo, error) {
+	var reportPos []*po.ReportPo
+	err := d.db.WithContext(ctx).Order("id DESC").
+		Find(&reportPos, "target_type = ? AND target_uuid = ? AND is_deleted = 0", targetType, targetUUID).Error
+	return reportPos, err
+}
+
+// GetByReporter 获取举报人提交的全部举报，按提交先后排序
+func (d *ReportDao) GetByReporter(ctx context.Context, reporterUUID string) ([]*po.ReportPo, error) {
+	var reportPos []*po.ReportPo
+	err := d.db.WithContext(ctx).Order("id ASC").
+		Find(&reportPos, "reporter_uuid = ? AND is_deleted = 0", reporterUUID).Error
+	return reportPos, err
+}
+
+// Resolve 在事务中关闭对象下所有待处理的举报并写入处理记录，没有待处理举报时不写入
+func (d *ReportDao) Resolve(ctx context.Context, actionPo *po.ReportActionPo) (int64, error) {
+	var resolved int64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.ReportPo{}).
+			Where("target_type = ? AND target_uuid = ? AND status = ? AND is_deleted = 0", actionPo.TargetType, actionPo.TargetUUID, "open").
+			Updates(map[string]interface{}{
+				"status":      "resolved",
+				"open_key":    nil,
+				"action_uuid": actionPo.UUID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		actionPo.ReportCount = result.RowsAffected
+		if err := tx.Create(actionPo).Error; err != nil {
+			return err
+		}
+		resolved = result.RowsAffected
+		return nil
+	})
+	return resolved, err
+}
+
+// GetActionsByTarget 获取对象的处理历史，最新的在前
+func (d *ReportDao) GetActionsByTarget(ctx context.Context, targetType, targetUUID string) ([]*po.ReportActionPo, error) {
+	var actionPos []*po.ReportActionPo
+	err := d.db.WithContext(ctx).Order("id DESC").
+		Find(&actionPos, "target_type = ? AND target_uuid = ? AND is_deleted = 0", targetType, targetUUID).Error
+	return actionPos, err
+}
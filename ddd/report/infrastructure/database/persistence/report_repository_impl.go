@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/report/domain/entity"
+	"go-video/ddd/report/domain/repo"
+	"go-video/ddd/report/domain/vo"
+	"go-video/ddd/report/infrastructure/database/convertor"
+	"go-video/ddd/report/infrastructure/database/dao"
+	"go-video/ddd/report/infrastructure/database/po"
+	"time"
+)
+
+// reportRepositoryImpl 举报仓储实现
+type reportRepositoryImpl struct {
+	reportDao       *dao.ReportDao
+	reportConvertor *convertor.ReportConvertor
+}
+
+// NewReportRepository 创建举报仓储实例（支持依赖注入）
+func NewReportRepository() repo.ReportRepository {
+	return &reportRepositoryImpl{
+		reportDao:       dao.NewReportDao(),
+		reportConvertor: convertor.NewReportConvertor(),
+	}
+}
+
+// Save 保存举报
+func (r *reportRepositoryImpl) Save(ctx context.Context, report *entity.Report) error {
+	return r.reportDao.Create(ctx, r.reportConvertor.EntityToPO(report))
+}
+
+// CountByReporterSince 统计举报人在指定时间之后提交的举报数
+func (r *reportRepositoryImpl) CountByReporterSince(ctx context.Context, reporterUUID string, since time.Time) (int64, error) {
+	return r.reportDao.CountByReporterSince(ctx, reporterUUID, since)
+}
+
+// ExistsOpen 检查举报人对该对象是否已有待处理的举报
+func (r *reportRepositoryImpl) ExistsOpen(ctx context.Context, reporterUUID string, targetType vo.TargetType, targetUUID string) (bool, error) {
+	return r.reportDao.ExistsOpen(ctx, r.reportConvertor.OpenKey(reporterUUID, targetType, targetUUID))
+}
+
+// FindOpenGroups 分页查找按对象聚合的待处理举报
+func (r *reportRepositoryImpl) FindOpenGroups(ctx context.Context, targetType *vo.TargetType, page *vo.Page) ([]*entity.ReportGroup, int64, error) {
+	typeFilter := ""
+	if targetType != nil {
+		typeFilter = targetType.Value()
+	}
+	rows, err := r.reportDao.GetOpenGroups(ctx, typeFilter, page.Offset(), page.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.reportDao.CountOpenGroups(ctx, typeFilter)
+	if err != nil {
+		return nil, 0, err
+	}
+	groups := make([]*entity.ReportGroup, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, r.reportConvertor.GroupRowToEntity(row))
+	}
+	return groups, total, nil
+}
+
+// FindByTarget 查找对象的举报
+func (r *reportRepositoryImpl) FindByTarget(ctx context.Context, targetType vo.TargetType, targetUUID string) ([]*entity.Report, error) {
+	reportPos, err := r.reportDao.GetByTarget(ctx, targetType.Value(), targetUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(reportPos), nil
+}
+
+// FindByReporter 查找举报人提交的全部举报
+func (r *reportRepositoryImpl) FindByReporter(ctx context.Context, reporterUUID string) ([]*entity.Report, error) {
+	reportPos, err := r.reportDao.GetByReporter(ctx, reporterUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(reportPos), nil
+}
+
+// Resolve 关闭对象下所有待处理的举报并写入处理记录
+func (r *reportRepositoryImpl) Resolve(ctx context.Context, action *entity.ReportAction) (int64, error) {
+	resolved, err := r.reportDao.Resolve(ctx, r.reportConvertor.ActionEntityToPO(action))
+	if err != nil {
+		return 0, err
+	}
+	action.SetReportCount(resolved)
+	return resolved, nil
+}
+
+// FindActionsByTarget 查找对象的处理历史
+func (r *reportRepositoryImpl) FindActionsByTarget(ctx context.Context, targetType vo.TargetType, targetUUID string) ([]*entity.ReportAction, error) {
+	actionPos, err := r.reportDao.GetActionsByTarget(ctx, targetType.Value(), targetUUID)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*entity.ReportAction, 0, len(actionPos))
+	for _, actionPo := range actionPos {
+		actions = append(actions, r.reportConvertor.ActionPOToEntity(actionPo))
+	}
+	return actions, nil
+}
+
+func (r *reportRepositoryImpl) toEntities(reportPos []*po.ReportPo) []*entity.Report {
+	reports := make([]*entity.Report, 0, len(reportPos))
+	for _, reportPo := range reportPos {
+		reports = append(reports, r.reportConvertor.POToEntity(reportPo))
+	}
+	return reports
+}
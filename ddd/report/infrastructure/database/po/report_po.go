@@ -0,0 +1,47 @@
+package po
+
+import "time"
+
+type ReportPo struct {
+	BaseModel
+
+	UUID         string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	ReporterUUID string `gorm:"index;size:36;not null;column:reporter_uuid" json:"reporter_uuid"`
+	TargetType   string `gorm:"size:20;not null;index:idx_report_target;column:target_type" json:"target_type"`
+	TargetUUID   string `gorm:"size:36;not null;index:idx_report_target;column:target_uuid" json:"target_uuid"`
+	ReasonCode   string `gorm:"size:30;not null;column:reason_code" json:"reason_code"`
+	Description  string `gorm:"type:text;column:description" json:"description"`
+	Status       string `gorm:"size:20;not null;column:status" json:"status"`
+	// OpenKey 待处理时为 举报人:对象类型:对象UUID，处理后置空，通过唯一索引保证同一举报人对同一对象只有一条待处理举报
+	OpenKey    *string `gorm:"uniqueIndex;size:120;column:open_key" json:"-"`
+	ActionUUID string  `gorm:"size:36;column:action_uuid" json:"action_uuid"`
+}
+
+func (r *ReportPo) TableName() string {
+	return "report"
+}
+
+type ReportActionPo struct {
+	BaseModel
+
+	UUID         string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	TargetType   string `gorm:"size:20;not null;index:idx_report_action_target;column:target_type" json:"target_type"`
+	TargetUUID   string `gorm:"size:36;not null;index:idx_report_action_target;column:target_uuid" json:"target_uuid"`
+	Action       string `gorm:"size:20;not null;column:action" json:"action"`
+	OperatorUUID string `gorm:"size:36;not null;column:operator_uuid" json:"operator_uuid"`
+	Note         string `gorm:"size:500;column:note" json:"note"`
+	ReportCount  int64  `gorm:"not null;column:report_count" json:"report_count"`
+}
+
+func (r *ReportActionPo) TableName() string {
+	return "report_action"
+}
+
+// ReportGroupRow 按对象聚合的查询结果
+type ReportGroupRow struct {
+	TargetType  string     `gorm:"column:target_type"`
+	TargetUUID  string     `gorm:"column:target_uuid"`
+	ReportCount int64      `gorm:"column:report_count"`
+	FirstAt     *time.Time `gorm:"column:first_at"`
+	LastAt      *time.Time `gorm:"column:last_at"`
+}
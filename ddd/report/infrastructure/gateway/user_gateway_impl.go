@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/report/domain/gateway"
+	userService "go-video/ddd/user/domain/service"
+	"go-video/pkg/errno"
+)
+
+// userGatewayImpl 通过用户领域服务访问用户上下文
+type userGatewayImpl struct {
+	userService *userService.UserService
+}
+
+// NewUserGateway 创建用户防腐层实例
+func NewUserGateway() gateway.UserGateway {
+	return &userGatewayImpl{
+		userService: userService.DefaultUserService(),
+	}
+}
+
+// Exists 检查用户是否存在
+func (g *userGatewayImpl) Exists(ctx context.Context, userUUID string) (bool, error) {
+	_, err := g.userService.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		if errno.AssertBizError(err).Code() == errno.ErrNotFound.Code {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DisableUser 禁用用户，用户服务以自增ID操作，这里先按UUID查出用户
+func (g *userGatewayImpl) DisableUser(ctx context.Context, userUUID string) error {
+	user, err := g.userService.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+	return g.userService.DisableUser(ctx, user.ID())
+}
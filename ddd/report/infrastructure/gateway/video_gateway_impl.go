@@ -0,0 +1,33 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/report/domain/gateway"
+	videoService "go-video/ddd/video/domain/service"
+)
+
+// videoGatewayImpl 通过视频领域服务访问视频上下文
+type videoGatewayImpl struct {
+	videoService videoService.VideoService
+}
+
+// NewVideoGateway 创建视频防腐层实例
+func NewVideoGateway() gateway.VideoGateway {
+	return &videoGatewayImpl{
+		videoService: videoService.DefaultVideoService(),
+	}
+}
+
+// Exists 检查视频是否存在
+func (g *videoGatewayImpl) Exists(ctx context.Context, videoUUID string) (bool, error) {
+	video, err := g.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return false, err
+	}
+	return video != nil, nil
+}
+
+// HideVideo 下架视频
+func (g *videoGatewayImpl) HideVideo(ctx context.Context, videoUUID string, reason string) error {
+	return g.videoService.HideVideo(ctx, videoUUID, reason)
+}
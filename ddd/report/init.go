@@ -0,0 +1,12 @@
+package report
+
+import (
+	"go-video/ddd/report/adapter/http"
+	"go-video/pkg/manager"
+)
+
+// init 包初始化函数，注册举报控制器插件
+func init() {
+	// 注册举报控制器插件到管理器
+	manager.RegisterControllerPlugin(&http.ReportControllerPlugin{})
+}
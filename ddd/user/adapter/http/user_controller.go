@@ -10,6 +10,7 @@ import (
 	"go-video/ddd/user/application/dto"
 	"go-video/pkg/assert"
 	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
 	"go-video/pkg/restapi"
 
 	"github.com/gin-gonic/gin"
@@ -86,7 +87,7 @@ func (c *userControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
 // RegisterOpsApi 注册运维API
 func (c *userControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
 	ops := router.Group("/v1")
-	ops.Use(c.AdminAuthMiddleware()) // 需要管理员权限
+	ops.Use(c.AuthMiddleware(), c.AdminAuthMiddleware()) // 需要管理员权限
 	{
 		// 获取用户列表
 		ops.GET("/users", c.GetUserList)
@@ -374,19 +375,10 @@ func (c *userControllerImpl) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AdminAuthMiddleware 管理员认证中间件
+// AdminAuthMiddleware 管理员认证中间件，挂在 AuthMiddleware 之后，只允许管理员名单中的用户
+// AuthMiddleware 认证通过后会继续执行后续处理器，不能在这里嵌套调用
 func (c *userControllerImpl) AdminAuthMiddleware() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		// 先进行普通认证
-		c.AuthMiddleware()(ctx)
-		if ctx.IsAborted() {
-			return
-		}
-
-		// TODO: 添加管理员权限检查逻辑
-		// 这里可以检查用户角色或权限
-		ctx.Next()
-	}
+	return middleware.AdminRequired()
 }
 
 // getCurrentUserUUID 获取当前用户UUID
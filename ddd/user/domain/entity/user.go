@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"time"
 
+	"go-video/pkg/event"
+
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -22,6 +24,8 @@ type User struct {
 	createdAt time.Time
 	updatedAt time.Time
 	deletedAt *time.Time
+
+	event.Recorder
 }
 
 // UserStatus 用户状态值对象
@@ -60,6 +64,7 @@ func NewUser(username, password, email string) (*User, error) {
 		return nil, err
 	}
 
+	user.Record(&event.UserRegistered{UserUUID: user.uuid, Username: user.username})
 	return user, nil
 }
 
@@ -167,8 +172,11 @@ func (u *User) Activate() {
 	u.updatedAt = time.Now()
 }
 
-// Disable 禁用用户
+// Disable 禁用用户，状态发生变化时记录禁用事件
 func (u *User) Disable() {
+	if u.status != UserStatusDisabled {
+		u.Record(&event.UserDisabled{UserUUID: u.uuid})
+	}
 	u.status = UserStatusDisabled
 	u.updatedAt = time.Now()
 }
@@ -8,6 +8,7 @@ import (
 	"go-video/ddd/internal/resource"
 	"go-video/ddd/user/infrastructure/database/po"
 	"go-video/pkg/assert"
+	"go-video/pkg/event"
 	"gorm.io/gorm"
 )
 
@@ -41,9 +42,14 @@ func NewUserDao() *UserDao {
 	}
 }
 
-// Create 创建用户
-func (d *UserDao) Create(ctx context.Context, user *po.UserPO) error {
-	return d.db.WithContext(ctx).Create(user).Error
+// Create 在事务中创建用户并写入领域事件
+func (d *UserDao) Create(ctx context.Context, user *po.UserPO, events ...*event.Event) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return event.Append(tx, events...)
+	})
 }
 
 // GetByID 根据内部ID获取用户（仅内部使用）
@@ -127,8 +133,13 @@ func (d *UserDao) Count(ctx context.Context) (int64, error) {
 }
 
 // Update 更新用户
-func (d *UserDao) Update(ctx context.Context, user *po.UserPO) error {
-	return d.db.WithContext(ctx).Save(user).Error
+func (d *UserDao) Update(ctx context.Context, user *po.UserPO, events ...*event.Event) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return event.Append(tx, events...)
+	})
 }
 
 // Delete 删除用户
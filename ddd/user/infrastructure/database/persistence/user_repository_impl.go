@@ -7,6 +7,7 @@ import (
 	"go-video/ddd/user/domain/vo"
 	"go-video/ddd/user/infrastructure/database/convertor"
 	"go-video/ddd/user/infrastructure/database/dao"
+	"go-video/pkg/event"
 )
 
 // userRepositoryImpl 用户仓储实现
@@ -25,10 +26,14 @@ func NewUserRepository() repo.UserRepository {
 	}
 }
 
-// Save 保存用户
+// Save 保存用户，同时写入用户记录的领域事件
 func (r *userRepositoryImpl) Save(ctx context.Context, user *entity.User) error {
+	events, err := event.NewAll(user.PullEvents())
+	if err != nil {
+		return err
+	}
 	userPO := r.userConvertor.ToPO(user)
-	if err := r.userDao.Create(ctx, userPO); err != nil {
+	if err := r.userDao.Create(ctx, userPO, events...); err != nil {
 		return err
 	}
 	// 设置生成的ID和UUID
@@ -97,10 +102,14 @@ func (r *userRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	return r.userDao.Count(ctx)
 }
 
-// Update 更新用户
+// Update 更新用户，同时写入用户记录的领域事件
 func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
+	events, err := event.NewAll(user.PullEvents())
+	if err != nil {
+		return err
+	}
 	userPO := r.userConvertor.ToPO(user)
-	return r.userDao.Update(ctx, userPO)
+	return r.userDao.Update(ctx, userPO, events...)
 }
 
 // Delete 删除用户
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkpointRecord 检查点文件中的一行，记录一个文件的处理结果
+type checkpointRecord struct {
+	Path      string    `json:"path"`
+	Status    string    `json:"status"`
+	VideoUUID string    `json:"video_uuid,omitempty"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// libraryCheckpoint 追加写入的检查点文件（每行一个JSON），中断后重新执行时跳过已导入的文件，失败的文件会重试
+type libraryCheckpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]*checkpointRecord
+}
+
+// openLibraryCheckpoint 读取已有的检查点并以追加方式打开，文件末尾不完整的行（写入时被中断）会被忽略
+func openLibraryCheckpoint(path string) (*libraryCheckpoint, error) {
+	done := make(map[string]*checkpointRecord)
+	if existing, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(existing)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			record := &checkpointRecord{}
+			if json.Unmarshal(scanner.Bytes(), record) != nil {
+				continue
+			}
+			if record.Status == ResultImported || record.Status == ResultDuplicate {
+				done[record.Path] = record
+			} else {
+				delete(done, record.Path)
+			}
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &libraryCheckpoint{file: file, done: done}, nil
+}
+
+// Done 文件在之前的执行中已导入（或确认重复）时返回其记录
+func (c *libraryCheckpoint) Done(path string) (*checkpointRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	record, ok := c.done[path]
+	return record, ok
+}
+
+// Record 追加一条处理结果并立即落盘
+func (c *libraryCheckpoint) Record(result *LibraryImportResult) error {
+	record := &checkpointRecord{
+		Path:      result.Path,
+		Status:    result.Status,
+		VideoUUID: result.VideoUUID,
+		SHA256:    result.SHA256,
+		Error:     result.Error,
+		Time:      time.Now(),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := c.file.Sync(); err != nil {
+		return err
+	}
+	if record.Status == ResultImported || record.Status == ResultDuplicate {
+		c.done[record.Path] = record
+	}
+	return nil
+}
+
+// Close 关闭检查点文件
+func (c *libraryCheckpoint) Close() error {
+	return c.file.Close()
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-video/ddd/video/application/app"
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+// defaultLibraryImportConcurrency 未指定时同时上传的文件数
+const defaultLibraryImportConcurrency = 4
+
+// 导入结果状态
+const (
+	// ResultImported 已导入
+	ResultImported = "imported"
+	// ResultDuplicate 所有者已有相同校验和的视频，未重复导入
+	ResultDuplicate = "duplicate"
+	// ResultCheckpointed 之前的执行中已处理，本次跳过
+	ResultCheckpointed = "checkpointed"
+	// ResultFailed 导入失败，重新执行时会重试
+	ResultFailed = "failed"
+	// ResultPending 执行被中断，尚未处理
+	ResultPending = "pending"
+	// ResultUnlisted 目录中存在但清单中没有的视频文件
+	ResultUnlisted = "unlisted"
+)
+
+// videoFileExtensions 遍历目录时视为视频文件的扩展名
+var videoFileExtensions = map[string]struct{}{
+	".mp4": {}, ".avi": {}, ".mov": {}, ".wmv": {}, ".flv": {}, ".webm": {}, ".mkv": {},
+}
+
+// LibraryImportOptions 视频库导入参数
+type LibraryImportOptions struct {
+	Dir         string // 视频文件所在目录，清单中的路径相对于该目录
+	Manifest    string // CSV或JSON清单
+	Checkpoint  string // 检查点文件，用于中断后继续
+	Report      string // 结果报告（CSV）
+	Concurrency int    // 同时上传的文件数
+}
+
+// LibraryImportResult 单个文件的导入结果
+type LibraryImportResult struct {
+	Path      string
+	Owner     string
+	Status    string
+	VideoUUID string
+	SHA256    string
+	Error     string
+}
+
+// LibraryImportSummary 导入结果汇总，Counts 按状态统计
+type LibraryImportSummary struct {
+	Results []*LibraryImportResult
+	Counts  map[string]int
+}
+
+// Failed 是否存在失败或未处理的文件
+func (s *LibraryImportSummary) Failed() bool {
+	return s.Counts[ResultFailed] > 0 || s.Counts[ResultPending] > 0
+}
+
+// LibraryImporter 从本地目录和清单批量导入已有视频库，通过 VideoApp 创建视频，与接口上传使用相同的领域逻辑
+type LibraryImporter struct {
+	videoApp app.VideoApp
+	opts     LibraryImportOptions
+}
+
+// NewLibraryImporter 创建视频库导入器
+func NewLibraryImporter(videoApp app.VideoApp, opts LibraryImportOptions) *LibraryImporter {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultLibraryImportConcurrency
+	}
+	return &LibraryImporter{
+		videoApp: videoApp,
+		opts:     opts,
+	}
+}
+
+// Run 执行导入并写出报告。ctx 取消后不再开始新的文件，已开始的文件会处理完，其余文件在报告中标记为 pending
+func (l *LibraryImporter) Run(ctx context.Context) (*LibraryImportSummary, error) {
+	entries, err := ParseLibraryManifest(l.opts.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	files, err := walkVideoFiles(l.opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := openLibraryCheckpoint(l.opts.Checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	defer checkpoint.Close()
+
+	results := make([]*LibraryImportResult, 0, len(entries)+len(files))
+	listed := make(map[string]struct{}, len(entries))
+	jobs := make(chan *libraryImportJob)
+	var wg sync.WaitGroup
+	for i := 0; i < l.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				l.importOne(ctx, checkpoint, job)
+			}
+		}()
+	}
+
+	for _, entry := range entries {
+		result := &LibraryImportResult{Path: entry.Path, Owner: entry.Owner, Status: ResultPending}
+		results = append(results, result)
+		rel, ok := relativePath(entry.Path)
+		if !ok {
+			result.Status, result.Error = ResultFailed, "path must be relative to the import directory"
+			continue
+		}
+		result.Path = rel
+		if _, dup := listed[rel]; dup {
+			result.Status, result.Error = ResultFailed, "duplicate manifest entry"
+			continue
+		}
+		listed[rel] = struct{}{}
+		if _, exists := files[rel]; !exists {
+			result.Status, result.Error = ResultFailed, "file not found in import directory"
+			continue
+		}
+		if record, done := checkpoint.Done(rel); done {
+			result.Status, result.VideoUUID, result.SHA256 = ResultCheckpointed, record.VideoUUID, record.SHA256
+			continue
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		select {
+		case jobs <- &libraryImportJob{entry: entry, result: result}:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, rel := range sortedKeys(files) {
+		if _, ok := listed[rel]; !ok {
+			results = append(results, &LibraryImportResult{Path: rel, Status: ResultUnlisted})
+		}
+	}
+	summary := &LibraryImportSummary{Results: results, Counts: make(map[string]int)}
+	for _, result := range results {
+		summary.Counts[result.Status]++
+	}
+	if err := writeLibraryImportReport(l.opts.Report, results); err != nil {
+		return summary, fmt.Errorf("write report: %w", err)
+	}
+	return summary, nil
+}
+
+// libraryImportJob 待导入的文件，result 由处理协程填写
+type libraryImportJob struct {
+	entry  *LibraryManifestEntry
+	result *LibraryImportResult
+}
+
+// importOne 导入单个文件并写入检查点
+func (l *LibraryImporter) importOne(ctx context.Context, checkpoint *libraryCheckpoint, job *libraryImportJob) {
+	result := job.result
+	cmd := &cqe.ImportLibraryFileCommand{
+		Path:          filepath.Join(l.opts.Dir, filepath.FromSlash(result.Path)),
+		OwnerUsername: job.entry.Owner,
+		Title:         job.entry.Title,
+		Description:   job.entry.Description,
+		Tags:          job.entry.Tags,
+		CreatedAt:     job.entry.CreatedAt,
+	}
+	imported, err := l.videoApp.ImportLibraryFile(ctx, cmd)
+	if err != nil {
+		result.Status, result.Error = ResultFailed, errorMessage(err)
+		logger.Error(fmt.Sprintf("library import %s failed: %v", result.Path, err))
+	} else {
+		result.Status, result.VideoUUID, result.SHA256 = ResultImported, imported.VideoUUID, imported.SHA256
+		if imported.Duplicate {
+			result.Status = ResultDuplicate
+		}
+		logger.Info(fmt.Sprintf("library import %s: %s video %s", result.Path, result.Status, result.VideoUUID))
+	}
+	if err := checkpoint.Record(result); err != nil {
+		logger.Error(fmt.Sprintf("library import write checkpoint for %s failed: %v", result.Path, err))
+	}
+}
+
+// writeLibraryImportReport 写出CSV结果报告
+func writeLibraryImportReport(reportPath string, results []*LibraryImportResult) error {
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"path", "owner", "status", "video_uuid", "sha256", "error"}); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if err := writer.Write([]string{result.Path, result.Owner, result.Status, result.VideoUUID, result.SHA256, result.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// walkVideoFiles 递归列出目录下的视频文件，键为相对路径（以 / 分隔）
+func walkVideoFiles(dir string) (map[string]struct{}, error) {
+	files := make(map[string]struct{})
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if _, ok := videoFileExtensions[strings.ToLower(filepath.Ext(p))]; !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+	return files, err
+}
+
+// relativePath 规范化清单中的路径，拒绝绝对路径和跳出导入目录的路径
+func relativePath(p string) (string, bool) {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	if p == "." || path.IsAbs(p) || filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
+}
+
+// errorMessage 报告中的错误信息，业务错误只取错误信息，完整错误见日志
+func errorMessage(err error) string {
+	if no, ok := err.(*errno.Errno); ok {
+		return no.Message
+	}
+	if bizErr, ok := err.(errno.BizError); ok {
+		return bizErr.Message()
+	}
+	return err.Error()
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
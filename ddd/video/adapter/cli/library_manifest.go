@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// createdAtLayouts 清单中 created_at 支持的时间格式，不带时区的按本地时间解析
+var createdAtLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// LibraryManifestEntry 视频库清单中的一条记录
+type LibraryManifestEntry struct {
+	Path        string     // 相对导入目录的文件路径
+	Owner       string     // 所有者用户名
+	Title       string     // 视频标题
+	Description string     // 视频描述
+	Tags        []string   // 标签
+	CreatedAt   *time.Time // 原始创建时间，可选
+}
+
+// jsonManifestEntry JSON清单的记录格式，tags 为数组
+type jsonManifestEntry struct {
+	Path        string   `json:"path"`
+	Owner       string   `json:"owner"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// ParseLibraryManifest 按扩展名解析 .json 或 .csv 清单。
+// CSV 第一行为表头，列名为 path, owner, title, description, tags, created_at，顺序不限，tags 以 | 或 ; 分隔
+func ParseLibraryManifest(path string) ([]*LibraryManifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseJSONManifest(file)
+	case ".csv":
+		return parseCSVManifest(file)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q, expected .csv or .json", filepath.Ext(path))
+	}
+}
+
+func parseJSONManifest(r io.Reader) ([]*LibraryManifestEntry, error) {
+	var rows []jsonManifestEntry
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	entries := make([]*LibraryManifestEntry, 0, len(rows))
+	for i, row := range rows {
+		createdAt, err := parseCreatedAt(row.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("manifest entry %d: %w", i+1, err)
+		}
+		entries = append(entries, &LibraryManifestEntry{
+			Path:        row.Path,
+			Owner:       row.Owner,
+			Title:       row.Title,
+			Description: row.Description,
+			Tags:        cleanTags(row.Tags),
+			CreatedAt:   createdAt,
+		})
+	}
+	return entries, nil
+}
+
+func parseCSVManifest(r io.Reader) ([]*LibraryManifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("parse manifest header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"path", "owner", "title"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("manifest is missing column %q", required)
+		}
+	}
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []*LibraryManifestEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+		createdAt, err := parseCreatedAt(column(record, "created_at"))
+		if err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+		tags := strings.FieldsFunc(column(record, "tags"), func(r rune) bool { return r == '|' || r == ';' })
+		entries = append(entries, &LibraryManifestEntry{
+			Path:        column(record, "path"),
+			Owner:       column(record, "owner"),
+			Title:       column(record, "title"),
+			Description: column(record, "description"),
+			Tags:        cleanTags(tags),
+			CreatedAt:   createdAt,
+		})
+	}
+}
+
+func parseCreatedAt(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range createdAtLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid created_at %q", value)
+}
+
+// cleanTags 去掉空白和空标签
+func cleanTags(tags []string) []string {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// ArtworkEventHandlerPlugin 视频删除后取消未完成的封面和缩略图生成的处理器插件（生成由处理流水线调度）
+type ArtworkEventHandlerPlugin struct {
+}
+
+func (p *ArtworkEventHandlerPlugin) Name() string {
+	return "videoArtworkEventHandlerPlugin"
+}
+
+func (p *ArtworkEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &artworkEventHandler{
+		artworkApp: app.DefaultArtworkApp(),
+	}
+}
+
+// artworkEventHandler 取消是幂等的，重复分发没有影响
+type artworkEventHandler struct {
+	artworkApp app.ArtworkApp
+}
+
+func (h *artworkEventHandler) EventTypes() []event.Type {
+	return []event.Type{event.TypeVideoDeleted}
+}
+
+func (h *artworkEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	switch e.Type {
+	case event.TypeVideoDeleted:
+		return h.artworkApp.CancelDeleted(ctx, e.AggregateID)
+	}
+	return nil
+}
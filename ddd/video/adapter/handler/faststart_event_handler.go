@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// FaststartEventHandlerPlugin 视频删除后取消未完成的 faststart 改写的处理器插件（改写由处理流水线调度）
+type FaststartEventHandlerPlugin struct {
+}
+
+func (p *FaststartEventHandlerPlugin) Name() string {
+	return "videoFaststartEventHandlerPlugin"
+}
+
+func (p *FaststartEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &faststartEventHandler{
+		faststartApp: app.DefaultFaststartApp(),
+	}
+}
+
+// faststartEventHandler 取消是幂等的，重复分发没有影响
+type faststartEventHandler struct {
+	faststartApp app.FaststartApp
+}
+
+func (h *faststartEventHandler) EventTypes() []event.Type {
+	return []event.Type{event.TypeVideoDeleted}
+}
+
+func (h *faststartEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	switch e.Type {
+	case event.TypeVideoDeleted:
+		return h.faststartApp.CancelDeleted(ctx, e.AggregateID)
+	}
+	return nil
+}
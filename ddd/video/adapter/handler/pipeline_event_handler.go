@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// PipelineEventHandlerPlugin 上传完成后创建处理流水线、视频删除后取消流水线的处理器插件
+type PipelineEventHandlerPlugin struct {
+}
+
+func (p *PipelineEventHandlerPlugin) Name() string {
+	return "videoPipelineEventHandlerPlugin"
+}
+
+func (p *PipelineEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &pipelineEventHandler{
+		pipelineApp: app.DefaultPipelineApp(),
+	}
+}
+
+// pipelineEventHandler 创建和取消都是幂等的，重复分发不会重复创建流水线
+type pipelineEventHandler struct {
+	pipelineApp app.PipelineApp
+}
+
+func (h *pipelineEventHandler) EventTypes() []event.Type {
+	return []event.Type{event.TypeVideoUploaded, event.TypeVideoDeleted}
+}
+
+func (h *pipelineEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	switch e.Type {
+	case event.TypeVideoUploaded:
+		return h.pipelineApp.StartUploaded(ctx, e.AggregateID)
+	case event.TypeVideoDeleted:
+		return h.pipelineApp.CancelDeleted(ctx, e.AggregateID)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// ScanEventHandlerPlugin 视频删除后取消未完成的恶意软件扫描的处理器插件（扫描由处理流水线调度）
+type ScanEventHandlerPlugin struct {
+}
+
+func (p *ScanEventHandlerPlugin) Name() string {
+	return "videoScanEventHandlerPlugin"
+}
+
+func (p *ScanEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &scanEventHandler{
+		scanApp: app.DefaultScanApp(),
+	}
+}
+
+// scanEventHandler 取消是幂等的，重复分发没有影响
+type scanEventHandler struct {
+	scanApp app.ScanApp
+}
+
+func (h *scanEventHandler) EventTypes() []event.Type {
+	return []event.Type{event.TypeVideoDeleted}
+}
+
+func (h *scanEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	switch e.Type {
+	case event.TypeVideoDeleted:
+		return h.scanApp.CancelDeleted(ctx, e.AggregateID)
+	}
+	return nil
+}
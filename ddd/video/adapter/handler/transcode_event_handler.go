@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// TranscodeEventHandlerPlugin 视频删除后取消未完成的转码的处理器插件（转码由处理流水线调度）
+type TranscodeEventHandlerPlugin struct {
+}
+
+func (p *TranscodeEventHandlerPlugin) Name() string {
+	return "videoTranscodeEventHandlerPlugin"
+}
+
+func (p *TranscodeEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &transcodeEventHandler{
+		transcodeApp: app.DefaultTranscodeApp(),
+	}
+}
+
+// transcodeEventHandler 取消是幂等的，重复分发没有影响
+type transcodeEventHandler struct {
+	transcodeApp app.TranscodeApp
+}
+
+func (h *transcodeEventHandler) EventTypes() []event.Type {
+	return []event.Type{event.TypeVideoDeleted}
+}
+
+func (h *transcodeEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	switch e.Type {
+	case event.TypeVideoDeleted:
+		return h.transcodeApp.CancelDeleted(ctx, e.AggregateID)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPoster 重定向到封面的预签名地址或服务端解密地址，预签名地址会过期，不能缓存重定向本身
+func (c *videoControllerImpl) GetPoster(ctx *gin.Context) {
+	query := cqe.ArtworkQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	posterURL, err := c.artworkApp.PosterURL(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Redirect(http.StatusFound, posterURL)
+}
+
+// GetStoryboard 获取拖动预览缩略图轨道（WebVTT），其中的雪碧图地址会过期
+func (c *videoControllerImpl) GetStoryboard(ctx *gin.Context) {
+	query := cqe.ArtworkQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	storyboard, err := c.artworkApp.Storyboard(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Data(http.StatusOK, storyboard.ContentType, storyboard.Content)
+}
+
+// GetArtworkFile 获取加密存储的封面、候选帧或雪碧图，在服务端解密
+func (c *videoControllerImpl) GetArtworkFile(ctx *gin.Context) {
+	query := cqe.ArtworkFileQuery{
+		ArtworkQuery: cqe.ArtworkQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")},
+		File:         ctx.Param("file"),
+	}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	file, err := c.artworkApp.OpenArtworkFile(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	// 封面可以被替换，解密后的内容不进入共享缓存
+	writeMediaObject(ctx, file, "private, no-cache")
+}
+
+// GetArtwork 视频所有者查看封面、候选帧和生成状态
+func (c *videoControllerImpl) GetArtwork(ctx *gin.Context) {
+	result, err := c.artworkApp.GetArtwork(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// SelectPoster 选择候选帧作为封面（{"index": 0}）
+func (c *videoControllerImpl) SelectPoster(ctx *gin.Context) {
+	var cmd cqe.SelectPosterCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "index"))
+		return
+	}
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+
+	result, err := c.artworkApp.SelectPoster(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// UploadCover 上传自定义封面（multipart/form-data: file）
+func (c *videoControllerImpl) UploadCover(ctx *gin.Context) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "file"))
+		return
+	}
+	cmd := cqe.UploadCoverCommand{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID: ctx.Param("id"),
+		File:      file,
+	}
+	result, err := c.artworkApp.UploadCover(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
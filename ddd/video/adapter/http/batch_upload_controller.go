@@ -0,0 +1,74 @@
+package http
+
+import (
+	"io"
+	"mime/multipart"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchUpload 批量上传视频：多个file部分，manifest部分为JSON清单（表单字段或文件均可）
+func (c *videoControllerImpl) BatchUpload(ctx *gin.Context) {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "multipart form"))
+		return
+	}
+	data, err := batchManifest(form)
+	if err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "manifest"))
+		return
+	}
+	cmd := cqe.BatchUploadCommand{
+		UserUUID: middleware.MustGetCurrentUserUUID(ctx),
+		Files:    form.File["file"],
+	}
+	if len(data) > 0 {
+		if cmd.Manifest, err = cqe.ParseBatchManifest(data); err != nil {
+			restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "manifest"))
+			return
+		}
+	}
+	result, err := c.videoApp.BatchUpload(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetUploadBatch 获取批量上传进度
+func (c *videoControllerImpl) GetUploadBatch(ctx *gin.Context) {
+	query := cqe.GetUploadBatchQuery{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		BatchUUID: ctx.Param("id"),
+	}
+	result, err := c.videoApp.GetUploadBatch(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// batchManifest 读取清单内容，优先使用manifest表单字段，其次是名为manifest的文件部分
+func batchManifest(form *multipart.Form) ([]byte, error) {
+	if values := form.Value["manifest"]; len(values) > 0 && values[0] != "" {
+		return []byte(values[0]), nil
+	}
+	files := form.File["manifest"]
+	if len(files) == 0 {
+		return nil, nil
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
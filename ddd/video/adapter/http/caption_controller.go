@@ -0,0 +1,69 @@
+package http
+
+import (
+	"strconv"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadCaption 上传字幕轨道（multipart/form-data: file, language, label, is_default）
+func (c *videoControllerImpl) UploadCaption(ctx *gin.Context) {
+	var cmd cqe.UploadCaptionCommand
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+	cmd.Language = ctx.PostForm("language")
+	cmd.Label = ctx.PostForm("label")
+	if isDefault := ctx.PostForm("is_default"); isDefault != "" {
+		v, err := strconv.ParseBool(isDefault)
+		if err != nil {
+			restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "is_default"))
+			return
+		}
+		cmd.IsDefault = v
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "file"))
+		return
+	}
+	cmd.File = file
+
+	result, err := c.captionApp.UploadCaption(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ListCaptions 获取视频字幕轨道列表
+func (c *videoControllerImpl) ListCaptions(ctx *gin.Context) {
+	query := cqe.CaptionQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	result, err := c.captionApp.ListCaptions(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// DeleteCaption 删除字幕轨道
+func (c *videoControllerImpl) DeleteCaption(ctx *gin.Context) {
+	cmd := cqe.DeleteCaptionCommand{
+		UserUUID:    middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID:   ctx.Param("id"),
+		CaptionUUID: ctx.Param("caption_id"),
+	}
+	if err := c.captionApp.DeleteCaption(ctx.Request.Context(), &cmd); err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, nil)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetChapters 显式设置视频章节，chapters为空表示清除
+func (c *videoControllerImpl) SetChapters(ctx *gin.Context) {
+	var cmd cqe.SetChaptersCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "chapters"))
+		return
+	}
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+
+	result, err := c.chapterApp.SetChapters(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetChapters 获取视频章节
+func (c *videoControllerImpl) GetChapters(ctx *gin.Context) {
+	result, err := c.chapterApp.GetChapters(ctx.Request.Context(), chapterQuery(ctx))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ExportChapters 导出WebVTT章节轨道，供播放器以 <track kind="chapters"> 加载
+func (c *videoControllerImpl) ExportChapters(ctx *gin.Context) {
+	data, err := c.chapterApp.ExportWebVTT(ctx.Request.Context(), chapterQuery(ctx))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "text/vtt; charset=utf-8", data)
+}
+
+// chapterQuery 从请求中读取视频UUID、当前登录用户和分享令牌
+func chapterQuery(ctx *gin.Context) *cqe.ChapterQuery {
+	query := &cqe.ChapterQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	return query
+}
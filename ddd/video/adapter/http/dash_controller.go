@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDASHManifest 获取DASH清单，其中的分片签名会过期，不能缓存
+func (c *videoControllerImpl) GetDASHManifest(ctx *gin.Context) {
+	query := cqe.DASHManifestQuery{VideoUUID: ctx.Param("id")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	manifest, err := c.dashApp.Manifest(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, manifest.ContentType, manifest.Content)
+}
+
+// GetDASHSegment 校验签名后重定向到分片的预签名地址
+func (c *videoControllerImpl) GetDASHSegment(ctx *gin.Context) {
+	query := cqe.DASHSegmentQuery{
+		VideoUUID:     ctx.Param("id"),
+		RenditionUUID: ctx.Param("rendition"),
+		Segment:       ctx.Param("segment"),
+		Expires:       ctx.Query("expires"),
+		Signature:     ctx.Query("signature"),
+	}
+	segmentURL, err := c.dashApp.SegmentURL(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Redirect(http.StatusFound, segmentURL)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportVideo 从远程URL导入视频（JSON: url, title, description, format，可选 content_md5、checksum_sha256）
+func (c *videoControllerImpl) ImportVideo(ctx *gin.Context) {
+	var cmd cqe.ImportVideoCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.importApp.ImportVideo(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetUploadTask 获取上传任务的状态和传输进度
+func (c *videoControllerImpl) GetUploadTask(ctx *gin.Context) {
+	query := cqe.GetUploadTaskQuery{
+		UserUUID: middleware.MustGetCurrentUserUUID(ctx),
+		TaskUUID: ctx.Param("id"),
+	}
+	result, err := c.importApp.GetUploadTask(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContentKey 获取HLS内容密钥，未登录时必须携带分享令牌；密钥不能被任何中间层缓存
+func (c *videoControllerImpl) GetContentKey(ctx *gin.Context) {
+	query := cqe.ContentKeyQuery{
+		VideoUUID:  ctx.Param("id"),
+		KeyUUID:    ctx.Param("key_id"),
+		ShareToken: ctx.Query("share_token"),
+		ClientIP:   ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+	}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	key, err := c.keyApp.ContentKey(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "application/octet-stream", key)
+}
+
+// IssueShareToken 视频所有者签发分享令牌
+func (c *videoControllerImpl) IssueShareToken(ctx *gin.Context) {
+	cmd := cqe.ShareTokenCommand{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID: ctx.Param("id"),
+	}
+	result, err := c.keyApp.IssueShareToken(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateLiveStream 创建直播（JSON: title, description），返回只显示一次的推流密钥
+func (c *videoControllerImpl) CreateLiveStream(ctx *gin.Context) {
+	var cmd cqe.CreateLiveStreamCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.liveApp.CreateLiveStream(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ListLiveStreams 获取我的直播
+func (c *videoControllerImpl) ListLiveStreams(ctx *gin.Context) {
+	result, err := c.liveApp.ListLiveStreams(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetLiveStream 获取直播状态和转换进度
+func (c *videoControllerImpl) GetLiveStream(ctx *gin.Context) {
+	cmd := cqe.LiveStreamCommand{
+		UserUUID:   middleware.MustGetCurrentUserUUID(ctx),
+		StreamUUID: ctx.Param("id"),
+	}
+	result, err := c.liveApp.GetLiveStream(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// EndLiveStream 结束直播
+func (c *videoControllerImpl) EndLiveStream(ctx *gin.Context) {
+	cmd := cqe.LiveStreamCommand{
+		UserUUID:   middleware.MustGetCurrentUserUUID(ctx),
+		StreamUUID: ctx.Param("id"),
+	}
+	result, err := c.liveApp.EndLiveStream(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// IngestLive 编码器推送分片或媒体播放列表，请求体为文件内容，以路径中的推流密钥认证
+func (c *videoControllerImpl) IngestLive(ctx *gin.Context) {
+	cmd := cqe.LiveIngestCommand{
+		StreamKey: ctx.Param("key"),
+		Filename:  ctx.Param("filename"),
+		Body:      ctx.Request.Body,
+	}
+	result, err := c.liveApp.Ingest(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetLivePlaylist 获取直播的滑动窗口播放列表，直播中每次刷新都会变化，不能缓存
+func (c *videoControllerImpl) GetLivePlaylist(ctx *gin.Context) {
+	query := cqe.LivePlaylistQuery{
+		StreamUUID: ctx.Param("id"),
+		UserUUID:   middleware.MustGetCurrentUserUUID(ctx),
+	}
+	playlist, err := c.liveApp.LivePlaylist(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, playlist.ContentType, playlist.Content)
+}
+
+// GetLiveSegment 获取加密存储的直播分片，在服务端解密
+func (c *videoControllerImpl) GetLiveSegment(ctx *gin.Context) {
+	query := cqe.LiveSegmentQuery{
+		StreamUUID: ctx.Param("id"),
+		UserUUID:   middleware.MustGetCurrentUserUUID(ctx),
+		Segment:    ctx.Param("segment"),
+	}
+	segment, err := c.liveApp.OpenSegment(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	// 登记后的分片内容不再变化，但需要登录才能观看，只允许私有缓存
+	writeMediaObject(ctx, segment, "private, max-age=3600")
+}
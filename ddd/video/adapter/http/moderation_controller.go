@@ -0,0 +1,78 @@
+package http
+
+import (
+	"io"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReviewQueue 获取审核队列
+func (c *videoControllerImpl) GetReviewQueue(ctx *gin.Context) {
+	var query cqe.ListVideosQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "query"))
+		return
+	}
+	result, err := c.moderationApp.ListQueue(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ClaimReview 认领审核任务
+func (c *videoControllerImpl) ClaimReview(ctx *gin.Context) {
+	cmd := cqe.ClaimReviewCommand{
+		ModeratorUUID: middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID:     ctx.Param("id"),
+	}
+	if err := c.moderationApp.Claim(ctx.Request.Context(), &cmd); err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, nil)
+}
+
+// ApproveReview 审核通过
+func (c *videoControllerImpl) ApproveReview(ctx *gin.Context) {
+	c.decideReview(ctx, vo.ReviewDecisionApprove)
+}
+
+// RejectReview 审核拒绝，需要填写原因
+func (c *videoControllerImpl) RejectReview(ctx *gin.Context) {
+	c.decideReview(ctx, vo.ReviewDecisionReject)
+}
+
+func (c *videoControllerImpl) decideReview(ctx *gin.Context, decision vo.ReviewDecision) {
+	var cmd cqe.ReviewDecisionCommand
+	// 通过时可以不带请求体
+	if err := ctx.ShouldBindJSON(&cmd); err != nil && err != io.EOF {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "reason"))
+		return
+	}
+	cmd.ModeratorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+	cmd.Decision = decision
+	if err := c.moderationApp.Decide(ctx.Request.Context(), &cmd); err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, nil)
+}
+
+// GetReviewLogs 获取视频的审核记录
+func (c *videoControllerImpl) GetReviewLogs(ctx *gin.Context) {
+	result, err := c.moderationApp.ListLogs(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
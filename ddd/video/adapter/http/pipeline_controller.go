@@ -0,0 +1,34 @@
+package http
+
+import (
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPipeline 运维人员查看视频的处理流水线和每个步骤的状态
+func (c *videoControllerImpl) GetPipeline(ctx *gin.Context) {
+	result, err := c.pipelineApp.GetPipeline(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// RerunPipelineStep 运维人员重新执行失败的步骤，因它失败而取消的后续步骤一并重新执行
+func (c *videoControllerImpl) RerunPipelineStep(ctx *gin.Context) {
+	cmd := cqe.RerunPipelineStepCommand{
+		OperatorUUID: middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID:    ctx.Param("id"),
+		Step:         ctx.Param("step"),
+	}
+	result, err := c.pipelineApp.RerunStep(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMasterPlaylist 获取HLS主播放列表
+func (c *videoControllerImpl) GetMasterPlaylist(ctx *gin.Context) {
+	query := cqe.PlaylistQuery{VideoUUID: ctx.Param("id"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	playlist, err := c.playlistApp.MasterPlaylist(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Data(http.StatusOK, playlist.ContentType, playlist.Content)
+}
+
+// GetMediaPlaylist 获取一档的HLS媒体播放列表，其中的分片地址很快过期，不能缓存
+func (c *videoControllerImpl) GetMediaPlaylist(ctx *gin.Context) {
+	query := cqe.PlaylistQuery{VideoUUID: ctx.Param("id"), Rendition: ctx.Param("rendition"), ShareToken: ctx.Query("share_token")}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	playlist, err := c.playlistApp.MediaPlaylist(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, playlist.ContentType, playlist.Content)
+}
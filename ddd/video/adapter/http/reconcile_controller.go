@@ -0,0 +1,36 @@
+package http
+
+import (
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reconcile 手动触发对象存储与数据库对账，默认试运行
+func (c *videoControllerImpl) Reconcile(ctx *gin.Context) {
+	var cmd cqe.ReconcileCommand
+	if err := ctx.ShouldBindQuery(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "dry_run"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.reconcileApp.Reconcile(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// RewrapKeys 主密钥轮换后重新包装所有数据密钥
+func (c *videoControllerImpl) RewrapKeys(ctx *gin.Context) {
+	result, err := c.encryptionApp.RewrapKeys(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
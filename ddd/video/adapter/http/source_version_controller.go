@@ -0,0 +1,71 @@
+package http
+
+import (
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/errno"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReplaceSource 替换视频源文件（multipart/form-data: file, format，可选 content_md5、checksum_sha256、keep_metadata）
+func (c *videoControllerImpl) ReplaceSource(ctx *gin.Context) {
+	var cmd cqe.ReplaceSourceCommand
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+	cmd.Format = ctx.PostForm("format")
+	cmd.ContentMD5 = headerOrForm(ctx, "Content-MD5", "content_md5")
+	cmd.ChecksumSHA256 = headerOrForm(ctx, "X-Checksum-SHA256", "checksum_sha256")
+	if keepMetadata := ctx.PostForm("keep_metadata"); keepMetadata != "" {
+		v, err := strconv.ParseBool(keepMetadata)
+		if err != nil {
+			restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "keep_metadata"))
+			return
+		}
+		cmd.KeepMetadata = v
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "file"))
+		return
+	}
+	cmd.File = file
+
+	result, err := c.sourceVersionApp.ReplaceSource(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ListSourceVersions 获取视频源文件版本列表
+func (c *videoControllerImpl) ListSourceVersions(ctx *gin.Context) {
+	result, err := c.sourceVersionApp.ListSourceVersions(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// RollbackSource 回滚视频源文件到历史版本
+func (c *videoControllerImpl) RollbackSource(ctx *gin.Context) {
+	var cmd cqe.RollbackSourceCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "version"))
+		return
+	}
+	cmd.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.VideoUUID = ctx.Param("id")
+
+	result, err := c.sourceVersionApp.RollbackSource(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
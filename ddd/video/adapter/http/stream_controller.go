@@ -0,0 +1,66 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamVideo 播放视频源文件，支持Range请求，加密存储的视频在服务端解密
+func (c *videoControllerImpl) StreamVideo(ctx *gin.Context) {
+	query := cqe.StreamVideoQuery{
+		VideoUUID: ctx.Param("id"),
+		Range:     ctx.GetHeader("Range"),
+		Rendition: ctx.Query("rendition"),
+	}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	stream, err := c.videoApp.OpenStream(ctx.Request.Context(), &query)
+	if err != nil {
+		if errno.AssertBizError(err).Code() == errno.ErrRangeInvalid.Code {
+			restapi.FailedWithStatus(ctx, err, http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		restapi.Failed(ctx, err)
+		return
+	}
+	defer stream.Reader.Close()
+
+	header := ctx.Writer.Header()
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Content-Type", stream.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(stream.Length, 10))
+	status := http.StatusOK
+	if stream.Partial {
+		status = http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", stream.Offset, stream.Offset+stream.Length-1, stream.Size))
+	}
+	ctx.Status(status)
+	if _, err := io.Copy(ctx.Writer, stream.Reader); err != nil {
+		// 响应头已发送，只能中断连接
+		logger.Error(fmt.Sprintf("StreamVideo video_uuid: %s, error: %v", query.VideoUUID, err))
+	}
+}
+
+// writeMediaObject 返回整个衍生对象的明文
+func writeMediaObject(ctx *gin.Context, object *dto.VideoStreamDto, cacheControl string) {
+	defer object.Reader.Close()
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", object.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(object.Length, 10))
+	header.Set("Cache-Control", cacheControl)
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, object.Reader); err != nil {
+		// 响应头已发送，只能中断连接
+		logger.Error(fmt.Sprintf("write media object %s, error: %v", ctx.Request.URL.Path, err))
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"go-video/ddd/video/application/cqe"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRenditions 获取视频的转码输出和进度
+func (c *videoControllerImpl) ListRenditions(ctx *gin.Context) {
+	result, err := c.transcodeApp.ListRenditions(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// Retranscode 重新转码视频
+func (c *videoControllerImpl) Retranscode(ctx *gin.Context) {
+	cmd := cqe.TranscodeCommand{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID: ctx.Param("id"),
+	}
+	result, err := c.transcodeApp.Retranscode(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// CancelTranscode 取消视频未完成的转码
+func (c *videoControllerImpl) CancelTranscode(ctx *gin.Context) {
+	cmd := cqe.TranscodeCommand{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID: ctx.Param("id"),
+	}
+	result, err := c.transcodeApp.CancelTranscode(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"go-video/pkg/logger"
+	"strconv"
 	"sync"
 
 	"go-video/ddd/video/application/app"
@@ -34,8 +35,23 @@ func (p *VideoControllerPlugin) MustCreateController() manager.Controller {
 	videoControllerOnce.Do(func() {
 		videoApp := app.DefaultVideoApp()
 		singletonVideoController = &videoControllerImpl{
-			Controller: nil, // 这里可以设置为nil，因为我们实现了manager.Controller接口
-			videoApp:   videoApp,
+			Controller:    nil, // 这里可以设置为nil，因为我们实现了manager.Controller接口
+			videoApp:      videoApp,
+			captionApp:    app.DefaultCaptionApp(),
+			chapterApp:    app.DefaultChapterApp(),
+			moderationApp: app.DefaultModerationApp(),
+			reconcileApp:  app.DefaultReconcileApp(),
+			encryptionApp: app.DefaultEncryptionApp(),
+
+			sourceVersionApp: app.DefaultSourceVersionApp(),
+			importApp:        app.DefaultImportApp(),
+			transcodeApp:     app.DefaultTranscodeApp(),
+			playlistApp:      app.DefaultPlaylistApp(),
+			dashApp:          app.DefaultDASHApp(),
+			keyApp:           app.DefaultKeyApp(),
+			artworkApp:       app.DefaultArtworkApp(),
+			pipelineApp:      app.DefaultPipelineApp(),
+			liveApp:          app.DefaultLiveApp(),
 		}
 	})
 	assert.NotNil(singletonVideoController)
@@ -50,7 +66,22 @@ type VideoController interface {
 
 type videoControllerImpl struct {
 	manager.Controller
-	videoApp app.VideoApp
+	videoApp      app.VideoApp
+	captionApp    app.CaptionApp
+	chapterApp    app.ChapterApp
+	moderationApp app.ModerationApp
+	reconcileApp  app.ReconcileApp
+	encryptionApp app.EncryptionApp
+
+	sourceVersionApp app.SourceVersionApp
+	importApp        app.ImportApp
+	transcodeApp     app.TranscodeApp
+	playlistApp      app.PlaylistApp
+	dashApp          app.DASHApp
+	keyApp           app.KeyApp
+	artworkApp       app.ArtworkApp
+	pipelineApp      app.PipelineApp
+	liveApp          app.LiveApp
 }
 
 func DefaultVideoController() VideoController {
@@ -58,7 +89,22 @@ func DefaultVideoController() VideoController {
 	videoControllerOnce.Do(func() {
 		videoApp := app.DefaultVideoApp()
 		singletonVideoController = &videoControllerImpl{
-			videoApp: videoApp,
+			videoApp:      videoApp,
+			captionApp:    app.DefaultCaptionApp(),
+			chapterApp:    app.DefaultChapterApp(),
+			moderationApp: app.DefaultModerationApp(),
+			reconcileApp:  app.DefaultReconcileApp(),
+			encryptionApp: app.DefaultEncryptionApp(),
+
+			sourceVersionApp: app.DefaultSourceVersionApp(),
+			importApp:        app.DefaultImportApp(),
+			transcodeApp:     app.DefaultTranscodeApp(),
+			playlistApp:      app.DefaultPlaylistApp(),
+			dashApp:          app.DefaultDASHApp(),
+			keyApp:           app.DefaultKeyApp(),
+			artworkApp:       app.DefaultArtworkApp(),
+			pipelineApp:      app.DefaultPipelineApp(),
+			liveApp:          app.DefaultLiveApp(),
 		}
 	})
 	assert.NotNil(singletonVideoController)
@@ -71,13 +117,67 @@ func (c *videoControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
 	{
 		// 视频上传需要认证
 		v1.POST("/videos/upload", middleware.AuthRequired(), c.UploadVideo)
-		// 视频查看可以不需要认证（公开访问）
-		v1.GET("/videos/:id", c.GetVideo)
+		// 视频查看可以不需要认证（公开访问），登录用户可以查看自己未通过审核的视频
+		v1.GET("/videos/:id", middleware.AuthOptional(), c.GetVideo)
+		v1.GET("/videos/:id/stream", middleware.AuthOptional(), c.StreamVideo)
+		v1.GET("/videos/:id/hls/master.m3u8", middleware.AuthOptional(), c.GetMasterPlaylist)
+		v1.GET("/videos/:id/hls/:rendition/index.m3u8", middleware.AuthOptional(), c.GetMediaPlaylist)
+		// 密钥接口同样接受分享令牌，登录校验和审计在应用层完成
+		v1.GET("/videos/:id/keys/:key_id", middleware.AuthOptional(), c.GetContentKey)
+		v1.GET("/videos/:id/dash/manifest.mpd", middleware.AuthOptional(), c.GetDASHManifest)
+		// 分片地址由清单签发，播放器请求分片时不携带登录凭证
+		v1.GET("/videos/:id/dash/:rendition/:segment", c.GetDASHSegment)
 		v1.GET("/videos", c.GetVideoList)
+		// 字幕和章节与播放的可见性相同
+		v1.GET("/videos/:id/captions", middleware.AuthOptional(), c.ListCaptions)
+		v1.GET("/videos/:id/chapters", middleware.AuthOptional(), c.GetChapters)
+		v1.GET("/videos/:id/chapters.vtt", middleware.AuthOptional(), c.ExportChapters)
+		// 封面和拖动预览缩略图，与视频详情的可见性相同
+		v1.GET("/videos/:id/poster", middleware.AuthOptional(), c.GetPoster)
+		v1.GET("/videos/:id/storyboard.vtt", middleware.AuthOptional(), c.GetStoryboard)
+		// 加密存储的封面、候选帧和雪碧图由服务端解密
+		v1.GET("/videos/:id/artwork/:file", middleware.AuthOptional(), c.GetArtworkFile)
+		// 直播：编码器凭推流密钥推送，不携带登录凭证；观看直播需要登录
+		v1.PUT("/live/ingest/:key/:filename", c.IngestLive)
+		v1.GET("/live/:id/index.m3u8", middleware.AuthRequired(), c.GetLivePlaylist)
+		v1.GET("/live/:id/segments/:segment", middleware.AuthRequired(), c.GetLiveSegment)
 	}
 	v2 := router.Group("/v2", middleware.AuthRequired())
 	{
 		v2.POST("/videos/upload", c.UploadSyncVideo)
+		// 批量上传
+		v2.POST("/videos/batch", c.BatchUpload)
+		v2.GET("/videos/batches/:id", c.GetUploadBatch)
+		// 从远程URL导入，上传任务进度
+		v2.POST("/videos/import", c.ImportVideo)
+		v2.GET("/videos/tasks/:id", c.GetUploadTask)
+		// 我的视频（包含审核状态）
+		v2.GET("/videos", c.GetMyVideoList)
+		v2.DELETE("/videos/:id", c.DeleteVideo)
+		// 字幕管理（仅视频所有者）
+		v2.POST("/videos/:id/captions", c.UploadCaption)
+		v2.DELETE("/videos/:id/captions/:caption_id", c.DeleteCaption)
+		// 章节管理（仅视频所有者）
+		v2.PUT("/videos/:id/chapters", c.SetChapters)
+		// 源文件版本（仅视频所有者）
+		v2.POST("/videos/:id/source", c.ReplaceSource)
+		v2.GET("/videos/:id/source/versions", c.ListSourceVersions)
+		v2.POST("/videos/:id/source/rollback", c.RollbackSource)
+		// 转码（仅视频所有者）
+		v2.GET("/videos/:id/renditions", c.ListRenditions)
+		v2.POST("/videos/:id/transcode", c.Retranscode)
+		v2.POST("/videos/:id/transcode/cancel", c.CancelTranscode)
+		// 分享令牌（仅视频所有者），持有者不登录也可以观看私有视频
+		v2.POST("/videos/:id/share-token", c.IssueShareToken)
+		// 封面（仅视频所有者）：选择候选帧或上传自定义封面
+		v2.GET("/videos/:id/artwork", c.GetArtwork)
+		v2.PUT("/videos/:id/poster", c.SelectPoster)
+		v2.POST("/videos/:id/cover", c.UploadCover)
+		// 直播（仅主播）
+		v2.POST("/live", c.CreateLiveStream)
+		v2.GET("/live", c.ListLiveStreams)
+		v2.GET("/live/:id", c.GetLiveStream)
+		v2.POST("/live/:id/end", c.EndLiveStream)
 	}
 }
 
@@ -93,7 +193,24 @@ func (c *videoControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
 
 // RegisterOpsApi 注册运维API
 func (c *videoControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
-	// 运维API实现
+	// 审核接口允许审核员和管理员，其余运维接口只允许管理员
+	moderation := router.Group("/v1/moderation", middleware.AuthRequired(), middleware.ModeratorRequired())
+	{
+		moderation.GET("/queue", c.GetReviewQueue)
+		moderation.POST("/videos/:id/claim", c.ClaimReview)
+		moderation.POST("/videos/:id/approve", c.ApproveReview)
+		moderation.POST("/videos/:id/reject", c.RejectReview)
+		moderation.GET("/videos/:id/logs", c.GetReviewLogs)
+	}
+	videos := router.Group("/v1/videos", middleware.AuthRequired(), middleware.AdminRequired())
+	{
+		videos.GET("/:id/status-history", c.GetStatusHistory)
+		videos.POST("/:id/verify-checksum", c.VerifyChecksum)
+		videos.GET("/:id/pipeline", c.GetPipeline)
+		videos.POST("/:id/pipeline/steps/:step/rerun", c.RerunPipelineStep)
+	}
+	router.POST("/v1/reconcile", middleware.AuthRequired(), middleware.AdminRequired(), c.Reconcile)
+	router.POST("/v1/encryption/rewrap", middleware.AuthRequired(), middleware.AdminRequired(), c.RewrapKeys)
 }
 
 // UploadVideo 上传视频
@@ -109,6 +226,16 @@ func (c *videoControllerImpl) UploadVideo(ctx *gin.Context) {
 	cmd.Title = ctx.PostForm("title")
 	cmd.Description = ctx.PostForm("description")
 	cmd.Format = ctx.PostForm("format")
+	cmd.ContentMD5 = headerOrForm(ctx, "Content-MD5", "content_md5")
+	cmd.ChecksumSHA256 = headerOrForm(ctx, "X-Checksum-SHA256", "checksum_sha256")
+	if keepMetadata := ctx.PostForm("keep_metadata"); keepMetadata != "" {
+		v, err := strconv.ParseBool(keepMetadata)
+		if err != nil {
+			restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "keep_metadata"))
+			return
+		}
+		cmd.KeepMetadata = v
+	}
 
 	// 获取文件
 	file, err := ctx.FormFile("file")
@@ -138,6 +265,16 @@ func (c *videoControllerImpl) UploadSyncVideo(ctx *gin.Context) {
 	cmd.Title = ctx.PostForm("title")
 	cmd.Description = ctx.PostForm("description")
 	cmd.Format = ctx.PostForm("format")
+	cmd.ContentMD5 = headerOrForm(ctx, "Content-MD5", "content_md5")
+	cmd.ChecksumSHA256 = headerOrForm(ctx, "X-Checksum-SHA256", "checksum_sha256")
+	if keepMetadata := ctx.PostForm("keep_metadata"); keepMetadata != "" {
+		v, err := strconv.ParseBool(keepMetadata)
+		if err != nil {
+			restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "keep_metadata"))
+			return
+		}
+		cmd.KeepMetadata = v
+	}
 
 	// 获取文件
 	file, err := ctx.FormFile("file")
@@ -160,10 +297,88 @@ func (c *videoControllerImpl) UploadSyncVideo(ctx *gin.Context) {
 
 // GetVideo 获取视频
 func (c *videoControllerImpl) GetVideo(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{"message": "get video endpoint"})
+	var query cqe.GetVideoQuery
+	if err := ctx.ShouldBindUri(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	query.UserUUID, _ = middleware.GetCurrentUserUUID(ctx)
+	result, err := c.videoApp.GetVideo(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
 }
 
-// GetVideoList 获取视频列表
+// GetVideoList 获取公开视频列表
 func (c *videoControllerImpl) GetVideoList(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{"message": "get video list endpoint"})
+	var query cqe.ListVideosQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "query"))
+		return
+	}
+	result, err := c.videoApp.ListVideos(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetMyVideoList 获取当前用户的视频列表
+func (c *videoControllerImpl) GetMyVideoList(ctx *gin.Context) {
+	var query cqe.ListVideosQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "query"))
+		return
+	}
+	query.UserUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.videoApp.ListUserVideos(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// DeleteVideo 删除当前用户的视频
+func (c *videoControllerImpl) DeleteVideo(ctx *gin.Context) {
+	cmd := cqe.DeleteVideoCommand{
+		UserUUID:  middleware.MustGetCurrentUserUUID(ctx),
+		VideoUUID: ctx.Param("id"),
+	}
+	if err := c.videoApp.DeleteVideo(ctx.Request.Context(), &cmd); err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, nil)
+}
+
+// VerifyChecksum 重新读取存储中的源文件并与记录的校验和比对
+func (c *videoControllerImpl) VerifyChecksum(ctx *gin.Context) {
+	result, err := c.videoApp.VerifyChecksum(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// headerOrForm 优先读取请求头，没有时读取同名表单字段
+func headerOrForm(ctx *gin.Context, header, field string) string {
+	if value := ctx.GetHeader(header); value != "" {
+		return value
+	}
+	return ctx.PostForm(field)
+}
+
+// GetStatusHistory 获取视频的状态迁移历史
+func (c *videoControllerImpl) GetStatusHistory(ctx *gin.Context) {
+	result, err := c.videoApp.GetStatusHistory(ctx.Request.Context(), middleware.MustGetCurrentUserUUID(ctx), ctx.Param("id"))
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
 }
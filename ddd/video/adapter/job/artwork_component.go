@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultArtworkPollInterval 未配置时扫描待生成记录的间隔
+const defaultArtworkPollInterval = 10 * time.Second
+
+// ArtworkComponentPlugin 封面和缩略图生成组件插件
+type ArtworkComponentPlugin struct {
+}
+
+func (p *ArtworkComponentPlugin) Name() string {
+	return "videoArtworkComponentPlugin"
+}
+
+func (p *ArtworkComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.ArtworkConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Artwork
+	}
+	return &artworkComponent{
+		cfg:        cfg,
+		artworkApp: app.DefaultArtworkApp(),
+	}
+}
+
+// artworkComponent 定期领取到期的记录并生成，本轮领满时立即开始下一轮；未启用时不做任何事
+type artworkComponent struct {
+	cfg        config.ArtworkConfig
+	artworkApp app.ArtworkApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *artworkComponent) GetName() string {
+	return "videoArtworkComponent"
+}
+
+// Start 启动生成
+func (c *artworkComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultArtworkPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("artwork component started, poll interval: %s", interval))
+	return nil
+}
+
+// Stop 停止生成，正在执行的生成被中止，租约到期后由其他实例重新执行
+func (c *artworkComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *artworkComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			processed, err := c.artworkApp.ProcessDue(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("artwork poll failed: %v", err))
+			}
+			next := interval
+			if processed > 0 && ctx.Err() == nil {
+				next = 0
+			}
+			timer.Reset(next)
+		}
+	}
+}
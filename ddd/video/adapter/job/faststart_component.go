@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultFaststartPollInterval 未配置时扫描待处理记录的间隔
+const defaultFaststartPollInterval = 10 * time.Second
+
+// FaststartComponentPlugin 源文件 faststart 改写组件插件
+type FaststartComponentPlugin struct {
+}
+
+func (p *FaststartComponentPlugin) Name() string {
+	return "videoFaststartComponentPlugin"
+}
+
+func (p *FaststartComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.FaststartConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Faststart
+	}
+	return &faststartComponent{
+		cfg:          cfg,
+		faststartApp: app.DefaultFaststartApp(),
+	}
+}
+
+// faststartComponent 定期领取到期的记录并改写，本轮领满时立即开始下一轮；未启用时不做任何事
+type faststartComponent struct {
+	cfg          config.FaststartConfig
+	faststartApp app.FaststartApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *faststartComponent) GetName() string {
+	return "videoFaststartComponent"
+}
+
+// Start 启动改写
+func (c *faststartComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultFaststartPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("faststart component started, poll interval: %s", interval))
+	return nil
+}
+
+// Stop 停止改写，正在执行的改写被中止，租约到期后由其他实例重新执行
+func (c *faststartComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *faststartComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			processed, err := c.faststartApp.ProcessDue(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("faststart poll failed: %v", err))
+			}
+			next := interval
+			if processed > 0 && ctx.Err() == nil {
+				next = 0
+			}
+			timer.Reset(next)
+		}
+	}
+}
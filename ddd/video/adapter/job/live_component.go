@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultLivePollInterval 未配置时检查空闲直播和待转换直播的间隔
+const defaultLivePollInterval = 10 * time.Second
+
+// LiveComponentPlugin 直播组件插件
+type LiveComponentPlugin struct {
+}
+
+func (p *LiveComponentPlugin) Name() string {
+	return "videoLiveComponentPlugin"
+}
+
+func (p *LiveComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.LiveConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Live
+	}
+	return &liveComponent{
+		cfg:     cfg,
+		liveApp: app.DefaultLiveApp(),
+	}
+}
+
+// liveComponent 定期结束推流中断的直播，并把已结束的直播转换为视频，本轮领满时立即开始下一轮；未启用时不做任何事
+type liveComponent struct {
+	cfg     config.LiveConfig
+	liveApp app.LiveApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *liveComponent) GetName() string {
+	return "videoLiveComponent"
+}
+
+// Start 启动直播后台任务
+func (c *liveComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultLivePollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("live component started, poll interval: %s", interval))
+	return nil
+}
+
+// Stop 停止后台任务，正在执行的转换被中止，租约到期后由其他实例重新执行
+func (c *liveComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *liveComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			processed, err := c.liveApp.ProcessDue(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("live poll failed: %v", err))
+			}
+			next := interval
+			if processed > 0 && ctx.Err() == nil {
+				next = 0
+			}
+			timer.Reset(next)
+		}
+	}
+}
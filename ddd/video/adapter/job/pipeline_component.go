@@ -0,0 +1,88 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultPipelinePollInterval 未配置时推进流水线的间隔
+const defaultPipelinePollInterval = 5 * time.Second
+
+// PipelineComponentPlugin 视频处理流水线组件插件
+type PipelineComponentPlugin struct {
+}
+
+func (p *PipelineComponentPlugin) Name() string {
+	return "videoPipelineComponentPlugin"
+}
+
+func (p *PipelineComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.PipelineConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Pipeline
+	}
+	return &pipelineComponent{
+		cfg:         cfg,
+		pipelineApp: app.DefaultPipelineApp(),
+	}
+}
+
+// pipelineComponent 定期查看执行中的步骤、开始依赖已完成的步骤并重试到期的失败步骤。
+// 执行中的步骤每轮都会被查看，因此每轮之后都等待一个间隔，不立即开始下一轮
+type pipelineComponent struct {
+	cfg         config.PipelineConfig
+	pipelineApp app.PipelineApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *pipelineComponent) GetName() string {
+	return "videoPipelineComponent"
+}
+
+// Start 启动推进
+func (c *pipelineComponent) Start() error {
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultPipelinePollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("pipeline component started, poll interval: %s", interval))
+	return nil
+}
+
+// Stop 停止推进，已调度的处理不受影响
+func (c *pipelineComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *pipelineComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.pipelineApp.AdvanceDue(ctx); err != nil {
+				logger.Error(fmt.Sprintf("pipeline poll failed: %v", err))
+			}
+		}
+	}
+}
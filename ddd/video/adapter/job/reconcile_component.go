@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultReconcileInterval 未配置时的定时对账间隔
+const defaultReconcileInterval = 24 * time.Hour
+
+// ReconcileComponentPlugin 定时对账组件插件
+type ReconcileComponentPlugin struct {
+}
+
+func (p *ReconcileComponentPlugin) Name() string {
+	return "videoReconcileComponentPlugin"
+}
+
+func (p *ReconcileComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.ReconcileConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Reconcile
+	}
+	return &reconcileComponent{
+		cfg:          cfg,
+		reconcileApp: app.DefaultReconcileApp(),
+	}
+}
+
+// reconcileComponent 按配置的间隔定时执行对账，未启用时不做任何事
+type reconcileComponent struct {
+	cfg          config.ReconcileConfig
+	reconcileApp app.ReconcileApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *reconcileComponent) GetName() string {
+	return "videoReconcileComponent"
+}
+
+// Start 启动定时对账
+func (c *reconcileComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.Interval
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("reconcile component started, interval: %s, dry_run: %v", interval, c.cfg.DryRun))
+	return nil
+}
+
+// Stop 停止定时对账，等待正在执行的对账退出
+func (c *reconcileComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *reconcileComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.reconcileApp.RunScheduled(ctx); err != nil {
+				logger.Error(fmt.Sprintf("scheduled reconcile failed: %v", err))
+			}
+		}
+	}
+}
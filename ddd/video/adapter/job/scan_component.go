@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultScanPollInterval 未配置时扫描待处理记录的间隔
+const defaultScanPollInterval = 5 * time.Second
+
+// ScanComponentPlugin 源文件恶意软件扫描组件插件
+type ScanComponentPlugin struct {
+}
+
+func (p *ScanComponentPlugin) Name() string {
+	return "videoScanComponentPlugin"
+}
+
+func (p *ScanComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.ScanConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Scan
+	}
+	return &scanComponent{
+		cfg:     cfg,
+		scanApp: app.DefaultScanApp(),
+	}
+}
+
+// scanComponent 定期领取到期的记录并扫描，本轮领满时立即开始下一轮；未启用时不做任何事
+type scanComponent struct {
+	cfg     config.ScanConfig
+	scanApp app.ScanApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *scanComponent) GetName() string {
+	return "videoScanComponent"
+}
+
+// Start 启动扫描
+func (c *scanComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultScanPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("scan component started, driver: %s, poll interval: %s", c.cfg.Driver, interval))
+	return nil
+}
+
+// Stop 停止扫描，正在执行的扫描被中止，租约到期后由其他实例重新执行
+func (c *scanComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *scanComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			processed, err := c.scanApp.ProcessDue(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("scan poll failed: %v", err))
+			}
+			next := interval
+			if processed > 0 && ctx.Err() == nil {
+				next = 0
+			}
+			timer.Reset(next)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultSourcePurgeInterval 未配置时的定时清理间隔
+const defaultSourcePurgeInterval = time.Hour
+
+// SourcePurgeComponentPlugin 定时清理过期源文件版本组件插件
+type SourcePurgeComponentPlugin struct {
+}
+
+func (p *SourcePurgeComponentPlugin) Name() string {
+	return "videoSourcePurgeComponentPlugin"
+}
+
+func (p *SourcePurgeComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.SourceVersionConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.SourceVersion
+	}
+	return &sourcePurgeComponent{
+		cfg:              cfg,
+		sourceVersionApp: app.DefaultSourceVersionApp(),
+	}
+}
+
+// sourcePurgeComponent 按配置的间隔删除超过保留期的源文件版本，未启用时不做任何事
+type sourcePurgeComponent struct {
+	cfg              config.SourceVersionConfig
+	sourceVersionApp app.SourceVersionApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *sourcePurgeComponent) GetName() string {
+	return "videoSourcePurgeComponent"
+}
+
+// Start 启动定时清理
+func (c *sourcePurgeComponent) Start() error {
+	if !c.cfg.PurgeEnabled {
+		return nil
+	}
+	interval := c.cfg.PurgeInterval
+	if interval <= 0 {
+		interval = defaultSourcePurgeInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("source purge component started, interval: %s", interval))
+	return nil
+}
+
+// Stop 停止定时清理，等待正在执行的清理退出
+func (c *sourcePurgeComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *sourcePurgeComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := c.sourceVersionApp.PurgeExpired(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("scheduled source purge failed after %d versions: %v", purged, err))
+				continue
+			}
+			if purged > 0 {
+				logger.Info(fmt.Sprintf("scheduled source purge removed %d versions", purged))
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/video/application/app"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultTranscodePollInterval 未配置时扫描待转码档位的间隔
+const defaultTranscodePollInterval = 5 * time.Second
+
+// TranscodeComponentPlugin 视频转码组件插件
+type TranscodeComponentPlugin struct {
+}
+
+func (p *TranscodeComponentPlugin) Name() string {
+	return "videoTranscodeComponentPlugin"
+}
+
+func (p *TranscodeComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var cfg config.TranscodeConfig
+	if deps != nil && deps.Config != nil {
+		cfg = deps.Config.Transcode
+	}
+	return &transcodeComponent{
+		cfg:          cfg,
+		transcodeApp: app.DefaultTranscodeApp(),
+	}
+}
+
+// transcodeComponent 定期领取到期的转码并执行，本轮领满时立即开始下一轮；未启用时不做任何事
+type transcodeComponent struct {
+	cfg          config.TranscodeConfig
+	transcodeApp app.TranscodeApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *transcodeComponent) GetName() string {
+	return "videoTranscodeComponent"
+}
+
+// Start 启动转码
+func (c *transcodeComponent) Start() error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	interval := c.cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultTranscodePollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx, interval)
+	logger.Info(fmt.Sprintf("transcode component started, driver: %s, poll interval: %s", c.cfg.Driver, interval))
+	return nil
+}
+
+// Stop 停止转码，正在执行的转码被中止，租约到期后由其他实例重新执行
+func (c *transcodeComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *transcodeComponent) loop(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			processed, err := c.transcodeApp.ProcessDue(ctx)
+			if err != nil {
+				logger.Error(fmt.Sprintf("transcode poll failed: %v", err))
+			}
+			next := interval
+			if processed > 0 && ctx.Err() == nil {
+				next = 0
+			}
+			timer.Reset(next)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/subtitle"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 未配置时的封面和缩略图参数
+const (
+	defaultPosterWidth       = 1280
+	defaultThumbnailInterval = 10 * time.Second
+	defaultThumbnailWidth    = 160
+	defaultSpriteColumns     = 10
+	defaultSpriteRows        = 10
+	defaultCoverMaxSize      = 10 * 1024 * 1024
+	defaultCoverMaxWidth     = 1920
+	defaultCoverMaxHeight    = 1080
+	defaultArtworkTimeout    = 30 * time.Minute
+	// artworkLeaseMargin 生成不续租，租约在超时之外多留的时间
+	artworkLeaseMargin = time.Minute
+	// posterFile 服务端解密地址中代表当前封面的文件名，封面可能是候选帧或自定义封面
+	posterFile = "poster"
+)
+
+// defaultPosterOffsets 未配置时候选封面的截取时间点
+var defaultPosterOffsets = []time.Duration{2 * time.Second, 10 * time.Second, 30 * time.Second, 60 * time.Second}
+
+var (
+	onceArtworkApp      sync.Once
+	singletonArtworkApp ArtworkApp
+)
+
+// ArtworkApp 封面和缩略图应用服务
+type ArtworkApp interface {
+	// CancelDeleted 视频被删除后取消其未完成的生成
+	CancelDeleted(ctx context.Context, videoUUID string) error
+	// ProcessDue 领取到期的记录并发执行，返回执行的数量
+	ProcessDue(ctx context.Context) (int, error)
+	// GetArtwork 视频所有者查看封面、候选帧和生成状态
+	GetArtwork(ctx context.Context, userUUID, videoUUID string) (*dto.ArtworkDto, error)
+	// SelectPoster 视频所有者选择候选帧作为封面
+	SelectPoster(ctx context.Context, cmd *cqe.SelectPosterCommand) (*dto.ArtworkDto, error)
+	// UploadCover 视频所有者上传自定义封面，重新生成候选帧时不会被替换
+	UploadCover(ctx context.Context, cmd *cqe.UploadCoverCommand) (*dto.ArtworkDto, error)
+	// PosterURL 获取封面地址，未加密的封面为短期预签名地址，加密的封面经服务端解密
+	PosterURL(ctx context.Context, query *cqe.ArtworkQuery) (string, error)
+	// Storyboard 获取缩略图轨道，雪碧图地址替换为短期有效的预签名地址或服务端解密地址
+	Storyboard(ctx context.Context, query *cqe.ArtworkQuery) (*dto.StoryboardDto, error)
+	// OpenArtworkFile 读取加密存储的封面、候选帧或雪碧图的明文
+	OpenArtworkFile(ctx context.Context, query *cqe.ArtworkFileQuery) (*dto.VideoStreamDto, error)
+}
+
+type artworkApp struct {
+	minioService   gateway.MinioService
+	videoRepo      repo.VideoRepository
+	artworkRepo    repo.ArtworkRepository
+	artworkService service.ArtworkService
+}
+
+func DefaultArtworkApp() ArtworkApp {
+	assert.NotCircular()
+	onceArtworkApp.Do(func() {
+		singletonArtworkApp = &artworkApp{
+			minioService:   minio.DefaultMinioService(),
+			videoRepo:      persistence.NewVideoRepository(),
+			artworkRepo:    persistence.NewArtworkRepository(),
+			artworkService: service.DefaultArtworkService(),
+		}
+	})
+	assert.NotNil(singletonArtworkApp)
+	return singletonArtworkApp
+}
+
+// CancelDeleted 取消已删除视频的生成
+func (a *artworkApp) CancelDeleted(ctx context.Context, videoUUID string) error {
+	_, err := a.artworkService.Cancel(ctx, videoUUID, "video deleted")
+	return err
+}
+
+// ProcessDue 每个实例同时生成的数量不超过配置的并发数
+func (a *artworkApp) ProcessDue(ctx context.Context) (int, error) {
+	cfg := artworkConfig()
+	artworks, err := a.artworkService.ClaimDue(ctx, cfg.Concurrency, cfg.Timeout+artworkLeaseMargin)
+	if err != nil {
+		return 0, err
+	}
+	opts := vo.NewArtworkOptions(cfg.PosterOffsets, cfg.PosterWidth, cfg.ThumbnailInterval, cfg.ThumbnailWidth,
+		cfg.SpriteColumns, cfg.SpriteRows, cfg.MaxAttempts, cfg.RetryDelay, cfg.Timeout, cfg.WorkDir)
+	var wg sync.WaitGroup
+	for _, artwork := range artworks {
+		wg.Add(1)
+		go func(artwork *entity.Artwork) {
+			defer wg.Done()
+			if err := a.artworkService.Generate(ctx, artwork, opts); err != nil {
+				logger.Error(fmt.Sprintf("generate artwork %s of video %s failed: %v", artwork.UUID(), artwork.VideoUuid(), err))
+			}
+		}(artwork)
+	}
+	wg.Wait()
+	return len(artworks), nil
+}
+
+// GetArtwork 查看封面和候选帧
+func (a *artworkApp) GetArtwork(ctx context.Context, userUUID, videoUUID string) (*dto.ArtworkDto, error) {
+	video, err := a.ownedVideo(ctx, userUUID, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return a.toArtworkDto(ctx, video)
+}
+
+// SelectPoster 选择候选帧
+func (a *artworkApp) SelectPoster(ctx context.Context, cmd *cqe.SelectPosterCommand) (*dto.ArtworkDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	video, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.artworkService.SelectPoster(ctx, video, *cmd.Index); err != nil {
+		return nil, err
+	}
+	return a.toArtworkDto(ctx, video)
+}
+
+// UploadCover 读取前先按文件大小拒绝，图片内容由领域服务校验
+func (a *artworkApp) UploadCover(ctx context.Context, cmd *cqe.UploadCoverCommand) (*dto.ArtworkDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := artworkConfig()
+	if cmd.File.Size > cfg.CoverMaxSize {
+		return nil, errno.NewSimpleBizError(errno.ErrCoverInvalid, nil, fmt.Sprintf("file larger than %d bytes", cfg.CoverMaxSize))
+	}
+	video, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	src, err := cmd.File.Open()
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, cfg.CoverMaxSize+1))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if int64(len(data)) > cfg.CoverMaxSize {
+		return nil, errno.NewSimpleBizError(errno.ErrCoverInvalid, nil, fmt.Sprintf("file larger than %d bytes", cfg.CoverMaxSize))
+	}
+	if err := a.artworkService.SetCover(ctx, video, data, cfg.CoverMaxWidth, cfg.CoverMaxHeight); err != nil {
+		return nil, err
+	}
+	return a.toArtworkDto(ctx, video)
+}
+
+// PosterURL 封面对能看到视频的用户开放，列表中的公开视频不需要登录
+func (a *artworkApp) PosterURL(ctx context.Context, query *cqe.ArtworkQuery) (string, error) {
+	video, err := a.visibleVideo(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	if video.PosterPath() == "" {
+		return "", errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+	}
+	_, expiry := playbackConfig()
+	url, err := mediaObjectURL(ctx, a.minioService, video.PosterPath(), expiry, artworkFileURL(video, posterFile, query.ShareToken))
+	if err != nil {
+		return "", errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return url, nil
+}
+
+// Storyboard 只返回为视频当前源文件生成的缩略图轨道，每张雪碧图只签名一次
+func (a *artworkApp) Storyboard(ctx context.Context, query *cqe.ArtworkQuery) (*dto.StoryboardDto, error) {
+	video, err := a.visibleVideo(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	artwork, err := a.artworkService.Current(ctx, video.UUID())
+	if err != nil {
+		return nil, err
+	}
+	if artwork == nil || !artwork.IsCurrentFor(video) || artwork.StoryboardPath() == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+	}
+	data, err := a.minioService.DownloadVideo(ctx, artwork.StoryboardPath())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	cues, err := subtitle.ParseWebVTT(bytes.NewReader(data))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	_, expiry := playbackConfig()
+	signed := make(map[string]string)
+	for i := range cues {
+		sprite, fragment, _ := strings.Cut(cues[i].Text, "#")
+		if _, ok := signed[sprite]; !ok {
+			url, err := mediaObjectURL(ctx, a.minioService, artwork.Dir()+path.Base(sprite), expiry,
+				artworkFileURL(video, path.Base(sprite), query.ShareToken))
+			if err != nil {
+				return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+			}
+			signed[sprite] = url
+		}
+		cues[i].Text = signed[sprite] + "#" + fragment
+	}
+	var buf bytes.Buffer
+	if err := subtitle.WriteWebVTT(&buf, cues); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return &dto.StoryboardDto{Content: buf.Bytes(), ContentType: "text/vtt; charset=utf-8"}, nil
+}
+
+// OpenArtworkFile 封面按视频当前的封面路径读取，其余文件只能是当前一次生成目录下的文件
+func (a *artworkApp) OpenArtworkFile(ctx context.Context, query *cqe.ArtworkFileQuery) (*dto.VideoStreamDto, error) {
+	video, err := a.visibleVideo(ctx, &query.ArtworkQuery)
+	if err != nil {
+		return nil, err
+	}
+	objectName := video.PosterPath()
+	if query.File != posterFile {
+		if query.File != path.Base(query.File) || query.File == "." || query.File == ".." {
+			return nil, errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+		}
+		artwork, err := a.artworkService.Current(ctx, video.UUID())
+		if err != nil {
+			return nil, err
+		}
+		if artwork == nil {
+			return nil, errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+		}
+		objectName = artwork.Dir() + query.File
+	}
+	if objectName == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+	}
+	return openMediaObject(ctx, a.minioService, objectName, errno.ErrArtworkNotFound)
+}
+
+// visibleVideo 能看到视频的用户、审核员或分享令牌的持有者可以获取封面和缩略图
+func (a *artworkApp) visibleVideo(ctx context.Context, query *cqe.ArtworkQuery) (*entity.Video, error) {
+	video, err := a.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || !(video.IsVisibleTo(query.UserUUID) || config.IsModerator(query.UserUUID) || verifyShareToken(video, query.ShareToken) != "") {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	return video, nil
+}
+
+// ownedVideo 获取视频并校验所有者
+func (a *artworkApp) ownedVideo(ctx context.Context, userUUID, videoUUID string) (*entity.Video, error) {
+	video, err := a.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(userUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	return video, nil
+}
+
+// toArtworkDto 候选帧地址为短期有效的预签名地址或服务端解密地址
+func (a *artworkApp) toArtworkDto(ctx context.Context, video *entity.Video) (*dto.ArtworkDto, error) {
+	result := &dto.ArtworkDto{
+		VideoUUID:   video.UUID(),
+		PosterURL:   posterURL(video),
+		CustomCover: vo.IsCustomCover(video.PosterPath()),
+		Candidates:  []*dto.PosterCandidateDto{},
+	}
+	latest, err := a.artworkRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if latest != nil {
+		result.Status = latest.Status().Value()
+		result.ErrorMsg = latest.ErrorMsg()
+	}
+	current, err := a.artworkService.Current(ctx, video.UUID())
+	if err != nil || current == nil {
+		return result, err
+	}
+	if current.IsCurrentFor(video) && current.StoryboardPath() != "" {
+		result.StoryboardURL = storyboardURL(video)
+	}
+	_, expiry := playbackConfig()
+	for i, candidate := range current.Candidates() {
+		url, err := mediaObjectURL(ctx, a.minioService, candidate.Path(), expiry, artworkFileURL(video, path.Base(candidate.Path()), ""))
+		if err != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+		}
+		result.Candidates = append(result.Candidates, &dto.PosterCandidateDto{
+			Index:    i,
+			OffsetMs: candidate.Offset().Milliseconds(),
+			URL:      url,
+			Selected: candidate.Path() == video.PosterPath(),
+		})
+	}
+	return result, nil
+}
+
+// posterURL 封面地址，还没有封面时为空
+func posterURL(video *entity.Video) string {
+	if video.PosterPath() == "" {
+		return ""
+	}
+	return fmt.Sprintf("/api/v1/videos/%s/poster", video.UUID())
+}
+
+// artworkFileURL 加密存储的封面和雪碧图的服务端解密地址，分享令牌的持有者不登录也可以访问
+func artworkFileURL(video *entity.Video, file, shareToken string) string {
+	return withShareToken(fmt.Sprintf("/api/v1/videos/%s/artwork/%s", video.UUID(), file), shareToken)
+}
+
+// storyboardURL 缩略图轨道地址
+func storyboardURL(video *entity.Video) string {
+	return fmt.Sprintf("/api/v1/videos/%s/storyboard.vtt", video.UUID())
+}
+
+// artworkConfig 封面和缩略图配置，未配置的参数使用默认值
+func artworkConfig() config.ArtworkConfig {
+	var cfg config.ArtworkConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Artwork
+	}
+	if len(cfg.PosterOffsets) == 0 {
+		cfg.PosterOffsets = defaultPosterOffsets
+	}
+	if cfg.PosterWidth <= 0 {
+		cfg.PosterWidth = defaultPosterWidth
+	}
+	if cfg.ThumbnailInterval <= 0 {
+		cfg.ThumbnailInterval = defaultThumbnailInterval
+	}
+	if cfg.ThumbnailWidth <= 0 {
+		cfg.ThumbnailWidth = defaultThumbnailWidth
+	}
+	if cfg.SpriteColumns <= 0 {
+		cfg.SpriteColumns = defaultSpriteColumns
+	}
+	if cfg.SpriteRows <= 0 {
+		cfg.SpriteRows = defaultSpriteRows
+	}
+	if cfg.CoverMaxSize <= 0 {
+		cfg.CoverMaxSize = defaultCoverMaxSize
+	}
+	if cfg.CoverMaxWidth <= 0 {
+		cfg.CoverMaxWidth = defaultCoverMaxWidth
+	}
+	if cfg.CoverMaxHeight <= 0 {
+		cfg.CoverMaxHeight = defaultCoverMaxHeight
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultTranscodeConcurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultTranscodeMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultTranscodeRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultArtworkTimeout
+	}
+	return cfg
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/subtitle"
+	"sync"
+)
+
+var (
+	onceCaptionApp      sync.Once
+	singletonCaptionApp CaptionApp
+)
+
+// CaptionApp 字幕应用服务
+type CaptionApp interface {
+	UploadCaption(ctx context.Context, cmd *cqe.UploadCaptionCommand) (*dto.CaptionTrackDto, error)
+	ListCaptions(ctx context.Context, query *cqe.CaptionQuery) ([]*dto.CaptionTrackDto, error)
+	DeleteCaption(ctx context.Context, cmd *cqe.DeleteCaptionCommand) error
+}
+
+type captionApp struct {
+	minioService gateway.MinioService
+	videoRepo    repo.VideoRepository
+	captionRepo  repo.CaptionRepository
+}
+
+func DefaultCaptionApp() CaptionApp {
+	assert.NotCircular()
+	onceCaptionApp.Do(func() {
+		singletonCaptionApp = &captionApp{
+			minioService: minio.DefaultMinioService(),
+			videoRepo:    persistence.NewVideoRepository(),
+			captionRepo:  persistence.NewCaptionRepository(),
+		}
+	})
+	assert.NotNil(singletonCaptionApp)
+	return singletonCaptionApp
+}
+
+// UploadCaption 上传字幕：解析SRT/WebVTT，统一转换为WebVTT后存入视频资源目录
+func (a *captionApp) UploadCaption(ctx context.Context, cmd *cqe.UploadCaptionCommand) (*dto.CaptionTrackDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	video, err := a.videoRepo.FindByUUID(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(cmd.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+
+	format, _ := subtitle.DetectFormat(cmd.File.Filename)
+	src, err := cmd.File.Open()
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "file")
+	}
+	defer src.Close()
+
+	cues, err := subtitle.Parse(src, format)
+	if err != nil {
+		var syntaxErr *subtitle.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, errno.NewSimpleBizError(errno.ErrCaptionInvalid, err, syntaxErr.Error())
+		}
+		return nil, errno.NewSimpleBizError(errno.ErrCaptionInvalid, err, err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := subtitle.WriteWebVTT(&buf, cues); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+
+	sourceFormat, _ := vo.NewCaptionFormat(string(format))
+	caption := entity.DefaultCaptionTrack(video, cmd.Language, cmd.Label, cmd.IsDefault, sourceFormat)
+	if err := a.minioService.PutObject(ctx, caption.StoragePath(), &buf, int64(buf.Len()), "text/vtt; charset=utf-8"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if err := a.captionRepo.Save(ctx, caption); err != nil {
+		// 元数据写入失败时清理已上传的对象，避免产生孤儿文件
+		if delErr := a.minioService.DeleteVideo(ctx, caption.StoragePath()); delErr != nil {
+			logger.Error(fmt.Sprintf("UploadCaption cleanup object %s failed: %v", caption.StoragePath(), delErr))
+		}
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+
+	return toCaptionTrackDto(ctx, a.minioService, caption), nil
+}
+
+// ListCaptions 获取视频的字幕轨道列表，可见性与播放相同；所有者在视频不可播放时也可以查看
+func (a *captionApp) ListCaptions(ctx context.Context, query *cqe.CaptionQuery) ([]*dto.CaptionTrackDto, error) {
+	video, err := a.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || !video.IsOwnedBy(query.UserUUID) {
+		if video, err = playableVideo(ctx, a.videoRepo, query.VideoUUID, query.UserUUID, query.ShareToken); err != nil {
+			return nil, err
+		}
+	}
+	captions, err := a.captionRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toCaptionTrackDtos(ctx, a.minioService, captions), nil
+}
+
+// DeleteCaption 删除字幕轨道
+func (a *captionApp) DeleteCaption(ctx context.Context, cmd *cqe.DeleteCaptionCommand) error {
+	video, err := a.videoRepo.FindByUUID(ctx, cmd.VideoUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(cmd.UserUUID) {
+		return errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+
+	caption, err := a.captionRepo.FindByUUID(ctx, cmd.CaptionUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if caption == nil || caption.VideoUuid() != video.UUID() {
+		return errno.NewSimpleBizError(errno.ErrCaptionNotFound, nil)
+	}
+	if err := a.captionRepo.Delete(ctx, caption.UUID()); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if err := a.minioService.DeleteVideo(ctx, caption.StoragePath()); err != nil {
+		logger.Error(fmt.Sprintf("DeleteCaption remove object %s failed: %v", caption.StoragePath(), err))
+	}
+	return nil
+}
+
+// toCaptionTrackDtos 字幕实体列表转DTO
+func toCaptionTrackDtos(ctx context.Context, minioService gateway.MinioService, captions []*entity.CaptionTrack) []*dto.CaptionTrackDto {
+	dtos := make([]*dto.CaptionTrackDto, 0, len(captions))
+	for _, caption := range captions {
+		dtos = append(dtos, toCaptionTrackDto(ctx, minioService, caption))
+	}
+	return dtos
+}
+
+// toCaptionTrackDto 字幕实体转DTO，附带预签名访问地址
+func toCaptionTrackDto(ctx context.Context, minioService gateway.MinioService, caption *entity.CaptionTrack) *dto.CaptionTrackDto {
+	url, err := minioService.GetVideoURL(ctx, caption.StoragePath())
+	if err != nil {
+		logger.Error(fmt.Sprintf("caption %s presign url failed: %v", caption.UUID(), err))
+	}
+	return &dto.CaptionTrackDto{
+		CaptionUUID: caption.UUID(),
+		Language:    caption.Language(),
+		Label:       caption.Label(),
+		IsDefault:   caption.IsDefault(),
+		URL:         url,
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/subtitle"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	onceChapterApp      sync.Once
+	singletonChapterApp ChapterApp
+)
+
+// ChapterApp 视频章节应用服务
+type ChapterApp interface {
+	SetChapters(ctx context.Context, cmd *cqe.SetChaptersCommand) (*dto.ChaptersDto, error)
+	GetChapters(ctx context.Context, query *cqe.ChapterQuery) (*dto.ChaptersDto, error)
+	// ExportWebVTT 导出 WebVTT 章节轨道（kind="chapters"）
+	ExportWebVTT(ctx context.Context, query *cqe.ChapterQuery) ([]byte, error)
+}
+
+type chapterApp struct {
+	videoRepo   repo.VideoRepository
+	chapterRepo repo.ChapterRepository
+}
+
+func DefaultChapterApp() ChapterApp {
+	assert.NotCircular()
+	onceChapterApp.Do(func() {
+		singletonChapterApp = &chapterApp{
+			videoRepo:   persistence.NewVideoRepository(),
+			chapterRepo: persistence.NewChapterRepository(),
+		}
+	})
+	assert.NotNil(singletonChapterApp)
+	return singletonChapterApp
+}
+
+// SetChapters 显式设置视频章节，会覆盖从描述中解析出的章节
+func (a *chapterApp) SetChapters(ctx context.Context, cmd *cqe.SetChaptersCommand) (*dto.ChaptersDto, error) {
+	chapters, err := cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+	video, err := a.videoRepo.FindByUUID(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(cmd.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	if err := vo.ValidateChapters(chapters, video.Duration()); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrChapterInvalid, err, err.Error())
+	}
+
+	if err := a.chapterRepo.Replace(ctx, video.UUID(), chapters); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return a.buildChapters(ctx, video)
+}
+
+// GetChapters 获取视频章节
+func (a *chapterApp) GetChapters(ctx context.Context, query *cqe.ChapterQuery) (*dto.ChaptersDto, error) {
+	video, err := a.visibleVideo(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return a.buildChapters(ctx, video)
+}
+
+// ExportWebVTT 导出 WebVTT 章节轨道
+func (a *chapterApp) ExportWebVTT(ctx context.Context, query *cqe.ChapterQuery) ([]byte, error) {
+	video, err := a.visibleVideo(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	chapters, _, err := resolveChapters(ctx, a.chapterRepo, video)
+	if err != nil {
+		return nil, err
+	}
+
+	ends := vo.ChapterEnds(chapters, video.Duration())
+	cues := make([]subtitle.Cue, 0, len(chapters))
+	for i, chapter := range chapters {
+		cues = append(cues, subtitle.Cue{
+			ID:    strconv.Itoa(i + 1),
+			Start: chapter.Start(),
+			End:   ends[i],
+			Text:  chapter.Title(),
+		})
+	}
+	var buf bytes.Buffer
+	if err := subtitle.WriteWebVTT(&buf, cues); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// visibleVideo 章节的可见性与播放相同，所有者在视频不可播放时也可以查看
+func (a *chapterApp) visibleVideo(ctx context.Context, query *cqe.ChapterQuery) (*entity.Video, error) {
+	video, err := a.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video != nil && video.IsOwnedBy(query.UserUUID) {
+		return video, nil
+	}
+	return playableVideo(ctx, a.videoRepo, query.VideoUUID, query.UserUUID, query.ShareToken)
+}
+
+func (a *chapterApp) buildChapters(ctx context.Context, video *entity.Video) (*dto.ChaptersDto, error) {
+	chapters, source, err := resolveChapters(ctx, a.chapterRepo, video)
+	if err != nil {
+		return nil, err
+	}
+	return toChaptersDto(chapters, source, video.Duration()), nil
+}
+
+// resolveChapters 获取视频的有效章节：优先使用显式设置的章节，否则从描述中解析
+func resolveChapters(ctx context.Context, chapterRepo repo.ChapterRepository, video *entity.Video) ([]vo.Chapter, vo.ChapterSource, error) {
+	chapters, err := chapterRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, vo.ChapterSourceNone, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if len(chapters) > 0 {
+		return chapters, vo.ChapterSourceManual, nil
+	}
+	if chapters = vo.ParseDescriptionChapters(video.Description(), video.Duration()); len(chapters) > 0 {
+		return chapters, vo.ChapterSourceDescription, nil
+	}
+	return nil, vo.ChapterSourceNone, nil
+}
+
+// toChaptersDto 章节值对象转DTO
+func toChaptersDto(chapters []vo.Chapter, source vo.ChapterSource, duration time.Duration) *dto.ChaptersDto {
+	ends := vo.ChapterEnds(chapters, duration)
+	dtos := make([]*dto.ChapterDto, 0, len(chapters))
+	for i, chapter := range chapters {
+		dtos = append(dtos, &dto.ChapterDto{
+			Start:   vo.FormatChapterTimestamp(chapter.Start()),
+			End:     vo.FormatChapterTimestamp(ends[i]),
+			StartMs: chapter.Start().Milliseconds(),
+			EndMs:   ends[i].Milliseconds(),
+			Title:   chapter.Title(),
+		})
+	}
+	return &dto.ChaptersDto{
+		Source:   source.Value(),
+		Chapters: dtos,
+	}
+}
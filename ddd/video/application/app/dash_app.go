@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/dash"
+	"go-video/pkg/errno"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultSignedURLExpiry 未配置时DASH分片地址的有效期
+const defaultSignedURLExpiry = 6 * time.Hour
+
+// dashAudioChannels 转码输出固定为双声道
+const dashAudioChannels = 2
+
+// errNoSigningSecret 签名密钥和JWT密钥都没有配置
+var errNoSigningSecret = errors.New("playback signing secret is not configured")
+
+var (
+	onceDASHApp      sync.Once
+	singletonDASHApp DASHApp
+)
+
+// DASHApp DASH清单应用服务
+type DASHApp interface {
+	// Manifest 按当前已打包DASH的档位生成清单，分片地址带有短期有效的签名
+	Manifest(ctx context.Context, query *cqe.DASHManifestQuery) (*dto.PlaylistDto, error)
+	// SegmentURL 校验清单签发的分片地址，返回分片的预签名地址
+	SegmentURL(ctx context.Context, query *cqe.DASHSegmentQuery) (string, error)
+}
+
+type dashApp struct {
+	minioService  gateway.MinioService
+	videoRepo     repo.VideoRepository
+	renditionRepo repo.RenditionRepository
+}
+
+func DefaultDASHApp() DASHApp {
+	assert.NotCircular()
+	onceDASHApp.Do(func() {
+		singletonDASHApp = &dashApp{
+			minioService:  minio.DefaultMinioService(),
+			videoRepo:     persistence.NewVideoRepository(),
+			renditionRepo: persistence.NewRenditionRepository(),
+		}
+	})
+	assert.NotNil(singletonDASHApp)
+	return singletonDASHApp
+}
+
+// Manifest 视频和音频都按码率从高到低排列，音频按档位的音频码率去重；
+// SegmentTemplate 的地址相对清单地址，为 <转码输出UUID>/<分片模板>?expires=&signature=
+func (a *dashApp) Manifest(ctx context.Context, query *cqe.DASHManifestQuery) (*dto.PlaylistDto, error) {
+	if !dashEnabled() {
+		return nil, errno.NewSimpleBizError(errno.ErrManifestNotFound, nil)
+	}
+	video, renditions, err := visibleRenditions(ctx, a.videoRepo, a.renditionRepo, query.VideoUUID, query.UserUUID, "")
+	if err != nil {
+		return nil, err
+	}
+	secret, expiry := playbackConfig()
+	if secret == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, errNoSigningSecret)
+	}
+	expires := time.Now().Add(expiry)
+	mpd := &dash.MPD{}
+	audioBitrates := make(map[int]bool, len(renditions))
+	for _, rendition := range renditions {
+		pkg := rendition.DASH()
+		if pkg.IsEmpty() {
+			continue
+		}
+		signature := url.Values{
+			"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+			"signature": {vo.DASHSegmentSignature(secret, video.UUID(), rendition.UUID(), expires)},
+		}.Encode()
+		segmentURL := func(name string) string {
+			return rendition.UUID() + "/" + name + "?" + signature
+		}
+		mpd.Duration = max(mpd.Duration, pkg.Duration())
+		mpd.MinBufferTime = max(mpd.MinBufferTime, pkg.SegmentDuration())
+		mpd.Video = append(mpd.Video, dash.Representation{
+			ID:              "video-" + rendition.Profile().Name(),
+			Bandwidth:       pkg.VideoBandwidth(),
+			Codecs:          pkg.VideoCodecs(),
+			Width:           rendition.Width(),
+			Height:          rendition.Height(),
+			Initialization:  segmentURL(vo.DASHInitialization(vo.DASHVideo)),
+			Media:           segmentURL(vo.DASHSegmentTemplate(vo.DASHVideo)),
+			SegmentDuration: pkg.SegmentDuration(),
+		})
+		if !pkg.HasAudio() || audioBitrates[rendition.Profile().AudioBitrate()] {
+			continue
+		}
+		audioBitrates[rendition.Profile().AudioBitrate()] = true
+		mpd.Audio = append(mpd.Audio, dash.Representation{
+			ID:              "audio-" + rendition.Profile().Name(),
+			Bandwidth:       pkg.AudioBandwidth(),
+			Codecs:          pkg.AudioCodecs(),
+			AudioChannels:   dashAudioChannels,
+			Initialization:  segmentURL(vo.DASHInitialization(vo.DASHAudio)),
+			Media:           segmentURL(vo.DASHSegmentTemplate(vo.DASHAudio)),
+			SegmentDuration: pkg.SegmentDuration(),
+		})
+	}
+	if len(mpd.Video) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrManifestNotFound, nil)
+	}
+	for _, representations := range [][]dash.Representation{mpd.Video, mpd.Audio} {
+		sort.SliceStable(representations, func(i, j int) bool {
+			return representations[i].Bandwidth > representations[j].Bandwidth
+		})
+	}
+	content, err := mpd.Encode()
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return &dto.PlaylistDto{Content: content, ContentType: dash.ContentType}, nil
+}
+
+// SegmentURL 签名只能证明请求者在有效期内获取过清单，仍然需要确认视频可播放且该输出没有被替换
+func (a *dashApp) SegmentURL(ctx context.Context, query *cqe.DASHSegmentQuery) (string, error) {
+	unix, err := strconv.ParseInt(query.Expires, 10, 64)
+	if err != nil || !vo.IsDASHObject(query.Segment) {
+		return "", errno.NewSimpleBizError(errno.ErrSegmentURLInvalid, err)
+	}
+	expires := time.Unix(unix, 0)
+	secret, _ := playbackConfig()
+	if secret == "" {
+		return "", errno.NewSimpleBizError(errno.ErrInternalServer, errNoSigningSecret)
+	}
+	expected := vo.DASHSegmentSignature(secret, query.VideoUUID, query.RenditionUUID, expires)
+	if time.Now().After(expires) || !hmac.Equal([]byte(expected), []byte(query.Signature)) {
+		return "", errno.NewSimpleBizError(errno.ErrSegmentURLInvalid, nil)
+	}
+
+	rendition, err := a.renditionRepo.FindByUUID(ctx, query.RenditionUUID)
+	if err != nil {
+		return "", errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	video, err := a.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return "", errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || !video.Status().IsPlayable() {
+		return "", errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !hasCurrentDASH(video, rendition) {
+		return "", errno.NewSimpleBizError(errno.ErrRenditionNotFound, nil, query.RenditionUUID)
+	}
+	segmentURL, err := a.minioService.GetVideoURL(ctx, rendition.DASH().Dir()+query.Segment)
+	if err != nil {
+		return "", errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return segmentURL, nil
+}
+
+// hasCurrentDASH 未开启HLS加密，转码输出属于该视频的当前源文件、已完成且打包了DASH
+func hasCurrentDASH(video *entity.Video, rendition *entity.Rendition) bool {
+	return dashEnabled() && rendition != nil && rendition.IsCurrentFor(video) &&
+		rendition.Status() == vo.RenditionStatusSucceeded && !rendition.DASH().IsEmpty()
+}
+
+// dashEnabled DASH分片不加密，HLS加密时不提供DASH播放，开启加密前打包的明文分片也不再发放
+func dashEnabled() bool {
+	return !transcodeConfig().HLSEncryption
+}
+
+// playbackConfig DASH分片地址和分享令牌的签名密钥、分片地址的有效期，未配置密钥时使用JWT密钥
+func playbackConfig() (string, time.Duration) {
+	var cfg config.PlaybackConfig
+	var jwtSecret string
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Playback
+		jwtSecret = global.JWT.Secret
+	}
+	if cfg.SigningSecret == "" {
+		cfg.SigningSecret = jwtSecret
+	}
+	if cfg.SignedURLExpiry <= 0 {
+		cfg.SignedURLExpiry = defaultSignedURLExpiry
+	}
+	return cfg.SigningSecret, cfg.SignedURLExpiry
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"sync"
+)
+
+var (
+	onceEncryptionApp      sync.Once
+	singletonEncryptionApp EncryptionApp
+)
+
+// EncryptionApp 静态加密运维应用服务
+type EncryptionApp interface {
+	// RewrapKeys 主密钥轮换后用新主密钥重新包装所有数据密钥
+	RewrapKeys(ctx context.Context, operatorUUID string) (*dto.RewrapReportDto, error)
+}
+
+type encryptionApp struct {
+	keyRotationService service.KeyRotationService
+}
+
+func DefaultEncryptionApp() EncryptionApp {
+	assert.NotCircular()
+	onceEncryptionApp.Do(func() {
+		singletonEncryptionApp = &encryptionApp{
+			keyRotationService: service.DefaultKeyRotationService(),
+		}
+	})
+	assert.NotNil(singletonEncryptionApp)
+	return singletonEncryptionApp
+}
+
+// RewrapKeys 重新包装数据密钥
+func (a *encryptionApp) RewrapKeys(ctx context.Context, operatorUUID string) (*dto.RewrapReportDto, error) {
+	if !config.IsAdmin(operatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	report, err := a.keyRotationService.RewrapKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]*dto.RewrapFailureDto, 0, len(report.Failures()))
+	for _, failure := range report.Failures() {
+		failures = append(failures, &dto.RewrapFailureDto{
+			ObjectName: failure.ObjectName(),
+			Error:      failure.ErrorMsg(),
+		})
+	}
+	return &dto.RewrapReportDto{
+		ActiveKeyID: report.ActiveKeyID(),
+		StartedAt:   report.StartedAt(),
+		FinishedAt:  report.FinishedAt(),
+		Rewrapped:   report.Count(vo.RewrapOutcomeRewrapped),
+		Current:     report.Count(vo.RewrapOutcomeCurrent),
+		Plaintext:   report.Count(vo.RewrapOutcomePlaintext),
+		Failures:    failures,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/po"
+)
+
+// memoryVideoRepo 内存中的视频仓储，经由真实的转换器保存PO，查询和更新的条件与DAO相同；
+// 只实现用到的方法，调用其余方法时 panic
+type memoryVideoRepo struct {
+	repo.VideoRepository
+
+	mu        sync.Mutex
+	convertor *convertor.VideoConvertor
+	videos    []*po.VideoPo // 按保存顺序
+}
+
+func newMemoryVideoRepo() *memoryVideoRepo {
+	return &memoryVideoRepo{convertor: convertor.NewVideoConvertor()}
+}
+
+// find 按UUID查找保存的PO
+func (r *memoryVideoRepo) find(videoUUID string) *po.VideoPo {
+	for _, videoPO := range r.videos {
+		if videoPO.UUID == videoUUID {
+			return videoPO
+		}
+	}
+	return nil
+}
+
+func (r *memoryVideoRepo) Save(ctx context.Context, video *entity.Video) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.videos = append(r.videos, r.convertor.EntityToPO(video))
+	return nil
+}
+
+func (r *memoryVideoRepo) FindByUUID(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	videoPO := r.find(videoUUID)
+	if videoPO == nil {
+		return nil, nil
+	}
+	copied := *videoPO
+	return r.convertor.POToEntity(&copied), nil
+}
+
+// FindByChecksum 与 VideoDao.GetByUserUUIDAndChecksum 的条件相同
+func (r *memoryVideoRepo) FindByChecksum(ctx context.Context, userUUID, sha256 string) (*entity.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, videoPO := range r.videos {
+		if videoPO.UserUUID == userUUID &&
+			(videoPO.SourceSHA256 == sha256 || (videoPO.SourceSHA256 == "" && videoPO.ChecksumSHA256 == sha256)) {
+			copied := *videoPO
+			return r.convertor.POToEntity(&copied), nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateSourceContent 与 VideoDao.UpdateSourceContent 更新的列相同
+func (r *memoryVideoRepo) UpdateSourceContent(ctx context.Context, video *entity.Video, previousETag string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	videoPO := r.find(video.UUID())
+	if videoPO == nil || videoPO.StoragePath != video.StoragePath() || videoPO.ETag != previousETag {
+		return false, nil
+	}
+	updated := r.convertor.EntityToPO(video)
+	videoPO.FileSize = updated.FileSize
+	videoPO.ChecksumMD5 = updated.ChecksumMD5
+	videoPO.ChecksumSHA256 = updated.ChecksumSHA256
+	videoPO.ETag = updated.ETag
+	videoPO.StrippedMetadata = updated.StrippedMetadata
+	return true, nil
+}
+
+// memoryStore 内存中的对象存储，上传时计算校验和，只实现用到的方法
+type memoryStore struct {
+	gateway.MinioService
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (s *memoryStore) GenerateObjectName(userUUID, filename string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("videos/%s/%d/%s", userUUID, len(s.objects), filename)
+}
+
+func (s *memoryStore) UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64,
+	expected vo.Checksum) (vo.UploadReceipt, error) {
+	hasher := vo.NewChecksumHasher()
+	data, err := io.ReadAll(io.TeeReader(reader, hasher))
+	if err != nil {
+		return vo.UploadReceipt{}, err
+	}
+	actual := hasher.Sum()
+	if err := expected.Verify(actual); err != nil {
+		return vo.UploadReceipt{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[objectName] = data
+	s.uploads++
+	return vo.NewUploadReceipt(objectName, actual, fmt.Sprintf("etag-%d", s.uploads)), nil
+}
+
+// fixedUserGateway 按用户名返回固定的用户UUID
+type fixedUserGateway map[string]string
+
+func (g fixedUserGateway) FindUUIDByUsername(ctx context.Context, username string) (string, error) {
+	return g[username], nil
+}
+
+// recordingVideoService 只记录状态迁移
+type recordingVideoService struct {
+	service.VideoService
+	transitions []vo.VideoStatus
+}
+
+func (s *recordingVideoService) Transition(ctx context.Context, videoUUID string, to vo.VideoStatus, cause string) (*entity.Video, error) {
+	s.transitions = append(s.transitions, to)
+	return nil, nil
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// 未配置时的 faststart 参数
+const (
+	defaultFaststartTimeout = 30 * time.Minute
+	// faststartLeaseMargin 改写不续租，租约在超时之外多留的时间
+	faststartLeaseMargin = time.Minute
+)
+
+var (
+	onceFaststartApp      sync.Once
+	singletonFaststartApp FaststartApp
+)
+
+// FaststartApp 源文件 faststart 应用服务
+type FaststartApp interface {
+	// CancelDeleted 视频被删除后取消其未完成的改写
+	CancelDeleted(ctx context.Context, videoUUID string) error
+	// ProcessDue 领取到期的记录并发执行，返回执行的数量
+	ProcessDue(ctx context.Context) (int, error)
+}
+
+type faststartApp struct {
+	faststartService service.FaststartService
+}
+
+func DefaultFaststartApp() FaststartApp {
+	assert.NotCircular()
+	onceFaststartApp.Do(func() {
+		singletonFaststartApp = &faststartApp{
+			faststartService: service.DefaultFaststartService(),
+		}
+	})
+	assert.NotNil(singletonFaststartApp)
+	return singletonFaststartApp
+}
+
+// CancelDeleted 取消已删除视频的改写
+func (a *faststartApp) CancelDeleted(ctx context.Context, videoUUID string) error {
+	_, err := a.faststartService.Cancel(ctx, videoUUID, "video deleted")
+	return err
+}
+
+// ProcessDue 每个实例同时改写的数量不超过配置的并发数
+func (a *faststartApp) ProcessDue(ctx context.Context) (int, error) {
+	cfg := faststartConfig()
+	jobs, err := a.faststartService.ClaimDue(ctx, cfg.Concurrency, cfg.Timeout+faststartLeaseMargin)
+	if err != nil {
+		return 0, err
+	}
+	opts := vo.NewFaststartOptions(!cfg.KeepMetadata, cfg.MaxAttempts, cfg.RetryDelay, cfg.Timeout, cfg.WorkDir)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *entity.FaststartJob) {
+			defer wg.Done()
+			if err := a.faststartService.Process(ctx, job, opts); err != nil {
+				logger.Error(fmt.Sprintf("faststart %s of video %s failed: %v", job.UUID(), job.VideoUuid(), err))
+			}
+		}(job)
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// faststartConfig faststart 配置，未配置的参数使用默认值
+func faststartConfig() config.FaststartConfig {
+	var cfg config.FaststartConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Faststart
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultTranscodeConcurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultTranscodeMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultTranscodeRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultFaststartTimeout
+	}
+	return cfg
+}
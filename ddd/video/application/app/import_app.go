@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/ddd/video/infrastructure/remote"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/netguard"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// importProgressInterval 导入过程中持久化传输进度的间隔
+const importProgressInterval = 2 * time.Second
+
+var (
+	onceImportApp      sync.Once
+	singletonImportApp ImportApp
+)
+
+// ImportApp 远程URL导入应用服务
+type ImportApp interface {
+	// ImportVideo 校验URL后创建视频和上传任务，后台下载远程文件并写入存储
+	ImportVideo(ctx context.Context, cmd *cqe.ImportVideoCommand) (*dto.VideoSyncVideoDto, error)
+	// GetUploadTask 查询上传任务的状态和传输进度，只有上传者可以查看
+	GetUploadTask(ctx context.Context, query *cqe.GetUploadTaskQuery) (*dto.UploadTaskDto, error)
+}
+
+type importApp struct {
+	remoteFetcher gateway.RemoteFetcher
+	minioService  gateway.MinioService
+	videoService  service.VideoService
+	videoRepo     repo.VideoRepository
+}
+
+func DefaultImportApp() ImportApp {
+	assert.NotCircular()
+	onceImportApp.Do(func() {
+		singletonImportApp = &importApp{
+			remoteFetcher: remote.DefaultRemoteFetcher(),
+			minioService:  minio.DefaultMinioService(),
+			videoService:  service.DefaultVideoService(),
+			videoRepo:     persistence.NewVideoRepository(),
+		}
+	})
+	assert.NotNil(singletonImportApp)
+	return singletonImportApp
+}
+
+// ImportVideo 提交时只校验URL是否允许访问，下载和上传在后台进行，进度记录在上传任务上
+func (a *importApp) ImportVideo(ctx context.Context, cmd *cqe.ImportVideoCommand) (*dto.VideoSyncVideoDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if err := a.remoteFetcher.CheckURL(cmd.URL); err != nil {
+		return nil, importBizError(err)
+	}
+	filename := cmd.Filename()
+	storagePath := a.minioService.GenerateObjectName(cmd.UserUUID, filename)
+	videoEntity := entity.DefaultVideo(cmd.UserUUID, cmd.Title, cmd.Description, filename, 0, cmd.Format, storagePath, vo.VideoStatusUploading)
+	videoEntity.SetReview(moderationMode().InitialReviewStatus(), "")
+	videoEntity.SetKeepMetadata(cmd.KeepMetadata)
+	videoTaskEntity := entity.DefaultVideoUploadTaskEntity(
+		cmd.UserUUID, videoEntity.UUID(), vo.VideoUploadTaskStatusInit, "", nil, storagePath)
+	videoTaskEntity.SetSourceURL(cmd.URL)
+	videoTaskEntity.SetProgress(0, -1)
+	if err := a.videoRepo.CreateVideo(ctx, videoEntity, videoTaskEntity); err != nil {
+		logger.Error(fmt.Sprintf("ImportVideo CreateVideo user_uuid: %s, error: %v", cmd.UserUUID, err))
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	expected, _ := cmd.ExpectedChecksum()
+
+	go a.importInBackground(context.WithoutCancel(ctx), videoTaskEntity, expected)
+
+	return &dto.VideoSyncVideoDto{
+		VideoUUID: videoEntity.UUID(),
+		TaskUUID:  videoTaskEntity.UUID(),
+	}, nil
+}
+
+// importInBackground 下载远程文件并写入存储，根据结果迁移视频和上传任务状态
+func (a *importApp) importInBackground(ctx context.Context, task *entity.VideoUploadTaskEntity, expected vo.Checksum) {
+	if err := a.videoService.StartUpload(ctx, task.UUID()); err != nil {
+		logger.Error(fmt.Sprintf("ImportVideo StartUpload task_uuid: %s, error: %v", task.UUID(), err))
+		return
+	}
+	receipt, importErr := a.fetchAndStore(ctx, task, expected)
+	if importErr == nil {
+		if err := a.videoRepo.UpdateFileSize(ctx, task.VideoUuid(), task.BytesReceived()); err != nil {
+			logger.Error(fmt.Sprintf("ImportVideo UpdateFileSize video_uuid: %s, error: %v", task.VideoUuid(), err))
+		}
+	} else {
+		logger.Error(fmt.Sprintf("ImportVideo task_uuid: %s, url: %s, error: %v", task.UUID(), task.SourceURL(), importErr))
+	}
+	if err := a.videoService.CompleteUpload(ctx, task.VideoUuid(), task.UUID(), receipt, importErr); err != nil {
+		logger.Error(fmt.Sprintf("ImportVideo CompleteUpload video_uuid: %s, task_uuid: %s, error: %v", task.VideoUuid(), task.UUID(), err))
+	}
+}
+
+// fetchAndStore 把远程响应体直接流式写入存储，不落本地磁盘；传输期间定时持久化进度
+func (a *importApp) fetchAndStore(ctx context.Context, task *entity.VideoUploadTaskEntity, expected vo.Checksum) (vo.UploadReceipt, error) {
+	file, body, err := a.remoteFetcher.Open(ctx, task.SourceURL())
+	if err != nil {
+		return vo.UploadReceipt{}, err
+	}
+	defer body.Close()
+	if !file.IsVideo() {
+		return vo.UploadReceipt{}, fmt.Errorf("remote file is not a video: %s", file.ContentType())
+	}
+
+	counter := &countingReader{reader: body}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(importProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				a.saveProgress(ctx, task, counter.Count(), file.Size())
+			}
+		}
+	}()
+
+	receipt, err := a.minioService.UploadStream(ctx, task.ObjectName(), counter, file.Size(), expected)
+	close(done)
+	wg.Wait()
+	a.saveProgress(ctx, task, counter.Count(), file.Size())
+	return receipt, err
+}
+
+// saveProgress 持久化传输进度，失败只记录日志
+func (a *importApp) saveProgress(ctx context.Context, task *entity.VideoUploadTaskEntity, received, total int64) {
+	task.SetProgress(received, total)
+	if err := a.videoRepo.UpdateTaskProgress(ctx, task); err != nil {
+		logger.Error(fmt.Sprintf("ImportVideo UpdateTaskProgress task_uuid: %s, error: %v", task.UUID(), err))
+	}
+}
+
+// GetUploadTask 查询上传任务进度
+func (a *importApp) GetUploadTask(ctx context.Context, query *cqe.GetUploadTaskQuery) (*dto.UploadTaskDto, error) {
+	task, err := a.videoRepo.FindUploadTask(ctx, query.TaskUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if task == nil || task.UserUuid() != query.UserUUID {
+		return nil, errno.NewSimpleBizError(errno.ErrUploadTaskNotFound, nil)
+	}
+	return &dto.UploadTaskDto{
+		TaskUUID:      task.UUID(),
+		VideoUUID:     task.VideoUuid(),
+		Status:        task.Status().Value(),
+		SourceURL:     task.SourceURL(),
+		BytesReceived: task.BytesReceived(),
+		BytesTotal:    task.BytesTotal(),
+		ErrorMsg:      task.ErrorMsg(),
+		CompletedAt:   task.CompletedAt(),
+	}, nil
+}
+
+// importBizError URL被访问策略拒绝时返回 ErrImportURLNotAllowed
+func importBizError(err error) error {
+	var denied *netguard.DeniedError
+	if errors.As(err, &denied) {
+		return errno.NewSimpleBizError(errno.ErrImportURLNotAllowed, err, denied.Reason)
+	}
+	return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "url")
+}
+
+// countingReader 统计已读取的字节数，可在其他协程中读取计数
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// Count 已读取的字节数
+func (r *countingReader) Count() int64 {
+	return r.count.Load()
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// defaultShareTokenTTL 未配置时分享令牌的有效期
+const defaultShareTokenTTL = 7 * 24 * time.Hour
+
+var (
+	onceKeyApp      sync.Once
+	singletonKeyApp KeyApp
+)
+
+// KeyApp HLS内容密钥应用服务
+type KeyApp interface {
+	// ContentKey 向登录且可以观看视频的用户、审核员或分享令牌的持有者发放内容密钥，每次请求都记录审计
+	ContentKey(ctx context.Context, query *cqe.ContentKeyQuery) ([]byte, error)
+	// IssueShareToken 视频所有者签发分享令牌
+	IssueShareToken(ctx context.Context, cmd *cqe.ShareTokenCommand) (*dto.ShareTokenDto, error)
+}
+
+type keyApp struct {
+	contentKeyService service.ContentKeyService
+	videoRepo         repo.VideoRepository
+	contentKeyRepo    repo.ContentKeyRepository
+}
+
+func DefaultKeyApp() KeyApp {
+	assert.NotCircular()
+	onceKeyApp.Do(func() {
+		singletonKeyApp = &keyApp{
+			contentKeyService: service.DefaultContentKeyService(),
+			videoRepo:         persistence.NewVideoRepository(),
+			contentKeyRepo:    persistence.NewContentKeyRepository(),
+		}
+	})
+	assert.NotNil(singletonKeyApp)
+	return singletonKeyApp
+}
+
+// ContentKey 公开视频同样要求登录或分享令牌；审计记录写入失败时不发放密钥
+func (a *keyApp) ContentKey(ctx context.Context, query *cqe.ContentKeyQuery) ([]byte, error) {
+	if query.UserUUID == "" && query.ShareToken == "" {
+		return nil, a.deny(ctx, query, "", "unauthenticated", errno.NewSimpleBizError(errno.ErrUnauthorized, nil))
+	}
+	key, err := a.contentKeyRepo.FindByUUID(ctx, query.KeyUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if key == nil || key.VideoUuid() != query.VideoUUID {
+		return nil, a.deny(ctx, query, "", "key not found", errno.NewSimpleBizError(errno.ErrContentKeyNotFound, nil))
+	}
+	video, err := a.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || !video.Status().IsPlayable() {
+		return nil, a.deny(ctx, query, "", "video not playable", errno.NewSimpleBizError(errno.ErrVideoNotFound, nil))
+	}
+
+	reason, issuerUUID := "", ""
+	switch {
+	case query.UserUUID != "" && video.IsOwnedBy(query.UserUUID):
+		reason = "owner"
+	case query.UserUUID != "" && video.IsVisibleTo(query.UserUUID):
+		reason = "viewer"
+	case query.UserUUID != "" && config.IsAdmin(query.UserUUID):
+		reason = "admin"
+	case query.UserUUID != "" && config.IsModerator(query.UserUUID):
+		// 与播放列表的可见性一致，审核员需要观看待审核的加密视频
+		reason = "moderator"
+	default:
+		if issuerUUID = verifyShareToken(video, query.ShareToken); issuerUUID != "" {
+			reason = "share token"
+		}
+	}
+	if reason == "" {
+		if query.ShareToken != "" {
+			return nil, a.deny(ctx, query, "", "invalid share token", errno.NewSimpleBizError(errno.ErrShareTokenInvalid, nil))
+		}
+		return nil, a.deny(ctx, query, "", "not visible", errno.NewSimpleBizError(errno.ErrForbidden, nil))
+	}
+
+	plain, err := a.contentKeyService.Open(key)
+	if err != nil {
+		return nil, a.deny(ctx, query, issuerUUID, "unwrap failed", errno.NewSimpleBizError(errno.ErrInternalServer, err))
+	}
+	access := entity.DefaultKeyAccess(query.VideoUUID, query.KeyUUID, query.UserUUID, issuerUUID,
+		query.ClientIP, query.UserAgent, true, reason)
+	if err := a.contentKeyRepo.SaveAccess(ctx, access); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return plain, nil
+}
+
+// deny 记录拒绝发放的审计后返回原错误，审计写入失败只记录日志
+func (a *keyApp) deny(ctx context.Context, query *cqe.ContentKeyQuery, issuerUUID, reason string, cause error) error {
+	access := entity.DefaultKeyAccess(query.VideoUUID, query.KeyUUID, query.UserUUID, issuerUUID,
+		query.ClientIP, query.UserAgent, false, reason)
+	if err := a.contentKeyRepo.SaveAccess(ctx, access); err != nil {
+		logger.Error(fmt.Sprintf("save key access of video %s key %s failed: %v", query.VideoUUID, query.KeyUUID, err))
+	}
+	return cause
+}
+
+// IssueShareToken 令牌不落库，有效期内无法单独吊销；更换签名密钥会使全部令牌失效
+func (a *keyApp) IssueShareToken(ctx context.Context, cmd *cqe.ShareTokenCommand) (*dto.ShareTokenDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	video, err := a.videoRepo.FindByUUID(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(cmd.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	secret, _ := playbackConfig()
+	if secret == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, errNoSigningSecret)
+	}
+	token := vo.NewShareToken(secret, video.UUID(), cmd.UserUUID, time.Now().Add(shareTokenTTL()))
+	return &dto.ShareTokenDto{
+		Token:     token.String(),
+		ExpiresAt: token.ExpiresAt(),
+		HLSURL:    withShareToken(fmt.Sprintf("/api/v1/videos/%s/hls/master.m3u8", video.UUID()), token.String()),
+	}, nil
+}
+
+// shareTokenTTL 分享令牌的有效期
+func shareTokenTTL() time.Duration {
+	if global := config.GetGlobalConfig(); global != nil && global.Playback.ShareTokenTTL > 0 {
+		return global.Playback.ShareTokenTTL
+	}
+	return defaultShareTokenTTL
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+)
+
+// memoryContentKeyRepo 只实现查找密钥和保存审计记录
+type memoryContentKeyRepo struct {
+	repo.ContentKeyRepository
+	keys     map[string]*entity.ContentKey
+	accesses []*entity.KeyAccess
+}
+
+func (r *memoryContentKeyRepo) FindByUUID(ctx context.Context, keyUUID string) (*entity.ContentKey, error) {
+	return r.keys[keyUUID], nil
+}
+
+func (r *memoryContentKeyRepo) SaveAccess(ctx context.Context, access *entity.KeyAccess) error {
+	r.accesses = append(r.accesses, access)
+	return nil
+}
+
+// plainContentKeyService 直接返回包装的密钥作为明文
+type plainContentKeyService struct {
+	service.ContentKeyService
+}
+
+func (s plainContentKeyService) Open(key *entity.ContentKey) ([]byte, error) {
+	return key.WrappedKey(), nil
+}
+
+// useConfig 测试期间替换全局配置
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	previous := config.GetGlobalConfig()
+	config.SetGlobalConfig(cfg)
+	t.Cleanup(func() { config.SetGlobalConfig(previous) })
+}
+
+func TestContentKeyAccessMatchesPlaylistVisibility(t *testing.T) {
+	useConfig(t, &config.Config{
+		Admin:      config.AdminConfig{Users: []string{"admin-1"}},
+		Moderation: config.ModerationConfig{Moderators: []string{"moderator-1"}},
+	})
+	// 待审核的视频只有所有者、审核员和管理员可以观看
+	video := entity.NewVideo("video-1", "owner-1", "title", "", "a.mp4", 1, "mp4", vo.VideoStatusReady)
+	video.SetReview(vo.ReviewStatusPending, "")
+	videoRepo := newMemoryVideoRepo()
+	if err := videoRepo.Save(context.Background(), video); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	key := entity.NewContentKey("key-1", video.UUID(), 0, "master-1", []byte("0123456789abcdef"), nil)
+
+	tests := []struct {
+		name       string
+		userUUID   string
+		wantReason string
+		wantErr    *errno.Errno
+	}{
+		{name: "owner", userUUID: "owner-1", wantReason: "owner"},
+		{name: "admin", userUUID: "admin-1", wantReason: "admin"},
+		{name: "moderator", userUUID: "moderator-1", wantReason: "moderator"},
+		{name: "other user", userUUID: "user-2", wantReason: "not visible", wantErr: errno.ErrForbidden},
+		{name: "anonymous", wantReason: "unauthenticated", wantErr: errno.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRepo := &memoryContentKeyRepo{keys: map[string]*entity.ContentKey{key.UUID(): key}}
+			app := &keyApp{contentKeyService: plainContentKeyService{}, videoRepo: videoRepo, contentKeyRepo: keyRepo}
+			plain, err := app.ContentKey(context.Background(), &cqe.ContentKeyQuery{
+				VideoUUID: video.UUID(),
+				KeyUUID:   key.UUID(),
+				UserUUID:  tt.userUUID,
+			})
+			if tt.wantErr != nil {
+				if err == nil || errno.AssertBizError(err).Code() != tt.wantErr.Code {
+					t.Fatalf("ContentKey error = %v, want %s", err, tt.wantErr.Message)
+				}
+			} else if err != nil || string(plain) != "0123456789abcdef" {
+				t.Fatalf("ContentKey = %q, %v", plain, err)
+			}
+			if len(keyRepo.accesses) != 1 {
+				t.Fatalf("saved %d key accesses, want 1", len(keyRepo.accesses))
+			}
+			access := keyRepo.accesses[0]
+			if access.Granted() != (tt.wantErr == nil) || access.Reason() != tt.wantReason {
+				t.Fatalf("key access granted = %v, reason = %q, want reason %q", access.Granted(), access.Reason(), tt.wantReason)
+			}
+
+			// 能拿到播放列表的用户必须也能拿到密钥，反之亦然
+			_, playlistErr := playableVideo(context.Background(), videoRepo, video.UUID(), tt.userUUID, "")
+			if (playlistErr == nil) != (err == nil) {
+				t.Fatalf("playlist error = %v but key error = %v", playlistErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"io"
+	"os"
+)
+
+// ImportLibraryFile 先计算本地文件的校验和用于去重，再上传并按与 Create 相同的流程保存视频；
+// 上传时校验同一校验和，避免两次读取之间文件被修改
+func (v *videoApp) ImportLibraryFile(ctx context.Context, cmd *cqe.ImportLibraryFileCommand) (*dto.LibraryImportDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	ownerUUID, err := v.userGateway.FindUUIDByUsername(ctx, cmd.OwnerUsername)
+	if err != nil {
+		return nil, err
+	}
+	if ownerUUID == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrImportOwnerNotFound, nil, cmd.OwnerUsername)
+	}
+
+	checksum, fileSize, err := localFileChecksum(cmd.Path)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "path")
+	}
+	existing, err := v.videoRepo.FindByChecksum(ctx, ownerUUID, checksum.SHA256())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if existing != nil {
+		return &dto.LibraryImportDto{
+			VideoUUID: existing.UUID(),
+			Duplicate: true,
+			SHA256:    checksum.SHA256(),
+			FileSize:  fileSize,
+		}, nil
+	}
+
+	file, err := os.Open(cmd.Path)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "path")
+	}
+	defer file.Close()
+	storagePath := v.minioService.GenerateObjectName(ownerUUID, cmd.Filename())
+	receipt, err := v.minioService.UploadStream(ctx, storagePath, file, fileSize, checksum)
+	if err != nil {
+		return nil, uploadBizError(err)
+	}
+	videoEntity := entity.DefaultVideo(
+		ownerUUID, cmd.Title, cmd.Description, cmd.Filename(), fileSize, cmd.Format(), receipt.ObjectName(),
+		vo.VideoStatusUploading,
+	)
+	videoEntity.SetTags(cmd.Tags)
+	if cmd.CreatedAt != nil {
+		videoEntity.SetCreatedAt(cmd.CreatedAt)
+	}
+	if err := v.saveUploadedVideo(ctx, videoEntity, receipt); err != nil {
+		logger.Error(fmt.Sprintf("ImportLibraryFile path: %s, object: %s, error: %v", cmd.Path, receipt.ObjectName(), err))
+		return nil, err
+	}
+	return &dto.LibraryImportDto{
+		VideoUUID: videoEntity.UUID(),
+		SHA256:    checksum.SHA256(),
+		FileSize:  fileSize,
+	}, nil
+}
+
+// localFileChecksum 计算本地文件的校验和和大小
+func localFileChecksum(path string) (vo.Checksum, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return vo.Checksum{}, 0, err
+	}
+	defer file.Close()
+	hasher := vo.NewChecksumHasher()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return vo.Checksum{}, 0, err
+	}
+	return hasher.Sum(), size, nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/domain/vo"
+)
+
+func TestImportLibraryFileDedupAfterSourceRewrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "holiday.mp4")
+	original := []byte("ftyp....mdat....moov....")
+	if err := os.WriteFile(path, original, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	store := newMemoryStore()
+	videoRepo := newMemoryVideoRepo()
+	app := &videoApp{
+		minioService: store,
+		videoRepo:    videoRepo,
+		videoService: &recordingVideoService{},
+		userGateway:  fixedUserGateway{"alice": "user-1"},
+	}
+	cmd := &cqe.ImportLibraryFileCommand{Path: path, OwnerUsername: "alice", Title: "Holiday"}
+
+	imported, err := app.ImportLibraryFile(ctx, cmd)
+	if err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if imported.Duplicate {
+		t.Fatalf("first import reported as duplicate")
+	}
+
+	// 与 faststart 覆盖源文件后保存的方式相同：moov 移到前面，校验和与ETag都变了
+	video, err := videoRepo.FindByUUID(ctx, imported.VideoUUID)
+	if err != nil || video == nil {
+		t.Fatalf("FindByUUID = %v, %v", video, err)
+	}
+	hasher := vo.NewChecksumHasher()
+	hasher.Write([]byte("ftyp....moov....mdat...."))
+	rewritten := hasher.Sum()
+	previousETag := video.RewriteSource(int64(len(original)), rewritten, "etag-rewritten", nil)
+	if ok, err := videoRepo.UpdateSourceContent(ctx, video, previousETag); err != nil || !ok {
+		t.Fatalf("UpdateSourceContent = %v, %v", ok, err)
+	}
+
+	stored, _ := videoRepo.FindByUUID(ctx, imported.VideoUUID)
+	if stored.Checksum().SHA256() != rewritten.SHA256() || stored.ETag() != "etag-rewritten" {
+		t.Fatalf("stored checksum = %s, etag = %s, want the rewritten object's", stored.Checksum().SHA256(), stored.ETag())
+	}
+	if stored.SourceSHA256() != imported.SHA256 {
+		t.Fatalf("source sha256 = %s, want the uploaded %s", stored.SourceSHA256(), imported.SHA256)
+	}
+
+	again, err := app.ImportLibraryFile(ctx, cmd)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if !again.Duplicate || again.VideoUUID != imported.VideoUUID {
+		t.Fatalf("second import = %+v, want duplicate of %s", again, imported.VideoUUID)
+	}
+	if len(store.objects) != 1 {
+		t.Fatalf("stored %d objects, want the duplicate not uploaded", len(store.objects))
+	}
+
+	// 其他用户导入同一文件不去重
+	app.userGateway = fixedUserGateway{"alice": "user-2"}
+	other, err := app.ImportLibraryFile(ctx, cmd)
+	if err != nil || other.Duplicate {
+		t.Fatalf("import by another user = %+v, %v", other, err)
+	}
+}
@@ -0,0 +1,341 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/hls"
+	"go-video/pkg/logger"
+	"io"
+	"path"
+	"sync"
+	"time"
+)
+
+// 未配置时的直播参数
+const (
+	defaultLiveWindowSize       = 6
+	defaultLiveMaxSegmentSize   = 32 << 20
+	defaultLiveMaxPlaylistSize  = 64 << 10
+	defaultLiveIdleTimeout      = time.Minute
+	defaultLiveSegmentURLExpiry = 5 * time.Minute
+	defaultLiveTimeout          = 30 * time.Minute
+	// liveConvertConcurrency 每个实例同时转换的直播数
+	liveConvertConcurrency = 2
+	// liveEndIdleBatchSize 每轮自动结束的空闲直播数
+	liveEndIdleBatchSize = 50
+	// liveLeaseMargin 转换不续租，租约在超时之外多留的时间
+	liveLeaseMargin = time.Minute
+)
+
+var (
+	onceLiveApp      sync.Once
+	singletonLiveApp LiveApp
+)
+
+// LiveApp 直播应用服务
+type LiveApp interface {
+	// CreateLiveStream 创建直播，返回推流密钥和推流地址，推流密钥只在此时返回
+	CreateLiveStream(ctx context.Context, cmd *cqe.CreateLiveStreamCommand) (*dto.LiveStreamDto, error)
+	// ListLiveStreams 获取当前用户创建的全部直播
+	ListLiveStreams(ctx context.Context, userUUID string) ([]*dto.LiveStreamDto, error)
+	// GetLiveStream 获取直播的状态和转换进度，只有主播可以查看
+	GetLiveStream(ctx context.Context, cmd *cqe.LiveStreamCommand) (*dto.LiveStreamDto, error)
+	// EndLiveStream 主播结束直播，之后不再接受推流，已登记的分片转换为视频
+	EndLiveStream(ctx context.Context, cmd *cqe.LiveStreamCommand) (*dto.LiveStreamDto, error)
+	// Ingest 接收编码器推送的分片或媒体播放列表
+	Ingest(ctx context.Context, cmd *cqe.LiveIngestCommand) (*dto.LiveIngestDto, error)
+	// LivePlaylist 观众的滑动窗口播放列表，分片地址为短期有效的预签名地址或服务端解密地址
+	LivePlaylist(ctx context.Context, query *cqe.LivePlaylistQuery) (*dto.PlaylistDto, error)
+	// OpenSegment 读取加密存储的直播分片的明文
+	OpenSegment(ctx context.Context, query *cqe.LiveSegmentQuery) (*dto.VideoStreamDto, error)
+	// ProcessDue 结束空闲的直播并转换已结束的直播，返回转换的数量
+	ProcessDue(ctx context.Context) (int, error)
+}
+
+type liveApp struct {
+	minioService gateway.MinioService
+	liveService  service.LiveService
+}
+
+func DefaultLiveApp() LiveApp {
+	assert.NotCircular()
+	onceLiveApp.Do(func() {
+		singletonLiveApp = &liveApp{
+			minioService: minio.DefaultMinioService(),
+			liveService:  service.DefaultLiveService(),
+		}
+	})
+	assert.NotNil(singletonLiveApp)
+	return singletonLiveApp
+}
+
+// CreateLiveStream 未启用直播时拒绝创建
+func (a *liveApp) CreateLiveStream(ctx context.Context, cmd *cqe.CreateLiveStreamCommand) (*dto.LiveStreamDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if !liveConfig().Enabled {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveDisabled, nil)
+	}
+	stream, streamKey, err := a.liveService.Create(ctx, cmd.UserUUID, cmd.Title, cmd.Description)
+	if err != nil {
+		return nil, err
+	}
+	liveStreamDto := toLiveStreamDto(stream)
+	liveStreamDto.StreamKey = streamKey
+	liveStreamDto.IngestURL = fmt.Sprintf("/api/v1/live/ingest/%s", streamKey)
+	return liveStreamDto, nil
+}
+
+// ListLiveStreams 新建的在前
+func (a *liveApp) ListLiveStreams(ctx context.Context, userUUID string) ([]*dto.LiveStreamDto, error) {
+	if userUUID == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	streams, err := a.liveService.List(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	liveStreamDtos := make([]*dto.LiveStreamDto, 0, len(streams))
+	for _, stream := range streams {
+		liveStreamDtos = append(liveStreamDtos, toLiveStreamDto(stream))
+	}
+	return liveStreamDtos, nil
+}
+
+// GetLiveStream 其他用户的直播视为不存在
+func (a *liveApp) GetLiveStream(ctx context.Context, cmd *cqe.LiveStreamCommand) (*dto.LiveStreamDto, error) {
+	stream, err := a.ownedStream(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return toLiveStreamDto(stream), nil
+}
+
+// EndLiveStream 已结束时直接返回当前状态
+func (a *liveApp) EndLiveStream(ctx context.Context, cmd *cqe.LiveStreamCommand) (*dto.LiveStreamDto, error) {
+	stream, err := a.ownedStream(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.liveService.End(ctx, stream); err != nil {
+		return nil, err
+	}
+	return toLiveStreamDto(stream), nil
+}
+
+// Ingest 按文件名区分分片和播放列表，超过大小上限的内容拒绝接收
+func (a *liveApp) Ingest(ctx context.Context, cmd *cqe.LiveIngestCommand) (*dto.LiveIngestDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := liveConfig()
+	if !cfg.Enabled {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveDisabled, nil)
+	}
+	stream, err := a.liveService.Authenticate(ctx, cmd.StreamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case vo.IsLiveSegmentFilename(cmd.Filename):
+		content, err := readLimited(cmd.Body, cfg.MaxSegmentSize, "segment")
+		if err != nil {
+			return nil, err
+		}
+		if err := a.liveService.IngestSegment(ctx, stream, cmd.Filename, content); err != nil {
+			return nil, err
+		}
+		return &dto.LiveIngestDto{Status: stream.Status().Value()}, nil
+	case vo.IsLivePlaylistFilename(cmd.Filename):
+		content, err := readLimited(cmd.Body, cfg.MaxPlaylistSize, "playlist")
+		if err != nil {
+			return nil, err
+		}
+		registered, err := a.liveService.IngestPlaylist(ctx, stream, content)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LiveIngestDto{Registered: registered, Status: stream.Status().Value()}, nil
+	default:
+		return nil, errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, nil,
+			fmt.Sprintf("unsupported file %q, expected a .ts segment or .m3u8 playlist", cmd.Filename))
+	}
+}
+
+// LivePlaylist 直播中时播放器按 TARGETDURATION 定期刷新；结束后带 EXT-X-ENDLIST，转换完成后分片已删除，改为观看视频
+func (a *liveApp) LivePlaylist(ctx context.Context, query *cqe.LivePlaylistQuery) (*dto.PlaylistDto, error) {
+	stream, err := a.watchableStream(ctx, query.StreamUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := liveConfig()
+	segments, err := a.liveService.Window(ctx, stream, cfg.WindowSize)
+	if err != nil {
+		return nil, err
+	}
+	playlist := &hls.LivePlaylist{Ended: stream.Status() == vo.LiveStreamStatusEnded}
+	for i, segment := range segments {
+		if i == 0 {
+			playlist.MediaSequence = segment.Sequence()
+		}
+		uri, err := mediaObjectURL(ctx, a.minioService, segment.Path(), cfg.SegmentURLExpiry,
+			fmt.Sprintf("/api/v1/live/%s/segments/%s", stream.UUID(), path.Base(segment.Path())))
+		if err != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+		}
+		playlist.Segments = append(playlist.Segments, hls.Segment{URI: uri, Duration: segment.Duration(), Size: segment.Size()})
+	}
+	return &dto.PlaylistDto{Content: playlist.Encode(), ContentType: hls.ContentType}, nil
+}
+
+// OpenSegment 与播放列表的可观看条件相同，转换完成后分片已删除
+func (a *liveApp) OpenSegment(ctx context.Context, query *cqe.LiveSegmentQuery) (*dto.VideoStreamDto, error) {
+	stream, err := a.watchableStream(ctx, query.StreamUUID)
+	if err != nil {
+		return nil, err
+	}
+	objectName, ok := vo.RegisteredSegmentObjectName(stream.UUID(), query.Segment)
+	if !ok {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveSegmentNotFound, nil)
+	}
+	return openMediaObject(ctx, a.minioService, objectName, errno.ErrLiveSegmentNotFound)
+}
+
+// watchableStream 直播中或已结束但还在转换的直播可以观看
+func (a *liveApp) watchableStream(ctx context.Context, streamUUID string) (*entity.LiveStream, error) {
+	if streamUUID == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	stream, err := a.liveService.Get(ctx, streamUUID)
+	if err != nil {
+		return nil, err
+	}
+	if stream.Status() == vo.LiveStreamStatusIdle {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveStreamNotLive, nil)
+	}
+	if stream.Status() == vo.LiveStreamStatusEnded && stream.VodStatus() != vo.RenditionStatusPending &&
+		stream.VodStatus() != vo.RenditionStatusRunning {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveStreamEnded, nil)
+	}
+	return stream, nil
+}
+
+// ProcessDue 编码器断开后不会推送 EXT-X-ENDLIST，先结束超过空闲时间的直播，再领取已结束的直播并发转换
+func (a *liveApp) ProcessDue(ctx context.Context) (int, error) {
+	cfg := liveConfig()
+	if ended, err := a.liveService.EndIdle(ctx, cfg.IdleTimeout, liveEndIdleBatchSize); err != nil {
+		logger.Error(fmt.Sprintf("live end idle streams failed: %v", err))
+	} else if ended > 0 {
+		logger.Info(fmt.Sprintf("live ended %d idle streams", ended))
+	}
+
+	streams, err := a.liveService.ClaimConvertible(ctx, liveConvertConcurrency, cfg.Timeout+liveLeaseMargin)
+	if err != nil {
+		return 0, err
+	}
+	opts := vo.NewLiveOptions(cfg.MaxAttempts, cfg.RetryDelay, cfg.Timeout)
+	initialReview := moderationMode().InitialReviewStatus()
+	var wg sync.WaitGroup
+	for _, stream := range streams {
+		wg.Add(1)
+		go func(stream *entity.LiveStream) {
+			defer wg.Done()
+			if err := a.liveService.Convert(ctx, stream, opts, initialReview); err != nil {
+				logger.Error(fmt.Sprintf("live %s conversion failed: %v", stream.UUID(), err))
+			}
+		}(stream)
+	}
+	wg.Wait()
+	return len(streams), nil
+}
+
+// ownedStream 获取主播自己的直播
+func (a *liveApp) ownedStream(ctx context.Context, cmd *cqe.LiveStreamCommand) (*entity.LiveStream, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	stream, err := a.liveService.Get(ctx, cmd.StreamUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !stream.IsOwnedBy(cmd.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveStreamNotFound, nil)
+	}
+	return stream, nil
+}
+
+// readLimited 读取推送的内容，超过limit字节时拒绝
+func readLimited(body io.Reader, limit int64, kind string) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, err, fmt.Sprintf("read %s: %v", kind, err))
+	}
+	if int64(len(content)) > limit {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, nil, fmt.Sprintf("%s exceeds %d bytes", kind, limit))
+	}
+	return content, nil
+}
+
+// toLiveStreamDto 直播实体转DTO，不包含推流密钥
+func toLiveStreamDto(stream *entity.LiveStream) *dto.LiveStreamDto {
+	return &dto.LiveStreamDto{
+		StreamUUID:   stream.UUID(),
+		Title:        stream.Title(),
+		Description:  stream.Description(),
+		Status:       stream.Status().Value(),
+		PlaylistURL:  fmt.Sprintf("/api/v1/live/%s/index.m3u8", stream.UUID()),
+		VideoUUID:    stream.VideoUuid(),
+		VodStatus:    stream.VodStatus().Value(),
+		VodErrorMsg:  stream.VodErrorMsg(),
+		StartedAt:    stream.StartedAt(),
+		LastIngestAt: stream.LastIngestAt(),
+		EndedAt:      stream.EndedAt(),
+		CreatedAt:    stream.CreatedAt(),
+	}
+}
+
+// liveConfig 直播配置，未配置的参数使用默认值
+func liveConfig() config.LiveConfig {
+	var cfg config.LiveConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Live
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = defaultLiveWindowSize
+	}
+	if cfg.MaxSegmentSize <= 0 {
+		cfg.MaxSegmentSize = defaultLiveMaxSegmentSize
+	}
+	if cfg.MaxPlaylistSize <= 0 {
+		cfg.MaxPlaylistSize = defaultLiveMaxPlaylistSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultLiveIdleTimeout
+	}
+	if cfg.SegmentURLExpiry <= 0 {
+		cfg.SegmentURLExpiry = defaultLiveSegmentURLExpiry
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultTranscodeMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultTranscodeRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultLiveTimeout
+	}
+	return cfg
+}
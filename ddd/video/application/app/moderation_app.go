@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// defaultReviewClaimTTL 未配置时审核认领的锁定时长
+const defaultReviewClaimTTL = 15 * time.Minute
+
+var (
+	onceModerationApp      sync.Once
+	singletonModerationApp ModerationApp
+)
+
+// ModerationApp 内容审核应用服务
+type ModerationApp interface {
+	ListQueue(ctx context.Context, moderatorUUID string, query *cqe.ListVideosQuery) (*dto.ReviewQueueDto, error)
+	Claim(ctx context.Context, cmd *cqe.ClaimReviewCommand) error
+	Decide(ctx context.Context, cmd *cqe.ReviewDecisionCommand) error
+	ListLogs(ctx context.Context, moderatorUUID, videoUUID string) ([]*dto.ReviewLogDto, error)
+}
+
+type moderationApp struct {
+	minioService gateway.MinioService
+	videoRepo    repo.VideoRepository
+	reviewRepo   repo.ReviewRepository
+}
+
+func DefaultModerationApp() ModerationApp {
+	assert.NotCircular()
+	onceModerationApp.Do(func() {
+		singletonModerationApp = &moderationApp{
+			minioService: minio.DefaultMinioService(),
+			videoRepo:    persistence.NewVideoRepository(),
+			reviewRepo:   persistence.NewReviewRepository(),
+		}
+	})
+	assert.NotNil(singletonModerationApp)
+	return singletonModerationApp
+}
+
+// ListQueue 获取审核队列
+func (a *moderationApp) ListQueue(ctx context.Context, moderatorUUID string, query *cqe.ListVideosQuery) (*dto.ReviewQueueDto, error) {
+	if !config.IsModerator(moderatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	page := vo.NewPage(query.Page, query.PageSize)
+	items, total, err := a.reviewRepo.FindQueue(ctx, page)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+
+	now, ttl := time.Now(), reviewClaimTTL()
+	dtos := make([]*dto.ReviewQueueItemDto, 0, len(items))
+	for _, item := range items {
+		itemDto := &dto.ReviewQueueItemDto{
+			Video: toVideoSummaryDto(item.Video(), false),
+		}
+		// 过期的认领视为未认领
+		if item.IsClaimed(now, ttl) {
+			itemDto.ClaimedBy = item.ClaimedBy()
+			itemDto.ClaimedAt = item.ClaimedAt()
+		}
+		itemDto.URL = playbackURL(ctx, a.minioService, item.Video())
+		dtos = append(dtos, itemDto)
+	}
+	return &dto.ReviewQueueDto{
+		Items:    dtos,
+		Total:    total,
+		Page:     page.Page(),
+		PageSize: page.PageSize(),
+	}, nil
+}
+
+// Claim 认领审核任务，认领期间其他审核员无法处理该视频
+func (a *moderationApp) Claim(ctx context.Context, cmd *cqe.ClaimReviewCommand) error {
+	if !config.IsModerator(cmd.ModeratorUUID) {
+		return errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	if _, err := a.findPendingVideo(ctx, cmd.VideoUUID); err != nil {
+		return err
+	}
+	claimed, err := a.reviewRepo.Claim(ctx, cmd.VideoUUID, cmd.ModeratorUUID, reviewClaimTTL())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !claimed {
+		return errno.NewSimpleBizError(errno.ErrReviewClaimed, nil)
+	}
+	return nil
+}
+
+// Decide 提交审核结论，需先认领
+func (a *moderationApp) Decide(ctx context.Context, cmd *cqe.ReviewDecisionCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if !config.IsModerator(cmd.ModeratorUUID) {
+		return errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	if _, err := a.findPendingVideo(ctx, cmd.VideoUUID); err != nil {
+		return err
+	}
+	reviewLog := entity.DefaultReviewLog(cmd.VideoUUID, cmd.ModeratorUUID, cmd.Decision, cmd.Reason)
+	decided, err := a.reviewRepo.Decide(ctx, reviewLog, reviewClaimTTL())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !decided {
+		return errno.NewSimpleBizError(errno.ErrReviewNotClaimed, nil)
+	}
+	logger.Info(fmt.Sprintf("video %s reviewed by %s: %s", cmd.VideoUUID, cmd.ModeratorUUID, cmd.Decision.Value()))
+	return nil
+}
+
+// ListLogs 获取视频的审核记录
+func (a *moderationApp) ListLogs(ctx context.Context, moderatorUUID, videoUUID string) ([]*dto.ReviewLogDto, error) {
+	if !config.IsModerator(moderatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	logs, err := a.reviewRepo.FindLogsByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	dtos := make([]*dto.ReviewLogDto, 0, len(logs))
+	for _, reviewLog := range logs {
+		dtos = append(dtos, &dto.ReviewLogDto{
+			LogUUID:       reviewLog.UUID(),
+			VideoUUID:     reviewLog.VideoUuid(),
+			ModeratorUUID: reviewLog.ModeratorUuid(),
+			Decision:      reviewLog.Decision().Value(),
+			Reason:        reviewLog.Reason(),
+			CreatedAt:     reviewLog.CreatedAt(),
+		})
+	}
+	return dtos, nil
+}
+
+func (a *moderationApp) findPendingVideo(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	video, err := a.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if video.ReviewStatus() != vo.ReviewStatusPending || !video.Status().IsPlayable() {
+		return nil, errno.NewSimpleBizError(errno.ErrReviewNotClaimed, nil)
+	}
+	return video, nil
+}
+
+// moderationMode 当前配置的审核模式
+func moderationMode() vo.ModerationMode {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return vo.ModerationModeOff
+	}
+	return vo.NewModerationMode(cfg.Moderation.Mode)
+}
+
+// reviewClaimTTL 审核认领的锁定时长
+func reviewClaimTTL() time.Duration {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || cfg.Moderation.ClaimTTL <= 0 {
+		return defaultReviewClaimTTL
+	}
+	return cfg.Moderation.ClaimTTL
+}
+
+// toVideoSummaryDto 视频实体转列表DTO，withReview 控制是否返回审核信息
+func toVideoSummaryDto(video *entity.Video, withReview bool) *dto.VideoSummaryDto {
+	summary := &dto.VideoSummaryDto{
+		VideoUUID:   video.UUID(),
+		UserUUID:    video.UserUuid(),
+		Title:       video.Title(),
+		Description: video.Description(),
+		Format:      video.Format(),
+		FileSize:    video.FileSize(),
+		Duration:    int64(video.Duration() / time.Second),
+		Status:      video.Status().Value(),
+		PosterURL:   posterURL(video),
+	}
+	if withReview {
+		summary.ReviewStatus = video.ReviewStatus().Value()
+		summary.ReviewReason = video.ReviewReason()
+	}
+	return summary
+}
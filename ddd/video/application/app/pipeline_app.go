@@ -0,0 +1,338 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// 未配置时的处理流水线参数
+const (
+	defaultPipelinePollInterval = 5 * time.Second
+	defaultPipelineBatchSize    = 50
+	defaultPipelineMaxAttempts  = 2
+	defaultPipelineRetryDelay   = 5 * time.Minute
+)
+
+// defaultPipeline 未配置或配置不合法时使用的流水线定义：扫描干净后才处理源文件，faststart 失败不影响播放，
+// 转码和封面都读取改写后的源文件
+var defaultPipeline = []config.PipelineStepConfig{
+	{Name: vo.PipelineStepScan},
+	{Name: vo.PipelineStepFaststart, DependsOn: []string{vo.PipelineStepScan}, Optional: true},
+	{Name: vo.PipelineStepTranscode, DependsOn: []string{vo.PipelineStepFaststart}},
+	{Name: vo.PipelineStepArtwork, DependsOn: []string{vo.PipelineStepFaststart}},
+}
+
+var (
+	oncePipelineApp      sync.Once
+	singletonPipelineApp PipelineApp
+)
+
+// PipelineApp 视频处理流水线应用服务
+type PipelineApp interface {
+	// StartUploaded 上传完成后为视频创建处理流水线并开始没有依赖的步骤
+	StartUploaded(ctx context.Context, videoUUID string) error
+	// StartSourceChanged 源文件被替换或回滚后，视频仍使用storagePath时为新的源文件创建处理流水线
+	StartSourceChanged(ctx context.Context, videoUUID, storagePath string) error
+	// CancelDeleted 视频被删除后取消未完成的步骤
+	CancelDeleted(ctx context.Context, videoUUID string) error
+	// AdvanceDue 推进有执行中或到期步骤的流水线，返回推进的视频数
+	AdvanceDue(ctx context.Context) (int, error)
+	// GetPipeline 运维人员查看视频最新的处理流水线和每个步骤的状态
+	GetPipeline(ctx context.Context, operatorUUID, videoUUID string) (*dto.PipelineDto, error)
+	// RerunStep 运维人员重新执行失败的步骤，已完成的步骤不再执行
+	RerunStep(ctx context.Context, cmd *cqe.RerunPipelineStepCommand) (*dto.PipelineDto, error)
+}
+
+type pipelineApp struct {
+	videoService    service.VideoService
+	pipelineService service.PipelineService
+	runners         map[string]service.PipelineStepRunner
+}
+
+func DefaultPipelineApp() PipelineApp {
+	assert.NotCircular()
+	oncePipelineApp.Do(func() {
+		singletonPipelineApp = &pipelineApp{
+			videoService:    service.DefaultVideoService(),
+			pipelineService: service.DefaultPipelineService(),
+			runners: map[string]service.PipelineStepRunner{
+				vo.PipelineStepScan:      &scanStep{scanService: service.DefaultScanService()},
+				vo.PipelineStepFaststart: &faststartStep{faststartService: service.DefaultFaststartService()},
+				vo.PipelineStepTranscode: &transcodeStep{
+					videoService:     service.DefaultVideoService(),
+					transcodeService: service.DefaultTranscodeService(),
+				},
+				vo.PipelineStepArtwork: &artworkStep{artworkService: service.DefaultArtworkService()},
+			},
+		}
+	})
+	assert.NotNil(singletonPipelineApp)
+	return singletonPipelineApp
+}
+
+// StartUploaded 事件可能重复到达，同一源文件只创建一条流水线；关闭的功能对应的步骤标记为跳过
+func (a *pipelineApp) StartUploaded(ctx context.Context, videoUUID string) error {
+	skipped := map[string]string{}
+	if !scanConfig().Enabled {
+		skipped[vo.PipelineStepScan] = "scan disabled"
+	}
+	if !faststartConfig().Enabled {
+		skipped[vo.PipelineStepFaststart] = "faststart disabled"
+	}
+	if !artworkConfig().Enabled {
+		skipped[vo.PipelineStepArtwork] = "artwork disabled"
+	}
+	return a.start(ctx, videoUUID, "", skipped)
+}
+
+// StartSourceChanged 替换或回滚的源文件与上传的源文件一样需要扫描、改写和清除元数据（上传版本时可选择保留）；
+// 旧源文件的输出在新的输出完成后被替换
+func (a *pipelineApp) StartSourceChanged(ctx context.Context, videoUUID, storagePath string) error {
+	skipped := map[string]string{}
+	if !scanConfig().Enabled {
+		skipped[vo.PipelineStepScan] = "scan disabled"
+	}
+	if !faststartConfig().Enabled {
+		skipped[vo.PipelineStepFaststart] = "faststart disabled"
+	}
+	if !artworkConfig().Enabled {
+		skipped[vo.PipelineStepArtwork] = "artwork disabled"
+	}
+	return a.start(ctx, videoUUID, storagePath, skipped)
+}
+
+// CancelDeleted 取消已删除视频的流水线，已调度的处理由各自的删除事件处理器取消
+func (a *pipelineApp) CancelDeleted(ctx context.Context, videoUUID string) error {
+	_, err := a.pipelineService.Cancel(ctx, videoUUID, "video deleted")
+	return err
+}
+
+// AdvanceDue 逐个推进，单个视频失败只记录日志，不影响其他视频
+func (a *pipelineApp) AdvanceDue(ctx context.Context) (int, error) {
+	cfg := pipelineConfig()
+	videoUUIDs, err := a.pipelineService.DueVideos(ctx, cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, videoUUID := range videoUUIDs {
+		if err := a.pipelineService.Advance(ctx, videoUUID, a.runners, cfg.RetryDelay); err != nil {
+			logger.Error(fmt.Sprintf("advance pipeline of video %s failed: %v", videoUUID, err))
+		}
+	}
+	return len(videoUUIDs), nil
+}
+
+// GetPipeline 查看处理流水线
+func (a *pipelineApp) GetPipeline(ctx context.Context, operatorUUID, videoUUID string) (*dto.PipelineDto, error) {
+	if !config.IsAdmin(operatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	video, err := a.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	pipeline, err := a.pipelineService.Latest(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrPipelineNotFound, nil)
+	}
+	return toPipelineDto(pipeline, video), nil
+}
+
+// RerunStep 重新执行后立即推进一次，不必等待下一轮
+func (a *pipelineApp) RerunStep(ctx context.Context, cmd *cqe.RerunPipelineStepCommand) (*dto.PipelineDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	video, err := a.videoService.GetVideo(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if _, err := a.pipelineService.Rerun(ctx, video, cmd.Step); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("pipeline step %s of video %s rerun by %s", cmd.Step, cmd.VideoUUID, cmd.OperatorUUID))
+	if err := a.pipelineService.Advance(ctx, cmd.VideoUUID, a.runners, pipelineConfig().RetryDelay); err != nil {
+		logger.Error(fmt.Sprintf("advance pipeline of video %s failed: %v", cmd.VideoUUID, err))
+	}
+	pipeline, err := a.pipelineService.Latest(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrPipelineNotFound, nil)
+	}
+	return toPipelineDto(pipeline, video), nil
+}
+
+// start 视频仍使用storagePath（为空时不校验）时创建流水线并立即推进一次；
+// 推进失败只记录日志，由流水线任务继续推进
+func (a *pipelineApp) start(ctx context.Context, videoUUID, storagePath string, skipped map[string]string) error {
+	video, err := a.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return err
+	}
+	if video == nil || video.StoragePath() == "" || (storagePath != "" && video.StoragePath() != storagePath) {
+		return nil
+	}
+	if _, err := a.pipelineService.Start(ctx, video, pipelineDefinition(), skipped); err != nil {
+		return err
+	}
+	if err := a.pipelineService.Advance(ctx, videoUUID, a.runners, pipelineConfig().RetryDelay); err != nil {
+		logger.Error(fmt.Sprintf("advance pipeline of video %s failed: %v", videoUUID, err))
+	}
+	return nil
+}
+
+// pipelineConfig 处理流水线配置，未配置的参数使用默认值
+func pipelineConfig() config.PipelineConfig {
+	var cfg config.PipelineConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Pipeline
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPipelinePollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultPipelineBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultPipelineMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultPipelineRetryDelay
+	}
+	if len(cfg.Steps) == 0 {
+		cfg.Steps = defaultPipeline
+	}
+	return cfg
+}
+
+// pipelineDefinition 配置的流水线定义；步骤名称未知、依赖不合法、缺少转码步骤（视频将无法离开上传完成状态）
+// 或转码不在扫描之后（未扫描的视频将可以播放）时使用默认定义
+func pipelineDefinition() []vo.PipelineStepDefinition {
+	cfg := pipelineConfig()
+	definition, err := buildPipeline(cfg.Steps, cfg.MaxAttempts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("invalid pipeline config, use default: %v", err))
+		definition, _ = buildPipeline(defaultPipeline, cfg.MaxAttempts)
+	}
+	return definition
+}
+
+// buildPipeline 由配置创建流水线定义并校验
+func buildPipeline(steps []config.PipelineStepConfig, maxAttempts int) ([]vo.PipelineStepDefinition, error) {
+	definition := make([]vo.PipelineStepDefinition, 0, len(steps))
+	transcode := false
+	for _, step := range steps {
+		switch step.Name {
+		case vo.PipelineStepScan, vo.PipelineStepFaststart, vo.PipelineStepArtwork:
+		case vo.PipelineStepTranscode:
+			transcode = true
+		default:
+			return nil, fmt.Errorf("unknown pipeline step %q", step.Name)
+		}
+		attempts := step.MaxAttempts
+		if attempts <= 0 {
+			attempts = maxAttempts
+		}
+		definition = append(definition, vo.NewPipelineStepDefinition(step.Name, step.DependsOn, attempts, step.Optional))
+	}
+	if !transcode {
+		return nil, fmt.Errorf("pipeline step %s is required", vo.PipelineStepTranscode)
+	}
+	if err := vo.ValidatePipeline(definition); err != nil {
+		return nil, err
+	}
+	if !dependsOn(definition, vo.PipelineStepTranscode, vo.PipelineStepScan) {
+		return nil, fmt.Errorf("pipeline step %s must depend on %s", vo.PipelineStepTranscode, vo.PipelineStepScan)
+	}
+	return definition, nil
+}
+
+// dependsOn 步骤name是否直接或间接依赖dependency，定义已通过校验
+func dependsOn(definition []vo.PipelineStepDefinition, name, dependency string) bool {
+	reaches := map[string]bool{dependency: true}
+	for _, step := range definition {
+		for _, upstream := range step.DependsOn() {
+			if reaches[upstream] {
+				reaches[step.Name()] = true
+			}
+		}
+	}
+	return name != dependency && reaches[name]
+}
+
+// processingProgress 视频当前源文件的处理进度，没有流水线或流水线属于旧源文件时为nil
+func processingProgress(ctx context.Context, pipelineService service.PipelineService, video *entity.Video) (*vo.ProcessingProgress, error) {
+	pipeline, err := pipelineService.Latest(ctx, video.UUID())
+	if err != nil || pipeline == nil || !pipeline.IsCurrentFor(video) {
+		return nil, err
+	}
+	progress := pipeline.Progress()
+	return &progress, nil
+}
+
+func toProcessingDto(progress *vo.ProcessingProgress) *dto.ProcessingDto {
+	if progress == nil {
+		return nil
+	}
+	return &dto.ProcessingDto{
+		Status:         progress.Status().Value(),
+		Progress:       progress.Progress(),
+		CompletedSteps: progress.CompletedSteps(),
+		TotalSteps:     progress.TotalSteps(),
+		CurrentSteps:   progress.CurrentSteps(),
+	}
+}
+
+func toPipelineDto(pipeline *entity.Pipeline, video *entity.Video) *dto.PipelineDto {
+	progress := pipeline.Progress()
+	steps := make([]*dto.PipelineStepDto, 0, len(pipeline.Steps()))
+	for _, step := range pipeline.Steps() {
+		steps = append(steps, &dto.PipelineStepDto{
+			Name:        step.Name(),
+			DependsOn:   step.DependsOn(),
+			Optional:    step.Optional(),
+			Status:      step.Status().Value(),
+			Progress:    step.Progress(),
+			Attempts:    step.Attempts(),
+			MaxAttempts: step.MaxAttempts(),
+			ErrorMsg:    step.ErrorMsg(),
+			NextRunAt:   step.NextRunAt(),
+			StartedAt:   step.StartedAt(),
+			CompletedAt: step.CompletedAt(),
+			CreatedAt:   step.CreatedAt(),
+		})
+	}
+	return &dto.PipelineDto{
+		PipelineUUID: pipeline.UUID(),
+		VideoUUID:    pipeline.VideoUuid(),
+		SourcePath:   pipeline.SourcePath(),
+		Current:      pipeline.IsCurrentFor(video),
+		Processing:   toProcessingDto(&progress),
+		Steps:        steps,
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+)
+
+// scanStep 扫描源文件中的恶意软件的步骤，发现恶意软件时视频已被封禁，后续步骤全部取消
+type scanStep struct {
+	scanService service.ScanService
+}
+
+// Start 创建扫描记录，由扫描任务领取执行
+func (r *scanStep) Start(ctx context.Context, video *entity.Video) error {
+	_, err := r.scanService.Schedule(ctx, video)
+	return err
+}
+
+// Check 以当前源文件最新的扫描记录为准，发现恶意软件时步骤取消而不是失败，不能重新执行
+func (r *scanStep) Check(ctx context.Context, video *entity.Video) (vo.StepProgress, error) {
+	job, err := r.scanService.Latest(ctx, video.UUID())
+	if err != nil {
+		return vo.StepProgress{}, err
+	}
+	if job == nil || !job.IsCurrentFor(video) {
+		return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, "scan job not found"), nil
+	}
+	if job.Status() == vo.RenditionStatusSucceeded && job.Infected() {
+		return vo.NewStepProgress(vo.PipelineStepStatusCanceled, 100, fmt.Sprintf("malware detected: %s", job.Signature())), nil
+	}
+	return jobProgress(vo.PipelineStepScan, job.Status(), job.ErrorMsg()), nil
+}
+
+// faststartStep 把源文件改写为 faststart 并清除元数据的步骤
+type faststartStep struct {
+	faststartService service.FaststartService
+}
+
+// Start 创建改写记录，由 faststart 任务领取执行
+func (r *faststartStep) Start(ctx context.Context, video *entity.Video) error {
+	_, err := r.faststartService.Schedule(ctx, video)
+	return err
+}
+
+// Check 以当前源文件最新的改写记录为准
+func (r *faststartStep) Check(ctx context.Context, video *entity.Video) (vo.StepProgress, error) {
+	job, err := r.faststartService.Latest(ctx, video.UUID())
+	if err != nil {
+		return vo.StepProgress{}, err
+	}
+	if job == nil || !job.IsCurrentFor(video) {
+		return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, "faststart job not found"), nil
+	}
+	return jobProgress(vo.PipelineStepFaststart, job.Status(), job.ErrorMsg()), nil
+}
+
+// artworkStep 生成候选封面和拖动预览缩略图的步骤
+type artworkStep struct {
+	artworkService service.ArtworkService
+}
+
+// Start 创建生成记录，由封面任务领取执行
+func (r *artworkStep) Start(ctx context.Context, video *entity.Video) error {
+	_, err := r.artworkService.Schedule(ctx, video)
+	return err
+}
+
+// Check 以当前源文件最新的生成记录为准
+func (r *artworkStep) Check(ctx context.Context, video *entity.Video) (vo.StepProgress, error) {
+	artwork, err := r.artworkService.Latest(ctx, video.UUID())
+	if err != nil {
+		return vo.StepProgress{}, err
+	}
+	if artwork == nil || !artwork.IsCurrentFor(video) {
+		return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, "artwork job not found"), nil
+	}
+	return jobProgress(vo.PipelineStepArtwork, artwork.Status(), artwork.ErrorMsg()), nil
+}
+
+// transcodeStep 按码率阶梯转码的步骤，必需档位全部完成后视频进入可播放状态
+type transcodeStep struct {
+	videoService     service.VideoService
+	transcodeService service.TranscodeService
+}
+
+// Start 刚上传完成的视频进入处理中（转码关闭时直接可播放）；
+// 重试时重新创建整个阶梯，已标记为失败的视频重新进入处理中，可播放的视频继续播放已有的输出
+func (r *transcodeStep) Start(ctx context.Context, video *entity.Video) error {
+	ladder := transcodeLadder()
+	if video.Status() == vo.VideoStatusUploaded {
+		return r.transcodeService.StartProcessing(ctx, video.UUID(), ladder)
+	}
+	if len(ladder) == 0 {
+		return nil
+	}
+	if _, err := r.transcodeService.Schedule(ctx, video, ladder); err != nil {
+		return err
+	}
+	if video.Status() == vo.VideoStatusFailed {
+		_, err := r.videoService.Transition(ctx, video.UUID(), vo.VideoStatusProcessing, "pipeline step retried")
+		return err
+	}
+	return nil
+}
+
+// Check 任一必需档位失败时失败；全部档位结束且必需档位完成时完成，进度为各档位的平均值
+func (r *transcodeStep) Check(ctx context.Context, video *entity.Video) (vo.StepProgress, error) {
+	if video.Status() == vo.VideoStatusUploaded {
+		return vo.NewStepProgress(vo.PipelineStepStatusRunning, 0, ""), nil
+	}
+	if len(transcodeLadder()) == 0 {
+		return vo.NewStepProgress(vo.PipelineStepStatusSucceeded, 100, ""), nil
+	}
+	renditions, err := r.transcodeService.CurrentRenditions(ctx, video)
+	if err != nil {
+		return vo.StepProgress{}, err
+	}
+	if len(renditions) == 0 {
+		return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, "no renditions for current source"), nil
+	}
+	total, done := 0, true
+	for _, rendition := range renditions {
+		switch rendition.Status() {
+		case vo.RenditionStatusPending, vo.RenditionStatusRunning:
+			total += rendition.Progress()
+			done = false
+		case vo.RenditionStatusSucceeded:
+			total += 100
+		default:
+			if rendition.Profile().Required() {
+				return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, fmt.Sprintf("rendition %s %s: %s",
+					rendition.Profile().Name(), rendition.Status().Value(), rendition.ErrorMsg())), nil
+			}
+			total += 100
+		}
+	}
+	if done {
+		return vo.NewStepProgress(vo.PipelineStepStatusSucceeded, 100, ""), nil
+	}
+	return vo.NewStepProgress(vo.PipelineStepStatusRunning, total/len(renditions), ""), nil
+}
+
+// jobProgress 把 faststart、封面等单条记录的状态映射为步骤进度，这些处理不上报百分比
+func jobProgress(name string, status vo.RenditionStatus, errorMsg string) vo.StepProgress {
+	switch status {
+	case vo.RenditionStatusPending, vo.RenditionStatusRunning:
+		return vo.NewStepProgress(vo.PipelineStepStatusRunning, 0, "")
+	case vo.RenditionStatusSucceeded:
+		return vo.NewStepProgress(vo.PipelineStepStatusSucceeded, 100, "")
+	}
+	return vo.NewStepProgress(vo.PipelineStepStatusFailed, 0, fmt.Sprintf("%s %s: %s", name, status.Value(), errorMsg))
+}
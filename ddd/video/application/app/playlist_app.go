@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/hls"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	oncePlaylistApp      sync.Once
+	singletonPlaylistApp PlaylistApp
+)
+
+// PlaylistApp HLS播放列表应用服务
+type PlaylistApp interface {
+	// MasterPlaylist 获取主播放列表，媒体播放列表地址相对主播放列表的地址
+	MasterPlaylist(ctx context.Context, query *cqe.PlaylistQuery) (*dto.PlaylistDto, error)
+	// MediaPlaylist 获取一档的媒体播放列表，分片地址替换为短期有效的预签名地址
+	MediaPlaylist(ctx context.Context, query *cqe.PlaylistQuery) (*dto.PlaylistDto, error)
+}
+
+type playlistApp struct {
+	minioService  gateway.MinioService
+	videoRepo     repo.VideoRepository
+	renditionRepo repo.RenditionRepository
+}
+
+func DefaultPlaylistApp() PlaylistApp {
+	assert.NotCircular()
+	oncePlaylistApp.Do(func() {
+		singletonPlaylistApp = &playlistApp{
+			minioService:  minio.DefaultMinioService(),
+			videoRepo:     persistence.NewVideoRepository(),
+			renditionRepo: persistence.NewRenditionRepository(),
+		}
+	})
+	assert.NotNil(singletonPlaylistApp)
+	return singletonPlaylistApp
+}
+
+// MasterPlaylist 主播放列表只包含媒体播放列表的相对地址，通过分享令牌访问时在地址上附加令牌，否则原样返回
+func (a *playlistApp) MasterPlaylist(ctx context.Context, query *cqe.PlaylistQuery) (*dto.PlaylistDto, error) {
+	video, renditions, err := a.packagedRenditions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(renditions) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrPlaylistNotFound, nil)
+	}
+	content, err := a.minioService.DownloadVideo(ctx, video.AssetPrefix()+vo.HLSMasterPlaylist)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if query.ShareToken != "" {
+		content, err = hls.RewriteURIs(content, func(uri string) (string, error) {
+			return withShareToken(uri, query.ShareToken), nil
+		})
+		if err != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+		}
+	}
+	return &dto.PlaylistDto{Content: content, ContentType: hls.ContentType}, nil
+}
+
+// MediaPlaylist 每次请求重新签名，私有视频的每个分片都只能在签名有效期内访问；
+// 加密分片的密钥地址替换为密钥接口的地址，密钥只发放给登录用户或分享令牌的持有者
+func (a *playlistApp) MediaPlaylist(ctx context.Context, query *cqe.PlaylistQuery) (*dto.PlaylistDto, error) {
+	video, renditions, err := a.packagedRenditions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var playlist vo.HLSPlaylist
+	for _, rendition := range renditions {
+		if rendition.Profile().Name() == query.Rendition {
+			playlist = rendition.HLS()
+			break
+		}
+	}
+	if playlist.IsEmpty() {
+		return nil, errno.NewSimpleBizError(errno.ErrRenditionNotFound, nil, query.Rendition)
+	}
+	content, err := a.minioService.DownloadVideo(ctx, playlist.Path())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	dir := path.Dir(playlist.Path())
+	content, err = hls.RewriteURIs(content, func(uri string) (string, error) {
+		if keyUUID, ok := vo.ParseHLSKeyURI(uri); ok {
+			return withShareToken(fmt.Sprintf("/api/v1/videos/%s/keys/%s", video.UUID(), keyUUID), query.ShareToken), nil
+		}
+		if strings.Contains(uri, "://") {
+			return uri, nil
+		}
+		return a.minioService.GetVideoURL(ctx, path.Join(dir, uri))
+	})
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return &dto.PlaylistDto{Content: content, ContentType: hls.ContentType}, nil
+}
+
+// packagedRenditions 当前已打包HLS的档位
+func (a *playlistApp) packagedRenditions(ctx context.Context, query *cqe.PlaylistQuery) (*entity.Video, []*entity.Rendition, error) {
+	video, renditions, err := visibleRenditions(ctx, a.videoRepo, a.renditionRepo, query.VideoUUID, query.UserUUID, query.ShareToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	packaged := make([]*entity.Rendition, 0, len(renditions))
+	for _, rendition := range renditions {
+		if !rendition.HLS().IsEmpty() {
+			packaged = append(packaged, rendition)
+		}
+	}
+	return video, packaged, nil
+}
+
+// visibleRenditions 校验可见性后返回视频当前可播放的档位，审核员可以获取待审核视频的播放列表，
+// 持有所有者签发的分享令牌时可以获取私有视频的播放列表
+func visibleRenditions(ctx context.Context, videoRepo repo.VideoRepository, renditionRepo repo.RenditionRepository,
+	videoUUID, userUUID, shareToken string) (*entity.Video, []*entity.Rendition, error) {
+	video, err := playableVideo(ctx, videoRepo, videoUUID, userUUID, shareToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	renditions, err := renditionRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return video, playableRenditions(video, renditions), nil
+}
+
+// playableVideo 视频可播放，且能看到视频的用户、审核员或分享令牌的持有者才能获取，否则视为不存在
+func playableVideo(ctx context.Context, videoRepo repo.VideoRepository, videoUUID, userUUID, shareToken string) (*entity.Video, error) {
+	video, err := videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || !(video.IsVisibleTo(userUUID) || config.IsModerator(userUUID) || verifyShareToken(video, shareToken) != "") ||
+		!video.Status().IsPlayable() {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	return video, nil
+}
+
+// verifyShareToken 分享令牌有效且签发人仍是视频所有者时返回签发人UUID，否则返回空
+func verifyShareToken(video *entity.Video, shareToken string) string {
+	if shareToken == "" {
+		return ""
+	}
+	token, ok := vo.ParseShareToken(shareToken)
+	if !ok {
+		return ""
+	}
+	secret, _ := playbackConfig()
+	if secret == "" || !token.Verify(secret, video.UUID(), time.Now()) || !video.IsOwnedBy(token.IssuerUuid()) {
+		return ""
+	}
+	return token.IssuerUuid()
+}
+
+// withShareToken 在地址上附加分享令牌，令牌为空时原样返回
+func withShareToken(uri, shareToken string) string {
+	if shareToken == "" {
+		return uri
+	}
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + url.Values{"share_token": {shareToken}}.Encode()
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"sync"
+	"time"
+)
+
+// defaultReconcileGracePeriod 未配置时的对账宽限期
+const defaultReconcileGracePeriod = 24 * time.Hour
+
+var (
+	onceReconcileApp      sync.Once
+	singletonReconcileApp ReconcileApp
+)
+
+// ReconcileApp 对象存储与数据库对账应用服务
+type ReconcileApp interface {
+	// Reconcile 运维手动触发对账
+	Reconcile(ctx context.Context, cmd *cqe.ReconcileCommand) (*dto.ReconcileReportDto, error)
+	// RunScheduled 定时任务触发对账，是否试运行由配置决定
+	RunScheduled(ctx context.Context) (*dto.ReconcileReportDto, error)
+}
+
+type reconcileApp struct {
+	reconcileService service.ReconcileService
+}
+
+func DefaultReconcileApp() ReconcileApp {
+	assert.NotCircular()
+	onceReconcileApp.Do(func() {
+		singletonReconcileApp = &reconcileApp{
+			reconcileService: service.DefaultReconcileService(),
+		}
+	})
+	assert.NotNil(singletonReconcileApp)
+	return singletonReconcileApp
+}
+
+// Reconcile 运维手动触发对账
+func (a *reconcileApp) Reconcile(ctx context.Context, cmd *cqe.ReconcileCommand) (*dto.ReconcileReportDto, error) {
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	report, err := a.reconcileService.Reconcile(ctx, cmd.DryRun, reconcileGracePeriod())
+	if err != nil {
+		return nil, err
+	}
+	return toReconcileReportDto(report), nil
+}
+
+// RunScheduled 定时任务触发对账
+func (a *reconcileApp) RunScheduled(ctx context.Context) (*dto.ReconcileReportDto, error) {
+	dryRun := true
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		dryRun = cfg.Reconcile.DryRun
+	}
+	report, err := a.reconcileService.Reconcile(ctx, dryRun, reconcileGracePeriod())
+	if err != nil {
+		return nil, err
+	}
+	return toReconcileReportDto(report), nil
+}
+
+// reconcileGracePeriod 对账宽限期
+func reconcileGracePeriod() time.Duration {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || cfg.Reconcile.GracePeriod <= 0 {
+		return defaultReconcileGracePeriod
+	}
+	return cfg.Reconcile.GracePeriod
+}
+
+func toReconcileReportDto(report *entity.ReconcileReport) *dto.ReconcileReportDto {
+	orphans := make([]*dto.OrphanObjectDto, 0, len(report.Orphans()))
+	for _, orphan := range report.Orphans() {
+		orphans = append(orphans, &dto.OrphanObjectDto{
+			Key:          orphan.Object().Key(),
+			Size:         orphan.Object().Size(),
+			LastModified: orphan.Object().LastModified(),
+			Action:       orphan.Action().Value(),
+			Error:        orphan.ErrorMsg(),
+		})
+	}
+	missing := make([]*dto.MissingObjectDto, 0, len(report.Missing()))
+	for _, item := range report.Missing() {
+		missing = append(missing, &dto.MissingObjectDto{
+			VideoUUID:   item.VideoUuid(),
+			StoragePath: item.StoragePath(),
+			Status:      item.Status().Value(),
+			Action:      item.Action().Value(),
+			Error:       item.ErrorMsg(),
+		})
+	}
+	return &dto.ReconcileReportDto{
+		DryRun:         report.DryRun(),
+		GracePeriod:    report.GracePeriod().String(),
+		StartedAt:      report.StartedAt(),
+		FinishedAt:     report.FinishedAt(),
+		ScannedObjects: report.ScannedObjects(),
+		ScannedVideos:  report.ScannedVideos(),
+		Orphans:        orphans,
+		Missing:        missing,
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// 未配置时的扫描参数
+const (
+	defaultScanConcurrency = 2
+	defaultScanTimeout     = 10 * time.Minute
+	// scanLeaseMargin 扫描不续租，租约在超时之外多留的时间
+	scanLeaseMargin = time.Minute
+)
+
+var (
+	onceScanApp      sync.Once
+	singletonScanApp ScanApp
+)
+
+// ScanApp 源文件恶意软件扫描应用服务
+type ScanApp interface {
+	// CancelDeleted 视频被删除后取消其未完成的扫描
+	CancelDeleted(ctx context.Context, videoUUID string) error
+	// ProcessDue 领取到期的记录并发执行，返回执行的数量
+	ProcessDue(ctx context.Context) (int, error)
+}
+
+type scanApp struct {
+	scanService service.ScanService
+}
+
+func DefaultScanApp() ScanApp {
+	assert.NotCircular()
+	onceScanApp.Do(func() {
+		singletonScanApp = &scanApp{
+			scanService: service.DefaultScanService(),
+		}
+	})
+	assert.NotNil(singletonScanApp)
+	return singletonScanApp
+}
+
+// CancelDeleted 取消已删除视频的扫描
+func (a *scanApp) CancelDeleted(ctx context.Context, videoUUID string) error {
+	_, err := a.scanService.Cancel(ctx, videoUUID, "video deleted")
+	return err
+}
+
+// ProcessDue 每个实例同时扫描的数量不超过配置的并发数
+func (a *scanApp) ProcessDue(ctx context.Context) (int, error) {
+	cfg := scanConfig()
+	jobs, err := a.scanService.ClaimDue(ctx, cfg.Concurrency, cfg.Timeout+scanLeaseMargin)
+	if err != nil {
+		return 0, err
+	}
+	opts := vo.NewScanOptions(cfg.MaxAttempts, cfg.RetryDelay, cfg.Timeout)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *entity.ScanJob) {
+			defer wg.Done()
+			if err := a.scanService.Process(ctx, job, opts); err != nil {
+				logger.Error(fmt.Sprintf("scan %s of video %s failed: %v", job.UUID(), job.VideoUuid(), err))
+			}
+		}(job)
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// scanConfig 扫描配置，未配置的参数使用默认值
+func scanConfig() config.ScanConfig {
+	var cfg config.ScanConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Scan
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultScanConcurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultTranscodeMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultTranscodeRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultScanTimeout
+	}
+	return cfg
+}
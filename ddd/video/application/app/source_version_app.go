@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"sync"
+	"time"
+)
+
+// defaultSourceRetention 未配置时被替换源文件的保留时长
+const defaultSourceRetention = 30 * 24 * time.Hour
+
+var (
+	onceSourceVersionApp      sync.Once
+	singletonSourceVersionApp SourceVersionApp
+)
+
+// SourceVersionApp 视频源文件版本应用服务
+type SourceVersionApp interface {
+	// ReplaceSource 上传新的源文件，上传完成后视频切换到新版本，视频UUID保持不变
+	ReplaceSource(ctx context.Context, cmd *cqe.ReplaceSourceCommand) (*dto.SourceVersionDto, error)
+	// ListSourceVersions 视频所有者查看源文件版本
+	ListSourceVersions(ctx context.Context, userUUID, videoUUID string) ([]*dto.SourceVersionDto, error)
+	// RollbackSource 视频所有者回滚到保留期内的历史版本
+	RollbackSource(ctx context.Context, cmd *cqe.RollbackSourceCommand) (*dto.SourceVersionDto, error)
+	// PurgeExpired 清理超过保留期的源文件，返回清理的版本数
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type sourceVersionApp struct {
+	minioService         gateway.MinioService
+	videoService         service.VideoService
+	sourceVersionService service.SourceVersionService
+	pipelineApp          PipelineApp
+}
+
+func DefaultSourceVersionApp() SourceVersionApp {
+	assert.NotCircular()
+	onceSourceVersionApp.Do(func() {
+		singletonSourceVersionApp = &sourceVersionApp{
+			minioService:         minio.DefaultMinioService(),
+			videoService:         service.DefaultVideoService(),
+			sourceVersionService: service.DefaultSourceVersionService(),
+			pipelineApp:          DefaultPipelineApp(),
+		}
+	})
+	assert.NotNil(singletonSourceVersionApp)
+	return singletonSourceVersionApp
+}
+
+// ReplaceSource 创建新版本后异步上传，视频在上传期间继续使用当前源文件
+func (a *sourceVersionApp) ReplaceSource(ctx context.Context, cmd *cqe.ReplaceSourceCommand) (*dto.SourceVersionDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	video, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := a.sourceVersionService.BeginReplace(ctx, video, cmd.UserUUID, cmd.File.Filename, cmd.File.Size, cmd.Format,
+		cmd.KeepMetadata)
+	if err != nil {
+		return nil, err
+	}
+	expected, _ := cmd.ExpectedChecksum()
+	uploadVo := vo.NewUploadVideo(cmd.UserUUID, video.UUID(), version.UUID(), version.StoragePath(), cmd.File, expected)
+
+	go a.replaceInBackground(context.WithoutCancel(ctx), uploadVo)
+
+	return toSourceVersionDto(version), nil
+}
+
+// replaceInBackground 上传新版本并根据结果切换或丢弃，切换成功后为新源文件创建处理流水线
+func (a *sourceVersionApp) replaceInBackground(ctx context.Context, uploadVo *vo.VideoUploadVO) {
+	receipt, uploadErr := a.minioService.SyncUploadVideo(ctx, uploadVo)
+	if err := a.sourceVersionService.CompleteReplace(ctx, uploadVo.TaskUUID(), receipt, uploadErr, sourceRetention()); err != nil {
+		logger.Error(fmt.Sprintf("ReplaceSource CompleteReplace video_uuid: %s, version_uuid: %s, error: %v", uploadVo.VideoUUID(), uploadVo.TaskUUID(), err))
+		return
+	}
+	if uploadErr == nil {
+		a.reprocess(ctx, uploadVo.VideoUUID(), uploadVo.StoragePath())
+	}
+}
+
+// ListSourceVersions 查看源文件版本
+func (a *sourceVersionApp) ListSourceVersions(ctx context.Context, userUUID, videoUUID string) ([]*dto.SourceVersionDto, error) {
+	if _, err := a.ownedVideo(ctx, userUUID, videoUUID); err != nil {
+		return nil, err
+	}
+	versions, err := a.sourceVersionService.Versions(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*dto.SourceVersionDto, 0, len(versions))
+	for _, version := range versions {
+		dtos = append(dtos, toSourceVersionDto(version))
+	}
+	return dtos, nil
+}
+
+// RollbackSource 回滚到历史版本
+func (a *sourceVersionApp) RollbackSource(ctx context.Context, cmd *cqe.RollbackSourceCommand) (*dto.SourceVersionDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	video, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := a.sourceVersionService.Rollback(ctx, video, cmd.Version, sourceRetention())
+	if err != nil {
+		return nil, err
+	}
+	a.reprocess(ctx, video.UUID(), version.StoragePath())
+	return toSourceVersionDto(version), nil
+}
+
+// PurgeExpired 清理超过保留期的源文件
+func (a *sourceVersionApp) PurgeExpired(ctx context.Context) (int, error) {
+	return a.sourceVersionService.PurgeExpired(ctx, time.Now())
+}
+
+// ownedVideo 获取视频并校验所有者
+func (a *sourceVersionApp) ownedVideo(ctx context.Context, userUUID, videoUUID string) (*entity.Video, error) {
+	video, err := a.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(userUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	return video, nil
+}
+
+// reprocess 视频仍使用storagePath时为其创建处理流水线，重新转码整个阶梯并重新生成封面和缩略图，
+// 旧源文件的输出在同名档位完成后被替换，旧的候选帧在新的生成完成后被替换
+func (a *sourceVersionApp) reprocess(ctx context.Context, videoUUID, storagePath string) {
+	if err := a.pipelineApp.StartSourceChanged(ctx, videoUUID, storagePath); err != nil {
+		logger.Error(fmt.Sprintf("start pipeline after source change video_uuid: %s, error: %v", videoUUID, err))
+	}
+}
+
+// sourceRetention 被替换源文件的保留时长
+func sourceRetention() time.Duration {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || cfg.SourceVersion.Retention <= 0 {
+		return defaultSourceRetention
+	}
+	return cfg.SourceVersion.Retention
+}
+
+func toSourceVersionDto(version *entity.SourceVersion) *dto.SourceVersionDto {
+	return &dto.SourceVersionDto{
+		VersionUUID:    version.UUID(),
+		Version:        version.Version(),
+		Status:         version.Status().Value(),
+		Filename:       version.Filename(),
+		FileSize:       version.FileSize(),
+		Format:         version.Format(),
+		ChecksumSHA256: version.Checksum().SHA256(),
+		KeepMetadata:   version.KeepMetadata(),
+		ErrorMsg:       version.ErrorMsg(),
+		Restorable:     version.CanRollback(time.Now()),
+		ActivatedAt:    version.ActivatedAt(),
+		RetainUntil:    version.RetainUntil(),
+		CreatedAt:      version.CreatedAt(),
+	}
+}
@@ -0,0 +1,296 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go-video/ddd/video/application/cqe"
+	"go-video/ddd/video/application/dto"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/service"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// 未配置时的转码参数
+const (
+	defaultTranscodeConcurrency = 1
+	defaultTranscodeMaxAttempts = 3
+	defaultTranscodeRetryDelay  = time.Minute
+	defaultTranscodeTimeout     = 2 * time.Hour
+	defaultSegmentDuration      = 6 * time.Second
+	defaultKeyRotation          = 10
+)
+
+// renditionName 档位名称会出现在存储路径和播放地址中，只允许字母、数字、下划线和连字符
+var renditionName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+
+// defaultLadder 未配置码率阶梯时使用的默认阶梯
+var defaultLadder = []config.RenditionConfig{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128, Required: true},
+	{Name: "480p", Height: 480, VideoBitrate: 1400, AudioBitrate: 128, Required: true},
+}
+
+var (
+	onceTranscodeApp      sync.Once
+	singletonTranscodeApp TranscodeApp
+)
+
+// TranscodeApp 视频转码应用服务
+type TranscodeApp interface {
+	// CancelDeleted 视频被删除后取消其未完成的转码
+	CancelDeleted(ctx context.Context, videoUUID string) error
+	// ProcessDue 领取到期的转码并发执行，返回执行的档位数
+	ProcessDue(ctx context.Context) (int, error)
+	// ListRenditions 视频所有者查看转码输出和进度
+	ListRenditions(ctx context.Context, userUUID, videoUUID string) ([]*dto.RenditionDto, error)
+	// Retranscode 视频所有者重新转码可播放或处理失败的视频
+	Retranscode(ctx context.Context, cmd *cqe.TranscodeCommand) (*dto.TranscodeDto, error)
+	// CancelTranscode 视频所有者取消未完成的转码，处理中的视频标记为失败
+	CancelTranscode(ctx context.Context, cmd *cqe.TranscodeCommand) (*dto.TranscodeDto, error)
+}
+
+type transcodeApp struct {
+	videoService     service.VideoService
+	transcodeService service.TranscodeService
+}
+
+func DefaultTranscodeApp() TranscodeApp {
+	assert.NotCircular()
+	onceTranscodeApp.Do(func() {
+		singletonTranscodeApp = &transcodeApp{
+			videoService:     service.DefaultVideoService(),
+			transcodeService: service.DefaultTranscodeService(),
+		}
+	})
+	assert.NotNil(singletonTranscodeApp)
+	return singletonTranscodeApp
+}
+
+// CancelDeleted 取消已删除视频的转码，正在执行的转码在下次续租时中止
+func (a *transcodeApp) CancelDeleted(ctx context.Context, videoUUID string) error {
+	_, err := a.transcodeService.Cancel(ctx, videoUUID, "video deleted")
+	return err
+}
+
+// ProcessDue 每个实例同时执行的档位数不超过配置的并发数
+func (a *transcodeApp) ProcessDue(ctx context.Context) (int, error) {
+	cfg := transcodeConfig()
+	renditions, err := a.transcodeService.ClaimDue(ctx, cfg.Concurrency)
+	if err != nil {
+		return 0, err
+	}
+	keyRotation := 0
+	if cfg.HLSEncryption {
+		keyRotation = cfg.KeyRotation
+	}
+	opts := vo.NewTranscodeOptions(cfg.WorkDir, cfg.MaxAttempts, cfg.RetryDelay, cfg.Timeout, cfg.SegmentDuration, keyRotation)
+	var wg sync.WaitGroup
+	for _, rendition := range renditions {
+		wg.Add(1)
+		go func(rendition *entity.Rendition) {
+			defer wg.Done()
+			if err := a.transcodeService.Transcode(ctx, rendition, opts); err != nil {
+				logger.Error(fmt.Sprintf("transcode rendition %s of video %s failed: %v", rendition.UUID(), rendition.VideoUuid(), err))
+			}
+		}(rendition)
+	}
+	wg.Wait()
+	return len(renditions), nil
+}
+
+// ListRenditions 查看转码输出
+func (a *transcodeApp) ListRenditions(ctx context.Context, userUUID, videoUUID string) ([]*dto.RenditionDto, error) {
+	video, err := a.ownedVideo(ctx, userUUID, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	renditions, err := a.transcodeService.Renditions(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*dto.RenditionDto, 0, len(renditions))
+	for _, rendition := range renditions {
+		dtos = append(dtos, toRenditionDto(rendition, video))
+	}
+	return dtos, nil
+}
+
+// Retranscode 为当前源文件重新转码整个阶梯：可播放的视频继续播放已有的输出，处理失败的视频重新进入处理中
+func (a *transcodeApp) Retranscode(ctx context.Context, cmd *cqe.TranscodeCommand) (*dto.TranscodeDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	ladder := transcodeLadder()
+	if len(ladder) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrTranscodeDisabled, nil)
+	}
+	video, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	status := video.Status()
+	if (status != vo.VideoStatusReady && status != vo.VideoStatusFailed) || video.StoragePath() == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrTranscodeNotAllowed, nil)
+	}
+	renditions, err := a.transcodeService.Schedule(ctx, video, ladder)
+	if err != nil {
+		return nil, err
+	}
+	if status == vo.VideoStatusFailed {
+		if video, err = a.videoService.Transition(ctx, video.UUID(), vo.VideoStatusProcessing, "retranscode requested"); err != nil {
+			return nil, err
+		}
+	}
+	return &dto.TranscodeDto{
+		VideoUUID:  video.UUID(),
+		Status:     video.Status().Value(),
+		Renditions: len(renditions),
+	}, nil
+}
+
+// CancelTranscode 取消转码
+func (a *transcodeApp) CancelTranscode(ctx context.Context, cmd *cqe.TranscodeCommand) (*dto.TranscodeDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := a.ownedVideo(ctx, cmd.UserUUID, cmd.VideoUUID); err != nil {
+		return nil, err
+	}
+	canceled, err := a.transcodeService.Cancel(ctx, cmd.VideoUUID, "transcoding canceled")
+	if err != nil {
+		return nil, err
+	}
+	if canceled == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrTranscodeNotActive, nil)
+	}
+	video, err := a.videoService.GetVideo(ctx, cmd.VideoUUID)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.TranscodeDto{VideoUUID: cmd.VideoUUID, Renditions: int(canceled)}
+	if video != nil {
+		result.Status = video.Status().Value()
+	}
+	return result, nil
+}
+
+// ownedVideo 获取视频并校验所有者
+func (a *transcodeApp) ownedVideo(ctx context.Context, userUUID, videoUUID string) (*entity.Video, error) {
+	video, err := a.videoService.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(userUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	return video, nil
+}
+
+// transcodeConfig 转码配置，未配置的参数使用默认值
+func transcodeConfig() config.TranscodeConfig {
+	var cfg config.TranscodeConfig
+	if global := config.GetGlobalConfig(); global != nil {
+		cfg = global.Transcode
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultTranscodeConcurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultTranscodeMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultTranscodeRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTranscodeTimeout
+	}
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = defaultSegmentDuration
+	}
+	if cfg.KeyRotation <= 0 {
+		cfg.KeyRotation = defaultKeyRotation
+	}
+	if len(cfg.Ladder) == 0 {
+		cfg.Ladder = defaultLadder
+	}
+	return cfg
+}
+
+// transcodeLadder 配置的码率阶梯，转码关闭时为空；名称重复或参数不合法的档位被忽略
+func transcodeLadder() []vo.RenditionProfile {
+	cfg := transcodeConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+	seen := make(map[string]bool, len(cfg.Ladder))
+	ladder := make([]vo.RenditionProfile, 0, len(cfg.Ladder))
+	for _, rung := range cfg.Ladder {
+		if !renditionName.MatchString(rung.Name) || seen[rung.Name] || rung.Height <= 0 || rung.VideoBitrate <= 0 || rung.AudioBitrate <= 0 {
+			logger.Error(fmt.Sprintf("ignore invalid rendition config: %+v", rung))
+			continue
+		}
+		seen[rung.Name] = true
+		ladder = append(ladder, vo.NewRenditionProfile(rung.Name, rung.Height, rung.VideoBitrate, rung.AudioBitrate, rung.Required))
+	}
+	return ladder
+}
+
+// playableRenditions 当前源文件已转码完成的档位，同名档位只保留最新的
+func playableRenditions(video *entity.Video, renditions []*entity.Rendition) []*entity.Rendition {
+	seen := make(map[string]bool, len(renditions))
+	playable := make([]*entity.Rendition, 0, len(renditions))
+	for _, rendition := range renditions {
+		if rendition.Status() != vo.RenditionStatusSucceeded || !rendition.IsCurrentFor(video) || seen[rendition.Profile().Name()] {
+			continue
+		}
+		seen[rendition.Profile().Name()] = true
+		playable = append(playable, rendition)
+	}
+	return playable
+}
+
+func toRenditionDto(rendition *entity.Rendition, video *entity.Video) *dto.RenditionDto {
+	profile := rendition.Profile()
+	return &dto.RenditionDto{
+		RenditionUUID: rendition.UUID(),
+		Name:          profile.Name(),
+		Required:      profile.Required(),
+		Status:        rendition.Status().Value(),
+		Progress:      rendition.Progress(),
+		Attempts:      rendition.Attempts(),
+		Current:       rendition.IsCurrentFor(video),
+		MaxHeight:     profile.Height(),
+		VideoBitrate:  profile.VideoBitrate(),
+		AudioBitrate:  profile.AudioBitrate(),
+		Width:         rendition.Width(),
+		Height:        rendition.Height(),
+		FileSize:      rendition.FileSize(),
+		ErrorMsg:      rendition.ErrorMsg(),
+		StartedAt:     rendition.StartedAt(),
+		CompletedAt:   rendition.CompletedAt(),
+		CreatedAt:     rendition.CreatedAt(),
+	}
+}
+
+func toPlayableRenditionDtos(video *entity.Video, renditions []*entity.Rendition) []*dto.PlayableRenditionDto {
+	dtos := make([]*dto.PlayableRenditionDto, 0, len(renditions))
+	for _, rendition := range playableRenditions(video, renditions) {
+		dtos = append(dtos, &dto.PlayableRenditionDto{
+			Name:    rendition.Profile().Name(),
+			Width:   rendition.Width(),
+			Height:  rendition.Height(),
+			Bitrate: rendition.Profile().VideoBitrate(),
+		})
+	}
+	return dtos
+}
@@ -2,20 +2,29 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-video/ddd/video/application/cqe"
 	"go-video/ddd/video/application/dto"
 	"go-video/ddd/video/domain/entity"
 	"go-video/ddd/video/domain/gateway"
 	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/service"
 	"go-video/ddd/video/domain/vo"
 	"go-video/ddd/video/infrastructure/database/persistence"
+	videoGateway "go-video/ddd/video/infrastructure/gateway"
 	"go-video/ddd/video/infrastructure/minio"
 	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
 	"go-video/pkg/logger"
 	"sync"
+	"time"
 )
 
+// batchUploadConcurrency 同一批量上传中同时上传的文件数
+const batchUploadConcurrency = 4
+
 var (
 	onceVideoApp      sync.Once
 	singletonVideoApp VideoApp
@@ -24,11 +33,38 @@ var (
 type VideoApp interface {
 	Create(ctx context.Context, cmd *cqe.UploadVideoCommand) (*dto.UploadVideoDto, error)
 	SyncUploadVideo(ctx context.Context, cmd *cqe.UploadVideoCommand) (*dto.VideoSyncVideoDto, error)
+	// ImportLibraryFile 导入本地视频文件（迁移已有视频库），所有者已有相同内容的视频时跳过
+	ImportLibraryFile(ctx context.Context, cmd *cqe.ImportLibraryFileCommand) (*dto.LibraryImportDto, error)
+	// BatchUpload 批量异步上传，每个文件单独校验，部分文件被拒绝时其余文件照常上传
+	BatchUpload(ctx context.Context, cmd *cqe.BatchUploadCommand) (*dto.BatchUploadDto, error)
+	// GetUploadBatch 按子任务汇总批量上传进度
+	GetUploadBatch(ctx context.Context, query *cqe.GetUploadBatchQuery) (*dto.UploadBatchStatusDto, error)
+	GetVideo(ctx context.Context, query *cqe.GetVideoQuery) (*dto.VideoDetailDto, error)
+	// DeleteVideo 所有者删除视频，删除事件与记录在同一事务中写入发件箱
+	DeleteVideo(ctx context.Context, cmd *cqe.DeleteVideoCommand) error
+	// ListVideos 公开视频列表，待审核和被拒绝的视频不会出现
+	ListVideos(ctx context.Context, query *cqe.ListVideosQuery) (*dto.VideoListDto, error)
+	// ListUserVideos 用户自己的视频列表，包含审核状态和拒绝原因
+	ListUserVideos(ctx context.Context, query *cqe.ListVideosQuery) (*dto.VideoListDto, error)
+	// OpenStream 打开视频源文件用于播放，支持Range请求，加密对象透明解密
+	OpenStream(ctx context.Context, query *cqe.StreamVideoQuery) (*dto.VideoStreamDto, error)
+	// GetStatusHistory 运维查看视频的状态迁移历史
+	GetStatusHistory(ctx context.Context, operatorUUID, videoUUID string) ([]*dto.StatusTransitionDto, error)
+	// VerifyChecksum 运维重新校验存储中的源文件与上传时记录的校验和是否一致
+	VerifyChecksum(ctx context.Context, operatorUUID, videoUUID string) (*dto.ChecksumVerificationDto, error)
 }
 
 type videoApp struct {
 	minioService gateway.MinioService
 	videoRepo    repo.VideoRepository
+	captionRepo  repo.CaptionRepository
+	chapterRepo  repo.ChapterRepository
+	videoService service.VideoService
+	userGateway  gateway.UserGateway
+
+	renditionRepo   repo.RenditionRepository
+	artworkRepo     repo.ArtworkRepository
+	pipelineService service.PipelineService
 }
 
 func DefaultVideoApp() VideoApp {
@@ -37,6 +73,14 @@ func DefaultVideoApp() VideoApp {
 		singletonVideoApp = &videoApp{
 			minioService: minio.DefaultMinioService(),
 			videoRepo:    persistence.NewVideoRepository(),
+			captionRepo:  persistence.NewCaptionRepository(),
+			chapterRepo:  persistence.NewChapterRepository(),
+			videoService: service.DefaultVideoService(),
+			userGateway:  videoGateway.NewUserGateway(),
+
+			renditionRepo:   persistence.NewRenditionRepository(),
+			artworkRepo:     persistence.NewArtworkRepository(),
+			pipelineService: service.DefaultPipelineService(),
 		}
 	})
 	assert.NotNil(singletonVideoApp)
@@ -47,17 +91,17 @@ func (v *videoApp) Create(ctx context.Context, cmd *cqe.UploadVideoCommand) (*dt
 	if err := cmd.Validate(); err != nil {
 		return nil, err
 	}
-	fileName, err := v.minioService.UploadVideo(ctx, cmd.UserUUID, cmd.File)
+	expected, _ := cmd.ExpectedChecksum()
+	receipt, err := v.minioService.UploadVideo(ctx, cmd.UserUUID, cmd.File, expected)
 	if err != nil {
-		return nil, err
+		return nil, uploadBizError(err)
 	}
 	videoEntity := entity.DefaultVideo(
-		cmd.UserUUID, cmd.Title, cmd.Description, cmd.File.Filename, cmd.FileSize, cmd.Format, fileName,
-		vo.VideoStatusInit,
+		cmd.UserUUID, cmd.Title, cmd.Description, cmd.File.Filename, cmd.FileSize, cmd.Format, receipt.ObjectName(),
+		vo.VideoStatusUploading,
 	)
-
-	err = v.videoRepo.Save(ctx, videoEntity)
-	if err != nil {
+	videoEntity.SetKeepMetadata(cmd.KeepMetadata)
+	if err := v.saveUploadedVideo(ctx, videoEntity, receipt); err != nil {
 		return nil, err
 	}
 	return &dto.UploadVideoDto{
@@ -65,6 +109,22 @@ func (v *videoApp) Create(ctx context.Context, cmd *cqe.UploadVideoCommand) (*dt
 	}, nil
 }
 
+// saveUploadedVideo 保存源文件已同步上传完成的视频
+func (v *videoApp) saveUploadedVideo(ctx context.Context, videoEntity *entity.Video, receipt vo.UploadReceipt) error {
+	videoEntity.SetReview(moderationMode().InitialReviewStatus(), "")
+	videoEntity.SetUploadedChecksum(receipt.Checksum(), receipt.ETag())
+
+	err := v.videoRepo.Save(ctx, videoEntity)
+	if err != nil {
+		return err
+	}
+	// 源文件已同步上传完成，上传完成事件触发转码流水线
+	if _, err := v.videoService.Transition(ctx, videoEntity.UUID(), vo.VideoStatusUploaded, "source uploaded"); err != nil {
+		return err
+	}
+	return nil
+}
+
 // SyncUploadVideo 异步上传视频
 func (v *videoApp) SyncUploadVideo(ctx context.Context, cmd *cqe.UploadVideoCommand) (*dto.VideoSyncVideoDto, error) {
 	if err := cmd.Validate(); err != nil {
@@ -72,7 +132,9 @@ func (v *videoApp) SyncUploadVideo(ctx context.Context, cmd *cqe.UploadVideoComm
 	}
 	storagePath := v.minioService.GenerateObjectName(cmd.UserUUID, cmd.File.Filename)
 	logger.Info(fmt.Sprintf("upload video %s to %s", cmd.UserUUID, storagePath))
-	videoEntity := entity.DefaultVideo(cmd.UserUUID, cmd.Title, cmd.Description, cmd.File.Filename, cmd.FileSize, cmd.Format, storagePath, vo.VideoStatusInit)
+	videoEntity := entity.DefaultVideo(cmd.UserUUID, cmd.Title, cmd.Description, cmd.File.Filename, cmd.FileSize, cmd.Format, storagePath, vo.VideoStatusUploading)
+	videoEntity.SetReview(moderationMode().InitialReviewStatus(), "")
+	videoEntity.SetKeepMetadata(cmd.KeepMetadata)
 	videoTaskEntity := entity.DefaultVideoUploadTaskEntity(
 		cmd.UserUUID, videoEntity.UUID(), vo.VideoUploadTaskStatusInit, "", nil, storagePath)
 	err := v.videoRepo.CreateVideo(ctx, videoEntity, videoTaskEntity)
@@ -80,12 +142,474 @@ func (v *videoApp) SyncUploadVideo(ctx context.Context, cmd *cqe.UploadVideoComm
 		logger.Info(fmt.Sprintf("SyncUploadVideo CreateVideo Failed to sync upload user_uuid: %v video: %s", cmd.UserUUID, err))
 		return nil, err
 	}
-	videoUploadVo := vo.NewUploadVideo(cmd.UserUUID, videoEntity.UUID(), videoTaskEntity.UUID(), storagePath, cmd.File)
+	expected, _ := cmd.ExpectedChecksum()
+	videoUploadVo := vo.NewUploadVideo(cmd.UserUUID, videoEntity.UUID(), videoTaskEntity.UUID(), storagePath, cmd.File, expected)
 
-	go v.minioService.SyncUploadVideo(ctx, videoUploadVo)
+	go v.uploadInBackground(context.WithoutCancel(ctx), videoUploadVo)
 
 	return &dto.VideoSyncVideoDto{
 		VideoUUID: videoEntity.UUID(),
 		TaskUUID:  videoTaskEntity.UUID(),
 	}, nil
 }
+
+// uploadInBackground 执行异步上传并根据结果迁移视频和上传任务状态
+func (v *videoApp) uploadInBackground(ctx context.Context, videoUploadVo *vo.VideoUploadVO) {
+	if err := v.videoService.StartUpload(ctx, videoUploadVo.TaskUUID()); err != nil {
+		logger.Error(fmt.Sprintf("SyncUploadVideo StartUpload task_uuid: %s, error: %v", videoUploadVo.TaskUUID(), err))
+		return
+	}
+	receipt, uploadErr := v.minioService.SyncUploadVideo(ctx, videoUploadVo)
+	if err := v.videoService.CompleteUpload(ctx, videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), receipt, uploadErr); err != nil {
+		logger.Error(fmt.Sprintf("SyncUploadVideo CompleteUpload video_uuid: %s, task_uuid: %s, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err))
+	}
+}
+
+// BatchUpload 批量异步上传：先逐个校验文件，再在一个事务中创建批量记录和全部视频、上传任务，最后后台上传
+func (v *videoApp) BatchUpload(ctx context.Context, cmd *cqe.BatchUploadCommand) (*dto.BatchUploadDto, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]*dto.BatchUploadItemDto, 0, len(cmd.Files))
+	accepted := make([]*cqe.UploadVideoCommand, 0, len(cmd.Files))
+	acceptedResults := make([]*dto.BatchUploadItemDto, 0, len(cmd.Files))
+	seen := make(map[string]struct{}, len(cmd.Files))
+	for _, file := range cmd.Files {
+		result := &dto.BatchUploadItemDto{Filename: file.Filename}
+		results = append(results, result)
+
+		if _, ok := seen[file.Filename]; ok {
+			rejectBatchItem(result, errno.NewSimpleBizError(errno.ErrBatchItemInvalid, nil, "duplicate filename"))
+			continue
+		}
+		seen[file.Filename] = struct{}{}
+		itemCmd, ok := cmd.Item(file)
+		if !ok {
+			rejectBatchItem(result, errno.NewSimpleBizError(errno.ErrBatchItemInvalid, nil, "no manifest entry for file"))
+			continue
+		}
+		if err := itemCmd.Validate(); err != nil {
+			rejectBatchItem(result, err)
+			continue
+		}
+		accepted = append(accepted, itemCmd)
+		acceptedResults = append(acceptedResults, result)
+	}
+
+	batch := entity.DefaultUploadBatch(cmd.UserUUID, len(cmd.Files), len(cmd.Files)-len(accepted))
+	items := make([]*entity.VideoEntity, 0, len(accepted))
+	uploads := make([]*vo.VideoUploadVO, 0, len(accepted))
+	reviewStatus := moderationMode().InitialReviewStatus()
+	for i, itemCmd := range accepted {
+		storagePath := v.minioService.GenerateObjectName(cmd.UserUUID, itemCmd.File.Filename)
+		videoEntity := entity.DefaultVideo(cmd.UserUUID, itemCmd.Title, itemCmd.Description, itemCmd.File.Filename,
+			itemCmd.FileSize, itemCmd.Format, storagePath, vo.VideoStatusUploading)
+		videoEntity.SetReview(reviewStatus, "")
+		videoEntity.SetKeepMetadata(itemCmd.KeepMetadata)
+		videoTaskEntity := entity.DefaultVideoUploadTaskEntity(
+			cmd.UserUUID, videoEntity.UUID(), vo.VideoUploadTaskStatusInit, "", nil, storagePath)
+		videoTaskEntity.SetBatchUuid(batch.UUID())
+		items = append(items, entity.NewVideoEntity(videoEntity, videoTaskEntity))
+
+		expected, _ := itemCmd.ExpectedChecksum()
+		uploads = append(uploads, vo.NewUploadVideo(cmd.UserUUID, videoEntity.UUID(), videoTaskEntity.UUID(), storagePath, itemCmd.File, expected))
+
+		acceptedResults[i].Accepted = true
+		acceptedResults[i].VideoUUID = videoEntity.UUID()
+		acceptedResults[i].TaskUUID = videoTaskEntity.UUID()
+	}
+	if err := v.videoRepo.CreateBatch(ctx, batch, items); err != nil {
+		logger.Error(fmt.Sprintf("BatchUpload CreateBatch user_uuid: %s, error: %v", cmd.UserUUID, err))
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	logger.Info(fmt.Sprintf("upload batch %s created, files: %d, accepted: %d", batch.UUID(), batch.TotalFiles(), len(items)))
+
+	go v.uploadBatchInBackground(context.WithoutCancel(ctx), uploads)
+
+	return &dto.BatchUploadDto{
+		BatchUUID: batch.UUID(),
+		Total:     batch.TotalFiles(),
+		Accepted:  len(items),
+		Rejected:  batch.RejectedFiles(),
+		Items:     results,
+	}, nil
+}
+
+// uploadBatchInBackground 以有限并发上传批量中的文件
+func (v *videoApp) uploadBatchInBackground(ctx context.Context, uploads []*vo.VideoUploadVO) {
+	sem := make(chan struct{}, batchUploadConcurrency)
+	var wg sync.WaitGroup
+	for _, upload := range uploads {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(upload *vo.VideoUploadVO) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			v.uploadInBackground(ctx, upload)
+		}(upload)
+	}
+	wg.Wait()
+}
+
+// GetUploadBatch 获取批量上传进度，只有上传者可以查看
+func (v *videoApp) GetUploadBatch(ctx context.Context, query *cqe.GetUploadBatchQuery) (*dto.UploadBatchStatusDto, error) {
+	batch, err := v.videoRepo.FindBatch(ctx, query.BatchUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if batch == nil || !batch.IsOwnedBy(query.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrBatchNotFound, nil)
+	}
+	items, err := v.videoRepo.FindBatchItems(ctx, batch.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	progress := entity.NewUploadBatchProgress(batch, items)
+
+	tasks := make([]*dto.BatchTaskDto, 0, len(items))
+	for _, item := range items {
+		processing, err := processingProgress(ctx, v.pipelineService, item.Video())
+		if err != nil {
+			return nil, err
+		}
+		item.SetProcessing(processing)
+		tasks = append(tasks, &dto.BatchTaskDto{
+			TaskUUID:    item.VideoUploadTask().UUID(),
+			VideoUUID:   item.Video().UUID(),
+			Filename:    item.Video().Filename(),
+			Title:       item.Video().Title(),
+			Status:      item.VideoUploadTask().Status().Value(),
+			VideoStatus: item.Video().Status().Value(),
+			ErrorMsg:    item.VideoUploadTask().ErrorMsg(),
+			CompletedAt: item.VideoUploadTask().CompletedAt(),
+			Processing:  toProcessingDto(item.Processing()),
+		})
+	}
+	return &dto.UploadBatchStatusDto{
+		BatchUUID: batch.UUID(),
+		Status:    progress.Status().Value(),
+		Total:     batch.TotalFiles(),
+		Rejected:  batch.RejectedFiles(),
+		Pending:   progress.Pending(),
+		Completed: progress.Completed(),
+		Failed:    progress.Failed(),
+		CreatedAt: batch.CreatedAt(),
+		Tasks:     tasks,
+	}, nil
+}
+
+// rejectBatchItem 记录被拒绝文件的错误码和错误信息
+func rejectBatchItem(result *dto.BatchUploadItemDto, err error) {
+	if no, ok := err.(*errno.Errno); ok {
+		err = errno.NewSimpleBizError(no, nil)
+	}
+	bizErr := errno.AssertBizError(err)
+	result.ErrorCode = bizErr.Code()
+	result.ErrorMsg = bizErr.Message()
+}
+
+// DeleteVideo 删除视频记录后尽力删除当前源文件，删除失败的对象由对账任务处理
+func (v *videoApp) DeleteVideo(ctx context.Context, cmd *cqe.DeleteVideoCommand) error {
+	video, err := v.videoService.GetVideo(ctx, cmd.VideoUUID)
+	if err != nil {
+		return err
+	}
+	if video == nil {
+		return errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.IsOwnedBy(cmd.UserUUID) {
+		return errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	video.Delete()
+	if err := v.videoRepo.Delete(ctx, video); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video.StoragePath() != "" {
+		if err := v.minioService.DeleteVideo(ctx, video.StoragePath()); err != nil {
+			logger.Error(fmt.Sprintf("DeleteVideo remove object %s failed: %v", video.StoragePath(), err))
+		}
+	}
+	return nil
+}
+
+// GetVideo 获取视频详情，包含字幕轨道和章节
+func (v *videoApp) GetVideo(ctx context.Context, query *cqe.GetVideoQuery) (*dto.VideoDetailDto, error) {
+	video, err := v.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	// 未通过审核的视频只对所有者可见
+	if video == nil || !video.IsVisibleTo(query.UserUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+
+	captions, err := v.captionRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	chapters, chapterSource, err := resolveChapters(ctx, v.chapterRepo, video)
+	if err != nil {
+		return nil, err
+	}
+	renditions, err := v.renditionRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	artwork, err := v.artworkRepo.FindLatestSucceeded(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+
+	detail := &dto.VideoDetailDto{
+		VideoUUID:   video.UUID(),
+		UserUUID:    video.UserUuid(),
+		Title:       video.Title(),
+		Description: video.Description(),
+		Tags:        video.Tags(),
+		Filename:    video.Filename(),
+		FileSize:    video.FileSize(),
+		Format:      video.Format(),
+		Status:      video.Status().Value(),
+		Duration:    int64(video.Duration() / time.Second),
+		PosterURL:   posterURL(video),
+		Captions:    toCaptionTrackDtos(ctx, v.minioService, captions),
+		Chapters:    toChaptersDto(chapters, chapterSource, video.Duration()),
+		Renditions:  toPlayableRenditionDtos(video, renditions),
+	}
+	if artwork != nil && artwork.IsCurrentFor(video) && artwork.StoryboardPath() != "" {
+		detail.StoryboardURL = storyboardURL(video)
+	}
+	if video.IsOwnedBy(query.UserUUID) {
+		detail.ReviewStatus = video.ReviewStatus().Value()
+		detail.ReviewReason = video.ReviewReason()
+		detail.KeepMetadata = video.KeepMetadata()
+		detail.StrippedMetadata = video.StrippedMetadata()
+		processing, err := processingProgress(ctx, v.pipelineService, video)
+		if err != nil {
+			return nil, err
+		}
+		detail.Processing = toProcessingDto(processing)
+	}
+	if video.Status().IsPlayable() && video.StoragePath() != "" {
+		detail.URL = playbackURL(ctx, v.minioService, video)
+		for _, rendition := range playableRenditions(video, renditions) {
+			if !rendition.HLS().IsEmpty() {
+				detail.HLSURL = fmt.Sprintf("/api/v1/videos/%s/hls/master.m3u8", video.UUID())
+			}
+			if !rendition.DASH().IsEmpty() && dashEnabled() {
+				detail.DASHURL = fmt.Sprintf("/api/v1/videos/%s/dash/manifest.mpd", video.UUID())
+			}
+		}
+	}
+	return detail, nil
+}
+
+// OpenStream 打开视频源文件或指定档位的转码输出用于播放
+func (v *videoApp) OpenStream(ctx context.Context, query *cqe.StreamVideoQuery) (*dto.VideoStreamDto, error) {
+	video, err := v.videoRepo.FindByUUID(ctx, query.VideoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	// 审核员需要观看待审核的视频
+	if video == nil || !(video.IsVisibleTo(query.UserUUID) || config.IsModerator(query.UserUUID)) {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if !video.Status().IsPlayable() || video.StoragePath() == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	objectName, contentType, err := v.streamObject(ctx, video, query.Rendition)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := v.minioService.StatObject(ctx, objectName)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if contentType == "" {
+		contentType = streamContentType(video, stat)
+	}
+	byteRange, err := vo.ParseByteRange(query.Range, stat.Size())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrRangeInvalid, err)
+	}
+	reader, err := v.minioService.OpenObject(ctx, objectName, byteRange.Offset(), byteRange.Length())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return &dto.VideoStreamDto{
+		Reader:      reader,
+		ContentType: contentType,
+		Offset:      byteRange.Offset(),
+		Length:      byteRange.Length(),
+		Size:        stat.Size(),
+		Partial:     byteRange.Partial(),
+	}, nil
+}
+
+// streamObject 要播放的对象：未指定档位时为源文件，内容类型按源文件格式确定；转码输出均为 MP4
+func (v *videoApp) streamObject(ctx context.Context, video *entity.Video, renditionName string) (string, string, error) {
+	if renditionName == "" {
+		return video.StoragePath(), "", nil
+	}
+	renditions, err := v.renditionRepo.FindByVideoUUID(ctx, video.UUID())
+	if err != nil {
+		return "", "", errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	for _, rendition := range playableRenditions(video, renditions) {
+		if rendition.Profile().Name() == renditionName {
+			return rendition.StoragePath(), "video/mp4", nil
+		}
+	}
+	return "", "", errno.NewSimpleBizError(errno.ErrRenditionNotFound, nil, renditionName)
+}
+
+// playbackURL 视频播放地址：加密对象只能经由服务端解密播放，未加密对象使用预签名地址直连存储
+func playbackURL(ctx context.Context, minioService gateway.MinioService, video *entity.Video) string {
+	stat, err := minioService.StatObject(ctx, video.StoragePath())
+	if err != nil {
+		logger.Error(fmt.Sprintf("stat object video_uuid: %s, error: %v", video.UUID(), err))
+		return ""
+	}
+	if stat.Encrypted() {
+		return fmt.Sprintf("/api/v1/videos/%s/stream", video.UUID())
+	}
+	url, err := minioService.GetVideoURL(ctx, video.StoragePath())
+	if err != nil {
+		logger.Error(fmt.Sprintf("presign url video_uuid: %s, error: %v", video.UUID(), err))
+	}
+	return url
+}
+
+// mediaObjectURL 封面、缩略图和直播分片等衍生对象的地址：加密对象经由 proxyURL 在服务端解密，未加密对象使用预签名地址直连存储
+func mediaObjectURL(ctx context.Context, minioService gateway.MinioService, objectName string, expiry time.Duration,
+	proxyURL string) (string, error) {
+	stat, err := minioService.StatObject(ctx, objectName)
+	if err != nil {
+		return "", err
+	}
+	if stat.Encrypted() {
+		return proxyURL, nil
+	}
+	return minioService.GetObjectURL(ctx, objectName, expiry)
+}
+
+// openMediaObject 打开整个衍生对象，加密对象透明解密，对象不存在时返回 notFound
+func openMediaObject(ctx context.Context, minioService gateway.MinioService, objectName string, notFound *errno.Errno) (*dto.VideoStreamDto, error) {
+	stat, err := minioService.StatObject(ctx, objectName)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(notFound, err)
+	}
+	reader, err := minioService.OpenObject(ctx, objectName, 0, stat.Size())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return &dto.VideoStreamDto{Reader: reader, ContentType: stat.ContentType(), Length: stat.Size(), Size: stat.Size()}, nil
+}
+
+// streamContentType 源文件以 application/octet-stream 存储，播放时按视频格式返回内容类型
+func streamContentType(video *entity.Video, stat vo.ObjectStat) string {
+	if video.Format() != "" {
+		return "video/" + video.Format()
+	}
+	return stat.ContentType()
+}
+
+// ListVideos 公开视频列表
+func (v *videoApp) ListVideos(ctx context.Context, query *cqe.ListVideosQuery) (*dto.VideoListDto, error) {
+	page := vo.NewPage(query.Page, query.PageSize)
+	videos, total, err := v.videoRepo.FindPublic(ctx, page)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toVideoListDto(videos, total, page, false), nil
+}
+
+// ListUserVideos 用户自己的视频列表
+func (v *videoApp) ListUserVideos(ctx context.Context, query *cqe.ListVideosQuery) (*dto.VideoListDto, error) {
+	if len(query.UserUUID) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	page := vo.NewPage(query.Page, query.PageSize)
+	videos, total, err := v.videoRepo.FindByUserUUID(ctx, query.UserUUID, page)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toVideoListDto(videos, total, page, true), nil
+}
+
+// GetStatusHistory 获取视频的状态迁移历史
+func (v *videoApp) GetStatusHistory(ctx context.Context, operatorUUID, videoUUID string) ([]*dto.StatusTransitionDto, error) {
+	if !config.IsAdmin(operatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	transitions, err := v.videoService.StatusHistory(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*dto.StatusTransitionDto, 0, len(transitions))
+	for _, transition := range transitions {
+		dtos = append(dtos, &dto.StatusTransitionDto{
+			TransitionUUID: transition.UUID(),
+			VideoUUID:      transition.VideoUuid(),
+			From:           transition.From().Value(),
+			To:             transition.To().Value(),
+			Cause:          transition.Cause(),
+			CreatedAt:      transition.CreatedAt(),
+		})
+	}
+	return dtos, nil
+}
+
+// VerifyChecksum 重新校验视频源文件的校验和
+func (v *videoApp) VerifyChecksum(ctx context.Context, operatorUUID, videoUUID string) (*dto.ChecksumVerificationDto, error) {
+	if !config.IsAdmin(operatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	verification, err := v.videoService.VerifyChecksum(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.ChecksumVerificationDto{
+		VideoUUID:      verification.VideoUuid(),
+		ObjectName:     verification.ObjectName(),
+		Matches:        verification.Matches(),
+		RecordedMD5:    verification.Recorded().MD5(),
+		RecordedSHA256: verification.Recorded().SHA256(),
+		ActualMD5:      verification.Actual().MD5(),
+		ActualSHA256:   verification.Actual().SHA256(),
+		RecordedETag:   verification.RecordedETag(),
+		StoredETag:     verification.StoredETag(),
+		VerifiedAt:     verification.VerifiedAt(),
+	}
+	if mismatch := verification.Mismatch(); mismatch != nil {
+		result.Mismatch = mismatch.Error()
+		logger.Error(fmt.Sprintf("VerifyChecksum video_uuid: %s, object: %s, %v", verification.VideoUuid(), verification.ObjectName(), mismatch))
+	}
+	return result, nil
+}
+
+// uploadBizError 校验和不匹配时返回 ErrChecksumMismatch，其他上传错误原样返回
+func uploadBizError(err error) error {
+	var mismatchErr *vo.ChecksumMismatchError
+	if errors.As(err, &mismatchErr) {
+		return errno.NewSimpleBizError(errno.ErrChecksumMismatch, err, mismatchErr.Error())
+	}
+	return err
+}
+
+func toVideoListDto(videos []*entity.Video, total int64, page *vo.Page, withReview bool) *dto.VideoListDto {
+	summaries := make([]*dto.VideoSummaryDto, 0, len(videos))
+	for _, video := range videos {
+		summaries = append(summaries, toVideoSummaryDto(video, withReview))
+	}
+	return &dto.VideoListDto{
+		Videos:   summaries,
+		Total:    total,
+		Page:     page.Page(),
+		PageSize: page.PageSize(),
+	}
+}
@@ -0,0 +1,52 @@
+package cqe
+
+import (
+	"go-video/pkg/errno"
+	"mime/multipart"
+)
+
+// SelectPosterCommand 选择候选帧作为封面命令
+type SelectPosterCommand struct {
+	UserUUID  string `json:"-"`
+	VideoUUID string `json:"-"`
+	Index     *int   `json:"index"` // 候选帧序号，从0开始
+}
+
+// Validate 校验选择封面参数
+func (c *SelectPosterCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 || c.Index == nil {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if *c.Index < 0 {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "index")
+	}
+	return nil
+}
+
+// UploadCoverCommand 上传自定义封面命令
+type UploadCoverCommand struct {
+	UserUUID  string                `json:"-"`
+	VideoUUID string                `json:"-"`
+	File      *multipart.FileHeader `json:"-"` // JPEG、PNG或GIF图片
+}
+
+// Validate 校验上传封面参数，大小上限和图片内容在应用层校验
+func (c *UploadCoverCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 || c.File == nil {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
+
+// ArtworkQuery 获取封面或缩略图轨道查询
+type ArtworkQuery struct {
+	VideoUUID  string `json:"-"`
+	UserUUID   string `json:"-"` // 当前登录用户UUID，未登录为空
+	ShareToken string `form:"share_token"`
+}
+
+// ArtworkFileQuery 获取加密存储的封面、候选帧或雪碧图查询
+type ArtworkFileQuery struct {
+	ArtworkQuery
+	File string `json:"-"` // 生成目录下的文件名，poster 表示当前封面
+}
@@ -0,0 +1,76 @@
+package cqe
+
+import (
+	"encoding/json"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"mime/multipart"
+)
+
+// BatchUploadManifestItem 清单中单个文件的元数据
+type BatchUploadManifestItem struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	Format         string `json:"format"`
+	ContentMD5     string `json:"content_md5"`     // 可选，base64或十六进制
+	ChecksumSHA256 string `json:"checksum_sha256"` // 可选，十六进制或base64
+	KeepMetadata   bool   `json:"keep_metadata"`   // 可选，保留源文件中的位置、设备等元数据
+}
+
+// BatchUploadCommand 批量上传视频命令：多个file部分加一个JSON清单，清单以文件名为键
+type BatchUploadCommand struct {
+	UserUUID string                             `json:"user_uuid"`
+	Files    []*multipart.FileHeader            `json:"-"`
+	Manifest map[string]BatchUploadManifestItem `json:"manifest"`
+}
+
+// ParseBatchManifest 解析清单，形如 {"a.mp4": {"title": "...", "description": "..."}}
+func ParseBatchManifest(data []byte) (map[string]BatchUploadManifestItem, error) {
+	manifest := map[string]BatchUploadManifestItem{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Validate 校验批量参数，单个文件的校验由 Item 生成的 UploadVideoCommand 完成
+func (c *BatchUploadCommand) Validate() error {
+	if len(c.UserUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(c.Files) == 0 {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "file")
+	}
+	if len(c.Files) > vo.MaxBatchUploadFiles {
+		return errno.NewSimpleBizError(errno.ErrBatchTooLarge, nil, vo.MaxBatchUploadFiles)
+	}
+	if len(c.Manifest) == 0 {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "manifest")
+	}
+	return nil
+}
+
+// Item 根据清单生成单个文件的上传命令，清单中没有该文件时返回false
+func (c *BatchUploadCommand) Item(file *multipart.FileHeader) (*UploadVideoCommand, bool) {
+	item, ok := c.Manifest[file.Filename]
+	if !ok {
+		return nil, false
+	}
+	return &UploadVideoCommand{
+		UserUUID:       c.UserUUID,
+		Title:          item.Title,
+		Description:    item.Description,
+		File:           file,
+		Format:         item.Format,
+		FileSize:       file.Size,
+		ContentMD5:     item.ContentMD5,
+		ChecksumSHA256: item.ChecksumSHA256,
+		KeepMetadata:   item.KeepMetadata,
+	}, true
+}
+
+// GetUploadBatchQuery 查询批量上传进度
+type GetUploadBatchQuery struct {
+	UserUUID  string `json:"-"`
+	BatchUUID string `json:"-"`
+}
@@ -0,0 +1,61 @@
+package cqe
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"go-video/pkg/subtitle"
+	"mime/multipart"
+)
+
+// maxCaptionFileSize 字幕文件大小上限（2MB）
+const maxCaptionFileSize = 2 * 1024 * 1024
+
+// UploadCaptionCommand 上传字幕命令
+type UploadCaptionCommand struct {
+	UserUUID  string                `json:"user_uuid"`  // 操作用户UUID
+	VideoUUID string                `json:"video_uuid"` // 视频UUID
+	Language  string                `json:"language"`   // 语言标签，例如 zh-CN
+	Label     string                `json:"label"`      // 展示名称，例如 简体中文
+	IsDefault bool                  `json:"is_default"` // 是否默认字幕
+	File      *multipart.FileHeader `json:"-"`          // SRT或WebVTT文件
+}
+
+// Validate 校验上传字幕参数
+func (c *UploadCaptionCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if !vo.IsValidCaptionLanguage(c.Language) {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "language")
+	}
+	if len(c.Label) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(c.Label) > 100 {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	if c.File == nil {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.File.Size > maxCaptionFileSize {
+		return errno.NewSimpleBizError(errno.ErrCaptionInvalid, nil, "file too large")
+	}
+	if _, ok := subtitle.DetectFormat(c.File.Filename); !ok {
+		return errno.NewSimpleBizError(errno.ErrCaptionInvalid, nil, "only .srt and .vtt files are supported")
+	}
+	return nil
+}
+
+// DeleteCaptionCommand 删除字幕命令
+type DeleteCaptionCommand struct {
+	UserUUID    string `json:"user_uuid"`
+	VideoUUID   string `json:"video_uuid"`
+	CaptionUUID string `json:"caption_uuid"`
+}
+
+// CaptionQuery 获取字幕轨道列表查询
+type CaptionQuery struct {
+	VideoUUID  string `json:"-"`
+	UserUUID   string `json:"-"` // 当前登录用户UUID，未登录为空
+	ShareToken string `form:"share_token"`
+}
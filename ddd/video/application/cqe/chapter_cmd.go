@@ -0,0 +1,46 @@
+package cqe
+
+import (
+	"fmt"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+)
+
+// ChapterItem 章节参数
+type ChapterItem struct {
+	Start string `json:"start"` // 开始时间 HH:MM:SS 或 MM:SS
+	Title string `json:"title"` // 章节标题
+}
+
+// SetChaptersCommand 设置视频章节命令，传入空列表表示清除显式章节、回退到从描述中解析
+type SetChaptersCommand struct {
+	UserUUID  string        `json:"-"`
+	VideoUUID string        `json:"-"`
+	Chapters  []ChapterItem `json:"chapters"`
+}
+
+// Validate 校验命令参数并转换为章节值对象，时长相关的校验在应用层完成
+func (c *SetChaptersCommand) Validate() ([]vo.Chapter, error) {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	chapters := make([]vo.Chapter, 0, len(c.Chapters))
+	for i, item := range c.Chapters {
+		start, err := vo.ParseChapterTimestamp(item.Start)
+		if err != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrChapterInvalid, err, fmt.Sprintf("chapter %d: %v", i+1, err))
+		}
+		chapters = append(chapters, vo.NewChapter(start, item.Title))
+	}
+	if err := vo.ValidateChapters(chapters, 0); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrChapterInvalid, err, err.Error())
+	}
+	return chapters, nil
+}
+
+// ChapterQuery 获取章节查询
+type ChapterQuery struct {
+	VideoUUID  string `json:"-"`
+	UserUUID   string `json:"-"` // 当前登录用户UUID，未登录为空
+	ShareToken string `form:"share_token"`
+}
@@ -0,0 +1,63 @@
+package cqe
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"net/url"
+	"path"
+)
+
+// ImportVideoCommand 从远程URL导入视频命令
+type ImportVideoCommand struct {
+	UserUUID    string `json:"-"`
+	URL         string `json:"url"`         // 远程文件地址
+	Title       string `json:"title"`       // 视频标题
+	Description string `json:"description"` // 视频描述
+	Format      string `json:"format"`      // 视频格式
+
+	ContentMD5     string `json:"content_md5"`     // 期望的MD5，base64或十六进制，可选
+	ChecksumSHA256 string `json:"checksum_sha256"` // 期望的SHA-256，十六进制或base64，可选
+
+	KeepMetadata bool `json:"keep_metadata"` // 保留源文件中的位置、设备等元数据，默认清除
+}
+
+// Validate 校验导入参数，URL是否允许访问由远程下载的访问策略校验
+func (c *ImportVideoCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.URL) == 0 || len(c.Title) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(c.Title) > 100 || len(c.Description) > 500 || len(c.URL) > 2048 {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "url")
+	}
+	if _, err := c.ExpectedChecksum(); err != nil {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "checksum")
+	}
+	return nil
+}
+
+// Filename 取URL路径的最后一段作为文件名
+func (c *ImportVideoCommand) Filename() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return ""
+	}
+	filename := path.Base(u.Path)
+	if filename == "." || filename == "/" {
+		return ""
+	}
+	return filename
+}
+
+// ExpectedChecksum 解析期望的校验和，未提供时返回空校验和
+func (c *ImportVideoCommand) ExpectedChecksum() (vo.Checksum, error) {
+	return vo.ParseExpectedChecksum(c.ContentMD5, c.ChecksumSHA256)
+}
+
+// GetUploadTaskQuery 查询上传任务进度
+type GetUploadTaskQuery struct {
+	UserUUID string `json:"-"`
+	TaskUUID string `json:"-"`
+}
@@ -0,0 +1,47 @@
+package cqe
+
+import (
+	"go-video/pkg/errno"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportLibraryFileCommand 从本地文件导入历史视频命令，用于迁移已有视频库
+type ImportLibraryFileCommand struct {
+	Path          string     `json:"path"`        // 本地文件路径
+	OwnerUsername string     `json:"owner"`       // 所有者用户名
+	Title         string     `json:"title"`       // 视频标题
+	Description   string     `json:"description"` // 视频描述
+	Tags          []string   `json:"tags"`        // 标签
+	CreatedAt     *time.Time `json:"created_at"`  // 原始创建时间，为空时使用导入时间
+}
+
+// Validate 校验导入参数，本地文件不受HTTP上传的大小限制
+func (c *ImportLibraryFileCommand) Validate() error {
+	if len(c.Path) == 0 || len(c.OwnerUsername) == 0 || len(c.Title) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(c.Title) > 100 || len(c.Description) > 500 || len(strings.Join(c.Tags, ",")) > 500 {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	for _, tag := range c.Tags {
+		if strings.Contains(tag, ",") {
+			return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "tags")
+		}
+	}
+	if !hasVideoExtension(strings.ToLower(c.Path)) {
+		return errno.NewSimpleBizError(errno.ErrVideoFormatInvalid, nil)
+	}
+	return nil
+}
+
+// Filename 文件名
+func (c *ImportLibraryFileCommand) Filename() string {
+	return filepath.Base(c.Path)
+}
+
+// Format 按扩展名推断的视频格式
+func (c *ImportLibraryFileCommand) Format() string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(c.Path)), ".")
+}
@@ -0,0 +1,66 @@
+package cqe
+
+import (
+	"go-video/pkg/errno"
+	"io"
+)
+
+// CreateLiveStreamCommand 创建直播命令
+type CreateLiveStreamCommand struct {
+	UserUUID    string `json:"-"`
+	Title       string `json:"title"`       // 直播标题，转换后的视频使用同一标题
+	Description string `json:"description"` // 直播描述
+}
+
+// Validate 校验创建直播参数
+func (c *CreateLiveStreamCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.Title) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(c.Title) > 100 || len(c.Description) > 500 {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	return nil
+}
+
+// LiveStreamCommand 主播操作自己的直播（查看、结束）
+type LiveStreamCommand struct {
+	UserUUID   string `json:"-"`
+	StreamUUID string `json:"-"`
+}
+
+// Validate 校验参数
+func (c *LiveStreamCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.StreamUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
+
+// LiveIngestCommand 编码器推送分片或播放列表命令，以推流密钥认证
+type LiveIngestCommand struct {
+	StreamKey string    `json:"-"`
+	Filename  string    `json:"-"` // 以 .ts 结尾为分片，以 .m3u8 结尾为媒体播放列表
+	Body      io.Reader `json:"-"`
+}
+
+// Validate 校验推流参数，文件名和内容在应用层校验
+func (c *LiveIngestCommand) Validate() error {
+	if len(c.StreamKey) == 0 || len(c.Filename) == 0 || c.Body == nil {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
+
+// LivePlaylistQuery 观众获取直播播放列表查询
+type LivePlaylistQuery struct {
+	StreamUUID string `json:"-"`
+	UserUUID   string `json:"-"`
+}
+
+// LiveSegmentQuery 观众获取加密存储的直播分片查询
+type LiveSegmentQuery struct {
+	StreamUUID string `json:"-"`
+	UserUUID   string `json:"-"`
+	Segment    string `json:"-"` // 播放列表中的分片文件名
+}
@@ -0,0 +1,18 @@
+package cqe
+
+import "go-video/pkg/errno"
+
+// RerunPipelineStepCommand 运维人员重新执行失败的流水线步骤命令
+type RerunPipelineStepCommand struct {
+	OperatorUUID string `json:"-"`
+	VideoUUID    string `json:"-"`
+	Step         string `json:"-"`
+}
+
+// Validate 校验重新执行命令参数
+func (c *RerunPipelineStepCommand) Validate() error {
+	if len(c.OperatorUUID) == 0 || len(c.VideoUUID) == 0 || len(c.Step) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package cqe
+
+// ReconcileCommand 手动触发对账命令
+type ReconcileCommand struct {
+	OperatorUUID string `json:"-"`
+	DryRun       bool   `form:"dry_run,default=true"` // 只生成报告，不删除对象也不修改状态，默认开启
+}
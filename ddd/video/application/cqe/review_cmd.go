@@ -0,0 +1,37 @@
+package cqe
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+)
+
+// maxReviewReasonLength 审核意见最大长度
+const maxReviewReasonLength = 500
+
+// ClaimReviewCommand 认领审核任务命令
+type ClaimReviewCommand struct {
+	ModeratorUUID string `json:"-"`
+	VideoUUID     string `json:"-"`
+}
+
+// ReviewDecisionCommand 审核结论命令
+type ReviewDecisionCommand struct {
+	ModeratorUUID string            `json:"-"`
+	VideoUUID     string            `json:"-"`
+	Decision      vo.ReviewDecision `json:"-"`
+	Reason        string            `json:"reason"` // 审核意见，拒绝时必填，会展示给上传者
+}
+
+// Validate 校验审核结论参数
+func (c *ReviewDecisionCommand) Validate() error {
+	if len(c.ModeratorUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.Decision == vo.ReviewDecisionReject && len(c.Reason) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len([]rune(c.Reason)) > maxReviewReasonLength {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	return nil
+}
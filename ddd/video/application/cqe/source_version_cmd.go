@@ -0,0 +1,60 @@
+package cqe
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/errno"
+	"mime/multipart"
+)
+
+// ReplaceSourceCommand 替换视频源文件命令
+type ReplaceSourceCommand struct {
+	UserUUID  string                `json:"user_uuid"`  // 操作用户UUID
+	VideoUUID string                `json:"video_uuid"` // 视频UUID
+	File      *multipart.FileHeader `json:"-"`          // 新的视频文件
+	Format    string                `json:"format"`     // 视频格式
+
+	KeepMetadata bool `json:"keep_metadata"` // 保留源文件中的位置、设备等元数据，默认清除
+
+	ContentMD5     string `json:"content_md5"`     // 客户端提供的MD5，base64或十六进制，可选
+	ChecksumSHA256 string `json:"checksum_sha256"` // 客户端提供的SHA-256，十六进制或base64，可选
+}
+
+// Validate 校验替换源文件参数
+func (c *ReplaceSourceCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.File == nil {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if err := validateVideoFile(c.File); err != nil {
+		return err
+	}
+	if _, err := c.ExpectedChecksum(); err != nil {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "checksum")
+	}
+	return nil
+}
+
+// ExpectedChecksum 解析客户端提供的校验和，未提供时返回空校验和
+func (c *ReplaceSourceCommand) ExpectedChecksum() (vo.Checksum, error) {
+	return vo.ParseExpectedChecksum(c.ContentMD5, c.ChecksumSHA256)
+}
+
+// RollbackSourceCommand 回滚视频源文件到历史版本命令
+type RollbackSourceCommand struct {
+	UserUUID  string `json:"-"`
+	VideoUUID string `json:"-"`
+	Version   int    `json:"version"` // 目标版本号
+}
+
+// Validate 校验回滚参数
+func (c *RollbackSourceCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.Version < 1 {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "version")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cqe
+
+import "go-video/pkg/errno"
+
+// TranscodeCommand 视频所有者重新转码或取消转码命令
+type TranscodeCommand struct {
+	UserUUID  string `json:"-"`
+	VideoUUID string `json:"-"`
+}
+
+// Validate 校验转码命令参数
+func (c *TranscodeCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
+
+// ShareTokenCommand 视频所有者签发分享令牌命令
+type ShareTokenCommand struct {
+	UserUUID  string `json:"-"`
+	VideoUUID string `json:"-"`
+}
+
+// Validate 校验签发分享令牌命令参数
+func (c *ShareTokenCommand) Validate() error {
+	if len(c.UserUUID) == 0 || len(c.VideoUUID) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	return nil
+}
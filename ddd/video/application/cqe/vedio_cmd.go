@@ -1,6 +1,7 @@
 package cqe
 
 import (
+	"go-video/ddd/video/domain/vo"
 	"go-video/pkg/errno"
 	"mime/multipart"
 )
@@ -15,6 +16,11 @@ type UploadVideoCommand struct {
 	File        *multipart.FileHeader `json:"-"`           // 视频文件
 	Format      string                `json:"format"`      // 视频格式
 	FileSize    int64                 `json:"file_size"`   // 文件大小(字节)
+
+	ContentMD5     string `json:"content_md5"`     // 客户端提供的MD5，base64或十六进制，可选
+	ChecksumSHA256 string `json:"checksum_sha256"` // 客户端提供的SHA-256，十六进制或base64，可选
+
+	KeepMetadata bool `json:"keep_metadata"` // 保留源文件中的位置、设备等元数据，默认清除
 }
 
 // Validate 实现Command接口的校验方法
@@ -42,22 +48,41 @@ func (c *UploadVideoCommand) Validate() error {
 		return errno.ErrMissingParam
 	}
 
+	// 验证文件大小和格式
+	if err := validateVideoFile(c.File); err != nil {
+		return err
+	}
+
+	// 验证校验和格式
+	if _, err := c.ExpectedChecksum(); err != nil {
+		return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "checksum")
+	}
+
+	return nil
+}
+
+// ExpectedChecksum 解析客户端提供的校验和，未提供时返回空校验和
+func (c *UploadVideoCommand) ExpectedChecksum() (vo.Checksum, error) {
+	return vo.ParseExpectedChecksum(c.ContentMD5, c.ChecksumSHA256)
+}
+
+// validateVideoFile 校验视频文件大小和格式
+func validateVideoFile(file *multipart.FileHeader) error {
 	// 验证文件大小（限制为100MB）
 	const maxFileSize = 100 * 1024 * 1024 // 100MB
-	if c.File.Size > maxFileSize {
+	if file.Size > maxFileSize {
 		return errno.ErrVideoTooLarge
 	}
 
 	// 验证视频文件格式
-	if !c.isValidVideoFormat() {
+	if !isValidVideoFormat(file) {
 		return errno.ErrVideoFormatInvalid
 	}
-
 	return nil
 }
 
 // isValidVideoFormat 检查是否为有效的视频格式
-func (c *UploadVideoCommand) isValidVideoFormat() bool {
+func isValidVideoFormat(file *multipart.FileHeader) bool {
 	validFormats := []string{
 		"video/mp4",
 		"video/avi",
@@ -69,7 +94,7 @@ func (c *UploadVideoCommand) isValidVideoFormat() bool {
 	}
 
 	// 检查MIME类型
-	contentType := c.File.Header.Get("Content-Type")
+	contentType := file.Header.Get("Content-Type")
 	for _, format := range validFormats {
 		if contentType == format {
 			return true
@@ -77,7 +102,11 @@ func (c *UploadVideoCommand) isValidVideoFormat() bool {
 	}
 
 	// 检查文件扩展名
-	filename := c.File.Filename
+	return hasVideoExtension(file.Filename)
+}
+
+// hasVideoExtension 检查文件扩展名是否为支持的视频格式
+func hasVideoExtension(filename string) bool {
 	if len(filename) == 0 {
 		return false
 	}
@@ -91,3 +120,9 @@ func (c *UploadVideoCommand) isValidVideoFormat() bool {
 
 	return false
 }
+
+// DeleteVideoCommand 删除视频命令
+type DeleteVideoCommand struct {
+	UserUUID  string `json:"-"`
+	VideoUUID string `json:"-"`
+}
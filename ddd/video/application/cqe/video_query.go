@@ -0,0 +1,55 @@
+package cqe
+
+// GetVideoQuery 获取视频详情查询
+type GetVideoQuery struct {
+	VideoUUID string `uri:"id" binding:"required"`
+	UserUUID  string `json:"-"` // 当前登录用户UUID，未登录为空
+}
+
+// ListVideosQuery 视频列表分页查询
+type ListVideosQuery struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	UserUUID string `form:"-"` // 列出指定用户的视频时使用
+}
+
+// PlaylistQuery 获取HLS播放列表查询
+type PlaylistQuery struct {
+	VideoUUID  string `json:"-"`
+	UserUUID   string `json:"-"` // 当前登录用户UUID，未登录为空
+	Rendition  string `json:"-"` // 档位名称，获取主播放列表时为空
+	ShareToken string `form:"share_token"`
+}
+
+// ContentKeyQuery 获取HLS内容密钥查询，需要登录或持有分享令牌
+type ContentKeyQuery struct {
+	VideoUUID  string `json:"-"`
+	KeyUUID    string `json:"-"`
+	UserUUID   string `json:"-"` // 当前登录用户UUID，未登录为空
+	ShareToken string `form:"share_token"`
+	ClientIP   string `json:"-"`
+	UserAgent  string `json:"-"`
+}
+
+// DASHManifestQuery 获取DASH清单查询
+type DASHManifestQuery struct {
+	VideoUUID string `json:"-"`
+	UserUUID  string `json:"-"` // 当前登录用户UUID，未登录为空
+}
+
+// DASHSegmentQuery 获取DASH分片查询，分片地址由清单签发，不需要登录
+type DASHSegmentQuery struct {
+	VideoUUID     string `json:"-"`
+	RenditionUUID string `json:"-"`
+	Segment       string `json:"-"` // 分片文件名
+	Expires       string `form:"expires"`
+	Signature     string `form:"signature"`
+}
+
+// StreamVideoQuery 播放视频源文件查询
+type StreamVideoQuery struct {
+	VideoUUID string `json:"-"`
+	UserUUID  string `json:"-"` // 当前登录用户UUID，未登录为空
+	Range     string `json:"-"` // HTTP Range 请求头
+	Rendition string `json:"-"` // 转码档位名称，例如 720p，为空时播放源文件
+}
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"io"
+	"time"
+)
+
 type UploadVideoDto struct {
 	VideoUUID string `json:"video_uuid"`
 }
@@ -8,3 +13,402 @@ type VideoSyncVideoDto struct {
 	VideoUUID string `json:"video_uuid"`
 	TaskUUID  string `json:"task_uuid"`
 }
+
+// VideoDetailDto 视频详情
+type VideoDetailDto struct {
+	VideoUUID     string                  `json:"video_uuid"`
+	UserUUID      string                  `json:"user_uuid"`
+	Title         string                  `json:"title"`
+	Description   string                  `json:"description"`
+	Tags          []string                `json:"tags,omitempty"`
+	Filename      string                  `json:"filename"`
+	FileSize      int64                   `json:"file_size"`
+	Format        string                  `json:"format"`
+	Status        string                  `json:"status"`
+	URL           string                  `json:"url,omitempty"`
+	HLSURL        string                  `json:"hls_url,omitempty"`        // HLS主播放列表地址，没有打包HLS时为空
+	DASHURL       string                  `json:"dash_url,omitempty"`       // DASH清单地址，没有打包DASH时为空
+	PosterURL     string                  `json:"poster_url,omitempty"`     // 封面地址，还没有封面时为空
+	StoryboardURL string                  `json:"storyboard_url,omitempty"` // 拖动预览缩略图轨道（WebVTT）地址，没有缩略图时为空
+	Duration      int64                   `json:"duration"`                 // 视频时长（秒），0表示未知
+	Captions      []*CaptionTrackDto      `json:"captions"`
+	Chapters      *ChaptersDto            `json:"chapters"`
+	Renditions    []*PlayableRenditionDto `json:"renditions"` // 已转码完成的档位，经由 stream?rendition= 播放
+
+	// 审核信息和源文件元数据仅对视频所有者返回
+	ReviewStatus     string         `json:"review_status,omitempty"`
+	ReviewReason     string         `json:"review_reason,omitempty"`
+	KeepMetadata     bool           `json:"keep_metadata,omitempty"`     // 上传时选择保留源文件元数据
+	StrippedMetadata []string       `json:"stripped_metadata,omitempty"` // 从源文件中清除的元数据，例如 moov/udta/©xyz
+	Processing       *ProcessingDto `json:"processing,omitempty"`        // 当前源文件的处理进度，没有处理流水线时为空
+}
+
+// VideoSummaryDto 视频列表条目
+type VideoSummaryDto struct {
+	VideoUUID    string `json:"video_uuid"`
+	UserUUID     string `json:"user_uuid"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Format       string `json:"format"`
+	FileSize     int64  `json:"file_size"`
+	Duration     int64  `json:"duration"`
+	Status       string `json:"status"`
+	PosterURL    string `json:"poster_url,omitempty"` // 封面地址，还没有封面时为空
+	ReviewStatus string `json:"review_status,omitempty"`
+	ReviewReason string `json:"review_reason,omitempty"`
+}
+
+// VideoListDto 视频列表
+type VideoListDto struct {
+	Videos   []*VideoSummaryDto `json:"videos"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// CaptionTrackDto 字幕轨道
+type CaptionTrackDto struct {
+	CaptionUUID string `json:"caption_uuid"`
+	Language    string `json:"language"`
+	Label       string `json:"label"`
+	IsDefault   bool   `json:"is_default"`
+	URL         string `json:"url"`
+}
+
+// ChaptersDto 视频章节列表
+type ChaptersDto struct {
+	Source   string        `json:"source"` // 章节来源：manual/description/none
+	Chapters []*ChapterDto `json:"chapters"`
+}
+
+// ChapterDto 视频章节
+type ChapterDto struct {
+	Start   string `json:"start"`    // 开始时间 HH:MM:SS
+	End     string `json:"end"`      // 结束时间 HH:MM:SS
+	StartMs int64  `json:"start_ms"` // 开始时间（毫秒）
+	EndMs   int64  `json:"end_ms"`   // 结束时间（毫秒）
+	Title   string `json:"title"`
+}
+
+// ReviewQueueItemDto 审核队列条目
+type ReviewQueueItemDto struct {
+	Video     *VideoSummaryDto `json:"video"`
+	URL       string           `json:"url"` // 供审核员观看的地址
+	ClaimedBy string           `json:"claimed_by,omitempty"`
+	ClaimedAt *time.Time       `json:"claimed_at,omitempty"`
+}
+
+// ReviewQueueDto 审核队列
+type ReviewQueueDto struct {
+	Items    []*ReviewQueueItemDto `json:"items"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// ReviewLogDto 审核记录
+type ReviewLogDto struct {
+	LogUUID       string     `json:"log_uuid"`
+	VideoUUID     string     `json:"video_uuid"`
+	ModeratorUUID string     `json:"moderator_uuid"`
+	Decision      string     `json:"decision"`
+	Reason        string     `json:"reason"`
+	CreatedAt     *time.Time `json:"created_at"`
+}
+
+// StatusTransitionDto 视频状态迁移记录
+type StatusTransitionDto struct {
+	TransitionUUID string     `json:"transition_uuid"`
+	VideoUUID      string     `json:"video_uuid"`
+	From           string     `json:"from"`
+	To             string     `json:"to"`
+	Cause          string     `json:"cause"`
+	CreatedAt      *time.Time `json:"created_at"`
+}
+
+// ChecksumVerificationDto 源文件校验和复核结果
+type ChecksumVerificationDto struct {
+	VideoUUID      string    `json:"video_uuid"`
+	ObjectName     string    `json:"object_name"`
+	Matches        bool      `json:"matches"`
+	Mismatch       string    `json:"mismatch,omitempty"`
+	RecordedMD5    string    `json:"recorded_md5,omitempty"`
+	RecordedSHA256 string    `json:"recorded_sha256,omitempty"`
+	ActualMD5      string    `json:"actual_md5"`
+	ActualSHA256   string    `json:"actual_sha256"`
+	RecordedETag   string    `json:"recorded_etag"`
+	StoredETag     string    `json:"stored_etag"`
+	VerifiedAt     time.Time `json:"verified_at"`
+}
+
+// LibraryImportDto 本地视频文件导入结果
+type LibraryImportDto struct {
+	VideoUUID string `json:"video_uuid"`
+	Duplicate bool   `json:"duplicate"` // 所有者已有相同内容的视频，未重复导入
+	SHA256    string `json:"sha256"`
+	FileSize  int64  `json:"file_size"`
+}
+
+// UploadTaskDto 上传任务进度
+type UploadTaskDto struct {
+	TaskUUID      string     `json:"task_uuid"`
+	VideoUUID     string     `json:"video_uuid"`
+	Status        string     `json:"status"`
+	SourceURL     string     `json:"source_url,omitempty"` // 远程导入的源地址
+	BytesReceived int64      `json:"bytes_received"`
+	BytesTotal    int64      `json:"bytes_total"` // -1表示远程服务端未提供长度
+	ErrorMsg      string     `json:"error_msg,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// BatchUploadDto 批量上传结果，Items与请求中的文件一一对应
+type BatchUploadDto struct {
+	BatchUUID string                `json:"batch_uuid"`
+	Total     int                   `json:"total"`
+	Accepted  int                   `json:"accepted"`
+	Rejected  int                   `json:"rejected"`
+	Items     []*BatchUploadItemDto `json:"items"`
+}
+
+// BatchUploadItemDto 批量上传中单个文件的结果
+type BatchUploadItemDto struct {
+	Filename  string `json:"filename"`
+	Accepted  bool   `json:"accepted"`
+	VideoUUID string `json:"video_uuid,omitempty"`
+	TaskUUID  string `json:"task_uuid,omitempty"`
+	ErrorCode int    `json:"error_code,omitempty"`
+	ErrorMsg  string `json:"error_msg,omitempty"`
+}
+
+// UploadBatchStatusDto 批量上传进度
+type UploadBatchStatusDto struct {
+	BatchUUID string          `json:"batch_uuid"`
+	Status    string          `json:"status"` // processing/completed/partial/failed
+	Total     int             `json:"total"`
+	Rejected  int             `json:"rejected"`
+	Pending   int             `json:"pending"`
+	Completed int             `json:"completed"`
+	Failed    int             `json:"failed"`
+	CreatedAt *time.Time      `json:"created_at"`
+	Tasks     []*BatchTaskDto `json:"tasks"`
+}
+
+// BatchTaskDto 批量上传中的单个上传任务
+type BatchTaskDto struct {
+	TaskUUID    string         `json:"task_uuid"`
+	VideoUUID   string         `json:"video_uuid"`
+	Filename    string         `json:"filename"`
+	Title       string         `json:"title"`
+	Status      string         `json:"status"`
+	VideoStatus string         `json:"video_status"`
+	ErrorMsg    string         `json:"error_msg,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	Processing  *ProcessingDto `json:"processing,omitempty"` // 上传完成后的处理进度
+}
+
+// SourceVersionDto 视频源文件版本
+type SourceVersionDto struct {
+	VersionUUID    string     `json:"version_uuid"`
+	Version        int        `json:"version"`
+	Status         string     `json:"status"` // uploading/active/superseded/failed/purged
+	Filename       string     `json:"filename"`
+	FileSize       int64      `json:"file_size"`
+	Format         string     `json:"format"`
+	ChecksumSHA256 string     `json:"checksum_sha256,omitempty"`
+	KeepMetadata   bool       `json:"keep_metadata,omitempty"` // 上传时选择保留源文件元数据
+	ErrorMsg       string     `json:"error_msg,omitempty"`
+	Restorable     bool       `json:"restorable"` // 是否可以回滚到该版本
+	ActivatedAt    *time.Time `json:"activated_at,omitempty"`
+	RetainUntil    *time.Time `json:"retain_until,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+}
+
+// ReconcileReportDto 对账报告
+type ReconcileReportDto struct {
+	DryRun         bool                `json:"dry_run"`
+	GracePeriod    string              `json:"grace_period"`
+	StartedAt      time.Time           `json:"started_at"`
+	FinishedAt     time.Time           `json:"finished_at"`
+	ScannedObjects int                 `json:"scanned_objects"`
+	ScannedVideos  int                 `json:"scanned_videos"`
+	Orphans        []*OrphanObjectDto  `json:"orphans"`
+	Missing        []*MissingObjectDto `json:"missing"`
+}
+
+// OrphanObjectDto 没有被任何视频引用的对象
+type OrphanObjectDto struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Action       string    `json:"action"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// MissingObjectDto 源文件缺失的视频
+type MissingObjectDto struct {
+	VideoUUID   string `json:"video_uuid"`
+	StoragePath string `json:"storage_path"`
+	Status      string `json:"status"`
+	Action      string `json:"action"`
+	Error       string `json:"error,omitempty"`
+}
+
+// VideoStreamDto 视频源文件或衍生对象的明文内容，调用方负责关闭Reader
+type VideoStreamDto struct {
+	Reader      io.ReadCloser
+	ContentType string
+	Offset      int64
+	Length      int64
+	Size        int64
+	Partial     bool
+}
+
+// RewrapReportDto 重新包装数据密钥报告
+type RewrapReportDto struct {
+	ActiveKeyID string              `json:"active_key_id"`
+	StartedAt   time.Time           `json:"started_at"`
+	FinishedAt  time.Time           `json:"finished_at"`
+	Rewrapped   int                 `json:"rewrapped"`
+	Current     int                 `json:"current"`
+	Plaintext   int                 `json:"plaintext"`
+	Failures    []*RewrapFailureDto `json:"failures"`
+}
+
+// RewrapFailureDto 重新包装失败的对象
+type RewrapFailureDto struct {
+	ObjectName string `json:"object_name"`
+	Error      string `json:"error"`
+}
+
+// PlayableRenditionDto 可播放的转码档位
+type PlayableRenditionDto struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Bitrate int    `json:"bitrate"` // 视频码率（kbps）
+}
+
+// RenditionDto 转码输出，仅视频所有者可见
+type RenditionDto struct {
+	RenditionUUID string     `json:"rendition_uuid"`
+	Name          string     `json:"name"`
+	Required      bool       `json:"required"` // 必需档位全部完成后视频才可播放
+	Status        string     `json:"status"`   // pending/running/succeeded/failed/canceled/superseded
+	Progress      int        `json:"progress"` // 0-100
+	Attempts      int        `json:"attempts"`
+	Current       bool       `json:"current"` // 是否由当前源文件转码而来
+	MaxHeight     int        `json:"max_height"`
+	VideoBitrate  int        `json:"video_bitrate"`
+	AudioBitrate  int        `json:"audio_bitrate"`
+	Width         int        `json:"width,omitempty"`
+	Height        int        `json:"height,omitempty"`
+	FileSize      int64      `json:"file_size,omitempty"`
+	ErrorMsg      string     `json:"error_msg,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// PlaylistDto 播放列表内容（HLS播放列表或DASH清单）
+type PlaylistDto struct {
+	Content     []byte
+	ContentType string
+}
+
+// ShareTokenDto 分享令牌，作为 share_token 查询参数附加在播放列表地址上
+type ShareTokenDto struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	HLSURL    string    `json:"hls_url"` // 带有分享令牌的主播放列表地址
+}
+
+// TranscodeDto 重新转码或取消转码的结果
+type TranscodeDto struct {
+	VideoUUID  string `json:"video_uuid"`
+	Status     string `json:"status"`     // 视频状态
+	Renditions int    `json:"renditions"` // 新建或取消的档位数
+}
+
+// ArtworkDto 视频的封面、候选帧和最近一次生成的状态
+type ArtworkDto struct {
+	VideoUUID     string                `json:"video_uuid"`
+	Status        string                `json:"status,omitempty"`    // 最近一次生成的状态，从未生成时为空
+	ErrorMsg      string                `json:"error_msg,omitempty"` // 最近一次生成失败的原因
+	PosterURL     string                `json:"poster_url,omitempty"`
+	CustomCover   bool                  `json:"custom_cover"` // 封面是否为上传的自定义封面
+	Candidates    []*PosterCandidateDto `json:"candidates"`   // 最新一次生成完成的候选帧
+	StoryboardURL string                `json:"storyboard_url,omitempty"`
+}
+
+// PosterCandidateDto 候选封面帧
+type PosterCandidateDto struct {
+	Index    int    `json:"index"`
+	OffsetMs int64  `json:"offset_ms"` // 截取的时间点（毫秒）
+	URL      string `json:"url"`       // 短期有效的预签名地址
+	Selected bool   `json:"selected"`  // 是否为当前封面
+}
+
+// StoryboardDto 缩略图轨道，雪碧图地址已替换为预签名地址
+type StoryboardDto struct {
+	Content     []byte
+	ContentType string
+}
+
+// ProcessingDto 视频处理流水线的汇总进度
+type ProcessingDto struct {
+	Status         string   `json:"status"`          // processing/completed/failed/canceled
+	Progress       int      `json:"progress"`        // 0-100，按需要执行的步骤平均
+	CompletedSteps int      `json:"completed_steps"` // 已完成的步骤数
+	TotalSteps     int      `json:"total_steps"`     // 需要执行的步骤数，不含跳过的步骤
+	CurrentSteps   []string `json:"current_steps,omitempty"`
+}
+
+// PipelineDto 视频的处理流水线，仅运维人员可见
+type PipelineDto struct {
+	PipelineUUID string             `json:"pipeline_uuid"`
+	VideoUUID    string             `json:"video_uuid"`
+	SourcePath   string             `json:"source_path"`
+	Current      bool               `json:"current"` // 是否为当前源文件创建
+	Processing   *ProcessingDto     `json:"processing"`
+	Steps        []*PipelineStepDto `json:"steps"`
+}
+
+// PipelineStepDto 流水线步骤
+type PipelineStepDto struct {
+	Name        string     `json:"name"`
+	DependsOn   []string   `json:"depends_on,omitempty"`
+	Optional    bool       `json:"optional"` // 失败时不阻塞依赖它的步骤
+	Status      string     `json:"status"`   // pending/running/succeeded/failed/skipped/canceled
+	Progress    int        `json:"progress"` // 0-100
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	ErrorMsg    string     `json:"error_msg,omitempty"`
+	NextRunAt   *time.Time `json:"next_run_at,omitempty"` // 失败后等待重试时的重试时间
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// LiveStreamDto 直播
+type LiveStreamDto struct {
+	StreamUUID   string     `json:"stream_uuid"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`               // idle/live/ended
+	StreamKey    string     `json:"stream_key,omitempty"` // 推流密钥，只在创建时返回一次
+	IngestURL    string     `json:"ingest_url,omitempty"` // 推流地址前缀，编码器以 PUT 推送 <ingest_url>/<文件名>
+	PlaylistURL  string     `json:"playlist_url"`         // 观众播放列表地址
+	VideoUUID    string     `json:"video_uuid,omitempty"` // 直播结束后转换得到的视频
+	VodStatus    string     `json:"vod_status,omitempty"` // 转换状态 pending/running/succeeded/failed/canceled
+	VodErrorMsg  string     `json:"vod_error_msg,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	LastIngestAt *time.Time `json:"last_ingest_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// LiveIngestDto 推送播放列表的结果
+type LiveIngestDto struct {
+	Registered int    `json:"registered"` // 本次登记的分片数
+	Status     string `json:"status"`     // 推送后的直播状态
+}
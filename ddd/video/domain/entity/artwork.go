@@ -0,0 +1,180 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Artwork 为视频的一个源文件生成的候选封面帧和缩略图（雪碧图和WebVTT缩略图轨道），
+// 处理状态和重试方式与转码输出相同
+type Artwork struct {
+	uuid           string
+	videoUuid      string
+	sourcePath     string
+	dir            string
+	status         vo.RenditionStatus
+	attempts       int
+	errorMsg       string
+	candidates     []vo.PosterCandidate
+	storyboardPath string
+	leaseUntil     *time.Time
+	startedAt      *time.Time
+	completedAt    *time.Time
+	createdAt      *time.Time
+}
+
+// DefaultArtwork 为视频当前的源文件创建等待生成的记录，输出存放在视频资源目录的 artwork/<UUID>/ 下
+func DefaultArtwork(video *Video) *Artwork {
+	artworkUuid := uuid.New().String()
+	return &Artwork{
+		uuid:       artworkUuid,
+		videoUuid:  video.UUID(),
+		sourcePath: video.StoragePath(),
+		dir:        video.AssetPrefix() + vo.ArtworkDir + artworkUuid + "/",
+		status:     vo.RenditionStatusPending,
+	}
+}
+
+// NewArtwork 创建记录（用于从数据库加载）
+func NewArtwork(uuid, videoUuid, sourcePath, dir string, status vo.RenditionStatus, attempts int) *Artwork {
+	return &Artwork{
+		uuid:       uuid,
+		videoUuid:  videoUuid,
+		sourcePath: sourcePath,
+		dir:        dir,
+		status:     status,
+		attempts:   attempts,
+	}
+}
+
+// UUID 获取UUID
+func (a *Artwork) UUID() string {
+	return a.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (a *Artwork) VideoUuid() string {
+	return a.videoUuid
+}
+
+// SourcePath 获取生成时使用的源文件路径
+func (a *Artwork) SourcePath() string {
+	return a.sourcePath
+}
+
+// Dir 获取输出目录
+func (a *Artwork) Dir() string {
+	return a.dir
+}
+
+// Status 获取处理状态
+func (a *Artwork) Status() vo.RenditionStatus {
+	return a.status
+}
+
+// Attempts 获取已尝试次数
+func (a *Artwork) Attempts() int {
+	return a.attempts
+}
+
+// ErrorMsg 获取最近一次失败的原因
+func (a *Artwork) ErrorMsg() string {
+	return a.errorMsg
+}
+
+// Candidates 获取候选封面，按截取时间排序
+func (a *Artwork) Candidates() []vo.PosterCandidate {
+	return a.candidates
+}
+
+// StoryboardPath 获取WebVTT缩略图轨道的存储路径，视频太短没有缩略图时为空
+func (a *Artwork) StoryboardPath() string {
+	return a.storyboardPath
+}
+
+// LeaseUntil 获取租约到期时间，等待重试时为重试时间
+func (a *Artwork) LeaseUntil() *time.Time {
+	return a.leaseUntil
+}
+
+// StartedAt 获取最近一次开始时间
+func (a *Artwork) StartedAt() *time.Time {
+	return a.startedAt
+}
+
+// CompletedAt 获取完成时间
+func (a *Artwork) CompletedAt() *time.Time {
+	return a.completedAt
+}
+
+// CreatedAt 获取创建时间
+func (a *Artwork) CreatedAt() *time.Time {
+	return a.createdAt
+}
+
+// IsCurrentFor 是否为视频当前的源文件生成
+func (a *Artwork) IsCurrentFor(video *Video) bool {
+	return a.videoUuid == video.UUID() && a.sourcePath == video.StoragePath()
+}
+
+// Start 开始一次生成，租约到期前由当前执行者独占
+func (a *Artwork) Start(leaseUntil time.Time) {
+	now := time.Now()
+	a.status = vo.RenditionStatusRunning
+	a.attempts++
+	a.errorMsg = ""
+	a.leaseUntil = &leaseUntil
+	a.startedAt = &now
+}
+
+// Succeed 生成完成
+func (a *Artwork) Succeed(candidates []vo.PosterCandidate, storyboardPath string) {
+	now := time.Now()
+	a.status = vo.RenditionStatusSucceeded
+	a.candidates = candidates
+	a.storyboardPath = storyboardPath
+	a.errorMsg = ""
+	a.leaseUntil = nil
+	a.completedAt = &now
+}
+
+// Fail 生成失败：未达到最大次数时等待 retryDelay*已尝试次数 后重试，否则标记为失败
+func (a *Artwork) Fail(reason string, maxAttempts int, retryDelay time.Duration) {
+	now := time.Now()
+	a.errorMsg = reason
+	if a.attempts < maxAttempts {
+		retryAt := now.Add(retryDelay * time.Duration(a.attempts))
+		a.status = vo.RenditionStatusPending
+		a.leaseUntil = &retryAt
+		return
+	}
+	a.status = vo.RenditionStatusFailed
+	a.leaseUntil = nil
+	a.completedAt = &now
+}
+
+// Cancel 源文件已被替换或视频已删除
+func (a *Artwork) Cancel(reason string) {
+	now := time.Now()
+	a.status = vo.RenditionStatusCanceled
+	a.errorMsg = reason
+	a.leaseUntil = nil
+	a.completedAt = &now
+}
+
+// SetResult 设置生成结果（用于从数据库加载）
+func (a *Artwork) SetResult(candidates []vo.PosterCandidate, storyboardPath, errorMsg string) {
+	a.candidates = candidates
+	a.storyboardPath = storyboardPath
+	a.errorMsg = errorMsg
+}
+
+// SetTimes 设置租约和时间（用于从数据库加载）
+func (a *Artwork) SetTimes(leaseUntil, startedAt, completedAt, createdAt *time.Time) {
+	a.leaseUntil = leaseUntil
+	a.startedAt = startedAt
+	a.completedAt = completedAt
+	a.createdAt = createdAt
+}
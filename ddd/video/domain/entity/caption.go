@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"go-video/ddd/video/domain/vo"
+
+	"github.com/google/uuid"
+)
+
+// CaptionTrack 字幕轨道实体
+type CaptionTrack struct {
+	uuid         string
+	videoUuid    string
+	language     string
+	label        string
+	isDefault    bool
+	sourceFormat vo.CaptionFormat
+	storagePath  string
+}
+
+// DefaultCaptionTrack 创建新的字幕轨道，存储路径位于视频资源目录下
+func DefaultCaptionTrack(video *Video, language, label string, isDefault bool, sourceFormat vo.CaptionFormat) *CaptionTrack {
+	trackUuid := uuid.New().String()
+	return &CaptionTrack{
+		uuid:         trackUuid,
+		videoUuid:    video.UUID(),
+		language:     language,
+		label:        label,
+		isDefault:    isDefault,
+		sourceFormat: sourceFormat,
+		storagePath:  fmt.Sprintf("%scaptions/%s.%s.vtt", video.AssetPrefix(), strings.ToLower(language), trackUuid),
+	}
+}
+
+// NewCaptionTrack 创建字幕轨道（仅用于从数据库加载）
+func NewCaptionTrack(uuid, videoUuid, language, label string, isDefault bool, sourceFormat vo.CaptionFormat, storagePath string) *CaptionTrack {
+	return &CaptionTrack{
+		uuid:         uuid,
+		videoUuid:    videoUuid,
+		language:     language,
+		label:        label,
+		isDefault:    isDefault,
+		sourceFormat: sourceFormat,
+		storagePath:  storagePath,
+	}
+}
+
+// UUID 获取字幕轨道UUID
+func (c *CaptionTrack) UUID() string {
+	return c.uuid
+}
+
+// VideoUuid 获取所属视频UUID
+func (c *CaptionTrack) VideoUuid() string {
+	return c.videoUuid
+}
+
+// Language 获取语言标签
+func (c *CaptionTrack) Language() string {
+	return c.language
+}
+
+// Label 获取展示名称
+func (c *CaptionTrack) Label() string {
+	return c.label
+}
+
+// IsDefault 是否为默认字幕
+func (c *CaptionTrack) IsDefault() bool {
+	return c.isDefault
+}
+
+// SourceFormat 获取上传时的源格式
+func (c *CaptionTrack) SourceFormat() vo.CaptionFormat {
+	return c.sourceFormat
+}
+
+// StoragePath 获取WebVTT文件存储路径
+func (c *CaptionTrack) StoragePath() string {
+	return c.storagePath
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// ChecksumVerification 存储对象与上传时记录的校验和的比对结果
+type ChecksumVerification struct {
+	videoUuid    string
+	objectName   string
+	recorded     vo.Checksum
+	actual       vo.Checksum
+	recordedETag string
+	storedETag   string
+	verifiedAt   time.Time
+}
+
+// NewChecksumVerification 创建校验结果
+func NewChecksumVerification(video *Video, actual vo.Checksum, storedETag string, verifiedAt time.Time) *ChecksumVerification {
+	return &ChecksumVerification{
+		videoUuid:    video.UUID(),
+		objectName:   video.StoragePath(),
+		recorded:     video.Checksum(),
+		actual:       actual,
+		recordedETag: video.ETag(),
+		storedETag:   storedETag,
+		verifiedAt:   verifiedAt,
+	}
+}
+
+// VideoUuid 获取视频UUID
+func (c *ChecksumVerification) VideoUuid() string {
+	return c.videoUuid
+}
+
+// ObjectName 获取被校验的对象名
+func (c *ChecksumVerification) ObjectName() string {
+	return c.objectName
+}
+
+// Recorded 获取上传时记录的校验和
+func (c *ChecksumVerification) Recorded() vo.Checksum {
+	return c.recorded
+}
+
+// Actual 获取重新计算的校验和
+func (c *ChecksumVerification) Actual() vo.Checksum {
+	return c.actual
+}
+
+// RecordedETag 获取上传时记录的ETag
+func (c *ChecksumVerification) RecordedETag() string {
+	return c.recordedETag
+}
+
+// StoredETag 获取存储当前返回的ETag，重新包装密钥等元数据操作会改变ETag
+func (c *ChecksumVerification) StoredETag() string {
+	return c.storedETag
+}
+
+// VerifiedAt 获取校验时间
+func (c *ChecksumVerification) VerifiedAt() time.Time {
+	return c.verifiedAt
+}
+
+// Mismatch 获取不匹配的详情，一致时返回nil
+func (c *ChecksumVerification) Mismatch() error {
+	return c.recorded.Verify(c.actual)
+}
+
+// Matches 内容是否与记录的校验和一致
+func (c *ChecksumVerification) Matches() bool {
+	return c.Mismatch() == nil
+}
@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentKey HLS分片的AES-128内容密钥，同一视频的每档转码输出在相同序号的分片上使用同一个密钥。
+// 数据库中只保存被主密钥包装后的密钥
+type ContentKey struct {
+	uuid        string
+	videoUuid   string
+	sequence    int
+	masterKeyID string
+	wrappedKey  []byte
+	createdAt   *time.Time
+}
+
+// DefaultContentKey 创建新的内容密钥
+func DefaultContentKey(videoUuid string, sequence int, masterKeyID string, wrappedKey []byte) *ContentKey {
+	return &ContentKey{
+		uuid:        uuid.New().String(),
+		videoUuid:   videoUuid,
+		sequence:    sequence,
+		masterKeyID: masterKeyID,
+		wrappedKey:  wrappedKey,
+	}
+}
+
+// NewContentKey 创建内容密钥（用于从数据库加载）
+func NewContentKey(uuid, videoUuid string, sequence int, masterKeyID string, wrappedKey []byte, createdAt *time.Time) *ContentKey {
+	return &ContentKey{
+		uuid:        uuid,
+		videoUuid:   videoUuid,
+		sequence:    sequence,
+		masterKeyID: masterKeyID,
+		wrappedKey:  wrappedKey,
+		createdAt:   createdAt,
+	}
+}
+
+// UUID 获取密钥UUID
+func (k *ContentKey) UUID() string {
+	return k.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (k *ContentKey) VideoUuid() string {
+	return k.videoUuid
+}
+
+// Sequence 获取密钥序号，第 n 个密钥加密下标为 [n*轮换分片数, (n+1)*轮换分片数) 的分片
+func (k *ContentKey) Sequence() int {
+	return k.sequence
+}
+
+// MasterKeyID 获取包装密钥的主密钥ID
+func (k *ContentKey) MasterKeyID() string {
+	return k.masterKeyID
+}
+
+// WrappedKey 获取被包装的密钥
+func (k *ContentKey) WrappedKey() []byte {
+	return k.wrappedKey
+}
+
+// CreatedAt 获取创建时间
+func (k *ContentKey) CreatedAt() *time.Time {
+	return k.createdAt
+}
+
+// Rewrap 主密钥轮换后用新的主密钥重新包装
+func (k *ContentKey) Rewrap(masterKeyID string, wrappedKey []byte) {
+	k.masterKeyID = masterKeyID
+	k.wrappedKey = wrappedKey
+}
+
+// KeyAccess 一次获取内容密钥的审计记录，拒绝的请求同样记录
+type KeyAccess struct {
+	uuid       string
+	videoUuid  string
+	keyUuid    string
+	userUuid   string
+	issuerUuid string
+	clientIP   string
+	userAgent  string
+	granted    bool
+	reason     string
+}
+
+// DefaultKeyAccess 创建审计记录，userUuid 为登录用户，issuerUuid 为使用的分享令牌的签发人
+func DefaultKeyAccess(videoUuid, keyUuid, userUuid, issuerUuid, clientIP, userAgent string, granted bool, reason string) *KeyAccess {
+	return &KeyAccess{
+		uuid:       uuid.New().String(),
+		videoUuid:  videoUuid,
+		keyUuid:    keyUuid,
+		userUuid:   userUuid,
+		issuerUuid: issuerUuid,
+		clientIP:   clientIP,
+		userAgent:  userAgent,
+		granted:    granted,
+		reason:     reason,
+	}
+}
+
+// UUID 获取记录UUID
+func (a *KeyAccess) UUID() string {
+	return a.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (a *KeyAccess) VideoUuid() string {
+	return a.videoUuid
+}
+
+// KeyUuid 获取请求的密钥UUID
+func (a *KeyAccess) KeyUuid() string {
+	return a.keyUuid
+}
+
+// UserUuid 获取登录用户UUID，未登录为空
+func (a *KeyAccess) UserUuid() string {
+	return a.userUuid
+}
+
+// IssuerUuid 获取分享令牌的签发人UUID，没有使用分享令牌时为空
+func (a *KeyAccess) IssuerUuid() string {
+	return a.issuerUuid
+}
+
+// ClientIP 获取客户端IP
+func (a *KeyAccess) ClientIP() string {
+	return a.clientIP
+}
+
+// UserAgent 获取客户端标识
+func (a *KeyAccess) UserAgent() string {
+	return a.userAgent
+}
+
+// Granted 是否发放了密钥
+func (a *KeyAccess) Granted() bool {
+	return a.granted
+}
+
+// Reason 获取发放或拒绝的原因
+func (a *KeyAccess) Reason() string {
+	return a.reason
+}
@@ -0,0 +1,178 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FaststartJob 为视频的一个源文件执行的 faststart 改写，处理状态和重试方式与转码输出相同
+type FaststartJob struct {
+	uuid         string
+	videoUuid    string
+	sourcePath   string
+	status       vo.RenditionStatus
+	attempts     int
+	errorMsg     string
+	outcome      vo.FaststartOutcome
+	originalSize int64
+	fileSize     int64
+	leaseUntil   *time.Time
+	startedAt    *time.Time
+	completedAt  *time.Time
+	createdAt    *time.Time
+}
+
+// DefaultFaststartJob 为视频当前的源文件创建等待处理的记录
+func DefaultFaststartJob(video *Video) *FaststartJob {
+	return &FaststartJob{
+		uuid:       uuid.New().String(),
+		videoUuid:  video.UUID(),
+		sourcePath: video.StoragePath(),
+		status:     vo.RenditionStatusPending,
+	}
+}
+
+// NewFaststartJob 创建记录（用于从数据库加载）
+func NewFaststartJob(uuid, videoUuid, sourcePath string, status vo.RenditionStatus, attempts int) *FaststartJob {
+	return &FaststartJob{
+		uuid:       uuid,
+		videoUuid:  videoUuid,
+		sourcePath: sourcePath,
+		status:     status,
+		attempts:   attempts,
+	}
+}
+
+// UUID 获取UUID
+func (j *FaststartJob) UUID() string {
+	return j.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (j *FaststartJob) VideoUuid() string {
+	return j.videoUuid
+}
+
+// SourcePath 获取要改写的源文件路径
+func (j *FaststartJob) SourcePath() string {
+	return j.sourcePath
+}
+
+// Status 获取处理状态
+func (j *FaststartJob) Status() vo.RenditionStatus {
+	return j.status
+}
+
+// Attempts 获取已尝试次数
+func (j *FaststartJob) Attempts() int {
+	return j.attempts
+}
+
+// ErrorMsg 获取最近一次失败的原因
+func (j *FaststartJob) ErrorMsg() string {
+	return j.errorMsg
+}
+
+// Outcome 获取处理结果
+func (j *FaststartJob) Outcome() vo.FaststartOutcome {
+	return j.outcome
+}
+
+// OriginalSize 获取改写前的文件大小
+func (j *FaststartJob) OriginalSize() int64 {
+	return j.originalSize
+}
+
+// FileSize 获取处理后的文件大小，未改写时与改写前相同
+func (j *FaststartJob) FileSize() int64 {
+	return j.fileSize
+}
+
+// LeaseUntil 获取租约到期时间，等待重试时为重试时间
+func (j *FaststartJob) LeaseUntil() *time.Time {
+	return j.leaseUntil
+}
+
+// StartedAt 获取最近一次开始时间
+func (j *FaststartJob) StartedAt() *time.Time {
+	return j.startedAt
+}
+
+// CompletedAt 获取完成时间
+func (j *FaststartJob) CompletedAt() *time.Time {
+	return j.completedAt
+}
+
+// CreatedAt 获取创建时间
+func (j *FaststartJob) CreatedAt() *time.Time {
+	return j.createdAt
+}
+
+// IsCurrentFor 是否为视频当前的源文件创建
+func (j *FaststartJob) IsCurrentFor(video *Video) bool {
+	return j.videoUuid == video.UUID() && j.sourcePath == video.StoragePath()
+}
+
+// Start 开始一次处理，租约到期前由当前执行者独占
+func (j *FaststartJob) Start(leaseUntil time.Time) {
+	now := time.Now()
+	j.status = vo.RenditionStatusRunning
+	j.attempts++
+	j.errorMsg = ""
+	j.leaseUntil = &leaseUntil
+	j.startedAt = &now
+}
+
+// Succeed 处理完成，源文件没有改写时 fileSize 与 originalSize 相同
+func (j *FaststartJob) Succeed(outcome vo.FaststartOutcome, originalSize, fileSize int64) {
+	now := time.Now()
+	j.status = vo.RenditionStatusSucceeded
+	j.outcome = outcome
+	j.originalSize = originalSize
+	j.fileSize = fileSize
+	j.errorMsg = ""
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// Fail 处理失败：未达到最大次数时等待 retryDelay*已尝试次数 后重试，否则标记为失败
+func (j *FaststartJob) Fail(reason string, maxAttempts int, retryDelay time.Duration) {
+	now := time.Now()
+	j.errorMsg = reason
+	if j.attempts < maxAttempts {
+		retryAt := now.Add(retryDelay * time.Duration(j.attempts))
+		j.status = vo.RenditionStatusPending
+		j.leaseUntil = &retryAt
+		return
+	}
+	j.status = vo.RenditionStatusFailed
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// Cancel 源文件已被替换或视频已删除
+func (j *FaststartJob) Cancel(reason string) {
+	now := time.Now()
+	j.status = vo.RenditionStatusCanceled
+	j.errorMsg = reason
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// SetResult 设置处理结果（用于从数据库加载）
+func (j *FaststartJob) SetResult(outcome vo.FaststartOutcome, originalSize, fileSize int64, errorMsg string) {
+	j.outcome = outcome
+	j.originalSize = originalSize
+	j.fileSize = fileSize
+	j.errorMsg = errorMsg
+}
+
+// SetTimes 设置租约和时间（用于从数据库加载）
+func (j *FaststartJob) SetTimes(leaseUntil, startedAt, completedAt, createdAt *time.Time) {
+	j.leaseUntil = leaseUntil
+	j.startedAt = startedAt
+	j.completedAt = completedAt
+	j.createdAt = createdAt
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LiveSegment 登记到直播的一个分片，sequence 从0开始连续递增，即 HLS 的媒体序列号
+type LiveSegment struct {
+	uuid       string
+	streamUuid string
+	sequence   int
+	filename   string
+	path       string
+	duration   time.Duration
+	size       int64
+	createdAt  *time.Time
+}
+
+// DefaultLiveSegment 为编码器推送的分片创建登记记录，分片存放在自己的路径下
+func DefaultLiveSegment(streamUuid string, sequence int, filename string, duration time.Duration, size int64) *LiveSegment {
+	segmentUuid := uuid.New().String()
+	return &LiveSegment{
+		uuid:       segmentUuid,
+		streamUuid: streamUuid,
+		sequence:   sequence,
+		filename:   filename,
+		path:       vo.LiveSegmentObjectName(streamUuid, segmentUuid),
+		duration:   duration,
+		size:       size,
+	}
+}
+
+// NewLiveSegment 创建分片（用于从数据库加载）
+func NewLiveSegment(uuid, streamUuid string, sequence int, filename, path string, duration time.Duration, size int64,
+	createdAt *time.Time) *LiveSegment {
+	return &LiveSegment{
+		uuid:       uuid,
+		streamUuid: streamUuid,
+		sequence:   sequence,
+		filename:   filename,
+		path:       path,
+		duration:   duration,
+		size:       size,
+		createdAt:  createdAt,
+	}
+}
+
+// Resequence 序列号已被并发登记的分片占用时改用下一个序列号，存储路径不变
+func (s *LiveSegment) Resequence(sequence int) {
+	s.sequence = sequence
+}
+
+// UUID 获取UUID
+func (s *LiveSegment) UUID() string {
+	return s.uuid
+}
+
+// StreamUuid 获取直播UUID
+func (s *LiveSegment) StreamUuid() string {
+	return s.streamUuid
+}
+
+// Sequence 获取媒体序列号
+func (s *LiveSegment) Sequence() int {
+	return s.sequence
+}
+
+// Filename 获取编码器推送时的文件名
+func (s *LiveSegment) Filename() string {
+	return s.filename
+}
+
+// Path 获取分片的存储路径
+func (s *LiveSegment) Path() string {
+	return s.path
+}
+
+// Duration 获取播放列表中声明的时长
+func (s *LiveSegment) Duration() time.Duration {
+	return s.duration
+}
+
+// Size 获取字节数
+func (s *LiveSegment) Size() int64 {
+	return s.size
+}
+
+// CreatedAt 获取登记时间
+func (s *LiveSegment) CreatedAt() *time.Time {
+	return s.createdAt
+}
@@ -0,0 +1,225 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LiveStream 直播：编码器凭推流密钥推送 HLS 分片，状态为 idle -> live -> ended。
+// 结束后有分片的直播由转换任务拼接为普通视频，转换的处理状态和重试方式与转码输出相同
+type LiveStream struct {
+	uuid          string
+	userUuid      string
+	title         string
+	description   string
+	streamKeyHash string
+	status        vo.LiveStreamStatus
+	startedAt     *time.Time
+	lastIngestAt  *time.Time
+	endedAt       *time.Time
+	createdAt     *time.Time
+
+	// 转换为视频
+	videoUuid     string
+	taskUuid      string
+	vodStatus     vo.RenditionStatus
+	vodAttempts   int
+	vodErrorMsg   string
+	vodLeaseUntil *time.Time
+}
+
+// DefaultLiveStream 创建还没有推流的直播，返回只在此时可见的推流密钥
+func DefaultLiveStream(userUuid, title, description string) (*LiveStream, string) {
+	streamKey := vo.NewStreamKey()
+	return &LiveStream{
+		uuid:          uuid.New().String(),
+		userUuid:      userUuid,
+		title:         title,
+		description:   description,
+		streamKeyHash: vo.HashStreamKey(streamKey),
+		status:        vo.LiveStreamStatusIdle,
+	}, streamKey
+}
+
+// NewLiveStream 创建直播（用于从数据库加载）
+func NewLiveStream(uuid, userUuid, title, description, streamKeyHash string, status vo.LiveStreamStatus) *LiveStream {
+	return &LiveStream{
+		uuid:          uuid,
+		userUuid:      userUuid,
+		title:         title,
+		description:   description,
+		streamKeyHash: streamKeyHash,
+		status:        status,
+	}
+}
+
+// UUID 获取UUID
+func (s *LiveStream) UUID() string {
+	return s.uuid
+}
+
+// UserUuid 获取主播UUID
+func (s *LiveStream) UserUuid() string {
+	return s.userUuid
+}
+
+// Title 获取标题，转换后的视频使用同一标题
+func (s *LiveStream) Title() string {
+	return s.title
+}
+
+// Description 获取描述
+func (s *LiveStream) Description() string {
+	return s.description
+}
+
+// StreamKeyHash 获取推流密钥的哈希
+func (s *LiveStream) StreamKeyHash() string {
+	return s.streamKeyHash
+}
+
+// Status 获取直播状态
+func (s *LiveStream) Status() vo.LiveStreamStatus {
+	return s.status
+}
+
+// StartedAt 获取开始直播（第一个分片登记）的时间
+func (s *LiveStream) StartedAt() *time.Time {
+	return s.startedAt
+}
+
+// LastIngestAt 获取最近一次推流的时间
+func (s *LiveStream) LastIngestAt() *time.Time {
+	return s.lastIngestAt
+}
+
+// EndedAt 获取结束时间
+func (s *LiveStream) EndedAt() *time.Time {
+	return s.endedAt
+}
+
+// CreatedAt 获取创建时间
+func (s *LiveStream) CreatedAt() *time.Time {
+	return s.createdAt
+}
+
+// VideoUuid 获取转换后的视频UUID，还没有开始转换时为空
+func (s *LiveStream) VideoUuid() string {
+	return s.videoUuid
+}
+
+// TaskUuid 获取转换使用的上传任务UUID
+func (s *LiveStream) TaskUuid() string {
+	return s.taskUuid
+}
+
+// VodStatus 获取转换状态，没有需要转换的分片时为空
+func (s *LiveStream) VodStatus() vo.RenditionStatus {
+	return s.vodStatus
+}
+
+// VodAttempts 获取转换的已尝试次数
+func (s *LiveStream) VodAttempts() int {
+	return s.vodAttempts
+}
+
+// VodErrorMsg 获取转换最近一次失败的原因
+func (s *LiveStream) VodErrorMsg() string {
+	return s.vodErrorMsg
+}
+
+// VodLeaseUntil 获取转换的租约到期时间，等待重试时为重试时间
+func (s *LiveStream) VodLeaseUntil() *time.Time {
+	return s.vodLeaseUntil
+}
+
+// IsOwnedBy 是否为该用户创建的直播
+func (s *LiveStream) IsOwnedBy(userUuid string) bool {
+	return userUuid != "" && s.userUuid == userUuid
+}
+
+// GoLive 第一个分片登记后进入直播中，已在直播中时返回false
+func (s *LiveStream) GoLive(now time.Time) bool {
+	if s.status != vo.LiveStreamStatusIdle {
+		return false
+	}
+	s.status = vo.LiveStreamStatusLive
+	s.startedAt = &now
+	s.lastIngestAt = &now
+	return true
+}
+
+// End 结束直播，有分片时等待转换为视频；已结束时返回false
+func (s *LiveStream) End(now time.Time, hasSegments bool) bool {
+	if s.status == vo.LiveStreamStatusEnded {
+		return false
+	}
+	s.status = vo.LiveStreamStatusEnded
+	s.endedAt = &now
+	if hasSegments {
+		s.vodStatus = vo.RenditionStatusPending
+	}
+	return true
+}
+
+// StartConversion 开始一次转换，租约到期前由当前执行者独占
+func (s *LiveStream) StartConversion(leaseUntil time.Time) {
+	s.vodStatus = vo.RenditionStatusRunning
+	s.vodAttempts++
+	s.vodErrorMsg = ""
+	s.vodLeaseUntil = &leaseUntil
+}
+
+// AttachVideo 记录转换创建的视频和上传任务，重新执行时继续使用，不再重复创建
+func (s *LiveStream) AttachVideo(videoUuid, taskUuid string) {
+	s.videoUuid = videoUuid
+	s.taskUuid = taskUuid
+}
+
+// SucceedConversion 视频源文件已上传，后续由处理流水线处理
+func (s *LiveStream) SucceedConversion() {
+	s.vodStatus = vo.RenditionStatusSucceeded
+	s.vodErrorMsg = ""
+	s.vodLeaseUntil = nil
+}
+
+// FailConversion 转换失败：未达到最大次数时等待 retryDelay*已尝试次数 后重试，否则标记为失败
+func (s *LiveStream) FailConversion(reason string, maxAttempts int, retryDelay time.Duration) {
+	s.vodErrorMsg = reason
+	if s.vodAttempts < maxAttempts {
+		retryAt := time.Now().Add(retryDelay * time.Duration(s.vodAttempts))
+		s.vodStatus = vo.RenditionStatusPending
+		s.vodLeaseUntil = &retryAt
+		return
+	}
+	s.vodStatus = vo.RenditionStatusFailed
+	s.vodLeaseUntil = nil
+}
+
+// CancelConversion 不再转换：转换创建的视频已被删除或没有分片
+func (s *LiveStream) CancelConversion(reason string) {
+	s.vodStatus = vo.RenditionStatusCanceled
+	s.vodErrorMsg = reason
+	s.vodLeaseUntil = nil
+}
+
+// SetConversion 设置转换状态（用于从数据库加载）
+func (s *LiveStream) SetConversion(videoUuid, taskUuid string, vodStatus vo.RenditionStatus, vodAttempts int, vodErrorMsg string,
+	vodLeaseUntil *time.Time) {
+	s.videoUuid = videoUuid
+	s.taskUuid = taskUuid
+	s.vodStatus = vodStatus
+	s.vodAttempts = vodAttempts
+	s.vodErrorMsg = vodErrorMsg
+	s.vodLeaseUntil = vodLeaseUntil
+}
+
+// SetTimes 设置时间（用于从数据库加载）
+func (s *LiveStream) SetTimes(startedAt, lastIngestAt, endedAt, createdAt *time.Time) {
+	s.startedAt = startedAt
+	s.lastIngestAt = lastIngestAt
+	s.endedAt = endedAt
+	s.createdAt = createdAt
+}
@@ -0,0 +1,408 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PipelineStep 处理流水线中的一个步骤，每次执行调度对应的处理（faststart、转码、封面等）并等待其完成
+type PipelineStep struct {
+	uuid         string
+	pipelineUuid string
+	videoUuid    string
+	sourcePath   string
+	name         string
+	position     int
+	dependsOn    []string
+	optional     bool
+	status       vo.PipelineStepStatus
+	attempts     int
+	maxAttempts  int
+	progress     int
+	errorMsg     string
+	nextRunAt    *time.Time
+	startedAt    *time.Time
+	completedAt  *time.Time
+	createdAt    *time.Time
+}
+
+// NewPipelineStep 创建步骤（用于从数据库加载）
+func NewPipelineStep(uuid, pipelineUuid, videoUuid, sourcePath, name string, position int, dependsOn []string, optional bool,
+	status vo.PipelineStepStatus, attempts, maxAttempts int) *PipelineStep {
+	return &PipelineStep{
+		uuid:         uuid,
+		pipelineUuid: pipelineUuid,
+		videoUuid:    videoUuid,
+		sourcePath:   sourcePath,
+		name:         name,
+		position:     position,
+		dependsOn:    dependsOn,
+		optional:     optional,
+		status:       status,
+		attempts:     attempts,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// UUID 获取UUID
+func (s *PipelineStep) UUID() string {
+	return s.uuid
+}
+
+// PipelineUuid 获取所属流水线的UUID
+func (s *PipelineStep) PipelineUuid() string {
+	return s.pipelineUuid
+}
+
+// VideoUuid 获取视频UUID
+func (s *PipelineStep) VideoUuid() string {
+	return s.videoUuid
+}
+
+// SourcePath 获取处理的源文件路径
+func (s *PipelineStep) SourcePath() string {
+	return s.sourcePath
+}
+
+// Name 获取步骤名称
+func (s *PipelineStep) Name() string {
+	return s.name
+}
+
+// Position 获取步骤在定义中的位置
+func (s *PipelineStep) Position() int {
+	return s.position
+}
+
+// DependsOn 获取依赖的步骤
+func (s *PipelineStep) DependsOn() []string {
+	return s.dependsOn
+}
+
+// Optional 失败时是否不阻塞依赖它的步骤
+func (s *PipelineStep) Optional() bool {
+	return s.optional
+}
+
+// Status 获取步骤状态
+func (s *PipelineStep) Status() vo.PipelineStepStatus {
+	return s.status
+}
+
+// Attempts 获取已执行次数
+func (s *PipelineStep) Attempts() int {
+	return s.attempts
+}
+
+// MaxAttempts 获取最多执行次数
+func (s *PipelineStep) MaxAttempts() int {
+	return s.maxAttempts
+}
+
+// Progress 获取完成百分比
+func (s *PipelineStep) Progress() int {
+	return s.progress
+}
+
+// ErrorMsg 获取最近一次失败、跳过或取消的原因
+func (s *PipelineStep) ErrorMsg() string {
+	return s.errorMsg
+}
+
+// NextRunAt 获取等待重试时的重试时间
+func (s *PipelineStep) NextRunAt() *time.Time {
+	return s.nextRunAt
+}
+
+// StartedAt 获取最近一次开始时间
+func (s *PipelineStep) StartedAt() *time.Time {
+	return s.startedAt
+}
+
+// CompletedAt 获取结束时间
+func (s *PipelineStep) CompletedAt() *time.Time {
+	return s.completedAt
+}
+
+// CreatedAt 获取创建时间
+func (s *PipelineStep) CreatedAt() *time.Time {
+	return s.createdAt
+}
+
+// IsDone 依赖它的步骤是否可以开始：已完成、已跳过，或可选步骤已失败
+func (s *PipelineStep) IsDone() bool {
+	switch s.status {
+	case vo.PipelineStepStatusSucceeded, vo.PipelineStepStatusSkipped:
+		return true
+	case vo.PipelineStepStatusFailed:
+		return s.optional
+	}
+	return false
+}
+
+// Dispatch 开始一次执行
+func (s *PipelineStep) Dispatch() {
+	now := time.Now()
+	s.status = vo.PipelineStepStatusRunning
+	s.attempts++
+	s.progress = 0
+	s.errorMsg = ""
+	s.nextRunAt = nil
+	s.startedAt = &now
+	s.completedAt = nil
+}
+
+// SetProgress 更新执行中的完成百分比
+func (s *PipelineStep) SetProgress(progress int) {
+	s.progress = progress
+}
+
+// Succeed 执行完成
+func (s *PipelineStep) Succeed() {
+	now := time.Now()
+	s.status = vo.PipelineStepStatusSucceeded
+	s.progress = 100
+	s.errorMsg = ""
+	s.completedAt = &now
+}
+
+// Fail 执行失败：未达到最大次数时等待 retryDelay*已执行次数 后重试，否则标记为失败
+func (s *PipelineStep) Fail(reason string, retryDelay time.Duration) {
+	now := time.Now()
+	s.errorMsg = reason
+	if s.attempts < s.maxAttempts {
+		retryAt := now.Add(retryDelay * time.Duration(s.attempts))
+		s.status = vo.PipelineStepStatusPending
+		s.nextRunAt = &retryAt
+		return
+	}
+	s.status = vo.PipelineStepStatusFailed
+	s.completedAt = &now
+}
+
+// Skip 对应的功能未启用
+func (s *PipelineStep) Skip(reason string) {
+	now := time.Now()
+	s.status = vo.PipelineStepStatusSkipped
+	s.errorMsg = reason
+	s.completedAt = &now
+}
+
+// Cancel 取消步骤
+func (s *PipelineStep) Cancel(reason string) {
+	now := time.Now()
+	s.status = vo.PipelineStepStatusCanceled
+	s.errorMsg = reason
+	s.nextRunAt = nil
+	s.completedAt = &now
+}
+
+// Reset 重新执行：回到等待状态并清零执行次数
+func (s *PipelineStep) Reset() {
+	s.status = vo.PipelineStepStatusPending
+	s.attempts = 0
+	s.progress = 0
+	s.errorMsg = ""
+	s.nextRunAt = nil
+	s.completedAt = nil
+}
+
+// SetResult 设置进度和原因（用于从数据库加载）
+func (s *PipelineStep) SetResult(progress int, errorMsg string) {
+	s.progress = progress
+	s.errorMsg = errorMsg
+}
+
+// SetTimes 设置时间（用于从数据库加载）
+func (s *PipelineStep) SetTimes(nextRunAt, startedAt, completedAt, createdAt *time.Time) {
+	s.nextRunAt = nextRunAt
+	s.startedAt = startedAt
+	s.completedAt = completedAt
+	s.createdAt = createdAt
+}
+
+// Pipeline 为视频的一个源文件创建的处理流水线，步骤按定义的顺序排列
+type Pipeline struct {
+	uuid       string
+	videoUuid  string
+	sourcePath string
+	steps      []*PipelineStep
+}
+
+// DefaultPipeline 按定义为视频当前的源文件创建流水线，全部步骤等待执行
+func DefaultPipeline(video *Video, definition []vo.PipelineStepDefinition) *Pipeline {
+	pipeline := &Pipeline{
+		uuid:       uuid.New().String(),
+		videoUuid:  video.UUID(),
+		sourcePath: video.StoragePath(),
+	}
+	for i, step := range definition {
+		pipeline.steps = append(pipeline.steps, NewPipelineStep(uuid.New().String(), pipeline.uuid, video.UUID(),
+			video.StoragePath(), step.Name(), i, step.DependsOn(), step.Optional(), vo.PipelineStepStatusPending, 0, step.MaxAttempts()))
+	}
+	return pipeline
+}
+
+// NewPipeline 由步骤组成流水线（用于从数据库加载），steps 按位置排列且不能为空
+func NewPipeline(steps []*PipelineStep) *Pipeline {
+	return &Pipeline{
+		uuid:       steps[0].pipelineUuid,
+		videoUuid:  steps[0].videoUuid,
+		sourcePath: steps[0].sourcePath,
+		steps:      steps,
+	}
+}
+
+// UUID 获取UUID
+func (p *Pipeline) UUID() string {
+	return p.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (p *Pipeline) VideoUuid() string {
+	return p.videoUuid
+}
+
+// SourcePath 获取处理的源文件路径
+func (p *Pipeline) SourcePath() string {
+	return p.sourcePath
+}
+
+// Steps 获取全部步骤
+func (p *Pipeline) Steps() []*PipelineStep {
+	return p.steps
+}
+
+// Step 按名称获取步骤，不存在时返回nil
+func (p *Pipeline) Step(name string) *PipelineStep {
+	for _, step := range p.steps {
+		if step.name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// IsCurrentFor 是否为视频当前的源文件创建
+func (p *Pipeline) IsCurrentFor(video *Video) bool {
+	return p.videoUuid == video.UUID() && p.sourcePath == video.StoragePath()
+}
+
+// Ready 依赖全部完成且已到重试时间的等待中步骤
+func (p *Pipeline) Ready(now time.Time) []*PipelineStep {
+	var ready []*PipelineStep
+	for _, step := range p.steps {
+		if step.status != vo.PipelineStepStatusPending || (step.nextRunAt != nil && step.nextRunAt.After(now)) {
+			continue
+		}
+		if p.blockedBy(step) == "" && p.dependenciesDone(step) {
+			ready = append(ready, step)
+		}
+	}
+	return ready
+}
+
+// Blocked 依赖的步骤已失败或被取消、永远不会开始的等待中步骤，值为阻塞它的步骤
+func (p *Pipeline) Blocked() map[*PipelineStep]string {
+	blocked := map[*PipelineStep]string{}
+	for _, step := range p.steps {
+		if step.status != vo.PipelineStepStatusPending {
+			continue
+		}
+		if dependency := p.blockedBy(step); dependency != "" {
+			blocked[step] = dependency
+		}
+	}
+	return blocked
+}
+
+// Downstream 直接或间接依赖name的步骤
+func (p *Pipeline) Downstream(name string) []*PipelineStep {
+	affected := map[string]bool{name: true}
+	var downstream []*PipelineStep
+	// 依赖总在之前声明，按顺序扫描一次即可覆盖间接依赖
+	for _, step := range p.steps {
+		for _, dependency := range step.dependsOn {
+			if affected[dependency] && !affected[step.name] {
+				affected[step.name] = true
+				downstream = append(downstream, step)
+			}
+		}
+	}
+	return downstream
+}
+
+// Progress 汇总进度：跳过的步骤不计入，有必需步骤失败时为失败
+func (p *Pipeline) Progress() vo.ProcessingProgress {
+	total, completed, progress := 0, 0, 0
+	var current []string
+	active, failed, canceled := false, false, false
+	for _, step := range p.steps {
+		switch step.status {
+		case vo.PipelineStepStatusSkipped:
+			continue
+		case vo.PipelineStepStatusSucceeded:
+			completed++
+		case vo.PipelineStepStatusRunning:
+			current = append(current, step.name)
+			active = true
+		case vo.PipelineStepStatusPending:
+			active = true
+		case vo.PipelineStepStatusFailed:
+			failed = failed || !step.optional
+		case vo.PipelineStepStatusCanceled:
+			canceled = true
+		}
+		total++
+		if step.status == vo.PipelineStepStatusFailed && step.optional {
+			progress += 100
+		} else {
+			progress += step.progress
+		}
+	}
+	percent := 100
+	if total > 0 {
+		percent = progress / total
+	}
+	status := vo.ProcessingStatusCompleted
+	switch {
+	case failed:
+		status = vo.ProcessingStatusFailed
+	case active:
+		status = vo.ProcessingStatusProcessing
+	case canceled:
+		status = vo.ProcessingStatusCanceled
+	}
+	return vo.NewProcessingProgress(status, percent, completed, total, current)
+}
+
+// dependenciesDone 依赖的步骤是否全部完成
+func (p *Pipeline) dependenciesDone(step *PipelineStep) bool {
+	for _, name := range step.dependsOn {
+		if dependency := p.Step(name); dependency != nil && !dependency.IsDone() {
+			return false
+		}
+	}
+	return true
+}
+
+// blockedBy 返回已失败（非可选）或被取消的依赖步骤名称，没有时返回空
+func (p *Pipeline) blockedBy(step *PipelineStep) string {
+	for _, name := range step.dependsOn {
+		dependency := p.Step(name)
+		if dependency == nil {
+			continue
+		}
+		switch dependency.status {
+		case vo.PipelineStepStatusCanceled:
+			return name
+		case vo.PipelineStepStatusFailed:
+			if !dependency.optional {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// ReconcileReport 对象存储与数据库的对账报告
+type ReconcileReport struct {
+	dryRun         bool
+	gracePeriod    time.Duration
+	startedAt      time.Time
+	finishedAt     time.Time
+	scannedObjects int
+	scannedVideos  int
+	orphans        []*OrphanObject
+	missing        []*MissingObject
+}
+
+// NewReconcileReport 创建对账报告
+func NewReconcileReport(dryRun bool, gracePeriod time.Duration, startedAt time.Time) *ReconcileReport {
+	return &ReconcileReport{
+		dryRun:      dryRun,
+		gracePeriod: gracePeriod,
+		startedAt:   startedAt,
+	}
+}
+
+// DryRun 是否为试运行
+func (r *ReconcileReport) DryRun() bool {
+	return r.dryRun
+}
+
+// GracePeriod 获取宽限期
+func (r *ReconcileReport) GracePeriod() time.Duration {
+	return r.gracePeriod
+}
+
+// StartedAt 获取开始时间
+func (r *ReconcileReport) StartedAt() time.Time {
+	return r.startedAt
+}
+
+// FinishedAt 获取结束时间
+func (r *ReconcileReport) FinishedAt() time.Time {
+	return r.finishedAt
+}
+
+// ScannedObjects 获取扫描的对象数
+func (r *ReconcileReport) ScannedObjects() int {
+	return r.scannedObjects
+}
+
+// ScannedVideos 获取扫描的视频记录数
+func (r *ReconcileReport) ScannedVideos() int {
+	return r.scannedVideos
+}
+
+// Orphans 获取没有被任何视频引用的对象
+func (r *ReconcileReport) Orphans() []*OrphanObject {
+	return r.orphans
+}
+
+// Missing 获取源文件缺失的视频
+func (r *ReconcileReport) Missing() []*MissingObject {
+	return r.missing
+}
+
+// SetScanned 记录扫描规模
+func (r *ReconcileReport) SetScanned(objects, videos int) {
+	r.scannedObjects = objects
+	r.scannedVideos = videos
+}
+
+// AddOrphan 记录孤儿对象
+func (r *ReconcileReport) AddOrphan(object vo.StoredObject, action vo.ReconcileAction, errMsg string) {
+	r.orphans = append(r.orphans, &OrphanObject{object: object, action: action, errMsg: errMsg})
+}
+
+// AddMissing 记录源文件缺失的视频
+func (r *ReconcileReport) AddMissing(video *Video, action vo.ReconcileAction, errMsg string) {
+	r.missing = append(r.missing, &MissingObject{
+		videoUuid:   video.UUID(),
+		storagePath: video.StoragePath(),
+		status:      video.Status(),
+		action:      action,
+		errMsg:      errMsg,
+	})
+}
+
+// Finish 标记对账结束
+func (r *ReconcileReport) Finish(finishedAt time.Time) {
+	r.finishedAt = finishedAt
+}
+
+// OrphanObject 孤儿对象
+type OrphanObject struct {
+	object vo.StoredObject
+	action vo.ReconcileAction
+	errMsg string
+}
+
+// Object 获取对象
+func (o *OrphanObject) Object() vo.StoredObject {
+	return o.object
+}
+
+// Action 获取处理方式
+func (o *OrphanObject) Action() vo.ReconcileAction {
+	return o.action
+}
+
+// ErrorMsg 获取处理失败原因
+func (o *OrphanObject) ErrorMsg() string {
+	return o.errMsg
+}
+
+// MissingObject 源文件缺失的视频
+type MissingObject struct {
+	videoUuid   string
+	storagePath string
+	status      vo.VideoStatus
+	action      vo.ReconcileAction
+	errMsg      string
+}
+
+// VideoUuid 获取视频UUID
+func (m *MissingObject) VideoUuid() string {
+	return m.videoUuid
+}
+
+// StoragePath 获取缺失的对象名
+func (m *MissingObject) StoragePath() string {
+	return m.storagePath
+}
+
+// Status 获取对账时的视频状态
+func (m *MissingObject) Status() vo.VideoStatus {
+	return m.status
+}
+
+// Action 获取处理方式
+func (m *MissingObject) Action() vo.ReconcileAction {
+	return m.action
+}
+
+// ErrorMsg 获取处理失败原因
+func (m *MissingObject) ErrorMsg() string {
+	return m.errMsg
+}
@@ -0,0 +1,238 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rendition 视频按码率阶梯中一档转码的输出
+type Rendition struct {
+	uuid        string
+	videoUuid   string
+	sourcePath  string
+	profile     vo.RenditionProfile
+	status      vo.RenditionStatus
+	progress    int
+	attempts    int
+	storagePath string
+	fileSize    int64
+	width       int
+	height      int
+	errorMsg    string
+	leaseUntil  *time.Time
+	startedAt   *time.Time
+	completedAt *time.Time
+	createdAt   *time.Time
+	hls         vo.HLSPlaylist
+	dash        vo.DASHPackage
+}
+
+// DefaultRendition 为视频当前的源文件创建一档等待转码的输出，对象存放在视频资源目录的 renditions/ 下
+func DefaultRendition(video *Video, profile vo.RenditionProfile) *Rendition {
+	renditionUuid := uuid.New().String()
+	return &Rendition{
+		uuid:        renditionUuid,
+		videoUuid:   video.UUID(),
+		sourcePath:  video.StoragePath(),
+		profile:     profile,
+		status:      vo.RenditionStatusPending,
+		storagePath: video.AssetPrefix() + vo.RenditionDir + renditionUuid + "/" + profile.Name() + ".mp4",
+	}
+}
+
+// NewRendition 创建转码输出（用于从数据库加载）
+func NewRendition(uuid, videoUuid, sourcePath string, profile vo.RenditionProfile, status vo.RenditionStatus,
+	progress, attempts int, storagePath string, fileSize int64, width, height int, errorMsg string,
+	leaseUntil, startedAt, completedAt, createdAt *time.Time) *Rendition {
+	return &Rendition{
+		uuid:        uuid,
+		videoUuid:   videoUuid,
+		sourcePath:  sourcePath,
+		profile:     profile,
+		status:      status,
+		progress:    progress,
+		attempts:    attempts,
+		storagePath: storagePath,
+		fileSize:    fileSize,
+		width:       width,
+		height:      height,
+		errorMsg:    errorMsg,
+		leaseUntil:  leaseUntil,
+		startedAt:   startedAt,
+		completedAt: completedAt,
+		createdAt:   createdAt,
+	}
+}
+
+// UUID 获取UUID
+func (r *Rendition) UUID() string {
+	return r.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (r *Rendition) VideoUuid() string {
+	return r.videoUuid
+}
+
+// SourcePath 获取转码时使用的源文件路径，源文件被替换后该输出不再对应当前源文件
+func (r *Rendition) SourcePath() string {
+	return r.sourcePath
+}
+
+// Profile 获取码率档位
+func (r *Rendition) Profile() vo.RenditionProfile {
+	return r.profile
+}
+
+// Status 获取状态
+func (r *Rendition) Status() vo.RenditionStatus {
+	return r.status
+}
+
+// Progress 获取转码进度（0-100）
+func (r *Rendition) Progress() int {
+	return r.progress
+}
+
+// Attempts 获取已开始转码的次数
+func (r *Rendition) Attempts() int {
+	return r.attempts
+}
+
+// StoragePath 获取输出的存储路径
+func (r *Rendition) StoragePath() string {
+	return r.storagePath
+}
+
+// FileSize 获取输出文件大小
+func (r *Rendition) FileSize() int64 {
+	return r.fileSize
+}
+
+// Width 获取输出宽度
+func (r *Rendition) Width() int {
+	return r.width
+}
+
+// Height 获取输出高度
+func (r *Rendition) Height() int {
+	return r.height
+}
+
+// ErrorMsg 获取最近一次失败或取消的原因
+func (r *Rendition) ErrorMsg() string {
+	return r.errorMsg
+}
+
+// LeaseUntil 获取租约截止时间：转码中时为执行者的租约，等待重试时为下次可领取的时间
+func (r *Rendition) LeaseUntil() *time.Time {
+	return r.leaseUntil
+}
+
+// StartedAt 获取最近一次开始转码的时间
+func (r *Rendition) StartedAt() *time.Time {
+	return r.startedAt
+}
+
+// CompletedAt 获取结束时间
+func (r *Rendition) CompletedAt() *time.Time {
+	return r.completedAt
+}
+
+// CreatedAt 获取创建时间
+func (r *Rendition) CreatedAt() *time.Time {
+	return r.createdAt
+}
+
+// HLS 获取HLS媒体播放列表，没有打包HLS时为空
+func (r *Rendition) HLS() vo.HLSPlaylist {
+	return r.hls
+}
+
+// SetHLS 设置HLS媒体播放列表
+func (r *Rendition) SetHLS(playlist vo.HLSPlaylist) {
+	r.hls = playlist
+}
+
+// HLSDir HLS分片和媒体播放列表的存储目录，与转码输出在同一目录下
+func (r *Rendition) HLSDir() string {
+	return path.Dir(r.storagePath) + "/" + vo.HLSDir
+}
+
+// DASH 获取DASH分片信息，没有打包DASH时为空
+func (r *Rendition) DASH() vo.DASHPackage {
+	return r.dash
+}
+
+// SetDASH 设置DASH分片信息
+func (r *Rendition) SetDASH(dash vo.DASHPackage) {
+	r.dash = dash
+}
+
+// DASHDir DASH初始化分片和媒体分片的存储目录，与转码输出在同一目录下
+func (r *Rendition) DASHDir() string {
+	return path.Dir(r.storagePath) + "/" + vo.DASHDir
+}
+
+// IsCurrentFor 是否由视频当前的源文件转码而来
+func (r *Rendition) IsCurrentFor(video *Video) bool {
+	return r.videoUuid == video.UUID() && r.sourcePath == video.StoragePath()
+}
+
+// Start 开始一次转码，租约到期前由当前执行者独占
+func (r *Rendition) Start(leaseUntil time.Time) {
+	now := time.Now()
+	r.status = vo.RenditionStatusRunning
+	r.attempts++
+	r.progress = 0
+	r.errorMsg = ""
+	r.leaseUntil = &leaseUntil
+	r.startedAt = &now
+}
+
+// SetProgress 更新转码进度并续租
+func (r *Rendition) SetProgress(progress int, leaseUntil time.Time) {
+	r.progress = min(max(progress, 0), 100)
+	r.leaseUntil = &leaseUntil
+}
+
+// Succeed 转码完成，记录输出的大小和分辨率
+func (r *Rendition) Succeed(info vo.MediaInfo, fileSize int64) {
+	now := time.Now()
+	r.status = vo.RenditionStatusSucceeded
+	r.progress = 100
+	r.fileSize = fileSize
+	r.width = info.Width()
+	r.height = info.Height()
+	r.errorMsg = ""
+	r.leaseUntil = nil
+	r.completedAt = &now
+}
+
+// Fail 转码失败：未达到最大次数时等待 retryDelay*已尝试次数 后重试，否则标记为失败
+func (r *Rendition) Fail(reason string, maxAttempts int, retryDelay time.Duration) {
+	now := time.Now()
+	r.errorMsg = reason
+	if r.attempts < maxAttempts {
+		retryAt := now.Add(retryDelay * time.Duration(r.attempts))
+		r.status = vo.RenditionStatusPending
+		r.progress = 0
+		r.leaseUntil = &retryAt
+		return
+	}
+	r.status = vo.RenditionStatusFailed
+	r.leaseUntil = nil
+	r.completedAt = &now
+}
+
+// Cancel 取消转码
+func (r *Rendition) Cancel(reason string) {
+	now := time.Now()
+	r.status = vo.RenditionStatusCanceled
+	r.errorMsg = reason
+	r.leaseUntil = nil
+	r.completedAt = &now
+}
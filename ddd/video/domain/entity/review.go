@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewQueueItem 审核队列条目
+type ReviewQueueItem struct {
+	video     *Video
+	claimedBy string
+	claimedAt *time.Time
+}
+
+// NewReviewQueueItem 创建审核队列条目
+func NewReviewQueueItem(video *Video, claimedBy string, claimedAt *time.Time) *ReviewQueueItem {
+	return &ReviewQueueItem{
+		video:     video,
+		claimedBy: claimedBy,
+		claimedAt: claimedAt,
+	}
+}
+
+// Video 获取待审核视频
+func (i *ReviewQueueItem) Video() *Video {
+	return i.video
+}
+
+// ClaimedBy 获取认领的审核员UUID，未认领为空
+func (i *ReviewQueueItem) ClaimedBy() string {
+	return i.claimedBy
+}
+
+// ClaimedAt 获取认领时间
+func (i *ReviewQueueItem) ClaimedAt() *time.Time {
+	return i.claimedAt
+}
+
+// IsClaimed 检查认领是否仍在有效期内
+func (i *ReviewQueueItem) IsClaimed(now time.Time, ttl time.Duration) bool {
+	return i.claimedBy != "" && i.claimedAt != nil && i.claimedAt.Add(ttl).After(now)
+}
+
+// ReviewLog 审核记录
+type ReviewLog struct {
+	uuid          string
+	videoUuid     string
+	moderatorUuid string
+	decision      vo.ReviewDecision
+	reason        string
+	createdAt     *time.Time
+}
+
+// DefaultReviewLog 创建新的审核记录
+func DefaultReviewLog(videoUuid, moderatorUuid string, decision vo.ReviewDecision, reason string) *ReviewLog {
+	return &ReviewLog{
+		uuid:          uuid.New().String(),
+		videoUuid:     videoUuid,
+		moderatorUuid: moderatorUuid,
+		decision:      decision,
+		reason:        reason,
+	}
+}
+
+// NewReviewLog 创建审核记录（用于从数据库加载）
+func NewReviewLog(uuid, videoUuid, moderatorUuid string, decision vo.ReviewDecision, reason string, createdAt *time.Time) *ReviewLog {
+	return &ReviewLog{
+		uuid:          uuid,
+		videoUuid:     videoUuid,
+		moderatorUuid: moderatorUuid,
+		decision:      decision,
+		reason:        reason,
+		createdAt:     createdAt,
+	}
+}
+
+// UUID 获取记录UUID
+func (l *ReviewLog) UUID() string {
+	return l.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (l *ReviewLog) VideoUuid() string {
+	return l.videoUuid
+}
+
+// ModeratorUuid 获取审核员UUID
+func (l *ReviewLog) ModeratorUuid() string {
+	return l.moderatorUuid
+}
+
+// Decision 获取审核结论
+func (l *ReviewLog) Decision() vo.ReviewDecision {
+	return l.decision
+}
+
+// Reason 获取审核意见
+func (l *ReviewLog) Reason() string {
+	return l.reason
+}
+
+// CreatedAt 获取审核时间
+func (l *ReviewLog) CreatedAt() *time.Time {
+	return l.createdAt
+}
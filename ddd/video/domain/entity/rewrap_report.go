@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// RewrapReport 主密钥轮换后重新包装数据密钥的报告
+type RewrapReport struct {
+	activeKeyID string
+	startedAt   time.Time
+	finishedAt  time.Time
+	counts      map[vo.RewrapOutcome]int
+	failures    []*RewrapFailure
+}
+
+// NewRewrapReport 创建重新包装报告
+func NewRewrapReport(activeKeyID string, startedAt time.Time) *RewrapReport {
+	return &RewrapReport{
+		activeKeyID: activeKeyID,
+		startedAt:   startedAt,
+		counts:      map[vo.RewrapOutcome]int{},
+	}
+}
+
+// ActiveKeyID 获取当前主密钥ID
+func (r *RewrapReport) ActiveKeyID() string {
+	return r.activeKeyID
+}
+
+// StartedAt 获取开始时间
+func (r *RewrapReport) StartedAt() time.Time {
+	return r.startedAt
+}
+
+// FinishedAt 获取结束时间
+func (r *RewrapReport) FinishedAt() time.Time {
+	return r.finishedAt
+}
+
+// Count 获取指定结果的对象数
+func (r *RewrapReport) Count(outcome vo.RewrapOutcome) int {
+	return r.counts[outcome]
+}
+
+// Failures 获取处理失败的对象
+func (r *RewrapReport) Failures() []*RewrapFailure {
+	return r.failures
+}
+
+// Add 记录一个对象的处理结果
+func (r *RewrapReport) Add(outcome vo.RewrapOutcome) {
+	r.counts[outcome]++
+}
+
+// AddFailure 记录处理失败的对象
+func (r *RewrapReport) AddFailure(objectName, errMsg string) {
+	r.failures = append(r.failures, &RewrapFailure{objectName: objectName, errMsg: errMsg})
+}
+
+// Finish 标记结束
+func (r *RewrapReport) Finish(finishedAt time.Time) {
+	r.finishedAt = finishedAt
+}
+
+// RewrapFailure 重新包装失败的对象
+type RewrapFailure struct {
+	objectName string
+	errMsg     string
+}
+
+// ObjectName 获取对象名
+func (f *RewrapFailure) ObjectName() string {
+	return f.objectName
+}
+
+// ErrorMsg 获取失败原因
+func (f *RewrapFailure) ErrorMsg() string {
+	return f.errMsg
+}
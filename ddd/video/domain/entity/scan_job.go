@@ -0,0 +1,183 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScanJob 为视频的一个源文件执行的恶意软件扫描，处理状态和重试方式与转码输出相同
+type ScanJob struct {
+	uuid           string
+	videoUuid      string
+	sourcePath     string
+	status         vo.RenditionStatus
+	attempts       int
+	errorMsg       string
+	verdict        vo.ScanVerdict
+	signature      string
+	quarantinePath string
+	leaseUntil     *time.Time
+	startedAt      *time.Time
+	completedAt    *time.Time
+	createdAt      *time.Time
+}
+
+// DefaultScanJob 为视频当前的源文件创建等待扫描的记录
+func DefaultScanJob(video *Video) *ScanJob {
+	return &ScanJob{
+		uuid:       uuid.New().String(),
+		videoUuid:  video.UUID(),
+		sourcePath: video.StoragePath(),
+		status:     vo.RenditionStatusPending,
+	}
+}
+
+// NewScanJob 创建记录（用于从数据库加载）
+func NewScanJob(uuid, videoUuid, sourcePath string, status vo.RenditionStatus, attempts int) *ScanJob {
+	return &ScanJob{
+		uuid:       uuid,
+		videoUuid:  videoUuid,
+		sourcePath: sourcePath,
+		status:     status,
+		attempts:   attempts,
+	}
+}
+
+// UUID 获取UUID
+func (j *ScanJob) UUID() string {
+	return j.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (j *ScanJob) VideoUuid() string {
+	return j.videoUuid
+}
+
+// SourcePath 获取要扫描的源文件路径
+func (j *ScanJob) SourcePath() string {
+	return j.sourcePath
+}
+
+// Status 获取处理状态
+func (j *ScanJob) Status() vo.RenditionStatus {
+	return j.status
+}
+
+// Attempts 获取已尝试次数
+func (j *ScanJob) Attempts() int {
+	return j.attempts
+}
+
+// ErrorMsg 获取最近一次失败的原因
+func (j *ScanJob) ErrorMsg() string {
+	return j.errorMsg
+}
+
+// Verdict 获取扫描结论
+func (j *ScanJob) Verdict() vo.ScanVerdict {
+	return j.verdict
+}
+
+// Signature 获取命中的特征名称，干净时为空
+func (j *ScanJob) Signature() string {
+	return j.signature
+}
+
+// QuarantinePath 获取源文件隔离后的路径，干净时为空
+func (j *ScanJob) QuarantinePath() string {
+	return j.quarantinePath
+}
+
+// LeaseUntil 获取租约到期时间，等待重试时为重试时间
+func (j *ScanJob) LeaseUntil() *time.Time {
+	return j.leaseUntil
+}
+
+// StartedAt 获取最近一次开始时间
+func (j *ScanJob) StartedAt() *time.Time {
+	return j.startedAt
+}
+
+// CompletedAt 获取完成时间
+func (j *ScanJob) CompletedAt() *time.Time {
+	return j.completedAt
+}
+
+// CreatedAt 获取创建时间
+func (j *ScanJob) CreatedAt() *time.Time {
+	return j.createdAt
+}
+
+// IsCurrentFor 是否为视频当前的源文件创建
+func (j *ScanJob) IsCurrentFor(video *Video) bool {
+	return j.videoUuid == video.UUID() && j.sourcePath == video.StoragePath()
+}
+
+// Infected 是否发现恶意软件
+func (j *ScanJob) Infected() bool {
+	return j.verdict == vo.ScanVerdictInfected
+}
+
+// Start 开始一次扫描，租约到期前由当前执行者独占
+func (j *ScanJob) Start(leaseUntil time.Time) {
+	now := time.Now()
+	j.status = vo.RenditionStatusRunning
+	j.attempts++
+	j.errorMsg = ""
+	j.leaseUntil = &leaseUntil
+	j.startedAt = &now
+}
+
+// Succeed 扫描完成，发现恶意软件时 quarantinePath 为源文件隔离后的路径
+func (j *ScanJob) Succeed(result vo.ScanResult, quarantinePath string) {
+	now := time.Now()
+	j.status = vo.RenditionStatusSucceeded
+	j.verdict = result.Verdict()
+	j.signature = result.Signature()
+	j.quarantinePath = quarantinePath
+	j.errorMsg = ""
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// Fail 扫描失败：未达到最大次数时等待 retryDelay*已尝试次数 后重试，否则标记为失败
+func (j *ScanJob) Fail(reason string, maxAttempts int, retryDelay time.Duration) {
+	now := time.Now()
+	j.errorMsg = reason
+	if j.attempts < maxAttempts {
+		retryAt := now.Add(retryDelay * time.Duration(j.attempts))
+		j.status = vo.RenditionStatusPending
+		j.leaseUntil = &retryAt
+		return
+	}
+	j.status = vo.RenditionStatusFailed
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// Cancel 源文件已被替换或视频已删除
+func (j *ScanJob) Cancel(reason string) {
+	now := time.Now()
+	j.status = vo.RenditionStatusCanceled
+	j.errorMsg = reason
+	j.leaseUntil = nil
+	j.completedAt = &now
+}
+
+// SetResult 设置扫描结果（用于从数据库加载）
+func (j *ScanJob) SetResult(verdict vo.ScanVerdict, signature, quarantinePath, errorMsg string) {
+	j.verdict = verdict
+	j.signature = signature
+	j.quarantinePath = quarantinePath
+	j.errorMsg = errorMsg
+}
+
+// SetTimes 设置租约和时间（用于从数据库加载）
+func (j *ScanJob) SetTimes(leaseUntil, startedAt, completedAt, createdAt *time.Time) {
+	j.leaseUntil = leaseUntil
+	j.startedAt = startedAt
+	j.completedAt = completedAt
+	j.createdAt = createdAt
+}
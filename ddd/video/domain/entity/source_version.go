@@ -0,0 +1,221 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SourceVersion 视频源文件版本，替换源文件时生成新版本，视频UUID和其他数据保持不变
+type SourceVersion struct {
+	uuid         string
+	videoUuid    string
+	version      int
+	storagePath  string
+	filename     string
+	fileSize     int64
+	format       string
+	checksum     vo.Checksum
+	etag         string
+	sourceSha256 string
+	status       vo.SourceVersionStatus
+	uploaderUuid string
+	keepMetadata bool
+	errorMsg     string
+	activatedAt  *time.Time
+	retainUntil  *time.Time
+	createdAt    *time.Time
+}
+
+// DefaultSourceVersion 为视频创建一个上传中的新版本，对象存放在视频资源目录的 source/ 下，
+// 版本号由仓储保存时分配；keepMetadata 为 true 时生效后不清除源文件中的元数据
+func DefaultSourceVersion(video *Video, uploaderUuid, filename string, fileSize int64, format string, keepMetadata bool) *SourceVersion {
+	versionUuid := uuid.New().String()
+	return &SourceVersion{
+		uuid:         versionUuid,
+		videoUuid:    video.UUID(),
+		storagePath:  video.AssetPrefix() + vo.SourceVersionDir + versionUuid + filepath.Ext(filename),
+		filename:     filename,
+		fileSize:     fileSize,
+		format:       format,
+		status:       vo.SourceVersionStatusUploading,
+		uploaderUuid: uploaderUuid,
+		keepMetadata: keepMetadata,
+	}
+}
+
+// InitialSourceVersion 根据视频当前的源文件生成第1个版本，用于首次替换前补录历史
+func InitialSourceVersion(video *Video) *SourceVersion {
+	now := time.Now()
+	return &SourceVersion{
+		uuid:         uuid.New().String(),
+		videoUuid:    video.UUID(),
+		version:      1,
+		storagePath:  video.StoragePath(),
+		filename:     video.Filename(),
+		fileSize:     video.FileSize(),
+		format:       video.Format(),
+		checksum:     video.Checksum(),
+		etag:         video.ETag(),
+		sourceSha256: video.SourceSHA256(),
+		status:       vo.SourceVersionStatusActive,
+		uploaderUuid: video.UserUuid(),
+		keepMetadata: video.KeepMetadata(),
+		activatedAt:  &now,
+	}
+}
+
+// NewSourceVersion 创建源文件版本（用于从数据库加载）
+func NewSourceVersion(uuid, videoUuid string, version int, storagePath, filename string, fileSize int64, format string,
+	status vo.SourceVersionStatus, uploaderUuid string) *SourceVersion {
+	return &SourceVersion{
+		uuid:         uuid,
+		videoUuid:    videoUuid,
+		version:      version,
+		storagePath:  storagePath,
+		filename:     filename,
+		fileSize:     fileSize,
+		format:       format,
+		status:       status,
+		uploaderUuid: uploaderUuid,
+	}
+}
+
+// UUID 获取版本UUID
+func (s *SourceVersion) UUID() string {
+	return s.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (s *SourceVersion) VideoUuid() string {
+	return s.videoUuid
+}
+
+// Version 获取版本号，从1开始递增
+func (s *SourceVersion) Version() int {
+	return s.version
+}
+
+// StoragePath 获取源文件对象名
+func (s *SourceVersion) StoragePath() string {
+	return s.storagePath
+}
+
+// Filename 获取上传时的文件名
+func (s *SourceVersion) Filename() string {
+	return s.filename
+}
+
+// FileSize 获取文件大小
+func (s *SourceVersion) FileSize() int64 {
+	return s.fileSize
+}
+
+// Format 获取格式
+func (s *SourceVersion) Format() string {
+	return s.format
+}
+
+// Checksum 获取校验和
+func (s *SourceVersion) Checksum() vo.Checksum {
+	return s.checksum
+}
+
+// ETag 获取存储ETag
+func (s *SourceVersion) ETag() string {
+	return s.etag
+}
+
+// SourceSHA256 获取上传时源文件的SHA-256，改写源文件后保持不变
+func (s *SourceVersion) SourceSHA256() string {
+	return s.sourceSha256
+}
+
+// Status 获取版本状态
+func (s *SourceVersion) Status() vo.SourceVersionStatus {
+	return s.status
+}
+
+// UploaderUuid 获取上传者UUID
+func (s *SourceVersion) UploaderUuid() string {
+	return s.uploaderUuid
+}
+
+// KeepMetadata 上传时是否选择保留源文件元数据
+func (s *SourceVersion) KeepMetadata() bool {
+	return s.keepMetadata
+}
+
+// ErrorMsg 获取上传失败原因
+func (s *SourceVersion) ErrorMsg() string {
+	return s.errorMsg
+}
+
+// ActivatedAt 获取最近一次生效时间
+func (s *SourceVersion) ActivatedAt() *time.Time {
+	return s.activatedAt
+}
+
+// RetainUntil 获取被替换后的保留截止时间
+func (s *SourceVersion) RetainUntil() *time.Time {
+	return s.retainUntil
+}
+
+// CreatedAt 获取创建时间
+func (s *SourceVersion) CreatedAt() *time.Time {
+	return s.createdAt
+}
+
+// CanRollback 是否可以回滚到该版本：已被替换且仍在保留期内
+func (s *SourceVersion) CanRollback(now time.Time) bool {
+	return s.status == vo.SourceVersionStatusSuperseded && (s.retainUntil == nil || now.Before(*s.retainUntil))
+}
+
+// SetVersion 设置版本号
+func (s *SourceVersion) SetVersion(version int) {
+	s.version = version
+}
+
+// SetChecksum 设置校验和和存储ETag
+func (s *SourceVersion) SetChecksum(checksum vo.Checksum, etag string) {
+	s.checksum = checksum
+	s.etag = etag
+}
+
+// SetUploadedChecksum 上传完成后设置校验和和存储ETag，上传内容的SHA-256同时记录为去重标识
+func (s *SourceVersion) SetUploadedChecksum(checksum vo.Checksum, etag string) {
+	s.SetChecksum(checksum, etag)
+	s.sourceSha256 = checksum.SHA256()
+}
+
+// SetSourceSHA256 设置上传时源文件的SHA-256（仅用于从数据库加载）
+func (s *SourceVersion) SetSourceSHA256(sha256 string) {
+	s.sourceSha256 = sha256
+}
+
+// SetKeepMetadata 设置是否保留源文件元数据（仅用于从数据库加载）
+func (s *SourceVersion) SetKeepMetadata(keepMetadata bool) {
+	s.keepMetadata = keepMetadata
+}
+
+// SetErrorMsg 设置上传失败原因（仅用于从数据库加载）
+func (s *SourceVersion) SetErrorMsg(errorMsg string) {
+	s.errorMsg = errorMsg
+}
+
+// SetActivatedAt 设置生效时间（仅用于从数据库加载）
+func (s *SourceVersion) SetActivatedAt(activatedAt *time.Time) {
+	s.activatedAt = activatedAt
+}
+
+// SetRetainUntil 设置保留截止时间（仅用于从数据库加载）
+func (s *SourceVersion) SetRetainUntil(retainUntil *time.Time) {
+	s.retainUntil = retainUntil
+}
+
+// SetCreatedAt 设置创建时间（仅用于从数据库加载）
+func (s *SourceVersion) SetCreatedAt(createdAt *time.Time) {
+	s.createdAt = createdAt
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/event"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusTransition 视频状态迁移记录
+type StatusTransition struct {
+	uuid      string
+	videoUuid string
+	from      vo.VideoStatus
+	to        vo.VideoStatus
+	cause     string
+	createdAt *time.Time
+
+	events []event.Payload
+}
+
+// DefaultStatusTransition 创建新的状态迁移记录
+func DefaultStatusTransition(videoUuid string, from, to vo.VideoStatus, cause string) *StatusTransition {
+	return &StatusTransition{
+		uuid:      uuid.New().String(),
+		videoUuid: videoUuid,
+		from:      from,
+		to:        to,
+		cause:     cause,
+	}
+}
+
+// NewStatusTransition 创建状态迁移记录（用于从数据库加载）
+func NewStatusTransition(uuid, videoUuid string, from, to vo.VideoStatus, cause string, createdAt *time.Time) *StatusTransition {
+	return &StatusTransition{
+		uuid:      uuid,
+		videoUuid: videoUuid,
+		from:      from,
+		to:        to,
+		cause:     cause,
+		createdAt: createdAt,
+	}
+}
+
+// UUID 获取记录UUID
+func (t *StatusTransition) UUID() string {
+	return t.uuid
+}
+
+// VideoUuid 获取视频UUID
+func (t *StatusTransition) VideoUuid() string {
+	return t.videoUuid
+}
+
+// From 获取迁移前状态
+func (t *StatusTransition) From() vo.VideoStatus {
+	return t.from
+}
+
+// To 获取迁移后状态
+func (t *StatusTransition) To() vo.VideoStatus {
+	return t.to
+}
+
+// Cause 获取迁移原因
+func (t *StatusTransition) Cause() string {
+	return t.cause
+}
+
+// CreatedAt 获取迁移时间
+func (t *StatusTransition) CreatedAt() *time.Time {
+	return t.createdAt
+}
+
+// Events 获取迁移产生的领域事件，与状态在同一事务中持久化
+func (t *StatusTransition) Events() []event.Payload {
+	return t.events
+}
+
+// TaskTransition 上传任务状态迁移
+type TaskTransition struct {
+	taskUuid    string
+	from        vo.VideoUploadTaskStatus
+	to          vo.VideoUploadTaskStatus
+	errorMsg    string
+	completedAt *time.Time
+}
+
+// TaskUuid 获取任务UUID
+func (t *TaskTransition) TaskUuid() string {
+	return t.taskUuid
+}
+
+// From 获取迁移前状态
+func (t *TaskTransition) From() vo.VideoUploadTaskStatus {
+	return t.from
+}
+
+// To 获取迁移后状态
+func (t *TaskTransition) To() vo.VideoUploadTaskStatus {
+	return t.to
+}
+
+// ErrorMsg 获取失败原因
+func (t *TaskTransition) ErrorMsg() string {
+	return t.errorMsg
+}
+
+// CompletedAt 获取完成时间
+func (t *TaskTransition) CompletedAt() *time.Time {
+	return t.completedAt
+}
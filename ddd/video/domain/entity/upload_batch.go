@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"go-video/ddd/video/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadBatch 批量上传，每个通过校验的文件对应一个上传任务
+type UploadBatch struct {
+	uuid          string
+	userUuid      string
+	totalFiles    int
+	rejectedFiles int
+	createdAt     *time.Time
+}
+
+// DefaultUploadBatch 创建批量上传
+func DefaultUploadBatch(userUuid string, totalFiles, rejectedFiles int) *UploadBatch {
+	return &UploadBatch{
+		uuid:          uuid.New().String(),
+		userUuid:      userUuid,
+		totalFiles:    totalFiles,
+		rejectedFiles: rejectedFiles,
+	}
+}
+
+// NewUploadBatch 创建批量上传（用于从数据库加载）
+func NewUploadBatch(uuid, userUuid string, totalFiles, rejectedFiles int, createdAt *time.Time) *UploadBatch {
+	return &UploadBatch{
+		uuid:          uuid,
+		userUuid:      userUuid,
+		totalFiles:    totalFiles,
+		rejectedFiles: rejectedFiles,
+		createdAt:     createdAt,
+	}
+}
+
+// UUID 获取批量上传UUID
+func (b *UploadBatch) UUID() string {
+	return b.uuid
+}
+
+// UserUuid 获取用户UUID
+func (b *UploadBatch) UserUuid() string {
+	return b.userUuid
+}
+
+// TotalFiles 获取请求中的文件数
+func (b *UploadBatch) TotalFiles() int {
+	return b.totalFiles
+}
+
+// RejectedFiles 获取校验未通过的文件数
+func (b *UploadBatch) RejectedFiles() int {
+	return b.rejectedFiles
+}
+
+// CreatedAt 获取创建时间
+func (b *UploadBatch) CreatedAt() *time.Time {
+	return b.createdAt
+}
+
+// IsOwnedBy 检查批量上传是否属于指定用户
+func (b *UploadBatch) IsOwnedBy(userUuid string) bool {
+	return userUuid != "" && b.userUuid == userUuid
+}
+
+// UploadBatchProgress 批量上传进度，按子任务状态汇总
+type UploadBatchProgress struct {
+	batch     *UploadBatch
+	items     []*VideoEntity
+	pending   int
+	completed int
+	failed    int
+}
+
+// NewUploadBatchProgress 根据子任务汇总批量上传进度
+func NewUploadBatchProgress(batch *UploadBatch, items []*VideoEntity) *UploadBatchProgress {
+	progress := &UploadBatchProgress{
+		batch: batch,
+		items: items,
+	}
+	for _, item := range items {
+		switch {
+		case item.VideoUploadTask().IsCompleted():
+			progress.completed++
+		case item.VideoUploadTask().IsFailed():
+			progress.failed++
+		default:
+			progress.pending++
+		}
+	}
+	return progress
+}
+
+// Batch 获取批量上传
+func (p *UploadBatchProgress) Batch() *UploadBatch {
+	return p.batch
+}
+
+// Items 获取子任务及其视频
+func (p *UploadBatchProgress) Items() []*VideoEntity {
+	return p.items
+}
+
+// Pending 获取尚未结束的任务数
+func (p *UploadBatchProgress) Pending() int {
+	return p.pending
+}
+
+// Completed 获取上传成功的任务数
+func (p *UploadBatchProgress) Completed() int {
+	return p.completed
+}
+
+// Failed 获取上传失败的任务数
+func (p *UploadBatchProgress) Failed() int {
+	return p.failed
+}
+
+// Status 汇总状态：有任务未结束时为processing，全部成功为completed，全部失败为failed，否则为partial
+func (p *UploadBatchProgress) Status() vo.UploadBatchStatus {
+	switch {
+	case p.pending > 0:
+		return vo.UploadBatchStatusProcessing
+	case p.failed == 0 && p.batch.rejectedFiles == 0:
+		return vo.UploadBatchStatusCompleted
+	case p.completed == 0:
+		return vo.UploadBatchStatusFailed
+	default:
+		return vo.UploadBatchStatusPartial
+	}
+}
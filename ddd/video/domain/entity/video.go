@@ -1,7 +1,9 @@
 package entity
 
 import (
+	"fmt"
 	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/event"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,24 @@ type Video struct {
 	format      string
 	storagePath string
 	status      vo.VideoStatus
+	duration    time.Duration
+	createdAt   *time.Time
+	checksum    vo.Checksum
+	etag        string
+	// sourceSha256 上传时源文件的SHA-256，改写源文件后保持不变，作为去重标识
+	sourceSha256 string
+
+	reviewStatus vo.ReviewStatus
+	reviewReason string
+
+	tags []string
+
+	posterPath string
+
+	keepMetadata     bool
+	strippedMetadata []string
+
+	event.Recorder
 }
 
 // VideoStatus 视频状态
@@ -33,6 +53,8 @@ func DefaultVideo(userUuid, title, description, filename string, fileSize int64,
 		format:      format,
 		storagePath: storagePath,
 		status:      status,
+
+		reviewStatus: vo.ReviewStatusNone,
 	}
 }
 
@@ -47,6 +69,8 @@ func NewVideo(uuid, userUuid string, title, description, filename string, fileSi
 		fileSize:    fileSize,
 		format:      format,
 		status:      status,
+
+		reviewStatus: vo.ReviewStatusNone,
 	}
 }
 
@@ -95,6 +119,81 @@ func (v *Video) Status() vo.VideoStatus {
 	return v.status
 }
 
+// Duration 获取探测到的视频时长，0表示未知
+func (v *Video) Duration() time.Duration {
+	return v.duration
+}
+
+// Checksum 获取源文件当前内容的校验和，改写源文件后随之更新
+func (v *Video) Checksum() vo.Checksum {
+	return v.checksum
+}
+
+// SourceSHA256 获取上传时源文件的SHA-256，用于去重
+func (v *Video) SourceSHA256() string {
+	return v.sourceSha256
+}
+
+// ETag 获取源文件在存储中的ETag
+func (v *Video) ETag() string {
+	return v.etag
+}
+
+// CreatedAt 获取创建时间，新建未保存的视频为nil
+func (v *Video) CreatedAt() *time.Time {
+	return v.createdAt
+}
+
+// Tags 获取标签
+func (v *Video) Tags() []string {
+	return v.tags
+}
+
+// PosterPath 获取封面图的存储路径，还没有封面时为空
+func (v *Video) PosterPath() string {
+	return v.posterPath
+}
+
+// KeepMetadata 上传时是否选择保留源文件中的位置、设备等元数据
+func (v *Video) KeepMetadata() bool {
+	return v.keepMetadata
+}
+
+// StrippedMetadata 获取从源文件中清除的元数据，例如 moov/udta/©xyz
+func (v *Video) StrippedMetadata() []string {
+	return v.strippedMetadata
+}
+
+// ReviewStatus 获取审核状态
+func (v *Video) ReviewStatus() vo.ReviewStatus {
+	return v.reviewStatus
+}
+
+// ReviewReason 获取审核意见（拒绝原因）
+func (v *Video) ReviewReason() string {
+	return v.reviewReason
+}
+
+// IsPublic 检查视频是否可以公开展示：可播放且无需审核或审核通过
+func (v *Video) IsPublic() bool {
+	return v.status.IsPlayable() && v.reviewStatus.IsPublic()
+}
+
+// IsVisibleTo 检查视频对指定用户是否可见，所有者总是可见
+func (v *Video) IsVisibleTo(userUuid string) bool {
+	return v.reviewStatus.IsPublic() || v.IsOwnedBy(userUuid)
+}
+
+// AssetPrefix 获取视频衍生资源（字幕等）的存储目录，与源文件版本无关
+func (v *Video) AssetPrefix() string {
+	return fmt.Sprintf("videos/%s/%s/", v.userUuid, v.uuid)
+}
+
+// IsOwnedBy 检查视频是否属于指定用户
+func (v *Video) IsOwnedBy(userUuid string) bool {
+	return userUuid != "" && v.userUuid == userUuid
+}
+
 // SetUUID 设置UUID
 func (v *Video) SetUUID(uuid string) {
 	v.uuid = uuid
@@ -115,9 +214,149 @@ func (v *Video) SetStoragePath(path string) {
 	v.storagePath = path
 }
 
-// SetStatus 设置状态
-func (v *Video) SetStatus(status vo.VideoStatus) {
-	v.status = status
+// UseSource 切换到指定的源文件版本，返回切换前的存储路径。是否保留元数据随版本切换，
+// 清除的元数据在新的源文件改写后重新记录
+func (v *Video) UseSource(version *SourceVersion) string {
+	previous := v.storagePath
+	v.storagePath = version.StoragePath()
+	v.filename = version.Filename()
+	v.fileSize = version.FileSize()
+	v.format = version.Format()
+	v.checksum = version.Checksum()
+	v.etag = version.ETag()
+	v.sourceSha256 = version.SourceSHA256()
+	v.keepMetadata = version.KeepMetadata()
+	v.strippedMetadata = nil
+	return previous
+}
+
+// RewriteSource 源文件在原存储路径被改写（faststart、清除元数据等）后更新大小、校验和、ETag和清除的元数据，返回改写前的ETag。
+// 上传时的SHA-256不变，同一文件再次导入时仍能去重
+func (v *Video) RewriteSource(fileSize int64, checksum vo.Checksum, etag string, strippedMetadata []string) string {
+	previous := v.etag
+	v.fileSize = fileSize
+	v.checksum = checksum
+	v.etag = etag
+	v.strippedMetadata = strippedMetadata
+	return previous
+}
+
+// TransitionTo 按状态机迁移到目标状态，返回需要持久化的迁移记录。
+// 非法迁移或不满足守卫条件时返回 *vo.TransitionError，实体状态保持不变
+func (v *Video) TransitionTo(to vo.VideoStatus, cause string) (*StatusTransition, error) {
+	from := v.status
+	if !from.CanTransitionTo(to) {
+		return nil, v.transitionError(to, "transition not allowed")
+	}
+	switch to {
+	case vo.VideoStatusUploaded, vo.VideoStatusReady:
+		if v.storagePath == "" {
+			return nil, v.transitionError(to, "source object is missing")
+		}
+	case vo.VideoStatusFailed, vo.VideoStatusBlocked:
+		if cause == "" {
+			return nil, v.transitionError(to, "cause is required")
+		}
+	}
+	v.status = to
+	switch {
+	case to == vo.VideoStatusUploaded:
+		v.Record(&event.VideoUploaded{
+			VideoUUID: v.uuid,
+			UserUUID:  v.userUuid,
+			Title:     v.title,
+			FileSize:  v.fileSize,
+			Format:    v.format,
+			SHA256:    v.checksum.SHA256(),
+		})
+	case to == vo.VideoStatusFailed && from == vo.VideoStatusUploading:
+		v.Record(&event.VideoUploadFailed{
+			VideoUUID: v.uuid,
+			UserUUID:  v.userUuid,
+			Title:     v.title,
+			Reason:    cause,
+		})
+	}
+	transition := DefaultStatusTransition(v.uuid, from, to, cause)
+	transition.events = v.PullEvents()
+	return transition, nil
+}
+
+// Delete 标记视频被删除，记录删除事件，由仓储在删除记录的同一事务中写入发件箱
+func (v *Video) Delete() {
+	v.Record(&event.VideoDeleted{
+		VideoUUID: v.uuid,
+		UserUUID:  v.userUuid,
+		Title:     v.title,
+	})
+}
+
+func (v *Video) transitionError(to vo.VideoStatus, reason string) error {
+	return &vo.TransitionError{
+		Subject: "video",
+		ID:      v.uuid,
+		From:    v.status.Value(),
+		To:      to.Value(),
+		Reason:  reason,
+	}
+}
+
+// SetReview 设置审核状态和审核意见
+func (v *Video) SetReview(status vo.ReviewStatus, reason string) {
+	v.reviewStatus = status
+	v.reviewReason = reason
+}
+
+// Hide 下架视频：不再公开展示，所有者可以看到原因
+func (v *Video) Hide(reason string) {
+	v.SetReview(vo.ReviewStatusRejected, reason)
+}
+
+// SetChecksum 设置源文件校验和和存储ETag
+func (v *Video) SetChecksum(checksum vo.Checksum, etag string) {
+	v.checksum = checksum
+	v.etag = etag
+}
+
+// SetUploadedChecksum 上传完成后设置校验和和存储ETag，上传内容的SHA-256同时记录为去重标识
+func (v *Video) SetUploadedChecksum(checksum vo.Checksum, etag string) {
+	v.SetChecksum(checksum, etag)
+	v.sourceSha256 = checksum.SHA256()
+}
+
+// SetSourceSHA256 设置上传时源文件的SHA-256（仅用于从数据库加载）
+func (v *Video) SetSourceSHA256(sha256 string) {
+	v.sourceSha256 = sha256
+}
+
+// SetTags 设置标签
+func (v *Video) SetTags(tags []string) {
+	v.tags = tags
+}
+
+// SetPosterPath 设置封面图的存储路径
+func (v *Video) SetPosterPath(posterPath string) {
+	v.posterPath = posterPath
+}
+
+// SetKeepMetadata 设置是否保留源文件中的元数据，默认清除
+func (v *Video) SetKeepMetadata(keepMetadata bool) {
+	v.keepMetadata = keepMetadata
+}
+
+// SetStrippedMetadata 设置从源文件中清除的元数据
+func (v *Video) SetStrippedMetadata(strippedMetadata []string) {
+	v.strippedMetadata = strippedMetadata
+}
+
+// SetCreatedAt 设置创建时间（从数据库加载，或导入历史视频时保留原始创建时间）
+func (v *Video) SetCreatedAt(createdAt *time.Time) {
+	v.createdAt = createdAt
+}
+
+// SetDuration 设置视频时长
+func (v *Video) SetDuration(duration time.Duration) {
+	v.duration = duration
 }
 
 type VideoUploadTaskEntity struct {
@@ -128,6 +367,13 @@ type VideoUploadTaskEntity struct {
 	errorMsg    string
 	completedAt *time.Time
 	storagePath string
+	checksum    vo.Checksum
+	etag        string
+	batchUuid   string
+
+	sourceUrl     string
+	bytesReceived int64
+	bytesTotal    int64
 }
 
 func DefaultVideoUploadTaskEntity(userUuid, videoUuid string,
@@ -173,6 +419,11 @@ func (v *VideoUploadTaskEntity) UserUuid() string {
 	return v.userUuid
 }
 
+// VideoUuid 获取视频UUID
+func (v *VideoUploadTaskEntity) VideoUuid() string {
+	return v.videoUuid
+}
+
 // Status 获取任务状态
 func (v *VideoUploadTaskEntity) Status() vo.VideoUploadTaskStatus {
 	return v.status
@@ -193,6 +444,58 @@ func (v *VideoUploadTaskEntity) ObjectName() string {
 	return v.storagePath
 }
 
+// Checksum 获取上传时计算的校验和
+func (v *VideoUploadTaskEntity) Checksum() vo.Checksum {
+	return v.checksum
+}
+
+// ETag 获取存储ETag
+func (v *VideoUploadTaskEntity) ETag() string {
+	return v.etag
+}
+
+// SetChecksum 设置校验和和存储ETag
+func (v *VideoUploadTaskEntity) SetChecksum(checksum vo.Checksum, etag string) {
+	v.checksum = checksum
+	v.etag = etag
+}
+
+// BatchUuid 获取所属批量上传的UUID，单个上传为空
+func (v *VideoUploadTaskEntity) BatchUuid() string {
+	return v.batchUuid
+}
+
+// SetBatchUuid 设置所属批量上传
+func (v *VideoUploadTaskEntity) SetBatchUuid(batchUuid string) {
+	v.batchUuid = batchUuid
+}
+
+// SourceURL 获取远程导入的源地址，本地上传为空
+func (v *VideoUploadTaskEntity) SourceURL() string {
+	return v.sourceUrl
+}
+
+// SetSourceURL 设置远程导入的源地址
+func (v *VideoUploadTaskEntity) SetSourceURL(sourceUrl string) {
+	v.sourceUrl = sourceUrl
+}
+
+// BytesReceived 获取已接收字节数
+func (v *VideoUploadTaskEntity) BytesReceived() int64 {
+	return v.bytesReceived
+}
+
+// BytesTotal 获取总字节数，-1表示未知
+func (v *VideoUploadTaskEntity) BytesTotal() int64 {
+	return v.bytesTotal
+}
+
+// SetProgress 设置传输进度
+func (v *VideoUploadTaskEntity) SetProgress(received, total int64) {
+	v.bytesReceived = received
+	v.bytesTotal = total
+}
+
 // SetUUID 设置任务UUID（仅用于从数据库加载）
 func (v *VideoUploadTaskEntity) SetUUID(uuid string) {
 	v.uuid = uuid
@@ -208,8 +511,36 @@ func (v *VideoUploadTaskEntity) SetCompletedAt(completedAt *time.Time) {
 	v.completedAt = completedAt
 }
 
-func (v *VideoUploadTaskEntity) SetStatus(status vo.VideoUploadTaskStatus) {
-	v.status = status
+// SetVideoUuid 设置视频UUID（仅用于从数据库加载）
+func (v *VideoUploadTaskEntity) SetVideoUuid(videoUuid string) {
+	v.videoUuid = videoUuid
+}
+
+// TransitionTo 按状态机迁移任务状态，非法迁移时返回 *vo.TransitionError
+func (v *VideoUploadTaskEntity) TransitionTo(to vo.VideoUploadTaskStatus, errorMsg string) (*TaskTransition, error) {
+	from := v.status
+	if !from.CanTransitionTo(to) {
+		return nil, &vo.TransitionError{
+			Subject: "upload_task",
+			ID:      v.uuid,
+			From:    from.Value(),
+			To:      to.Value(),
+			Reason:  "transition not allowed",
+		}
+	}
+	v.status = to
+	v.errorMsg = errorMsg
+	if to == vo.VideoUploadTaskStatusCompleted {
+		now := time.Now()
+		v.completedAt = &now
+	}
+	return &TaskTransition{
+		taskUuid:    v.uuid,
+		from:        from,
+		to:          to,
+		errorMsg:    errorMsg,
+		completedAt: v.completedAt,
+	}, nil
 }
 
 // IsCompleted 检查是否已完成
@@ -230,6 +561,7 @@ func (v *VideoUploadTaskEntity) IsInProgress() bool {
 type VideoEntity struct {
 	videoUploadTask *VideoUploadTaskEntity
 	video           *Video
+	processing      *vo.ProcessingProgress
 }
 
 func NewVideoEntity(video *Video, videoUploadTask *VideoUploadTaskEntity) *VideoEntity {
@@ -244,3 +576,13 @@ func (v *VideoEntity) Video() *Video {
 func (v *VideoEntity) VideoUploadTask() *VideoUploadTaskEntity {
 	return v.videoUploadTask
 }
+
+// Processing 获取上传完成后的处理进度，还没有处理流水线时为nil
+func (v *VideoEntity) Processing() *vo.ProcessingProgress {
+	return v.processing
+}
+
+// SetProcessing 设置由处理流水线汇总的处理进度
+func (v *VideoEntity) SetProcessing(processing *vo.ProcessingProgress) {
+	v.processing = processing
+}
@@ -3,25 +3,65 @@ package gateway
 import (
 	"context"
 	"go-video/ddd/video/domain/vo"
+	"io"
 	"mime/multipart"
+	"time"
 )
 
 // MinioService MinIO服务接口
 type MinioService interface {
 	// UploadVideo 上传视频文件
-	UploadVideo(ctx context.Context, userUUID string, file *multipart.FileHeader) (string, error)
+	// 上传时同时计算校验和，与客户端提供的校验和不一致时删除对象并返回 *vo.ChecksumMismatchError
+	UploadVideo(ctx context.Context, userUUID string, file *multipart.FileHeader, expected vo.Checksum) (vo.UploadReceipt, error)
 
-	// DownloadVideo 下载视频文件
+	// PutObject 上传任意对象（字幕等衍生资源），size为-1时表示长度未知
+	PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+
+	// PutMediaObject 上传包含视频画面的衍生对象（封面、缩略图、直播分片），开启加密时与源文件一样加密，size为-1时表示长度未知
+	PutMediaObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+
+	// DownloadVideo 下载视频文件，加密对象透明解密
 	DownloadVideo(ctx context.Context, objectName string) ([]byte, error)
 
+	// StatObject 获取对象的明文属性
+	StatObject(ctx context.Context, objectName string) (vo.ObjectStat, error)
+
+	// OpenObject 读取对象明文 [offset, offset+length)，加密对象透明解密
+	OpenObject(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error)
+
 	// DeleteVideo 删除视频文件
 	DeleteVideo(ctx context.Context, objectName string) error
 
-	// GetVideoURL 获取视频访问URL
+	// MoveObject 把对象移到 dst（服务端复制后删除原对象），加密对象的信封随元数据一起保留
+	MoveObject(ctx context.Context, src, dst string) error
+
+	// GetVideoURL 获取对象的预签名访问URL，加密对象通过该地址只能拿到密文
 	GetVideoURL(ctx context.Context, objectName string) (string, error)
 
+	// GetObjectURL 获取指定有效期的预签名访问URL，加密对象通过该地址只能拿到密文
+	GetObjectURL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
+
+	// ListObjects 递归列出指定前缀下的全部对象
+	ListObjects(ctx context.Context, prefix string) ([]vo.StoredObject, error)
+
+	// ActiveKeyID 当前主密钥ID，未配置加密时为空
+	ActiveKeyID() string
+
+	// RewrapObjectKey 用当前主密钥重新包装对象的数据密钥，用于主密钥轮换
+	RewrapObjectKey(ctx context.Context, objectName string) (vo.RewrapOutcome, error)
+
+	// WrapKey 用当前主密钥包装存放在对象之外的密钥（HLS内容密钥等），未配置主密钥时返回错误
+	WrapKey(key []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey 用指定主密钥解开 WrapKey 包装的密钥
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+
 	// GenerateObjectName 生成文件路径
 	GenerateObjectName(userUUID, filename string) string
 
-	SyncUploadVideo(ctx context.Context, videoUploadVo *vo.VideoUploadVO)
+	// SyncUploadVideo 上传源文件到对象存储并校验校验和，状态迁移由调用方负责
+	SyncUploadVideo(ctx context.Context, videoUploadVo *vo.VideoUploadVO) (vo.UploadReceipt, error)
+
+	// UploadStream 以流的方式上传源文件（远程导入等）并校验校验和，size为-1时表示长度未知
+	UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64, expected vo.Checksum) (vo.UploadReceipt, error)
 }
@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/video/domain/vo"
+	"io"
+)
+
+// RemoteFetcher 远程文件下载接口
+type RemoteFetcher interface {
+	// CheckURL 按访问策略校验URL，不发起请求；不允许访问时返回 *netguard.DeniedError
+	CheckURL(rawURL string) error
+
+	// Open 请求远程文件，返回的响应体读取超过大小上限时返回 vo.ErrRemoteFileTooLarge，调用方负责关闭
+	Open(ctx context.Context, rawURL string) (vo.RemoteFile, io.ReadCloser, error)
+}
@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/video/domain/vo"
+	"io"
+)
+
+// ErrScanTooLarge 内容超过扫描服务允许的大小，重试也不会成功
+var ErrScanTooLarge = errors.New("content exceeds scanner size limit")
+
+// Scanner 恶意软件扫描
+type Scanner interface {
+	// Scan 扫描reader中的全部内容，ctx取消时中止扫描并返回错误；
+	// 发现恶意软件不是错误，以结果中的结论和特征名称返回
+	Scan(ctx context.Context, reader io.Reader) (vo.ScanResult, error)
+}
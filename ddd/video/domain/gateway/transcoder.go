@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// Transcoder 视频转码，输入输出都是本地文件
+type Transcoder interface {
+	// Probe 读取视频文件的时长、分辨率和编码
+	Probe(ctx context.Context, input string) (vo.MediaInfo, error)
+
+	// Transcode 按档位把视频转码为mp4写入output，转码过程中以已处理的时长回调progress，
+	// ctx取消时终止转码并返回错误；返回输出文件的媒体信息
+	Transcode(ctx context.Context, input, output string, profile vo.RenditionProfile, progress func(processed time.Duration)) (vo.MediaInfo, error)
+
+	// Segment 不重新编码，把Transcode输出的mp4在关键帧处切分为约segmentDuration长的MPEG-TS分片写入outputDir，
+	// 按播放顺序返回分片
+	Segment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.MediaSegment, error)
+
+	// Fragment 不重新编码，把Transcode输出的mp4中的视频和音频（没有音频时只有视频）分别切分为约segmentDuration长的fMP4分片写入outputDir，
+	// 分片按 vo.DASHInitialization、vo.DASHSegment 命名，返回各轨道的媒体分片
+	Fragment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.FragmentedTrack, error)
+
+	// Snapshot 截取 offset 处的一帧，按比例缩放到 width 宽后写入JPEG文件output
+	Snapshot(ctx context.Context, input, output string, offset time.Duration, width int) error
+
+	// Thumbnails 从头开始每隔 interval 截取一帧，按比例缩放到 width 宽后写入outputDir下的JPEG文件，
+	// 按时间顺序返回文件名，第i张对应 [i*interval, (i+1)*interval)
+	Thumbnails(ctx context.Context, input, outputDir string, interval time.Duration, width int) ([]string, error)
+}
@@ -0,0 +1,9 @@
+package gateway
+
+import "context"
+
+// UserGateway 用户上下文防腐层
+type UserGateway interface {
+	// FindUUIDByUsername 根据用户名查找用户UUID，用户不存在时返回空字符串
+	FindUUIDByUsername(ctx context.Context, username string) (string, error)
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// ArtworkRepository 封面候选帧和缩略图仓储接口
+type ArtworkRepository interface {
+	// Create 保存等待生成的记录
+	Create(ctx context.Context, artwork *entity.Artwork) error
+	// FindLatest 查找视频最新的记录，不存在时返回nil
+	FindLatest(ctx context.Context, videoUUID string) (*entity.Artwork, error)
+	// FindLatestSucceeded 查找视频最新的已完成记录，不存在时返回nil
+	FindLatestSucceeded(ctx context.Context, videoUUID string) (*entity.Artwork, error)
+	// FindByVideoUUID 查找视频的全部记录，新建的在前
+	FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.Artwork, error)
+	// FindClaimable 查找等待生成或租约已过期的记录
+	FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.Artwork, error)
+	// Claim 以比较并交换的方式领取，成功后实体进入生成中
+	Claim(ctx context.Context, artwork *entity.Artwork, leaseUntil time.Time) (bool, error)
+	// Finish 记录一次生成的结果，已不再由本次执行持有时返回false
+	Finish(ctx context.Context, artwork *entity.Artwork) (bool, error)
+	// MarkSuperseded 已完成的记录标记为被新的记录替换
+	MarkSuperseded(ctx context.Context, artworkUUID string) error
+	// CancelActive 取消视频全部未完成的记录，返回取消的数量
+	CancelActive(ctx context.Context, videoUUID, reason string) (int64, error)
+}
@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+)
+
+// CaptionRepository 字幕轨道仓储接口
+type CaptionRepository interface {
+	// Save 保存字幕轨道，默认字幕会取消同一视频下其他字幕的默认标记
+	Save(ctx context.Context, caption *entity.CaptionTrack) error
+	// FindByUUID 根据UUID查找字幕轨道，不存在时返回nil
+	FindByUUID(ctx context.Context, captionUUID string) (*entity.CaptionTrack, error)
+	// FindByVideoUUID 查找视频的全部字幕轨道
+	FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.CaptionTrack, error)
+	// Delete 删除字幕轨道
+	Delete(ctx context.Context, captionUUID string) error
+}
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/vo"
+)
+
+// ChapterRepository 视频章节仓储接口
+type ChapterRepository interface {
+	// Replace 替换视频的显式章节，传入空列表表示清除
+	Replace(ctx context.Context, videoUUID string, chapters []vo.Chapter) error
+	// FindByVideoUUID 查找视频的显式章节
+	FindByVideoUUID(ctx context.Context, videoUUID string) ([]vo.Chapter, error)
+}
@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+)
+
+// ContentKeyRepository HLS内容密钥仓储接口
+type ContentKeyRepository interface {
+	// Create 保存内容密钥，同一视频同一序号的密钥已存在时不写入并返回false
+	Create(ctx context.Context, key *entity.ContentKey) (bool, error)
+	// FindByUUID 根据UUID查找内容密钥，不存在时返回nil
+	FindByUUID(ctx context.Context, keyUUID string) (*entity.ContentKey, error)
+	// FindBySequence 查找视频指定序号的内容密钥，不存在时返回nil
+	FindBySequence(ctx context.Context, videoUUID string, sequence int) (*entity.ContentKey, error)
+	// FindNotWrappedWith 按UUID顺序查找UUID大于afterUUID、不是用指定主密钥包装的内容密钥
+	FindNotWrappedWith(ctx context.Context, masterKeyID, afterUUID string, limit int) ([]*entity.ContentKey, error)
+	// UpdateWrappedKey 保存重新包装的密钥，密钥已被并发修改（包装的主密钥不再是previousKeyID）时返回false
+	UpdateWrappedKey(ctx context.Context, key *entity.ContentKey, previousKeyID string) (bool, error)
+	// SaveAccess 保存获取密钥的审计记录
+	SaveAccess(ctx context.Context, access *entity.KeyAccess) error
+}
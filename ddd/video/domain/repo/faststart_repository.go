@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// FaststartRepository 源文件 faststart 改写记录仓储接口
+type FaststartRepository interface {
+	// Create 保存等待处理的记录
+	Create(ctx context.Context, job *entity.FaststartJob) error
+	// FindLatest 查找视频最新的记录，不存在时返回nil
+	FindLatest(ctx context.Context, videoUUID string) (*entity.FaststartJob, error)
+	// FindClaimable 查找等待处理或租约已过期的记录
+	FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.FaststartJob, error)
+	// Claim 以比较并交换的方式领取，成功后实体进入处理中
+	Claim(ctx context.Context, job *entity.FaststartJob, leaseUntil time.Time) (bool, error)
+	// Finish 记录一次处理的结果，已不再由本次执行持有时返回false
+	Finish(ctx context.Context, job *entity.FaststartJob) (bool, error)
+	// CancelActive 取消视频全部未完成的记录，返回取消的数量
+	CancelActive(ctx context.Context, videoUUID, reason string) (int64, error)
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// LiveStreamRepository 直播和直播分片仓储接口
+type LiveStreamRepository interface {
+	// Create 保存新建的直播
+	Create(ctx context.Context, stream *entity.LiveStream) error
+	// FindByUUID 根据UUID查找直播，不存在时返回nil
+	FindByUUID(ctx context.Context, streamUUID string) (*entity.LiveStream, error)
+	// FindByStreamKeyHash 根据推流密钥的哈希查找直播，不存在时返回nil
+	FindByStreamKeyHash(ctx context.Context, streamKeyHash string) (*entity.LiveStream, error)
+	// FindByUser 查找用户创建的全部直播，新建的在前
+	FindByUser(ctx context.Context, userUUID string) ([]*entity.LiveStream, error)
+	// FindIdle 查找最近一次推流早于cutoff的直播中的直播
+	FindIdle(ctx context.Context, cutoff time.Time, limit int) ([]*entity.LiveStream, error)
+	// UpdateStatus 以比较并交换的方式保存直播状态（开始或结束），状态已不是from时返回false
+	UpdateStatus(ctx context.Context, stream *entity.LiveStream, from vo.LiveStreamStatus) (bool, error)
+	// TouchIngest 记录最近一次推流的时间
+	TouchIngest(ctx context.Context, streamUUID string, at time.Time) error
+	// FindConvertible 查找等待转换或转换租约已过期的已结束直播
+	FindConvertible(ctx context.Context, now time.Time, limit int) ([]*entity.LiveStream, error)
+	// ClaimConversion 以比较并交换的方式领取转换，成功后实体进入转换中
+	ClaimConversion(ctx context.Context, stream *entity.LiveStream, leaseUntil time.Time) (bool, error)
+	// SaveConversion 保存转换的进展和结果，已不再由本次执行持有时返回false
+	SaveConversion(ctx context.Context, stream *entity.LiveStream) (bool, error)
+
+	// CreateSegment 登记分片，同一序列号已被登记时返回false
+	CreateSegment(ctx context.Context, segment *entity.LiveSegment) (bool, error)
+	// FindLastSegments 查找直播最后的limit个分片，按序列号升序
+	FindLastSegments(ctx context.Context, streamUUID string, limit int) ([]*entity.LiveSegment, error)
+	// FindSegments 查找直播的全部分片，按序列号升序
+	FindSegments(ctx context.Context, streamUUID string) ([]*entity.LiveSegment, error)
+}
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// PipelineRepository 视频处理流水线仓储接口
+type PipelineRepository interface {
+	// Create 保存流水线的全部步骤
+	Create(ctx context.Context, pipeline *entity.Pipeline) error
+	// FindLatest 查找视频最新的流水线，不存在时返回nil
+	FindLatest(ctx context.Context, videoUUID string) (*entity.Pipeline, error)
+	// FindDueVideos 查找有执行中步骤或已到执行时间的等待中步骤的视频
+	FindDueVideos(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// Update 以比较并交换的方式保存步骤，状态和执行次数与 fromStatus、fromAttempts 一致才更新
+	Update(ctx context.Context, step *entity.PipelineStep, fromStatus string, fromAttempts int) (bool, error)
+	// CancelActive 取消视频全部未完成的步骤，返回取消的数量
+	CancelActive(ctx context.Context, videoUUID, reason string) (int64, error)
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// RenditionRepository 转码输出仓储接口
+type RenditionRepository interface {
+	// SaveAll 保存新建的转码输出
+	SaveAll(ctx context.Context, renditions []*entity.Rendition) error
+	// FindByUUID 根据UUID查找转码输出，不存在时返回nil
+	FindByUUID(ctx context.Context, renditionUUID string) (*entity.Rendition, error)
+	// FindByVideoUUID 查找视频的全部转码输出，新建的在前
+	FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.Rendition, error)
+	// FindClaimable 查找可以领取的转码输出：等待中且已到重试时间，或转码中但执行者的租约已过期
+	FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.Rendition, error)
+	// Claim 以比较并交换的方式领取转码输出，成功时实体进入转码中；已被其他执行者领取时返回false
+	Claim(ctx context.Context, rendition *entity.Rendition, leaseUntil time.Time) (bool, error)
+	// UpdateProgress 记录转码进度并续租；转码已被取消或被其他执行者接手时返回false
+	UpdateProgress(ctx context.Context, rendition *entity.Rendition) (bool, error)
+	// Finish 记录一次转码的结果；转码已被取消或被其他执行者接手时返回false
+	Finish(ctx context.Context, rendition *entity.Rendition) (bool, error)
+	// CancelActive 取消视频全部等待中和转码中的输出，返回取消的数量
+	CancelActive(ctx context.Context, videoUUID, reason string) (int64, error)
+	// MarkSuperseded 已完成的输出标记为被替换
+	MarkSuperseded(ctx context.Context, renditionUUID string) error
+}
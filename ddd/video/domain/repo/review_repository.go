@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/vo"
+	"time"
+)
+
+// ReviewRepository 视频审核仓储接口
+type ReviewRepository interface {
+	// FindQueue 分页查找审核队列（上传完成且待审核的视频）
+	FindQueue(ctx context.Context, page *vo.Page) ([]*entity.ReviewQueueItem, int64, error)
+	// Claim 认领审核任务，已被他人认领且未过期时返回false
+	Claim(ctx context.Context, videoUUID, moderatorUUID string, ttl time.Duration) (bool, error)
+	// Decide 保存审核结论，审核员未持有有效认领时返回false
+	Decide(ctx context.Context, reviewLog *entity.ReviewLog, ttl time.Duration) (bool, error)
+	// FindLogsByVideoUUID 查找视频的审核记录
+	FindLogsByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.ReviewLog, error)
+}
@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// ScanRepository 源文件恶意软件扫描记录仓储接口
+type ScanRepository interface {
+	// Create 保存等待扫描的记录
+	Create(ctx context.Context, job *entity.ScanJob) error
+	// FindLatest 查找视频最新的记录，不存在时返回nil
+	FindLatest(ctx context.Context, videoUUID string) (*entity.ScanJob, error)
+	// FindClaimable 查找等待扫描或租约已过期的记录
+	FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.ScanJob, error)
+	// Claim 以比较并交换的方式领取，成功后实体进入处理中
+	Claim(ctx context.Context, job *entity.ScanJob, leaseUntil time.Time) (bool, error)
+	// Finish 记录一次扫描的结果，已不再由本次执行持有时返回false
+	Finish(ctx context.Context, job *entity.ScanJob) (bool, error)
+	// CancelActive 取消视频全部未完成的记录，返回取消的数量
+	CancelActive(ctx context.Context, videoUUID, reason string) (int64, error)
+}
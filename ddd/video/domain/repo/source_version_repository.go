@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"time"
+)
+
+// SourceVersionRepository 视频源文件版本仓储接口
+type SourceVersionRepository interface {
+	// CreateNext 保存上传中的新版本并分配版本号；视频还没有版本记录时先补录initial
+	CreateNext(ctx context.Context, initial, version *entity.SourceVersion) error
+	// Activate 把视频切换到目标版本，原生效版本保留到retainUntil。
+	// 视频源文件或目标版本状态已被并发修改时返回 *vo.TransitionError
+	Activate(ctx context.Context, version *entity.SourceVersion, previousPath string, retainUntil time.Time) error
+	// MarkFailed 上传中的版本标记为失败
+	MarkFailed(ctx context.Context, versionUUID, errorMsg string) error
+	// MarkPurged 超过保留期的版本标记为已清理，返回是否标记成功
+	MarkPurged(ctx context.Context, versionUUID string, now time.Time) (bool, error)
+	// FindByUUID 根据UUID查找版本，不存在时返回nil
+	FindByUUID(ctx context.Context, versionUUID string) (*entity.SourceVersion, error)
+	// FindByVersion 根据版本号查找版本，不存在时返回nil
+	FindByVersion(ctx context.Context, videoUUID string, version int) (*entity.SourceVersion, error)
+	// FindByVideoUUID 查找视频的全部版本，新版本在前
+	FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.SourceVersion, error)
+	// FindExpired 查找保留期已过、对象尚未清理的版本
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.SourceVersion, error)
+	// FindRetainedStoragePaths 查找仍需保留对象的版本的存储路径，用于对账
+	FindRetainedStoragePaths(ctx context.Context) ([]string, error)
+}
@@ -10,6 +10,48 @@ import (
 type VideoRepository interface {
 	// Save 保存视频
 	Save(ctx context.Context, video *entity.Video) error
+	// FindByUUID 根据UUID查找视频，不存在时返回nil
+	FindByUUID(ctx context.Context, videoUUID string) (*entity.Video, error)
+	// FindByChecksum 根据上传时的源文件SHA-256查找用户的视频，源文件改写（faststart、清除元数据）后仍能找到，不存在时返回nil
+	FindByChecksum(ctx context.Context, userUUID, sha256 string) (*entity.Video, error)
 	CreateVideo(ctx context.Context, video *entity.Video, videoUploadTask *entity.VideoUploadTaskEntity) error
-	UpdateVideoStatus(ctx context.Context, videoUUID string, videoStatus vo.VideoStatus, videoUploadTaskUUID string, videotaskStatus vo.VideoUploadTaskStatus) error
+	// CreateBatch 在一个事务中保存批量上传以及通过校验的视频和上传任务
+	CreateBatch(ctx context.Context, batch *entity.UploadBatch, items []*entity.VideoEntity) error
+	// FindBatch 根据UUID查找批量上传，不存在时返回nil
+	FindBatch(ctx context.Context, batchUUID string) (*entity.UploadBatch, error)
+	// FindBatchItems 查找批量上传下的上传任务及其视频
+	FindBatchItems(ctx context.Context, batchUUID string) ([]*entity.VideoEntity, error)
+	// FindPublic 分页查找公开视频（可播放且无需审核或审核通过）
+	FindPublic(ctx context.Context, page *vo.Page) ([]*entity.Video, int64, error)
+	// FindByUserUUID 分页查找用户的全部视频
+	FindByUserUUID(ctx context.Context, userUUID string, page *vo.Page) ([]*entity.Video, int64, error)
+	// FindAllByUserUUID 查找用户的全部视频，用于数据导出
+	FindAllByUserUUID(ctx context.Context, userUUID string) ([]*entity.Video, error)
+	// FindUploadTasksByUser 查找用户的全部上传任务，用于数据导出
+	FindUploadTasksByUser(ctx context.Context, userUUID string) ([]*entity.VideoUploadTaskEntity, error)
+	// UpdateReview 更新视频的审核状态和审核意见
+	UpdateReview(ctx context.Context, video *entity.Video) error
+	// UpdateVideoStatus 以比较并交换的方式持久化视频状态迁移（可选同时迁移上传任务状态），并记录迁移历史。
+	// 当前状态已被并发修改时返回 *vo.TransitionError
+	UpdateVideoStatus(ctx context.Context, transition *entity.StatusTransition, taskTransition *entity.TaskTransition) error
+	// UpdateChecksum 记录视频和上传任务（可为nil）的校验和和存储ETag
+	UpdateChecksum(ctx context.Context, video *entity.Video, task *entity.VideoUploadTaskEntity) error
+	// UpdateTaskProgress 记录上传任务的传输进度
+	UpdateTaskProgress(ctx context.Context, task *entity.VideoUploadTaskEntity) error
+	// UpdateFileSize 记录视频源文件的实际大小（远程导入时上传前未知）
+	UpdateFileSize(ctx context.Context, videoUUID string, fileSize int64) error
+	// UpdateSourceContent 保存改写后源文件的大小、校验和、ETag和清除的元数据，源文件已被替换或改写（存储路径或ETag变化）时返回false
+	UpdateSourceContent(ctx context.Context, video *entity.Video, previousETag string) (bool, error)
+	// UpdatePoster 保存视频的封面，封面已被并发修改（不再是previousPath）时返回false
+	UpdatePoster(ctx context.Context, video *entity.Video, previousPath string) (bool, error)
+	// Delete 删除视频并写入视频记录的领域事件，源文件由调用方删除
+	Delete(ctx context.Context, video *entity.Video) error
+	// UpdateTaskStatus 以比较并交换的方式持久化上传任务状态迁移
+	UpdateTaskStatus(ctx context.Context, taskTransition *entity.TaskTransition) error
+	// FindUploadTask 根据UUID查找上传任务，不存在时返回nil
+	FindUploadTask(ctx context.Context, taskUUID string) (*entity.VideoUploadTaskEntity, error)
+	// FindAllStorageRefs 查找全部视频，只加载UUID、用户、存储路径、状态和创建时间，用于对账
+	FindAllStorageRefs(ctx context.Context) ([]*entity.Video, error)
+	// FindStatusHistory 查找视频的状态迁移历史，按时间先后排序
+	FindStatusHistory(ctx context.Context, videoUUID string) ([]*entity.StatusTransition, error)
 }
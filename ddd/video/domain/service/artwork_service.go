@@ -0,0 +1,485 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/ddd/video/infrastructure/transcoder"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/imaging"
+	"go-video/pkg/logger"
+	"go-video/pkg/subtitle"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// coverMaxPixels 自定义封面解码前允许的最大像素数
+	coverMaxPixels = 50_000_000
+	// storyboardContentType 缩略图轨道的内容类型
+	storyboardContentType = "text/vtt"
+	// posterUpdateRounds 更新封面时比较并交换失败后最多重试的次数
+	posterUpdateRounds = 3
+)
+
+var (
+	artworkServiceOnce      sync.Once
+	singletonArtworkService ArtworkService
+)
+
+// ArtworkService 封面和缩略图服务：从源文件截取候选封面帧和拖动预览缩略图，所有者可以选择候选帧或上传自定义封面
+type ArtworkService interface {
+	// Schedule 为视频当前的源文件创建等待生成的记录，已有未失败的记录时直接返回
+	Schedule(ctx context.Context, video *entity.Video) (*entity.Artwork, error)
+	// ClaimDue 领取最多limit个到期的记录，租约为lease
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Artwork, error)
+	// Generate 执行已领取的记录：截取候选帧、生成雪碧图和缩略图轨道并上传，完成后视频没有自定义封面时使用第一张候选帧
+	Generate(ctx context.Context, artwork *entity.Artwork, opts vo.ArtworkOptions) error
+	// Cancel 取消视频未完成的记录，返回取消的数量
+	Cancel(ctx context.Context, videoUUID, reason string) (int64, error)
+	// Latest 获取视频最新的记录（包括未完成和失败的），不存在时返回nil
+	Latest(ctx context.Context, videoUUID string) (*entity.Artwork, error)
+	// Current 获取视频最新生成完成的记录，没有时返回nil
+	Current(ctx context.Context, videoUUID string) (*entity.Artwork, error)
+	// SelectPoster 使用最新一次生成的第index张候选帧作为封面
+	SelectPoster(ctx context.Context, video *entity.Video, index int) error
+	// SetCover 校验上传的图片并等比缩小到不超过 maxWidth x maxHeight 后作为自定义封面
+	SetCover(ctx context.Context, video *entity.Video, data []byte, maxWidth, maxHeight int) error
+}
+
+type artworkServiceImpl struct {
+	minioService gateway.MinioService
+	transcoder   gateway.Transcoder
+	videoRepo    repo.VideoRepository
+	artworkRepo  repo.ArtworkRepository
+}
+
+// DefaultArtworkService 获取默认封面和缩略图服务实例
+func DefaultArtworkService() ArtworkService {
+	assert.NotCircular()
+	artworkServiceOnce.Do(func() {
+		singletonArtworkService = &artworkServiceImpl{
+			minioService: minio.DefaultMinioService(),
+			transcoder:   transcoder.DefaultTranscoder(),
+			videoRepo:    persistence.NewVideoRepository(),
+			artworkRepo:  persistence.NewArtworkRepository(),
+		}
+	})
+	assert.NotNil(singletonArtworkService)
+	return singletonArtworkService
+}
+
+// Schedule 事件可能重复到达，同一源文件等待中、生成中或已完成的记录不再重复创建
+func (s *artworkServiceImpl) Schedule(ctx context.Context, video *entity.Video) (*entity.Artwork, error) {
+	latest, err := s.artworkRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if latest != nil && latest.IsCurrentFor(video) {
+		switch latest.Status() {
+		case vo.RenditionStatusPending, vo.RenditionStatusRunning, vo.RenditionStatusSucceeded:
+			return latest, nil
+		}
+	}
+	if _, err := s.artworkRepo.CancelActive(ctx, video.UUID(), "rescheduled"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	artwork := entity.DefaultArtwork(video)
+	if err := s.artworkRepo.Create(ctx, artwork); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return artwork, nil
+}
+
+// ClaimDue 多实例同时领取时以比较并交换保证每条记录只有一个执行者
+func (s *artworkServiceImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Artwork, error) {
+	now := time.Now()
+	candidates, err := s.artworkRepo.FindClaimable(ctx, now, limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	claimed := make([]*entity.Artwork, 0, len(candidates))
+	for _, artwork := range candidates {
+		ok, err := s.artworkRepo.Claim(ctx, artwork, now.Add(lease))
+		if err != nil {
+			return claimed, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			claimed = append(claimed, artwork)
+		}
+	}
+	return claimed, nil
+}
+
+// Generate 生成比转码快得多，不续租，租约应长于超时时间；
+// ctx 被取消（服务停止）时保持生成中状态，租约到期后由其他实例重新执行
+func (s *artworkServiceImpl) Generate(ctx context.Context, artwork *entity.Artwork, opts vo.ArtworkOptions) error {
+	video, err := s.videoRepo.FindByUUID(ctx, artwork.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return s.finishCanceled(ctx, artwork, "video deleted")
+	}
+	if !artwork.IsCurrentFor(video) {
+		return s.finishCanceled(ctx, artwork, "source replaced")
+	}
+
+	workCtx := ctx
+	if opts.Timeout() > 0 {
+		var cancel context.CancelFunc
+		workCtx, cancel = context.WithTimeout(ctx, opts.Timeout())
+		defer cancel()
+	}
+	candidates, storyboardPath, runErr := s.run(workCtx, artwork, opts)
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		reason := runErr.Error()
+		if errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+			reason = fmt.Sprintf("artwork timed out after %s", opts.Timeout())
+		}
+		logger.Error(fmt.Sprintf("artwork %s of video %s attempt %d failed: %s", artwork.UUID(), artwork.VideoUuid(), artwork.Attempts(), reason))
+		artwork.Fail(reason, opts.MaxAttempts(), opts.RetryDelay())
+		ok, err := s.artworkRepo.Finish(ctx, artwork)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok && artwork.Status() == vo.RenditionStatusFailed {
+			s.deleteOutputs(ctx, artwork)
+		}
+		return nil
+	}
+
+	artwork.Succeed(candidates, storyboardPath)
+	ok, err := s.artworkRepo.Finish(ctx, artwork)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		// 上传完成时记录已被取消，输出不会被引用
+		s.deleteOutputs(ctx, artwork)
+		return nil
+	}
+	logger.Info(fmt.Sprintf("artwork %s of video %s succeeded: %d poster candidates", artwork.UUID(), artwork.VideoUuid(), len(candidates)))
+	// 先切换封面再删除旧的候选帧，避免封面指向已删除的对象
+	if err := s.applyDefaultPoster(ctx, artwork); err != nil {
+		return err
+	}
+	return s.supersede(ctx, artwork)
+}
+
+// Cancel 取消生成
+func (s *artworkServiceImpl) Cancel(ctx context.Context, videoUUID, reason string) (int64, error) {
+	canceled, err := s.artworkRepo.CancelActive(ctx, videoUUID, reason)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return canceled, nil
+}
+
+// Latest 获取最新的记录
+func (s *artworkServiceImpl) Latest(ctx context.Context, videoUUID string) (*entity.Artwork, error) {
+	artwork, err := s.artworkRepo.FindLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return artwork, nil
+}
+
+// Current 获取最新生成完成的记录
+func (s *artworkServiceImpl) Current(ctx context.Context, videoUUID string) (*entity.Artwork, error) {
+	artwork, err := s.artworkRepo.FindLatestSucceeded(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return artwork, nil
+}
+
+// SelectPoster 替换掉的自定义封面随后删除
+func (s *artworkServiceImpl) SelectPoster(ctx context.Context, video *entity.Video, index int) error {
+	artwork, err := s.Current(ctx, video.UUID())
+	if err != nil {
+		return err
+	}
+	if artwork == nil {
+		return errno.NewSimpleBizError(errno.ErrArtworkNotFound, nil)
+	}
+	if index < 0 || index >= len(artwork.Candidates()) {
+		return errno.NewSimpleBizError(errno.ErrPosterCandidateInvalid, nil, index)
+	}
+	return s.replacePoster(ctx, video, artwork.Candidates()[index].Path())
+}
+
+// SetCover 只接受 JPEG、PNG 和 GIF，统一重新编码为JPEG，图片中的元数据不会保留
+func (s *artworkServiceImpl) SetCover(ctx context.Context, video *entity.Video, data []byte, maxWidth, maxHeight int) error {
+	img, err := imaging.Decode(data, coverMaxPixels)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrCoverInvalid, err, err.Error())
+	}
+	encoded, err := imaging.EncodeJPEG(imaging.Fit(img, maxWidth, maxHeight), imaging.DefaultQuality)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	coverPath := vo.CustomCoverPath(video.AssetPrefix(), uuid.New().String())
+	if err := s.minioService.PutMediaObject(ctx, coverPath, bytes.NewReader(encoded), int64(len(encoded)), imaging.ContentTypeJPEG); err != nil {
+		return errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if err := s.replacePoster(ctx, video, coverPath); err != nil {
+		s.deleteObject(ctx, coverPath)
+		return err
+	}
+	return nil
+}
+
+// replacePoster 以比较并交换的方式更新封面，替换掉的自定义封面随后删除
+func (s *artworkServiceImpl) replacePoster(ctx context.Context, video *entity.Video, posterPath string) error {
+	previous := video.PosterPath()
+	video.SetPosterPath(posterPath)
+	ok, err := s.videoRepo.UpdatePoster(ctx, video, previous)
+	if err != nil {
+		video.SetPosterPath(previous)
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		video.SetPosterPath(previous)
+		return errno.NewSimpleBizError(errno.ErrPosterChanged, nil)
+	}
+	if previous != posterPath && vo.IsCustomCover(previous) {
+		s.deleteObject(ctx, previous)
+	}
+	return nil
+}
+
+// applyDefaultPoster 视频没有自定义封面时使用第一张候选帧；与所有者同时修改封面时重新读取后再判断
+func (s *artworkServiceImpl) applyDefaultPoster(ctx context.Context, artwork *entity.Artwork) error {
+	if len(artwork.Candidates()) == 0 {
+		return nil
+	}
+	posterPath := artwork.Candidates()[0].Path()
+	for round := 0; round < posterUpdateRounds; round++ {
+		video, err := s.videoRepo.FindByUUID(ctx, artwork.VideoUuid())
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if video == nil || vo.IsCustomCover(video.PosterPath()) {
+			return nil
+		}
+		previous := video.PosterPath()
+		video.SetPosterPath(posterPath)
+		ok, err := s.videoRepo.UpdatePoster(ctx, video, previous)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			return nil
+		}
+	}
+	logger.Error(fmt.Sprintf("video %s poster not updated: changed concurrently %d times", artwork.VideoUuid(), posterUpdateRounds))
+	return nil
+}
+
+// supersede 更早生成完成的记录标记为被替换并删除其输出
+func (s *artworkServiceImpl) supersede(ctx context.Context, current *entity.Artwork) error {
+	artworks, err := s.artworkRepo.FindByVideoUUID(ctx, current.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	older := false
+	for _, artwork := range artworks {
+		if artwork.UUID() == current.UUID() {
+			older = true
+			continue
+		}
+		if !older || artwork.Status() != vo.RenditionStatusSucceeded {
+			continue
+		}
+		if err := s.artworkRepo.MarkSuperseded(ctx, artwork.UUID()); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		s.deleteOutputs(ctx, artwork)
+	}
+	return nil
+}
+
+// run 下载源文件到临时目录，截取候选帧和缩略图并上传，返回候选帧和缩略图轨道路径
+func (s *artworkServiceImpl) run(ctx context.Context, artwork *entity.Artwork, opts vo.ArtworkOptions) ([]vo.PosterCandidate, string, error) {
+	if opts.WorkDir() != "" {
+		if err := os.MkdirAll(opts.WorkDir(), 0o755); err != nil {
+			return nil, "", err
+		}
+	}
+	dir, err := os.MkdirTemp(opts.WorkDir(), "artwork-"+artwork.UUID()+"-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "source")
+	if err := downloadObject(ctx, s.minioService, artwork.SourcePath(), input); err != nil {
+		return nil, "", fmt.Errorf("download source: %w", err)
+	}
+	source, err := s.transcoder.Probe(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("probe source: %w", err)
+	}
+
+	offsets := posterOffsets(opts.PosterOffsets(), source.Duration())
+	candidates := make([]vo.PosterCandidate, 0, len(offsets))
+	for i, offset := range offsets {
+		local := filepath.Join(dir, vo.PosterFilename(i))
+		if err := s.transcoder.Snapshot(ctx, input, local, offset, opts.PosterWidth()); err != nil {
+			return nil, "", fmt.Errorf("poster at %s: %w", offset, err)
+		}
+		objectName := artwork.Dir() + vo.PosterFilename(i)
+		if err := s.uploadFile(ctx, local, objectName, imaging.ContentTypeJPEG); err != nil {
+			return nil, "", fmt.Errorf("upload poster: %w", err)
+		}
+		candidates = append(candidates, vo.NewPosterCandidate(offset, objectName))
+	}
+
+	storyboardPath, err := s.storyboard(ctx, artwork, input, filepath.Join(dir, "thumbnails"), source.Duration(), opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return candidates, storyboardPath, nil
+}
+
+// storyboard 把缩略图按 columns x rows 拼成雪碧图，缩略图轨道中每个条目指向雪碧图中的一块区域（#xywh=）
+func (s *artworkServiceImpl) storyboard(ctx context.Context, artwork *entity.Artwork, input, dir string, duration time.Duration,
+	opts vo.ArtworkOptions) (string, error) {
+	interval := opts.ThumbnailInterval()
+	names, err := s.transcoder.Thumbnails(ctx, input, dir, interval, opts.ThumbnailWidth())
+	if err != nil {
+		return "", fmt.Errorf("thumbnails: %w", err)
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	perSprite := opts.SpriteColumns() * opts.SpriteRows()
+	cues := make([]subtitle.Cue, 0, len(names))
+	for first := 0; first < len(names); first += perSprite {
+		batch := names[first:min(first+perSprite, len(names))]
+		tiles := make([]image.Image, 0, len(batch))
+		for _, name := range batch {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return "", err
+			}
+			tile, err := imaging.Decode(data, 0)
+			if err != nil {
+				return "", fmt.Errorf("thumbnail %s: %w", name, err)
+			}
+			tiles = append(tiles, tile)
+		}
+		// 同一次截取的缩略图尺寸相同
+		tileWidth, tileHeight := tiles[0].Bounds().Dx(), tiles[0].Bounds().Dy()
+		columns := min(opts.SpriteColumns(), len(tiles))
+		encoded, err := imaging.EncodeJPEG(imaging.Sprite(tiles, columns, tileWidth, tileHeight), imaging.DefaultQuality)
+		if err != nil {
+			return "", err
+		}
+		spriteName := vo.SpriteFilename(first / perSprite)
+		if err := s.minioService.PutMediaObject(ctx, artwork.Dir()+spriteName, bytes.NewReader(encoded), int64(len(encoded)),
+			imaging.ContentTypeJPEG); err != nil {
+			return "", fmt.Errorf("upload sprite: %w", err)
+		}
+		for j := range batch {
+			start := time.Duration(first+j) * interval
+			end := start + interval
+			if duration > start && duration < end {
+				end = duration
+			}
+			cues = append(cues, subtitle.Cue{
+				Start: start,
+				End:   end,
+				Text: fmt.Sprintf("%s#xywh=%d,%d,%d,%d", spriteName,
+					j%columns*tileWidth, j/columns*tileHeight, tileWidth, tileHeight),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := subtitle.WriteWebVTT(&buf, cues); err != nil {
+		return "", err
+	}
+	storyboardPath := artwork.Dir() + vo.StoryboardFile
+	// 缩略图轨道只有雪碧图的文件名和坐标，不包含画面，读取后由应用层改写地址，不需要加密
+	if err := s.minioService.PutObject(ctx, storyboardPath, &buf, int64(buf.Len()), storyboardContentType); err != nil {
+		return "", fmt.Errorf("upload storyboard: %w", err)
+	}
+	return storyboardPath, nil
+}
+
+// posterOffsets 去掉超出视频时长的时间点，全部超出时取中间一帧；时长未知时取第一帧
+func posterOffsets(configured []time.Duration, duration time.Duration) []time.Duration {
+	if duration <= 0 {
+		return []time.Duration{0}
+	}
+	offsets := make([]time.Duration, 0, len(configured))
+	for _, offset := range configured {
+		if offset >= 0 && offset < duration {
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) == 0 {
+		offsets = append(offsets, duration/2)
+	}
+	return offsets
+}
+
+// uploadFile 上传本地截取的画面，开启加密时加密存储
+func (s *artworkServiceImpl) uploadFile(ctx context.Context, localPath, objectName, contentType string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return s.minioService.PutMediaObject(ctx, objectName, file, stat.Size(), contentType)
+}
+
+func (s *artworkServiceImpl) finishCanceled(ctx context.Context, artwork *entity.Artwork, reason string) error {
+	artwork.Cancel(reason)
+	if _, err := s.artworkRepo.Finish(ctx, artwork); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// deleteOutputs 删除记录输出目录下的全部对象，失败只记录日志，不再被引用的对象后续由对账清理
+func (s *artworkServiceImpl) deleteOutputs(ctx context.Context, artwork *entity.Artwork) {
+	objects, err := s.minioService.ListObjects(ctx, artwork.Dir())
+	if err != nil {
+		logger.Error(fmt.Sprintf("list artwork %s objects failed: %v", artwork.UUID(), err))
+		return
+	}
+	for _, object := range objects {
+		s.deleteObject(ctx, object.Key())
+	}
+}
+
+// deleteObject 删除对象，失败只记录日志
+func (s *artworkServiceImpl) deleteObject(ctx context.Context, objectName string) {
+	if err := s.minioService.DeleteVideo(ctx, objectName); err != nil {
+		logger.Error(fmt.Sprintf("delete artwork object %s failed: %v", objectName, err))
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/hls"
+)
+
+var (
+	contentKeyServiceOnce      sync.Once
+	singletonContentKeyService ContentKeyService
+)
+
+// ContentKeyService HLS内容密钥服务：密钥明文只在打包分片和发放给播放器时出现，数据库中保存被主密钥包装的密钥
+type ContentKeyService interface {
+	// KeyFor 获取视频指定序号的内容密钥及其明文，不存在时生成
+	KeyFor(ctx context.Context, videoUUID string, sequence int) (*entity.ContentKey, []byte, error)
+	// Open 解开内容密钥
+	Open(key *entity.ContentKey) ([]byte, error)
+}
+
+type contentKeyServiceImpl struct {
+	minioService   gateway.MinioService
+	contentKeyRepo repo.ContentKeyRepository
+}
+
+// DefaultContentKeyService 获取默认内容密钥服务实例
+func DefaultContentKeyService() ContentKeyService {
+	assert.NotCircular()
+	contentKeyServiceOnce.Do(func() {
+		singletonContentKeyService = &contentKeyServiceImpl{
+			minioService:   minio.DefaultMinioService(),
+			contentKeyRepo: persistence.NewContentKeyRepository(),
+		}
+	})
+	assert.NotNil(singletonContentKeyService)
+	return singletonContentKeyService
+}
+
+// KeyFor 多档并发打包时可能同时生成同一序号的密钥，只有先写入的生效，其余的重新读取
+func (s *contentKeyServiceImpl) KeyFor(ctx context.Context, videoUUID string, sequence int) (*entity.ContentKey, []byte, error) {
+	key, err := s.contentKeyRepo.FindBySequence(ctx, videoUUID, sequence)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil {
+		plain := make([]byte, hls.KeySize)
+		if _, err := rand.Read(plain); err != nil {
+			return nil, nil, err
+		}
+		masterKeyID, wrapped, err := s.minioService.WrapKey(plain)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wrap content key: %w", err)
+		}
+		key = entity.DefaultContentKey(videoUUID, sequence, masterKeyID, wrapped)
+		created, err := s.contentKeyRepo.Create(ctx, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if created {
+			return key, plain, nil
+		}
+		if key, err = s.contentKeyRepo.FindBySequence(ctx, videoUUID, sequence); err != nil {
+			return nil, nil, err
+		}
+		if key == nil {
+			return nil, nil, fmt.Errorf("content key %d of video %s not found after conflict", sequence, videoUUID)
+		}
+	}
+	plain, err := s.Open(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, plain, nil
+}
+
+// Open 解开内容密钥
+func (s *contentKeyServiceImpl) Open(key *entity.ContentKey) ([]byte, error) {
+	plain, err := s.minioService.UnwrapKey(key.MasterKeyID(), key.WrappedKey())
+	if err != nil {
+		return nil, fmt.Errorf("unwrap content key %s: %w", key.UUID(), err)
+	}
+	if len(plain) != hls.KeySize {
+		return nil, fmt.Errorf("content key %s has %d bytes", key.UUID(), len(plain))
+	}
+	return plain, nil
+}
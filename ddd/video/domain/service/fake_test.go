@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+)
+
+// memoryStore 内存中的对象存储，只实现服务用到的方法，调用其余方法时 panic
+type memoryStore struct {
+	gateway.MinioService
+
+	mu           sync.Mutex
+	objects      map[string][]byte
+	contentTypes map[string]string
+	// encrypted 经 PutMediaObject 写入、开启加密时会加密存储的对象
+	encrypted map[string]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}, contentTypes: map[string]string{}, encrypted: map[string]bool{}}
+}
+
+func (s *memoryStore) put(objectName string, data []byte, contentType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[objectName] = data
+	s.contentTypes[objectName] = contentType
+	delete(s.encrypted, objectName)
+}
+
+func (s *memoryStore) isEncrypted(objectName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encrypted[objectName]
+}
+
+func (s *memoryStore) get(objectName string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[objectName]
+	return data, ok
+}
+
+// keys 按字典序返回指定前缀下的对象
+func (s *memoryStore) keys(prefix string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *memoryStore) PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	s.put(objectName, data, contentType)
+	return nil
+}
+
+func (s *memoryStore) PutMediaObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	if err := s.PutObject(ctx, objectName, reader, size, contentType); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encrypted[objectName] = true
+	return nil
+}
+
+func (s *memoryStore) UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64,
+	expected vo.Checksum) (vo.UploadReceipt, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return vo.UploadReceipt{}, err
+	}
+	s.put(objectName, data, "")
+	return vo.NewUploadReceipt(objectName, vo.Checksum{}, ""), nil
+}
+
+func (s *memoryStore) StatObject(ctx context.Context, objectName string) (vo.ObjectStat, error) {
+	data, ok := s.get(objectName)
+	if !ok {
+		return vo.ObjectStat{}, io.ErrUnexpectedEOF
+	}
+	keyID := ""
+	if s.isEncrypted(objectName) {
+		keyID = "memory-key"
+	}
+	return vo.NewObjectStat(int64(len(data)), s.contentTypes[objectName], keyID, ""), nil
+}
+
+func (s *memoryStore) OpenObject(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error) {
+	data, ok := s.get(objectName)
+	if !ok {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func (s *memoryStore) ListObjects(ctx context.Context, prefix string) ([]vo.StoredObject, error) {
+	var objects []vo.StoredObject
+	for _, key := range s.keys(prefix) {
+		data, _ := s.get(key)
+		objects = append(objects, vo.NewStoredObject(key, int64(len(data)), time.Now()))
+	}
+	return objects, nil
+}
+
+func (s *memoryStore) MoveObject(ctx context.Context, src, dst string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[src]
+	if !ok {
+		return fmt.Errorf("object %s not found", src)
+	}
+	s.objects[dst] = data
+	s.contentTypes[dst] = s.contentTypes[src]
+	s.encrypted[dst] = s.encrypted[src]
+	delete(s.objects, src)
+	delete(s.contentTypes, src)
+	delete(s.encrypted, src)
+	return nil
+}
+
+func (s *memoryStore) GenerateObjectName(userUUID, filename string) string {
+	return fmt.Sprintf("videos/%s/%s", userUUID, filename)
+}
+
+func (s *memoryStore) DeleteVideo(ctx context.Context, objectName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, objectName)
+	delete(s.contentTypes, objectName)
+	return nil
+}
+
+// memoryVideoRepo 内存中的视频仓储，记录状态迁移
+type memoryVideoRepo struct {
+	repo.VideoRepository
+
+	mu          sync.Mutex
+	videos      map[string]*entity.Video
+	tasks       map[string]*entity.VideoUploadTaskEntity
+	transitions []vo.VideoStatus
+}
+
+func newMemoryVideoRepo(videos ...*entity.Video) *memoryVideoRepo {
+	r := &memoryVideoRepo{videos: map[string]*entity.Video{}, tasks: map[string]*entity.VideoUploadTaskEntity{}}
+	for _, video := range videos {
+		r.videos[video.UUID()] = video
+	}
+	return r
+}
+
+func (r *memoryVideoRepo) CreateVideo(ctx context.Context, video *entity.Video, task *entity.VideoUploadTaskEntity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.videos[video.UUID()] = video
+	r.tasks[task.UUID()] = task
+	return nil
+}
+
+func (r *memoryVideoRepo) FindUploadTask(ctx context.Context, taskUUID string) (*entity.VideoUploadTaskEntity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tasks[taskUUID], nil
+}
+
+func (r *memoryVideoRepo) FindByUUID(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.videos[videoUUID], nil
+}
+
+func (r *memoryVideoRepo) UpdateVideoStatus(ctx context.Context, transition *entity.StatusTransition,
+	taskTransition *entity.TaskTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, transition.To())
+	return nil
+}
+
+func (r *memoryVideoRepo) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]string, 0, len(r.transitions))
+	for _, status := range r.transitions {
+		values = append(values, status.Value())
+	}
+	return values
+}
+
+// uploadedVideo 源文件已上传完成的视频，源文件写入 store
+func uploadedVideo(store *memoryStore, source []byte) *entity.Video {
+	video := entity.DefaultVideo("user-1", "clip", "", "clip.mp4", int64(len(source)), "mp4", "", vo.VideoStatusUploaded)
+	video.SetStoragePath(video.AssetPrefix() + "source/clip.mp4")
+	store.put(video.StoragePath(), source, "video/mp4")
+	return video
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/mp4"
+)
+
+// errSourceChanged 下载和改写期间源文件被替换或视频被删除
+var errSourceChanged = errors.New("source changed during faststart")
+
+var (
+	faststartServiceOnce      sync.Once
+	singletonFaststartService FaststartService
+)
+
+// faststartResult 一次改写的结果
+type faststartResult struct {
+	outcome      vo.FaststartOutcome
+	originalSize int64
+	fileSize     int64
+}
+
+// FaststartService 源文件改写服务：moov 在 mdat 之后的 MP4 必须整个下载完才能开始播放，
+// 把 moov 移到 mdat 之前后浏览器可以边下载边播放；手机录制的视频在 udta/meta 中带有拍摄位置和设备信息，
+// 上传时没有选择保留的在同一次改写中清除，媒体数据保持不变
+type FaststartService interface {
+	// Schedule 为视频当前的源文件创建等待处理的记录，已有未失败的记录时直接返回
+	Schedule(ctx context.Context, video *entity.Video) (*entity.FaststartJob, error)
+	// ClaimDue 领取最多limit个到期的记录，租约为lease
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.FaststartJob, error)
+	// Process 执行已领取的记录：源文件需要改写时在原存储路径上覆盖，并保存新的大小、校验和、ETag和清除的元数据
+	Process(ctx context.Context, job *entity.FaststartJob, opts vo.FaststartOptions) error
+	// Cancel 取消视频未完成的记录，返回取消的数量
+	Cancel(ctx context.Context, videoUUID, reason string) (int64, error)
+	// Latest 获取视频最新的记录，不存在时返回nil
+	Latest(ctx context.Context, videoUUID string) (*entity.FaststartJob, error)
+}
+
+type faststartServiceImpl struct {
+	minioService  gateway.MinioService
+	videoRepo     repo.VideoRepository
+	faststartRepo repo.FaststartRepository
+}
+
+// DefaultFaststartService 获取默认 faststart 服务实例
+func DefaultFaststartService() FaststartService {
+	assert.NotCircular()
+	faststartServiceOnce.Do(func() {
+		singletonFaststartService = &faststartServiceImpl{
+			minioService:  minio.DefaultMinioService(),
+			videoRepo:     persistence.NewVideoRepository(),
+			faststartRepo: persistence.NewFaststartRepository(),
+		}
+	})
+	assert.NotNil(singletonFaststartService)
+	return singletonFaststartService
+}
+
+// Schedule 事件可能重复到达，同一源文件等待中、处理中或已完成的记录不再重复创建
+func (s *faststartServiceImpl) Schedule(ctx context.Context, video *entity.Video) (*entity.FaststartJob, error) {
+	latest, err := s.faststartRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if latest != nil && latest.IsCurrentFor(video) {
+		switch latest.Status() {
+		case vo.RenditionStatusPending, vo.RenditionStatusRunning, vo.RenditionStatusSucceeded:
+			return latest, nil
+		}
+	}
+	if _, err := s.faststartRepo.CancelActive(ctx, video.UUID(), "rescheduled"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	job := entity.DefaultFaststartJob(video)
+	if err := s.faststartRepo.Create(ctx, job); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+// ClaimDue 多实例同时领取时以比较并交换保证每条记录只有一个执行者
+func (s *faststartServiceImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.FaststartJob, error) {
+	now := time.Now()
+	candidates, err := s.faststartRepo.FindClaimable(ctx, now, limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	claimed := make([]*entity.FaststartJob, 0, len(candidates))
+	for _, job := range candidates {
+		ok, err := s.faststartRepo.Claim(ctx, job, now.Add(lease))
+		if err != nil {
+			return claimed, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+// Process 不续租，租约应长于超时时间；ctx 被取消（服务停止）时保持处理中状态，租约到期后由其他实例重新执行。
+// 重新执行时源文件可能已经改写过，此时只补记校验和
+func (s *faststartServiceImpl) Process(ctx context.Context, job *entity.FaststartJob, opts vo.FaststartOptions) error {
+	video, err := s.videoRepo.FindByUUID(ctx, job.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return s.finishCanceled(ctx, job, "video deleted")
+	}
+	if !job.IsCurrentFor(video) {
+		return s.finishCanceled(ctx, job, "source replaced")
+	}
+
+	workCtx := ctx
+	if opts.Timeout() > 0 {
+		var cancel context.CancelFunc
+		workCtx, cancel = context.WithTimeout(ctx, opts.Timeout())
+		defer cancel()
+	}
+	result, runErr := s.run(workCtx, video, opts)
+	if errors.Is(runErr, errSourceChanged) {
+		return s.finishCanceled(ctx, job, "source replaced")
+	}
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		reason := runErr.Error()
+		if errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+			reason = fmt.Sprintf("faststart timed out after %s", opts.Timeout())
+		}
+		logger.Error(fmt.Sprintf("faststart %s of video %s attempt %d failed: %s", job.UUID(), job.VideoUuid(), job.Attempts(), reason))
+		job.Fail(reason, opts.MaxAttempts(), opts.RetryDelay())
+		if _, err := s.faststartRepo.Finish(ctx, job); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		return nil
+	}
+
+	job.Succeed(result.outcome, result.originalSize, result.fileSize)
+	if _, err := s.faststartRepo.Finish(ctx, job); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	logger.Info(fmt.Sprintf("faststart %s of video %s: %s, size %d -> %d", job.UUID(), job.VideoUuid(),
+		result.outcome.Value(), result.originalSize, result.fileSize))
+	return nil
+}
+
+// Cancel 取消处理
+func (s *faststartServiceImpl) Cancel(ctx context.Context, videoUUID, reason string) (int64, error) {
+	canceled, err := s.faststartRepo.CancelActive(ctx, videoUUID, reason)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return canceled, nil
+}
+
+// Latest 获取最新的记录
+func (s *faststartServiceImpl) Latest(ctx context.Context, videoUUID string) (*entity.FaststartJob, error) {
+	job, err := s.faststartRepo.FindLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+// run 先按范围读取顶层box和 moov 判断是否需要改写，不需要改写或不是 MP4 时不下载整个文件；
+// 需要改写时下载到临时目录，改写到另一个临时文件后覆盖原对象（加密存储的视频重新加密）。
+// 存储路径不变，转码输出、封面和源文件版本的引用都保持有效
+func (s *faststartServiceImpl) run(ctx context.Context, video *entity.Video, opts vo.FaststartOptions) (*faststartResult, error) {
+	stat, err := s.minioService.StatObject(ctx, video.StoragePath())
+	if err != nil {
+		return nil, fmt.Errorf("stat source: %w", err)
+	}
+	size := stat.Size()
+	rewriteOpts := mp4.Options{Faststart: true, StripMetadata: opts.StripMetadata() && !video.KeepMetadata()}
+	inspected, err := mp4.Inspect(&objectReaderAt{ctx: ctx, minioService: s.minioService, objectName: video.StoragePath(), size: size}, size, rewriteOpts)
+	if outcome, ok := skippedOutcome(err); ok {
+		return &faststartResult{outcome: outcome, originalSize: size, fileSize: size}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inspect source: %w", err)
+	}
+	// 上次执行已覆盖对象但没来得及保存校验和时，ETag 与记录的不一致，需要下载重新计算
+	if !inspected.Rewritten && stat.ETag() == video.ETag() {
+		return &faststartResult{outcome: vo.FaststartOutcomeUnchanged, originalSize: size, fileSize: size}, nil
+	}
+
+	dir, err := os.MkdirTemp(opts.WorkDir(), "faststart-"+video.UUID()+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	input, output := filepath.Join(dir, "source"), filepath.Join(dir, "rewritten")
+	if err := downloadObject(ctx, s.minioService, video.StoragePath(), input); err != nil {
+		return nil, fmt.Errorf("download source: %w", err)
+	}
+	result, err := rewriteSource(input, output, rewriteOpts)
+	if outcome, ok := skippedOutcome(err); ok {
+		return &faststartResult{outcome: outcome, originalSize: size, fileSize: size}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rewrite source: %w", err)
+	}
+	if !result.Rewritten {
+		// 上次执行清除的元数据已无从得知，保留记录中的值
+		checksum, err := fileChecksum(input)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.saveSource(ctx, video, size, checksum, stat.ETag(), video.StrippedMetadata()); err != nil {
+			return nil, err
+		}
+		return &faststartResult{outcome: vo.FaststartOutcomeUnchanged, originalSize: size, fileSize: size}, nil
+	}
+
+	// 下载和改写期间源文件可能已被替换，覆盖前再确认一次
+	latest, err := s.videoRepo.FindByUUID(ctx, video.UUID())
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.StoragePath() != video.StoragePath() || latest.ETag() != video.ETag() {
+		return nil, errSourceChanged
+	}
+	receipt, fileSize, err := s.upload(ctx, video.StoragePath(), output)
+	if err != nil {
+		return nil, fmt.Errorf("upload rewritten source: %w", err)
+	}
+	if err := s.saveSource(ctx, latest, fileSize, receipt.Checksum(), receipt.ETag(), result.Stripped); err != nil {
+		return nil, err
+	}
+	if len(result.Stripped) > 0 {
+		logger.Info(fmt.Sprintf("faststart video %s: stripped metadata %v", video.UUID(), result.Stripped))
+	}
+	return &faststartResult{outcome: vo.FaststartOutcomeRewritten, originalSize: size, fileSize: fileSize}, nil
+}
+
+// skippedOutcome 不是 MP4 或结构无法安全改写时源文件保持不变，不视为失败
+func skippedOutcome(err error) (vo.FaststartOutcome, bool) {
+	switch {
+	case errors.Is(err, mp4.ErrNotMP4):
+		return vo.FaststartOutcomeNotMP4, true
+	case errors.Is(err, mp4.ErrUnsupported):
+		return vo.FaststartOutcomeUnsupported, true
+	}
+	return vo.FaststartOutcomeNone, false
+}
+
+// saveSource 保存覆盖后源文件的属性，记录已被并发修改时改写后的内容与原文件是同一个视频，只记录日志
+func (s *faststartServiceImpl) saveSource(ctx context.Context, video *entity.Video, fileSize int64, checksum vo.Checksum,
+	etag string, strippedMetadata []string) error {
+	previousETag := video.RewriteSource(fileSize, checksum, etag, strippedMetadata)
+	ok, err := s.videoRepo.UpdateSourceContent(ctx, video, previousETag)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.Error(fmt.Sprintf("faststart video %s: source record changed concurrently, checksum not updated", video.UUID()))
+	}
+	return nil
+}
+
+// upload 以原存储路径上传改写后的文件，同时计算新的校验和，返回上传回执和文件大小
+func (s *faststartServiceImpl) upload(ctx context.Context, objectName, localPath string) (vo.UploadReceipt, int64, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return vo.UploadReceipt{}, 0, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return vo.UploadReceipt{}, 0, err
+	}
+	receipt, err := s.minioService.UploadStream(ctx, objectName, file, stat.Size(), vo.Checksum{})
+	return receipt, stat.Size(), err
+}
+
+// finishCanceled 结束一次被取消的处理
+func (s *faststartServiceImpl) finishCanceled(ctx context.Context, job *entity.FaststartJob, reason string) error {
+	job.Cancel(reason)
+	if _, err := s.faststartRepo.Finish(ctx, job); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// objectReaderAt 按范围读取对象明文，判断是否需要改写时只读取顶层box的头部和 moov
+type objectReaderAt struct {
+	ctx          context.Context
+	minioService gateway.MinioService
+	objectName   string
+	size         int64
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), r.size-offset)
+	reader, err := r.minioService.OpenObject(r.ctx, r.objectName, offset, length)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	n, err := io.ReadFull(reader, p[:length])
+	if err == nil && length < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// rewriteSource 按选项改写input写入output，不需要改写时output为空文件，返回的 Rewritten 为false
+func rewriteSource(input, output string, opts mp4.Options) (*mp4.Result, error) {
+	src, err := os.Open(input)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	stat, err := src.Stat()
+	if err != nil {
+		return nil, err
+	}
+	dst, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+	result, err := mp4.Rewrite(dst, src, stat.Size(), opts)
+	if err != nil {
+		dst.Close()
+		return nil, err
+	}
+	return result, dst.Close()
+}
+
+// fileChecksum 计算本地文件的校验和
+func fileChecksum(path string) (vo.Checksum, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return vo.Checksum{}, err
+	}
+	defer file.Close()
+	hasher := vo.NewChecksumHasher()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return vo.Checksum{}, err
+	}
+	return hasher.Sum(), nil
+}
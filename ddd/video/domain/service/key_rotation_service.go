@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+// rewrapBatchSize 每批重新包装的内容密钥数
+const rewrapBatchSize = 500
+
+var (
+	keyRotationServiceOnce      sync.Once
+	singletonKeyRotationService KeyRotationService
+)
+
+// KeyRotationService 主密钥轮换服务
+type KeyRotationService interface {
+	// RewrapKeys 用当前主密钥重新包装 videos/ 下所有加密对象的数据密钥和HLS内容密钥，完成后旧主密钥即可下线
+	RewrapKeys(ctx context.Context) (*entity.RewrapReport, error)
+}
+
+type keyRotationServiceImpl struct {
+	minioService   gateway.MinioService
+	contentKeyRepo repo.ContentKeyRepository
+	// running 同一进程内同时只允许一次重新包装
+	running sync.Mutex
+}
+
+// DefaultKeyRotationService 获取默认主密钥轮换服务实例
+func DefaultKeyRotationService() KeyRotationService {
+	assert.NotCircular()
+	keyRotationServiceOnce.Do(func() {
+		singletonKeyRotationService = &keyRotationServiceImpl{
+			minioService:   minio.DefaultMinioService(),
+			contentKeyRepo: persistence.NewContentKeyRepository(),
+		}
+	})
+	assert.NotNil(singletonKeyRotationService)
+	return singletonKeyRotationService
+}
+
+// RewrapKeys 重新包装数据密钥，只改写对象元数据，不重新加密视频数据；可重复执行
+func (s *keyRotationServiceImpl) RewrapKeys(ctx context.Context) (*entity.RewrapReport, error) {
+	activeKeyID := s.minioService.ActiveKeyID()
+	if activeKeyID == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrEncryptionDisabled, nil)
+	}
+	if !s.running.TryLock() {
+		return nil, errno.NewSimpleBizError(errno.ErrRewrapRunning, nil)
+	}
+	defer s.running.Unlock()
+
+	report := entity.NewRewrapReport(activeKeyID, time.Now())
+	objects, err := s.minioService.ListObjects(ctx, vo.VideoObjectPrefix)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	for _, object := range objects {
+		if ctx.Err() != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		outcome, err := s.minioService.RewrapObjectKey(ctx, object.Key())
+		if err != nil {
+			logger.Error(fmt.Sprintf("rewrap object %s failed: %v", object.Key(), err))
+			report.AddFailure(object.Key(), err.Error())
+			continue
+		}
+		report.Add(outcome)
+	}
+	if err := s.rewrapContentKeys(ctx, activeKeyID, report); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	report.Finish(time.Now())
+	logger.Info(fmt.Sprintf("rewrap finished active_key_id: %s, rewrapped: %d, failed: %d",
+		activeKeyID, report.Count(vo.RewrapOutcomeRewrapped), len(report.Failures())))
+	return report, nil
+}
+
+// rewrapContentKeys 重新包装数据库中的HLS内容密钥，失败的密钥以 content_key/<UUID> 记入报告
+func (s *keyRotationServiceImpl) rewrapContentKeys(ctx context.Context, activeKeyID string, report *entity.RewrapReport) error {
+	afterUUID := ""
+	for {
+		keys, err := s.contentKeyRepo.FindNotWrappedWith(ctx, activeKeyID, afterUUID, rewrapBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			afterUUID = key.UUID()
+			if err := s.rewrapContentKey(ctx, key); err != nil {
+				logger.Error(fmt.Sprintf("rewrap content key %s failed: %v", key.UUID(), err))
+				report.AddFailure("content_key/"+key.UUID(), err.Error())
+				continue
+			}
+			report.Add(vo.RewrapOutcomeRewrapped)
+		}
+		if len(keys) < rewrapBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *keyRotationServiceImpl) rewrapContentKey(ctx context.Context, key *entity.ContentKey) error {
+	previousKeyID := key.MasterKeyID()
+	plain, err := s.minioService.UnwrapKey(previousKeyID, key.WrappedKey())
+	if err != nil {
+		return err
+	}
+	masterKeyID, wrapped, err := s.minioService.WrapKey(plain)
+	if err != nil {
+		return err
+	}
+	key.Rewrap(masterKeyID, wrapped)
+	ok, err := s.contentKeyRepo.UpdateWrappedKey(ctx, key, previousKeyID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("content key changed concurrently")
+	}
+	return nil
+}
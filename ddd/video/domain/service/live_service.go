@@ -0,0 +1,501 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/hls"
+	"go-video/pkg/logger"
+)
+
+// liveSegmentContentType 编码器推送的 MPEG-TS 分片
+const liveSegmentContentType = "video/mp2t"
+
+// liveRegisterRetries 并发登记分片时序列号被占用的最多重试次数
+const liveRegisterRetries = 3
+
+var (
+	liveServiceOnce      sync.Once
+	singletonLiveService LiveService
+)
+
+// LiveService 直播服务：编码器推送的分片先暂存，随后推送的播放列表声明分片时长，按播放列表中的顺序登记到直播；
+// 观众拉取最后若干个已登记分片组成的滑动窗口。直播结束后已登记的分片按顺序拼接为普通视频的源文件
+type LiveService interface {
+	// Create 创建直播，返回只在此时可见的推流密钥
+	Create(ctx context.Context, userUUID, title, description string) (*entity.LiveStream, string, error)
+	// Get 获取直播，不存在时返回 ErrLiveStreamNotFound
+	Get(ctx context.Context, streamUUID string) (*entity.LiveStream, error)
+	// List 获取用户创建的全部直播
+	List(ctx context.Context, userUUID string) ([]*entity.LiveStream, error)
+	// Authenticate 根据推流密钥查找直播，密钥不存在时返回 ErrStreamKeyInvalid
+	Authenticate(ctx context.Context, streamKey string) (*entity.LiveStream, error)
+	// IngestSegment 暂存编码器推送的分片，等待播放列表声明后登记
+	IngestSegment(ctx context.Context, stream *entity.LiveStream, filename string, content []byte) error
+	// IngestPlaylist 登记播放列表中已暂存的分片，返回登记的数量；播放列表包含 EXT-X-ENDLIST 时结束直播
+	IngestPlaylist(ctx context.Context, stream *entity.LiveStream, content []byte) (int, error)
+	// End 结束直播，已结束时直接返回
+	End(ctx context.Context, stream *entity.LiveStream) error
+	// EndIdle 结束超过idleTimeout没有推流的直播，返回结束的数量
+	EndIdle(ctx context.Context, idleTimeout time.Duration, limit int) (int, error)
+	// Window 获取观众播放列表中的最后size个分片
+	Window(ctx context.Context, stream *entity.LiveStream, size int) ([]*entity.LiveSegment, error)
+	// ClaimConvertible 领取最多limit个等待转换的直播，租约为lease
+	ClaimConvertible(ctx context.Context, limit int, lease time.Duration) ([]*entity.LiveStream, error)
+	// Convert 执行已领取的转换：创建视频后把分片拼接上传为源文件，视频以initialReview进入审核
+	Convert(ctx context.Context, stream *entity.LiveStream, opts vo.LiveOptions, initialReview vo.ReviewStatus) error
+}
+
+type liveServiceImpl struct {
+	minioService gateway.MinioService
+	videoService VideoService
+	videoRepo    repo.VideoRepository
+	liveRepo     repo.LiveStreamRepository
+}
+
+// DefaultLiveService 获取默认直播服务实例
+func DefaultLiveService() LiveService {
+	assert.NotCircular()
+	liveServiceOnce.Do(func() {
+		singletonLiveService = &liveServiceImpl{
+			minioService: minio.DefaultMinioService(),
+			videoService: DefaultVideoService(),
+			videoRepo:    persistence.NewVideoRepository(),
+			liveRepo:     persistence.NewLiveStreamRepository(),
+		}
+	})
+	assert.NotNil(singletonLiveService)
+	return singletonLiveService
+}
+
+// Create 库中只保存推流密钥的哈希
+func (s *liveServiceImpl) Create(ctx context.Context, userUUID, title, description string) (*entity.LiveStream, string, error) {
+	stream, streamKey := entity.DefaultLiveStream(userUUID, title, description)
+	if err := s.liveRepo.Create(ctx, stream); err != nil {
+		return nil, "", errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return stream, streamKey, nil
+}
+
+// Get 获取直播
+func (s *liveServiceImpl) Get(ctx context.Context, streamUUID string) (*entity.LiveStream, error) {
+	stream, err := s.liveRepo.FindByUUID(ctx, streamUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if stream == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrLiveStreamNotFound, nil)
+	}
+	return stream, nil
+}
+
+// List 获取用户的直播
+func (s *liveServiceImpl) List(ctx context.Context, userUUID string) ([]*entity.LiveStream, error) {
+	streams, err := s.liveRepo.FindByUser(ctx, userUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return streams, nil
+}
+
+// Authenticate 按密钥的哈希查找，不区分密钥错误和直播不存在
+func (s *liveServiceImpl) Authenticate(ctx context.Context, streamKey string) (*entity.LiveStream, error) {
+	if streamKey == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrStreamKeyInvalid, nil)
+	}
+	stream, err := s.liveRepo.FindByStreamKeyHash(ctx, vo.HashStreamKey(streamKey))
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if stream == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrStreamKeyInvalid, nil)
+	}
+	return stream, nil
+}
+
+// IngestSegment 编码器重启后可能以相同文件名推送新的分片，暂存路径被覆盖前的分片如果已被播放列表声明则已经登记
+func (s *liveServiceImpl) IngestSegment(ctx context.Context, stream *entity.LiveStream, filename string, content []byte) error {
+	if stream.Status() == vo.LiveStreamStatusEnded {
+		return errno.NewSimpleBizError(errno.ErrLiveStreamEnded, nil)
+	}
+	if !vo.IsLiveSegmentFilename(filename) {
+		return errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, nil, "segment filename must match [A-Za-z0-9_.-]+.ts")
+	}
+	objectName := vo.LiveIncomingObjectName(stream.UUID(), filename)
+	err := s.minioService.PutMediaObject(ctx, objectName, bytes.NewReader(content), int64(len(content)), liveSegmentContentType)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	if stream.Status() == vo.LiveStreamStatusLive {
+		if err := s.liveRepo.TouchIngest(ctx, stream.UUID(), time.Now()); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+	}
+	return nil
+}
+
+// IngestPlaylist 播放列表中仍在暂存路径下的分片是还没有登记的分片，按播放列表中的顺序登记；
+// 已登记的分片已移出暂存路径，重复推送同一播放列表不会重复登记。第一个分片登记后直播进入直播中
+func (s *liveServiceImpl) IngestPlaylist(ctx context.Context, stream *entity.LiveStream, content []byte) (int, error) {
+	if stream.Status() == vo.LiveStreamStatusEnded {
+		return 0, errno.NewSimpleBizError(errno.ErrLiveStreamEnded, nil)
+	}
+	playlist, err := hls.ParseMediaPlaylist(content)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, err, err.Error())
+	}
+
+	registered := 0
+	for _, declared := range playlist.Segments {
+		filename := path.Base(declared.URI)
+		if !vo.IsLiveSegmentFilename(filename) {
+			return registered, errno.NewSimpleBizError(errno.ErrLiveIngestInvalid, nil,
+				fmt.Sprintf("playlist references unsupported segment %q", declared.URI))
+		}
+		ok, err := s.register(ctx, stream, filename, declared.Duration)
+		if err != nil {
+			return registered, err
+		}
+		if ok {
+			registered++
+		}
+	}
+
+	now := time.Now()
+	if registered > 0 {
+		if err := s.goLive(ctx, stream, now); err != nil {
+			return registered, err
+		}
+	}
+	if playlist.Ended {
+		return registered, s.End(ctx, stream)
+	}
+	return registered, nil
+}
+
+// register 把暂存的分片移到分片自己的路径后登记，暂存路径下不存在时（已登记或还没推送）返回false。
+// 先移动后登记：登记前中断时分片成为直播前缀下的孤立对象，转换完成后随直播前缀一起删除
+func (s *liveServiceImpl) register(ctx context.Context, stream *entity.LiveStream, filename string,
+	duration time.Duration) (bool, error) {
+	incoming := vo.LiveIncomingObjectName(stream.UUID(), filename)
+	stat, err := s.minioService.StatObject(ctx, incoming)
+	if err != nil {
+		return false, nil
+	}
+	last, err := s.liveRepo.FindLastSegments(ctx, stream.UUID(), 1)
+	if err != nil {
+		return false, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	segment := entity.DefaultLiveSegment(stream.UUID(), nextSequence(last), filename, duration, stat.Size())
+	if err := s.minioService.MoveObject(ctx, incoming, segment.Path()); err != nil {
+		// 同一播放列表被并发推送时，另一个请求已移走该分片
+		logger.Info(fmt.Sprintf("live %s segment %s not registered: %v", stream.UUID(), filename, err))
+		return false, nil
+	}
+	for range liveRegisterRetries {
+		ok, err := s.liveRepo.CreateSegment(ctx, segment)
+		if err != nil {
+			return false, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			return true, nil
+		}
+		last, err = s.liveRepo.FindLastSegments(ctx, stream.UUID(), 1)
+		if err != nil {
+			return false, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		segment.Resequence(nextSequence(last))
+	}
+	return false, errno.NewSimpleBizError(errno.ErrInternalServer,
+		fmt.Errorf("live %s segment %s: sequence conflict after %d retries", stream.UUID(), filename, liveRegisterRetries))
+}
+
+// nextSequence 最后一个分片之后的序列号，没有分片时为0
+func nextSequence(last []*entity.LiveSegment) int {
+	if len(last) == 0 {
+		return 0
+	}
+	return last[len(last)-1].Sequence() + 1
+}
+
+// goLive 空闲的直播进入直播中，已在直播中时只记录推流时间
+func (s *liveServiceImpl) goLive(ctx context.Context, stream *entity.LiveStream, now time.Time) error {
+	from := stream.Status()
+	if stream.GoLive(now) {
+		if _, err := s.liveRepo.UpdateStatus(ctx, stream, from); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		logger.Info(fmt.Sprintf("live %s is live", stream.UUID()))
+		return nil
+	}
+	if err := s.liveRepo.TouchIngest(ctx, stream.UUID(), now); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// End 有已登记的分片时等待转换为视频；与自动结束并发时只有一个生效
+func (s *liveServiceImpl) End(ctx context.Context, stream *entity.LiveStream) error {
+	last, err := s.liveRepo.FindLastSegments(ctx, stream.UUID(), 1)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	from := stream.Status()
+	if !stream.End(time.Now(), len(last) > 0) {
+		return nil
+	}
+	ok, err := s.liveRepo.UpdateStatus(ctx, stream, from)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if ok {
+		logger.Info(fmt.Sprintf("live %s ended with %d segments", stream.UUID(), nextSequence(last)))
+	}
+	return nil
+}
+
+// EndIdle 编码器断开后不会推送 EXT-X-ENDLIST，超过空闲时间后自动结束
+func (s *liveServiceImpl) EndIdle(ctx context.Context, idleTimeout time.Duration, limit int) (int, error) {
+	streams, err := s.liveRepo.FindIdle(ctx, time.Now().Add(-idleTimeout), limit)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	for _, stream := range streams {
+		if err := s.End(ctx, stream); err != nil {
+			return 0, err
+		}
+	}
+	return len(streams), nil
+}
+
+// Window 按序列号升序
+func (s *liveServiceImpl) Window(ctx context.Context, stream *entity.LiveStream, size int) ([]*entity.LiveSegment, error) {
+	segments, err := s.liveRepo.FindLastSegments(ctx, stream.UUID(), size)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return segments, nil
+}
+
+// ClaimConvertible 多实例同时领取时以比较并交换保证每个直播只有一个执行者
+func (s *liveServiceImpl) ClaimConvertible(ctx context.Context, limit int, lease time.Duration) ([]*entity.LiveStream, error) {
+	now := time.Now()
+	candidates, err := s.liveRepo.FindConvertible(ctx, now, limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	claimed := make([]*entity.LiveStream, 0, len(candidates))
+	for _, stream := range candidates {
+		ok, err := s.liveRepo.ClaimConversion(ctx, stream, now.Add(lease))
+		if err != nil {
+			return claimed, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			claimed = append(claimed, stream)
+		}
+	}
+	return claimed, nil
+}
+
+// Convert 不续租，租约应长于超时时间；ctx 被取消（服务停止）时保持转换中状态，租约到期后由其他实例重新执行。
+// 视频和上传任务在上传前创建并记录在直播上，重新执行时继续使用；最后一次失败时视频标记为上传失败
+func (s *liveServiceImpl) Convert(ctx context.Context, stream *entity.LiveStream, opts vo.LiveOptions,
+	initialReview vo.ReviewStatus) error {
+	segments, err := s.liveRepo.FindSegments(ctx, stream.UUID())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if len(segments) == 0 {
+		return s.finishConversion(ctx, stream, func() { stream.CancelConversion("no segments") })
+	}
+	video, task, err := s.prepareVideo(ctx, stream, segments, initialReview)
+	if err != nil {
+		return err
+	}
+	if video == nil {
+		return s.finishConversion(ctx, stream, func() { stream.CancelConversion("video deleted") })
+	}
+	if video.Status() != vo.VideoStatusUploading {
+		// 上次执行已完成上传，没来得及保存转换结果
+		return s.finishConversion(ctx, stream, stream.SucceedConversion)
+	}
+
+	workCtx := ctx
+	if opts.Timeout() > 0 {
+		var cancel context.CancelFunc
+		workCtx, cancel = context.WithTimeout(ctx, opts.Timeout())
+		defer cancel()
+	}
+	receipt, runErr := s.upload(workCtx, task, segments)
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		reason := runErr.Error()
+		if errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+			reason = fmt.Sprintf("conversion timed out after %s", opts.Timeout())
+		}
+		logger.Error(fmt.Sprintf("live %s conversion to video %s attempt %d failed: %s", stream.UUID(), video.UUID(),
+			stream.VodAttempts(), reason))
+		stream.FailConversion(reason, opts.MaxAttempts(), opts.RetryDelay())
+		if stream.VodStatus() == vo.RenditionStatusFailed {
+			if err := s.videoService.CompleteUpload(ctx, video.UUID(), task.UUID(), vo.UploadReceipt{}, runErr); err != nil {
+				logger.Error(fmt.Sprintf("live %s CompleteUpload video_uuid: %s, error: %v", stream.UUID(), video.UUID(), err))
+			}
+		}
+		if _, err := s.liveRepo.SaveConversion(ctx, stream); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		return nil
+	}
+
+	if err := s.videoService.CompleteUpload(ctx, video.UUID(), task.UUID(), receipt, nil); err != nil {
+		return err
+	}
+	if err := s.finishConversion(ctx, stream, stream.SucceedConversion); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("live %s converted to video %s from %d segments", stream.UUID(), video.UUID(), len(segments)))
+	s.purge(ctx, stream)
+	return nil
+}
+
+// prepareVideo 第一次执行时创建视频和上传任务并先记录在直播上；上传任务存在而视频不存在时视频已被删除，返回nil
+func (s *liveServiceImpl) prepareVideo(ctx context.Context, stream *entity.LiveStream, segments []*entity.LiveSegment,
+	initialReview vo.ReviewStatus) (*entity.Video, *entity.VideoUploadTaskEntity, error) {
+	if stream.TaskUuid() != "" {
+		task, err := s.videoRepo.FindUploadTask(ctx, stream.TaskUuid())
+		if err != nil {
+			return nil, nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if task != nil {
+			video, err := s.videoRepo.FindByUUID(ctx, stream.VideoUuid())
+			if err != nil {
+				return nil, nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+			}
+			return video, task, nil
+		}
+	}
+
+	var size int64
+	for _, segment := range segments {
+		size += segment.Size()
+	}
+	filename := fmt.Sprintf("live-%s.ts", stream.UUID())
+	storagePath := s.minioService.GenerateObjectName(stream.UserUuid(), filename)
+	video := entity.DefaultVideo(stream.UserUuid(), stream.Title(), stream.Description(), filename, size, "ts", storagePath,
+		vo.VideoStatusUploading)
+	video.SetReview(initialReview, "")
+	task := entity.DefaultVideoUploadTaskEntity(stream.UserUuid(), video.UUID(), vo.VideoUploadTaskStatusInit, "", nil, storagePath)
+	stream.AttachVideo(video.UUID(), task.UUID())
+	ok, err := s.liveRepo.SaveConversion(ctx, stream)
+	if err != nil {
+		return nil, nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		return nil, nil, errno.NewSimpleBizError(errno.ErrInternalServer, fmt.Errorf("live %s conversion lease lost", stream.UUID()))
+	}
+	if err := s.videoRepo.CreateVideo(ctx, video, task); err != nil {
+		return nil, nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if err := s.videoService.StartUpload(ctx, task.UUID()); err != nil {
+		return nil, nil, err
+	}
+	return video, task, nil
+}
+
+// upload 按序列号依次读取分片拼接为一个 MPEG-TS 源文件，以流的方式上传，不落本地磁盘
+func (s *liveServiceImpl) upload(ctx context.Context, task *entity.VideoUploadTaskEntity,
+	segments []*entity.LiveSegment) (vo.UploadReceipt, error) {
+	var size int64
+	for _, segment := range segments {
+		size += segment.Size()
+	}
+	reader := &segmentReader{ctx: ctx, minioService: s.minioService, segments: segments}
+	defer reader.Close()
+	return s.minioService.UploadStream(ctx, task.ObjectName(), reader, size, vo.Checksum{})
+}
+
+// purge 删除直播前缀下的分片，失败只记录日志，不影响视频
+func (s *liveServiceImpl) purge(ctx context.Context, stream *entity.LiveStream) {
+	objects, err := s.minioService.ListObjects(ctx, vo.LiveStreamPrefix(stream.UUID()))
+	if err != nil {
+		logger.Error(fmt.Sprintf("live %s list segments: %v", stream.UUID(), err))
+		return
+	}
+	for _, object := range objects {
+		if err := s.minioService.DeleteVideo(ctx, object.Key()); err != nil {
+			logger.Error(fmt.Sprintf("live %s delete %s: %v", stream.UUID(), object.Key(), err))
+		}
+	}
+}
+
+// finishConversion 保存一次转换的结果
+func (s *liveServiceImpl) finishConversion(ctx context.Context, stream *entity.LiveStream, finish func()) error {
+	finish()
+	if _, err := s.liveRepo.SaveConversion(ctx, stream); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// segmentReader 依次打开每个分片，读完一个再打开下一个
+type segmentReader struct {
+	ctx          context.Context
+	minioService gateway.MinioService
+	segments     []*entity.LiveSegment
+	current      io.ReadCloser
+}
+
+func (r *segmentReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.segments) == 0 {
+				return 0, io.EOF
+			}
+			segment := r.segments[0]
+			r.segments = r.segments[1:]
+			if segment.Size() == 0 {
+				continue
+			}
+			object, err := r.minioService.OpenObject(r.ctx, segment.Path(), 0, segment.Size())
+			if err != nil {
+				return 0, fmt.Errorf("open segment %d: %w", segment.Sequence(), err)
+			}
+			r.current = object
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *segmentReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
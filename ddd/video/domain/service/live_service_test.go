@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+)
+
+// memoryLiveRepo 内存中的直播仓储，直播状态和转换领取按比较并交换保存
+type memoryLiveRepo struct {
+	repo.LiveStreamRepository
+
+	mu       sync.Mutex
+	streams  map[string]*entity.LiveStream
+	statuses map[string]vo.LiveStreamStatus
+	segments map[string][]*entity.LiveSegment
+}
+
+func newMemoryLiveRepo() *memoryLiveRepo {
+	return &memoryLiveRepo{
+		streams:  map[string]*entity.LiveStream{},
+		statuses: map[string]vo.LiveStreamStatus{},
+		segments: map[string][]*entity.LiveSegment{},
+	}
+}
+
+func (r *memoryLiveRepo) Create(ctx context.Context, stream *entity.LiveStream) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.streams[stream.UUID()] = stream
+	r.statuses[stream.UUID()] = stream.Status()
+	return nil
+}
+
+func (r *memoryLiveRepo) UpdateStatus(ctx context.Context, stream *entity.LiveStream, from vo.LiveStreamStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.statuses[stream.UUID()] != from {
+		return false, nil
+	}
+	r.statuses[stream.UUID()] = stream.Status()
+	return true, nil
+}
+
+func (r *memoryLiveRepo) TouchIngest(ctx context.Context, streamUUID string, at time.Time) error {
+	return nil
+}
+
+// convertible 等待转换且重试时间已到，或转换租约已过期
+func convertible(stream *entity.LiveStream, now time.Time) bool {
+	lease := stream.VodLeaseUntil()
+	switch stream.VodStatus() {
+	case vo.RenditionStatusPending:
+		return lease == nil || !lease.After(now)
+	case vo.RenditionStatusRunning:
+		return lease != nil && lease.Before(now)
+	}
+	return false
+}
+
+func (r *memoryLiveRepo) FindConvertible(ctx context.Context, now time.Time, limit int) ([]*entity.LiveStream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var streams []*entity.LiveStream
+	for uuid, stream := range r.streams {
+		if r.statuses[uuid] == vo.LiveStreamStatusEnded && convertible(stream, now) && len(streams) < limit {
+			streams = append(streams, stream)
+		}
+	}
+	return streams, nil
+}
+
+func (r *memoryLiveRepo) ClaimConversion(ctx context.Context, stream *entity.LiveStream, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !convertible(stream, time.Now()) {
+		return false, nil
+	}
+	stream.StartConversion(leaseUntil)
+	return true, nil
+}
+
+func (r *memoryLiveRepo) SaveConversion(ctx context.Context, stream *entity.LiveStream) (bool, error) {
+	return true, nil
+}
+
+func (r *memoryLiveRepo) CreateSegment(ctx context.Context, segment *entity.LiveSegment) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	segments := r.segments[segment.StreamUuid()]
+	for _, existing := range segments {
+		if existing.Sequence() == segment.Sequence() {
+			return false, nil
+		}
+	}
+	segments = append(segments, segment)
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Sequence() < segments[j].Sequence() })
+	r.segments[segment.StreamUuid()] = segments
+	return true, nil
+}
+
+func (r *memoryLiveRepo) FindLastSegments(ctx context.Context, streamUUID string, limit int) ([]*entity.LiveSegment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	segments := r.segments[streamUUID]
+	if len(segments) > limit {
+		segments = segments[len(segments)-limit:]
+	}
+	return append([]*entity.LiveSegment(nil), segments...), nil
+}
+
+func (r *memoryLiveRepo) FindSegments(ctx context.Context, streamUUID string) ([]*entity.LiveSegment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*entity.LiveSegment(nil), r.segments[streamUUID]...), nil
+}
+
+// uploadCompletion 一次 CompleteUpload 调用
+type uploadCompletion struct {
+	videoUUID string
+	taskUUID  string
+	err       error
+}
+
+// recordingVideoService 记录上传任务的开始和结束，不迁移视频状态
+type recordingVideoService struct {
+	VideoService
+
+	mu        sync.Mutex
+	started   []string
+	completed []uploadCompletion
+}
+
+func (s *recordingVideoService) StartUpload(ctx context.Context, taskUUID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = append(s.started, taskUUID)
+	return nil
+}
+
+func (s *recordingVideoService) CompleteUpload(ctx context.Context, videoUUID, taskUUID string, receipt vo.UploadReceipt,
+	uploadErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, uploadCompletion{videoUUID: videoUUID, taskUUID: taskUUID, err: uploadErr})
+	return nil
+}
+
+// flakyStore 前failures次上传失败
+type flakyStore struct {
+	*memoryStore
+	failures int
+}
+
+func (s *flakyStore) UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64,
+	expected vo.Checksum) (vo.UploadReceipt, error) {
+	if s.failures > 0 {
+		s.failures--
+		return vo.UploadReceipt{}, errors.New("storage unavailable")
+	}
+	return s.memoryStore.UploadStream(ctx, objectName, reader, size, expected)
+}
+
+type liveFixture struct {
+	store     *memoryStore
+	liveRepo  *memoryLiveRepo
+	videoRepo *memoryVideoRepo
+	videos    *recordingVideoService
+	service   *liveServiceImpl
+	stream    *entity.LiveStream
+}
+
+// newLiveFixture 创建一个空闲的直播，uploadFailures 为转换上传前几次失败的次数
+func newLiveFixture(t *testing.T, uploadFailures int) *liveFixture {
+	t.Helper()
+	f := &liveFixture{
+		store:     newMemoryStore(),
+		liveRepo:  newMemoryLiveRepo(),
+		videoRepo: newMemoryVideoRepo(),
+		videos:    &recordingVideoService{},
+	}
+	var minioService gateway.MinioService = f.store
+	if uploadFailures > 0 {
+		minioService = &flakyStore{memoryStore: f.store, failures: uploadFailures}
+	}
+	f.service = &liveServiceImpl{minioService: minioService, videoService: f.videos, videoRepo: f.videoRepo, liveRepo: f.liveRepo}
+	stream, _, err := f.service.Create(context.Background(), "user-1", "launch", "live launch")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.stream = stream
+	return f
+}
+
+// livePlaylist 编码器推送的播放列表，每个分片2秒
+func livePlaylist(ended bool, filenames ...string) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n")
+	for _, filename := range filenames {
+		fmt.Fprintf(&b, "#EXTINF:2.000,\n%s\n", filename)
+	}
+	if ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return b.String()
+}
+
+func segmentContent(filename string) []byte {
+	return []byte("ts:" + filename + ";")
+}
+
+// ingest 推送分片后推送播放列表，返回登记的数量和第一个错误
+func (f *liveFixture) ingest(segments []string, playlist string) (int, error) {
+	ctx := context.Background()
+	for _, filename := range segments {
+		if err := f.service.IngestSegment(ctx, f.stream, filename, segmentContent(filename)); err != nil {
+			return 0, err
+		}
+	}
+	if playlist == "" {
+		return 0, nil
+	}
+	return f.service.IngestPlaylist(ctx, f.stream, []byte(playlist))
+}
+
+func TestLiveIngest(t *testing.T) {
+	type step struct {
+		segments       []string
+		playlist       string
+		wantRegistered int
+		wantErr        bool
+	}
+	tests := []struct {
+		name         string
+		steps        []step
+		wantWindow   []string
+		wantStatus   vo.LiveStreamStatus
+		wantVod      vo.RenditionStatus
+		wantIncoming int
+	}{
+		{
+			name: "sliding window",
+			steps: []step{
+				{segments: []string{"s0.ts", "s1.ts", "s2.ts"}, playlist: livePlaylist(false, "s0.ts", "s1.ts", "s2.ts"), wantRegistered: 3},
+				{segments: []string{"s3.ts"}, playlist: livePlaylist(false, "s1.ts", "s2.ts", "s3.ts"), wantRegistered: 1},
+				{segments: []string{"s4.ts"}, playlist: livePlaylist(false, "s2.ts", "s3.ts", "s4.ts"), wantRegistered: 1},
+			},
+			wantWindow: []string{"s2.ts", "s3.ts", "s4.ts"},
+			wantStatus: vo.LiveStreamStatusLive,
+		},
+		{
+			name: "repeated playlist",
+			steps: []step{
+				{segments: []string{"a.ts"}, playlist: livePlaylist(false, "a.ts"), wantRegistered: 1},
+				{playlist: livePlaylist(false, "a.ts")},
+			},
+			wantWindow: []string{"a.ts"},
+			wantStatus: vo.LiveStreamStatusLive,
+		},
+		{
+			name: "segment pushed after playlist",
+			steps: []step{
+				{segments: []string{"a.ts"}, playlist: livePlaylist(false, "a.ts", "b.ts"), wantRegistered: 1},
+				{segments: []string{"b.ts"}, playlist: livePlaylist(false, "a.ts", "b.ts"), wantRegistered: 1},
+			},
+			wantWindow: []string{"a.ts", "b.ts"},
+			wantStatus: vo.LiveStreamStatusLive,
+		},
+		{
+			name: "playlist order decides sequence",
+			steps: []step{
+				{segments: []string{"z.ts", "a.ts"}, playlist: livePlaylist(false, "z.ts", "a.ts"), wantRegistered: 2},
+			},
+			wantWindow: []string{"z.ts", "a.ts"},
+			wantStatus: vo.LiveStreamStatusLive,
+		},
+		{
+			name: "segments without playlist stay incoming",
+			steps: []step{
+				{segments: []string{"a.ts", "b.ts"}},
+				{playlist: livePlaylist(false, "c.ts")},
+			},
+			wantStatus:   vo.LiveStreamStatusIdle,
+			wantIncoming: 2,
+		},
+		{
+			name: "endlist ends the stream",
+			steps: []step{
+				{segments: []string{"a.ts", "b.ts"}, playlist: livePlaylist(true, "a.ts", "b.ts"), wantRegistered: 2},
+				{segments: []string{"c.ts"}, wantErr: true},
+				{playlist: livePlaylist(false, "c.ts"), wantErr: true},
+			},
+			wantWindow: []string{"a.ts", "b.ts"},
+			wantStatus: vo.LiveStreamStatusEnded,
+			wantVod:    vo.RenditionStatusPending,
+		},
+		{
+			name: "endlist without segments",
+			steps: []step{
+				{playlist: livePlaylist(true)},
+			},
+			wantStatus: vo.LiveStreamStatusEnded,
+		},
+		{
+			name: "invalid segment filename",
+			steps: []step{
+				{segments: []string{"../a.ts"}, wantErr: true},
+				{segments: []string{"a.mp4"}, wantErr: true},
+			},
+			wantStatus: vo.LiveStreamStatusIdle,
+		},
+		{
+			name: "invalid playlist",
+			steps: []step{
+				{playlist: "not a playlist", wantErr: true},
+				{playlist: livePlaylist(false, "a.mp4"), wantErr: true},
+			},
+			wantStatus: vo.LiveStreamStatusIdle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newLiveFixture(t, 0)
+			for i, step := range tt.steps {
+				registered, err := f.ingest(step.segments, step.playlist)
+				if (err != nil) != step.wantErr {
+					t.Fatalf("step %d error = %v, wantErr %v", i, err, step.wantErr)
+				}
+				if registered != step.wantRegistered {
+					t.Fatalf("step %d registered = %d, want %d", i, registered, step.wantRegistered)
+				}
+			}
+
+			window, err := f.service.Window(context.Background(), f.stream, 3)
+			if err != nil {
+				t.Fatalf("Window: %v", err)
+			}
+			var filenames []string
+			for i, segment := range window {
+				filenames = append(filenames, segment.Filename())
+				if i > 0 && segment.Sequence() != window[i-1].Sequence()+1 {
+					t.Fatalf("window sequences not consecutive: %d after %d", segment.Sequence(), window[i-1].Sequence())
+				}
+				if segment.Duration() != 2*time.Second {
+					t.Fatalf("segment %s duration = %s", segment.Filename(), segment.Duration())
+				}
+				data, ok := f.store.get(segment.Path())
+				if !ok || string(data) != string(segmentContent(segment.Filename())) || segment.Size() != int64(len(data)) {
+					t.Fatalf("segment %s stored as %q (size %d)", segment.Filename(), data, segment.Size())
+				}
+				if !f.store.isEncrypted(segment.Path()) {
+					t.Fatalf("segment %s not stored through the encrypting upload", segment.Filename())
+				}
+			}
+			if !reflect.DeepEqual(filenames, tt.wantWindow) {
+				t.Fatalf("window = %v, want %v", filenames, tt.wantWindow)
+			}
+			if f.stream.Status() != tt.wantStatus || f.liveRepo.statuses[f.stream.UUID()] != tt.wantStatus {
+				t.Fatalf("status = %s (saved %s), want %s", f.stream.Status().Value(),
+					f.liveRepo.statuses[f.stream.UUID()].Value(), tt.wantStatus.Value())
+			}
+			if f.stream.VodStatus() != tt.wantVod {
+				t.Fatalf("vod status = %q, want %q", f.stream.VodStatus().Value(), tt.wantVod.Value())
+			}
+			incomingDir := path.Dir(vo.LiveIncomingObjectName(f.stream.UUID(), "a.ts")) + "/"
+			if incoming := f.store.keys(incomingDir); len(incoming) != tt.wantIncoming {
+				t.Fatalf("incoming = %v, want %d objects", incoming, tt.wantIncoming)
+			}
+		})
+	}
+}
+
+func TestLiveConvert(t *testing.T) {
+	tests := []struct {
+		name           string
+		uploadFailures int
+		maxAttempts    int
+		wantAttempts   int
+		wantVod        vo.RenditionStatus
+		wantUploadErr  bool
+	}{
+		{name: "first attempt", maxAttempts: 3, wantAttempts: 1, wantVod: vo.RenditionStatusSucceeded},
+		{name: "retried upload", uploadFailures: 1, maxAttempts: 3, wantAttempts: 2, wantVod: vo.RenditionStatusSucceeded},
+		{name: "attempts exhausted", uploadFailures: 2, maxAttempts: 2, wantAttempts: 2, wantVod: vo.RenditionStatusFailed,
+			wantUploadErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newLiveFixture(t, tt.uploadFailures)
+			if _, err := f.ingest([]string{"z.ts", "a.ts"}, livePlaylist(false, "z.ts", "a.ts")); err != nil {
+				t.Fatalf("ingest: %v", err)
+			}
+			if _, err := f.ingest([]string{"m.ts"}, livePlaylist(true, "a.ts", "m.ts")); err != nil {
+				t.Fatalf("ingest: %v", err)
+			}
+
+			opts := vo.NewLiveOptions(tt.maxAttempts, 0, time.Minute)
+			for attempt := 1; attempt <= tt.wantAttempts; attempt++ {
+				claimed, err := f.service.ClaimConvertible(ctx, 10, time.Hour)
+				if err != nil {
+					t.Fatalf("ClaimConvertible: %v", err)
+				}
+				if len(claimed) != 1 || claimed[0].UUID() != f.stream.UUID() {
+					t.Fatalf("attempt %d claimed %d streams", attempt, len(claimed))
+				}
+				if err := f.service.Convert(ctx, claimed[0], opts, vo.ReviewStatusPending); err != nil {
+					t.Fatalf("Convert: %v", err)
+				}
+			}
+			if claimed, _ := f.service.ClaimConvertible(ctx, 10, time.Hour); len(claimed) != 0 {
+				t.Fatalf("claimed %d streams after the conversion finished", len(claimed))
+			}
+			if f.stream.VodStatus() != tt.wantVod || f.stream.VodAttempts() != tt.wantAttempts {
+				t.Fatalf("vod = %s after %d attempts, want %s after %d", f.stream.VodStatus().Value(),
+					f.stream.VodAttempts(), tt.wantVod.Value(), tt.wantAttempts)
+			}
+
+			// 视频和上传任务只在第一次执行时创建，重试时继续使用
+			video, _ := f.videoRepo.FindByUUID(ctx, f.stream.VideoUuid())
+			task, _ := f.videoRepo.FindUploadTask(ctx, f.stream.TaskUuid())
+			if video == nil || task == nil || len(f.videoRepo.videos) != 1 {
+				t.Fatalf("created %d videos, stream video %q task %q", len(f.videoRepo.videos), f.stream.VideoUuid(), f.stream.TaskUuid())
+			}
+			if video.Title() != "launch" || video.ReviewStatus() != vo.ReviewStatusPending || task.ObjectName() != video.StoragePath() {
+				t.Fatalf("video title %q review %s object %q storage %q", video.Title(), video.ReviewStatus().Value(),
+					task.ObjectName(), video.StoragePath())
+			}
+			if !reflect.DeepEqual(f.videos.started, []string{task.UUID()}) {
+				t.Fatalf("started uploads = %v", f.videos.started)
+			}
+			if len(f.videos.completed) != 1 {
+				t.Fatalf("completed uploads = %v", f.videos.completed)
+			}
+			completion := f.videos.completed[0]
+			if completion.videoUUID != video.UUID() || completion.taskUUID != task.UUID() || (completion.err != nil) != tt.wantUploadErr {
+				t.Fatalf("completion = %+v, want error %v", completion, tt.wantUploadErr)
+			}
+
+			segments := f.store.keys(vo.LiveStreamPrefix(f.stream.UUID()))
+			source, uploaded := f.store.get(video.StoragePath())
+			if tt.wantUploadErr {
+				if uploaded || len(segments) != 3 {
+					t.Fatalf("failed conversion uploaded %v, kept segments %v", uploaded, segments)
+				}
+				return
+			}
+			// 分片按登记顺序拼接，与文件名无关；转换完成后删除直播前缀下的分片
+			if want := "ts:z.ts;ts:a.ts;ts:m.ts;"; string(source) != want {
+				t.Fatalf("source = %q, want %q", source, want)
+			}
+			if video.FileSize() != int64(len(source)) {
+				t.Fatalf("video size = %d, want %d", video.FileSize(), len(source))
+			}
+			if len(segments) != 0 {
+				t.Fatalf("segments not purged: %v", segments)
+			}
+		})
+	}
+}
+
+func TestLiveConvertWithoutSegments(t *testing.T) {
+	ctx := context.Background()
+	f := newLiveFixture(t, 0)
+	// 结束时已有分片记录，转换前分片记录丢失
+	f.stream.End(time.Now(), true)
+	f.liveRepo.statuses[f.stream.UUID()] = f.stream.Status()
+	claimed, err := f.service.ClaimConvertible(ctx, 10, time.Hour)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimConvertible = %d streams, %v", len(claimed), err)
+	}
+	if err := f.service.Convert(ctx, claimed[0], vo.NewLiveOptions(3, 0, time.Minute), vo.ReviewStatusNone); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if f.stream.VodStatus() != vo.RenditionStatusCanceled || len(f.videoRepo.videos) != 0 || len(f.videos.started) != 0 {
+		t.Fatalf("vod = %s, created %d videos", f.stream.VodStatus().Value(), len(f.videoRepo.videos))
+	}
+}
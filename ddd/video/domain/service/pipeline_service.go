@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+var (
+	pipelineServiceOnce      sync.Once
+	singletonPipelineService PipelineService
+)
+
+// PipelineStepRunner 执行流水线中的一类步骤：Start 调度对应的处理，之后由 Check 查看处理是否完成
+type PipelineStepRunner interface {
+	// Start 为视频当前的源文件调度处理，返回错误时本次执行失败
+	Start(ctx context.Context, video *entity.Video) error
+	// Check 查看处理的进度，状态为执行中、完成、失败或取消（后续步骤不应继续，不再重试）
+	Check(ctx context.Context, video *entity.Video) (vo.StepProgress, error)
+}
+
+// PipelineService 视频处理流水线服务：上传完成或源文件被替换后按定义的依赖依次执行各个步骤，
+// 每个步骤记录状态、执行次数和失败原因，失败后按配置重试，重试用尽后可以只重新执行失败的步骤
+type PipelineService interface {
+	// Start 为视频当前的源文件创建流水线，已有时直接返回；skipped 中的步骤直接跳过，值为原因
+	Start(ctx context.Context, video *entity.Video, definition []vo.PipelineStepDefinition,
+		skipped map[string]string) (*entity.Pipeline, error)
+	// Advance 推进视频最新的流水线：查看执行中步骤的进度，取消依赖已失败的步骤，开始依赖已完成的步骤
+	Advance(ctx context.Context, videoUUID string, runners map[string]PipelineStepRunner, retryDelay time.Duration) error
+	// DueVideos 获取最多limit个需要推进流水线的视频
+	DueVideos(ctx context.Context, limit int) ([]string, error)
+	// Rerun 重新执行已失败的步骤，因它失败而取消的后续步骤一并恢复等待
+	Rerun(ctx context.Context, video *entity.Video, name string) (*entity.Pipeline, error)
+	// Cancel 取消视频未完成的步骤，返回取消的数量
+	Cancel(ctx context.Context, videoUUID, reason string) (int64, error)
+	// Latest 获取视频最新的流水线，不存在时返回nil
+	Latest(ctx context.Context, videoUUID string) (*entity.Pipeline, error)
+}
+
+type pipelineServiceImpl struct {
+	videoRepo    repo.VideoRepository
+	pipelineRepo repo.PipelineRepository
+}
+
+// DefaultPipelineService 获取默认处理流水线服务实例
+func DefaultPipelineService() PipelineService {
+	assert.NotCircular()
+	pipelineServiceOnce.Do(func() {
+		singletonPipelineService = &pipelineServiceImpl{
+			videoRepo:    persistence.NewVideoRepository(),
+			pipelineRepo: persistence.NewPipelineRepository(),
+		}
+	})
+	assert.NotNil(singletonPipelineService)
+	return singletonPipelineService
+}
+
+// Start 事件可能重复到达，同一源文件只创建一条流水线；源文件被替换时取消旧流水线未完成的步骤
+func (s *pipelineServiceImpl) Start(ctx context.Context, video *entity.Video, definition []vo.PipelineStepDefinition,
+	skipped map[string]string) (*entity.Pipeline, error) {
+	latest, err := s.pipelineRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if latest != nil && latest.IsCurrentFor(video) {
+		return latest, nil
+	}
+	if _, err := s.pipelineRepo.CancelActive(ctx, video.UUID(), "source replaced"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	pipeline := entity.DefaultPipeline(video, definition)
+	for name, reason := range skipped {
+		if step := pipeline.Step(name); step != nil {
+			step.Skip(reason)
+		}
+	}
+	if err := s.pipelineRepo.Create(ctx, pipeline); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return pipeline, nil
+}
+
+// Advance 多个实例可能同时推进同一视频，每次保存都以比较并交换进行，发现已被其他实例更新时停止本次推进
+func (s *pipelineServiceImpl) Advance(ctx context.Context, videoUUID string, runners map[string]PipelineStepRunner,
+	retryDelay time.Duration) error {
+	pipeline, err := s.pipelineRepo.FindLatest(ctx, videoUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if pipeline == nil {
+		return nil
+	}
+	video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		_, err := s.Cancel(ctx, videoUUID, "video deleted")
+		return err
+	}
+	if !pipeline.IsCurrentFor(video) {
+		_, err := s.Cancel(ctx, videoUUID, "source replaced")
+		return err
+	}
+
+	for _, step := range pipeline.Steps() {
+		if step.Status() != vo.PipelineStepStatusRunning {
+			continue
+		}
+		if ok, err := s.check(ctx, video, step, runners[step.Name()], retryDelay); err != nil || !ok {
+			return err
+		}
+	}
+	for blocked := pipeline.Blocked(); len(blocked) > 0; blocked = pipeline.Blocked() {
+		for step, dependency := range blocked {
+			fromStatus, fromAttempts := step.Status(), step.Attempts()
+			step.Cancel(fmt.Sprintf("dependency %s %s", dependency, pipeline.Step(dependency).Status().Value()))
+			if ok, err := s.save(ctx, step, fromStatus, fromAttempts); err != nil || !ok {
+				return err
+			}
+		}
+	}
+	for _, step := range pipeline.Ready(time.Now()) {
+		if ok, err := s.dispatch(ctx, video, step, runners[step.Name()], retryDelay); err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// DueVideos 获取需要推进流水线的视频
+func (s *pipelineServiceImpl) DueVideos(ctx context.Context, limit int) ([]string, error) {
+	videoUUIDs, err := s.pipelineRepo.FindDueVideos(ctx, time.Now(), limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return videoUUIDs, nil
+}
+
+// Rerun 只恢复失败的步骤和被它阻塞的步骤，已完成的步骤不再执行；恢复后的步骤执行次数从零开始
+func (s *pipelineServiceImpl) Rerun(ctx context.Context, video *entity.Video, name string) (*entity.Pipeline, error) {
+	pipeline, err := s.pipelineRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if pipeline == nil || !pipeline.IsCurrentFor(video) {
+		return nil, errno.NewSimpleBizError(errno.ErrPipelineNotFound, nil)
+	}
+	step := pipeline.Step(name)
+	if step == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrPipelineStepNotFound, nil, name)
+	}
+	if step.Status() != vo.PipelineStepStatusFailed {
+		return nil, errno.NewSimpleBizError(errno.ErrPipelineStepNotFailed, nil, name)
+	}
+	steps := []*entity.PipelineStep{step}
+	for _, downstream := range pipeline.Downstream(name) {
+		if downstream.Status() == vo.PipelineStepStatusCanceled {
+			steps = append(steps, downstream)
+		}
+	}
+	for _, step := range steps {
+		fromStatus, fromAttempts := step.Status(), step.Attempts()
+		step.Reset()
+		ok, err := s.save(ctx, step, fromStatus, fromAttempts)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errno.NewSimpleBizError(errno.ErrPipelineStepNotFailed, nil, step.Name())
+		}
+	}
+	return pipeline, nil
+}
+
+// Cancel 取消未完成的步骤，已调度的处理由各自的取消流程处理
+func (s *pipelineServiceImpl) Cancel(ctx context.Context, videoUUID, reason string) (int64, error) {
+	canceled, err := s.pipelineRepo.CancelActive(ctx, videoUUID, reason)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return canceled, nil
+}
+
+// Latest 获取最新的流水线
+func (s *pipelineServiceImpl) Latest(ctx context.Context, videoUUID string) (*entity.Pipeline, error) {
+	pipeline, err := s.pipelineRepo.FindLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return pipeline, nil
+}
+
+// check 查看执行中步骤对应的处理，完成、失败或取消时结束本次执行，否则只在进度变化时更新
+func (s *pipelineServiceImpl) check(ctx context.Context, video *entity.Video, step *entity.PipelineStep,
+	runner PipelineStepRunner, retryDelay time.Duration) (bool, error) {
+	fromStatus, fromAttempts := step.Status(), step.Attempts()
+	if runner == nil {
+		step.Fail(fmt.Sprintf("no runner for step %s", step.Name()), retryDelay)
+		return s.save(ctx, step, fromStatus, fromAttempts)
+	}
+	progress, err := runner.Check(ctx, video)
+	if err != nil {
+		return false, err
+	}
+	switch progress.Status() {
+	case vo.PipelineStepStatusSucceeded:
+		step.Succeed()
+	case vo.PipelineStepStatusFailed:
+		step.Fail(progress.ErrorMsg(), retryDelay)
+		logger.Info(fmt.Sprintf("pipeline step %s of video %s failed (attempt %d/%d): %s",
+			step.Name(), video.UUID(), step.Attempts(), step.MaxAttempts(), progress.ErrorMsg()))
+	case vo.PipelineStepStatusCanceled:
+		step.Cancel(progress.ErrorMsg())
+		logger.Info(fmt.Sprintf("pipeline step %s of video %s canceled: %s", step.Name(), video.UUID(), progress.ErrorMsg()))
+	default:
+		if progress.Progress() == step.Progress() {
+			return true, nil
+		}
+		step.SetProgress(progress.Progress())
+	}
+	return s.save(ctx, step, fromStatus, fromAttempts)
+}
+
+// dispatch 先以比较并交换把步骤标记为执行中再调度处理，保证同一次执行只调度一次
+func (s *pipelineServiceImpl) dispatch(ctx context.Context, video *entity.Video, step *entity.PipelineStep,
+	runner PipelineStepRunner, retryDelay time.Duration) (bool, error) {
+	fromStatus, fromAttempts := step.Status(), step.Attempts()
+	step.Dispatch()
+	if ok, err := s.save(ctx, step, fromStatus, fromAttempts); err != nil || !ok {
+		return ok, err
+	}
+	var startErr error
+	if runner == nil {
+		startErr = fmt.Errorf("no runner for step %s", step.Name())
+	} else {
+		startErr = runner.Start(ctx, video)
+	}
+	if startErr == nil {
+		return true, nil
+	}
+	logger.Error(fmt.Sprintf("start pipeline step %s of video %s failed: %v", step.Name(), video.UUID(), startErr))
+	fromStatus, fromAttempts = step.Status(), step.Attempts()
+	step.Fail(startErr.Error(), retryDelay)
+	return s.save(ctx, step, fromStatus, fromAttempts)
+}
+
+// save 以比较并交换保存步骤，已被其他实例更新时返回false
+func (s *pipelineServiceImpl) save(ctx context.Context, step *entity.PipelineStep, fromStatus vo.PipelineStepStatus,
+	fromAttempts int) (bool, error) {
+	ok, err := s.pipelineRepo.Update(ctx, step, fromStatus.Value(), fromAttempts)
+	if err != nil {
+		return false, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		logger.Info(fmt.Sprintf("pipeline step %s of video %s was updated concurrently", step.Name(), step.VideoUuid()))
+	}
+	return ok, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+var (
+	reconcileServiceOnce      sync.Once
+	singletonReconcileService ReconcileService
+)
+
+// ReconcileService 对象存储与数据库对账服务
+type ReconcileService interface {
+	// Reconcile 对比存储桶 videos/ 下的对象和视频记录的存储路径：
+	// 删除超过宽限期的孤儿对象，将源文件缺失的视频标记为失败。dryRun为true时只生成报告
+	Reconcile(ctx context.Context, dryRun bool, gracePeriod time.Duration) (*entity.ReconcileReport, error)
+}
+
+type reconcileServiceImpl struct {
+	minioService gateway.MinioService
+	videoRepo    repo.VideoRepository
+	versionRepo  repo.SourceVersionRepository
+	videoService VideoService
+	// running 同一进程内同时只允许一次对账
+	running sync.Mutex
+}
+
+// DefaultReconcileService 获取默认对账服务实例
+func DefaultReconcileService() ReconcileService {
+	assert.NotCircular()
+	reconcileServiceOnce.Do(func() {
+		singletonReconcileService = &reconcileServiceImpl{
+			minioService: minio.DefaultMinioService(),
+			videoRepo:    persistence.NewVideoRepository(),
+			versionRepo:  persistence.NewSourceVersionRepository(),
+			videoService: DefaultVideoService(),
+		}
+	})
+	assert.NotNil(singletonReconcileService)
+	return singletonReconcileService
+}
+
+// sourceRequiredStatuses 这些状态的视频必须有源文件，上传中的视频超过宽限期仍没有源文件视为上传中断
+var sourceRequiredStatuses = []vo.VideoStatus{
+	vo.VideoStatusUploading,
+	vo.VideoStatusUploaded,
+	vo.VideoStatusProcessing,
+	vo.VideoStatusReady,
+}
+
+// Reconcile 执行一次对账
+func (s *reconcileServiceImpl) Reconcile(ctx context.Context, dryRun bool, gracePeriod time.Duration) (*entity.ReconcileReport, error) {
+	if !s.running.TryLock() {
+		return nil, errno.NewSimpleBizError(errno.ErrReconcileRunning, nil)
+	}
+	defer s.running.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-gracePeriod)
+	report := entity.NewReconcileReport(dryRun, gracePeriod, now)
+
+	// 先列对象再查记录：列举期间新上传并入库的对象不会被误判为孤儿
+	objects, err := s.minioService.ListObjects(ctx, vo.VideoObjectPrefix)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	videos, err := s.videoRepo.FindAllStorageRefs(ctx)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	// 被替换但仍在保留期内的源文件版本也视为被引用
+	retainedPaths, err := s.versionRepo.FindRetainedStoragePaths(ctx)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	report.SetScanned(len(objects), len(videos))
+
+	referenced := make(map[string]struct{}, len(videos))
+	assetPrefixes := make(map[string]struct{}, len(videos))
+	for _, video := range videos {
+		if video.StoragePath() != "" {
+			referenced[video.StoragePath()] = struct{}{}
+		}
+		assetPrefixes[video.AssetPrefix()] = struct{}{}
+	}
+	for _, path := range retainedPaths {
+		referenced[path] = struct{}{}
+	}
+
+	existing := make(map[string]struct{}, len(objects))
+	for _, object := range objects {
+		existing[object.Key()] = struct{}{}
+		if _, ok := referenced[object.Key()]; ok {
+			continue
+		}
+		// 字幕等衍生资源存放在视频资源目录下，视频存在即视为被引用；
+		// source/ 下的源文件版本只有仍被版本记录保留时才被引用
+		if prefix := assetPrefixOf(object.Key()); !strings.HasPrefix(object.Key(), prefix+vo.SourceVersionDir) {
+			if _, ok := assetPrefixes[prefix]; ok {
+				continue
+			}
+		}
+		s.handleOrphan(ctx, report, object, cutoff)
+	}
+
+	for _, video := range videos {
+		if video.StoragePath() == "" || !requiresSource(video.Status()) {
+			continue
+		}
+		if _, ok := existing[video.StoragePath()]; ok {
+			continue
+		}
+		s.handleMissing(ctx, report, video, cutoff)
+	}
+
+	report.Finish(time.Now())
+	logger.Info(fmt.Sprintf("reconcile finished dry_run: %v, objects: %d, videos: %d, orphans: %d, missing: %d",
+		dryRun, report.ScannedObjects(), report.ScannedVideos(), len(report.Orphans()), len(report.Missing())))
+	return report, nil
+}
+
+func (s *reconcileServiceImpl) handleOrphan(ctx context.Context, report *entity.ReconcileReport, object vo.StoredObject, cutoff time.Time) {
+	switch {
+	case object.LastModified().After(cutoff):
+		report.AddOrphan(object, vo.ReconcileActionGracePeriod, "")
+	case report.DryRun():
+		report.AddOrphan(object, vo.ReconcileActionReported, "")
+	default:
+		if err := s.minioService.DeleteVideo(ctx, object.Key()); err != nil {
+			logger.Error(fmt.Sprintf("reconcile delete orphan %s failed: %v", object.Key(), err))
+			report.AddOrphan(object, vo.ReconcileActionError, err.Error())
+			return
+		}
+		report.AddOrphan(object, vo.ReconcileActionDeleted, "")
+	}
+}
+
+func (s *reconcileServiceImpl) handleMissing(ctx context.Context, report *entity.ReconcileReport, video *entity.Video, cutoff time.Time) {
+	switch {
+	case video.CreatedAt() == nil || video.CreatedAt().After(cutoff):
+		report.AddMissing(video, vo.ReconcileActionGracePeriod, "")
+	case report.DryRun():
+		report.AddMissing(video, vo.ReconcileActionReported, "")
+	default:
+		if _, err := s.videoService.Transition(ctx, video.UUID(), vo.VideoStatusFailed, "source object missing in storage"); err != nil {
+			logger.Error(fmt.Sprintf("reconcile mark video %s failed: %v", video.UUID(), err))
+			report.AddMissing(video, vo.ReconcileActionError, err.Error())
+			return
+		}
+		report.AddMissing(video, vo.ReconcileActionMarkedFailed, "")
+	}
+}
+
+func requiresSource(status vo.VideoStatus) bool {
+	for _, required := range sourceRequiredStatuses {
+		if status == required {
+			return true
+		}
+	}
+	return false
+}
+
+// assetPrefixOf 取对象名的前三级目录 videos/{user}/{video}/
+func assetPrefixOf(key string) string {
+	parts := strings.SplitN(key, "/", 4)
+	if len(parts) < 4 {
+		return ""
+	}
+	return strings.Join(parts[:3], "/") + "/"
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/ddd/video/infrastructure/scanner"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+var (
+	scanServiceOnce      sync.Once
+	singletonScanService ScanService
+)
+
+// ScanService 源文件恶意软件扫描服务：上传的文件在转码发布之前先扫描，
+// 发现恶意软件时封禁视频并把源文件移到隔离前缀下，扫描结论和特征名称保存在扫描记录中
+type ScanService interface {
+	// Schedule 为视频当前的源文件创建等待扫描的记录，已有未失败的记录时直接返回
+	Schedule(ctx context.Context, video *entity.Video) (*entity.ScanJob, error)
+	// ClaimDue 领取最多limit个到期的记录，租约为lease
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.ScanJob, error)
+	// Process 执行已领取的记录：读取源文件明文交给扫描实现，发现恶意软件时封禁视频并隔离源文件
+	Process(ctx context.Context, job *entity.ScanJob, opts vo.ScanOptions) error
+	// Cancel 取消视频未完成的记录，返回取消的数量
+	Cancel(ctx context.Context, videoUUID, reason string) (int64, error)
+	// Latest 获取视频最新的记录，不存在时返回nil
+	Latest(ctx context.Context, videoUUID string) (*entity.ScanJob, error)
+}
+
+type scanServiceImpl struct {
+	minioService gateway.MinioService
+	scanner      gateway.Scanner
+	videoRepo    repo.VideoRepository
+	scanRepo     repo.ScanRepository
+}
+
+// DefaultScanService 获取默认恶意软件扫描服务实例
+func DefaultScanService() ScanService {
+	assert.NotCircular()
+	scanServiceOnce.Do(func() {
+		singletonScanService = &scanServiceImpl{
+			minioService: minio.DefaultMinioService(),
+			scanner:      scanner.DefaultScanner(),
+			videoRepo:    persistence.NewVideoRepository(),
+			scanRepo:     persistence.NewScanRepository(),
+		}
+	})
+	assert.NotNil(singletonScanService)
+	return singletonScanService
+}
+
+// Schedule 事件可能重复到达，同一源文件等待中、处理中或已完成的记录不再重复创建
+func (s *scanServiceImpl) Schedule(ctx context.Context, video *entity.Video) (*entity.ScanJob, error) {
+	latest, err := s.scanRepo.FindLatest(ctx, video.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if latest != nil && latest.IsCurrentFor(video) {
+		switch latest.Status() {
+		case vo.RenditionStatusPending, vo.RenditionStatusRunning, vo.RenditionStatusSucceeded:
+			return latest, nil
+		}
+	}
+	if _, err := s.scanRepo.CancelActive(ctx, video.UUID(), "rescheduled"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	job := entity.DefaultScanJob(video)
+	if err := s.scanRepo.Create(ctx, job); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+// ClaimDue 多实例同时领取时以比较并交换保证每条记录只有一个执行者
+func (s *scanServiceImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.ScanJob, error) {
+	now := time.Now()
+	candidates, err := s.scanRepo.FindClaimable(ctx, now, limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	claimed := make([]*entity.ScanJob, 0, len(candidates))
+	for _, job := range candidates {
+		ok, err := s.scanRepo.Claim(ctx, job, now.Add(lease))
+		if err != nil {
+			return claimed, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+// Process 不续租，租约应长于超时时间；ctx 被取消（服务停止）时保持处理中状态，租约到期后由其他实例重新执行。
+// 发现恶意软件时先封禁再隔离，隔离失败时按失败重试，重新扫描后再次隔离
+func (s *scanServiceImpl) Process(ctx context.Context, job *entity.ScanJob, opts vo.ScanOptions) error {
+	video, err := s.videoRepo.FindByUUID(ctx, job.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return s.finishCanceled(ctx, job, "video deleted")
+	}
+	if !job.IsCurrentFor(video) {
+		return s.finishCanceled(ctx, job, "source replaced")
+	}
+
+	workCtx := ctx
+	if opts.Timeout() > 0 {
+		var cancel context.CancelFunc
+		workCtx, cancel = context.WithTimeout(ctx, opts.Timeout())
+		defer cancel()
+	}
+	result, quarantinePath, runErr := s.run(workCtx, video)
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		reason := runErr.Error()
+		if errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+			reason = fmt.Sprintf("scan timed out after %s", opts.Timeout())
+		}
+		logger.Error(fmt.Sprintf("scan %s of video %s attempt %d failed: %s", job.UUID(), job.VideoUuid(), job.Attempts(), reason))
+		maxAttempts := opts.MaxAttempts()
+		if errors.Is(runErr, gateway.ErrScanTooLarge) {
+			// 超过扫描上限，重试也会失败
+			maxAttempts = 0
+		}
+		job.Fail(reason, maxAttempts, opts.RetryDelay())
+		if _, err := s.scanRepo.Finish(ctx, job); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		return nil
+	}
+
+	job.Succeed(result, quarantinePath)
+	if _, err := s.scanRepo.Finish(ctx, job); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if result.Infected() {
+		logger.Error(fmt.Sprintf("scan %s of video %s: malware %s detected, source quarantined to %s", job.UUID(),
+			job.VideoUuid(), result.Signature(), quarantinePath))
+	} else {
+		logger.Info(fmt.Sprintf("scan %s of video %s: clean", job.UUID(), job.VideoUuid()))
+	}
+	return nil
+}
+
+// Cancel 取消扫描
+func (s *scanServiceImpl) Cancel(ctx context.Context, videoUUID, reason string) (int64, error) {
+	canceled, err := s.scanRepo.CancelActive(ctx, videoUUID, reason)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return canceled, nil
+}
+
+// Latest 获取最新的记录
+func (s *scanServiceImpl) Latest(ctx context.Context, videoUUID string) (*entity.ScanJob, error) {
+	job, err := s.scanRepo.FindLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+// run 以流的方式读取源文件明文交给扫描实现，加密存储的视频透明解密；
+// 发现恶意软件时返回隔离后的路径，视频的存储路径保持不变，误报时可以从隔离路径恢复。
+// 上次执行已隔离但没来得及保存结果时源文件已不存在，扫描隔离后的对象
+func (s *scanServiceImpl) run(ctx context.Context, video *entity.Video) (vo.ScanResult, string, error) {
+	objectName := video.StoragePath()
+	quarantinePath := vo.QuarantineObjectName(objectName)
+	stat, err := s.minioService.StatObject(ctx, objectName)
+	if err != nil {
+		quarantined, qerr := s.minioService.StatObject(ctx, quarantinePath)
+		if qerr != nil {
+			return vo.ScanResult{}, "", fmt.Errorf("stat source: %w", err)
+		}
+		objectName, stat = quarantinePath, quarantined
+	}
+	var reader io.Reader = strings.NewReader("")
+	if stat.Size() > 0 {
+		object, err := s.minioService.OpenObject(ctx, objectName, 0, stat.Size())
+		if err != nil {
+			return vo.ScanResult{}, "", fmt.Errorf("open source: %w", err)
+		}
+		defer object.Close()
+		reader = object
+	}
+	result, err := s.scanner.Scan(ctx, reader)
+	if err != nil {
+		return vo.ScanResult{}, "", fmt.Errorf("scan source: %w", err)
+	}
+	if !result.Infected() {
+		return result, "", nil
+	}
+
+	if err := s.block(ctx, video, fmt.Sprintf("malware detected: %s", result.Signature())); err != nil {
+		return vo.ScanResult{}, "", err
+	}
+	if objectName != quarantinePath {
+		if err := s.minioService.MoveObject(ctx, objectName, quarantinePath); err != nil {
+			return vo.ScanResult{}, "", fmt.Errorf("quarantine source: %w", err)
+		}
+	}
+	return result, quarantinePath, nil
+}
+
+// block 封禁视频，已封禁（重新执行时）或状态不允许封禁时只记录日志
+func (s *scanServiceImpl) block(ctx context.Context, video *entity.Video, cause string) error {
+	transition, err := video.TransitionTo(vo.VideoStatusBlocked, cause)
+	if err == nil {
+		err = s.videoRepo.UpdateVideoStatus(ctx, transition, nil)
+	}
+	var transitionErr *vo.TransitionError
+	if errors.As(err, &transitionErr) {
+		logger.Info(fmt.Sprintf("video %s transition skipped: %v", video.UUID(), err))
+		return nil
+	}
+	return err
+}
+
+// finishCanceled 结束一次被取消的扫描
+func (s *scanServiceImpl) finishCanceled(ctx context.Context, job *entity.ScanJob, reason string) error {
+	job.Cancel(reason)
+	if _, err := s.scanRepo.Finish(ctx, job); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+)
+
+// purgeBatchSize 每批清理的过期版本数
+const purgeBatchSize = 100
+
+var (
+	sourceVersionServiceOnce      sync.Once
+	singletonSourceVersionService SourceVersionService
+)
+
+// SourceVersionService 视频源文件版本服务：替换源文件时视频UUID、评论、分享链接等保持不变，
+// 旧版本在保留期内可以回滚
+type SourceVersionService interface {
+	// BeginReplace 为视频创建上传中的新版本，视频仍使用当前源文件直到新版本上传完成
+	BeginReplace(ctx context.Context, video *entity.Video, uploaderUUID, filename string, fileSize int64, format string,
+		keepMetadata bool) (*entity.SourceVersion, error)
+	// CompleteReplace 根据上传结果生效或标记失败新版本，被替换的版本保留retention
+	CompleteReplace(ctx context.Context, versionUUID string, receipt vo.UploadReceipt, uploadErr error, retention time.Duration) error
+	// Rollback 回滚到保留期内的历史版本，当前版本保留retention
+	Rollback(ctx context.Context, video *entity.Video, version int, retention time.Duration) (*entity.SourceVersion, error)
+	// Versions 获取视频的全部版本，新版本在前
+	Versions(ctx context.Context, videoUUID string) ([]*entity.SourceVersion, error)
+	// PurgeExpired 删除超过保留期的版本对象，返回清理的版本数
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type sourceVersionServiceImpl struct {
+	minioService gateway.MinioService
+	videoRepo    repo.VideoRepository
+	versionRepo  repo.SourceVersionRepository
+}
+
+// DefaultSourceVersionService 获取默认源文件版本服务实例
+func DefaultSourceVersionService() SourceVersionService {
+	assert.NotCircular()
+	sourceVersionServiceOnce.Do(func() {
+		singletonSourceVersionService = &sourceVersionServiceImpl{
+			minioService: minio.DefaultMinioService(),
+			videoRepo:    persistence.NewVideoRepository(),
+			versionRepo:  persistence.NewSourceVersionRepository(),
+		}
+	})
+	assert.NotNil(singletonSourceVersionService)
+	return singletonSourceVersionService
+}
+
+// BeginReplace 只有可播放的视频可以替换源文件；首次替换时把当前源文件补录为第1个版本
+func (s *sourceVersionServiceImpl) BeginReplace(ctx context.Context, video *entity.Video, uploaderUUID, filename string,
+	fileSize int64, format string, keepMetadata bool) (*entity.SourceVersion, error) {
+	if !video.Status().IsPlayable() {
+		return nil, errno.NewSimpleBizError(errno.ErrSourceNotReplaceable, nil)
+	}
+	var initial *entity.SourceVersion
+	if video.StoragePath() != "" {
+		initial = entity.InitialSourceVersion(video)
+	}
+	version := entity.DefaultSourceVersion(video, uploaderUUID, filename, fileSize, format, keepMetadata)
+	if err := s.versionRepo.CreateNext(ctx, initial, version); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return version, nil
+}
+
+// CompleteReplace 上传成功时在一个事务中切换视频的存储路径和版本状态；
+// 切换前视频的源文件已被其他替换或回滚修改时，新版本标记为失败
+func (s *sourceVersionServiceImpl) CompleteReplace(ctx context.Context, versionUUID string, receipt vo.UploadReceipt,
+	uploadErr error, retention time.Duration) error {
+	version, err := s.findVersion(ctx, versionUUID)
+	if err != nil {
+		return err
+	}
+	if uploadErr != nil {
+		return s.discard(ctx, version, uploadErr.Error())
+	}
+
+	video, err := s.videoRepo.FindByUUID(ctx, version.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return s.discard(ctx, version, "video not found")
+	}
+	version.SetUploadedChecksum(receipt.Checksum(), receipt.ETag())
+	previous := video.UseSource(version)
+	if err := s.versionRepo.Activate(ctx, version, previous, time.Now().Add(retention)); err != nil {
+		if discardErr := s.discard(ctx, version, err.Error()); discardErr != nil {
+			logger.Error(fmt.Sprintf("CompleteReplace discard version %s failed: %v", version.UUID(), discardErr))
+		}
+		return transitionBizError(err)
+	}
+	logger.Info(fmt.Sprintf("video %s source replaced with version %d", video.UUID(), version.Version()))
+	return nil
+}
+
+// Rollback 回滚到历史版本
+func (s *sourceVersionServiceImpl) Rollback(ctx context.Context, video *entity.Video, versionNumber int,
+	retention time.Duration) (*entity.SourceVersion, error) {
+	if !video.Status().IsPlayable() {
+		return nil, errno.NewSimpleBizError(errno.ErrSourceNotReplaceable, nil)
+	}
+	version, err := s.versionRepo.FindByVersion(ctx, video.UUID(), versionNumber)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if version == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrSourceVersionNotFound, nil)
+	}
+	if !version.CanRollback(time.Now()) {
+		return nil, errno.NewSimpleBizError(errno.ErrSourceVersionExpired, nil, versionNumber)
+	}
+	previous := video.UseSource(version)
+	if err := s.versionRepo.Activate(ctx, version, previous, time.Now().Add(retention)); err != nil {
+		return nil, transitionBizError(err)
+	}
+	logger.Info(fmt.Sprintf("video %s source rolled back to version %d", video.UUID(), version.Version()))
+	version, err = s.versionRepo.FindByUUID(ctx, version.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return version, nil
+}
+
+// Versions 获取视频的全部版本
+func (s *sourceVersionServiceImpl) Versions(ctx context.Context, videoUUID string) ([]*entity.SourceVersion, error) {
+	versions, err := s.versionRepo.FindByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return versions, nil
+}
+
+// PurgeExpired 先把版本标记为已清理再删除对象，避免删除清理期间被回滚的版本；
+// 对象删除失败只记录日志，不再被引用的对象后续由对账清理
+func (s *sourceVersionServiceImpl) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	purged := 0
+	for {
+		versions, err := s.versionRepo.FindExpired(ctx, now, purgeBatchSize)
+		if err != nil {
+			return purged, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if len(versions) == 0 {
+			return purged, nil
+		}
+		for _, version := range versions {
+			if ctx.Err() != nil {
+				return purged, errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+			}
+			ok, err := s.versionRepo.MarkPurged(ctx, version.UUID(), now)
+			if err != nil {
+				return purged, errno.NewSimpleBizError(errno.ErrDatabase, err)
+			}
+			if !ok {
+				continue
+			}
+			purged++
+			if err := s.minioService.DeleteVideo(ctx, version.StoragePath()); err != nil {
+				logger.Error(fmt.Sprintf("purge source version %s object %s failed: %v", version.UUID(), version.StoragePath(), err))
+			}
+		}
+	}
+}
+
+func (s *sourceVersionServiceImpl) findVersion(ctx context.Context, versionUUID string) (*entity.SourceVersion, error) {
+	version, err := s.versionRepo.FindByUUID(ctx, versionUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if version == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrSourceVersionNotFound, nil)
+	}
+	return version, nil
+}
+
+// discard 新版本标记为失败并删除可能已上传的对象，失败版本的对象不会被任何记录引用
+func (s *sourceVersionServiceImpl) discard(ctx context.Context, version *entity.SourceVersion, errorMsg string) error {
+	if err := s.versionRepo.MarkFailed(ctx, version.UUID(), errorMsg); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if err := s.minioService.DeleteVideo(ctx, version.StoragePath()); err != nil {
+		logger.Error(fmt.Sprintf("discard source version %s object %s failed: %v", version.UUID(), version.StoragePath(), err))
+	}
+	return nil
+}
@@ -0,0 +1,642 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
+	"go-video/ddd/video/infrastructure/transcoder"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/hls"
+	"go-video/pkg/logger"
+)
+
+const (
+	// renditionLease 领取转码后的租约时长，执行期间定期续租，执行者退出后租约到期由其他实例接手
+	renditionLease = 2 * time.Minute
+	// heartbeatInterval 记录转码进度并续租的间隔
+	heartbeatInterval = 10 * time.Second
+	// masterPublishRounds 发布主播放列表时最多重写的次数
+	masterPublishRounds = 5
+	// segmentContentType MPEG-TS 分片的内容类型
+	segmentContentType = "video/mp2t"
+)
+
+// transcodeOutput 一次转码的输出
+type transcodeOutput struct {
+	info     vo.MediaInfo
+	fileSize int64
+	hls      vo.HLSPlaylist
+	dash     vo.DASHPackage
+}
+
+var (
+	transcodeServiceOnce      sync.Once
+	singletonTranscodeService TranscodeService
+)
+
+// TranscodeService 视频转码服务：上传完成的视频按码率阶梯转码，必需的档位全部完成后视频才可播放
+type TranscodeService interface {
+	// StartProcessing 为刚上传完成的视频创建转码输出并进入处理中；阶梯为空时直接进入可播放状态
+	StartProcessing(ctx context.Context, videoUUID string, ladder []vo.RenditionProfile) error
+	// Schedule 取消视频未完成的转码，为当前源文件重新创建整个阶梯的转码输出，不改变视频状态
+	Schedule(ctx context.Context, video *entity.Video, ladder []vo.RenditionProfile) ([]*entity.Rendition, error)
+	// ClaimDue 领取最多limit个到期的转码输出
+	ClaimDue(ctx context.Context, limit int) ([]*entity.Rendition, error)
+	// Transcode 执行已领取的一档转码，打包HLS和DASH并更新HLS主播放列表，结束后结算视频状态
+	Transcode(ctx context.Context, rendition *entity.Rendition, opts vo.TranscodeOptions) error
+	// Cancel 取消视频未完成的转码，处理中的视频标记为失败，返回取消的数量
+	Cancel(ctx context.Context, videoUUID, reason string) (int64, error)
+	// Renditions 获取视频的全部转码输出，新建的在前
+	Renditions(ctx context.Context, videoUUID string) ([]*entity.Rendition, error)
+	// CurrentRenditions 获取当前源文件每个档位最新的转码输出，不含已取消和被替换的
+	CurrentRenditions(ctx context.Context, video *entity.Video) ([]*entity.Rendition, error)
+}
+
+type transcodeServiceImpl struct {
+	minioService      gateway.MinioService
+	transcoder        gateway.Transcoder
+	videoRepo         repo.VideoRepository
+	renditionRepo     repo.RenditionRepository
+	contentKeyService ContentKeyService
+}
+
+// DefaultTranscodeService 获取默认转码服务实例
+func DefaultTranscodeService() TranscodeService {
+	assert.NotCircular()
+	transcodeServiceOnce.Do(func() {
+		singletonTranscodeService = &transcodeServiceImpl{
+			minioService:      minio.DefaultMinioService(),
+			transcoder:        transcoder.DefaultTranscoder(),
+			videoRepo:         persistence.NewVideoRepository(),
+			renditionRepo:     persistence.NewRenditionRepository(),
+			contentKeyService: DefaultContentKeyService(),
+		}
+	})
+	assert.NotNil(singletonTranscodeService)
+	return singletonTranscodeService
+}
+
+// StartProcessing 事件可能重复到达，视频已离开上传完成状态时忽略；
+// 已为当前源文件创建过转码输出时（上次处理在迁移状态前中断）不再重复创建
+func (s *transcodeServiceImpl) StartProcessing(ctx context.Context, videoUUID string, ladder []vo.RenditionProfile) error {
+	video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || video.Status() != vo.VideoStatusUploaded {
+		return nil
+	}
+	if len(ladder) == 0 {
+		return s.transition(ctx, video, vo.VideoStatusReady, "no processing pipeline")
+	}
+
+	renditions, err := s.currentRenditions(ctx, video)
+	if err != nil {
+		return err
+	}
+	if len(renditions) == 0 {
+		if renditions, err = s.Schedule(ctx, video, ladder); err != nil {
+			return err
+		}
+	}
+	required := 0
+	for _, rendition := range renditions {
+		if rendition.Profile().Required() {
+			required++
+		}
+	}
+	if required == 0 {
+		return s.transition(ctx, video, vo.VideoStatusReady, "no required renditions")
+	}
+	return s.transition(ctx, video, vo.VideoStatusProcessing, fmt.Sprintf("transcoding %d renditions", len(renditions)))
+}
+
+// Schedule 重新创建整个阶梯
+func (s *transcodeServiceImpl) Schedule(ctx context.Context, video *entity.Video, ladder []vo.RenditionProfile) ([]*entity.Rendition, error) {
+	if _, err := s.renditionRepo.CancelActive(ctx, video.UUID(), "rescheduled"); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	renditions := make([]*entity.Rendition, 0, len(ladder))
+	for _, profile := range ladder {
+		renditions = append(renditions, entity.DefaultRendition(video, profile))
+	}
+	if err := s.renditionRepo.SaveAll(ctx, renditions); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return renditions, nil
+}
+
+// ClaimDue 多实例同时领取时以比较并交换保证每档只有一个执行者
+func (s *transcodeServiceImpl) ClaimDue(ctx context.Context, limit int) ([]*entity.Rendition, error) {
+	now := time.Now()
+	candidates, err := s.renditionRepo.FindClaimable(ctx, now, limit)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	claimed := make([]*entity.Rendition, 0, len(candidates))
+	for _, rendition := range candidates {
+		ok, err := s.renditionRepo.Claim(ctx, rendition, now.Add(renditionLease))
+		if err != nil {
+			return claimed, errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok {
+			claimed = append(claimed, rendition)
+		}
+	}
+	return claimed, nil
+}
+
+// Transcode 执行期间定期记录进度并续租，续租失败说明转码已被取消或被其他实例接手，此时中止转码；
+// ctx 被取消（服务停止）时保持转码中状态，租约到期后由其他实例重新执行
+func (s *transcodeServiceImpl) Transcode(ctx context.Context, rendition *entity.Rendition, opts vo.TranscodeOptions) error {
+	video, err := s.videoRepo.FindByUUID(ctx, rendition.VideoUuid())
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil {
+		return s.finishCanceled(ctx, rendition, "video deleted")
+	}
+	if !rendition.IsCurrentFor(video) {
+		return s.finishCanceled(ctx, rendition, "source replaced")
+	}
+
+	workCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if opts.Timeout() > 0 {
+		var cancelTimeout context.CancelFunc
+		workCtx, cancelTimeout = context.WithTimeout(workCtx, opts.Timeout())
+		defer cancelTimeout()
+	}
+	var percent atomic.Int64
+	stopHeartbeat := s.heartbeat(workCtx, rendition, &percent, cancel)
+	output, runErr := s.run(workCtx, rendition, opts, &percent)
+	stopHeartbeat()
+
+	if runErr != nil {
+		if errors.Is(context.Cause(workCtx), vo.ErrTranscodeCanceled) {
+			logger.Info(fmt.Sprintf("rendition %s of video %s aborted: canceled or taken over", rendition.UUID(), rendition.VideoUuid()))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, ctx.Err())
+		}
+		reason := runErr.Error()
+		if errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+			reason = fmt.Sprintf("transcode timed out after %s", opts.Timeout())
+		}
+		logger.Error(fmt.Sprintf("rendition %s of video %s attempt %d failed: %s", rendition.UUID(), rendition.VideoUuid(), rendition.Attempts(), reason))
+		rendition.Fail(reason, opts.MaxAttempts(), opts.RetryDelay())
+		ok, err := s.renditionRepo.Finish(ctx, rendition)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if ok && rendition.Status() == vo.RenditionStatusFailed {
+			// 不再重试，删除已上传的部分输出
+			s.deleteOutputs(ctx, rendition)
+		}
+		return s.settle(ctx, rendition.VideoUuid())
+	}
+
+	rendition.SetHLS(output.hls)
+	rendition.SetDASH(output.dash)
+	rendition.Succeed(output.info, output.fileSize)
+	ok, err := s.renditionRepo.Finish(ctx, rendition)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		// 上传完成时转码已被取消，输出不会被引用
+		s.deleteOutputs(ctx, rendition)
+		return nil
+	}
+	logger.Info(fmt.Sprintf("rendition %s (%s) of video %s succeeded: %dx%d, %d bytes", rendition.UUID(),
+		rendition.Profile().Name(), rendition.VideoUuid(), rendition.Width(), rendition.Height(), rendition.FileSize()))
+	if err := s.supersede(ctx, rendition); err != nil {
+		return err
+	}
+	if err := s.publishMaster(ctx, rendition.VideoUuid()); err != nil {
+		return err
+	}
+	return s.settle(ctx, rendition.VideoUuid())
+}
+
+// Cancel 取消转码
+func (s *transcodeServiceImpl) Cancel(ctx context.Context, videoUUID, reason string) (int64, error) {
+	canceled, err := s.renditionRepo.CancelActive(ctx, videoUUID, reason)
+	if err != nil {
+		return 0, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return canceled, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video != nil && video.Status() == vo.VideoStatusProcessing {
+		if err := s.transition(ctx, video, vo.VideoStatusFailed, reason); err != nil {
+			return canceled, err
+		}
+	}
+	return canceled, nil
+}
+
+// Renditions 获取视频的全部转码输出
+func (s *transcodeServiceImpl) Renditions(ctx context.Context, videoUUID string) ([]*entity.Rendition, error) {
+	renditions, err := s.renditionRepo.FindByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return renditions, nil
+}
+
+// CurrentRenditions 获取当前源文件的转码输出
+func (s *transcodeServiceImpl) CurrentRenditions(ctx context.Context, video *entity.Video) ([]*entity.Rendition, error) {
+	return s.currentRenditions(ctx, video)
+}
+
+// run 下载源文件到临时目录、转码、切分HLS和DASH分片并上传全部输出
+func (s *transcodeServiceImpl) run(ctx context.Context, rendition *entity.Rendition, opts vo.TranscodeOptions,
+	percent *atomic.Int64) (*transcodeOutput, error) {
+	if opts.WorkDir() != "" {
+		if err := os.MkdirAll(opts.WorkDir(), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := os.MkdirTemp(opts.WorkDir(), "transcode-"+rendition.UUID()+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "source")
+	if err := downloadObject(ctx, s.minioService, rendition.SourcePath(), input); err != nil {
+		return nil, fmt.Errorf("download source: %w", err)
+	}
+	source, err := s.transcoder.Probe(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("probe source: %w", err)
+	}
+
+	output := filepath.Join(dir, rendition.Profile().Name()+".mp4")
+	info, err := s.transcoder.Transcode(ctx, input, output, rendition.Profile(), func(processed time.Duration) {
+		if source.Duration() > 0 {
+			// 上传完成前最多报告99%
+			percent.Store(min(int64(processed*100/source.Duration()), 99))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	playlist, err := s.packageHLS(ctx, rendition, output, filepath.Join(dir, "hls"), opts, info.Codecs())
+	if err != nil {
+		return nil, fmt.Errorf("package hls: %w", err)
+	}
+	// DASH分片没有加密，HLS加密时不打包，避免绕过密钥接口拿到明文
+	var dash vo.DASHPackage
+	if opts.KeyRotation() == 0 {
+		if dash, err = s.packageDASH(ctx, rendition, output, filepath.Join(dir, "dash"), opts.SegmentDuration(), info); err != nil {
+			return nil, fmt.Errorf("package dash: %w", err)
+		}
+	}
+
+	fileSize, err := s.uploadFile(ctx, output, rendition.StoragePath(), "")
+	if err != nil {
+		return nil, fmt.Errorf("upload rendition: %w", err)
+	}
+	return &transcodeOutput{info: info, fileSize: fileSize, hls: playlist, dash: dash}, nil
+}
+
+// packageHLS 切分分片并生成媒体播放列表，分片播放时经由预签名地址直接从存储读取；
+// 配置了密钥轮换时分片使用AES-128加密，每 KeyRotation 个分片换一个内容密钥，播放器从密钥接口获取密钥，否则分片为明文
+func (s *transcodeServiceImpl) packageHLS(ctx context.Context, rendition *entity.Rendition, input, dir string,
+	opts vo.TranscodeOptions, codecs string) (vo.HLSPlaylist, error) {
+	segments, err := s.transcoder.Segment(ctx, input, dir, opts.SegmentDuration())
+	if err != nil {
+		return vo.HLSPlaylist{}, err
+	}
+	playlist := &hls.MediaPlaylist{Segments: make([]hls.Segment, 0, len(segments))}
+	for i, segment := range segments {
+		localPath := filepath.Join(dir, segment.Filename())
+		objectName := rendition.HLSDir() + segment.Filename()
+		var size int64
+		var keyURI string
+		if opts.KeyRotation() > 0 {
+			keyURI, size, err = s.uploadEncryptedSegment(ctx, rendition, localPath, objectName, i, opts.KeyRotation())
+		} else {
+			size, err = s.uploadFile(ctx, localPath, objectName, segmentContentType)
+		}
+		if err != nil {
+			return vo.HLSPlaylist{}, err
+		}
+		playlist.Segments = append(playlist.Segments, hls.Segment{URI: segment.Filename(), Duration: segment.Duration(), Size: size, KeyURI: keyURI})
+	}
+	playlistPath := rendition.HLSDir() + "index.m3u8"
+	content := playlist.Encode()
+	if err := s.minioService.PutObject(ctx, playlistPath, bytes.NewReader(content), int64(len(content)), hls.ContentType); err != nil {
+		return vo.HLSPlaylist{}, err
+	}
+	return vo.NewHLSPlaylist(playlistPath, playlist.PeakBandwidth(), playlist.AverageBandwidth(), codecs), nil
+}
+
+// uploadEncryptedSegment 用第 index/keyRotation 个内容密钥加密第 index 个分片后上传，返回密钥地址和密文大小
+func (s *transcodeServiceImpl) uploadEncryptedSegment(ctx context.Context, rendition *entity.Rendition, localPath, objectName string,
+	index, keyRotation int) (string, int64, error) {
+	key, plainKey, err := s.contentKeyService.KeyFor(ctx, rendition.VideoUuid(), index/keyRotation)
+	if err != nil {
+		return "", 0, fmt.Errorf("content key: %w", err)
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", 0, err
+	}
+	encrypted, err := hls.EncryptSegment(data, plainKey, index)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := s.minioService.PutObject(ctx, objectName, bytes.NewReader(encrypted), int64(len(encrypted)), segmentContentType); err != nil {
+		return "", 0, err
+	}
+	return vo.HLSKeyURI(key.UUID()), int64(len(encrypted)), nil
+}
+
+// packageDASH 把视频和音频分别切分为fMP4分片并上传，清单在播放时按档位信息生成；分片不加密，只在HLS不加密时打包
+func (s *transcodeServiceImpl) packageDASH(ctx context.Context, rendition *entity.Rendition, input, dir string,
+	segmentDuration time.Duration, info vo.MediaInfo) (vo.DASHPackage, error) {
+	tracks, err := s.transcoder.Fragment(ctx, input, dir, segmentDuration)
+	if err != nil {
+		return vo.DASHPackage{}, err
+	}
+	bandwidths := make(map[string]int, len(tracks))
+	for _, track := range tracks {
+		contentType := track.ContentType() + "/mp4"
+		initialization := vo.DASHInitialization(track.ContentType())
+		if _, err := s.uploadFile(ctx, filepath.Join(dir, initialization), rendition.DASHDir()+initialization, contentType); err != nil {
+			return vo.DASHPackage{}, err
+		}
+		peak := 0
+		for _, segment := range track.Segments() {
+			size, err := s.uploadFile(ctx, filepath.Join(dir, segment.Filename()), rendition.DASHDir()+segment.Filename(), contentType)
+			if err != nil {
+				return vo.DASHPackage{}, err
+			}
+			if segment.Duration() > 0 {
+				peak = max(peak, int(math.Ceil(float64(size*8)/segment.Duration().Seconds())))
+			}
+		}
+		bandwidths[track.ContentType()] = peak
+	}
+	if bandwidths[vo.DASHVideo] <= 0 {
+		return vo.DASHPackage{}, errors.New("no video track")
+	}
+	return vo.NewDASHPackage(rendition.DASHDir(), segmentDuration, info.Duration(),
+		bandwidths[vo.DASHVideo], bandwidths[vo.DASHAudio], info.Codecs()), nil
+}
+
+// uploadFile 上传本地文件，contentType 为空时按源文件方式上传（配置了静态加密时加密），返回文件大小
+func (s *transcodeServiceImpl) uploadFile(ctx context.Context, localPath, objectName, contentType string) (int64, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if contentType == "" {
+		_, err = s.minioService.UploadStream(ctx, objectName, file, stat.Size(), vo.Checksum{})
+	} else {
+		err = s.minioService.PutObject(ctx, objectName, file, stat.Size(), contentType)
+	}
+	return stat.Size(), err
+}
+
+// downloadObject 把存储中的源文件写入本地文件，加密的源文件在读取时解密
+func downloadObject(ctx context.Context, minioService gateway.MinioService, objectName, path string) error {
+	stat, err := minioService.StatObject(ctx, objectName)
+	if err != nil {
+		return err
+	}
+	reader, err := minioService.OpenObject(ctx, objectName, 0, stat.Size())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// heartbeat 定期记录进度并续租，转码已不再由当前执行者持有时以 ErrTranscodeCanceled 取消执行；返回停止函数
+func (s *transcodeServiceImpl) heartbeat(ctx context.Context, rendition *entity.Rendition, percent *atomic.Int64,
+	cancel context.CancelCauseFunc) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rendition.SetProgress(int(percent.Load()), time.Now().Add(renditionLease))
+				ok, err := s.renditionRepo.UpdateProgress(ctx, rendition)
+				if err != nil {
+					logger.Error(fmt.Sprintf("rendition %s update progress failed: %v", rendition.UUID(), err))
+					continue
+				}
+				if !ok {
+					cancel(vo.ErrTranscodeCanceled)
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// supersede 同一档位以前完成的输出标记为被替换并删除对象
+func (s *transcodeServiceImpl) supersede(ctx context.Context, current *entity.Rendition) error {
+	renditions, err := s.Renditions(ctx, current.VideoUuid())
+	if err != nil {
+		return err
+	}
+	for _, rendition := range renditions {
+		if rendition.UUID() == current.UUID() || rendition.Status() != vo.RenditionStatusSucceeded ||
+			rendition.Profile().Name() != current.Profile().Name() {
+			continue
+		}
+		if err := s.renditionRepo.MarkSuperseded(ctx, rendition.UUID()); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		s.deleteOutputs(ctx, rendition)
+	}
+	return nil
+}
+
+// publishMaster 按当前源文件已打包HLS的档位重写主播放列表，码率从高到低排列，媒体播放列表地址为 <档位名称>/index.m3u8；
+// 写入后重新读取，期间有其他档位完成时再次写入，多个执行者并发发布时最后一次写入总是包含全部已完成的档位
+func (s *transcodeServiceImpl) publishMaster(ctx context.Context, videoUUID string) error {
+	var published []byte
+	for round := 0; round < masterPublishRounds; round++ {
+		video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+		if video == nil {
+			return nil
+		}
+		renditions, err := s.currentRenditions(ctx, video)
+		if err != nil {
+			return err
+		}
+		master := &hls.MasterPlaylist{}
+		for _, rendition := range renditions {
+			if rendition.Status() != vo.RenditionStatusSucceeded || rendition.HLS().IsEmpty() {
+				continue
+			}
+			master.Variants = append(master.Variants, hls.Variant{
+				URI:              rendition.Profile().Name() + "/index.m3u8",
+				Bandwidth:        rendition.HLS().Bandwidth(),
+				AverageBandwidth: rendition.HLS().AverageBandwidth(),
+				Width:            rendition.Width(),
+				Height:           rendition.Height(),
+				Codecs:           rendition.HLS().Codecs(),
+			})
+		}
+		sort.SliceStable(master.Variants, func(i, j int) bool {
+			return master.Variants[i].Bandwidth > master.Variants[j].Bandwidth
+		})
+		content := master.Encode()
+		if bytes.Equal(content, published) {
+			return nil
+		}
+		objectName := video.AssetPrefix() + vo.HLSMasterPlaylist
+		if err := s.minioService.PutObject(ctx, objectName, bytes.NewReader(content), int64(len(content)), hls.ContentType); err != nil {
+			return errno.NewSimpleBizError(errno.ErrInternalServer, err)
+		}
+		published = content
+	}
+	return nil
+}
+
+// settle 处理中的视频在必需档位全部完成后可播放，任一必需档位失败时视频标记为失败并取消其余转码
+func (s *transcodeServiceImpl) settle(ctx context.Context, videoUUID string) error {
+	video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if video == nil || video.Status() != vo.VideoStatusProcessing {
+		return nil
+	}
+	renditions, err := s.currentRenditions(ctx, video)
+	if err != nil {
+		return err
+	}
+	// 先检查失败的档位：其余档位仍在转码时也立即结算，不再等待
+	active := false
+	for _, rendition := range renditions {
+		if !rendition.Profile().Required() {
+			continue
+		}
+		switch rendition.Status() {
+		case vo.RenditionStatusSucceeded:
+		case vo.RenditionStatusPending, vo.RenditionStatusRunning:
+			active = true
+		default:
+			reason := fmt.Sprintf("rendition %s %s: %s", rendition.Profile().Name(), rendition.Status().Value(), rendition.ErrorMsg())
+			if _, err := s.renditionRepo.CancelActive(ctx, videoUUID, "required rendition failed"); err != nil {
+				return errno.NewSimpleBizError(errno.ErrDatabase, err)
+			}
+			return s.transition(ctx, video, vo.VideoStatusFailed, reason)
+		}
+	}
+	if active {
+		return nil
+	}
+	return s.transition(ctx, video, vo.VideoStatusReady, "required renditions ready")
+}
+
+// currentRenditions 当前源文件每个档位最新的转码输出，不含已取消和被替换的
+func (s *transcodeServiceImpl) currentRenditions(ctx context.Context, video *entity.Video) ([]*entity.Rendition, error) {
+	renditions, err := s.Renditions(ctx, video.UUID())
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(renditions))
+	current := make([]*entity.Rendition, 0, len(renditions))
+	for _, rendition := range renditions {
+		if !rendition.IsCurrentFor(video) || seen[rendition.Profile().Name()] ||
+			rendition.Status() == vo.RenditionStatusCanceled || rendition.Status() == vo.RenditionStatusSuperseded {
+			continue
+		}
+		seen[rendition.Profile().Name()] = true
+		current = append(current, rendition)
+	}
+	return current, nil
+}
+
+// transition 迁移视频状态；并发结算时其他执行者已完成迁移，忽略状态冲突
+func (s *transcodeServiceImpl) transition(ctx context.Context, video *entity.Video, to vo.VideoStatus, cause string) error {
+	transition, err := video.TransitionTo(to, cause)
+	if err == nil {
+		err = s.videoRepo.UpdateVideoStatus(ctx, transition, nil)
+	}
+	var transitionErr *vo.TransitionError
+	if errors.As(err, &transitionErr) {
+		logger.Info(fmt.Sprintf("video %s transition skipped: %v", video.UUID(), err))
+		return nil
+	}
+	return transitionBizError(err)
+}
+
+func (s *transcodeServiceImpl) finishCanceled(ctx context.Context, rendition *entity.Rendition, reason string) error {
+	rendition.Cancel(reason)
+	if _, err := s.renditionRepo.Finish(ctx, rendition); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// deleteOutputs 删除转码输出目录下的全部对象（mp4、HLS和DASH分片），失败只记录日志，不再被引用的对象后续由对账清理
+func (s *transcodeServiceImpl) deleteOutputs(ctx context.Context, rendition *entity.Rendition) {
+	objects, err := s.minioService.ListObjects(ctx, path.Dir(rendition.StoragePath())+"/")
+	if err != nil {
+		logger.Error(fmt.Sprintf("list rendition %s objects failed: %v", rendition.UUID(), err))
+		return
+	}
+	for _, object := range objects {
+		if err := s.minioService.DeleteVideo(ctx, object.Key()); err != nil {
+			logger.Error(fmt.Sprintf("delete rendition %s object %s failed: %v", rendition.UUID(), object.Key(), err))
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/transcoder"
+	"go-video/pkg/hls"
+)
+
+// memoryRenditionRepo 内存中的转码输出仓储，保存实体的副本，领取和结束按状态与尝试次数比较并交换
+type memoryRenditionRepo struct {
+	mu         sync.Mutex
+	renditions []*entity.Rendition
+}
+
+var _ repo.RenditionRepository = (*memoryRenditionRepo)(nil)
+
+func cloneRendition(rendition *entity.Rendition) *entity.Rendition {
+	clone := *rendition
+	return &clone
+}
+
+func (r *memoryRenditionRepo) index(renditionUUID string) int {
+	for i, rendition := range r.renditions {
+		if rendition.UUID() == renditionUUID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *memoryRenditionRepo) SaveAll(ctx context.Context, renditions []*entity.Rendition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rendition := range renditions {
+		r.renditions = append(r.renditions, cloneRendition(rendition))
+	}
+	return nil
+}
+
+func (r *memoryRenditionRepo) FindByUUID(ctx context.Context, renditionUUID string) (*entity.Rendition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.index(renditionUUID); i >= 0 {
+		return cloneRendition(r.renditions[i]), nil
+	}
+	return nil, nil
+}
+
+func (r *memoryRenditionRepo) FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.Rendition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*entity.Rendition
+	for i := len(r.renditions) - 1; i >= 0; i-- {
+		if r.renditions[i].VideoUuid() == videoUUID {
+			found = append(found, cloneRendition(r.renditions[i]))
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryRenditionRepo) FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.Rendition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*entity.Rendition
+	for _, rendition := range r.renditions {
+		if len(found) < limit && claimable(rendition, now) {
+			found = append(found, cloneRendition(rendition))
+		}
+	}
+	return found, nil
+}
+
+func claimable(rendition *entity.Rendition, now time.Time) bool {
+	lease := rendition.LeaseUntil()
+	switch rendition.Status() {
+	case vo.RenditionStatusPending:
+		return lease == nil || !lease.After(now)
+	case vo.RenditionStatusRunning:
+		return lease != nil && lease.Before(now)
+	}
+	return false
+}
+
+func (r *memoryRenditionRepo) Claim(ctx context.Context, rendition *entity.Rendition, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.index(rendition.UUID())
+	if i < 0 || !claimable(r.renditions[i], time.Now()) {
+		return false, nil
+	}
+	rendition.Start(leaseUntil)
+	r.renditions[i] = cloneRendition(rendition)
+	return true, nil
+}
+
+// held 实体是否仍由持有该副本的执行者持有
+func (r *memoryRenditionRepo) held(rendition *entity.Rendition) int {
+	i := r.index(rendition.UUID())
+	if i < 0 || r.renditions[i].Status() != vo.RenditionStatusRunning || r.renditions[i].Attempts() != rendition.Attempts() {
+		return -1
+	}
+	return i
+}
+
+func (r *memoryRenditionRepo) UpdateProgress(ctx context.Context, rendition *entity.Rendition) (bool, error) {
+	return r.Finish(ctx, rendition)
+}
+
+func (r *memoryRenditionRepo) Finish(ctx context.Context, rendition *entity.Rendition) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.held(rendition)
+	if i < 0 {
+		return false, nil
+	}
+	r.renditions[i] = cloneRendition(rendition)
+	return true, nil
+}
+
+func (r *memoryRenditionRepo) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var canceled int64
+	for _, rendition := range r.renditions {
+		if rendition.VideoUuid() == videoUUID && rendition.Status().IsActive() {
+			rendition.Cancel(reason)
+			canceled++
+		}
+	}
+	return canceled, nil
+}
+
+func (r *memoryRenditionRepo) MarkSuperseded(ctx context.Context, renditionUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.index(renditionUUID); i >= 0 {
+		old := r.renditions[i]
+		r.renditions[i] = entity.NewRendition(old.UUID(), old.VideoUuid(), old.SourcePath(), old.Profile(),
+			vo.RenditionStatusSuperseded, old.Progress(), old.Attempts(), old.StoragePath(), old.FileSize(),
+			old.Width(), old.Height(), old.ErrorMsg(), nil, old.StartedAt(), old.CompletedAt(), old.CreatedAt())
+	}
+	return nil
+}
+
+// ladderTranscoder 在 FakeTranscoder 的基础上按档位码率决定输出大小，使各档位的HLS码率不同；
+// failures 中的档位在前若干次转码时失败
+type ladderTranscoder struct {
+	*transcoder.FakeTranscoder
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func (t *ladderTranscoder) Transcode(ctx context.Context, input, output string, profile vo.RenditionProfile,
+	progress func(processed time.Duration)) (vo.MediaInfo, error) {
+	t.mu.Lock()
+	if t.failures[profile.Name()] > 0 {
+		t.failures[profile.Name()]--
+		t.mu.Unlock()
+		return vo.MediaInfo{}, errors.New("encoder crashed")
+	}
+	t.mu.Unlock()
+	info, err := t.FakeTranscoder.Transcode(ctx, input, output, profile, progress)
+	if err != nil {
+		return info, err
+	}
+	return info, os.WriteFile(output, bytes.Repeat([]byte{0x47}, profile.VideoBitrate()*10), 0o644)
+}
+
+// memoryContentKeys 每个序号一个固定的内容密钥
+type memoryContentKeys struct {
+	mu   sync.Mutex
+	keys map[int]*entity.ContentKey
+}
+
+func (k *memoryContentKeys) KeyFor(ctx context.Context, videoUUID string, sequence int) (*entity.ContentKey, []byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		k.keys = map[int]*entity.ContentKey{}
+	}
+	if k.keys[sequence] == nil {
+		k.keys[sequence] = entity.DefaultContentKey(videoUUID, sequence, "test", nil)
+	}
+	return k.keys[sequence], bytes.Repeat([]byte{byte(sequence + 1)}, hls.KeySize), nil
+}
+
+func (k *memoryContentKeys) Open(key *entity.ContentKey) ([]byte, error) {
+	return bytes.Repeat([]byte{byte(key.Sequence() + 1)}, hls.KeySize), nil
+}
+
+var testLadder = []vo.RenditionProfile{
+	vo.NewRenditionProfile("1080p", 1080, 5000, 192, false),
+	vo.NewRenditionProfile("720p", 720, 2800, 128, true),
+	vo.NewRenditionProfile("360p", 360, 800, 96, true),
+}
+
+func statusValues(statuses ...vo.VideoStatus) []string {
+	values := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		values = append(values, status.Value())
+	}
+	return values
+}
+
+// masterVariants 主播放列表中媒体播放列表的地址，按输出顺序
+func masterVariants(content []byte) []string {
+	var uris []string
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF:") && i+1 < len(lines) {
+			uris = append(uris, lines[i+1])
+		}
+	}
+	return uris
+}
+
+func TestTranscodePipeline(t *testing.T) {
+	tests := []struct {
+		name           string
+		ladder         []vo.RenditionProfile
+		failures       map[string]int
+		maxAttempts    int
+		keyRotation    int
+		replaceSource  bool
+		wantStatuses   []string
+		wantRenditions map[string]vo.RenditionStatus
+		wantVariants   []string
+	}{
+		{
+			name:         "empty ladder",
+			wantStatuses: statusValues(vo.VideoStatusReady),
+		},
+		{
+			name:         "all renditions succeed",
+			ladder:       testLadder,
+			maxAttempts:  1,
+			wantStatuses: statusValues(vo.VideoStatusProcessing, vo.VideoStatusReady),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"1080p": vo.RenditionStatusSucceeded, "720p": vo.RenditionStatusSucceeded, "360p": vo.RenditionStatusSucceeded,
+			},
+			wantVariants: []string{"1080p/index.m3u8", "720p/index.m3u8", "360p/index.m3u8"},
+		},
+		{
+			name:         "transient failure is retried",
+			ladder:       testLadder,
+			failures:     map[string]int{"720p": 1},
+			maxAttempts:  2,
+			wantStatuses: statusValues(vo.VideoStatusProcessing, vo.VideoStatusReady),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"1080p": vo.RenditionStatusSucceeded, "720p": vo.RenditionStatusSucceeded, "360p": vo.RenditionStatusSucceeded,
+			},
+			wantVariants: []string{"1080p/index.m3u8", "720p/index.m3u8", "360p/index.m3u8"},
+		},
+		{
+			name:         "optional rendition fails",
+			ladder:       testLadder,
+			failures:     map[string]int{"1080p": 100},
+			maxAttempts:  2,
+			wantStatuses: statusValues(vo.VideoStatusProcessing, vo.VideoStatusReady),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"1080p": vo.RenditionStatusFailed, "720p": vo.RenditionStatusSucceeded, "360p": vo.RenditionStatusSucceeded,
+			},
+			wantVariants: []string{"720p/index.m3u8", "360p/index.m3u8"},
+		},
+		{
+			name:         "required rendition fails",
+			ladder:       testLadder,
+			failures:     map[string]int{"720p": 100},
+			maxAttempts:  1,
+			wantStatuses: statusValues(vo.VideoStatusProcessing, vo.VideoStatusFailed),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"1080p": vo.RenditionStatusSucceeded, "720p": vo.RenditionStatusFailed, "360p": vo.RenditionStatusCanceled,
+			},
+			wantVariants: []string{"1080p/index.m3u8"},
+		},
+		{
+			name:         "encrypted segments",
+			ladder:       testLadder[1:],
+			maxAttempts:  1,
+			keyRotation:  2,
+			wantStatuses: statusValues(vo.VideoStatusProcessing, vo.VideoStatusReady),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"720p": vo.RenditionStatusSucceeded, "360p": vo.RenditionStatusSucceeded,
+			},
+			wantVariants: []string{"720p/index.m3u8", "360p/index.m3u8"},
+		},
+		{
+			name:          "source replaced before transcoding",
+			ladder:        testLadder[1:],
+			maxAttempts:   1,
+			replaceSource: true,
+			wantStatuses:  statusValues(vo.VideoStatusProcessing),
+			wantRenditions: map[string]vo.RenditionStatus{
+				"720p": vo.RenditionStatusCanceled, "360p": vo.RenditionStatusCanceled,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			video := uploadedVideo(store, bytes.Repeat([]byte("source"), 1000))
+			videoRepo := newMemoryVideoRepo(video)
+			renditionRepo := &memoryRenditionRepo{}
+			s := &transcodeServiceImpl{
+				minioService:      store,
+				transcoder:        &ladderTranscoder{FakeTranscoder: transcoder.NewFakeTranscoder(), failures: tt.failures},
+				videoRepo:         videoRepo,
+				renditionRepo:     renditionRepo,
+				contentKeyService: &memoryContentKeys{},
+			}
+			opts := vo.NewTranscodeOptions(t.TempDir(), tt.maxAttempts, 0, time.Minute, 4*time.Second, tt.keyRotation)
+
+			if err := s.StartProcessing(ctx, video.UUID(), tt.ladder); err != nil {
+				t.Fatalf("StartProcessing: %v", err)
+			}
+			if tt.replaceSource {
+				video.SetStoragePath(video.AssetPrefix() + "source/replaced.mp4")
+			}
+			for round := 0; ; round++ {
+				if round > 10 {
+					t.Fatal("renditions are still claimable after 10 rounds")
+				}
+				claimed, err := s.ClaimDue(ctx, 10)
+				if err != nil {
+					t.Fatalf("ClaimDue: %v", err)
+				}
+				if len(claimed) == 0 {
+					break
+				}
+				for _, rendition := range claimed {
+					if err := s.Transcode(ctx, rendition, opts); err != nil {
+						t.Fatalf("Transcode %s: %v", rendition.Profile().Name(), err)
+					}
+				}
+			}
+
+			if got := videoRepo.statuses(); !reflect.DeepEqual(got, tt.wantStatuses) {
+				t.Fatalf("video transitions = %v, want %v", got, tt.wantStatuses)
+			}
+			renditions, _ := renditionRepo.FindByVideoUUID(ctx, video.UUID())
+			if len(renditions) != len(tt.wantRenditions) {
+				t.Fatalf("%d renditions, want %d", len(renditions), len(tt.wantRenditions))
+			}
+			for _, rendition := range renditions {
+				name := rendition.Profile().Name()
+				if want := tt.wantRenditions[name]; rendition.Status() != want {
+					t.Fatalf("rendition %s = %s (%s), want %s", name, rendition.Status().Value(), rendition.ErrorMsg(), want.Value())
+				}
+				outputs := store.keys(path.Dir(rendition.StoragePath()) + "/")
+				if rendition.Status() != vo.RenditionStatusSucceeded {
+					if len(outputs) != 0 {
+						t.Fatalf("rendition %s left outputs %v", name, outputs)
+					}
+					continue
+				}
+				checkRenditionOutputs(t, store, rendition, tt.keyRotation)
+			}
+
+			master, ok := store.get(video.AssetPrefix() + vo.HLSMasterPlaylist)
+			if ok != (tt.wantVariants != nil) {
+				t.Fatalf("master playlist published = %v, want %v", ok, tt.wantVariants != nil)
+			}
+			if got := masterVariants(master); !reflect.DeepEqual(got, tt.wantVariants) {
+				t.Fatalf("master variants = %v, want %v", got, tt.wantVariants)
+			}
+		})
+	}
+}
+
+// checkRenditionOutputs 检查完成的档位上传了mp4、HLS媒体播放列表和分片；HLS加密时分片带密钥且不打包DASH
+func checkRenditionOutputs(t *testing.T, store *memoryStore, rendition *entity.Rendition, keyRotation int) {
+	t.Helper()
+	name := rendition.Profile().Name()
+	mp4, ok := store.get(rendition.StoragePath())
+	if !ok || int64(len(mp4)) != rendition.FileSize() || rendition.Width() == 0 || rendition.Height() > rendition.Profile().Height() {
+		t.Fatalf("rendition %s output: stored %v, %d bytes, %dx%d", name, ok, rendition.FileSize(), rendition.Width(), rendition.Height())
+	}
+
+	content, ok := store.get(rendition.HLS().Path())
+	if !ok {
+		t.Fatalf("rendition %s has no media playlist", name)
+	}
+	var keyURIs []string
+	var total time.Duration
+	var segments int
+	for _, line := range strings.Split(string(content), "\n") {
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-KEY:METHOD=AES-128"):
+			keyURIs = append(keyURIs, line)
+		case strings.HasPrefix(line, "#EXTINF:"):
+			duration, err := time.ParseDuration(strings.TrimSuffix(strings.TrimPrefix(line, "#EXTINF:"), ",") + "s")
+			if err != nil {
+				t.Fatalf("rendition %s EXTINF %q: %v", name, line, err)
+			}
+			total += duration
+		case line != "" && !strings.HasPrefix(line, "#"):
+			segments++
+			if _, ok := store.get(rendition.HLSDir() + line); !ok {
+				t.Fatalf("rendition %s segment %s not uploaded", name, line)
+			}
+		}
+	}
+	// FakeTranscoder 的源文件为10秒，按4秒切分为3个分片
+	if segments != 3 || total != 10*time.Second {
+		t.Fatalf("rendition %s playlist has %d segments of %s:\n%s", name, segments, total, content)
+	}
+	if rendition.HLS().Bandwidth() <= 0 {
+		t.Fatalf("rendition %s has no bandwidth", name)
+	}
+
+	dash := store.keys(rendition.DASHDir())
+	if keyRotation > 0 {
+		// 3个分片每2个换一个密钥
+		if len(keyURIs) != 2 {
+			t.Fatalf("rendition %s key tags = %v, want 2", name, keyURIs)
+		}
+		if len(dash) != 0 || !rendition.DASH().IsEmpty() {
+			t.Fatalf("rendition %s packaged clear DASH segments %v while HLS is encrypted", name, dash)
+		}
+		return
+	}
+	if len(keyURIs) != 0 {
+		t.Fatalf("rendition %s has key tags without encryption", name)
+	}
+	// 视频和音频各一个初始化分片和3个媒体分片
+	if len(dash) != 8 || rendition.DASH().IsEmpty() || !rendition.DASH().HasAudio() {
+		t.Fatalf("rendition %s DASH objects = %v", name, dash)
+	}
+}
@@ -1,11 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"io"
 	"sync"
+	"time"
 
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/gateway"
 	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
 	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/ddd/video/infrastructure/minio"
 	"go-video/pkg/assert"
+	"go-video/pkg/errno"
 )
 
 var (
@@ -15,10 +24,35 @@ var (
 
 // VideoService 视频领域服务
 type VideoService interface {
+	// GetVideo 根据UUID获取视频，不存在时返回nil
+	GetVideo(ctx context.Context, videoUUID string) (*entity.Video, error)
+	// HideVideo 下架视频，使其不再出现在公开列表中
+	HideVideo(ctx context.Context, videoUUID string, reason string) error
+	// Transition 按状态机迁移视频状态并记录迁移原因
+	Transition(ctx context.Context, videoUUID string, to vo.VideoStatus, cause string) (*entity.Video, error)
+	// StartUpload 标记上传任务开始执行
+	StartUpload(ctx context.Context, taskUUID string) error
+	// CompleteUpload 根据上传结果迁移视频和上传任务状态，uploadErr为nil表示上传成功，此时记录receipt中的校验和
+	CompleteUpload(ctx context.Context, videoUUID, taskUUID string, receipt vo.UploadReceipt, uploadErr error) error
+	// VerifyChecksum 重新读取存储中的源文件，与上传时记录的校验和比对
+	VerifyChecksum(ctx context.Context, videoUUID string) (*entity.ChecksumVerification, error)
+	// StatusHistory 获取视频的状态迁移历史
+	StatusHistory(ctx context.Context, videoUUID string) ([]*entity.StatusTransition, error)
+	// ListUserVideos 获取用户的全部视频（包括未公开的）
+	ListUserVideos(ctx context.Context, userUUID string) ([]*entity.Video, error)
+	// ListUserUploadTasks 获取用户的全部上传任务
+	ListUserUploadTasks(ctx context.Context, userUUID string) ([]*entity.VideoUploadTaskEntity, error)
+	// StatSource 获取视频源文件的明文属性
+	StatSource(ctx context.Context, video *entity.Video) (vo.ObjectStat, error)
+	// OpenSource 读取视频源文件明文，加密对象透明解密
+	OpenSource(ctx context.Context, video *entity.Video) (io.ReadCloser, error)
+	// SourceURL 获取源文件指定有效期的预签名地址，加密对象通过该地址只能拿到密文
+	SourceURL(ctx context.Context, video *entity.Video, expiry time.Duration) (string, error)
 }
 
 type videoServiceImpl struct {
-	videoRepo repo.VideoRepository
+	videoRepo    repo.VideoRepository
+	minioService gateway.MinioService
 }
 
 // DefaultVideoService 获取默认视频服务实例
@@ -26,9 +60,221 @@ func DefaultVideoService() VideoService {
 	assert.NotCircular()
 	videoDomainServiceOnce.Do(func() {
 		singletonVideoDomainService = &videoServiceImpl{
-			videoRepo: persistence.NewVideoRepository(),
+			videoRepo:    persistence.NewVideoRepository(),
+			minioService: minio.DefaultMinioService(),
 		}
 	})
 	assert.NotNil(singletonVideoDomainService)
 	return singletonVideoDomainService
 }
+
+// GetVideo 根据UUID获取视频
+func (s *videoServiceImpl) GetVideo(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	video, err := s.videoRepo.FindByUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return video, nil
+}
+
+// HideVideo 下架视频
+func (s *videoServiceImpl) HideVideo(ctx context.Context, videoUUID string, reason string) error {
+	video, err := s.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return err
+	}
+	if video == nil {
+		return errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	video.Hide(reason)
+	if err := s.videoRepo.UpdateReview(ctx, video); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// Transition 按状态机迁移视频状态，非法迁移或并发冲突时返回 ErrIllegalTransition
+func (s *videoServiceImpl) Transition(ctx context.Context, videoUUID string, to vo.VideoStatus, cause string) (*entity.Video, error) {
+	video, err := s.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	transition, err := video.TransitionTo(to, cause)
+	if err != nil {
+		return nil, transitionBizError(err)
+	}
+	if err := s.videoRepo.UpdateVideoStatus(ctx, transition, nil); err != nil {
+		return nil, transitionBizError(err)
+	}
+	return video, nil
+}
+
+// StartUpload 上传任务 init -> in_progress
+func (s *videoServiceImpl) StartUpload(ctx context.Context, taskUUID string) error {
+	task, err := s.findUploadTask(ctx, taskUUID)
+	if err != nil {
+		return err
+	}
+	taskTransition, err := task.TransitionTo(vo.VideoUploadTaskStatusInProgress, "")
+	if err != nil {
+		return transitionBizError(err)
+	}
+	return transitionBizError(s.videoRepo.UpdateTaskStatus(ctx, taskTransition))
+}
+
+// CompleteUpload 上传成功时 uploading -> uploaded，上传完成事件触发转码流水线；
+// 上传失败时 uploading -> failed。视频和任务状态在同一事务中迁移，领域事件随状态迁移写入发件箱
+func (s *videoServiceImpl) CompleteUpload(ctx context.Context, videoUUID, taskUUID string, receipt vo.UploadReceipt, uploadErr error) error {
+	video, err := s.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return err
+	}
+	if video == nil {
+		return errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	task, err := s.findUploadTask(ctx, taskUUID)
+	if err != nil {
+		return err
+	}
+
+	videoTo, taskTo, cause := vo.VideoStatusUploaded, vo.VideoUploadTaskStatusCompleted, "source uploaded"
+	if uploadErr != nil {
+		videoTo, taskTo, cause = vo.VideoStatusFailed, vo.VideoUploadTaskStatusFailed, "upload failed: "+uploadErr.Error()
+	} else {
+		// 先设置校验和，上传完成事件中带上源文件的SHA-256
+		video.SetUploadedChecksum(receipt.Checksum(), receipt.ETag())
+		task.SetChecksum(receipt.Checksum(), receipt.ETag())
+	}
+	transition, err := video.TransitionTo(videoTo, cause)
+	if err != nil {
+		return transitionBizError(err)
+	}
+	taskErrorMsg := ""
+	if uploadErr != nil {
+		taskErrorMsg = uploadErr.Error()
+	} else {
+		if err := s.videoRepo.UpdateChecksum(ctx, video, task); err != nil {
+			return errno.NewSimpleBizError(errno.ErrDatabase, err)
+		}
+	}
+	taskTransition, err := task.TransitionTo(taskTo, taskErrorMsg)
+	if err != nil {
+		return transitionBizError(err)
+	}
+	return transitionBizError(s.videoRepo.UpdateVideoStatus(ctx, transition, taskTransition))
+}
+
+// VerifyChecksum 流式读取（加密对象先解密）源文件重新计算校验和，
+// 上传时没有记录校验和的视频返回 ErrChecksumMissing
+func (s *videoServiceImpl) VerifyChecksum(ctx context.Context, videoUUID string) (*entity.ChecksumVerification, error) {
+	video, err := s.GetVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil || video.StoragePath() == "" {
+		return nil, errno.NewSimpleBizError(errno.ErrVideoNotFound, nil)
+	}
+	if video.Checksum().IsEmpty() {
+		return nil, errno.NewSimpleBizError(errno.ErrChecksumMissing, nil)
+	}
+
+	stat, err := s.minioService.StatObject(ctx, video.StoragePath())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	reader, err := s.minioService.OpenObject(ctx, video.StoragePath(), 0, stat.Size())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	defer reader.Close()
+
+	hasher := vo.NewChecksumHasher()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return entity.NewChecksumVerification(video, hasher.Sum(), stat.ETag(), time.Now()), nil
+}
+
+// StatusHistory 获取视频的状态迁移历史
+func (s *videoServiceImpl) StatusHistory(ctx context.Context, videoUUID string) ([]*entity.StatusTransition, error) {
+	transitions, err := s.videoRepo.FindStatusHistory(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return transitions, nil
+}
+
+// ListUserVideos 获取用户的全部视频
+func (s *videoServiceImpl) ListUserVideos(ctx context.Context, userUUID string) ([]*entity.Video, error) {
+	videos, err := s.videoRepo.FindAllByUserUUID(ctx, userUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return videos, nil
+}
+
+// ListUserUploadTasks 获取用户的全部上传任务
+func (s *videoServiceImpl) ListUserUploadTasks(ctx context.Context, userUUID string) ([]*entity.VideoUploadTaskEntity, error) {
+	tasks, err := s.videoRepo.FindUploadTasksByUser(ctx, userUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return tasks, nil
+}
+
+// StatSource 获取视频源文件的明文属性
+func (s *videoServiceImpl) StatSource(ctx context.Context, video *entity.Video) (vo.ObjectStat, error) {
+	stat, err := s.minioService.StatObject(ctx, video.StoragePath())
+	if err != nil {
+		return vo.ObjectStat{}, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return stat, nil
+}
+
+// OpenSource 读取视频源文件明文
+func (s *videoServiceImpl) OpenSource(ctx context.Context, video *entity.Video) (io.ReadCloser, error) {
+	stat, err := s.StatSource(ctx, video)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := s.minioService.OpenObject(ctx, video.StoragePath(), 0, stat.Size())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return reader, nil
+}
+
+// SourceURL 获取源文件指定有效期的预签名地址
+func (s *videoServiceImpl) SourceURL(ctx context.Context, video *entity.Video, expiry time.Duration) (string, error) {
+	url, err := s.minioService.GetObjectURL(ctx, video.StoragePath(), expiry)
+	if err != nil {
+		return "", errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	return url, nil
+}
+
+func (s *videoServiceImpl) findUploadTask(ctx context.Context, taskUUID string) (*entity.VideoUploadTaskEntity, error) {
+	task, err := s.videoRepo.FindUploadTask(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrNotFound, nil)
+	}
+	return task, nil
+}
+
+// transitionBizError 将 *vo.TransitionError 转换为 ErrIllegalTransition，其他错误视为数据库错误
+func transitionBizError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var transitionErr *vo.TransitionError
+	if errors.As(err, &transitionErr) {
+		return errno.NewSimpleBizError(errno.ErrIllegalTransition, err, transitionErr.Error())
+	}
+	return errno.NewSimpleBizError(errno.ErrDatabase, err)
+}
@@ -0,0 +1,143 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ArtworkDir 视频资源目录下存放封面、候选帧和缩略图的子目录
+const ArtworkDir = "artwork/"
+
+// customCoverPrefix 自定义封面的文件名前缀
+const customCoverPrefix = "cover_"
+
+// StoryboardFile 缩略图轨道文件名，与雪碧图在同一目录下
+const StoryboardFile = "storyboard.vtt"
+
+// CustomCoverPath 视频资源目录下自定义封面的存储路径
+func CustomCoverPath(assetPrefix, coverUUID string) string {
+	return assetPrefix + ArtworkDir + customCoverPrefix + coverUUID + ".jpg"
+}
+
+// IsCustomCover 封面是否为所有者上传的自定义封面，重新生成候选帧时不会替换自定义封面
+func IsCustomCover(posterPath string) bool {
+	return strings.Contains(posterPath, "/"+ArtworkDir+customCoverPrefix)
+}
+
+// PosterFilename 第 index 个候选帧的文件名
+func PosterFilename(index int) string {
+	return fmt.Sprintf("poster_%02d.jpg", index)
+}
+
+// SpriteFilename 第 index 张雪碧图的文件名
+func SpriteFilename(index int) string {
+	return fmt.Sprintf("sprite_%03d.jpg", index)
+}
+
+// PosterCandidate 从视频中截取的一张候选封面
+type PosterCandidate struct {
+	offset time.Duration
+	path   string
+}
+
+// NewPosterCandidate 创建候选封面
+func NewPosterCandidate(offset time.Duration, path string) PosterCandidate {
+	return PosterCandidate{
+		offset: offset,
+		path:   path,
+	}
+}
+
+// Offset 截取的时间点
+func (c PosterCandidate) Offset() time.Duration {
+	return c.offset
+}
+
+// Path 存储路径
+func (c PosterCandidate) Path() string {
+	return c.path
+}
+
+// ArtworkOptions 生成封面候选帧和缩略图的参数
+type ArtworkOptions struct {
+	posterOffsets     []time.Duration
+	posterWidth       int
+	thumbnailInterval time.Duration
+	thumbnailWidth    int
+	spriteColumns     int
+	spriteRows        int
+
+	maxAttempts int
+	retryDelay  time.Duration
+	timeout     time.Duration
+	workDir     string
+}
+
+// NewArtworkOptions 创建生成参数
+func NewArtworkOptions(posterOffsets []time.Duration, posterWidth int, thumbnailInterval time.Duration, thumbnailWidth,
+	spriteColumns, spriteRows, maxAttempts int, retryDelay, timeout time.Duration, workDir string) ArtworkOptions {
+	return ArtworkOptions{
+		posterOffsets:     posterOffsets,
+		posterWidth:       posterWidth,
+		thumbnailInterval: thumbnailInterval,
+		thumbnailWidth:    thumbnailWidth,
+		spriteColumns:     spriteColumns,
+		spriteRows:        spriteRows,
+
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		timeout:     timeout,
+		workDir:     workDir,
+	}
+}
+
+// PosterOffsets 候选帧的截取时间点
+func (o ArtworkOptions) PosterOffsets() []time.Duration {
+	return o.posterOffsets
+}
+
+// PosterWidth 候选帧的宽度
+func (o ArtworkOptions) PosterWidth() int {
+	return o.posterWidth
+}
+
+// ThumbnailInterval 缩略图的间隔
+func (o ArtworkOptions) ThumbnailInterval() time.Duration {
+	return o.thumbnailInterval
+}
+
+// ThumbnailWidth 缩略图的宽度
+func (o ArtworkOptions) ThumbnailWidth() int {
+	return o.thumbnailWidth
+}
+
+// SpriteColumns 雪碧图每行的缩略图数
+func (o ArtworkOptions) SpriteColumns() int {
+	return o.spriteColumns
+}
+
+// SpriteRows 雪碧图的行数，缩略图更多时生成多张雪碧图
+func (o ArtworkOptions) SpriteRows() int {
+	return o.spriteRows
+}
+
+// MaxAttempts 最多尝试次数
+func (o ArtworkOptions) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// RetryDelay 失败后的重试间隔，随尝试次数线性增长
+func (o ArtworkOptions) RetryDelay() time.Duration {
+	return o.retryDelay
+}
+
+// Timeout 单次生成的超时时间
+func (o ArtworkOptions) Timeout() time.Duration {
+	return o.timeout
+}
+
+// WorkDir 存放源文件和截图的临时目录，为空时使用系统临时目录
+func (o ArtworkOptions) WorkDir() string {
+	return o.workDir
+}
@@ -0,0 +1,79 @@
+package vo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable 请求的区间超出对象范围
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ByteRange HTTP Range 请求对应的字节区间
+type ByteRange struct {
+	offset  int64
+	length  int64
+	partial bool
+}
+
+// ParseByteRange 解析单个 "bytes=start-end"、"bytes=start-"、"bytes=-suffix" 区间。
+// header为空、格式无法识别或包含多个区间时返回整个对象（RFC 7233 允许忽略Range）
+func ParseByteRange(header string, size int64) (ByteRange, error) {
+	full := ByteRange{offset: 0, length: size}
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return full, nil
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return full, nil
+	}
+
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			return full, nil
+		}
+		if suffix <= 0 || size == 0 {
+			return ByteRange{}, ErrRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return ByteRange{offset: size - suffix, length: suffix, partial: true}, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return full, nil
+	}
+	if start >= size {
+		return ByteRange{}, ErrRangeNotSatisfiable
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return full, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return ByteRange{offset: start, length: end - start + 1, partial: true}, nil
+}
+
+// Offset 获取起始偏移
+func (r ByteRange) Offset() int64 {
+	return r.offset
+}
+
+// Length 获取长度
+func (r ByteRange) Length() int64 {
+	return r.length
+}
+
+// Partial 是否为部分内容（需要返回206）
+func (r ByteRange) Partial() bool {
+	return r.partial
+}
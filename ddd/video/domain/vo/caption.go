@@ -0,0 +1,45 @@
+package vo
+
+import "regexp"
+
+// CaptionFormat 字幕源文件格式
+type CaptionFormat struct {
+	value string
+}
+
+var (
+	CaptionFormatSRT = CaptionFormat{
+		"srt",
+	}
+	CaptionFormatWebVTT = CaptionFormat{
+		"vtt",
+	}
+)
+
+var CaptionFormats = []CaptionFormat{
+	CaptionFormatSRT,
+	CaptionFormatWebVTT,
+}
+
+// NewCaptionFormat 根据字符串创建字幕格式，未知格式返回false
+func NewCaptionFormat(value string) (CaptionFormat, bool) {
+	for _, format := range CaptionFormats {
+		if format.value == value {
+			return format, true
+		}
+	}
+	return CaptionFormat{}, false
+}
+
+// Value 返回格式的字符串值
+func (f CaptionFormat) Value() string {
+	return f.value
+}
+
+// captionLanguagePattern BCP 47 语言标签的简化校验，例如 en、zh-CN、zh-Hans-CN
+var captionLanguagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// IsValidCaptionLanguage 校验字幕语言标签
+func IsValidCaptionLanguage(language string) bool {
+	return len(language) <= 35 && captionLanguagePattern.MatchString(language)
+}
@@ -0,0 +1,187 @@
+package vo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxChapters 单个视频最多章节数
+	MaxChapters = 100
+	// MaxChapterTitleLength 章节标题最大长度
+	MaxChapterTitleLength = 100
+	// openChapterEnd 时长未知时最后一个章节的结束时间，播放器会按实际时长截断
+	openChapterEnd = 24 * time.Hour
+)
+
+// Chapter 章节值对象
+type Chapter struct {
+	start time.Duration
+	title string
+}
+
+// NewChapter 创建章节
+func NewChapter(start time.Duration, title string) Chapter {
+	return Chapter{
+		start: start,
+		title: strings.TrimSpace(title),
+	}
+}
+
+// Start 获取开始时间
+func (c Chapter) Start() time.Duration {
+	return c.start
+}
+
+// Title 获取标题
+func (c Chapter) Title() string {
+	return c.title
+}
+
+// ChapterSource 章节来源
+type ChapterSource struct {
+	value string
+}
+
+var (
+	// ChapterSourceNone 没有章节
+	ChapterSourceNone = ChapterSource{
+		"none",
+	}
+	// ChapterSourceManual 通过API显式设置
+	ChapterSourceManual = ChapterSource{
+		"manual",
+	}
+	// ChapterSourceDescription 从视频描述中解析
+	ChapterSourceDescription = ChapterSource{
+		"description",
+	}
+)
+
+// Value 返回来源的字符串值
+func (s ChapterSource) Value() string {
+	return s.value
+}
+
+// ValidateChapters 校验章节列表：标题非空、开始时间严格递增且不超过视频时长（duration为0表示时长未知）
+func ValidateChapters(chapters []Chapter, duration time.Duration) error {
+	if len(chapters) > MaxChapters {
+		return fmt.Errorf("at most %d chapters are allowed", MaxChapters)
+	}
+	for i, chapter := range chapters {
+		if chapter.title == "" {
+			return fmt.Errorf("chapter %d: title is required", i+1)
+		}
+		if len([]rune(chapter.title)) > MaxChapterTitleLength {
+			return fmt.Errorf("chapter %d: title is longer than %d characters", i+1, MaxChapterTitleLength)
+		}
+		if chapter.start < 0 {
+			return fmt.Errorf("chapter %d: start must not be negative", i+1)
+		}
+		if i > 0 && chapter.start <= chapters[i-1].start {
+			return fmt.Errorf("chapter %d: start must be after the previous chapter", i+1)
+		}
+		if duration > 0 && chapter.start >= duration {
+			return fmt.Errorf("chapter %d: start %s is beyond the video duration %s", i+1, FormatChapterTimestamp(chapter.start), FormatChapterTimestamp(duration))
+		}
+	}
+	return nil
+}
+
+// ChapterEnds 计算每个章节的结束时间：下一章节的开始或视频结束
+func ChapterEnds(chapters []Chapter, duration time.Duration) []time.Duration {
+	ends := make([]time.Duration, len(chapters))
+	for i := range chapters {
+		switch {
+		case i+1 < len(chapters):
+			ends[i] = chapters[i+1].start
+		case duration > chapters[i].start:
+			ends[i] = duration
+		default:
+			ends[i] = chapters[i].start + openChapterEnd
+		}
+	}
+	return ends
+}
+
+// descriptionChapterPattern 匹配描述中的 "HH:MM:SS Title" 或 "MM:SS - Title" 行
+var descriptionChapterPattern = regexp.MustCompile(`^\s*(?:[-*•]\s*)?\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*(?:[-–—:|]\s*)?(\S.*)$`)
+
+// ParseDescriptionChapters 从视频描述中解析章节。
+// 为避免把偶然出现的时间误当作章节，要求至少两个章节、第一个从 00:00 开始且校验通过，否则返回空
+func ParseDescriptionChapters(description string, duration time.Duration) []Chapter {
+	var chapters []Chapter
+	for _, line := range strings.Split(description, "\n") {
+		matches := descriptionChapterPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if matches == nil {
+			continue
+		}
+		start, err := ParseChapterTimestamp(matches[1])
+		if err != nil {
+			continue
+		}
+		chapters = append(chapters, NewChapter(start, matches[2]))
+	}
+
+	if len(chapters) < 2 || chapters[0].start != 0 {
+		return nil
+	}
+	if err := ValidateChapters(chapters, duration); err != nil {
+		return nil
+	}
+	return chapters
+}
+
+// ParseChapterTimestamp 解析 HH:MM:SS、MM:SS，可选 .mmm 毫秒
+func ParseChapterTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	mainPart, msPart, hasMs := strings.Cut(s, ".")
+	fields := strings.Split(mainPart, ":")
+	if len(fields) != 2 && len(fields) != 3 {
+		return 0, errors.New("timestamp must be HH:MM:SS or MM:SS")
+	}
+
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		if field == "" || len(field) > 2 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		// 首个字段之后的分、秒必须小于60
+		if i > 0 && v > 59 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		values[i] = v
+	}
+
+	var d time.Duration
+	for _, v := range values {
+		d = d*60 + time.Duration(v)
+	}
+	d *= time.Second
+
+	if hasMs {
+		if len(msPart) != 3 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		ms, err := strconv.Atoi(msPart)
+		if err != nil || ms < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		d += time.Duration(ms) * time.Millisecond
+	}
+	return d, nil
+}
+
+// FormatChapterTimestamp 格式化为 HH:MM:SS
+func FormatChapterTimestamp(d time.Duration) string {
+	total := int64(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total%3600/60, total%60)
+}
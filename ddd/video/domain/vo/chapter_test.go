@@ -0,0 +1,202 @@
+package vo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateChapters(t *testing.T) {
+	tests := []struct {
+		name     string
+		chapters []Chapter
+		duration time.Duration
+		wantErr  string
+	}{
+		{
+			name:     "ordered within duration",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(90*time.Second, "Main"), NewChapter(9*time.Minute, "Outro")},
+			duration: 10 * time.Minute,
+		},
+		{
+			name:     "unknown duration",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(48*time.Hour, "Later")},
+		},
+		{
+			name:     "empty",
+			duration: time.Minute,
+		},
+		{
+			name:     "overlapping start",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(time.Minute, "A"), NewChapter(time.Minute, "B")},
+			duration: 10 * time.Minute,
+			wantErr:  "chapter 3: start must be after the previous chapter",
+		},
+		{
+			name:     "out of order",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(5*time.Minute, "Late"), NewChapter(2*time.Minute, "Early")},
+			duration: 10 * time.Minute,
+			wantErr:  "chapter 3: start must be after the previous chapter",
+		},
+		{
+			name:     "beyond duration",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(11*time.Minute, "After the end")},
+			duration: 10 * time.Minute,
+			wantErr:  "chapter 2: start 00:11:00 is beyond the video duration 00:10:00",
+		},
+		{
+			name:     "starts exactly at the end",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(10*time.Minute, "End")},
+			duration: 10 * time.Minute,
+			wantErr:  "chapter 2: start 00:10:00 is beyond the video duration",
+		},
+		{
+			name:     "negative start",
+			chapters: []Chapter{NewChapter(-time.Second, "Before")},
+			wantErr:  "chapter 1: start must not be negative",
+		},
+		{
+			name:     "blank title",
+			chapters: []Chapter{NewChapter(0, "Intro"), NewChapter(time.Minute, "   ")},
+			wantErr:  "chapter 2: title is required",
+		},
+		{
+			name:     "title too long",
+			chapters: []Chapter{NewChapter(0, strings.Repeat("章", MaxChapterTitleLength+1))},
+			wantErr:  "chapter 1: title is longer than",
+		},
+		{
+			name:     "too many chapters",
+			chapters: make([]Chapter, MaxChapters+1),
+			wantErr:  "at most 100 chapters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChapters(tt.chapters, tt.duration)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateChapters: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateChapters error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDescriptionChapters(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		duration    time.Duration
+		want        []Chapter
+	}{
+		{
+			name:        "timestamps with separators",
+			description: "My trip\r\n\r\n00:00 Intro\r\n1:30 - Packing\r\n(01:02:03) | Arrival\r\n- 1:05:00 — Home\r\nthanks for watching",
+			duration:    2 * time.Hour,
+			want: []Chapter{
+				NewChapter(0, "Intro"),
+				NewChapter(90*time.Second, "Packing"),
+				NewChapter(time.Hour+2*time.Minute+3*time.Second, "Arrival"),
+				NewChapter(time.Hour+5*time.Minute, "Home"),
+			},
+		},
+		{
+			name:        "single timestamp",
+			description: "Starts at 00:00 sharp\n00:00 Intro",
+		},
+		{
+			name:        "first chapter not at zero",
+			description: "00:10 Intro\n01:00 Main",
+		},
+		{
+			name:        "overlapping chapters",
+			description: "00:00 Intro\n01:00 Main\n01:00 Again",
+		},
+		{
+			name:        "out of order chapters",
+			description: "00:00 Intro\n05:00 Later\n02:00 Earlier",
+		},
+		{
+			name:        "beyond duration",
+			description: "00:00 Intro\n05:00 Main\n12:00 Credits",
+			duration:    10 * time.Minute,
+		},
+		{
+			name:        "beyond unknown duration",
+			description: "00:00 Intro\n12:00 Credits",
+			want:        []Chapter{NewChapter(0, "Intro"), NewChapter(12*time.Minute, "Credits")},
+		},
+		{
+			name:        "invalid timestamps ignored",
+			description: "00:00 Intro\n00:75 Not a time\n02:00 Main",
+			want:        []Chapter{NewChapter(0, "Intro"), NewChapter(2*time.Minute, "Main")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDescriptionChapters(tt.description, tt.duration)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseDescriptionChapters = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChapterTimestamp(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "00:00", want: 0},
+		{in: "5:07", want: 5*time.Minute + 7*time.Second},
+		{in: " 01:02:03 ", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "00:01.250", want: 1250 * time.Millisecond},
+		{in: "99:59", want: 99*time.Minute + 59*time.Second},
+		{in: "90", wantErr: true},
+		{in: "1:2:3:4", wantErr: true},
+		{in: "00:60", wantErr: true},
+		{in: "01:60:00", wantErr: true},
+		{in: "100:00", wantErr: true},
+		{in: "00:-1", wantErr: true},
+		{in: "00:01.25", wantErr: true},
+		{in: "a:00", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseChapterTimestamp(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChapterTimestamp(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("ParseChapterTimestamp(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapterEnds(t *testing.T) {
+	chapters := []Chapter{NewChapter(0, "Intro"), NewChapter(time.Minute, "Main")}
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     []time.Duration
+	}{
+		{name: "known duration", duration: 3 * time.Minute, want: []time.Duration{time.Minute, 3 * time.Minute}},
+		{name: "unknown duration", want: []time.Duration{time.Minute, time.Minute + openChapterEnd}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChapterEnds(chapters, tt.duration); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ChapterEnds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
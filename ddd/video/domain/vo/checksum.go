@@ -0,0 +1,156 @@
+package vo
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Checksum 文件内容校验和，统一保存为小写十六进制，空字符串表示未知
+type Checksum struct {
+	md5    string
+	sha256 string
+}
+
+// NewChecksum 创建校验和（用于从数据库加载）
+func NewChecksum(md5Hex, sha256Hex string) Checksum {
+	return Checksum{
+		md5:    strings.ToLower(md5Hex),
+		sha256: strings.ToLower(sha256Hex),
+	}
+}
+
+// ParseExpectedChecksum 解析客户端提供的校验和：Content-MD5 为base64（RFC 1864），
+// SHA-256 可以是十六进制或base64。两者都可以为空
+func ParseExpectedChecksum(contentMD5, checksumSHA256 string) (Checksum, error) {
+	md5Hex, err := parseDigest("Content-MD5", contentMD5, md5.Size)
+	if err != nil {
+		return Checksum{}, err
+	}
+	sha256Hex, err := parseDigest("X-Checksum-SHA256", checksumSHA256, sha256.Size)
+	if err != nil {
+		return Checksum{}, err
+	}
+	return Checksum{md5: md5Hex, sha256: sha256Hex}, nil
+}
+
+func parseDigest(name, value string, size int) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if len(value) == hex.EncodedLen(size) {
+		if digest, err := hex.DecodeString(value); err == nil {
+			return hex.EncodeToString(digest), nil
+		}
+	}
+	digest, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(digest) != size {
+		return "", fmt.Errorf("%s must be a %d-byte digest in base64 or hex", name, size)
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// MD5 获取MD5（十六进制）
+func (c Checksum) MD5() string {
+	return c.md5
+}
+
+// SHA256 获取SHA-256（十六进制）
+func (c Checksum) SHA256() string {
+	return c.sha256
+}
+
+// IsEmpty 是否没有任何校验和
+func (c Checksum) IsEmpty() bool {
+	return c.md5 == "" && c.sha256 == ""
+}
+
+// Verify 用实际计算出的校验和校验期望值，只比较期望值中提供了的算法
+func (c Checksum) Verify(actual Checksum) error {
+	if c.md5 != "" && c.md5 != actual.md5 {
+		return &ChecksumMismatchError{Algorithm: "md5", Expected: c.md5, Actual: actual.md5}
+	}
+	if c.sha256 != "" && c.sha256 != actual.sha256 {
+		return &ChecksumMismatchError{Algorithm: "sha256", Expected: c.sha256, Actual: actual.sha256}
+	}
+	return nil
+}
+
+// ChecksumMismatchError 校验和不匹配
+type ChecksumMismatchError struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+// Error 实现error接口
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// ChecksumHasher 边读边计算MD5和SHA-256
+type ChecksumHasher struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+	writer io.Writer
+}
+
+// NewChecksumHasher 创建校验和计算器
+func NewChecksumHasher() *ChecksumHasher {
+	h := &ChecksumHasher{
+		md5:    md5.New(),
+		sha256: sha256.New(),
+	}
+	h.writer = io.MultiWriter(h.md5, h.sha256)
+	return h
+}
+
+// Write 实现io.Writer接口
+func (h *ChecksumHasher) Write(p []byte) (int, error) {
+	return h.writer.Write(p)
+}
+
+// Sum 获取已写入内容的校验和
+func (h *ChecksumHasher) Sum() Checksum {
+	return Checksum{
+		md5:    hex.EncodeToString(h.md5.Sum(nil)),
+		sha256: hex.EncodeToString(h.sha256.Sum(nil)),
+	}
+}
+
+// UploadReceipt 上传结果：对象名、服务端计算的明文校验和以及存储返回的ETag
+type UploadReceipt struct {
+	objectName string
+	checksum   Checksum
+	etag       string
+}
+
+// NewUploadReceipt 创建上传结果
+func NewUploadReceipt(objectName string, checksum Checksum, etag string) UploadReceipt {
+	return UploadReceipt{
+		objectName: objectName,
+		checksum:   checksum,
+		etag:       etag,
+	}
+}
+
+// ObjectName 获取对象名
+func (r UploadReceipt) ObjectName() string {
+	return r.objectName
+}
+
+// Checksum 获取校验和
+func (r UploadReceipt) Checksum() Checksum {
+	return r.checksum
+}
+
+// ETag 获取存储ETag
+func (r UploadReceipt) ETag() string {
+	return r.etag
+}
@@ -0,0 +1,153 @@
+package vo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DASHDir 转码输出目录下存放DASH初始化分片和媒体分片的子目录
+const DASHDir = "dash/"
+
+// DASH 分片的轨道类型，同时作为分片文件名的前缀
+const (
+	DASHVideo = "video"
+	DASHAudio = "audio"
+)
+
+// dashObject DASH 分片文件名：<轨道>_init.mp4 或 <轨道>_<编号>.m4s
+var dashObject = regexp.MustCompile(`^(video|audio)_(init\.mp4|[0-9]{5,}\.m4s)$`)
+
+// DASHInitialization 轨道的初始化分片文件名
+func DASHInitialization(contentType string) string {
+	return contentType + "_init.mp4"
+}
+
+// DASHSegment 轨道第number个媒体分片的文件名，编号从1开始
+func DASHSegment(contentType string, number int) string {
+	return fmt.Sprintf("%s_%05d.m4s", contentType, number)
+}
+
+// DASHSegmentTemplate 轨道媒体分片的 SegmentTemplate 地址模板
+func DASHSegmentTemplate(contentType string) string {
+	return contentType + "_$Number%05d$.m4s"
+}
+
+// IsDASHObject 是否为DASH分片文件名
+func IsDASHObject(name string) bool {
+	return dashObject.MatchString(name)
+}
+
+// DASHSegmentSignature 计算分片地址签名：HMAC-SHA256(secret, "dash-segment" + 视频UUID + 转码输出UUID + 过期时间) 的十六进制，
+// 同一档位的全部分片共用一个签名
+func DASHSegmentSignature(secret, videoUUID, renditionUUID string, expires time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{"dash-segment", videoUUID, renditionUUID, strconv.FormatInt(expires.Unix(), 10)}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FragmentedTrack 切分出的一条fMP4轨道，初始化分片和媒体分片按 DASHInitialization、DASHSegment 命名
+type FragmentedTrack struct {
+	contentType string
+	segments    []MediaSegment
+}
+
+// NewFragmentedTrack 创建fMP4轨道
+func NewFragmentedTrack(contentType string, segments []MediaSegment) FragmentedTrack {
+	return FragmentedTrack{
+		contentType: contentType,
+		segments:    segments,
+	}
+}
+
+// ContentType 轨道类型，DASHVideo 或 DASHAudio
+func (t FragmentedTrack) ContentType() string {
+	return t.contentType
+}
+
+// Segments 按播放顺序排列的媒体分片
+func (t FragmentedTrack) Segments() []MediaSegment {
+	return t.segments
+}
+
+// DASHPackage 一档转码输出的DASH分片信息，视频和音频为独立的轨道
+type DASHPackage struct {
+	dir             string
+	segmentDuration time.Duration
+	duration        time.Duration
+	videoBandwidth  int
+	audioBandwidth  int
+	codecs          string
+}
+
+// NewDASHPackage 创建DASH分片信息
+func NewDASHPackage(dir string, segmentDuration, duration time.Duration, videoBandwidth, audioBandwidth int, codecs string) DASHPackage {
+	return DASHPackage{
+		dir:             dir,
+		segmentDuration: segmentDuration,
+		duration:        duration,
+		videoBandwidth:  videoBandwidth,
+		audioBandwidth:  audioBandwidth,
+		codecs:          codecs,
+	}
+}
+
+// Dir 分片的存储目录
+func (p DASHPackage) Dir() string {
+	return p.dir
+}
+
+// SegmentDuration 媒体分片的标称时长，最后一个分片可以更短
+func (p DASHPackage) SegmentDuration() time.Duration {
+	return p.segmentDuration
+}
+
+// Duration 媒体时长
+func (p DASHPackage) Duration() time.Duration {
+	return p.duration
+}
+
+// VideoBandwidth 视频轨道的峰值码率（bit/s）
+func (p DASHPackage) VideoBandwidth() int {
+	return p.videoBandwidth
+}
+
+// AudioBandwidth 音频轨道的峰值码率（bit/s），为0表示没有音频
+func (p DASHPackage) AudioBandwidth() int {
+	return p.audioBandwidth
+}
+
+// HasAudio 是否有音频轨道
+func (p DASHPackage) HasAudio() bool {
+	return p.audioBandwidth > 0
+}
+
+// VideoCodecs 视频轨道的 RFC 6381 编码
+func (p DASHPackage) VideoCodecs() string {
+	return p.trackCodecs("avc1.")
+}
+
+// AudioCodecs 音频轨道的 RFC 6381 编码
+func (p DASHPackage) AudioCodecs() string {
+	return p.trackCodecs("mp4a.")
+}
+
+// IsEmpty 是否没有打包DASH（在支持DASH之前完成的转码）
+func (p DASHPackage) IsEmpty() bool {
+	return p.dir == ""
+}
+
+// trackCodecs 从与HLS共用的 CODECS 中取出指定前缀的编码
+func (p DASHPackage) trackCodecs(prefix string) string {
+	for _, codec := range strings.Split(p.codecs, ",") {
+		if strings.HasPrefix(codec, prefix) {
+			return codec
+		}
+	}
+	return ""
+}
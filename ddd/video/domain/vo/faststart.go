@@ -0,0 +1,98 @@
+package vo
+
+import "time"
+
+// FaststartOutcome 源文件改写（faststart 和清除元数据）的结果
+type FaststartOutcome struct {
+	value string
+}
+
+var (
+	// FaststartOutcomeNone 还没有处理完成
+	FaststartOutcomeNone = FaststartOutcome{
+		"",
+	}
+	// FaststartOutcomeRewritten moov 已移到文件开头或元数据已清除，源文件被改写
+	FaststartOutcomeRewritten = FaststartOutcome{
+		"rewritten",
+	}
+	// FaststartOutcomeUnchanged 源文件已经是 faststart 且没有需要清除的元数据，保持不变
+	FaststartOutcomeUnchanged = FaststartOutcome{
+		"unchanged",
+	}
+	// FaststartOutcomeNotMP4 源文件不是 MP4，保持不变
+	FaststartOutcomeNotMP4 = FaststartOutcome{
+		"not_mp4",
+	}
+	// FaststartOutcomeUnsupported 源文件结构无法安全改写（分片 MP4、压缩的 moov 等），保持不变
+	FaststartOutcomeUnsupported = FaststartOutcome{
+		"unsupported",
+	}
+)
+
+var FaststartOutcomes = []FaststartOutcome{
+	FaststartOutcomeRewritten,
+	FaststartOutcomeUnchanged,
+	FaststartOutcomeNotMP4,
+	FaststartOutcomeUnsupported,
+}
+
+// NewFaststartOutcome 根据字符串创建处理结果，无法识别时视为还没有处理完成
+func NewFaststartOutcome(value string) FaststartOutcome {
+	for _, outcome := range FaststartOutcomes {
+		if outcome.value == value {
+			return outcome
+		}
+	}
+	return FaststartOutcomeNone
+}
+
+// Value 获取字符串值
+func (o FaststartOutcome) Value() string {
+	return o.value
+}
+
+// FaststartOptions 源文件改写的参数
+type FaststartOptions struct {
+	stripMetadata bool
+	maxAttempts   int
+	retryDelay    time.Duration
+	timeout       time.Duration
+	workDir       string
+}
+
+// NewFaststartOptions 创建改写参数，stripMetadata 为true时同时清除上传时没有选择保留的元数据
+func NewFaststartOptions(stripMetadata bool, maxAttempts int, retryDelay, timeout time.Duration, workDir string) FaststartOptions {
+	return FaststartOptions{
+		stripMetadata: stripMetadata,
+		maxAttempts:   maxAttempts,
+		retryDelay:    retryDelay,
+		timeout:       timeout,
+		workDir:       workDir,
+	}
+}
+
+// StripMetadata 是否清除源文件中的位置、设备和自定义元数据
+func (o FaststartOptions) StripMetadata() bool {
+	return o.stripMetadata
+}
+
+// MaxAttempts 最多尝试次数
+func (o FaststartOptions) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// RetryDelay 失败后的重试间隔，随尝试次数线性增长
+func (o FaststartOptions) RetryDelay() time.Duration {
+	return o.retryDelay
+}
+
+// Timeout 单次下载、改写和上传的超时
+func (o FaststartOptions) Timeout() time.Duration {
+	return o.timeout
+}
+
+// WorkDir 临时目录，为空时使用系统临时目录
+func (o FaststartOptions) WorkDir() string {
+	return o.workDir
+}
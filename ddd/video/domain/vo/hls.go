@@ -0,0 +1,93 @@
+package vo
+
+import (
+	"strings"
+	"time"
+)
+
+// HLSDir 转码输出目录下存放HLS分片和媒体播放列表的子目录
+const HLSDir = "hls/"
+
+// HLSMasterPlaylist 视频资源目录下的HLS主播放列表
+const HLSMasterPlaylist = "hls/master.m3u8"
+
+// hlsKeyScheme 媒体播放列表中内容密钥的地址前缀，播放时替换为密钥接口的地址
+const hlsKeyScheme = "key://"
+
+// HLSKeyURI 写入媒体播放列表的内容密钥地址
+func HLSKeyURI(keyUUID string) string {
+	return hlsKeyScheme + keyUUID
+}
+
+// ParseHLSKeyURI 从媒体播放列表中的地址解析内容密钥UUID，不是内容密钥地址时返回false
+func ParseHLSKeyURI(uri string) (string, bool) {
+	keyUUID, ok := strings.CutPrefix(uri, hlsKeyScheme)
+	return keyUUID, ok && keyUUID != ""
+}
+
+// HLSPlaylist 一档转码输出的HLS媒体播放列表
+type HLSPlaylist struct {
+	path             string
+	bandwidth        int
+	averageBandwidth int
+	codecs           string
+}
+
+// NewHLSPlaylist 创建HLS媒体播放列表信息
+func NewHLSPlaylist(path string, bandwidth, averageBandwidth int, codecs string) HLSPlaylist {
+	return HLSPlaylist{
+		path:             path,
+		bandwidth:        bandwidth,
+		averageBandwidth: averageBandwidth,
+		codecs:           codecs,
+	}
+}
+
+// Path 媒体播放列表的存储路径，分片与其在同一目录下
+func (p HLSPlaylist) Path() string {
+	return p.path
+}
+
+// Bandwidth 峰值码率（bit/s）
+func (p HLSPlaylist) Bandwidth() int {
+	return p.bandwidth
+}
+
+// AverageBandwidth 平均码率（bit/s）
+func (p HLSPlaylist) AverageBandwidth() int {
+	return p.averageBandwidth
+}
+
+// Codecs RFC 6381 编码，例如 avc1.64001f,mp4a.40.2
+func (p HLSPlaylist) Codecs() string {
+	return p.codecs
+}
+
+// IsEmpty 是否没有打包HLS（在支持HLS之前完成的转码）
+func (p HLSPlaylist) IsEmpty() bool {
+	return p.path == ""
+}
+
+// MediaSegment 切分出的媒体分片
+type MediaSegment struct {
+	filename string
+	duration time.Duration
+}
+
+// NewMediaSegment 创建媒体分片
+func NewMediaSegment(filename string, duration time.Duration) MediaSegment {
+	return MediaSegment{
+		filename: filename,
+		duration: duration,
+	}
+}
+
+// Filename 分片文件名，位于切分的输出目录下
+func (s MediaSegment) Filename() string {
+	return s.filename
+}
+
+// Duration 分片时长
+func (s MediaSegment) Duration() time.Duration {
+	return s.duration
+}
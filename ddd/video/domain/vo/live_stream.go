@@ -0,0 +1,147 @@
+package vo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LiveObjectPrefix 直播分片所在的前缀，不在 VideoObjectPrefix 下，对账不会把它们当作孤立对象
+const LiveObjectPrefix = "live/"
+
+// liveIncomingDir 编码器推送的分片在登记到直播之前的目录
+const liveIncomingDir = "incoming/"
+
+// streamKeySize 推流密钥的随机字节数
+const streamKeySize = 24
+
+// liveSegmentFilename 编码器推送的分片文件名：只允许字母、数字、下划线、连字符和点，扩展名为 .ts
+var liveSegmentFilename = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}\.ts$`)
+
+// registeredSegmentFilename 登记后的分片文件名：分片UUID加 .ts 扩展名
+var registeredSegmentFilename = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\.ts$`)
+
+// IsLiveSegmentFilename 是否为允许推送的分片文件名
+func IsLiveSegmentFilename(filename string) bool {
+	return liveSegmentFilename.MatchString(filename)
+}
+
+// IsLivePlaylistFilename 是否为编码器推送的媒体播放列表
+func IsLivePlaylistFilename(filename string) bool {
+	return strings.HasSuffix(filename, ".m3u8") && len(filename) <= 64 && !strings.ContainsAny(filename, "/\\")
+}
+
+// LiveIncomingObjectName 编码器推送的分片的暂存路径，编码器重启后文件名可能重复，登记时移到分片自己的路径
+func LiveIncomingObjectName(streamUuid, filename string) string {
+	return LiveObjectPrefix + streamUuid + "/" + liveIncomingDir + path.Base(filename)
+}
+
+// LiveSegmentObjectName 登记后的分片路径
+func LiveSegmentObjectName(streamUuid, segmentUuid string) string {
+	return LiveObjectPrefix + streamUuid + "/" + segmentUuid + ".ts"
+}
+
+// RegisteredSegmentObjectName 观众按登记后的分片文件名请求分片，文件名不合法时返回 false
+func RegisteredSegmentObjectName(streamUuid, filename string) (string, bool) {
+	if !registeredSegmentFilename.MatchString(filename) {
+		return "", false
+	}
+	return LiveObjectPrefix + streamUuid + "/" + filename, true
+}
+
+// LiveStreamPrefix 直播全部分片所在的前缀
+func LiveStreamPrefix(streamUuid string) string {
+	return LiveObjectPrefix + streamUuid + "/"
+}
+
+// NewStreamKey 生成推流密钥，只在创建时返回给主播，库中只保存哈希
+func NewStreamKey() string {
+	key := make([]byte, streamKeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// HashStreamKey 推流密钥的SHA-256，用于按密钥查找直播
+func HashStreamKey(streamKey string) string {
+	sum := sha256.Sum256([]byte(streamKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// LiveStreamStatus 直播状态
+type LiveStreamStatus struct {
+	value string
+}
+
+var (
+	// LiveStreamStatusIdle 已创建，还没有推流
+	LiveStreamStatusIdle = LiveStreamStatus{
+		"idle",
+	}
+	// LiveStreamStatusLive 直播中
+	LiveStreamStatusLive = LiveStreamStatus{
+		"live",
+	}
+	// LiveStreamStatusEnded 已结束，不再接受推流
+	LiveStreamStatusEnded = LiveStreamStatus{
+		"ended",
+	}
+)
+
+var LiveStreamStatuses = []LiveStreamStatus{
+	LiveStreamStatusIdle,
+	LiveStreamStatusLive,
+	LiveStreamStatusEnded,
+}
+
+// NewLiveStreamStatus 根据字符串创建直播状态，无法识别时视为已结束
+func NewLiveStreamStatus(value string) LiveStreamStatus {
+	for _, status := range LiveStreamStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return LiveStreamStatusEnded
+}
+
+// Value 返回状态的字符串值
+func (s LiveStreamStatus) Value() string {
+	return s.value
+}
+
+// LiveOptions 直播结束后转换为视频的参数
+type LiveOptions struct {
+	maxAttempts int
+	retryDelay  time.Duration
+	timeout     time.Duration
+}
+
+// NewLiveOptions 创建转换参数
+func NewLiveOptions(maxAttempts int, retryDelay, timeout time.Duration) LiveOptions {
+	return LiveOptions{
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		timeout:     timeout,
+	}
+}
+
+// MaxAttempts 最多尝试次数
+func (o LiveOptions) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// RetryDelay 失败后的重试间隔，随尝试次数线性增长
+func (o LiveOptions) RetryDelay() time.Duration {
+	return o.retryDelay
+}
+
+// Timeout 单次拼接和上传的超时
+func (o LiveOptions) Timeout() time.Duration {
+	return o.timeout
+}
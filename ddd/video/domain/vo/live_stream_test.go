@@ -0,0 +1,28 @@
+package vo
+
+import "testing"
+
+func TestRegisteredSegmentObjectName(t *testing.T) {
+	const stream = "0b6d4f5e-2f1c-4d47-9a41-1f0c3e2d4b5a"
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{filename: "7c9e6679-7425-40de-944b-e07fc1f90ae7.ts", want: LiveObjectPrefix + stream + "/7c9e6679-7425-40de-944b-e07fc1f90ae7.ts"},
+		{filename: "7C9E6679-7425-40DE-944B-E07FC1F90AE7.ts"},
+		{filename: "7c9e6679-7425-40de-944b-e07fc1f90ae7.m3u8"},
+		{filename: "7c9e6679-7425-40de-944b-e07fc1f90ae7"},
+		{filename: "segment_001.ts"},
+		{filename: "../7c9e6679-7425-40de-944b-e07fc1f90ae7.ts"},
+		{filename: "incoming%2F7c9e6679-7425-40de-944b-e07fc1f90ae7.ts"},
+		{filename: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, ok := RegisteredSegmentObjectName(stream, tt.filename)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Fatalf("RegisteredSegmentObjectName(%q) = %q, %v, want %q", tt.filename, got, ok, tt.want)
+			}
+		})
+	}
+}
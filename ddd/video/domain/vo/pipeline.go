@@ -0,0 +1,239 @@
+package vo
+
+import "fmt"
+
+// 处理流水线的步骤名称
+const (
+	// PipelineStepScan 扫描源文件中的恶意软件，发现时封禁视频并隔离源文件
+	PipelineStepScan = "scan"
+	// PipelineStepFaststart 把源文件改写为 faststart 并清除元数据
+	PipelineStepFaststart = "faststart"
+	// PipelineStepTranscode 按码率阶梯转码，必需档位完成后视频可播放
+	PipelineStepTranscode = "transcode"
+	// PipelineStepArtwork 生成候选封面和拖动预览缩略图
+	PipelineStepArtwork = "artwork"
+)
+
+// PipelineStepStatus 流水线步骤状态
+type PipelineStepStatus struct {
+	value string
+}
+
+var (
+	// PipelineStepStatusPending 等待依赖完成或等待重试
+	PipelineStepStatusPending = PipelineStepStatus{
+		"pending",
+	}
+	// PipelineStepStatusRunning 已调度，等待执行完成
+	PipelineStepStatusRunning = PipelineStepStatus{
+		"running",
+	}
+	// PipelineStepStatusSucceeded 执行完成
+	PipelineStepStatusSucceeded = PipelineStepStatus{
+		"succeeded",
+	}
+	// PipelineStepStatusFailed 重试次数用尽，可以由运维重新执行
+	PipelineStepStatusFailed = PipelineStepStatus{
+		"failed",
+	}
+	// PipelineStepStatusSkipped 对应的功能未启用，不需要执行
+	PipelineStepStatusSkipped = PipelineStepStatus{
+		"skipped",
+	}
+	// PipelineStepStatusCanceled 视频被删除、源文件被替换、依赖的步骤失败或被取消，或处理不应继续（发现恶意软件）
+	PipelineStepStatusCanceled = PipelineStepStatus{
+		"canceled",
+	}
+)
+
+var PipelineStepStatuses = []PipelineStepStatus{
+	PipelineStepStatusPending,
+	PipelineStepStatusRunning,
+	PipelineStepStatusSucceeded,
+	PipelineStepStatusFailed,
+	PipelineStepStatusSkipped,
+	PipelineStepStatusCanceled,
+}
+
+// NewPipelineStepStatus 根据字符串创建步骤状态，无法识别时视为失败
+func NewPipelineStepStatus(value string) PipelineStepStatus {
+	for _, status := range PipelineStepStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return PipelineStepStatusFailed
+}
+
+// Value 返回状态的字符串值
+func (s PipelineStepStatus) Value() string {
+	return s.value
+}
+
+// IsActive 是否还在等待或执行中
+func (s PipelineStepStatus) IsActive() bool {
+	return s == PipelineStepStatusPending || s == PipelineStepStatusRunning
+}
+
+// PipelineStepDefinition 流水线定义中的一个步骤
+type PipelineStepDefinition struct {
+	name        string
+	dependsOn   []string
+	maxAttempts int
+	optional    bool
+}
+
+// NewPipelineStepDefinition 创建步骤定义，optional 的步骤失败时不阻塞依赖它的步骤
+func NewPipelineStepDefinition(name string, dependsOn []string, maxAttempts int, optional bool) PipelineStepDefinition {
+	return PipelineStepDefinition{
+		name:        name,
+		dependsOn:   dependsOn,
+		maxAttempts: maxAttempts,
+		optional:    optional,
+	}
+}
+
+// Name 步骤名称
+func (d PipelineStepDefinition) Name() string {
+	return d.name
+}
+
+// DependsOn 依赖的步骤
+func (d PipelineStepDefinition) DependsOn() []string {
+	return d.dependsOn
+}
+
+// MaxAttempts 最多执行次数
+func (d PipelineStepDefinition) MaxAttempts() int {
+	return d.maxAttempts
+}
+
+// Optional 失败时是否不阻塞依赖它的步骤
+func (d PipelineStepDefinition) Optional() bool {
+	return d.optional
+}
+
+// ValidatePipeline 校验流水线定义：步骤名称不重复，依赖必须在之前声明（因此不会有环）
+func ValidatePipeline(definition []PipelineStepDefinition) error {
+	declared := make(map[string]bool, len(definition))
+	for _, step := range definition {
+		if step.name == "" {
+			return fmt.Errorf("pipeline step name is empty")
+		}
+		if declared[step.name] {
+			return fmt.Errorf("pipeline step %s is declared twice", step.name)
+		}
+		for _, dependency := range step.dependsOn {
+			if !declared[dependency] {
+				return fmt.Errorf("pipeline step %s depends on %s, which is not declared before it", step.name, dependency)
+			}
+		}
+		declared[step.name] = true
+	}
+	return nil
+}
+
+// StepProgress 步骤对应的处理的当前进度
+type StepProgress struct {
+	status   PipelineStepStatus
+	progress int
+	errorMsg string
+}
+
+// NewStepProgress 创建步骤进度，status 为执行中、完成或失败，progress 为0-100
+func NewStepProgress(status PipelineStepStatus, progress int, errorMsg string) StepProgress {
+	return StepProgress{
+		status:   status,
+		progress: progress,
+		errorMsg: errorMsg,
+	}
+}
+
+// Status 处理状态
+func (p StepProgress) Status() PipelineStepStatus {
+	return p.status
+}
+
+// Progress 完成百分比
+func (p StepProgress) Progress() int {
+	return p.progress
+}
+
+// ErrorMsg 失败原因
+func (p StepProgress) ErrorMsg() string {
+	return p.errorMsg
+}
+
+// ProcessingStatus 视频处理的汇总状态
+type ProcessingStatus struct {
+	value string
+}
+
+var (
+	// ProcessingStatusProcessing 还有步骤在等待或执行中
+	ProcessingStatusProcessing = ProcessingStatus{
+		"processing",
+	}
+	// ProcessingStatusCompleted 全部步骤已完成或跳过（可选步骤可能失败）
+	ProcessingStatusCompleted = ProcessingStatus{
+		"completed",
+	}
+	// ProcessingStatusFailed 有必需的步骤失败
+	ProcessingStatusFailed = ProcessingStatus{
+		"failed",
+	}
+	// ProcessingStatusCanceled 处理已取消
+	ProcessingStatusCanceled = ProcessingStatus{
+		"canceled",
+	}
+)
+
+// Value 返回状态的字符串值
+func (s ProcessingStatus) Value() string {
+	return s.value
+}
+
+// ProcessingProgress 视频处理流水线的汇总进度
+type ProcessingProgress struct {
+	status         ProcessingStatus
+	progress       int
+	completedSteps int
+	totalSteps     int
+	currentSteps   []string
+}
+
+// NewProcessingProgress 创建汇总进度
+func NewProcessingProgress(status ProcessingStatus, progress, completedSteps, totalSteps int, currentSteps []string) ProcessingProgress {
+	return ProcessingProgress{
+		status:         status,
+		progress:       progress,
+		completedSteps: completedSteps,
+		totalSteps:     totalSteps,
+		currentSteps:   currentSteps,
+	}
+}
+
+// Status 汇总状态
+func (p ProcessingProgress) Status() ProcessingStatus {
+	return p.status
+}
+
+// Progress 完成百分比，按需要执行的步骤平均
+func (p ProcessingProgress) Progress() int {
+	return p.progress
+}
+
+// CompletedSteps 已完成的步骤数
+func (p ProcessingProgress) CompletedSteps() int {
+	return p.completedSteps
+}
+
+// TotalSteps 需要执行的步骤数，不含跳过的步骤
+func (p ProcessingProgress) TotalSteps() int {
+	return p.totalSteps
+}
+
+// CurrentSteps 执行中的步骤
+func (p ProcessingProgress) CurrentSteps() []string {
+	return p.currentSteps
+}
@@ -0,0 +1,65 @@
+package vo
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrRemoteFileTooLarge 远程文件超过导入大小上限
+var ErrRemoteFileTooLarge = errors.New("remote file exceeds the import size limit")
+
+// videoExtensions 允许导入的视频文件扩展名
+var videoExtensions = []string{".mp4", ".avi", ".mov", ".wmv", ".flv", ".webm", ".mkv"}
+
+// RemoteFile 远程文件的响应信息，size为-1表示服务端未提供长度
+type RemoteFile struct {
+	url         string
+	filename    string
+	contentType string
+	size        int64
+}
+
+// NewRemoteFile 创建远程文件信息
+func NewRemoteFile(url, filename, contentType string, size int64) RemoteFile {
+	return RemoteFile{
+		url:         url,
+		filename:    filename,
+		contentType: contentType,
+		size:        size,
+	}
+}
+
+// URL 获取最终请求的地址（跟随重定向后）
+func (f RemoteFile) URL() string {
+	return f.url
+}
+
+// Filename 获取文件名，取自 Content-Disposition 或URL路径
+func (f RemoteFile) Filename() string {
+	return f.filename
+}
+
+// ContentType 获取响应的内容类型
+func (f RemoteFile) ContentType() string {
+	return f.contentType
+}
+
+// Size 获取响应声明的长度，未知时为-1
+func (f RemoteFile) Size() int64 {
+	return f.size
+}
+
+// IsVideo 内容类型为 video/* 或文件扩展名为视频格式
+func (f RemoteFile) IsVideo() bool {
+	if strings.HasPrefix(strings.ToLower(f.contentType), "video/") {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(f.filename))
+	for _, videoExt := range videoExtensions {
+		if ext == videoExt {
+			return true
+		}
+	}
+	return false
+}
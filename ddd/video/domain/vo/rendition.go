@@ -0,0 +1,211 @@
+package vo
+
+import (
+	"errors"
+	"time"
+)
+
+// RenditionDir 视频资源目录下存放转码输出的子目录
+const RenditionDir = "renditions/"
+
+// ErrTranscodeCanceled 转码被取消（视频被删除、源文件被替换或所有者取消）
+var ErrTranscodeCanceled = errors.New("transcode canceled")
+
+// RenditionStatus 转码输出状态
+type RenditionStatus struct {
+	value string
+}
+
+var (
+	// RenditionStatusPending 等待转码，包括失败后等待重试
+	RenditionStatusPending = RenditionStatus{
+		"pending",
+	}
+	// RenditionStatusRunning 转码中
+	RenditionStatusRunning = RenditionStatus{
+		"running",
+	}
+	// RenditionStatusSucceeded 转码完成，输出已写入存储
+	RenditionStatusSucceeded = RenditionStatus{
+		"succeeded",
+	}
+	// RenditionStatusFailed 重试次数用尽
+	RenditionStatusFailed = RenditionStatus{
+		"failed",
+	}
+	// RenditionStatusCanceled 已取消
+	RenditionStatusCanceled = RenditionStatus{
+		"canceled",
+	}
+	// RenditionStatusSuperseded 同一档位重新转码成功后被替换，对象已删除
+	RenditionStatusSuperseded = RenditionStatus{
+		"superseded",
+	}
+)
+
+var RenditionStatuses = []RenditionStatus{
+	RenditionStatusPending,
+	RenditionStatusRunning,
+	RenditionStatusSucceeded,
+	RenditionStatusFailed,
+	RenditionStatusCanceled,
+	RenditionStatusSuperseded,
+}
+
+// NewRenditionStatus 根据字符串创建转码输出状态，无法识别时视为失败
+func NewRenditionStatus(value string) RenditionStatus {
+	for _, status := range RenditionStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return RenditionStatusFailed
+}
+
+// Value 返回状态的字符串值
+func (s RenditionStatus) Value() string {
+	return s.value
+}
+
+// IsActive 是否还在等待或正在转码
+func (s RenditionStatus) IsActive() bool {
+	return s == RenditionStatusPending || s == RenditionStatusRunning
+}
+
+// RenditionProfile 码率阶梯中的一档：输出高度（宽度按比例缩放）和目标码率。
+// 源视频低于该高度时不放大，按源分辨率输出
+type RenditionProfile struct {
+	name         string
+	height       int
+	videoBitrate int
+	audioBitrate int
+	required     bool
+}
+
+// NewRenditionProfile 创建码率档位，码率单位为kbps；required 表示该档位转码成功后视频才能播放
+func NewRenditionProfile(name string, height, videoBitrate, audioBitrate int, required bool) RenditionProfile {
+	return RenditionProfile{
+		name:         name,
+		height:       height,
+		videoBitrate: videoBitrate,
+		audioBitrate: audioBitrate,
+		required:     required,
+	}
+}
+
+// Name 档位名称，例如 720p
+func (p RenditionProfile) Name() string {
+	return p.name
+}
+
+// Height 输出高度上限（像素）
+func (p RenditionProfile) Height() int {
+	return p.height
+}
+
+// VideoBitrate 视频目标码率（kbps）
+func (p RenditionProfile) VideoBitrate() int {
+	return p.videoBitrate
+}
+
+// AudioBitrate 音频码率（kbps）
+func (p RenditionProfile) AudioBitrate() int {
+	return p.audioBitrate
+}
+
+// Required 是否为视频可播放前必须完成的档位
+func (p RenditionProfile) Required() bool {
+	return p.required
+}
+
+// MediaInfo 视频文件的时长、分辨率和编码
+type MediaInfo struct {
+	duration time.Duration
+	width    int
+	height   int
+	codecs   string
+}
+
+// NewMediaInfo 创建媒体信息
+func NewMediaInfo(duration time.Duration, width, height int, codecs string) MediaInfo {
+	return MediaInfo{
+		duration: duration,
+		width:    width,
+		height:   height,
+		codecs:   codecs,
+	}
+}
+
+// Duration 时长，未知时为0
+func (m MediaInfo) Duration() time.Duration {
+	return m.duration
+}
+
+// Width 视频宽度（像素）
+func (m MediaInfo) Width() int {
+	return m.width
+}
+
+// Height 视频高度（像素）
+func (m MediaInfo) Height() int {
+	return m.height
+}
+
+// Codecs RFC 6381 编码，例如 avc1.64001f,mp4a.40.2，无法识别时为空
+func (m MediaInfo) Codecs() string {
+	return m.codecs
+}
+
+// TranscodeOptions 一次转码的执行参数
+type TranscodeOptions struct {
+	workDir     string
+	maxAttempts int
+	retryDelay  time.Duration
+	timeout     time.Duration
+
+	segmentDuration time.Duration
+	keyRotation     int
+}
+
+// NewTranscodeOptions 创建转码执行参数，workDir为空时使用系统临时目录；keyRotation 为0时HLS分片不加密
+func NewTranscodeOptions(workDir string, maxAttempts int, retryDelay, timeout, segmentDuration time.Duration, keyRotation int) TranscodeOptions {
+	return TranscodeOptions{
+		workDir:     workDir,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		timeout:     timeout,
+
+		segmentDuration: segmentDuration,
+		keyRotation:     keyRotation,
+	}
+}
+
+// WorkDir 存放源文件和转码输出的临时目录
+func (o TranscodeOptions) WorkDir() string {
+	return o.workDir
+}
+
+// MaxAttempts 每档最多转码次数
+func (o TranscodeOptions) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// RetryDelay 失败后的重试间隔，随尝试次数线性增长
+func (o TranscodeOptions) RetryDelay() time.Duration {
+	return o.retryDelay
+}
+
+// Timeout 单次转码的超时时间
+func (o TranscodeOptions) Timeout() time.Duration {
+	return o.timeout
+}
+
+// SegmentDuration HLS 分片的目标时长
+func (o TranscodeOptions) SegmentDuration() time.Duration {
+	return o.segmentDuration
+}
+
+// KeyRotation HLS 分片加密时每个内容密钥加密的分片数，为0表示不加密
+func (o TranscodeOptions) KeyRotation() int {
+	return o.keyRotation
+}
@@ -0,0 +1,128 @@
+package vo
+
+// ReviewStatus 视频审核状态
+type ReviewStatus struct {
+	value string
+}
+
+var (
+	// ReviewStatusNone 无需审核（审核关闭时上传的视频）
+	ReviewStatusNone = ReviewStatus{
+		"none",
+	}
+	// ReviewStatusPending 待审核
+	ReviewStatusPending = ReviewStatus{
+		"pending_review",
+	}
+	// ReviewStatusApproved 审核通过
+	ReviewStatusApproved = ReviewStatus{
+		"approved",
+	}
+	// ReviewStatusRejected 审核拒绝
+	ReviewStatusRejected = ReviewStatus{
+		"rejected",
+	}
+)
+
+var ReviewStatuses = []ReviewStatus{
+	ReviewStatusNone,
+	ReviewStatusPending,
+	ReviewStatusApproved,
+	ReviewStatusRejected,
+}
+
+// NewReviewStatus 根据字符串创建审核状态，未知值（包括历史数据的空值）视为无需审核
+func NewReviewStatus(value string) ReviewStatus {
+	for _, status := range ReviewStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return ReviewStatusNone
+}
+
+// Value 返回状态的字符串值
+func (s ReviewStatus) Value() string {
+	return s.value
+}
+
+// IsPublic 该审核状态下视频是否允许公开展示
+func (s ReviewStatus) IsPublic() bool {
+	return s == ReviewStatusNone || s == ReviewStatusApproved
+}
+
+// ReviewDecision 审核结论
+type ReviewDecision struct {
+	value string
+}
+
+var (
+	// ReviewDecisionApprove 通过
+	ReviewDecisionApprove = ReviewDecision{
+		"approve",
+	}
+	// ReviewDecisionReject 拒绝
+	ReviewDecisionReject = ReviewDecision{
+		"reject",
+	}
+)
+
+// NewReviewDecision 根据字符串创建审核结论
+func NewReviewDecision(value string) (ReviewDecision, bool) {
+	for _, decision := range []ReviewDecision{ReviewDecisionApprove, ReviewDecisionReject} {
+		if decision.value == value {
+			return decision, true
+		}
+	}
+	return ReviewDecision{}, false
+}
+
+// Value 返回结论的字符串值
+func (d ReviewDecision) Value() string {
+	return d.value
+}
+
+// ResultStatus 结论对应的审核状态
+func (d ReviewDecision) ResultStatus() ReviewStatus {
+	if d == ReviewDecisionApprove {
+		return ReviewStatusApproved
+	}
+	return ReviewStatusRejected
+}
+
+// ModerationMode 审核模式
+type ModerationMode struct {
+	value string
+}
+
+var (
+	// ModerationModeOff 不审核，上传完成即公开
+	ModerationModeOff = ModerationMode{
+		"off",
+	}
+	// ModerationModePrePublish 发布前审核，审核通过后才公开
+	ModerationModePrePublish = ModerationMode{
+		"pre_publish",
+	}
+)
+
+// NewModerationMode 根据配置值创建审核模式，未配置时默认关闭
+func NewModerationMode(value string) ModerationMode {
+	if value == ModerationModePrePublish.value {
+		return ModerationModePrePublish
+	}
+	return ModerationModeOff
+}
+
+// Value 返回模式的字符串值
+func (m ModerationMode) Value() string {
+	return m.value
+}
+
+// InitialReviewStatus 新上传视频的初始审核状态
+func (m ModerationMode) InitialReviewStatus() ReviewStatus {
+	if m == ModerationModePrePublish {
+		return ReviewStatusPending
+	}
+	return ReviewStatusNone
+}
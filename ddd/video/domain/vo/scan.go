@@ -0,0 +1,116 @@
+package vo
+
+import (
+	"strings"
+	"time"
+)
+
+// QuarantinePrefix 隔离的源文件所在的前缀，不在 VideoObjectPrefix 下，对账不会把它们当作孤立对象
+const QuarantinePrefix = "quarantine/"
+
+// QuarantineObjectName 源文件隔离后的路径，保留原路径便于排查
+func QuarantineObjectName(objectName string) string {
+	return QuarantinePrefix + strings.TrimPrefix(objectName, "/")
+}
+
+// ScanVerdict 恶意软件扫描的结论
+type ScanVerdict struct {
+	value string
+}
+
+var (
+	// ScanVerdictNone 还没有扫描完成
+	ScanVerdictNone = ScanVerdict{
+		"",
+	}
+	// ScanVerdictClean 没有发现恶意软件
+	ScanVerdictClean = ScanVerdict{
+		"clean",
+	}
+	// ScanVerdictInfected 发现恶意软件，源文件已隔离
+	ScanVerdictInfected = ScanVerdict{
+		"infected",
+	}
+)
+
+var ScanVerdicts = []ScanVerdict{
+	ScanVerdictClean,
+	ScanVerdictInfected,
+}
+
+// NewScanVerdict 根据字符串创建扫描结论，无法识别时视为还没有扫描完成
+func NewScanVerdict(value string) ScanVerdict {
+	for _, verdict := range ScanVerdicts {
+		if verdict.value == value {
+			return verdict
+		}
+	}
+	return ScanVerdictNone
+}
+
+// Value 获取字符串值
+func (v ScanVerdict) Value() string {
+	return v.value
+}
+
+// ScanResult 一次扫描的结果
+type ScanResult struct {
+	verdict   ScanVerdict
+	signature string
+}
+
+// NewCleanScanResult 没有发现恶意软件
+func NewCleanScanResult() ScanResult {
+	return ScanResult{verdict: ScanVerdictClean}
+}
+
+// NewInfectedScanResult 发现恶意软件，signature 为扫描引擎报告的特征名称
+func NewInfectedScanResult(signature string) ScanResult {
+	return ScanResult{verdict: ScanVerdictInfected, signature: signature}
+}
+
+// Verdict 获取扫描结论
+func (r ScanResult) Verdict() ScanVerdict {
+	return r.verdict
+}
+
+// Signature 获取命中的特征名称，干净时为空
+func (r ScanResult) Signature() string {
+	return r.signature
+}
+
+// Infected 是否发现恶意软件
+func (r ScanResult) Infected() bool {
+	return r.verdict == ScanVerdictInfected
+}
+
+// ScanOptions 恶意软件扫描的参数
+type ScanOptions struct {
+	maxAttempts int
+	retryDelay  time.Duration
+	timeout     time.Duration
+}
+
+// NewScanOptions 创建扫描参数
+func NewScanOptions(maxAttempts int, retryDelay, timeout time.Duration) ScanOptions {
+	return ScanOptions{
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		timeout:     timeout,
+	}
+}
+
+// MaxAttempts 最多尝试次数
+func (o ScanOptions) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// RetryDelay 失败后的重试间隔，随尝试次数线性增长
+func (o ScanOptions) RetryDelay() time.Duration {
+	return o.retryDelay
+}
+
+// Timeout 单次读取和扫描的超时
+func (o ScanOptions) Timeout() time.Duration {
+	return o.timeout
+}
@@ -0,0 +1,73 @@
+package vo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShareToken 视频所有者签发的分享令牌，格式为 <签发人UUID>.<过期时间>.<签名>；
+// 令牌不落库，签名密钥更换或签发人不再拥有视频时失效
+type ShareToken struct {
+	issuerUuid string
+	expiresAt  time.Time
+	signature  string
+}
+
+// NewShareToken 签发分享令牌
+func NewShareToken(secret, videoUuid, issuerUuid string, expiresAt time.Time) ShareToken {
+	expiresAt = time.Unix(expiresAt.Unix(), 0)
+	return ShareToken{
+		issuerUuid: issuerUuid,
+		expiresAt:  expiresAt,
+		signature:  shareTokenSignature(secret, videoUuid, issuerUuid, expiresAt),
+	}
+}
+
+// ParseShareToken 解析分享令牌，格式不正确时返回false；签名需要再用 Verify 校验
+func ParseShareToken(token string) (ShareToken, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return ShareToken{}, false
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ShareToken{}, false
+	}
+	return ShareToken{
+		issuerUuid: parts[0],
+		expiresAt:  time.Unix(unix, 0),
+		signature:  parts[2],
+	}, true
+}
+
+// IssuerUuid 签发人UUID
+func (t ShareToken) IssuerUuid() string {
+	return t.issuerUuid
+}
+
+// ExpiresAt 过期时间
+func (t ShareToken) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+// Verify 令牌是为该视频签发的且未过期
+func (t ShareToken) Verify(secret, videoUuid string, now time.Time) bool {
+	expected := shareTokenSignature(secret, videoUuid, t.issuerUuid, t.expiresAt)
+	return now.Before(t.expiresAt) && hmac.Equal([]byte(expected), []byte(t.signature))
+}
+
+// String 令牌文本，可以直接放在查询参数中
+func (t ShareToken) String() string {
+	return t.issuerUuid + "." + strconv.FormatInt(t.expiresAt.Unix(), 10) + "." + t.signature
+}
+
+// shareTokenSignature HMAC-SHA256(secret, "share-token" + 视频UUID + 签发人UUID + 过期时间) 的十六进制
+func shareTokenSignature(secret, videoUuid, issuerUuid string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{"share-token", videoUuid, issuerUuid, strconv.FormatInt(expiresAt.Unix(), 10)}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
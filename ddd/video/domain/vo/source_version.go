@@ -0,0 +1,60 @@
+package vo
+
+// SourceVersionDir 视频资源目录下存放源文件版本的子目录
+const SourceVersionDir = "source/"
+
+// SourceVersionStatus 源文件版本状态
+type SourceVersionStatus struct {
+	value string
+}
+
+var (
+	// SourceVersionStatusUploading 新版本上传中，视频仍使用当前版本
+	SourceVersionStatusUploading = SourceVersionStatus{
+		"uploading",
+	}
+	// SourceVersionStatusActive 视频当前使用的版本，每个视频最多一个
+	SourceVersionStatusActive = SourceVersionStatus{
+		"active",
+	}
+	// SourceVersionStatusSuperseded 已被替换，保留期内可以回滚
+	SourceVersionStatusSuperseded = SourceVersionStatus{
+		"superseded",
+	}
+	// SourceVersionStatusFailed 上传失败，从未生效
+	SourceVersionStatusFailed = SourceVersionStatus{
+		"failed",
+	}
+	// SourceVersionStatusPurged 超过保留期，对象已删除
+	SourceVersionStatusPurged = SourceVersionStatus{
+		"purged",
+	}
+)
+
+var SourceVersionStatuses = []SourceVersionStatus{
+	SourceVersionStatusUploading,
+	SourceVersionStatusActive,
+	SourceVersionStatusSuperseded,
+	SourceVersionStatusFailed,
+	SourceVersionStatusPurged,
+}
+
+// NewSourceVersionStatus 根据字符串创建版本状态，无法识别时视为失败
+func NewSourceVersionStatus(value string) SourceVersionStatus {
+	for _, status := range SourceVersionStatuses {
+		if status.value == value {
+			return status
+		}
+	}
+	return SourceVersionStatusFailed
+}
+
+// Value 返回状态的字符串值
+func (s SourceVersionStatus) Value() string {
+	return s.value
+}
+
+// HoldsObject 该状态下版本的存储对象是否需要保留
+func (s SourceVersionStatus) HoldsObject() bool {
+	return s == SourceVersionStatusUploading || s == SourceVersionStatusActive || s == SourceVersionStatusSuperseded
+}
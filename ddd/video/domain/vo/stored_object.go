@@ -0,0 +1,138 @@
+package vo
+
+import "time"
+
+// VideoObjectPrefix 视频相关对象在存储桶中的公共前缀
+const VideoObjectPrefix = "videos/"
+
+// StoredObject 对象存储中的对象
+type StoredObject struct {
+	key          string
+	size         int64
+	lastModified time.Time
+}
+
+// NewStoredObject 创建对象描述
+func NewStoredObject(key string, size int64, lastModified time.Time) StoredObject {
+	return StoredObject{
+		key:          key,
+		size:         size,
+		lastModified: lastModified,
+	}
+}
+
+// Key 获取对象名
+func (o StoredObject) Key() string {
+	return o.key
+}
+
+// Size 获取对象大小
+func (o StoredObject) Size() int64 {
+	return o.size
+}
+
+// LastModified 获取最后修改时间
+func (o StoredObject) LastModified() time.Time {
+	return o.lastModified
+}
+
+// ReconcileAction 对账时对差异项采取的处理
+type ReconcileAction struct {
+	value string
+}
+
+var (
+	// ReconcileActionReported 试运行，仅报告
+	ReconcileActionReported = ReconcileAction{
+		"reported",
+	}
+	// ReconcileActionGracePeriod 仍在宽限期内，暂不处理
+	ReconcileActionGracePeriod = ReconcileAction{
+		"grace_period",
+	}
+	// ReconcileActionDeleted 孤儿对象已删除
+	ReconcileActionDeleted = ReconcileAction{
+		"deleted",
+	}
+	// ReconcileActionMarkedFailed 源文件缺失的视频已标记为失败
+	ReconcileActionMarkedFailed = ReconcileAction{
+		"marked_failed",
+	}
+	// ReconcileActionError 处理失败
+	ReconcileActionError = ReconcileAction{
+		"error",
+	}
+)
+
+// Value 返回处理方式的字符串值
+func (a ReconcileAction) Value() string {
+	return a.value
+}
+
+// ObjectStat 对象的明文属性，加密对象的大小为解密后的大小
+type ObjectStat struct {
+	size        int64
+	contentType string
+	keyID       string
+	etag        string
+}
+
+// NewObjectStat 创建对象属性，keyID为空表示未加密
+func NewObjectStat(size int64, contentType, keyID, etag string) ObjectStat {
+	return ObjectStat{
+		size:        size,
+		contentType: contentType,
+		keyID:       keyID,
+		etag:        etag,
+	}
+}
+
+// ETag 获取存储ETag
+func (s ObjectStat) ETag() string {
+	return s.etag
+}
+
+// Size 获取明文大小
+func (s ObjectStat) Size() int64 {
+	return s.size
+}
+
+// ContentType 获取内容类型
+func (s ObjectStat) ContentType() string {
+	return s.contentType
+}
+
+// KeyID 获取包装数据密钥的主密钥ID，未加密为空
+func (s ObjectStat) KeyID() string {
+	return s.keyID
+}
+
+// Encrypted 是否为加密对象，加密对象不能通过预签名地址直接播放
+func (s ObjectStat) Encrypted() bool {
+	return s.keyID != ""
+}
+
+// RewrapOutcome 重新包装数据密钥的结果
+type RewrapOutcome struct {
+	value string
+}
+
+var (
+	// RewrapOutcomeRewrapped 已用当前主密钥重新包装
+	RewrapOutcomeRewrapped = RewrapOutcome{
+		"rewrapped",
+	}
+	// RewrapOutcomeCurrent 已经使用当前主密钥，无需处理
+	RewrapOutcomeCurrent = RewrapOutcome{
+		"current",
+	}
+	// RewrapOutcomePlaintext 未加密对象，无需处理
+	RewrapOutcomePlaintext = RewrapOutcome{
+		"plaintext",
+	}
+)
+
+// Value 返回结果的字符串值
+func (o RewrapOutcome) Value() string {
+	return o.value
+}
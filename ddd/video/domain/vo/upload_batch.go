@@ -0,0 +1,33 @@
+package vo
+
+// MaxBatchUploadFiles 单次批量上传最多文件数
+const MaxBatchUploadFiles = 50
+
+// UploadBatchStatus 批量上传的汇总状态，由子任务状态计算得出
+type UploadBatchStatus struct {
+	value string
+}
+
+var (
+	// UploadBatchStatusProcessing 仍有子任务在上传
+	UploadBatchStatusProcessing = UploadBatchStatus{
+		"processing",
+	}
+	// UploadBatchStatusCompleted 全部文件上传成功
+	UploadBatchStatusCompleted = UploadBatchStatus{
+		"completed",
+	}
+	// UploadBatchStatusPartial 部分文件被拒绝或上传失败
+	UploadBatchStatusPartial = UploadBatchStatus{
+		"partial",
+	}
+	// UploadBatchStatusFailed 没有任何文件上传成功
+	UploadBatchStatusFailed = UploadBatchStatus{
+		"failed",
+	}
+)
+
+// Value 返回状态的字符串值
+func (s UploadBatchStatus) Value() string {
+	return s.value
+}
@@ -1,6 +1,10 @@
 package vo
 
-import "mime/multipart"
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+)
 
 type VideoUploadVO struct {
 	userUUID    string
@@ -8,15 +12,17 @@ type VideoUploadVO struct {
 	taskUUID    string
 	storagePath string
 	file        *multipart.FileHeader
+	expected    Checksum
 }
 
-func NewUploadVideo(userUUID string, videoUUID string, taskUUID string, storagePath string, file *multipart.FileHeader) *VideoUploadVO {
+func NewUploadVideo(userUUID string, videoUUID string, taskUUID string, storagePath string, file *multipart.FileHeader, expected Checksum) *VideoUploadVO {
 	return &VideoUploadVO{
 		userUUID:    userUUID,
 		videoUUID:   videoUUID,
 		taskUUID:    taskUUID,
 		file:        file,
 		storagePath: storagePath,
+		expected:    expected,
 	}
 }
 func (v *VideoUploadVO) UserUUID() string {
@@ -39,6 +45,11 @@ func (v *VideoUploadVO) File() *multipart.FileHeader {
 	return v.file
 }
 
+// ExpectedChecksum 客户端提供的校验和，为空表示不校验
+func (v *VideoUploadVO) ExpectedChecksum() Checksum {
+	return v.expected
+}
+
 type VideoUploadTaskStatus struct {
 	value string
 }
@@ -70,13 +81,18 @@ var VideoUploadTaskStatuses = []VideoUploadTaskStatus{
 	VideoUploadTaskStatusFailed,
 }
 
-func NewVideoUploadTaskStatus(value string) VideoUploadTaskStatus {
+// ErrUnknownVideoUploadTaskStatus 无法识别的上传任务状态
+var ErrUnknownVideoUploadTaskStatus = errors.New("unknown upload task status")
+
+// NewVideoUploadTaskStatus 根据字符串创建上传任务状态。
+// 无法识别时返回错误，同时返回保留原始值的状态，该状态不允许任何迁移，不会被当作新任务重新开始上传
+func NewVideoUploadTaskStatus(value string) (VideoUploadTaskStatus, error) {
 	for _, status := range VideoUploadTaskStatuses {
 		if status.value == value {
-			return status
+			return status, nil
 		}
 	}
-	return VideoUploadTaskStatusInit
+	return VideoUploadTaskStatus{value}, fmt.Errorf("%w: %q", ErrUnknownVideoUploadTaskStatus, value)
 }
 
 // String 返回状态的字符串值（用于PO转换）
@@ -114,40 +130,19 @@ func (s VideoUploadTaskStatus) IsFailed() bool {
 	return s.value == VideoUploadTaskStatusFailed.value
 }
 
-type VideoStatus struct {
-	value string
+// videoUploadTaskTransitions 上传任务允许的状态迁移
+var videoUploadTaskTransitions = map[VideoUploadTaskStatus][]VideoUploadTaskStatus{
+	VideoUploadTaskStatusInit:       {VideoUploadTaskStatusInProgress, VideoUploadTaskStatusFailed},
+	VideoUploadTaskStatusInProgress: {VideoUploadTaskStatusCompleted, VideoUploadTaskStatusFailed},
+	VideoUploadTaskStatusFailed:     {VideoUploadTaskStatusInProgress},
 }
 
-var (
-	VideoStatusInit = VideoStatus{
-		"init",
-	}
-	VideoStatusInProgress = VideoStatus{
-		"in_progress",
-	}
-	VideoStatusCompleted = VideoStatus{
-		"completed",
-	}
-	VideoStatusFailed = VideoStatus{
-		"failed",
-	}
-)
-var VideoStatuses = []VideoStatus{
-	VideoStatusInit,
-	VideoStatusInProgress,
-	VideoStatusCompleted,
-	VideoStatusFailed,
-}
-
-func NewVideoStatus(value string) VideoStatus {
-	for _, status := range VideoStatuses {
-		if status.value == value {
-			return status
+// CanTransitionTo 检查是否允许迁移到目标状态
+func (s VideoUploadTaskStatus) CanTransitionTo(to VideoUploadTaskStatus) bool {
+	for _, allowed := range videoUploadTaskTransitions[s] {
+		if allowed == to {
+			return true
 		}
 	}
-	return VideoStatusInit
-}
-
-func (s VideoStatus) Value() string {
-	return s.value
+	return false
 }
@@ -0,0 +1,45 @@
+package vo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewVideoUploadTaskStatus(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    VideoUploadTaskStatus
+		wantErr bool
+	}{
+		{value: "init", want: VideoUploadTaskStatusInit},
+		{value: "in_progress", want: VideoUploadTaskStatusInProgress},
+		{value: "completed", want: VideoUploadTaskStatusCompleted},
+		{value: "failed", want: VideoUploadTaskStatusFailed},
+		{value: "", wantErr: true},
+		{value: "INIT", wantErr: true},
+		{value: "uploading", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			status, err := NewVideoUploadTaskStatus(tt.value)
+			if !tt.wantErr {
+				if err != nil || status != tt.want {
+					t.Fatalf("NewVideoUploadTaskStatus(%q) = %q, %v", tt.value, status.Value(), err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnknownVideoUploadTaskStatus) {
+				t.Fatalf("NewVideoUploadTaskStatus(%q) error = %v, want ErrUnknownVideoUploadTaskStatus", tt.value, err)
+			}
+			// 保留原始值，不会被当作新任务，也不允许迁移到任何状态
+			if status.Value() != tt.value || status.IsInit() {
+				t.Fatalf("NewVideoUploadTaskStatus(%q) = %q", tt.value, status.Value())
+			}
+			for _, to := range VideoUploadTaskStatuses {
+				if status.CanTransitionTo(to) {
+					t.Fatalf("unknown status %q can transition to %q", tt.value, to.Value())
+				}
+			}
+		})
+	}
+}
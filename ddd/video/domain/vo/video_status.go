@@ -0,0 +1,131 @@
+package vo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// VideoStatus 视频生命周期状态
+type VideoStatus struct {
+	value string
+}
+
+var (
+	// VideoStatusUploading 源文件上传中
+	VideoStatusUploading = VideoStatus{
+		"uploading",
+	}
+	// VideoStatusUploaded 源文件已上传，等待处理
+	VideoStatusUploaded = VideoStatus{
+		"uploaded",
+	}
+	// VideoStatusProcessing 处理中（转码等）
+	VideoStatusProcessing = VideoStatus{
+		"processing",
+	}
+	// VideoStatusReady 可播放
+	VideoStatusReady = VideoStatus{
+		"ready",
+	}
+	// VideoStatusFailed 上传或处理失败
+	VideoStatusFailed = VideoStatus{
+		"failed",
+	}
+	// VideoStatusBlocked 被封禁，不可播放
+	VideoStatusBlocked = VideoStatus{
+		"blocked",
+	}
+	// VideoStatusDeleted 已删除，终态
+	VideoStatusDeleted = VideoStatus{
+		"deleted",
+	}
+)
+
+var VideoStatuses = []VideoStatus{
+	VideoStatusUploading,
+	VideoStatusUploaded,
+	VideoStatusProcessing,
+	VideoStatusReady,
+	VideoStatusFailed,
+	VideoStatusBlocked,
+	VideoStatusDeleted,
+}
+
+// legacyVideoStatuses 旧版本写入数据库的状态值
+var legacyVideoStatuses = map[string]VideoStatus{
+	"init":        VideoStatusUploading,
+	"in_progress": VideoStatusUploading,
+	"completed":   VideoStatusReady,
+}
+
+// videoStatusTransitions 允许的状态迁移
+var videoStatusTransitions = map[VideoStatus][]VideoStatus{
+	VideoStatusUploading:  {VideoStatusUploaded, VideoStatusFailed, VideoStatusDeleted},
+	VideoStatusUploaded:   {VideoStatusProcessing, VideoStatusReady, VideoStatusFailed, VideoStatusBlocked, VideoStatusDeleted},
+	VideoStatusProcessing: {VideoStatusReady, VideoStatusFailed, VideoStatusBlocked, VideoStatusDeleted},
+	VideoStatusReady:      {VideoStatusProcessing, VideoStatusBlocked, VideoStatusDeleted},
+	VideoStatusFailed:     {VideoStatusUploading, VideoStatusProcessing, VideoStatusDeleted},
+	VideoStatusBlocked:    {VideoStatusReady, VideoStatusDeleted},
+}
+
+// ErrUnknownVideoStatus 无法识别的视频状态
+var ErrUnknownVideoStatus = errors.New("unknown video status")
+
+// NewVideoStatus 根据字符串创建视频状态，兼容旧版本的状态值。
+// 无法识别时返回错误，同时返回保留原始值的状态，该状态不允许任何迁移
+func NewVideoStatus(value string) (VideoStatus, error) {
+	for _, status := range VideoStatuses {
+		if status.value == value {
+			return status, nil
+		}
+	}
+	if status, ok := legacyVideoStatuses[value]; ok {
+		return status, nil
+	}
+	return VideoStatus{value}, fmt.Errorf("%w: %q", ErrUnknownVideoStatus, value)
+}
+
+// Value 返回状态的字符串值
+func (s VideoStatus) Value() string {
+	return s.value
+}
+
+// StoredValues 返回数据库中可能表示该状态的全部取值（包括旧版本的取值），用于查询和条件更新
+func (s VideoStatus) StoredValues() []string {
+	values := []string{s.value}
+	for legacy, status := range legacyVideoStatuses {
+		if status == s {
+			values = append(values, legacy)
+		}
+	}
+	return values
+}
+
+// IsPlayable 检查是否可以播放
+func (s VideoStatus) IsPlayable() bool {
+	return s == VideoStatusReady
+}
+
+// CanTransitionTo 检查是否允许迁移到目标状态
+func (s VideoStatus) CanTransitionTo(to VideoStatus) bool {
+	for _, allowed := range videoStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError 非法的状态迁移
+type TransitionError struct {
+	Subject string // 迁移对象，例如 video、upload_task
+	ID      string // 对象UUID
+	From    string
+	To      string
+	Reason  string
+}
+
+// Error 实现error接口
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s %s: cannot transition from %s to %s: %s", e.Subject, e.ID, e.From, e.To, e.Reason)
+}
@@ -1,11 +1,16 @@
 package convertor
 
 import (
+	"encoding/json"
+	"fmt"
 	"go-video/ddd/video/domain/entity"
 	"go-video/ddd/video/domain/vo"
 	"go-video/ddd/video/infrastructure/database/po"
 	"go-video/pkg/assert"
+	"go-video/pkg/logger"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -47,6 +52,25 @@ func (c *VideoConvertor) EntityToPO(video *entity.Video) *po.VideoPo {
 		Format:      video.Format(),
 		StoragePath: video.StoragePath(),
 		Status:      video.Status().Value(),
+		Duration:    int(video.Duration() / time.Second),
+
+		ChecksumMD5:    video.Checksum().MD5(),
+		ChecksumSHA256: video.Checksum().SHA256(),
+		ETag:           video.ETag(),
+		SourceSHA256:   video.SourceSHA256(),
+
+		ReviewStatus: video.ReviewStatus().Value(),
+		ReviewReason: video.ReviewReason(),
+
+		Tags: strings.Join(video.Tags(), ","),
+
+		PosterPath: video.PosterPath(),
+
+		KeepMetadata:     video.KeepMetadata(),
+		StrippedMetadata: strings.Join(video.StrippedMetadata(), ","),
+	}
+	if video.CreatedAt() != nil {
+		videoPO.CreatedAt = video.CreatedAt()
 	}
 
 	return videoPO
@@ -58,7 +82,26 @@ func (c *VideoConvertor) POToEntity(videoPO *po.VideoPo) *entity.Video {
 		return nil
 	}
 
-	video := entity.NewVideo(videoPO.UUID, videoPO.UserUUID, videoPO.Title, videoPO.Description, videoPO.Filename, videoPO.FileSize, videoPO.Format, vo.NewVideoStatus(videoPO.Status))
+	status, err := vo.NewVideoStatus(videoPO.Status)
+	if err != nil {
+		// 保留原始值，未知状态不允许任何迁移，需要人工处理
+		logger.Error(fmt.Sprintf("video %s: %v", videoPO.UUID, err))
+	}
+	video := entity.NewVideo(videoPO.UUID, videoPO.UserUUID, videoPO.Title, videoPO.Description, videoPO.Filename, videoPO.FileSize, videoPO.Format, status)
+	video.SetStoragePath(videoPO.StoragePath)
+	video.SetDuration(time.Duration(videoPO.Duration) * time.Second)
+	video.SetCreatedAt(videoPO.CreatedAt)
+	video.SetChecksum(vo.NewChecksum(videoPO.ChecksumMD5, videoPO.ChecksumSHA256), videoPO.ETag)
+	video.SetSourceSHA256(videoPO.SourceSHA256)
+	video.SetReview(vo.NewReviewStatus(videoPO.ReviewStatus), videoPO.ReviewReason)
+	if videoPO.Tags != "" {
+		video.SetTags(strings.Split(videoPO.Tags, ","))
+	}
+	video.SetPosterPath(videoPO.PosterPath)
+	video.SetKeepMetadata(videoPO.KeepMetadata)
+	if videoPO.StrippedMetadata != "" {
+		video.SetStrippedMetadata(strings.Split(videoPO.StrippedMetadata, ","))
+	}
 
 	return video
 }
@@ -72,10 +115,21 @@ func (c *VideoConvertor) VideoUploadTaskEntityToPO(task *entity.VideoUploadTaskE
 	taskPO := &po.VideoUploadTaskPo{
 		UUID:        task.UUID(),
 		UserUUID:    task.UserUuid(),
+		VideoUUID:   task.VideoUuid(),
 		Status:      task.Status().String(),
 		ErrorMsg:    task.ErrorMsg(),
 		CompletedAt: task.CompletedAt(),
 		StoragePath: task.ObjectName(),
+
+		ChecksumMD5:    task.Checksum().MD5(),
+		ChecksumSHA256: task.Checksum().SHA256(),
+		ETag:           task.ETag(),
+
+		BatchUUID: task.BatchUuid(),
+
+		SourceURL:     task.SourceURL(),
+		BytesReceived: task.BytesReceived(),
+		BytesTotal:    task.BytesTotal(),
 	}
 
 	return taskPO
@@ -86,10 +140,506 @@ func (c *VideoConvertor) VideoUploadTaskPOToEntity(taskPO *po.VideoUploadTaskPo)
 	if taskPO == nil {
 		return nil
 	}
-	return entity.NewVideoUploadTask(taskPO.UUID,
+	status, err := vo.NewVideoUploadTaskStatus(taskPO.Status)
+	if err != nil {
+		// 保留原始值，未知状态不允许任何迁移，需要人工处理
+		logger.Error(fmt.Sprintf("upload task %s: %v", taskPO.UUID, err))
+	}
+	task := entity.NewVideoUploadTask(taskPO.UUID,
 		taskPO.UserUUID,
-		vo.NewVideoUploadTaskStatus(taskPO.Status),
+		status,
 		taskPO.ErrorMsg,
 		taskPO.CompletedAt,
 		taskPO.StoragePath)
+	task.SetVideoUuid(taskPO.VideoUUID)
+	task.SetChecksum(vo.NewChecksum(taskPO.ChecksumMD5, taskPO.ChecksumSHA256), taskPO.ETag)
+	task.SetBatchUuid(taskPO.BatchUUID)
+	task.SetSourceURL(taskPO.SourceURL)
+	task.SetProgress(taskPO.BytesReceived, taskPO.BytesTotal)
+	return task
+}
+
+// StatusTransitionToPO 状态迁移记录转PO
+func (c *VideoConvertor) StatusTransitionToPO(transition *entity.StatusTransition) *po.VideoStatusHistoryPo {
+	if transition == nil {
+		return nil
+	}
+	return &po.VideoStatusHistoryPo{
+		UUID:       transition.UUID(),
+		VideoUUID:  transition.VideoUuid(),
+		FromStatus: transition.From().Value(),
+		ToStatus:   transition.To().Value(),
+		Cause:      transition.Cause(),
+	}
+}
+
+// StatusHistoryPOToEntity 状态迁移记录PO转实体
+func (c *VideoConvertor) StatusHistoryPOToEntity(historyPO *po.VideoStatusHistoryPo) *entity.StatusTransition {
+	if historyPO == nil {
+		return nil
+	}
+	from, _ := vo.NewVideoStatus(historyPO.FromStatus)
+	to, _ := vo.NewVideoStatus(historyPO.ToStatus)
+	return entity.NewStatusTransition(historyPO.UUID, historyPO.VideoUUID, from, to, historyPO.Cause, historyPO.CreatedAt)
+}
+
+// TaskTransitionToPO 上传任务状态迁移转PO，仅包含需要更新的字段
+func (c *VideoConvertor) TaskTransitionToPO(transition *entity.TaskTransition) *po.VideoUploadTaskPo {
+	if transition == nil {
+		return nil
+	}
+	return &po.VideoUploadTaskPo{
+		UUID:        transition.TaskUuid(),
+		Status:      transition.To().Value(),
+		ErrorMsg:    transition.ErrorMsg(),
+		CompletedAt: transition.CompletedAt(),
+	}
+}
+
+// CaptionEntityToPO 字幕轨道实体转PO
+func (c *VideoConvertor) CaptionEntityToPO(caption *entity.CaptionTrack) *po.VideoCaptionPo {
+	if caption == nil {
+		return nil
+	}
+	return &po.VideoCaptionPo{
+		UUID:         caption.UUID(),
+		VideoUUID:    caption.VideoUuid(),
+		Language:     caption.Language(),
+		Label:        caption.Label(),
+		IsDefault:    caption.IsDefault(),
+		SourceFormat: caption.SourceFormat().Value(),
+		StoragePath:  caption.StoragePath(),
+	}
+}
+
+// CaptionPOToEntity 字幕轨道PO转实体
+func (c *VideoConvertor) CaptionPOToEntity(captionPO *po.VideoCaptionPo) *entity.CaptionTrack {
+	if captionPO == nil {
+		return nil
+	}
+	sourceFormat, _ := vo.NewCaptionFormat(captionPO.SourceFormat)
+	return entity.NewCaptionTrack(captionPO.UUID,
+		captionPO.VideoUUID,
+		captionPO.Language,
+		captionPO.Label,
+		captionPO.IsDefault,
+		sourceFormat,
+		captionPO.StoragePath)
+}
+
+// ChapterToPO 章节值对象转PO
+func (c *VideoConvertor) ChapterToPO(videoUUID string, position int, chapter vo.Chapter) *po.VideoChapterPo {
+	return &po.VideoChapterPo{
+		VideoUUID: videoUUID,
+		Position:  position,
+		StartMs:   chapter.Start().Milliseconds(),
+		Title:     chapter.Title(),
+	}
+}
+
+// ChapterPOToVO 章节PO转值对象
+func (c *VideoConvertor) ChapterPOToVO(chapterPO *po.VideoChapterPo) vo.Chapter {
+	return vo.NewChapter(time.Duration(chapterPO.StartMs)*time.Millisecond, chapterPO.Title)
+}
+
+// POToReviewQueueItem 视频PO转审核队列条目
+func (c *VideoConvertor) POToReviewQueueItem(videoPO *po.VideoPo) *entity.ReviewQueueItem {
+	if videoPO == nil {
+		return nil
+	}
+	return entity.NewReviewQueueItem(c.POToEntity(videoPO), videoPO.ReviewClaimedBy, videoPO.ReviewClaimedAt)
+}
+
+// ReviewLogEntityToPO 审核记录实体转PO
+func (c *VideoConvertor) ReviewLogEntityToPO(reviewLog *entity.ReviewLog) *po.VideoReviewLogPo {
+	if reviewLog == nil {
+		return nil
+	}
+	return &po.VideoReviewLogPo{
+		UUID:          reviewLog.UUID(),
+		VideoUUID:     reviewLog.VideoUuid(),
+		ModeratorUUID: reviewLog.ModeratorUuid(),
+		Decision:      reviewLog.Decision().Value(),
+		Reason:        reviewLog.Reason(),
+	}
+}
+
+// ReviewLogPOToEntity 审核记录PO转实体
+func (c *VideoConvertor) ReviewLogPOToEntity(logPO *po.VideoReviewLogPo) *entity.ReviewLog {
+	if logPO == nil {
+		return nil
+	}
+	decision, _ := vo.NewReviewDecision(logPO.Decision)
+	return entity.NewReviewLog(logPO.UUID, logPO.VideoUUID, logPO.ModeratorUUID, decision, logPO.Reason, logPO.CreatedAt)
+}
+
+// SourceVersionToPO 源文件版本实体转PO
+func (c *VideoConvertor) SourceVersionToPO(version *entity.SourceVersion) *po.VideoSourceVersionPo {
+	return &po.VideoSourceVersionPo{
+		UUID:           version.UUID(),
+		VideoUUID:      version.VideoUuid(),
+		Version:        version.Version(),
+		StoragePath:    version.StoragePath(),
+		Filename:       version.Filename(),
+		FileSize:       version.FileSize(),
+		Format:         version.Format(),
+		ChecksumMD5:    version.Checksum().MD5(),
+		ChecksumSHA256: version.Checksum().SHA256(),
+		ETag:           version.ETag(),
+		SourceSHA256:   version.SourceSHA256(),
+		Status:         version.Status().Value(),
+		UploaderUUID:   version.UploaderUuid(),
+		KeepMetadata:   version.KeepMetadata(),
+		ErrorMsg:       version.ErrorMsg(),
+		ActivatedAt:    version.ActivatedAt(),
+		RetainUntil:    version.RetainUntil(),
+	}
+}
+
+// SourceVersionPOToEntity 源文件版本PO转实体
+func (c *VideoConvertor) SourceVersionPOToEntity(versionPO *po.VideoSourceVersionPo) *entity.SourceVersion {
+	if versionPO == nil {
+		return nil
+	}
+	version := entity.NewSourceVersion(versionPO.UUID,
+		versionPO.VideoUUID,
+		versionPO.Version,
+		versionPO.StoragePath,
+		versionPO.Filename,
+		versionPO.FileSize,
+		versionPO.Format,
+		vo.NewSourceVersionStatus(versionPO.Status),
+		versionPO.UploaderUUID)
+	version.SetChecksum(vo.NewChecksum(versionPO.ChecksumMD5, versionPO.ChecksumSHA256), versionPO.ETag)
+	version.SetSourceSHA256(versionPO.SourceSHA256)
+	version.SetKeepMetadata(versionPO.KeepMetadata)
+	version.SetErrorMsg(versionPO.ErrorMsg)
+	version.SetActivatedAt(versionPO.ActivatedAt)
+	version.SetRetainUntil(versionPO.RetainUntil)
+	version.SetCreatedAt(versionPO.CreatedAt)
+	return version
+}
+
+// UploadBatchToPO 批量上传实体转PO
+func (c *VideoConvertor) UploadBatchToPO(batch *entity.UploadBatch) *po.VideoUploadBatchPo {
+	return &po.VideoUploadBatchPo{
+		UUID:          batch.UUID(),
+		UserUUID:      batch.UserUuid(),
+		TotalFiles:    batch.TotalFiles(),
+		RejectedFiles: batch.RejectedFiles(),
+	}
+}
+
+// UploadBatchPOToEntity 批量上传PO转实体
+func (c *VideoConvertor) UploadBatchPOToEntity(batchPO *po.VideoUploadBatchPo) *entity.UploadBatch {
+	if batchPO == nil {
+		return nil
+	}
+	return entity.NewUploadBatch(batchPO.UUID, batchPO.UserUUID, batchPO.TotalFiles, batchPO.RejectedFiles, batchPO.CreatedAt)
+}
+
+// RenditionToPO 转码输出实体转PO
+func (c *VideoConvertor) RenditionToPO(rendition *entity.Rendition) *po.VideoRenditionPo {
+	profile := rendition.Profile()
+	return &po.VideoRenditionPo{
+		UUID:         rendition.UUID(),
+		VideoUUID:    rendition.VideoUuid(),
+		SourcePath:   rendition.SourcePath(),
+		Name:         profile.Name(),
+		MaxHeight:    profile.Height(),
+		VideoBitrate: profile.VideoBitrate(),
+		AudioBitrate: profile.AudioBitrate(),
+		Required:     profile.Required(),
+		Status:       rendition.Status().Value(),
+		Progress:     rendition.Progress(),
+		Attempts:     rendition.Attempts(),
+		StoragePath:  rendition.StoragePath(),
+		FileSize:     rendition.FileSize(),
+		Width:        rendition.Width(),
+		Height:       rendition.Height(),
+		ErrorMsg:     rendition.ErrorMsg(),
+		LeaseUntil:   rendition.LeaseUntil(),
+		StartedAt:    rendition.StartedAt(),
+		CompletedAt:  rendition.CompletedAt(),
+
+		HLSPath:          rendition.HLS().Path(),
+		Codecs:           rendition.HLS().Codecs(),
+		Bandwidth:        rendition.HLS().Bandwidth(),
+		AverageBandwidth: rendition.HLS().AverageBandwidth(),
+
+		DASHPath:            rendition.DASH().Dir(),
+		DASHSegmentDuration: rendition.DASH().SegmentDuration().Milliseconds(),
+		DASHDuration:        rendition.DASH().Duration().Milliseconds(),
+		VideoBandwidth:      rendition.DASH().VideoBandwidth(),
+		AudioBandwidth:      rendition.DASH().AudioBandwidth(),
+	}
+}
+
+// RenditionPOToEntity 转码输出PO转实体
+func (c *VideoConvertor) RenditionPOToEntity(renditionPO *po.VideoRenditionPo) *entity.Rendition {
+	if renditionPO == nil {
+		return nil
+	}
+	profile := vo.NewRenditionProfile(renditionPO.Name, renditionPO.MaxHeight, renditionPO.VideoBitrate,
+		renditionPO.AudioBitrate, renditionPO.Required)
+	rendition := entity.NewRendition(renditionPO.UUID,
+		renditionPO.VideoUUID,
+		renditionPO.SourcePath,
+		profile,
+		vo.NewRenditionStatus(renditionPO.Status),
+		renditionPO.Progress,
+		renditionPO.Attempts,
+		renditionPO.StoragePath,
+		renditionPO.FileSize,
+		renditionPO.Width,
+		renditionPO.Height,
+		renditionPO.ErrorMsg,
+		renditionPO.LeaseUntil,
+		renditionPO.StartedAt,
+		renditionPO.CompletedAt,
+		renditionPO.CreatedAt)
+	rendition.SetHLS(vo.NewHLSPlaylist(renditionPO.HLSPath, renditionPO.Bandwidth, renditionPO.AverageBandwidth, renditionPO.Codecs))
+	rendition.SetDASH(vo.NewDASHPackage(renditionPO.DASHPath,
+		time.Duration(renditionPO.DASHSegmentDuration)*time.Millisecond,
+		time.Duration(renditionPO.DASHDuration)*time.Millisecond,
+		renditionPO.VideoBandwidth, renditionPO.AudioBandwidth, renditionPO.Codecs))
+	return rendition
+}
+
+// ContentKeyToPO 内容密钥实体转PO
+func (c *VideoConvertor) ContentKeyToPO(key *entity.ContentKey) *po.VideoContentKeyPo {
+	return &po.VideoContentKeyPo{
+		UUID:        key.UUID(),
+		VideoUUID:   key.VideoUuid(),
+		Sequence:    key.Sequence(),
+		MasterKeyID: key.MasterKeyID(),
+		WrappedKey:  key.WrappedKey(),
+	}
+}
+
+// ContentKeyPOToEntity 内容密钥PO转实体
+func (c *VideoConvertor) ContentKeyPOToEntity(keyPO *po.VideoContentKeyPo) *entity.ContentKey {
+	if keyPO == nil {
+		return nil
+	}
+	return entity.NewContentKey(keyPO.UUID, keyPO.VideoUUID, keyPO.Sequence, keyPO.MasterKeyID, keyPO.WrappedKey, keyPO.CreatedAt)
+}
+
+// KeyAccessToPO 获取密钥的审计记录实体转PO
+func (c *VideoConvertor) KeyAccessToPO(access *entity.KeyAccess) *po.VideoKeyAccessLogPo {
+	return &po.VideoKeyAccessLogPo{
+		UUID:       access.UUID(),
+		VideoUUID:  access.VideoUuid(),
+		KeyUUID:    access.KeyUuid(),
+		UserUUID:   access.UserUuid(),
+		IssuerUUID: access.IssuerUuid(),
+		ClientIP:   access.ClientIP(),
+		UserAgent:  access.UserAgent(),
+		Granted:    access.Granted(),
+		Reason:     access.Reason(),
+	}
+}
+
+// ArtworkToPO 封面候选帧和缩略图实体转PO
+func (c *VideoConvertor) ArtworkToPO(artwork *entity.Artwork) *po.VideoArtworkPo {
+	candidates := make([]po.PosterCandidatePo, 0, len(artwork.Candidates()))
+	for _, candidate := range artwork.Candidates() {
+		candidates = append(candidates, po.PosterCandidatePo{
+			OffsetMs: candidate.Offset().Milliseconds(),
+			Path:     candidate.Path(),
+		})
+	}
+	candidatesJSON, _ := json.Marshal(candidates)
+	return &po.VideoArtworkPo{
+		UUID:           artwork.UUID(),
+		VideoUUID:      artwork.VideoUuid(),
+		SourcePath:     artwork.SourcePath(),
+		Dir:            artwork.Dir(),
+		Status:         artwork.Status().Value(),
+		Attempts:       artwork.Attempts(),
+		Candidates:     string(candidatesJSON),
+		StoryboardPath: artwork.StoryboardPath(),
+		ErrorMsg:       artwork.ErrorMsg(),
+		LeaseUntil:     artwork.LeaseUntil(),
+		StartedAt:      artwork.StartedAt(),
+		CompletedAt:    artwork.CompletedAt(),
+	}
+}
+
+// ArtworkPOToEntity 封面候选帧和缩略图PO转实体
+func (c *VideoConvertor) ArtworkPOToEntity(artworkPO *po.VideoArtworkPo) *entity.Artwork {
+	if artworkPO == nil {
+		return nil
+	}
+	var candidatePOs []po.PosterCandidatePo
+	if artworkPO.Candidates != "" {
+		if err := json.Unmarshal([]byte(artworkPO.Candidates), &candidatePOs); err != nil {
+			logger.Error(fmt.Sprintf("artwork %s: %v", artworkPO.UUID, err))
+		}
+	}
+	candidates := make([]vo.PosterCandidate, 0, len(candidatePOs))
+	for _, candidatePO := range candidatePOs {
+		candidates = append(candidates, vo.NewPosterCandidate(time.Duration(candidatePO.OffsetMs)*time.Millisecond, candidatePO.Path))
+	}
+	artwork := entity.NewArtwork(artworkPO.UUID, artworkPO.VideoUUID, artworkPO.SourcePath, artworkPO.Dir,
+		vo.NewRenditionStatus(artworkPO.Status), artworkPO.Attempts)
+	artwork.SetResult(candidates, artworkPO.StoryboardPath, artworkPO.ErrorMsg)
+	artwork.SetTimes(artworkPO.LeaseUntil, artworkPO.StartedAt, artworkPO.CompletedAt, artworkPO.CreatedAt)
+	return artwork
+}
+
+// FaststartJobToPO faststart 改写记录实体转PO
+func (c *VideoConvertor) FaststartJobToPO(job *entity.FaststartJob) *po.VideoFaststartPo {
+	return &po.VideoFaststartPo{
+		UUID:         job.UUID(),
+		VideoUUID:    job.VideoUuid(),
+		SourcePath:   job.SourcePath(),
+		Status:       job.Status().Value(),
+		Attempts:     job.Attempts(),
+		Outcome:      job.Outcome().Value(),
+		OriginalSize: job.OriginalSize(),
+		FileSize:     job.FileSize(),
+		ErrorMsg:     job.ErrorMsg(),
+		LeaseUntil:   job.LeaseUntil(),
+		StartedAt:    job.StartedAt(),
+		CompletedAt:  job.CompletedAt(),
+	}
+}
+
+// FaststartJobPOToEntity faststart 改写记录PO转实体
+func (c *VideoConvertor) FaststartJobPOToEntity(jobPO *po.VideoFaststartPo) *entity.FaststartJob {
+	if jobPO == nil {
+		return nil
+	}
+	job := entity.NewFaststartJob(jobPO.UUID, jobPO.VideoUUID, jobPO.SourcePath, vo.NewRenditionStatus(jobPO.Status), jobPO.Attempts)
+	job.SetResult(vo.NewFaststartOutcome(jobPO.Outcome), jobPO.OriginalSize, jobPO.FileSize, jobPO.ErrorMsg)
+	job.SetTimes(jobPO.LeaseUntil, jobPO.StartedAt, jobPO.CompletedAt, jobPO.CreatedAt)
+	return job
+}
+
+// ScanJobToPO 恶意软件扫描记录实体转PO
+func (c *VideoConvertor) ScanJobToPO(job *entity.ScanJob) *po.VideoScanPo {
+	return &po.VideoScanPo{
+		UUID:           job.UUID(),
+		VideoUUID:      job.VideoUuid(),
+		SourcePath:     job.SourcePath(),
+		Status:         job.Status().Value(),
+		Attempts:       job.Attempts(),
+		Verdict:        job.Verdict().Value(),
+		Signature:      job.Signature(),
+		QuarantinePath: job.QuarantinePath(),
+		ErrorMsg:       job.ErrorMsg(),
+		LeaseUntil:     job.LeaseUntil(),
+		StartedAt:      job.StartedAt(),
+		CompletedAt:    job.CompletedAt(),
+	}
+}
+
+// ScanJobPOToEntity 恶意软件扫描记录PO转实体
+func (c *VideoConvertor) ScanJobPOToEntity(scanPO *po.VideoScanPo) *entity.ScanJob {
+	if scanPO == nil {
+		return nil
+	}
+	job := entity.NewScanJob(scanPO.UUID, scanPO.VideoUUID, scanPO.SourcePath, vo.NewRenditionStatus(scanPO.Status), scanPO.Attempts)
+	job.SetResult(vo.NewScanVerdict(scanPO.Verdict), scanPO.Signature, scanPO.QuarantinePath, scanPO.ErrorMsg)
+	job.SetTimes(scanPO.LeaseUntil, scanPO.StartedAt, scanPO.CompletedAt, scanPO.CreatedAt)
+	return job
+}
+
+// PipelineStepToPO 流水线步骤实体转PO
+func (c *VideoConvertor) PipelineStepToPO(step *entity.PipelineStep) *po.VideoPipelineStepPo {
+	return &po.VideoPipelineStepPo{
+		UUID:         step.UUID(),
+		PipelineUUID: step.PipelineUuid(),
+		VideoUUID:    step.VideoUuid(),
+		SourcePath:   step.SourcePath(),
+		Name:         step.Name(),
+		Position:     step.Position(),
+		DependsOn:    strings.Join(step.DependsOn(), ","),
+		Optional:     step.Optional(),
+		Status:       step.Status().Value(),
+		Attempts:     step.Attempts(),
+		MaxAttempts:  step.MaxAttempts(),
+		Progress:     step.Progress(),
+		ErrorMsg:     step.ErrorMsg(),
+		NextRunAt:    step.NextRunAt(),
+		StartedAt:    step.StartedAt(),
+		CompletedAt:  step.CompletedAt(),
+	}
+}
+
+// PipelineStepPOToEntity 流水线步骤PO转实体
+func (c *VideoConvertor) PipelineStepPOToEntity(stepPO *po.VideoPipelineStepPo) *entity.PipelineStep {
+	if stepPO == nil {
+		return nil
+	}
+	var dependsOn []string
+	if stepPO.DependsOn != "" {
+		dependsOn = strings.Split(stepPO.DependsOn, ",")
+	}
+	step := entity.NewPipelineStep(stepPO.UUID, stepPO.PipelineUUID, stepPO.VideoUUID, stepPO.SourcePath, stepPO.Name,
+		stepPO.Position, dependsOn, stepPO.Optional, vo.NewPipelineStepStatus(stepPO.Status), stepPO.Attempts, stepPO.MaxAttempts)
+	step.SetResult(stepPO.Progress, stepPO.ErrorMsg)
+	step.SetTimes(stepPO.NextRunAt, stepPO.StartedAt, stepPO.CompletedAt, stepPO.CreatedAt)
+	return step
+}
+
+// LiveStreamToPO 直播实体转PO
+func (c *VideoConvertor) LiveStreamToPO(stream *entity.LiveStream) *po.VideoLiveStreamPo {
+	return &po.VideoLiveStreamPo{
+		UUID:          stream.UUID(),
+		UserUUID:      stream.UserUuid(),
+		Title:         stream.Title(),
+		Description:   stream.Description(),
+		StreamKeyHash: stream.StreamKeyHash(),
+		Status:        stream.Status().Value(),
+		StartedAt:     stream.StartedAt(),
+		LastIngestAt:  stream.LastIngestAt(),
+		EndedAt:       stream.EndedAt(),
+		VideoUUID:     stream.VideoUuid(),
+		TaskUUID:      stream.TaskUuid(),
+		VodStatus:     stream.VodStatus().Value(),
+		VodAttempts:   stream.VodAttempts(),
+		VodErrorMsg:   stream.VodErrorMsg(),
+		VodLeaseUntil: stream.VodLeaseUntil(),
+	}
+}
+
+// LiveStreamPOToEntity 直播PO转实体，没有需要转换的分片时转换状态保持为空
+func (c *VideoConvertor) LiveStreamPOToEntity(streamPO *po.VideoLiveStreamPo) *entity.LiveStream {
+	if streamPO == nil {
+		return nil
+	}
+	stream := entity.NewLiveStream(streamPO.UUID, streamPO.UserUUID, streamPO.Title, streamPO.Description,
+		streamPO.StreamKeyHash, vo.NewLiveStreamStatus(streamPO.Status))
+	var vodStatus vo.RenditionStatus
+	if streamPO.VodStatus != "" {
+		vodStatus = vo.NewRenditionStatus(streamPO.VodStatus)
+	}
+	stream.SetConversion(streamPO.VideoUUID, streamPO.TaskUUID, vodStatus, streamPO.VodAttempts, streamPO.VodErrorMsg,
+		streamPO.VodLeaseUntil)
+	stream.SetTimes(streamPO.StartedAt, streamPO.LastIngestAt, streamPO.EndedAt, streamPO.CreatedAt)
+	return stream
+}
+
+// LiveSegmentToPO 直播分片实体转PO
+func (c *VideoConvertor) LiveSegmentToPO(segment *entity.LiveSegment) *po.VideoLiveSegmentPo {
+	return &po.VideoLiveSegmentPo{
+		UUID:       segment.UUID(),
+		StreamUUID: segment.StreamUuid(),
+		Sequence:   segment.Sequence(),
+		Filename:   segment.Filename(),
+		Path:       segment.Path(),
+		DurationMs: segment.Duration().Round(time.Millisecond).Milliseconds(),
+		Size:       segment.Size(),
+	}
+}
+
+// LiveSegmentPOToEntity 直播分片PO转实体
+func (c *VideoConvertor) LiveSegmentPOToEntity(segmentPO *po.VideoLiveSegmentPo) *entity.LiveSegment {
+	if segmentPO == nil {
+		return nil
+	}
+	return entity.NewLiveSegment(segmentPO.UUID, segmentPO.StreamUUID, segmentPO.Sequence, segmentPO.Filename, segmentPO.Path,
+		time.Duration(segmentPO.DurationMs)*time.Millisecond, segmentPO.Size, segmentPO.CreatedAt)
 }
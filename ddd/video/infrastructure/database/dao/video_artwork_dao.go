@@ -0,0 +1,116 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoArtworkDao struct {
+	db *gorm.DB
+}
+
+func NewVideoArtworkDao() *VideoArtworkDao {
+	return &VideoArtworkDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+func (d *VideoArtworkDao) Create(ctx context.Context, artworkPo *po.VideoArtworkPo) error {
+	return d.db.WithContext(ctx).Create(artworkPo).Error
+}
+
+// GetLatest 获取视频最新的记录，statuses 为空时不限制状态
+func (d *VideoArtworkDao) GetLatest(ctx context.Context, videoUUID string, statuses []string) (*po.VideoArtworkPo, error) {
+	var artworkPo po.VideoArtworkPo
+	query := d.db.WithContext(ctx).Where("video_uuid = ? AND is_deleted = 0", videoUUID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	err := query.Order("id DESC").First(&artworkPo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &artworkPo, nil
+}
+
+// GetByVideoUUID 获取视频的全部记录，新建的在前
+func (d *VideoArtworkDao) GetByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoArtworkPo, error) {
+	var artworkPos []*po.VideoArtworkPo
+	err := d.db.WithContext(ctx).Order("id DESC").Find(&artworkPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworkPos, nil
+}
+
+// GetClaimable 获取可以领取的记录，先创建的先生成
+func (d *VideoArtworkDao) GetClaimable(ctx context.Context, statuses []string, now time.Time, limit int) ([]*po.VideoArtworkPo, error) {
+	var artworkPos []*po.VideoArtworkPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&artworkPos, "status IN ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0", statuses, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return artworkPos, nil
+}
+
+// Claim 以比较并交换的方式领取：状态、尝试次数和租约都与读取时一致才更新
+func (d *VideoArtworkDao) Claim(ctx context.Context, artworkPo *po.VideoArtworkPo, fromStatus string, fromAttempts int,
+	now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoArtworkPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0",
+			artworkPo.UUID, fromStatus, fromAttempts, now).
+		Updates(map[string]interface{}{
+			"status":      artworkPo.Status,
+			"attempts":    artworkPo.Attempts,
+			"error_msg":   artworkPo.ErrorMsg,
+			"lease_until": artworkPo.LeaseUntil,
+			"started_at":  artworkPo.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish 记录生成结果，只有仍由本次执行持有时才更新
+func (d *VideoArtworkDao) Finish(ctx context.Context, artworkPo *po.VideoArtworkPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoArtworkPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", artworkPo.UUID, "running", artworkPo.Attempts).
+		Updates(map[string]interface{}{
+			"status":          artworkPo.Status,
+			"candidates":      artworkPo.Candidates,
+			"storyboard_path": artworkPo.StoryboardPath,
+			"error_msg":       artworkPo.ErrorMsg,
+			"lease_until":     artworkPo.LeaseUntil,
+			"completed_at":    artworkPo.CompletedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelActive 取消视频全部等待中和生成中的记录
+func (d *VideoArtworkDao) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&po.VideoArtworkPo{}).
+		Where("video_uuid = ? AND status IN ? AND is_deleted = 0", videoUUID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":       "canceled",
+			"error_msg":    reason,
+			"lease_until":  nil,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateStatus 以比较并交换的方式更新状态
+func (d *VideoArtworkDao) UpdateStatus(ctx context.Context, uuid, fromStatus, toStatus string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoArtworkPo{}).
+		Where("uuid = ? AND status = ? AND is_deleted = 0", uuid, fromStatus).
+		Update("status", toStatus)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"gorm.io/gorm"
+)
+
+type VideoCaptionDao struct {
+	db *gorm.DB
+}
+
+func NewVideoCaptionDao() *VideoCaptionDao {
+	return &VideoCaptionDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// Create 创建字幕轨道，如果是默认字幕则在同一事务中取消该视频其他字幕的默认标记
+func (d *VideoCaptionDao) Create(ctx context.Context, captionPo *po.VideoCaptionPo) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if captionPo.IsDefault {
+			if err := tx.Model(&po.VideoCaptionPo{}).
+				Where("video_uuid = ? AND is_deleted = 0", captionPo.VideoUUID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(captionPo).Error
+	})
+}
+
+func (d *VideoCaptionDao) GetByUUID(ctx context.Context, uuid string) (*po.VideoCaptionPo, error) {
+	var captionPo po.VideoCaptionPo
+	err := d.db.WithContext(ctx).First(&captionPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &captionPo, nil
+}
+
+func (d *VideoCaptionDao) GetByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoCaptionPo, error) {
+	var captionPos []*po.VideoCaptionPo
+	err := d.db.WithContext(ctx).Order("id ASC").Find(&captionPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return captionPos, nil
+}
+
+// DeleteByUUID 软删除字幕轨道
+func (d *VideoCaptionDao) DeleteByUUID(ctx context.Context, uuid string) error {
+	return d.db.WithContext(ctx).Model(&po.VideoCaptionPo{}).
+		Where("uuid = ? AND is_deleted = 0", uuid).
+		Update("is_deleted", 1).Error
+}
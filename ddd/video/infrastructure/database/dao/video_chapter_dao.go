@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"context"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"gorm.io/gorm"
+)
+
+type VideoChapterDao struct {
+	db *gorm.DB
+}
+
+func NewVideoChapterDao() *VideoChapterDao {
+	return &VideoChapterDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// ReplaceByVideoUUID 在事务中替换视频的全部章节
+func (d *VideoChapterDao) ReplaceByVideoUUID(ctx context.Context, videoUUID string, chapterPos []*po.VideoChapterPo) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.VideoChapterPo{}).
+			Where("video_uuid = ? AND is_deleted = 0", videoUUID).
+			Update("is_deleted", 1).Error; err != nil {
+			return err
+		}
+		if len(chapterPos) == 0 {
+			return nil
+		}
+		return tx.Create(chapterPos).Error
+	})
+}
+
+func (d *VideoChapterDao) GetByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoChapterPo, error) {
+	var chapterPos []*po.VideoChapterPo
+	err := d.db.WithContext(ctx).Order("position ASC").Find(&chapterPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return chapterPos, nil
+}
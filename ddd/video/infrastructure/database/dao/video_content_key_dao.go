@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VideoContentKeyDao struct {
+	db *gorm.DB
+}
+
+func NewVideoContentKeyDao() *VideoContentKeyDao {
+	return &VideoContentKeyDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// Create 写入内容密钥，同一视频同一序号已存在时（多档并发转码）不写入并返回false
+func (d *VideoContentKeyDao) Create(ctx context.Context, keyPo *po.VideoContentKeyPo) (bool, error) {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(keyPo)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (d *VideoContentKeyDao) GetByUUID(ctx context.Context, uuid string) (*po.VideoContentKeyPo, error) {
+	var keyPo po.VideoContentKeyPo
+	err := d.db.WithContext(ctx).First(&keyPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &keyPo, nil
+}
+
+func (d *VideoContentKeyDao) GetBySequence(ctx context.Context, videoUUID string, sequence int) (*po.VideoContentKeyPo, error) {
+	var keyPo po.VideoContentKeyPo
+	err := d.db.WithContext(ctx).First(&keyPo, "video_uuid = ? AND sequence = ? AND is_deleted = 0", videoUUID, sequence).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &keyPo, nil
+}
+
+// GetNotWrappedWith 按UUID顺序分批获取不是用指定主密钥包装的内容密钥
+func (d *VideoContentKeyDao) GetNotWrappedWith(ctx context.Context, masterKeyID, afterUUID string, limit int) ([]*po.VideoContentKeyPo, error) {
+	var keyPos []*po.VideoContentKeyPo
+	err := d.db.WithContext(ctx).Order("uuid ASC").Limit(limit).
+		Find(&keyPos, "master_key_id <> ? AND uuid > ? AND is_deleted = 0", masterKeyID, afterUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return keyPos, nil
+}
+
+// UpdateWrappedKey 包装的主密钥仍为previousKeyID时才更新（比较并交换）
+func (d *VideoContentKeyDao) UpdateWrappedKey(ctx context.Context, keyPo *po.VideoContentKeyPo, previousKeyID string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoContentKeyPo{}).
+		Where("uuid = ? AND master_key_id = ? AND is_deleted = 0", keyPo.UUID, previousKeyID).
+		Updates(map[string]interface{}{
+			"master_key_id": keyPo.MasterKeyID,
+			"wrapped_key":   keyPo.WrappedKey,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateAccessLog 写入获取密钥的审计记录
+func (d *VideoContentKeyDao) CreateAccessLog(ctx context.Context, logPo *po.VideoKeyAccessLogPo) error {
+	return d.db.WithContext(ctx).Create(logPo).Error
+}
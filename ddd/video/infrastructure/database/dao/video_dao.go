@@ -5,6 +5,7 @@ import (
 	"errors"
 	"go-video/ddd/internal/resource"
 	"go-video/ddd/video/infrastructure/database/po"
+	"go-video/pkg/event"
 	"gorm.io/gorm"
 )
 
@@ -43,6 +44,23 @@ func (v *VideoDao) GetByUserUUID(ctx context.Context, userUUID string) ([]*po.Vi
 	return videoPos, nil
 }
 
+// GetByUserUUIDAndChecksum 根据上传时的源文件SHA-256查找用户的视频，不存在时返回nil；
+// 没有记录 source_sha256 的旧数据按源文件当前的SHA-256匹配
+func (v *VideoDao) GetByUserUUIDAndChecksum(ctx context.Context, userUUID, sha256 string) (*po.VideoPo, error) {
+	var videoPo po.VideoPo
+	err := v.db.WithContext(ctx).
+		Where("user_uuid = ? AND is_deleted = 0", userUUID).
+		Where("source_sha256 = ? OR (source_sha256 = '' AND checksum_sha256 = ?)", sha256, sha256).
+		Order("id").First(&videoPo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &videoPo, nil
+}
+
 // CreateVideoAndTask 通过事务插入两条记录，保证原子性
 func (d *VideoDao) CreateVideoAndTask(ctx context.Context, video *po.VideoPo, videoUploadTaskPo *po.VideoUploadTaskPo) error {
 	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -56,14 +74,300 @@ func (d *VideoDao) CreateVideoAndTask(ctx context.Context, video *po.VideoPo, vi
 	})
 }
 
-func (d *VideoDao) UpdateVideoStatus(ctx context.Context, videoUUID string, videoStatus string, videoUploadTaskUUID string, videoTaskStatus string) error {
+// CreateBatch 在一个事务中写入批量上传记录以及全部视频和上传任务
+func (d *VideoDao) CreateBatch(ctx context.Context, batchPo *po.VideoUploadBatchPo, videoPos []*po.VideoPo, taskPos []*po.VideoUploadTaskPo) error {
 	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&po.VideoPo{}).Where("uuid = ? AND is_deleted = 0 ", videoUUID).Update("status", videoStatus).Error; err != nil {
+		if err := tx.Create(batchPo).Error; err != nil {
 			return err
 		}
-		if err := tx.Model(&po.VideoUploadTaskPo{}).Where("uuid = ? AND is_deleted = 0 ", videoUploadTaskUUID).Update("status", videoTaskStatus).Error; err != nil {
+		if len(videoPos) == 0 {
+			return nil
+		}
+		if err := tx.Create(videoPos).Error; err != nil {
 			return err
 		}
-		return nil
+		return tx.Create(taskPos).Error
+	})
+}
+
+// GetBatchByUUID 获取批量上传记录
+func (d *VideoDao) GetBatchByUUID(ctx context.Context, uuid string) (*po.VideoUploadBatchPo, error) {
+	var batchPo po.VideoUploadBatchPo
+	err := d.db.WithContext(ctx).First(&batchPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &batchPo, nil
+}
+
+// GetTasksByBatchUUID 获取批量上传下的全部上传任务
+func (d *VideoDao) GetTasksByBatchUUID(ctx context.Context, batchUUID string) ([]*po.VideoUploadTaskPo, error) {
+	var taskPos []*po.VideoUploadTaskPo
+	err := d.db.WithContext(ctx).Order("id ASC").Find(&taskPos, "batch_uuid = ? AND is_deleted = 0", batchUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return taskPos, nil
+}
+
+// GetByUUIDs 批量获取视频
+func (d *VideoDao) GetByUUIDs(ctx context.Context, uuids []string) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	if len(uuids) == 0 {
+		return videoPos, nil
+	}
+	err := d.db.WithContext(ctx).Find(&videoPos, "uuid IN ? AND is_deleted = 0", uuids).Error
+	if err != nil {
+		return nil, err
+	}
+	return videoPos, nil
+}
+
+// errStaleStatus 条件更新未命中，状态已被并发修改
+var errStaleStatus = errors.New("stale status")
+
+// UpdateVideoStatus 在事务中以比较并交换的方式更新视频状态（可选同时更新上传任务状态），并写入状态迁移记录和领域事件。
+// 当前状态与预期不符时不做任何修改并返回false
+func (d *VideoDao) UpdateVideoStatus(ctx context.Context, historyPo *po.VideoStatusHistoryPo, fromStatuses []string,
+	taskPo *po.VideoUploadTaskPo, taskFromStatus string, events []*event.Event) (bool, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.VideoPo{}).
+			Where("uuid = ? AND status IN ? AND is_deleted = 0", historyPo.VideoUUID, fromStatuses).
+			Update("status", historyPo.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errStaleStatus
+		}
+		if taskPo != nil {
+			if err := d.updateTaskStatus(tx, taskPo, taskFromStatus); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(historyPo).Error; err != nil {
+			return err
+		}
+		return event.Append(tx, events...)
 	})
+	if errors.Is(err, errStaleStatus) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpdateTaskStatus 以比较并交换的方式更新上传任务状态
+func (d *VideoDao) UpdateTaskStatus(ctx context.Context, taskPo *po.VideoUploadTaskPo, taskFromStatus string) (bool, error) {
+	err := d.updateTaskStatus(d.db.WithContext(ctx), taskPo, taskFromStatus)
+	if errors.Is(err, errStaleStatus) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (d *VideoDao) updateTaskStatus(tx *gorm.DB, taskPo *po.VideoUploadTaskPo, taskFromStatus string) error {
+	result := tx.Model(&po.VideoUploadTaskPo{}).
+		Where("uuid = ? AND status = ? AND is_deleted = 0", taskPo.UUID, taskFromStatus).
+		Updates(map[string]interface{}{
+			"status":       taskPo.Status,
+			"error_msg":    taskPo.ErrorMsg,
+			"completed_at": taskPo.CompletedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return errStaleStatus
+	}
+	return nil
+}
+
+// UpdateChecksum 在事务中记录视频和上传任务（taskUUID为空时只更新视频）的校验和和ETag，视频同时记录上传时的SHA-256
+func (d *VideoDao) UpdateChecksum(ctx context.Context, videoUUID, taskUUID, md5, sha256, etag, sourceSha256 string) error {
+	values := map[string]interface{}{
+		"checksum_md5":    md5,
+		"checksum_sha256": sha256,
+		"etag":            etag,
+	}
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.VideoPo{}).Where("uuid = ? AND is_deleted = 0", videoUUID).
+			Updates(map[string]interface{}{
+				"checksum_md5":    md5,
+				"checksum_sha256": sha256,
+				"etag":            etag,
+				"source_sha256":   sourceSha256,
+			}).Error; err != nil {
+			return err
+		}
+		if taskUUID == "" {
+			return nil
+		}
+		return tx.Model(&po.VideoUploadTaskPo{}).Where("uuid = ? AND is_deleted = 0", taskUUID).Updates(values).Error
+	})
+}
+
+// UpdateTaskProgress 更新上传任务的传输进度
+func (d *VideoDao) UpdateTaskProgress(ctx context.Context, taskUUID string, received, total int64) error {
+	return d.db.WithContext(ctx).Model(&po.VideoUploadTaskPo{}).
+		Where("uuid = ? AND is_deleted = 0", taskUUID).
+		Updates(map[string]interface{}{
+			"bytes_received": received,
+			"bytes_total":    total,
+		}).Error
+}
+
+// UpdateFileSize 更新视频文件大小
+func (d *VideoDao) UpdateFileSize(ctx context.Context, videoUUID string, fileSize int64) error {
+	return d.db.WithContext(ctx).Model(&po.VideoPo{}).
+		Where("uuid = ? AND is_deleted = 0", videoUUID).
+		Update("file_size", fileSize).Error
+}
+
+// UpdateSourceContent 源文件仍为storagePath且ETag仍为previousETag时才更新大小、校验和、ETag和清除的元数据（比较并交换），
+// 同一事务中更新使用该存储路径的源文件版本，回滚时使用改写后的校验和；上传时的SHA-256（source_sha256）不变，仍用于去重
+func (d *VideoDao) UpdateSourceContent(ctx context.Context, videoUUID, storagePath, previousETag string, fileSize int64,
+	md5, sha256, etag, strippedMetadata string) (bool, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.VideoPo{}).
+			Where("uuid = ? AND storage_path = ? AND etag = ? AND is_deleted = 0", videoUUID, storagePath, previousETag).
+			Updates(map[string]interface{}{
+				"file_size":         fileSize,
+				"checksum_md5":      md5,
+				"checksum_sha256":   sha256,
+				"etag":              etag,
+				"stripped_metadata": strippedMetadata,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errStaleStatus
+		}
+		return tx.Model(&po.VideoSourceVersionPo{}).
+			Where("video_uuid = ? AND storage_path = ? AND is_deleted = 0", videoUUID, storagePath).
+			Updates(map[string]interface{}{
+				"file_size":       fileSize,
+				"checksum_md5":    md5,
+				"checksum_sha256": sha256,
+				"etag":            etag,
+			}).Error
+	})
+	if errors.Is(err, errStaleStatus) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpdatePoster 封面仍为previousPath时才更新（比较并交换）
+func (d *VideoDao) UpdatePoster(ctx context.Context, videoUUID, previousPath, posterPath string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoPo{}).
+		Where("uuid = ? AND poster_path = ? AND is_deleted = 0", videoUUID, previousPath).
+		Update("poster_path", posterPath)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Delete 在事务中软删除视频并写入领域事件，视频已被删除时不写入事件
+func (d *VideoDao) Delete(ctx context.Context, videoUUID string, events ...*event.Event) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.VideoPo{}).
+			Where("uuid = ? AND is_deleted = 0", videoUUID).
+			Update("is_deleted", 1)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return event.Append(tx, events...)
+	})
+}
+
+// GetTaskByUUID 根据UUID获取上传任务，不存在时返回nil
+func (d *VideoDao) GetTaskByUUID(ctx context.Context, uuid string) (*po.VideoUploadTaskPo, error) {
+	var taskPo po.VideoUploadTaskPo
+	err := d.db.WithContext(ctx).First(&taskPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &taskPo, nil
+}
+
+// GetStatusHistory 获取视频的状态迁移记录，按时间先后排序
+func (d *VideoDao) GetStatusHistory(ctx context.Context, videoUUID string) ([]*po.VideoStatusHistoryPo, error) {
+	var historyPos []*po.VideoStatusHistoryPo
+	err := d.db.WithContext(ctx).Order("id ASC").Find(&historyPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	return historyPos, err
+}
+
+// UpdateReview 更新审核状态，同时释放审核认领
+func (d *VideoDao) UpdateReview(ctx context.Context, videoUUID, reviewStatus, reviewReason string) error {
+	return d.db.WithContext(ctx).Model(&po.VideoPo{}).
+		Where("uuid = ? AND is_deleted = 0", videoUUID).
+		Updates(map[string]interface{}{
+			"review_status":     reviewStatus,
+			"review_reason":     reviewReason,
+			"review_claimed_by": "",
+			"review_claimed_at": nil,
+		}).Error
+}
+
+// publicQuery 公开视频：可播放且无需审核或审核通过
+func (d *VideoDao) publicQuery(ctx context.Context, readyStatuses []string) *gorm.DB {
+	return d.db.WithContext(ctx).Model(&po.VideoPo{}).
+		Where("status IN ? AND review_status IN ? AND is_deleted = 0", readyStatuses, []string{"none", "approved"})
+}
+
+// GetPublicByPage 分页获取公开视频，最新的在前
+func (d *VideoDao) GetPublicByPage(ctx context.Context, readyStatuses []string, offset, limit int) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	err := d.publicQuery(ctx, readyStatuses).Order("id DESC").Offset(offset).Limit(limit).Find(&videoPos).Error
+	return videoPos, err
+}
+
+// CountPublic 统计公开视频数量
+func (d *VideoDao) CountPublic(ctx context.Context, readyStatuses []string) (int64, error) {
+	var count int64
+	err := d.publicQuery(ctx, readyStatuses).Count(&count).Error
+	return count, err
+}
+
+// GetByUserUUIDAndPage 分页获取用户的视频，最新的在前
+func (d *VideoDao) GetByUserUUIDAndPage(ctx context.Context, userUUID string, offset, limit int) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	err := d.db.WithContext(ctx).Where("user_uuid = ? AND is_deleted = 0", userUUID).
+		Order("id DESC").Offset(offset).Limit(limit).Find(&videoPos).Error
+	return videoPos, err
+}
+
+// GetAllByUserUUID 获取用户的全部视频，按创建先后排序
+func (d *VideoDao) GetAllByUserUUID(ctx context.Context, userUUID string) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	err := d.db.WithContext(ctx).Order("id ASC").Find(&videoPos, "user_uuid = ? AND is_deleted = 0", userUUID).Error
+	return videoPos, err
+}
+
+// GetTasksByUserUUID 获取用户的全部上传任务，按创建先后排序
+func (d *VideoDao) GetTasksByUserUUID(ctx context.Context, userUUID string) ([]*po.VideoUploadTaskPo, error) {
+	var taskPos []*po.VideoUploadTaskPo
+	err := d.db.WithContext(ctx).Order("id ASC").Find(&taskPos, "user_uuid = ? AND is_deleted = 0", userUUID).Error
+	return taskPos, err
+}
+
+// CountByUserUUID 统计用户的视频数量
+func (d *VideoDao) CountByUserUUID(ctx context.Context, userUUID string) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&po.VideoPo{}).Where("user_uuid = ? AND is_deleted = 0", userUUID).Count(&count).Error
+	return count, err
+}
+
+// GetAllStorageRefs 获取全部视频的存储引用，只查询对账需要的字段
+func (d *VideoDao) GetAllStorageRefs(ctx context.Context) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	err := d.db.WithContext(ctx).
+		Select("uuid", "user_uuid", "storage_path", "status", "created_at").
+		Find(&videoPos, "is_deleted = 0").Error
+	return videoPos, err
 }
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoFaststartDao struct {
+	db *gorm.DB
+}
+
+func NewVideoFaststartDao() *VideoFaststartDao {
+	return &VideoFaststartDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+func (d *VideoFaststartDao) Create(ctx context.Context, jobPo *po.VideoFaststartPo) error {
+	return d.db.WithContext(ctx).Create(jobPo).Error
+}
+
+// GetLatest 获取视频最新的记录
+func (d *VideoFaststartDao) GetLatest(ctx context.Context, videoUUID string) (*po.VideoFaststartPo, error) {
+	var jobPo po.VideoFaststartPo
+	err := d.db.WithContext(ctx).Where("video_uuid = ? AND is_deleted = 0", videoUUID).Order("id DESC").First(&jobPo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &jobPo, nil
+}
+
+// GetClaimable 获取可以领取的记录，先创建的先处理
+func (d *VideoFaststartDao) GetClaimable(ctx context.Context, statuses []string, now time.Time, limit int) ([]*po.VideoFaststartPo, error) {
+	var jobPos []*po.VideoFaststartPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&jobPos, "status IN ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0", statuses, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobPos, nil
+}
+
+// Claim 以比较并交换的方式领取：状态、尝试次数和租约都与读取时一致才更新
+func (d *VideoFaststartDao) Claim(ctx context.Context, jobPo *po.VideoFaststartPo, fromStatus string, fromAttempts int,
+	now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoFaststartPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0",
+			jobPo.UUID, fromStatus, fromAttempts, now).
+		Updates(map[string]interface{}{
+			"status":      jobPo.Status,
+			"attempts":    jobPo.Attempts,
+			"error_msg":   jobPo.ErrorMsg,
+			"lease_until": jobPo.LeaseUntil,
+			"started_at":  jobPo.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish 记录处理结果，只有仍由本次执行持有时才更新
+func (d *VideoFaststartDao) Finish(ctx context.Context, jobPo *po.VideoFaststartPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoFaststartPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", jobPo.UUID, "running", jobPo.Attempts).
+		Updates(map[string]interface{}{
+			"status":        jobPo.Status,
+			"outcome":       jobPo.Outcome,
+			"original_size": jobPo.OriginalSize,
+			"file_size":     jobPo.FileSize,
+			"error_msg":     jobPo.ErrorMsg,
+			"lease_until":   jobPo.LeaseUntil,
+			"completed_at":  jobPo.CompletedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelActive 取消视频全部等待中和处理中的记录
+func (d *VideoFaststartDao) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&po.VideoFaststartPo{}).
+		Where("video_uuid = ? AND status IN ? AND is_deleted = 0", videoUUID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":       "canceled",
+			"error_msg":    reason,
+			"lease_until":  nil,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VideoLiveStreamDao struct {
+	db *gorm.DB
+}
+
+func NewVideoLiveStreamDao() *VideoLiveStreamDao {
+	return &VideoLiveStreamDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+func (d *VideoLiveStreamDao) Create(ctx context.Context, streamPo *po.VideoLiveStreamPo) error {
+	return d.db.WithContext(ctx).Create(streamPo).Error
+}
+
+func (d *VideoLiveStreamDao) GetByUUID(ctx context.Context, uuid string) (*po.VideoLiveStreamPo, error) {
+	return d.first(ctx, "uuid = ? AND is_deleted = 0", uuid)
+}
+
+func (d *VideoLiveStreamDao) GetByStreamKeyHash(ctx context.Context, streamKeyHash string) (*po.VideoLiveStreamPo, error) {
+	return d.first(ctx, "stream_key_hash = ? AND is_deleted = 0", streamKeyHash)
+}
+
+func (d *VideoLiveStreamDao) first(ctx context.Context, query string, args ...interface{}) (*po.VideoLiveStreamPo, error) {
+	var streamPo po.VideoLiveStreamPo
+	err := d.db.WithContext(ctx).Where(query, args...).First(&streamPo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &streamPo, nil
+}
+
+// GetByUser 获取用户创建的全部直播，新建的在前
+func (d *VideoLiveStreamDao) GetByUser(ctx context.Context, userUUID string) ([]*po.VideoLiveStreamPo, error) {
+	var streamPos []*po.VideoLiveStreamPo
+	err := d.db.WithContext(ctx).Order("id DESC").Find(&streamPos, "user_uuid = ? AND is_deleted = 0", userUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return streamPos, nil
+}
+
+// GetIdle 获取最近一次推流早于 cutoff 的直播
+func (d *VideoLiveStreamDao) GetIdle(ctx context.Context, status string, cutoff time.Time, limit int) ([]*po.VideoLiveStreamPo, error) {
+	var streamPos []*po.VideoLiveStreamPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&streamPos, "status = ? AND last_ingest_at <= ? AND is_deleted = 0", status, cutoff).Error
+	if err != nil {
+		return nil, err
+	}
+	return streamPos, nil
+}
+
+// UpdateStatus 以比较并交换的方式更新直播状态
+func (d *VideoLiveStreamDao) UpdateStatus(ctx context.Context, streamPo *po.VideoLiveStreamPo, fromStatus string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoLiveStreamPo{}).
+		Where("uuid = ? AND status = ? AND is_deleted = 0", streamPo.UUID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":         streamPo.Status,
+			"started_at":     streamPo.StartedAt,
+			"last_ingest_at": streamPo.LastIngestAt,
+			"ended_at":       streamPo.EndedAt,
+			"vod_status":     streamPo.VodStatus,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// TouchIngest 更新最近一次推流的时间，时间只前进不后退
+func (d *VideoLiveStreamDao) TouchIngest(ctx context.Context, uuid string, at time.Time) error {
+	return d.db.WithContext(ctx).Model(&po.VideoLiveStreamPo{}).
+		Where("uuid = ? AND (last_ingest_at IS NULL OR last_ingest_at < ?) AND is_deleted = 0", uuid, at).
+		Update("last_ingest_at", at).Error
+}
+
+// GetConvertible 获取可以领取转换的直播，先结束的先转换
+func (d *VideoLiveStreamDao) GetConvertible(ctx context.Context, statuses []string, now time.Time, limit int) ([]*po.VideoLiveStreamPo, error) {
+	var streamPos []*po.VideoLiveStreamPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&streamPos, "vod_status IN ? AND (vod_lease_until IS NULL OR vod_lease_until <= ?) AND is_deleted = 0",
+			statuses, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return streamPos, nil
+}
+
+// ClaimConversion 以比较并交换的方式领取转换：转换状态、尝试次数和租约都与读取时一致才更新
+func (d *VideoLiveStreamDao) ClaimConversion(ctx context.Context, streamPo *po.VideoLiveStreamPo, fromStatus string,
+	fromAttempts int, now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoLiveStreamPo{}).
+		Where("uuid = ? AND vod_status = ? AND vod_attempts = ? AND (vod_lease_until IS NULL OR vod_lease_until <= ?) AND is_deleted = 0",
+			streamPo.UUID, fromStatus, fromAttempts, now).
+		Updates(map[string]interface{}{
+			"vod_status":      streamPo.VodStatus,
+			"vod_attempts":    streamPo.VodAttempts,
+			"vod_error_msg":   streamPo.VodErrorMsg,
+			"vod_lease_until": streamPo.VodLeaseUntil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveConversion 保存转换的进展和结果，只有仍由本次执行持有时才更新
+func (d *VideoLiveStreamDao) SaveConversion(ctx context.Context, streamPo *po.VideoLiveStreamPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoLiveStreamPo{}).
+		Where("uuid = ? AND vod_status = ? AND vod_attempts = ? AND is_deleted = 0", streamPo.UUID, "running", streamPo.VodAttempts).
+		Updates(map[string]interface{}{
+			"video_uuid":      streamPo.VideoUUID,
+			"task_uuid":       streamPo.TaskUUID,
+			"vod_status":      streamPo.VodStatus,
+			"vod_error_msg":   streamPo.VodErrorMsg,
+			"vod_lease_until": streamPo.VodLeaseUntil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateSegment 登记分片，同一直播同一序列号已存在时（并发推流）不写入并返回false
+func (d *VideoLiveStreamDao) CreateSegment(ctx context.Context, segmentPo *po.VideoLiveSegmentPo) (bool, error) {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(segmentPo)
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetLastSegments 获取直播最后的 limit 个分片，按序列号降序
+func (d *VideoLiveStreamDao) GetLastSegments(ctx context.Context, streamUUID string, limit int) ([]*po.VideoLiveSegmentPo, error) {
+	var segmentPos []*po.VideoLiveSegmentPo
+	err := d.db.WithContext(ctx).Order("sequence DESC").Limit(limit).
+		Find(&segmentPos, "stream_uuid = ? AND is_deleted = 0", streamUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return segmentPos, nil
+}
+
+// GetSegments 获取直播的全部分片，按序列号升序
+func (d *VideoLiveStreamDao) GetSegments(ctx context.Context, streamUUID string) ([]*po.VideoLiveSegmentPo, error) {
+	var segmentPos []*po.VideoLiveSegmentPo
+	err := d.db.WithContext(ctx).Order("sequence ASC").Find(&segmentPos, "stream_uuid = ? AND is_deleted = 0", streamUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return segmentPos, nil
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoPipelineStepDao struct {
+	db *gorm.DB
+}
+
+func NewVideoPipelineStepDao() *VideoPipelineStepDao {
+	return &VideoPipelineStepDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// CreateBatch 在事务中保存流水线的全部步骤
+func (d *VideoPipelineStepDao) CreateBatch(ctx context.Context, stepPos []*po.VideoPipelineStepPo) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(stepPos).Error
+	})
+}
+
+// GetLatest 获取视频最新的流水线的全部步骤，按位置排列
+func (d *VideoPipelineStepDao) GetLatest(ctx context.Context, videoUUID string) ([]*po.VideoPipelineStepPo, error) {
+	var latest po.VideoPipelineStepPo
+	result := d.db.WithContext(ctx).Where("video_uuid = ? AND is_deleted = 0", videoUUID).Order("id DESC").Limit(1).Find(&latest)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	var stepPos []*po.VideoPipelineStepPo
+	err := d.db.WithContext(ctx).Order("position ASC").
+		Find(&stepPos, "pipeline_uuid = ? AND is_deleted = 0", latest.PipelineUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return stepPos, nil
+}
+
+// GetDueVideoUUIDs 获取有执行中步骤或已到执行时间的等待中步骤的视频，等待最久的先处理
+func (d *VideoPipelineStepDao) GetDueVideoUUIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var videoUUIDs []string
+	err := d.db.WithContext(ctx).Model(&po.VideoPipelineStepPo{}).
+		Where("(status = ? OR (status = ? AND (next_run_at IS NULL OR next_run_at <= ?))) AND is_deleted = 0",
+			"running", "pending", now).
+		Group("video_uuid").Order("MIN(id) ASC").Limit(limit).
+		Pluck("video_uuid", &videoUUIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return videoUUIDs, nil
+}
+
+// Update 以比较并交换的方式更新步骤：状态和执行次数都与读取时一致才更新
+func (d *VideoPipelineStepDao) Update(ctx context.Context, stepPo *po.VideoPipelineStepPo, fromStatus string,
+	fromAttempts int) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoPipelineStepPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", stepPo.UUID, fromStatus, fromAttempts).
+		Updates(map[string]interface{}{
+			"status":       stepPo.Status,
+			"attempts":     stepPo.Attempts,
+			"progress":     stepPo.Progress,
+			"error_msg":    stepPo.ErrorMsg,
+			"next_run_at":  stepPo.NextRunAt,
+			"started_at":   stepPo.StartedAt,
+			"completed_at": stepPo.CompletedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelActive 取消视频全部等待中和执行中的步骤
+func (d *VideoPipelineStepDao) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&po.VideoPipelineStepPo{}).
+		Where("video_uuid = ? AND status IN ? AND is_deleted = 0", videoUUID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":       "canceled",
+			"error_msg":    reason,
+			"next_run_at":  nil,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
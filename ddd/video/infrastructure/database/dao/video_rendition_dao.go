@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoRenditionDao struct {
+	db *gorm.DB
+}
+
+func NewVideoRenditionDao() *VideoRenditionDao {
+	return &VideoRenditionDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// CreateBatch 批量创建转码输出
+func (d *VideoRenditionDao) CreateBatch(ctx context.Context, renditionPos []*po.VideoRenditionPo) error {
+	if len(renditionPos) == 0 {
+		return nil
+	}
+	return d.db.WithContext(ctx).Create(&renditionPos).Error
+}
+
+func (d *VideoRenditionDao) GetByUUID(ctx context.Context, uuid string) (*po.VideoRenditionPo, error) {
+	var renditionPo po.VideoRenditionPo
+	err := d.db.WithContext(ctx).First(&renditionPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &renditionPo, nil
+}
+
+// GetByVideoUUID 获取视频的全部转码输出，新建的在前
+func (d *VideoRenditionDao) GetByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoRenditionPo, error) {
+	var renditionPos []*po.VideoRenditionPo
+	err := d.db.WithContext(ctx).Order("id DESC").Find(&renditionPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return renditionPos, nil
+}
+
+// GetClaimable 获取可以领取的转码输出，先创建的先转码
+func (d *VideoRenditionDao) GetClaimable(ctx context.Context, statuses []string, now time.Time, limit int) ([]*po.VideoRenditionPo, error) {
+	var renditionPos []*po.VideoRenditionPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&renditionPos, "status IN ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0", statuses, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return renditionPos, nil
+}
+
+// Claim 以比较并交换的方式领取：状态、尝试次数和租约都与读取时一致才更新
+func (d *VideoRenditionDao) Claim(ctx context.Context, renditionPo *po.VideoRenditionPo, fromStatus string, fromAttempts int,
+	now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoRenditionPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0",
+			renditionPo.UUID, fromStatus, fromAttempts, now).
+		Updates(map[string]interface{}{
+			"status":      renditionPo.Status,
+			"attempts":    renditionPo.Attempts,
+			"progress":    renditionPo.Progress,
+			"error_msg":   renditionPo.ErrorMsg,
+			"lease_until": renditionPo.LeaseUntil,
+			"started_at":  renditionPo.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateProgress 更新转码进度和租约，只有仍由本次执行持有时才更新
+func (d *VideoRenditionDao) UpdateProgress(ctx context.Context, renditionPo *po.VideoRenditionPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoRenditionPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", renditionPo.UUID, "running", renditionPo.Attempts).
+		Updates(map[string]interface{}{
+			"progress":    renditionPo.Progress,
+			"lease_until": renditionPo.LeaseUntil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish 记录转码结果，只有仍由本次执行持有时才更新
+func (d *VideoRenditionDao) Finish(ctx context.Context, renditionPo *po.VideoRenditionPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoRenditionPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", renditionPo.UUID, "running", renditionPo.Attempts).
+		Updates(map[string]interface{}{
+			"status":       renditionPo.Status,
+			"progress":     renditionPo.Progress,
+			"file_size":    renditionPo.FileSize,
+			"width":        renditionPo.Width,
+			"height":       renditionPo.Height,
+			"error_msg":    renditionPo.ErrorMsg,
+			"lease_until":  renditionPo.LeaseUntil,
+			"completed_at": renditionPo.CompletedAt,
+
+			"hls_path":          renditionPo.HLSPath,
+			"codecs":            renditionPo.Codecs,
+			"bandwidth":         renditionPo.Bandwidth,
+			"average_bandwidth": renditionPo.AverageBandwidth,
+
+			"dash_path":             renditionPo.DASHPath,
+			"dash_segment_duration": renditionPo.DASHSegmentDuration,
+			"dash_duration":         renditionPo.DASHDuration,
+			"video_bandwidth":       renditionPo.VideoBandwidth,
+			"audio_bandwidth":       renditionPo.AudioBandwidth,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelActive 取消视频全部等待中和转码中的输出
+func (d *VideoRenditionDao) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&po.VideoRenditionPo{}).
+		Where("video_uuid = ? AND status IN ? AND is_deleted = 0", videoUUID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":       "canceled",
+			"error_msg":    reason,
+			"lease_until":  nil,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateStatus 以比较并交换的方式更新状态
+func (d *VideoRenditionDao) UpdateStatus(ctx context.Context, uuid, fromStatus, toStatus string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoRenditionPo{}).
+		Where("uuid = ? AND status = ? AND is_deleted = 0", uuid, fromStatus).
+		Update("status", toStatus)
+	return result.RowsAffected == 1, result.Error
+}
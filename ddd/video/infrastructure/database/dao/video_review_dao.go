@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"context"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoReviewDao struct {
+	db *gorm.DB
+}
+
+func NewVideoReviewDao() *VideoReviewDao {
+	return &VideoReviewDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// pendingQuery 审核队列：可播放且待审核的视频
+func (d *VideoReviewDao) pendingQuery(ctx context.Context, readyStatuses []string) *gorm.DB {
+	return d.db.WithContext(ctx).Model(&po.VideoPo{}).
+		Where("review_status = ? AND status IN ? AND is_deleted = 0", "pending_review", readyStatuses)
+}
+
+// GetPendingByPage 分页获取审核队列，按上传时间先进先出
+func (d *VideoReviewDao) GetPendingByPage(ctx context.Context, readyStatuses []string, offset, limit int) ([]*po.VideoPo, error) {
+	var videoPos []*po.VideoPo
+	err := d.pendingQuery(ctx, readyStatuses).Order("id ASC").Offset(offset).Limit(limit).Find(&videoPos).Error
+	return videoPos, err
+}
+
+// CountPending 统计审核队列长度
+func (d *VideoReviewDao) CountPending(ctx context.Context, readyStatuses []string) (int64, error) {
+	var count int64
+	err := d.pendingQuery(ctx, readyStatuses).Count(&count).Error
+	return count, err
+}
+
+// Claim 认领审核任务：仅当未被认领、认领已过期或本人已认领时成功，通过条件更新保证并发安全
+func (d *VideoReviewDao) Claim(ctx context.Context, videoUUID string, readyStatuses []string, moderatorUUID string, now, staleBefore time.Time) (bool, error) {
+	result := d.pendingQuery(ctx, readyStatuses).
+		Where("uuid = ?", videoUUID).
+		Where("(review_claimed_by = '' OR review_claimed_by IS NULL OR review_claimed_by = ? OR review_claimed_at < ?)", moderatorUUID, staleBefore).
+		Updates(map[string]interface{}{
+			"review_claimed_by": moderatorUUID,
+			"review_claimed_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Decide 在事务中写入审核结论和审核记录，要求审核员持有未过期的认领
+func (d *VideoReviewDao) Decide(ctx context.Context, logPo *po.VideoReviewLogPo, readyStatuses []string, reviewStatus string, staleBefore time.Time) (bool, error) {
+	decided := false
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.VideoPo{}).
+			Where("uuid = ? AND review_status = ? AND status IN ? AND is_deleted = 0", logPo.VideoUUID, "pending_review", readyStatuses).
+			Where("review_claimed_by = ? AND review_claimed_at >= ?", logPo.ModeratorUUID, staleBefore).
+			Updates(map[string]interface{}{
+				"review_status":     reviewStatus,
+				"review_reason":     logPo.Reason,
+				"review_claimed_by": "",
+				"review_claimed_at": nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		if err := tx.Create(logPo).Error; err != nil {
+			return err
+		}
+		decided = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return decided, nil
+}
+
+// GetLogsByVideoUUID 获取视频的审核记录，最新的在前
+func (d *VideoReviewDao) GetLogsByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoReviewLogPo, error) {
+	var logPos []*po.VideoReviewLogPo
+	err := d.db.WithContext(ctx).Order("id DESC").Find(&logPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return logPos, nil
+}
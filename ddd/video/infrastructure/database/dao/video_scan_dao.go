@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VideoScanDao struct {
+	db *gorm.DB
+}
+
+func NewVideoScanDao() *VideoScanDao {
+	return &VideoScanDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+func (d *VideoScanDao) Create(ctx context.Context, scanPo *po.VideoScanPo) error {
+	return d.db.WithContext(ctx).Create(scanPo).Error
+}
+
+// GetLatest 获取视频最新的记录
+func (d *VideoScanDao) GetLatest(ctx context.Context, videoUUID string) (*po.VideoScanPo, error) {
+	var scanPo po.VideoScanPo
+	err := d.db.WithContext(ctx).Where("video_uuid = ? AND is_deleted = 0", videoUUID).Order("id DESC").First(&scanPo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scanPo, nil
+}
+
+// GetClaimable 获取可以领取的记录，先创建的先扫描
+func (d *VideoScanDao) GetClaimable(ctx context.Context, statuses []string, now time.Time, limit int) ([]*po.VideoScanPo, error) {
+	var scanPos []*po.VideoScanPo
+	err := d.db.WithContext(ctx).Order("id ASC").Limit(limit).
+		Find(&scanPos, "status IN ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0", statuses, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return scanPos, nil
+}
+
+// Claim 以比较并交换的方式领取：状态、尝试次数和租约都与读取时一致才更新
+func (d *VideoScanDao) Claim(ctx context.Context, scanPo *po.VideoScanPo, fromStatus string, fromAttempts int,
+	now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoScanPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND (lease_until IS NULL OR lease_until <= ?) AND is_deleted = 0",
+			scanPo.UUID, fromStatus, fromAttempts, now).
+		Updates(map[string]interface{}{
+			"status":      scanPo.Status,
+			"attempts":    scanPo.Attempts,
+			"error_msg":   scanPo.ErrorMsg,
+			"lease_until": scanPo.LeaseUntil,
+			"started_at":  scanPo.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish 记录扫描结果，只有仍由本次执行持有时才更新
+func (d *VideoScanDao) Finish(ctx context.Context, scanPo *po.VideoScanPo) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoScanPo{}).
+		Where("uuid = ? AND status = ? AND attempts = ? AND is_deleted = 0", scanPo.UUID, "running", scanPo.Attempts).
+		Updates(map[string]interface{}{
+			"status":          scanPo.Status,
+			"verdict":         scanPo.Verdict,
+			"signature":       scanPo.Signature,
+			"quarantine_path": scanPo.QuarantinePath,
+			"error_msg":       scanPo.ErrorMsg,
+			"lease_until":     scanPo.LeaseUntil,
+			"completed_at":    scanPo.CompletedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelActive 取消视频全部等待中和处理中的记录
+func (d *VideoScanDao) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&po.VideoScanPo{}).
+		Where("video_uuid = ? AND status IN ? AND is_deleted = 0", videoUUID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":       "canceled",
+			"error_msg":    reason,
+			"lease_until":  nil,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
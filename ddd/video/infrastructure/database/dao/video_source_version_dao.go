@@ -0,0 +1,184 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VideoSourceVersionDao struct {
+	db *gorm.DB
+}
+
+func NewVideoSourceVersionDao() *VideoSourceVersionDao {
+	return &VideoSourceVersionDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// CreateNext 在事务中锁定视频记录并分配下一个版本号。
+// 视频还没有任何版本时先写入initialPo（当前源文件补录为第1个版本）
+func (d *VideoSourceVersionDao) CreateNext(ctx context.Context, initialPo, versionPo *po.VideoSourceVersionPo) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var videoPo po.VideoPo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&videoPo, "uuid = ? AND is_deleted = 0", versionPo.VideoUUID).Error; err != nil {
+			return err
+		}
+		var maxVersion int
+		if err := tx.Model(&po.VideoSourceVersionPo{}).
+			Where("video_uuid = ? AND is_deleted = 0", versionPo.VideoUUID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+		if maxVersion == 0 && initialPo != nil {
+			if err := tx.Create(initialPo).Error; err != nil {
+				return err
+			}
+			maxVersion = initialPo.Version
+		}
+		versionPo.Version = maxVersion + 1
+		return tx.Create(versionPo).Error
+	})
+}
+
+// Activate 在事务中把视频切换到目标版本：视频的存储路径仍为previousPath时才更新（比较并交换），
+// 是否保留元数据随版本切换，清除的元数据等新的源文件改写后重新记录；
+// 原生效版本标记为superseded并设置保留截止时间，目标版本从fromStatus变为active。
+// 视频或目标版本已被并发修改时返回false
+func (d *VideoSourceVersionDao) Activate(ctx context.Context, versionPo *po.VideoSourceVersionPo, previousPath, fromStatus string,
+	retainUntil time.Time) (bool, error) {
+	now := time.Now()
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.VideoPo{}).
+			Where("uuid = ? AND storage_path = ? AND is_deleted = 0", versionPo.VideoUUID, previousPath).
+			Updates(map[string]interface{}{
+				"storage_path":      versionPo.StoragePath,
+				"filename":          versionPo.Filename,
+				"file_size":         versionPo.FileSize,
+				"format":            versionPo.Format,
+				"checksum_md5":      versionPo.ChecksumMD5,
+				"checksum_sha256":   versionPo.ChecksumSHA256,
+				"etag":              versionPo.ETag,
+				"source_sha256":     versionPo.SourceSHA256,
+				"keep_metadata":     versionPo.KeepMetadata,
+				"stripped_metadata": "",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errStaleStatus
+		}
+		if err := tx.Model(&po.VideoSourceVersionPo{}).
+			Where("video_uuid = ? AND status = ? AND is_deleted = 0", versionPo.VideoUUID, "active").
+			Updates(map[string]interface{}{
+				"status":       "superseded",
+				"retain_until": retainUntil,
+			}).Error; err != nil {
+			return err
+		}
+		result = tx.Model(&po.VideoSourceVersionPo{}).
+			Where("uuid = ? AND status = ? AND is_deleted = 0", versionPo.UUID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":          "active",
+				"checksum_md5":    versionPo.ChecksumMD5,
+				"checksum_sha256": versionPo.ChecksumSHA256,
+				"etag":            versionPo.ETag,
+				"source_sha256":   versionPo.SourceSHA256,
+				"activated_at":    now,
+				"retain_until":    nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errStaleStatus
+		}
+		return nil
+	})
+	if errors.Is(err, errStaleStatus) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MarkFailed 上传中的版本标记为失败
+func (d *VideoSourceVersionDao) MarkFailed(ctx context.Context, uuid, errorMsg string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoSourceVersionPo{}).
+		Where("uuid = ? AND status = ? AND is_deleted = 0", uuid, "uploading").
+		Updates(map[string]interface{}{
+			"status":    "failed",
+			"error_msg": errorMsg,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkPurged 超过保留期的版本标记为已清理
+func (d *VideoSourceVersionDao) MarkPurged(ctx context.Context, uuid string, now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.VideoSourceVersionPo{}).
+		Where("uuid = ? AND status = ? AND retain_until <= ? AND is_deleted = 0", uuid, "superseded", now).
+		Update("status", "purged")
+	return result.RowsAffected == 1, result.Error
+}
+
+func (d *VideoSourceVersionDao) GetByUUID(ctx context.Context, uuid string) (*po.VideoSourceVersionPo, error) {
+	var versionPo po.VideoSourceVersionPo
+	err := d.db.WithContext(ctx).First(&versionPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &versionPo, nil
+}
+
+func (d *VideoSourceVersionDao) GetByVersion(ctx context.Context, videoUUID string, version int) (*po.VideoSourceVersionPo, error) {
+	var versionPo po.VideoSourceVersionPo
+	err := d.db.WithContext(ctx).First(&versionPo, "video_uuid = ? AND version = ? AND is_deleted = 0", videoUUID, version).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &versionPo, nil
+}
+
+// GetByVideoUUID 获取视频的全部版本，新版本在前
+func (d *VideoSourceVersionDao) GetByVideoUUID(ctx context.Context, videoUUID string) ([]*po.VideoSourceVersionPo, error) {
+	var versionPos []*po.VideoSourceVersionPo
+	err := d.db.WithContext(ctx).Order("version DESC").Find(&versionPos, "video_uuid = ? AND is_deleted = 0", videoUUID).Error
+	if err != nil {
+		return nil, err
+	}
+	return versionPos, nil
+}
+
+// GetExpired 获取保留期已过的被替换版本
+func (d *VideoSourceVersionDao) GetExpired(ctx context.Context, now time.Time, limit int) ([]*po.VideoSourceVersionPo, error) {
+	var versionPos []*po.VideoSourceVersionPo
+	err := d.db.WithContext(ctx).Order("retain_until ASC").Limit(limit).
+		Find(&versionPos, "status = ? AND retain_until <= ? AND is_deleted = 0", "superseded", now).Error
+	if err != nil {
+		return nil, err
+	}
+	return versionPos, nil
+}
+
+// GetStoragePathsByStatus 获取指定状态版本的存储路径
+func (d *VideoSourceVersionDao) GetStoragePathsByStatus(ctx context.Context, statuses []string) ([]string, error) {
+	var paths []string
+	err := d.db.WithContext(ctx).Model(&po.VideoSourceVersionPo{}).
+		Where("status IN ? AND is_deleted = 0", statuses).
+		Pluck("storage_path", &paths).Error
+	return paths, err
+}
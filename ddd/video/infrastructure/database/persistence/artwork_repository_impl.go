@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+)
+
+// artworkRepositoryImpl 封面候选帧和缩略图仓储实现
+type artworkRepositoryImpl struct {
+	artworkDao     *dao.VideoArtworkDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewArtworkRepository 创建封面候选帧和缩略图仓储实例（支持依赖注入）
+func NewArtworkRepository() repo.ArtworkRepository {
+	return &artworkRepositoryImpl{
+		artworkDao:     dao.NewVideoArtworkDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存等待生成的记录
+func (r *artworkRepositoryImpl) Create(ctx context.Context, artwork *entity.Artwork) error {
+	return r.artworkDao.Create(ctx, r.videoConvertor.ArtworkToPO(artwork))
+}
+
+// FindLatest 查找视频最新的记录
+func (r *artworkRepositoryImpl) FindLatest(ctx context.Context, videoUUID string) (*entity.Artwork, error) {
+	artworkPO, err := r.artworkDao.GetLatest(ctx, videoUUID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.ArtworkPOToEntity(artworkPO), nil
+}
+
+// FindLatestSucceeded 查找视频最新的已完成记录
+func (r *artworkRepositoryImpl) FindLatestSucceeded(ctx context.Context, videoUUID string) (*entity.Artwork, error) {
+	artworkPO, err := r.artworkDao.GetLatest(ctx, videoUUID, []string{vo.RenditionStatusSucceeded.Value()})
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.ArtworkPOToEntity(artworkPO), nil
+}
+
+// FindByVideoUUID 查找视频的全部记录
+func (r *artworkRepositoryImpl) FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.Artwork, error) {
+	artworkPOs, err := r.artworkDao.GetByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(artworkPOs), nil
+}
+
+// FindClaimable 查找可以领取的记录
+func (r *artworkRepositoryImpl) FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.Artwork, error) {
+	statuses := []string{vo.RenditionStatusPending.Value(), vo.RenditionStatusRunning.Value()}
+	artworkPOs, err := r.artworkDao.GetClaimable(ctx, statuses, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(artworkPOs), nil
+}
+
+// Claim 领取成功后实体进入生成中；领取失败时实体保持不变
+func (r *artworkRepositoryImpl) Claim(ctx context.Context, artwork *entity.Artwork, leaseUntil time.Time) (bool, error) {
+	fromStatus, fromAttempts := artwork.Status().Value(), artwork.Attempts()
+	claimed := *artwork
+	claimed.Start(leaseUntil)
+	ok, err := r.artworkDao.Claim(ctx, r.videoConvertor.ArtworkToPO(&claimed), fromStatus, fromAttempts, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	*artwork = claimed
+	return true, nil
+}
+
+// Finish 记录一次生成的结果
+func (r *artworkRepositoryImpl) Finish(ctx context.Context, artwork *entity.Artwork) (bool, error) {
+	return r.artworkDao.Finish(ctx, r.videoConvertor.ArtworkToPO(artwork))
+}
+
+// MarkSuperseded 已完成的记录标记为被替换
+func (r *artworkRepositoryImpl) MarkSuperseded(ctx context.Context, artworkUUID string) error {
+	_, err := r.artworkDao.UpdateStatus(ctx, artworkUUID, vo.RenditionStatusSucceeded.Value(), vo.RenditionStatusSuperseded.Value())
+	return err
+}
+
+// CancelActive 取消视频全部未完成的记录
+func (r *artworkRepositoryImpl) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	return r.artworkDao.CancelActive(ctx, videoUUID, reason)
+}
+
+func (r *artworkRepositoryImpl) toEntities(artworkPOs []*po.VideoArtworkPo) []*entity.Artwork {
+	artworks := make([]*entity.Artwork, 0, len(artworkPOs))
+	for _, artworkPO := range artworkPOs {
+		artworks = append(artworks, r.videoConvertor.ArtworkPOToEntity(artworkPO))
+	}
+	return artworks
+}
@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+)
+
+// captionRepositoryImpl 字幕轨道仓储实现
+type captionRepositoryImpl struct {
+	captionDao     *dao.VideoCaptionDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewCaptionRepository 创建字幕轨道仓储实例（支持依赖注入）
+func NewCaptionRepository() repo.CaptionRepository {
+	return &captionRepositoryImpl{
+		captionDao:     dao.NewVideoCaptionDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Save 保存字幕轨道
+func (r *captionRepositoryImpl) Save(ctx context.Context, caption *entity.CaptionTrack) error {
+	return r.captionDao.Create(ctx, r.videoConvertor.CaptionEntityToPO(caption))
+}
+
+// FindByUUID 根据UUID查找字幕轨道
+func (r *captionRepositoryImpl) FindByUUID(ctx context.Context, captionUUID string) (*entity.CaptionTrack, error) {
+	captionPO, err := r.captionDao.GetByUUID(ctx, captionUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.CaptionPOToEntity(captionPO), nil
+}
+
+// FindByVideoUUID 查找视频的全部字幕轨道
+func (r *captionRepositoryImpl) FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.CaptionTrack, error) {
+	captionPOs, err := r.captionDao.GetByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	captions := make([]*entity.CaptionTrack, 0, len(captionPOs))
+	for _, captionPO := range captionPOs {
+		captions = append(captions, r.videoConvertor.CaptionPOToEntity(captionPO))
+	}
+	return captions, nil
+}
+
+// Delete 删除字幕轨道
+func (r *captionRepositoryImpl) Delete(ctx context.Context, captionUUID string) error {
+	return r.captionDao.DeleteByUUID(ctx, captionUUID)
+}
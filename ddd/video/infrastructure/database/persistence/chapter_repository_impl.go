@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+)
+
+// chapterRepositoryImpl 视频章节仓储实现
+type chapterRepositoryImpl struct {
+	chapterDao     *dao.VideoChapterDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewChapterRepository 创建视频章节仓储实例（支持依赖注入）
+func NewChapterRepository() repo.ChapterRepository {
+	return &chapterRepositoryImpl{
+		chapterDao:     dao.NewVideoChapterDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Replace 替换视频的显式章节
+func (r *chapterRepositoryImpl) Replace(ctx context.Context, videoUUID string, chapters []vo.Chapter) error {
+	chapterPos := make([]*po.VideoChapterPo, 0, len(chapters))
+	for i, chapter := range chapters {
+		chapterPos = append(chapterPos, r.videoConvertor.ChapterToPO(videoUUID, i, chapter))
+	}
+	return r.chapterDao.ReplaceByVideoUUID(ctx, videoUUID, chapterPos)
+}
+
+// FindByVideoUUID 查找视频的显式章节
+func (r *chapterRepositoryImpl) FindByVideoUUID(ctx context.Context, videoUUID string) ([]vo.Chapter, error) {
+	chapterPos, err := r.chapterDao.GetByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	chapters := make([]vo.Chapter, 0, len(chapterPos))
+	for _, chapterPo := range chapterPos {
+		chapters = append(chapters, r.videoConvertor.ChapterPOToVO(chapterPo))
+	}
+	return chapters, nil
+}
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+)
+
+// contentKeyRepositoryImpl HLS内容密钥仓储实现
+type contentKeyRepositoryImpl struct {
+	contentKeyDao  *dao.VideoContentKeyDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewContentKeyRepository 创建内容密钥仓储实例（支持依赖注入）
+func NewContentKeyRepository() repo.ContentKeyRepository {
+	return &contentKeyRepositoryImpl{
+		contentKeyDao:  dao.NewVideoContentKeyDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存内容密钥
+func (r *contentKeyRepositoryImpl) Create(ctx context.Context, key *entity.ContentKey) (bool, error) {
+	return r.contentKeyDao.Create(ctx, r.videoConvertor.ContentKeyToPO(key))
+}
+
+// FindByUUID 根据UUID查找内容密钥
+func (r *contentKeyRepositoryImpl) FindByUUID(ctx context.Context, keyUUID string) (*entity.ContentKey, error) {
+	keyPO, err := r.contentKeyDao.GetByUUID(ctx, keyUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.ContentKeyPOToEntity(keyPO), nil
+}
+
+// FindBySequence 查找视频指定序号的内容密钥
+func (r *contentKeyRepositoryImpl) FindBySequence(ctx context.Context, videoUUID string, sequence int) (*entity.ContentKey, error) {
+	keyPO, err := r.contentKeyDao.GetBySequence(ctx, videoUUID, sequence)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.ContentKeyPOToEntity(keyPO), nil
+}
+
+// FindNotWrappedWith 查找需要重新包装的内容密钥
+func (r *contentKeyRepositoryImpl) FindNotWrappedWith(ctx context.Context, masterKeyID, afterUUID string, limit int) ([]*entity.ContentKey, error) {
+	keyPOs, err := r.contentKeyDao.GetNotWrappedWith(ctx, masterKeyID, afterUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*entity.ContentKey, 0, len(keyPOs))
+	for _, keyPO := range keyPOs {
+		keys = append(keys, r.videoConvertor.ContentKeyPOToEntity(keyPO))
+	}
+	return keys, nil
+}
+
+// UpdateWrappedKey 保存重新包装的密钥
+func (r *contentKeyRepositoryImpl) UpdateWrappedKey(ctx context.Context, key *entity.ContentKey, previousKeyID string) (bool, error) {
+	return r.contentKeyDao.UpdateWrappedKey(ctx, r.videoConvertor.ContentKeyToPO(key), previousKeyID)
+}
+
+// SaveAccess 保存获取密钥的审计记录
+func (r *contentKeyRepositoryImpl) SaveAccess(ctx context.Context, access *entity.KeyAccess) error {
+	return r.contentKeyDao.CreateAccessLog(ctx, r.videoConvertor.KeyAccessToPO(access))
+}
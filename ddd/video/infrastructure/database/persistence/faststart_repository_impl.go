@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"time"
+)
+
+// faststartRepositoryImpl 源文件 faststart 改写记录仓储实现
+type faststartRepositoryImpl struct {
+	faststartDao   *dao.VideoFaststartDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewFaststartRepository 创建 faststart 改写记录仓储实例（支持依赖注入）
+func NewFaststartRepository() repo.FaststartRepository {
+	return &faststartRepositoryImpl{
+		faststartDao:   dao.NewVideoFaststartDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存等待处理的记录
+func (r *faststartRepositoryImpl) Create(ctx context.Context, job *entity.FaststartJob) error {
+	return r.faststartDao.Create(ctx, r.videoConvertor.FaststartJobToPO(job))
+}
+
+// FindLatest 查找视频最新的记录
+func (r *faststartRepositoryImpl) FindLatest(ctx context.Context, videoUUID string) (*entity.FaststartJob, error) {
+	jobPO, err := r.faststartDao.GetLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.FaststartJobPOToEntity(jobPO), nil
+}
+
+// FindClaimable 查找可以领取的记录
+func (r *faststartRepositoryImpl) FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.FaststartJob, error) {
+	statuses := []string{vo.RenditionStatusPending.Value(), vo.RenditionStatusRunning.Value()}
+	jobPOs, err := r.faststartDao.GetClaimable(ctx, statuses, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*entity.FaststartJob, 0, len(jobPOs))
+	for _, jobPO := range jobPOs {
+		jobs = append(jobs, r.videoConvertor.FaststartJobPOToEntity(jobPO))
+	}
+	return jobs, nil
+}
+
+// Claim 领取成功后实体进入处理中；领取失败时实体保持不变
+func (r *faststartRepositoryImpl) Claim(ctx context.Context, job *entity.FaststartJob, leaseUntil time.Time) (bool, error) {
+	fromStatus, fromAttempts := job.Status().Value(), job.Attempts()
+	claimed := *job
+	claimed.Start(leaseUntil)
+	ok, err := r.faststartDao.Claim(ctx, r.videoConvertor.FaststartJobToPO(&claimed), fromStatus, fromAttempts, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	*job = claimed
+	return true, nil
+}
+
+// Finish 记录一次处理的结果
+func (r *faststartRepositoryImpl) Finish(ctx context.Context, job *entity.FaststartJob) (bool, error) {
+	return r.faststartDao.Finish(ctx, r.videoConvertor.FaststartJobToPO(job))
+}
+
+// CancelActive 取消视频全部未完成的记录
+func (r *faststartRepositoryImpl) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	return r.faststartDao.CancelActive(ctx, videoUUID, reason)
+}
@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"slices"
+	"time"
+)
+
+// liveStreamRepositoryImpl 直播和直播分片仓储实现
+type liveStreamRepositoryImpl struct {
+	liveStreamDao  *dao.VideoLiveStreamDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewLiveStreamRepository 创建直播仓储实例（支持依赖注入）
+func NewLiveStreamRepository() repo.LiveStreamRepository {
+	return &liveStreamRepositoryImpl{
+		liveStreamDao:  dao.NewVideoLiveStreamDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存新建的直播
+func (r *liveStreamRepositoryImpl) Create(ctx context.Context, stream *entity.LiveStream) error {
+	return r.liveStreamDao.Create(ctx, r.videoConvertor.LiveStreamToPO(stream))
+}
+
+// FindByUUID 根据UUID查找直播
+func (r *liveStreamRepositoryImpl) FindByUUID(ctx context.Context, streamUUID string) (*entity.LiveStream, error) {
+	streamPO, err := r.liveStreamDao.GetByUUID(ctx, streamUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.LiveStreamPOToEntity(streamPO), nil
+}
+
+// FindByStreamKeyHash 根据推流密钥的哈希查找直播
+func (r *liveStreamRepositoryImpl) FindByStreamKeyHash(ctx context.Context, streamKeyHash string) (*entity.LiveStream, error) {
+	streamPO, err := r.liveStreamDao.GetByStreamKeyHash(ctx, streamKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.LiveStreamPOToEntity(streamPO), nil
+}
+
+// FindByUser 查找用户创建的全部直播
+func (r *liveStreamRepositoryImpl) FindByUser(ctx context.Context, userUUID string) ([]*entity.LiveStream, error) {
+	streamPOs, err := r.liveStreamDao.GetByUser(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toStreams(streamPOs), nil
+}
+
+// FindIdle 查找推流中断的直播
+func (r *liveStreamRepositoryImpl) FindIdle(ctx context.Context, cutoff time.Time, limit int) ([]*entity.LiveStream, error) {
+	streamPOs, err := r.liveStreamDao.GetIdle(ctx, vo.LiveStreamStatusLive.Value(), cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toStreams(streamPOs), nil
+}
+
+// UpdateStatus 保存直播状态
+func (r *liveStreamRepositoryImpl) UpdateStatus(ctx context.Context, stream *entity.LiveStream, from vo.LiveStreamStatus) (bool, error) {
+	return r.liveStreamDao.UpdateStatus(ctx, r.videoConvertor.LiveStreamToPO(stream), from.Value())
+}
+
+// TouchIngest 记录最近一次推流的时间
+func (r *liveStreamRepositoryImpl) TouchIngest(ctx context.Context, streamUUID string, at time.Time) error {
+	return r.liveStreamDao.TouchIngest(ctx, streamUUID, at)
+}
+
+// FindConvertible 查找可以领取转换的直播
+func (r *liveStreamRepositoryImpl) FindConvertible(ctx context.Context, now time.Time, limit int) ([]*entity.LiveStream, error) {
+	statuses := []string{vo.RenditionStatusPending.Value(), vo.RenditionStatusRunning.Value()}
+	streamPOs, err := r.liveStreamDao.GetConvertible(ctx, statuses, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toStreams(streamPOs), nil
+}
+
+// ClaimConversion 领取成功后实体进入转换中；领取失败时实体保持不变
+func (r *liveStreamRepositoryImpl) ClaimConversion(ctx context.Context, stream *entity.LiveStream, leaseUntil time.Time) (bool, error) {
+	fromStatus, fromAttempts := stream.VodStatus().Value(), stream.VodAttempts()
+	claimed := *stream
+	claimed.StartConversion(leaseUntil)
+	ok, err := r.liveStreamDao.ClaimConversion(ctx, r.videoConvertor.LiveStreamToPO(&claimed), fromStatus, fromAttempts, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	*stream = claimed
+	return true, nil
+}
+
+// SaveConversion 保存转换的进展和结果
+func (r *liveStreamRepositoryImpl) SaveConversion(ctx context.Context, stream *entity.LiveStream) (bool, error) {
+	return r.liveStreamDao.SaveConversion(ctx, r.videoConvertor.LiveStreamToPO(stream))
+}
+
+// CreateSegment 登记分片
+func (r *liveStreamRepositoryImpl) CreateSegment(ctx context.Context, segment *entity.LiveSegment) (bool, error) {
+	return r.liveStreamDao.CreateSegment(ctx, r.videoConvertor.LiveSegmentToPO(segment))
+}
+
+// FindLastSegments 查找直播最后的若干个分片，按序列号升序
+func (r *liveStreamRepositoryImpl) FindLastSegments(ctx context.Context, streamUUID string, limit int) ([]*entity.LiveSegment, error) {
+	segmentPOs, err := r.liveStreamDao.GetLastSegments(ctx, streamUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(segmentPOs)
+	return r.toSegments(segmentPOs), nil
+}
+
+// FindSegments 查找直播的全部分片
+func (r *liveStreamRepositoryImpl) FindSegments(ctx context.Context, streamUUID string) ([]*entity.LiveSegment, error) {
+	segmentPOs, err := r.liveStreamDao.GetSegments(ctx, streamUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toSegments(segmentPOs), nil
+}
+
+func (r *liveStreamRepositoryImpl) toStreams(streamPOs []*po.VideoLiveStreamPo) []*entity.LiveStream {
+	streams := make([]*entity.LiveStream, 0, len(streamPOs))
+	for _, streamPO := range streamPOs {
+		streams = append(streams, r.videoConvertor.LiveStreamPOToEntity(streamPO))
+	}
+	return streams
+}
+
+func (r *liveStreamRepositoryImpl) toSegments(segmentPOs []*po.VideoLiveSegmentPo) []*entity.LiveSegment {
+	segments := make([]*entity.LiveSegment, 0, len(segmentPOs))
+	for _, segmentPO := range segmentPOs {
+		segments = append(segments, r.videoConvertor.LiveSegmentPOToEntity(segmentPO))
+	}
+	return segments
+}
@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+)
+
+// pipelineRepositoryImpl 视频处理流水线仓储实现
+type pipelineRepositoryImpl struct {
+	pipelineStepDao *dao.VideoPipelineStepDao
+	videoConvertor  *convertor.VideoConvertor
+}
+
+// NewPipelineRepository 创建处理流水线仓储实例（支持依赖注入）
+func NewPipelineRepository() repo.PipelineRepository {
+	return &pipelineRepositoryImpl{
+		pipelineStepDao: dao.NewVideoPipelineStepDao(),
+		videoConvertor:  convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存流水线的全部步骤
+func (r *pipelineRepositoryImpl) Create(ctx context.Context, pipeline *entity.Pipeline) error {
+	stepPOs := make([]*po.VideoPipelineStepPo, 0, len(pipeline.Steps()))
+	for _, step := range pipeline.Steps() {
+		stepPOs = append(stepPOs, r.videoConvertor.PipelineStepToPO(step))
+	}
+	return r.pipelineStepDao.CreateBatch(ctx, stepPOs)
+}
+
+// FindLatest 查找视频最新的流水线
+func (r *pipelineRepositoryImpl) FindLatest(ctx context.Context, videoUUID string) (*entity.Pipeline, error) {
+	stepPOs, err := r.pipelineStepDao.GetLatest(ctx, videoUUID)
+	if err != nil || len(stepPOs) == 0 {
+		return nil, err
+	}
+	steps := make([]*entity.PipelineStep, 0, len(stepPOs))
+	for _, stepPO := range stepPOs {
+		steps = append(steps, r.videoConvertor.PipelineStepPOToEntity(stepPO))
+	}
+	return entity.NewPipeline(steps), nil
+}
+
+// FindDueVideos 查找需要推进流水线的视频
+func (r *pipelineRepositoryImpl) FindDueVideos(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return r.pipelineStepDao.GetDueVideoUUIDs(ctx, now, limit)
+}
+
+// Update 以比较并交换的方式保存步骤
+func (r *pipelineRepositoryImpl) Update(ctx context.Context, step *entity.PipelineStep, fromStatus string,
+	fromAttempts int) (bool, error) {
+	return r.pipelineStepDao.Update(ctx, r.videoConvertor.PipelineStepToPO(step), fromStatus, fromAttempts)
+}
+
+// CancelActive 取消视频全部未完成的步骤
+func (r *pipelineRepositoryImpl) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	return r.pipelineStepDao.CancelActive(ctx, videoUUID, reason)
+}
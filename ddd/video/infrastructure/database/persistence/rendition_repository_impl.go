@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+)
+
+// renditionRepositoryImpl 转码输出仓储实现
+type renditionRepositoryImpl struct {
+	renditionDao   *dao.VideoRenditionDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewRenditionRepository 创建转码输出仓储实例（支持依赖注入）
+func NewRenditionRepository() repo.RenditionRepository {
+	return &renditionRepositoryImpl{
+		renditionDao:   dao.NewVideoRenditionDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// SaveAll 保存新建的转码输出
+func (r *renditionRepositoryImpl) SaveAll(ctx context.Context, renditions []*entity.Rendition) error {
+	renditionPOs := make([]*po.VideoRenditionPo, 0, len(renditions))
+	for _, rendition := range renditions {
+		renditionPOs = append(renditionPOs, r.videoConvertor.RenditionToPO(rendition))
+	}
+	return r.renditionDao.CreateBatch(ctx, renditionPOs)
+}
+
+// FindByUUID 根据UUID查找转码输出
+func (r *renditionRepositoryImpl) FindByUUID(ctx context.Context, renditionUUID string) (*entity.Rendition, error) {
+	renditionPO, err := r.renditionDao.GetByUUID(ctx, renditionUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.RenditionPOToEntity(renditionPO), nil
+}
+
+// FindByVideoUUID 查找视频的全部转码输出
+func (r *renditionRepositoryImpl) FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.Rendition, error) {
+	renditionPOs, err := r.renditionDao.GetByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(renditionPOs), nil
+}
+
+// FindClaimable 查找可以领取的转码输出
+func (r *renditionRepositoryImpl) FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.Rendition, error) {
+	statuses := []string{vo.RenditionStatusPending.Value(), vo.RenditionStatusRunning.Value()}
+	renditionPOs, err := r.renditionDao.GetClaimable(ctx, statuses, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(renditionPOs), nil
+}
+
+// Claim 领取成功后实体进入转码中；领取失败时实体保持不变
+func (r *renditionRepositoryImpl) Claim(ctx context.Context, rendition *entity.Rendition, leaseUntil time.Time) (bool, error) {
+	fromStatus, fromAttempts := rendition.Status().Value(), rendition.Attempts()
+	claimed := *rendition
+	claimed.Start(leaseUntil)
+	ok, err := r.renditionDao.Claim(ctx, r.videoConvertor.RenditionToPO(&claimed), fromStatus, fromAttempts, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	*rendition = claimed
+	return true, nil
+}
+
+// UpdateProgress 记录转码进度并续租
+func (r *renditionRepositoryImpl) UpdateProgress(ctx context.Context, rendition *entity.Rendition) (bool, error) {
+	return r.renditionDao.UpdateProgress(ctx, r.videoConvertor.RenditionToPO(rendition))
+}
+
+// Finish 记录一次转码的结果
+func (r *renditionRepositoryImpl) Finish(ctx context.Context, rendition *entity.Rendition) (bool, error) {
+	return r.renditionDao.Finish(ctx, r.videoConvertor.RenditionToPO(rendition))
+}
+
+// CancelActive 取消视频全部未完成的输出
+func (r *renditionRepositoryImpl) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	return r.renditionDao.CancelActive(ctx, videoUUID, reason)
+}
+
+// MarkSuperseded 已完成的输出标记为被替换
+func (r *renditionRepositoryImpl) MarkSuperseded(ctx context.Context, renditionUUID string) error {
+	_, err := r.renditionDao.UpdateStatus(ctx, renditionUUID, vo.RenditionStatusSucceeded.Value(), vo.RenditionStatusSuperseded.Value())
+	return err
+}
+
+func (r *renditionRepositoryImpl) toEntities(renditionPOs []*po.VideoRenditionPo) []*entity.Rendition {
+	renditions := make([]*entity.Rendition, 0, len(renditionPOs))
+	for _, renditionPO := range renditionPOs {
+		renditions = append(renditions, r.videoConvertor.RenditionPOToEntity(renditionPO))
+	}
+	return renditions
+}
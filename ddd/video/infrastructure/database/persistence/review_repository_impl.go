@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"time"
+)
+
+// reviewRepositoryImpl 视频审核仓储实现
+type reviewRepositoryImpl struct {
+	reviewDao      *dao.VideoReviewDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewReviewRepository 创建视频审核仓储实例（支持依赖注入）
+func NewReviewRepository() repo.ReviewRepository {
+	return &reviewRepositoryImpl{
+		reviewDao:      dao.NewVideoReviewDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// FindQueue 分页查找审核队列
+func (r *reviewRepositoryImpl) FindQueue(ctx context.Context, page *vo.Page) ([]*entity.ReviewQueueItem, int64, error) {
+	videoPOs, err := r.reviewDao.GetPendingByPage(ctx, vo.VideoStatusReady.StoredValues(), page.Offset(), page.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.reviewDao.CountPending(ctx, vo.VideoStatusReady.StoredValues())
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*entity.ReviewQueueItem, 0, len(videoPOs))
+	for _, videoPO := range videoPOs {
+		items = append(items, r.videoConvertor.POToReviewQueueItem(videoPO))
+	}
+	return items, total, nil
+}
+
+// Claim 认领审核任务
+func (r *reviewRepositoryImpl) Claim(ctx context.Context, videoUUID, moderatorUUID string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	return r.reviewDao.Claim(ctx, videoUUID, vo.VideoStatusReady.StoredValues(), moderatorUUID, now, now.Add(-ttl))
+}
+
+// Decide 保存审核结论并记录审核日志
+func (r *reviewRepositoryImpl) Decide(ctx context.Context, reviewLog *entity.ReviewLog, ttl time.Duration) (bool, error) {
+	logPO := r.videoConvertor.ReviewLogEntityToPO(reviewLog)
+	return r.reviewDao.Decide(ctx, logPO, vo.VideoStatusReady.StoredValues(), reviewLog.Decision().ResultStatus().Value(), time.Now().Add(-ttl))
+}
+
+// FindLogsByVideoUUID 查找视频的审核记录
+func (r *reviewRepositoryImpl) FindLogsByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.ReviewLog, error) {
+	logPOs, err := r.reviewDao.GetLogsByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*entity.ReviewLog, 0, len(logPOs))
+	for _, logPO := range logPOs {
+		logs = append(logs, r.videoConvertor.ReviewLogPOToEntity(logPO))
+	}
+	return logs, nil
+}
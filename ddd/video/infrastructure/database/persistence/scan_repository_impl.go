@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"time"
+)
+
+// scanRepositoryImpl 源文件恶意软件扫描记录仓储实现
+type scanRepositoryImpl struct {
+	scanDao        *dao.VideoScanDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewScanRepository 创建恶意软件扫描记录仓储实例（支持依赖注入）
+func NewScanRepository() repo.ScanRepository {
+	return &scanRepositoryImpl{
+		scanDao:        dao.NewVideoScanDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// Create 保存等待扫描的记录
+func (r *scanRepositoryImpl) Create(ctx context.Context, job *entity.ScanJob) error {
+	return r.scanDao.Create(ctx, r.videoConvertor.ScanJobToPO(job))
+}
+
+// FindLatest 查找视频最新的记录
+func (r *scanRepositoryImpl) FindLatest(ctx context.Context, videoUUID string) (*entity.ScanJob, error) {
+	scanPO, err := r.scanDao.GetLatest(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.ScanJobPOToEntity(scanPO), nil
+}
+
+// FindClaimable 查找可以领取的记录
+func (r *scanRepositoryImpl) FindClaimable(ctx context.Context, now time.Time, limit int) ([]*entity.ScanJob, error) {
+	statuses := []string{vo.RenditionStatusPending.Value(), vo.RenditionStatusRunning.Value()}
+	scanPOs, err := r.scanDao.GetClaimable(ctx, statuses, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*entity.ScanJob, 0, len(scanPOs))
+	for _, scanPO := range scanPOs {
+		jobs = append(jobs, r.videoConvertor.ScanJobPOToEntity(scanPO))
+	}
+	return jobs, nil
+}
+
+// Claim 领取成功后实体进入处理中；领取失败时实体保持不变
+func (r *scanRepositoryImpl) Claim(ctx context.Context, job *entity.ScanJob, leaseUntil time.Time) (bool, error) {
+	fromStatus, fromAttempts := job.Status().Value(), job.Attempts()
+	claimed := *job
+	claimed.Start(leaseUntil)
+	ok, err := r.scanDao.Claim(ctx, r.videoConvertor.ScanJobToPO(&claimed), fromStatus, fromAttempts, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	*job = claimed
+	return true, nil
+}
+
+// Finish 记录一次扫描的结果
+func (r *scanRepositoryImpl) Finish(ctx context.Context, job *entity.ScanJob) (bool, error) {
+	return r.scanDao.Finish(ctx, r.videoConvertor.ScanJobToPO(job))
+}
+
+// CancelActive 取消视频全部未完成的记录
+func (r *scanRepositoryImpl) CancelActive(ctx context.Context, videoUUID, reason string) (int64, error) {
+	return r.scanDao.CancelActive(ctx, videoUUID, reason)
+}
@@ -0,0 +1,124 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/video/domain/entity"
+	"go-video/ddd/video/domain/repo"
+	"go-video/ddd/video/domain/vo"
+	"go-video/ddd/video/infrastructure/database/convertor"
+	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"time"
+)
+
+// sourceVersionRepositoryImpl 源文件版本仓储实现
+type sourceVersionRepositoryImpl struct {
+	versionDao     *dao.VideoSourceVersionDao
+	videoConvertor *convertor.VideoConvertor
+}
+
+// NewSourceVersionRepository 创建源文件版本仓储实例（支持依赖注入）
+func NewSourceVersionRepository() repo.SourceVersionRepository {
+	return &sourceVersionRepositoryImpl{
+		versionDao:     dao.NewVideoSourceVersionDao(),
+		videoConvertor: convertor.NewVideoConvertor(),
+	}
+}
+
+// CreateNext 保存新版本，分配的版本号回写到实体
+func (r *sourceVersionRepositoryImpl) CreateNext(ctx context.Context, initial, version *entity.SourceVersion) error {
+	var initialPO *po.VideoSourceVersionPo
+	if initial != nil {
+		initialPO = r.videoConvertor.SourceVersionToPO(initial)
+	}
+	versionPO := r.videoConvertor.SourceVersionToPO(version)
+	if err := r.versionDao.CreateNext(ctx, initialPO, versionPO); err != nil {
+		return err
+	}
+	version.SetVersion(versionPO.Version)
+	return nil
+}
+
+// Activate 切换视频的生效版本
+func (r *sourceVersionRepositoryImpl) Activate(ctx context.Context, version *entity.SourceVersion, previousPath string, retainUntil time.Time) error {
+	ok, err := r.versionDao.Activate(ctx, r.videoConvertor.SourceVersionToPO(version), previousPath, version.Status().Value(), retainUntil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &vo.TransitionError{
+			Subject: "source_version",
+			ID:      version.UUID(),
+			From:    version.Status().Value(),
+			To:      vo.SourceVersionStatusActive.Value(),
+			Reason:  "source changed concurrently",
+		}
+	}
+	return nil
+}
+
+// MarkFailed 上传中的版本标记为失败
+func (r *sourceVersionRepositoryImpl) MarkFailed(ctx context.Context, versionUUID, errorMsg string) error {
+	_, err := r.versionDao.MarkFailed(ctx, versionUUID, errorMsg)
+	return err
+}
+
+// MarkPurged 超过保留期的版本标记为已清理
+func (r *sourceVersionRepositoryImpl) MarkPurged(ctx context.Context, versionUUID string, now time.Time) (bool, error) {
+	return r.versionDao.MarkPurged(ctx, versionUUID, now)
+}
+
+// FindByUUID 根据UUID查找版本
+func (r *sourceVersionRepositoryImpl) FindByUUID(ctx context.Context, versionUUID string) (*entity.SourceVersion, error) {
+	versionPO, err := r.versionDao.GetByUUID(ctx, versionUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.SourceVersionPOToEntity(versionPO), nil
+}
+
+// FindByVersion 根据版本号查找版本
+func (r *sourceVersionRepositoryImpl) FindByVersion(ctx context.Context, videoUUID string, version int) (*entity.SourceVersion, error) {
+	versionPO, err := r.versionDao.GetByVersion(ctx, videoUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.SourceVersionPOToEntity(versionPO), nil
+}
+
+// FindByVideoUUID 查找视频的全部版本
+func (r *sourceVersionRepositoryImpl) FindByVideoUUID(ctx context.Context, videoUUID string) ([]*entity.SourceVersion, error) {
+	versionPOs, err := r.versionDao.GetByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(versionPOs), nil
+}
+
+// FindExpired 查找保留期已过的版本
+func (r *sourceVersionRepositoryImpl) FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.SourceVersion, error) {
+	versionPOs, err := r.versionDao.GetExpired(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(versionPOs), nil
+}
+
+// FindRetainedStoragePaths 查找仍需保留对象的版本的存储路径
+func (r *sourceVersionRepositoryImpl) FindRetainedStoragePaths(ctx context.Context) ([]string, error) {
+	statuses := make([]string, 0, len(vo.SourceVersionStatuses))
+	for _, status := range vo.SourceVersionStatuses {
+		if status.HoldsObject() {
+			statuses = append(statuses, status.Value())
+		}
+	}
+	return r.versionDao.GetStoragePathsByStatus(ctx, statuses)
+}
+
+func (r *sourceVersionRepositoryImpl) toEntities(versionPOs []*po.VideoSourceVersionPo) []*entity.SourceVersion {
+	versions := make([]*entity.SourceVersion, 0, len(versionPOs))
+	for _, versionPO := range versionPOs {
+		versions = append(versions, r.videoConvertor.SourceVersionPOToEntity(versionPO))
+	}
+	return versions
+}
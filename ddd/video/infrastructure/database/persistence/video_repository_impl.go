@@ -7,6 +7,9 @@ import (
 	"go-video/ddd/video/domain/vo"
 	"go-video/ddd/video/infrastructure/database/convertor"
 	"go-video/ddd/video/infrastructure/database/dao"
+	"go-video/ddd/video/infrastructure/database/po"
+	"go-video/pkg/event"
+	"strings"
 )
 
 // videoRepositoryImpl 视频仓储实现
@@ -29,12 +32,255 @@ func (r *videoRepositoryImpl) Save(ctx context.Context, video *entity.Video) err
 	return r.videoDao.Create(ctx, videoPO)
 }
 
+// FindByChecksum 根据上传时的源文件SHA-256查找用户的视频
+func (r *videoRepositoryImpl) FindByChecksum(ctx context.Context, userUUID, sha256 string) (*entity.Video, error) {
+	videoPO, err := r.videoDao.GetByUserUUIDAndChecksum(ctx, userUUID, sha256)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.POToEntity(videoPO), nil
+}
+
+// FindByUUID 根据UUID查找视频
+func (r *videoRepositoryImpl) FindByUUID(ctx context.Context, videoUUID string) (*entity.Video, error) {
+	videoPO, err := r.videoDao.GetByUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.POToEntity(videoPO), nil
+}
+
 func (r *videoRepositoryImpl) CreateVideo(ctx context.Context, video *entity.Video, videoUploadTask *entity.VideoUploadTaskEntity) error {
 	videoPO := r.videoConvertor.EntityToPO(video)
 	videoUploadTaskPo := r.videoConvertor.VideoUploadTaskEntityToPO(videoUploadTask)
 	return r.videoDao.CreateVideoAndTask(ctx, videoPO, videoUploadTaskPo)
 }
 
-func (r *videoRepositoryImpl) UpdateVideoStatus(ctx context.Context, videoUUID string, videoStatus vo.VideoStatus, videoUploadTaskUUID string, videotaskStatus vo.VideoUploadTaskStatus) error {
-	return r.videoDao.UpdateVideoStatus(ctx, videoUUID, videoStatus.Value(), videoUploadTaskUUID, videotaskStatus.Value())
+// CreateBatch 保存批量上传及其视频和上传任务
+func (r *videoRepositoryImpl) CreateBatch(ctx context.Context, batch *entity.UploadBatch, items []*entity.VideoEntity) error {
+	videoPOs := make([]*po.VideoPo, 0, len(items))
+	taskPOs := make([]*po.VideoUploadTaskPo, 0, len(items))
+	for _, item := range items {
+		videoPOs = append(videoPOs, r.videoConvertor.EntityToPO(item.Video()))
+		taskPOs = append(taskPOs, r.videoConvertor.VideoUploadTaskEntityToPO(item.VideoUploadTask()))
+	}
+	return r.videoDao.CreateBatch(ctx, r.videoConvertor.UploadBatchToPO(batch), videoPOs, taskPOs)
+}
+
+// FindBatch 根据UUID查找批量上传
+func (r *videoRepositoryImpl) FindBatch(ctx context.Context, batchUUID string) (*entity.UploadBatch, error) {
+	batchPO, err := r.videoDao.GetBatchByUUID(ctx, batchUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.UploadBatchPOToEntity(batchPO), nil
+}
+
+// FindBatchItems 查找批量上传下的上传任务及其视频，视频已被删除的任务不返回
+func (r *videoRepositoryImpl) FindBatchItems(ctx context.Context, batchUUID string) ([]*entity.VideoEntity, error) {
+	taskPOs, err := r.videoDao.GetTasksByBatchUUID(ctx, batchUUID)
+	if err != nil {
+		return nil, err
+	}
+	videoUUIDs := make([]string, 0, len(taskPOs))
+	for _, taskPO := range taskPOs {
+		videoUUIDs = append(videoUUIDs, taskPO.VideoUUID)
+	}
+	videoPOs, err := r.videoDao.GetByUUIDs(ctx, videoUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	videos := make(map[string]*entity.Video, len(videoPOs))
+	for _, videoPO := range videoPOs {
+		videos[videoPO.UUID] = r.videoConvertor.POToEntity(videoPO)
+	}
+	items := make([]*entity.VideoEntity, 0, len(taskPOs))
+	for _, taskPO := range taskPOs {
+		video, ok := videos[taskPO.VideoUUID]
+		if !ok {
+			continue
+		}
+		items = append(items, entity.NewVideoEntity(video, r.videoConvertor.VideoUploadTaskPOToEntity(taskPO)))
+	}
+	return items, nil
+}
+
+// FindPublic 分页查找公开视频
+func (r *videoRepositoryImpl) FindPublic(ctx context.Context, page *vo.Page) ([]*entity.Video, int64, error) {
+	videoPOs, err := r.videoDao.GetPublicByPage(ctx, vo.VideoStatusReady.StoredValues(), page.Offset(), page.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.videoDao.CountPublic(ctx, vo.VideoStatusReady.StoredValues())
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toEntities(videoPOs), total, nil
+}
+
+// FindByUserUUID 分页查找用户的全部视频
+func (r *videoRepositoryImpl) FindByUserUUID(ctx context.Context, userUUID string, page *vo.Page) ([]*entity.Video, int64, error) {
+	videoPOs, err := r.videoDao.GetByUserUUIDAndPage(ctx, userUUID, page.Offset(), page.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.videoDao.CountByUserUUID(ctx, userUUID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toEntities(videoPOs), total, nil
+}
+
+// FindAllByUserUUID 查找用户的全部视频
+func (r *videoRepositoryImpl) FindAllByUserUUID(ctx context.Context, userUUID string) ([]*entity.Video, error) {
+	videoPOs, err := r.videoDao.GetAllByUserUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(videoPOs), nil
+}
+
+// FindUploadTasksByUser 查找用户的全部上传任务
+func (r *videoRepositoryImpl) FindUploadTasksByUser(ctx context.Context, userUUID string) ([]*entity.VideoUploadTaskEntity, error) {
+	taskPOs, err := r.videoDao.GetTasksByUserUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*entity.VideoUploadTaskEntity, 0, len(taskPOs))
+	for _, taskPO := range taskPOs {
+		tasks = append(tasks, r.videoConvertor.VideoUploadTaskPOToEntity(taskPO))
+	}
+	return tasks, nil
+}
+
+// UpdateReview 更新视频的审核状态和审核意见
+func (r *videoRepositoryImpl) UpdateReview(ctx context.Context, video *entity.Video) error {
+	return r.videoDao.UpdateReview(ctx, video.UUID(), video.ReviewStatus().Value(), video.ReviewReason())
+}
+
+func (r *videoRepositoryImpl) toEntities(videoPOs []*po.VideoPo) []*entity.Video {
+	videos := make([]*entity.Video, 0, len(videoPOs))
+	for _, videoPO := range videoPOs {
+		videos = append(videos, r.videoConvertor.POToEntity(videoPO))
+	}
+	return videos
+}
+
+// UpdateVideoStatus 以比较并交换的方式持久化视频状态迁移
+func (r *videoRepositoryImpl) UpdateVideoStatus(ctx context.Context, transition *entity.StatusTransition, taskTransition *entity.TaskTransition) error {
+	historyPO := r.videoConvertor.StatusTransitionToPO(transition)
+	var taskPO *po.VideoUploadTaskPo
+	taskFromStatus := ""
+	if taskTransition != nil {
+		taskPO = r.videoConvertor.TaskTransitionToPO(taskTransition)
+		taskFromStatus = taskTransition.From().Value()
+	}
+	events, err := event.NewAll(transition.Events())
+	if err != nil {
+		return err
+	}
+	applied, err := r.videoDao.UpdateVideoStatus(ctx, historyPO, transition.From().StoredValues(), taskPO, taskFromStatus, events)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return &vo.TransitionError{
+			Subject: "video",
+			ID:      transition.VideoUuid(),
+			From:    transition.From().Value(),
+			To:      transition.To().Value(),
+			Reason:  "status changed concurrently",
+		}
+	}
+	return nil
+}
+
+// UpdateChecksum 记录视频和上传任务的校验和和存储ETag
+func (r *videoRepositoryImpl) UpdateChecksum(ctx context.Context, video *entity.Video, task *entity.VideoUploadTaskEntity) error {
+	taskUUID := ""
+	if task != nil {
+		taskUUID = task.UUID()
+	}
+	return r.videoDao.UpdateChecksum(ctx, video.UUID(), taskUUID, video.Checksum().MD5(), video.Checksum().SHA256(), video.ETag(),
+		video.SourceSHA256())
+}
+
+// UpdateTaskProgress 记录上传任务的传输进度
+func (r *videoRepositoryImpl) UpdateTaskProgress(ctx context.Context, task *entity.VideoUploadTaskEntity) error {
+	return r.videoDao.UpdateTaskProgress(ctx, task.UUID(), task.BytesReceived(), task.BytesTotal())
+}
+
+// UpdateFileSize 记录视频源文件的实际大小
+func (r *videoRepositoryImpl) UpdateFileSize(ctx context.Context, videoUUID string, fileSize int64) error {
+	return r.videoDao.UpdateFileSize(ctx, videoUUID, fileSize)
+}
+
+// UpdateSourceContent 更新改写后的源文件属性
+func (r *videoRepositoryImpl) UpdateSourceContent(ctx context.Context, video *entity.Video, previousETag string) (bool, error) {
+	return r.videoDao.UpdateSourceContent(ctx, video.UUID(), video.StoragePath(), previousETag, video.FileSize(),
+		video.Checksum().MD5(), video.Checksum().SHA256(), video.ETag(), strings.Join(video.StrippedMetadata(), ","))
+}
+
+// UpdatePoster 更新视频封面
+func (r *videoRepositoryImpl) UpdatePoster(ctx context.Context, video *entity.Video, previousPath string) (bool, error) {
+	return r.videoDao.UpdatePoster(ctx, video.UUID(), previousPath, video.PosterPath())
+}
+
+// Delete 删除视频，同时写入视频记录的领域事件
+func (r *videoRepositoryImpl) Delete(ctx context.Context, video *entity.Video) error {
+	events, err := event.NewAll(video.PullEvents())
+	if err != nil {
+		return err
+	}
+	return r.videoDao.Delete(ctx, video.UUID(), events...)
+}
+
+// UpdateTaskStatus 以比较并交换的方式持久化上传任务状态迁移
+func (r *videoRepositoryImpl) UpdateTaskStatus(ctx context.Context, taskTransition *entity.TaskTransition) error {
+	taskPO := r.videoConvertor.TaskTransitionToPO(taskTransition)
+	applied, err := r.videoDao.UpdateTaskStatus(ctx, taskPO, taskTransition.From().Value())
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return &vo.TransitionError{
+			Subject: "upload_task",
+			ID:      taskTransition.TaskUuid(),
+			From:    taskTransition.From().Value(),
+			To:      taskTransition.To().Value(),
+			Reason:  "status changed concurrently",
+		}
+	}
+	return nil
+}
+
+// FindUploadTask 根据UUID查找上传任务
+func (r *videoRepositoryImpl) FindUploadTask(ctx context.Context, taskUUID string) (*entity.VideoUploadTaskEntity, error) {
+	taskPO, err := r.videoDao.GetTaskByUUID(ctx, taskUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.videoConvertor.VideoUploadTaskPOToEntity(taskPO), nil
+}
+
+// FindAllStorageRefs 查找全部视频的存储引用
+func (r *videoRepositoryImpl) FindAllStorageRefs(ctx context.Context) ([]*entity.Video, error) {
+	videoPOs, err := r.videoDao.GetAllStorageRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(videoPOs), nil
+}
+
+// FindStatusHistory 查找视频的状态迁移历史
+func (r *videoRepositoryImpl) FindStatusHistory(ctx context.Context, videoUUID string) ([]*entity.StatusTransition, error) {
+	historyPOs, err := r.videoDao.GetStatusHistory(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	transitions := make([]*entity.StatusTransition, 0, len(historyPOs))
+	for _, historyPO := range historyPOs {
+		transitions = append(transitions, r.videoConvertor.StatusHistoryPOToEntity(historyPO))
+	}
+	return transitions, nil
 }
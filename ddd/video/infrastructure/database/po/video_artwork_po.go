@@ -0,0 +1,30 @@
+package po
+
+import "time"
+
+type VideoArtworkPo struct {
+	BaseModel
+
+	UUID           string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID      string     `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	SourcePath     string     `gorm:"size:500;not null;column:source_path" json:"source_path"` // 生成时使用的源文件路径
+	Dir            string     `gorm:"size:500;not null;column:dir" json:"dir"`                 // 候选帧和雪碧图的存储目录
+	Status         string     `gorm:"index:idx_status_lease;size:20;not null;column:status" json:"status"`
+	Attempts       int        `gorm:"column:attempts" json:"attempts"`
+	Candidates     string     `gorm:"type:text;column:candidates" json:"candidates"` // 候选封面的JSON数组
+	StoryboardPath string     `gorm:"size:500;column:storyboard_path" json:"storyboard_path"`
+	ErrorMsg       string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	LeaseUntil     *time.Time `gorm:"index:idx_status_lease;column:lease_until" json:"lease_until"`
+	StartedAt      *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt    *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+// PosterCandidatePo 候选封面在 candidates 列中的格式
+type PosterCandidatePo struct {
+	OffsetMs int64  `json:"offset_ms"`
+	Path     string `json:"path"`
+}
+
+func (v *VideoArtworkPo) TableName() string {
+	return "video_artwork"
+}
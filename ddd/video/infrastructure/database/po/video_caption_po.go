@@ -0,0 +1,17 @@
+package po
+
+type VideoCaptionPo struct {
+	BaseModel
+
+	UUID         string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID    string `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	Language     string `gorm:"size:35;not null;column:language" json:"language"`
+	Label        string `gorm:"size:100;not null;column:label" json:"label"`
+	IsDefault    bool   `gorm:"not null;default:false;column:is_default" json:"is_default"`
+	SourceFormat string `gorm:"size:10;not null;column:source_format" json:"source_format"` // 上传时的源格式 srt/vtt
+	StoragePath  string `gorm:"size:500;not null;column:storage_path" json:"storage_path"`  // 规范化后的WebVTT对象名
+}
+
+func (v *VideoCaptionPo) TableName() string {
+	return "video_caption"
+}
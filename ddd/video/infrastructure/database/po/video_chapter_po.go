@@ -0,0 +1,14 @@
+package po
+
+type VideoChapterPo struct {
+	BaseModel
+
+	VideoUUID string `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	Position  int    `gorm:"not null;column:position" json:"position"` // 章节序号，从0开始
+	StartMs   int64  `gorm:"not null;column:start_ms" json:"start_ms"` // 开始时间（毫秒）
+	Title     string `gorm:"size:255;not null;column:title" json:"title"`
+}
+
+func (v *VideoChapterPo) TableName() string {
+	return "video_chapter"
+}
@@ -0,0 +1,15 @@
+package po
+
+type VideoContentKeyPo struct {
+	BaseModel
+
+	UUID        string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID   string `gorm:"uniqueIndex:uk_video_sequence;size:36;not null;column:video_uuid" json:"video_uuid"`
+	Sequence    int    `gorm:"uniqueIndex:uk_video_sequence;not null;column:sequence" json:"sequence"` // 密钥序号，按分片下标除以轮换分片数计算
+	MasterKeyID string `gorm:"index;size:64;not null;column:master_key_id" json:"master_key_id"`
+	WrappedKey  []byte `gorm:"type:varbinary(128);not null;column:wrapped_key" json:"-"` // 被主密钥包装的AES-128密钥
+}
+
+func (v *VideoContentKeyPo) TableName() string {
+	return "video_content_key"
+}
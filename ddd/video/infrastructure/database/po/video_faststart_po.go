@@ -0,0 +1,24 @@
+package po
+
+import "time"
+
+type VideoFaststartPo struct {
+	BaseModel
+
+	UUID         string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID    string     `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	SourcePath   string     `gorm:"size:500;not null;column:source_path" json:"source_path"` // 要改写的源文件路径
+	Status       string     `gorm:"index:idx_status_lease;size:20;not null;column:status" json:"status"`
+	Attempts     int        `gorm:"column:attempts" json:"attempts"`
+	Outcome      string     `gorm:"size:20;column:outcome" json:"outcome"` // rewritten/unchanged/not_mp4/unsupported
+	OriginalSize int64      `gorm:"column:original_size" json:"original_size"`
+	FileSize     int64      `gorm:"column:file_size" json:"file_size"`
+	ErrorMsg     string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	LeaseUntil   *time.Time `gorm:"index:idx_status_lease;column:lease_until" json:"lease_until"`
+	StartedAt    *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt  *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+func (v *VideoFaststartPo) TableName() string {
+	return "video_faststart"
+}
@@ -0,0 +1,19 @@
+package po
+
+type VideoKeyAccessLogPo struct {
+	BaseModel
+
+	UUID       string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID  string `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	KeyUUID    string `gorm:"index;size:64;not null;column:key_uuid" json:"key_uuid"` // 请求中的密钥UUID，拒绝的请求可能不是有效的UUID
+	UserUUID   string `gorm:"index;size:36;column:user_uuid" json:"user_uuid"`
+	IssuerUUID string `gorm:"size:36;column:issuer_uuid" json:"issuer_uuid"` // 使用分享令牌时令牌的签发人
+	ClientIP   string `gorm:"size:64;column:client_ip" json:"client_ip"`
+	UserAgent  string `gorm:"size:500;column:user_agent" json:"user_agent"`
+	Granted    bool   `gorm:"not null;column:granted" json:"granted"`
+	Reason     string `gorm:"size:100;column:reason" json:"reason"`
+}
+
+func (v *VideoKeyAccessLogPo) TableName() string {
+	return "video_key_access_log"
+}
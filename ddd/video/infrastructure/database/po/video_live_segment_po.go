@@ -0,0 +1,17 @@
+package po
+
+type VideoLiveSegmentPo struct {
+	BaseModel
+
+	UUID       string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	StreamUUID string `gorm:"uniqueIndex:idx_stream_sequence;size:36;not null;column:stream_uuid" json:"stream_uuid"`
+	Sequence   int    `gorm:"uniqueIndex:idx_stream_sequence;not null;column:sequence" json:"sequence"` // 媒体序列号，唯一索引保证并发推流时不会重复
+	Filename   string `gorm:"size:64;not null;column:filename" json:"filename"`
+	Path       string `gorm:"size:500;not null;column:path" json:"path"`
+	DurationMs int64  `gorm:"column:duration_ms" json:"duration_ms"`
+	Size       int64  `gorm:"column:size" json:"size"`
+}
+
+func (v *VideoLiveSegmentPo) TableName() string {
+	return "video_live_segment"
+}
@@ -0,0 +1,29 @@
+package po
+
+import "time"
+
+type VideoLiveStreamPo struct {
+	BaseModel
+
+	UUID          string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	UserUUID      string     `gorm:"index;size:36;not null;column:user_uuid" json:"user_uuid"`
+	Title         string     `gorm:"size:255;not null;column:title" json:"title"`
+	Description   string     `gorm:"type:text;column:description" json:"description"`
+	StreamKeyHash string     `gorm:"uniqueIndex;size:64;not null;column:stream_key_hash" json:"-"` // 推流密钥的SHA-256，密钥本身不保存
+	Status        string     `gorm:"index:idx_status_ingest;size:20;not null;column:status" json:"status"`
+	StartedAt     *time.Time `gorm:"column:started_at" json:"started_at"`
+	LastIngestAt  *time.Time `gorm:"index:idx_status_ingest;column:last_ingest_at" json:"last_ingest_at"`
+	EndedAt       *time.Time `gorm:"column:ended_at" json:"ended_at"`
+
+	// 转换为视频
+	VideoUUID     string     `gorm:"size:36;column:video_uuid" json:"video_uuid"`
+	TaskUUID      string     `gorm:"size:36;column:task_uuid" json:"task_uuid"`
+	VodStatus     string     `gorm:"index:idx_vod_status_lease;size:20;column:vod_status" json:"vod_status"`
+	VodAttempts   int        `gorm:"column:vod_attempts" json:"vod_attempts"`
+	VodErrorMsg   string     `gorm:"size:500;column:vod_error_msg" json:"vod_error_msg"`
+	VodLeaseUntil *time.Time `gorm:"index:idx_vod_status_lease;column:vod_lease_until" json:"vod_lease_until"`
+}
+
+func (v *VideoLiveStreamPo) TableName() string {
+	return "video_live_stream"
+}
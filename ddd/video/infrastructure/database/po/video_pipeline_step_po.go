@@ -0,0 +1,28 @@
+package po
+
+import "time"
+
+type VideoPipelineStepPo struct {
+	BaseModel
+
+	UUID         string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	PipelineUUID string     `gorm:"index;size:36;not null;column:pipeline_uuid" json:"pipeline_uuid"`
+	VideoUUID    string     `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	SourcePath   string     `gorm:"size:500;not null;column:source_path" json:"source_path"` // 流水线处理的源文件路径
+	Name         string     `gorm:"size:50;not null;column:name" json:"name"`
+	Position     int        `gorm:"column:position" json:"position"`
+	DependsOn    string     `gorm:"size:500;column:depends_on" json:"depends_on"` // 依赖的步骤名称，逗号分隔
+	Optional     bool       `gorm:"column:optional" json:"optional"`
+	Status       string     `gorm:"index:idx_status_next_run;size:20;not null;column:status" json:"status"`
+	Attempts     int        `gorm:"column:attempts" json:"attempts"`
+	MaxAttempts  int        `gorm:"column:max_attempts" json:"max_attempts"`
+	Progress     int        `gorm:"column:progress" json:"progress"`
+	ErrorMsg     string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	NextRunAt    *time.Time `gorm:"index:idx_status_next_run;column:next_run_at" json:"next_run_at"`
+	StartedAt    *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt  *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+func (v *VideoPipelineStepPo) TableName() string {
+	return "video_pipeline_step"
+}
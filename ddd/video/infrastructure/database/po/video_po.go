@@ -1,5 +1,7 @@
 package po
 
+import "time"
+
 type VideoPo struct {
 	BaseModel
 
@@ -13,6 +15,23 @@ type VideoPo struct {
 	Format      string `gorm:"size:20;column:format" json:"format"`
 	StoragePath string `gorm:"size:500;column:storage_path" json:"storage_path"`
 	Status      string `gorm:"column:status" json:"status"`
+
+	ChecksumMD5    string `gorm:"size:32;column:checksum_md5" json:"checksum_md5"`                             // 源文件明文MD5（十六进制）
+	ChecksumSHA256 string `gorm:"size:64;index;column:checksum_sha256" json:"checksum_sha256"`                 // 源文件当前内容的明文SHA-256（十六进制），改写源文件后随之更新
+	ETag           string `gorm:"size:64;column:etag" json:"etag"`                                             // 存储返回的ETag
+	SourceSHA256   string `gorm:"size:64;not null;default:'';index;column:source_sha256" json:"source_sha256"` // 上传时源文件明文SHA-256，改写源文件后不变，用于去重
+
+	ReviewStatus    string     `gorm:"size:20;not null;default:none;index;column:review_status" json:"review_status"`
+	ReviewReason    string     `gorm:"size:500;column:review_reason" json:"review_reason"`
+	ReviewClaimedBy string     `gorm:"size:36;column:review_claimed_by" json:"review_claimed_by"` // 当前认领的审核员UUID
+	ReviewClaimedAt *time.Time `gorm:"column:review_claimed_at" json:"review_claimed_at"`
+
+	Tags string `gorm:"size:500;column:tags" json:"tags"` // 标签，逗号分隔
+
+	PosterPath string `gorm:"size:500;not null;default:'';column:poster_path" json:"poster_path"` // 封面图路径，候选帧或自定义封面
+
+	KeepMetadata     bool   `gorm:"not null;default:false;column:keep_metadata" json:"keep_metadata"` // 上传时选择保留源文件元数据
+	StrippedMetadata string `gorm:"type:text;column:stripped_metadata" json:"stripped_metadata"`      // 从源文件中清除的元数据，逗号分隔
 }
 
 func (v *VideoPo) TableName() string {
@@ -0,0 +1,42 @@
+package po
+
+import "time"
+
+type VideoRenditionPo struct {
+	BaseModel
+
+	UUID         string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID    string     `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	SourcePath   string     `gorm:"size:500;not null;column:source_path" json:"source_path"` // 转码时使用的源文件路径
+	Name         string     `gorm:"size:20;not null;column:name" json:"name"`                // 档位名称，例如 720p
+	MaxHeight    int        `gorm:"column:max_height" json:"max_height"`                     // 档位的输出高度上限
+	VideoBitrate int        `gorm:"column:video_bitrate" json:"video_bitrate"`               // kbps
+	AudioBitrate int        `gorm:"column:audio_bitrate" json:"audio_bitrate"`               // kbps
+	Required     bool       `gorm:"column:required" json:"required"`
+	Status       string     `gorm:"index:idx_status_lease;size:20;not null;column:status" json:"status"`
+	Progress     int        `gorm:"column:progress" json:"progress"`
+	Attempts     int        `gorm:"column:attempts" json:"attempts"`
+	StoragePath  string     `gorm:"size:500;not null;column:storage_path" json:"storage_path"`
+	FileSize     int64      `gorm:"column:file_size" json:"file_size"`
+	Width        int        `gorm:"column:width" json:"width"`
+	Height       int        `gorm:"column:height" json:"height"`
+	ErrorMsg     string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	LeaseUntil   *time.Time `gorm:"index:idx_status_lease;column:lease_until" json:"lease_until"`
+	StartedAt    *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt  *time.Time `gorm:"column:completed_at" json:"completed_at"`
+
+	HLSPath          string `gorm:"size:500;column:hls_path" json:"hls_path"`          // HLS媒体播放列表路径，为空表示没有打包HLS
+	Codecs           string `gorm:"size:100;column:codecs" json:"codecs"`              // RFC 6381 编码
+	Bandwidth        int    `gorm:"column:bandwidth" json:"bandwidth"`                 // 峰值码率（bit/s）
+	AverageBandwidth int    `gorm:"column:average_bandwidth" json:"average_bandwidth"` // 平均码率（bit/s）
+
+	DASHPath            string `gorm:"size:500;column:dash_path" json:"dash_path"`                // DASH分片目录，为空表示没有打包DASH
+	DASHSegmentDuration int64  `gorm:"column:dash_segment_duration" json:"dash_segment_duration"` // 媒体分片的标称时长（毫秒）
+	DASHDuration        int64  `gorm:"column:dash_duration" json:"dash_duration"`                 // 媒体时长（毫秒）
+	VideoBandwidth      int    `gorm:"column:video_bandwidth" json:"video_bandwidth"`             // DASH视频轨道的峰值码率（bit/s）
+	AudioBandwidth      int    `gorm:"column:audio_bandwidth" json:"audio_bandwidth"`             // DASH音频轨道的峰值码率（bit/s），0表示没有音频
+}
+
+func (v *VideoRenditionPo) TableName() string {
+	return "video_rendition"
+}
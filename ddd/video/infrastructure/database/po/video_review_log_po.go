@@ -0,0 +1,15 @@
+package po
+
+type VideoReviewLogPo struct {
+	BaseModel
+
+	UUID          string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID     string `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	ModeratorUUID string `gorm:"size:36;not null;column:moderator_uuid" json:"moderator_uuid"`
+	Decision      string `gorm:"size:20;not null;column:decision" json:"decision"` // approve/reject
+	Reason        string `gorm:"size:500;column:reason" json:"reason"`
+}
+
+func (v *VideoReviewLogPo) TableName() string {
+	return "video_review_log"
+}
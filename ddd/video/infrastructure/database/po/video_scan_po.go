@@ -0,0 +1,24 @@
+package po
+
+import "time"
+
+type VideoScanPo struct {
+	BaseModel
+
+	UUID           string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID      string     `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	SourcePath     string     `gorm:"size:500;not null;column:source_path" json:"source_path"` // 扫描的源文件路径
+	Status         string     `gorm:"index:idx_status_lease;size:20;not null;column:status" json:"status"`
+	Attempts       int        `gorm:"column:attempts" json:"attempts"`
+	Verdict        string     `gorm:"size:20;column:verdict" json:"verdict"`                  // clean/infected
+	Signature      string     `gorm:"size:255;column:signature" json:"signature"`             // 命中的特征名称
+	QuarantinePath string     `gorm:"size:500;column:quarantine_path" json:"quarantine_path"` // 隔离后的路径
+	ErrorMsg       string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	LeaseUntil     *time.Time `gorm:"index:idx_status_lease;column:lease_until" json:"lease_until"`
+	StartedAt      *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt    *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+func (v *VideoScanPo) TableName() string {
+	return "video_scan"
+}
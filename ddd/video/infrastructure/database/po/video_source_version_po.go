@@ -0,0 +1,29 @@
+package po
+
+import "time"
+
+type VideoSourceVersionPo struct {
+	BaseModel
+
+	UUID           string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID      string     `gorm:"uniqueIndex:uk_video_version;size:36;not null;column:video_uuid" json:"video_uuid"`
+	Version        int        `gorm:"uniqueIndex:uk_video_version;not null;column:version" json:"version"` // 版本号，同一视频内从1递增
+	StoragePath    string     `gorm:"size:500;not null;column:storage_path" json:"storage_path"`
+	Filename       string     `gorm:"size:255;column:filename" json:"filename"`
+	FileSize       int64      `gorm:"column:file_size" json:"file_size"`
+	Format         string     `gorm:"size:20;column:format" json:"format"`
+	ChecksumMD5    string     `gorm:"size:32;column:checksum_md5" json:"checksum_md5"`
+	ChecksumSHA256 string     `gorm:"size:64;column:checksum_sha256" json:"checksum_sha256"`
+	ETag           string     `gorm:"size:64;column:etag" json:"etag"`
+	SourceSHA256   string     `gorm:"size:64;not null;default:'';column:source_sha256" json:"source_sha256"` // 上传时源文件明文SHA-256，改写源文件后不变
+	Status         string     `gorm:"index;size:20;not null;column:status" json:"status"`
+	UploaderUUID   string     `gorm:"size:36;column:uploader_uuid" json:"uploader_uuid"`
+	KeepMetadata   bool       `gorm:"not null;default:false;column:keep_metadata" json:"keep_metadata"` // 上传时选择保留源文件元数据
+	ErrorMsg       string     `gorm:"size:500;column:error_msg" json:"error_msg"`
+	ActivatedAt    *time.Time `gorm:"column:activated_at" json:"activated_at"` // 最近一次生效时间
+	RetainUntil    *time.Time `gorm:"column:retain_until" json:"retain_until"` // 被替换后的保留截止时间，之后对象会被清理
+}
+
+func (v *VideoSourceVersionPo) TableName() string {
+	return "video_source_version"
+}
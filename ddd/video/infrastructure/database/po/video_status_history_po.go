@@ -0,0 +1,15 @@
+package po
+
+type VideoStatusHistoryPo struct {
+	BaseModel
+
+	UUID       string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	VideoUUID  string `gorm:"index;size:36;not null;column:video_uuid" json:"video_uuid"`
+	FromStatus string `gorm:"size:20;not null;column:from_status" json:"from_status"`
+	ToStatus   string `gorm:"size:20;not null;column:to_status" json:"to_status"`
+	Cause      string `gorm:"size:500;column:cause" json:"cause"` // 迁移原因
+}
+
+func (v *VideoStatusHistoryPo) TableName() string {
+	return "video_status_history"
+}
@@ -0,0 +1,14 @@
+package po
+
+type VideoUploadBatchPo struct {
+	BaseModel
+
+	UUID          string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	UserUUID      string `gorm:"index;size:36;not null;column:user_uuid" json:"user_uuid"`
+	TotalFiles    int    `gorm:"not null;column:total_files" json:"total_files"`       // 请求中的文件数
+	RejectedFiles int    `gorm:"not null;column:rejected_files" json:"rejected_files"` // 校验未通过、没有创建任务的文件数
+}
+
+func (v *VideoUploadBatchPo) TableName() string {
+	return "video_upload_batch"
+}
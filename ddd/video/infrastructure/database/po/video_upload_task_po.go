@@ -11,6 +11,16 @@ type VideoUploadTaskPo struct {
 	ErrorMsg    string     `json:"error_msg"`    // 任务失败情况
 	CompletedAt *time.Time `json:"completed_at"` // 完成时间
 	StoragePath string     `json:"storage_path"` //  Minio存储唯一对象名字
+
+	ChecksumMD5    string `json:"checksum_md5"`            // 上传内容MD5（十六进制）
+	ChecksumSHA256 string `json:"checksum_sha256"`         // 上传内容SHA-256（十六进制）
+	ETag           string `gorm:"column:etag" json:"etag"` // 存储返回的ETag
+
+	BatchUUID string `gorm:"index;size:36;column:batch_uuid" json:"batch_uuid"` // 所属批量上传，单个上传为空
+
+	SourceURL     string `gorm:"size:2048;column:source_url" json:"source_url"` // 远程导入的源地址，本地上传为空
+	BytesReceived int64  `json:"bytes_received"`                                // 已接收字节数
+	BytesTotal    int64  `json:"bytes_total"`                                   // 总字节数，-1表示未知
 }
 
 func (v *VideoUploadTaskPo) TableName() string {
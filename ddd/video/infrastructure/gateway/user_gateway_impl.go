@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+	userService "go-video/ddd/user/domain/service"
+	"go-video/ddd/video/domain/gateway"
+	"go-video/pkg/errno"
+)
+
+// userGatewayImpl 通过用户领域服务访问用户上下文
+type userGatewayImpl struct {
+	userService *userService.UserService
+}
+
+// NewUserGateway 创建用户防腐层实例
+func NewUserGateway() gateway.UserGateway {
+	return &userGatewayImpl{
+		userService: userService.DefaultUserService(),
+	}
+}
+
+// FindUUIDByUsername 根据用户名查找用户UUID
+func (g *userGatewayImpl) FindUUIDByUsername(ctx context.Context, username string) (string, error) {
+	user, err := g.userService.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errno.AssertBizError(err).Code() == errno.ErrNotFound.Code {
+			return "", nil
+		}
+		return "", err
+	}
+	return user.UUID(), nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"go-video/ddd/video/domain/vo"
-	"go-video/ddd/video/infrastructure/database/persistence"
+	"go-video/pkg/envelope"
 	"go-video/pkg/logger"
+	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,12 +17,14 @@ import (
 
 	"go-video/ddd/internal/resource"
 	"go-video/ddd/video/domain/gateway"
-	"go-video/ddd/video/domain/repo"
 	"go-video/pkg/assert"
 
 	"github.com/minio/minio-go/v7"
 )
 
+// streamPartSize 长度未知的流式上传的分片大小
+const streamPartSize = 16 << 20
+
 var (
 	minioServiceOnce      sync.Once
 	singletonMinioService gateway.MinioService
@@ -28,60 +32,62 @@ var (
 
 type MinioServiceImpl struct {
 	minioClient *resource.MinioResource
-	videoRepo   repo.VideoRepository
+	// keyring 主密钥环，未配置加密时为nil
+	keyring *envelope.Keyring
+	// encryptUploads 新上传的视频是否加密
+	encryptUploads bool
+	chunkSize      int
 }
 
 func DefaultMinioService() gateway.MinioService {
 	assert.NotCircular()
 	minioServiceOnce.Do(func() {
+		keyring, encryptUploads, chunkSize := mustNewKeyring()
 		singletonMinioService = &MinioServiceImpl{
-			minioClient: resource.DefaultMinioResource(),
-			videoRepo:   persistence.NewVideoRepository(),
+			minioClient:    resource.DefaultMinioResource(),
+			keyring:        keyring,
+			encryptUploads: encryptUploads,
+			chunkSize:      chunkSize,
 		}
 	})
 	return singletonMinioService
 }
 
-func (m *MinioServiceImpl) SyncUploadVideo(ctx context.Context, videoUploadVo *vo.VideoUploadVO) {
+func (m *MinioServiceImpl) SyncUploadVideo(ctx context.Context, videoUploadVo *vo.VideoUploadVO) (vo.UploadReceipt, error) {
 	// 确保MinIO资源已初始化
 	m.minioClient.MustOpen()
 	file := videoUploadVo.File()
 	src, err := file.Open()
-	defer src.Close()
 	if err != nil {
-		logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
-		err = m.videoRepo.UpdateVideoStatus(ctx, videoUploadVo.VideoUUID(), vo.VideoStatusFailed, videoUploadVo.TaskUUID(), vo.VideoUploadTaskStatusFailed)
-		if err != nil {
-			logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo UpdateVideoStatusFailed Failed user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
-		}
-		return
+		logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.UserUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
+		return vo.UploadReceipt{}, err
 	}
-	// 获取内容类型
-	contentType := m.getContentType()
-	client := m.minioClient.GetClient()
-	bucketName := m.minioClient.GetBucketName()
+	defer src.Close()
 	logger.Info(fmt.Sprintf("StoragePath : %v", videoUploadVo.StoragePath()))
-	_, err = client.PutObject(ctx, bucketName, videoUploadVo.StoragePath(), src, file.Size, minio.PutObjectOptions{
-		ContentType: contentType,
-	})
+	receipt, err := m.putVideoObject(ctx, videoUploadVo.StoragePath(), src, file.Size, m.getContentType(), videoUploadVo.ExpectedChecksum())
 	if err != nil {
 		logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo "+
-			"user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
-		err = m.videoRepo.UpdateVideoStatus(ctx, videoUploadVo.VideoUUID(), vo.VideoStatusFailed, videoUploadVo.TaskUUID(), vo.VideoUploadTaskStatusFailed)
-		if err != nil {
-			logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo UpdateVideoStatusFailed Failed user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
-		}
-		return
+			"user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.UserUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
+		return vo.UploadReceipt{}, err
 	}
-	err = m.videoRepo.UpdateVideoStatus(ctx, videoUploadVo.VideoUUID(), vo.VideoStatusCompleted, videoUploadVo.TaskUUID(), vo.VideoUploadTaskStatusCompleted)
+	return receipt, nil
+}
+
+// UploadStream 以流的方式上传源文件
+func (m *MinioServiceImpl) UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64, expected vo.Checksum) (vo.UploadReceipt, error) {
+	// 确保MinIO资源已初始化
+	m.minioClient.MustOpen()
+
+	receipt, err := m.putVideoObject(ctx, objectName, reader, size, m.getContentType(), expected)
 	if err != nil {
-		logger.Error(fmt.Sprintf("MinioServiceImpl SyncUploadVideo UpdateVideoStatusFailed completed user_uuid: %v, video_uuid: %v ,task_uuid %v, error: %v", videoUploadVo.VideoUUID(), videoUploadVo.VideoUUID(), videoUploadVo.TaskUUID(), err.Error()))
+		logger.Error(fmt.Sprintf("MinioServiceImpl UploadStream object: %v, error: %v", objectName, err.Error()))
+		return vo.UploadReceipt{}, err
 	}
-
+	return receipt, nil
 }
 
 // UploadVideo 上传视频文件
-func (m *MinioServiceImpl) UploadVideo(ctx context.Context, userUUID string, file *multipart.FileHeader) (string, error) {
+func (m *MinioServiceImpl) UploadVideo(ctx context.Context, userUUID string, file *multipart.FileHeader, expected vo.Checksum) (vo.UploadReceipt, error) {
 	// 确保MinIO资源已初始化
 	m.minioClient.MustOpen()
 
@@ -89,7 +95,7 @@ func (m *MinioServiceImpl) UploadVideo(ctx context.Context, userUUID string, fil
 	src, err := file.Open()
 	if err != nil {
 		logger.Info("MinioServiceImpl file open error: " + err.Error())
-		return "", err
+		return vo.UploadReceipt{}, err
 	}
 	defer src.Close()
 
@@ -97,53 +103,262 @@ func (m *MinioServiceImpl) UploadVideo(ctx context.Context, userUUID string, fil
 	fileUuid := uuid.NewString()
 	objectName := m.GenerateObjectName(userUUID, fileUuid)
 
-	// 获取内容类型
-	contentType := m.getContentType()
-
 	// 上传文件到MinIO
+	receipt, err := m.putVideoObject(ctx, objectName, src, file.Size, m.getContentType(), expected)
+	if err != nil {
+		logger.Info("MinioServiceImpl bucketName upload err: " + err.Error())
+		return vo.UploadReceipt{}, err
+	}
+	return receipt, nil
+}
+
+// putVideoObject 上传视频源文件和包含视频画面的衍生对象，边上传边计算明文校验和，与期望值不一致时删除对象。
+// 开启加密时为对象生成独立的数据密钥并分块加密，被包装的数据密钥写入对象元数据
+func (m *MinioServiceImpl) putVideoObject(ctx context.Context, objectName string, src io.Reader, size int64, contentType string,
+	expected vo.Checksum) (vo.UploadReceipt, error) {
 	client := m.minioClient.GetClient()
 	bucketName := m.minioClient.GetBucketName()
-	_, err = client.PutObject(ctx, bucketName, objectName, src, file.Size, minio.PutObjectOptions{
+	opts := minio.PutObjectOptions{
 		ContentType: contentType,
-	})
+	}
+	hasher := vo.NewChecksumHasher()
+	var reader io.Reader = io.TeeReader(src, hasher)
+	uploadSize := size
+
+	if m.encryptUploads {
+		dataKey, keyID, wrappedKey, err := m.keyring.GenerateDataKey()
+		if err != nil {
+			return vo.UploadReceipt{}, err
+		}
+		reader, err = envelope.NewEncryptReader(reader, dataKey, m.chunkSize)
+		if err != nil {
+			return vo.UploadReceipt{}, err
+		}
+		env := &objectEnvelope{keyID: keyID, wrappedKey: wrappedKey, chunkSize: m.chunkSize}
+		opts.UserMetadata = env.metadata()
+		if size >= 0 {
+			uploadSize = envelope.EncryptedSize(size, m.chunkSize)
+		}
+	}
+
+	if uploadSize < 0 {
+		// 长度未知时SDK按最大对象大小计算分片并在内存中缓冲整个分片，这里限制分片大小
+		opts.PartSize = streamPartSize
+	}
+
+	info, err := client.PutObject(ctx, bucketName, objectName, reader, uploadSize, opts)
 	if err != nil {
-		logger.Info("MinioServiceImpl bucketName upload err: " + err.Error())
-		return "", err
+		return vo.UploadReceipt{}, err
 	}
-	return objectName, nil
+	actual := hasher.Sum()
+	if err := expected.Verify(actual); err != nil {
+		if delErr := client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{}); delErr != nil {
+			logger.Error(fmt.Sprintf("MinioServiceImpl remove mismatched object: %v, error: %v", objectName, delErr.Error()))
+		}
+		return vo.UploadReceipt{}, err
+	}
+	return vo.NewUploadReceipt(objectName, actual, info.ETag), nil
 }
 
-// DownloadVideo 下载视频文件
-func (m *MinioServiceImpl) DownloadVideo(ctx context.Context, objectName string) ([]byte, error) {
+// PutObject 上传任意对象
+func (m *MinioServiceImpl) PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
 	// 确保MinIO资源已初始化
 	m.minioClient.MustOpen()
 
-	// 从MinIO下载文件
 	client := m.minioClient.GetClient()
 	bucketName := m.minioClient.GetBucketName()
-	object, err := client.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	_, err := client.PutObject(ctx, bucketName, objectName, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("MinioServiceImpl PutObject object: %v, error: %v", objectName, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// PutMediaObject 与源文件共用加密上传流程，不校验校验和
+func (m *MinioServiceImpl) PutMediaObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	// 确保MinIO资源已初始化
+	m.minioClient.MustOpen()
+
+	if _, err := m.putVideoObject(ctx, objectName, reader, size, contentType, vo.Checksum{}); err != nil {
+		logger.Error(fmt.Sprintf("MinioServiceImpl PutMediaObject object: %v, error: %v", objectName, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DownloadVideo 下载视频文件，加密对象透明解密
+func (m *MinioServiceImpl) DownloadVideo(ctx context.Context, objectName string) ([]byte, error) {
+	stat, err := m.StatObject(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := m.OpenObject(ctx, objectName, 0, stat.Size())
 	if err != nil {
 		return nil, err
 	}
-	defer object.Close()
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
 
-	// 读取文件内容
-	data := make([]byte, 0)
-	buffer := make([]byte, 1024)
-	for {
-		n, err := object.Read(buffer)
-		if n > 0 {
-			data = append(data, buffer[:n]...)
-		}
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
+// StatObject 获取对象的明文属性
+func (m *MinioServiceImpl) StatObject(ctx context.Context, objectName string) (vo.ObjectStat, error) {
+	info, env, err := m.statEnvelope(ctx, objectName)
+	if err != nil {
+		return vo.ObjectStat{}, err
+	}
+	if env == nil {
+		return vo.NewObjectStat(info.Size, info.ContentType, "", info.ETag), nil
+	}
+	plainSize, err := envelope.PlainSize(info.Size, env.chunkSize)
+	if err != nil {
+		return vo.ObjectStat{}, err
+	}
+	return vo.NewObjectStat(plainSize, info.ContentType, env.keyID, info.ETag), nil
+}
+
+// OpenObject 读取对象明文 [offset, offset+length)，加密对象只获取覆盖该区间的密文分块
+func (m *MinioServiceImpl) OpenObject(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error) {
+	if length <= 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	info, env, err := m.statEnvelope(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	client := m.minioClient.GetClient()
+	bucketName := m.minioClient.GetBucketName()
+	opts := minio.GetObjectOptions{}
+	if env == nil {
+		if err := opts.SetRange(offset, offset+length-1); err != nil {
 			return nil, err
 		}
+		return client.GetObject(ctx, bucketName, objectName, opts)
+	}
+
+	if m.keyring == nil {
+		return nil, fmt.Errorf("object %s is encrypted but no master key is configured", objectName)
 	}
+	dataKey, err := m.keyring.Unwrap(env.keyID, env.wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	plainSize, err := envelope.PlainSize(info.Size, env.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	cipherRange, err := envelope.RangeFor(offset, length, plainSize, env.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.SetRange(cipherRange.Offset, cipherRange.Offset+cipherRange.Length-1); err != nil {
+		return nil, err
+	}
+	object, err := client.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := envelope.NewRangeReader(object, dataKey, env.chunkSize, plainSize, cipherRange, length)
+	if err != nil {
+		object.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: reader, Closer: object}, nil
+}
+
+// ActiveKeyID 当前主密钥ID，未配置加密时为空
+func (m *MinioServiceImpl) ActiveKeyID() string {
+	if m.keyring == nil {
+		return ""
+	}
+	return m.keyring.ActiveKeyID()
+}
+
+// WrapKey 用当前主密钥包装密钥
+func (m *MinioServiceImpl) WrapKey(key []byte) (string, []byte, error) {
+	if m.keyring == nil {
+		return "", nil, fmt.Errorf("no master key is configured")
+	}
+	return m.keyring.Wrap(key)
+}
 
-	return data, nil
+// UnwrapKey 解开被包装的密钥，包装使用的主密钥已下线时返回错误
+func (m *MinioServiceImpl) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	if m.keyring == nil {
+		return nil, fmt.Errorf("no master key is configured")
+	}
+	return m.keyring.Unwrap(keyID, wrapped)
+}
+
+// RewrapObjectKey 用当前主密钥重新包装对象的数据密钥。只改写元数据（服务端复制），不重新加密数据；
+// 复制以ETag为条件，避免覆盖并发写入的新对象
+func (m *MinioServiceImpl) RewrapObjectKey(ctx context.Context, objectName string) (vo.RewrapOutcome, error) {
+	if m.keyring == nil {
+		return vo.RewrapOutcome{}, fmt.Errorf("no master key is configured")
+	}
+	info, env, err := m.statEnvelope(ctx, objectName)
+	if err != nil {
+		return vo.RewrapOutcome{}, err
+	}
+	if env == nil {
+		return vo.RewrapOutcomePlaintext, nil
+	}
+	if env.keyID == m.keyring.ActiveKeyID() {
+		return vo.RewrapOutcomeCurrent, nil
+	}
+	dataKey, err := m.keyring.Unwrap(env.keyID, env.wrappedKey)
+	if err != nil {
+		return vo.RewrapOutcome{}, err
+	}
+	keyID, wrappedKey, err := m.keyring.Wrap(dataKey)
+	if err != nil {
+		return vo.RewrapOutcome{}, err
+	}
+	rewrapped := &objectEnvelope{keyID: keyID, wrappedKey: wrappedKey, chunkSize: env.chunkSize}
+
+	bucketName := m.minioClient.GetBucketName()
+	_, err = m.minioClient.GetClient().ComposeObject(ctx,
+		minio.CopyDestOptions{
+			Bucket:          bucketName,
+			Object:          objectName,
+			UserMetadata:    rewrapped.metadata(),
+			ReplaceMetadata: true,
+			ContentType:     info.ContentType,
+		},
+		minio.CopySrcOptions{
+			Bucket:    bucketName,
+			Object:    objectName,
+			MatchETag: info.ETag,
+		})
+	if err != nil {
+		logger.Error(fmt.Sprintf("MinioServiceImpl RewrapObjectKey object: %v, error: %v", objectName, err.Error()))
+		return vo.RewrapOutcome{}, err
+	}
+	return vo.RewrapOutcomeRewrapped, nil
+}
+
+// statEnvelope 获取对象信息和加密信封，未加密对象的信封为nil
+func (m *MinioServiceImpl) statEnvelope(ctx context.Context, objectName string) (minio.ObjectInfo, *objectEnvelope, error) {
+	// 确保MinIO资源已初始化
+	m.minioClient.MustOpen()
+
+	info, err := m.minioClient.GetClient().StatObject(ctx, m.minioClient.GetBucketName(), objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, nil, err
+	}
+	env, err := parseEnvelope(info.UserMetadata)
+	if err != nil {
+		return minio.ObjectInfo{}, nil, fmt.Errorf("object %s: %w", objectName, err)
+	}
+	return info, env, nil
+}
+
+// readCloser 组合解密Reader和底层对象的Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // DeleteVideo 删除视频文件
@@ -157,15 +372,49 @@ func (m *MinioServiceImpl) DeleteVideo(ctx context.Context, objectName string) e
 	return client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
 }
 
+// MoveObject 移动对象：复制时不替换元数据，加密对象仍能用原来的信封解密；
+// 复制以ETag为条件，避免把并发写入的新对象当作原对象删除
+func (m *MinioServiceImpl) MoveObject(ctx context.Context, src, dst string) error {
+	// 确保MinIO资源已初始化
+	m.minioClient.MustOpen()
+
+	client := m.minioClient.GetClient()
+	bucketName := m.minioClient.GetBucketName()
+	info, err := client.StatObject(ctx, bucketName, src, minio.StatObjectOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = client.ComposeObject(ctx,
+		minio.CopyDestOptions{
+			Bucket: bucketName,
+			Object: dst,
+		},
+		minio.CopySrcOptions{
+			Bucket:    bucketName,
+			Object:    src,
+			MatchETag: info.ETag,
+		})
+	if err != nil {
+		logger.Error(fmt.Sprintf("MinioServiceImpl MoveObject object: %v -> %v, error: %v", src, dst, err.Error()))
+		return err
+	}
+	return client.RemoveObject(ctx, bucketName, src, minio.RemoveObjectOptions{})
+}
+
 // GetVideoURL 获取视频访问URL
 func (m *MinioServiceImpl) GetVideoURL(ctx context.Context, objectName string) (string, error) {
+	// 生成预签名URL（1小时有效期）
+	return m.GetObjectURL(ctx, objectName, 3600*time.Second)
+}
+
+// GetObjectURL 获取指定有效期的预签名访问URL
+func (m *MinioServiceImpl) GetObjectURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
 	// 确保MinIO资源已初始化
 	m.minioClient.MustOpen()
 
-	// 生成预签名URL（1小时有效期）
 	client := m.minioClient.GetClient()
 	bucketName := m.minioClient.GetBucketName()
-	url, err := client.PresignedGetObject(ctx, bucketName, objectName, 3600*time.Second, nil)
+	url, err := client.PresignedGetObject(ctx, bucketName, objectName, expiry, nil)
 	if err != nil {
 		return "", err
 	}
@@ -173,6 +422,24 @@ func (m *MinioServiceImpl) GetVideoURL(ctx context.Context, objectName string) (
 	return url.String(), nil
 }
 
+// ListObjects 递归列出指定前缀下的全部对象
+func (m *MinioServiceImpl) ListObjects(ctx context.Context, prefix string) ([]vo.StoredObject, error) {
+	// 确保MinIO资源已初始化
+	m.minioClient.MustOpen()
+
+	client := m.minioClient.GetClient()
+	bucketName := m.minioClient.GetBucketName()
+	var objects []vo.StoredObject
+	for info := range client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			logger.Error(fmt.Sprintf("MinioServiceImpl ListObjects prefix: %v, error: %v", prefix, info.Err.Error()))
+			return nil, info.Err
+		}
+		objects = append(objects, vo.NewStoredObject(info.Key, info.Size, info.LastModified))
+	}
+	return objects, nil
+}
+
 // GenerateObjectName 生成对象名称（按年-月-日）
 func (m *MinioServiceImpl) GenerateObjectName(userUUID, filename string) string {
 	// 格式化为 yyyy-MM-dd
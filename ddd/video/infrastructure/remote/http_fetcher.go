@@ -0,0 +1,150 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/netguard"
+)
+
+const (
+	// defaultMaxSize 未配置时单个导入文件的大小上限
+	defaultMaxSize = 2 << 30
+	// defaultTimeout 未配置时单次导入的总超时
+	defaultTimeout = 30 * time.Minute
+)
+
+var (
+	remoteFetcherOnce      sync.Once
+	singletonRemoteFetcher gateway.RemoteFetcher
+)
+
+// HTTPFetcher 通过HTTP(S)下载远程文件，所有请求（包括重定向）都经过访问策略校验
+type HTTPFetcher struct {
+	policy  *netguard.Policy
+	client  *http.Client
+	maxSize int64
+}
+
+// DefaultRemoteFetcher 获取按全局配置创建的远程文件下载实例
+func DefaultRemoteFetcher() gateway.RemoteFetcher {
+	assert.NotCircular()
+	remoteFetcherOnce.Do(func() {
+		singletonRemoteFetcher = mustNewHTTPFetcher()
+	})
+	assert.NotNil(singletonRemoteFetcher)
+	return singletonRemoteFetcher
+}
+
+// NewHTTPFetcher 创建远程文件下载实例，maxSize为单个文件大小上限，timeout为整个请求（含读取响应体）的超时
+func NewHTTPFetcher(policy *netguard.Policy, maxSize int64, timeout time.Duration) *HTTPFetcher {
+	return &HTTPFetcher{
+		policy:  policy,
+		client:  policy.Client(timeout),
+		maxSize: maxSize,
+	}
+}
+
+func mustNewHTTPFetcher() *HTTPFetcher {
+	var importConfig config.ImportConfig
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		importConfig = cfg.Import
+	}
+	policy, err := netguard.NewPolicy(importConfig.AllowedSchemes, importConfig.AllowedHosts, importConfig.AllowedNetworks)
+	if err != nil {
+		panic(fmt.Sprintf("invalid import config: %v", err))
+	}
+	maxSize, timeout := importConfig.MaxSize, importConfig.Timeout
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return NewHTTPFetcher(policy, maxSize, timeout)
+}
+
+// CheckURL 校验URL格式、协议和主机名
+func (f *HTTPFetcher) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &netguard.DeniedError{Target: rawURL, Reason: err.Error()}
+	}
+	return f.policy.CheckURL(u)
+}
+
+// Open 发起GET请求，非2xx响应和声明长度超过上限时直接返回错误；
+// 未声明长度或声明不实时，读取超过上限的响应体返回 vo.ErrRemoteFileTooLarge
+func (f *HTTPFetcher) Open(ctx context.Context, rawURL string) (vo.RemoteFile, io.ReadCloser, error) {
+	if err := f.CheckURL(rawURL); err != nil {
+		return vo.RemoteFile{}, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return vo.RemoteFile{}, nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return vo.RemoteFile{}, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return vo.RemoteFile{}, nil, fmt.Errorf("remote server responded %s", resp.Status)
+	}
+	if resp.ContentLength > f.maxSize {
+		resp.Body.Close()
+		return vo.RemoteFile{}, nil, vo.ErrRemoteFileTooLarge
+	}
+	file := vo.NewRemoteFile(resp.Request.URL.String(), remoteFilename(resp), resp.Header.Get("Content-Type"), resp.ContentLength)
+	return file, &limitedBody{body: resp.Body, remaining: f.maxSize}, nil
+}
+
+// remoteFilename 优先使用 Content-Disposition 中的文件名，其次是最终URL路径的最后一段
+func remoteFilename(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if filename := path.Base(params["filename"]); filename != "." && filename != "/" {
+			return filename
+		}
+	}
+	if filename := path.Base(resp.Request.URL.Path); filename != "." && filename != "/" {
+		return filename
+	}
+	return ""
+}
+
+// limitedBody 读取超过上限时返回 vo.ErrRemoteFileTooLarge，而不是像 io.LimitReader 那样静默截断
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, vo.ErrRemoteFileTooLarge
+	}
+	// 多读1个字节用于判断是否超过上限
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, vo.ErrRemoteFileTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
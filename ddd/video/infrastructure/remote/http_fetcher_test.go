@@ -0,0 +1,204 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/netguard"
+)
+
+const testMaxSize = 64
+
+// startInternalServer 模拟内网服务：监听 127.0.0.2，记录收到的请求数
+func startInternalServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("cannot listen on 127.0.0.2: %v", err)
+	}
+	hits := &atomic.Int32{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, "secret")
+	}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+// startPublicServer 模拟允许访问的远程服务器，/redirect?to= 重定向到任意地址
+func startPublicServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/videos/clip.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		fmt.Fprint(w, "video content")
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="../../holiday.mov"`)
+		fmt.Fprint(w, "video content")
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", testMaxSize+1))
+	})
+	mux.HandleFunc("/exact", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", testMaxSize))
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		// 不声明长度，分多次写入
+		for i := 0; i <= testMaxSize/8; i++ {
+			fmt.Fprint(w, "xxxxxxxx")
+			w.(http.Flusher).Flush()
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestFetcher(t *testing.T, networks ...string) *HTTPFetcher {
+	t.Helper()
+	policy, err := netguard.NewPolicy([]string{"http"}, nil, networks)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	return NewHTTPFetcher(policy, testMaxSize, 5*time.Second)
+}
+
+func TestHTTPFetcherRedirects(t *testing.T) {
+	internal, hits := startInternalServer(t)
+	public := startPublicServer(t)
+	// 只把测试用的远程服务器所在的 127.0.0.1 加入白名单
+	fetcher := newTestFetcher(t, "127.0.0.1")
+	internalPort := internal.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name       string
+		to         string
+		wantDenied bool
+		wantURL    string
+	}{
+		{name: "internal server", to: internal.URL + "/admin", wantDenied: true},
+		{name: "cloud metadata", to: "http://169.254.169.254/latest/meta-data/", wantDenied: true},
+		{name: "private network", to: "http://10.0.0.1/", wantDenied: true},
+		{name: "ipv6 loopback", to: fmt.Sprintf("http://[::1]:%d/", internalPort), wantDenied: true},
+		{name: "ipv4-mapped ipv6", to: fmt.Sprintf("http://[::ffff:127.0.0.2]:%d/", internalPort), wantDenied: true},
+		{name: "unspecified address", to: fmt.Sprintf("http://0.0.0.0:%d/", internalPort), wantDenied: true},
+		{name: "disallowed scheme", to: "file:///etc/passwd", wantDenied: true},
+		{name: "allowed target", to: public.URL + "/videos/clip.mp4", wantURL: public.URL + "/videos/clip.mp4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, body, err := fetcher.Open(context.Background(), public.URL+"/redirect?to="+tt.to)
+			var denied *netguard.DeniedError
+			if tt.wantDenied {
+				if !errors.As(err, &denied) {
+					t.Fatalf("Open error = %v, want *netguard.DeniedError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer body.Close()
+			if file.URL() != tt.wantURL || file.Filename() != "clip.mp4" || file.ContentType() != "video/mp4" {
+				t.Fatalf("Open = url %q filename %q type %q", file.URL(), file.Filename(), file.ContentType())
+			}
+		})
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("internal server received %d requests", n)
+	}
+
+	t.Run("too many redirects", func(t *testing.T) {
+		if _, _, err := fetcher.Open(context.Background(), public.URL+"/loop"); err == nil {
+			t.Fatal("Open should fail")
+		}
+	})
+}
+
+func TestHTTPFetcherChecksResolvedAddress(t *testing.T) {
+	public := startPublicServer(t)
+	// 不允许任何内网网段：主机名通过 CheckURL，但解析出的回环地址在连接时被拒绝
+	fetcher := newTestFetcher(t)
+	port := public.Listener.Addr().(*net.TCPAddr).Port
+	target := fmt.Sprintf("http://localhost:%d/videos/clip.mp4", port)
+	if err := fetcher.CheckURL(target); err != nil {
+		t.Fatalf("CheckURL(%q) = %v, hostnames are checked when connecting", target, err)
+	}
+	var denied *netguard.DeniedError
+	if _, _, err := fetcher.Open(context.Background(), target); !errors.As(err, &denied) {
+		t.Fatalf("Open error = %v, want *netguard.DeniedError", err)
+	}
+	if _, _, err := fetcher.Open(context.Background(), public.URL+"/videos/clip.mp4"); !errors.As(err, &denied) {
+		t.Fatalf("Open IP literal error = %v, want *netguard.DeniedError", err)
+	}
+}
+
+func TestHTTPFetcherOpen(t *testing.T) {
+	public := startPublicServer(t)
+	fetcher := newTestFetcher(t, "127.0.0.1")
+	tests := []struct {
+		name         string
+		path         string
+		wantOpenErr  bool
+		wantErr      error
+		wantFilename string
+		wantBody     string
+	}{
+		{name: "content disposition filename", path: "/download", wantFilename: "holiday.mov", wantBody: "video content"},
+		{name: "not found", path: "/missing", wantOpenErr: true},
+		{name: "declared length too large", path: "/large", wantOpenErr: true, wantErr: vo.ErrRemoteFileTooLarge},
+		{name: "exactly the limit", path: "/exact", wantFilename: "exact", wantBody: strings.Repeat("x", testMaxSize)},
+		{name: "undeclared length too large", path: "/chunked", wantErr: vo.ErrRemoteFileTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, body, err := fetcher.Open(context.Background(), public.URL+tt.path)
+			if tt.wantOpenErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Open error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer body.Close()
+			content, err := io.ReadAll(body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("read error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if file.Filename() != tt.wantFilename || string(content) != tt.wantBody {
+				t.Fatalf("Open = filename %q body %q", file.Filename(), content)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+)
+
+const (
+	// driverClamd 通过 clamd 扫描
+	driverClamd = "clamd"
+	// defaultNetwork 未配置时连接 clamd 的网络类型
+	defaultNetwork = "tcp"
+	// defaultAddress 未配置时 clamd 的地址
+	defaultAddress = "127.0.0.1:3310"
+	// defaultChunkSize 未配置时 INSTREAM 每块的字节数，clamd 的 StreamMaxLength 限制的是总长度
+	defaultChunkSize = 64 << 10
+	// maxReplySize clamd 回复的最大长度
+	maxReplySize = 4096
+	// sizeLimitReply 超过 StreamMaxLength 时 clamd 的回复
+	sizeLimitReply = "INSTREAM size limit exceeded"
+)
+
+// errReadContent 读取待扫描的内容失败，此时没有发送结束块，clamd 不会回复
+var errReadContent = errors.New("read content")
+
+var (
+	scannerOnce      sync.Once
+	singletonScanner gateway.Scanner
+)
+
+// DefaultScanner 获取按全局配置创建的扫描实例，driver 为 clamd 时使用 ClamdScanner，否则不扫描
+func DefaultScanner() gateway.Scanner {
+	assert.NotCircular()
+	scannerOnce.Do(func() {
+		var scanConfig config.ScanConfig
+		if cfg := config.GetGlobalConfig(); cfg != nil {
+			scanConfig = cfg.Scan
+		}
+		if scanConfig.Driver != driverClamd {
+			singletonScanner = NewNoopScanner()
+			return
+		}
+		singletonScanner = NewClamdScanner(scanConfig.Network, scanConfig.Address, scanConfig.ChunkSize, scanConfig.MaxSize)
+	})
+	assert.NotNil(singletonScanner)
+	return singletonScanner
+}
+
+// ClamdScanner 以 INSTREAM 命令把内容发给 clamd 扫描，每次扫描使用一个新连接
+type ClamdScanner struct {
+	network   string
+	address   string
+	chunkSize int
+	maxSize   int64
+}
+
+// NewClamdScanner 创建 clamd 扫描实例，network 为 tcp 或 unix，未配置的参数使用默认值；
+// maxSize 应与 clamd 的 StreamMaxLength 一致，为0时不在本地检查
+func NewClamdScanner(network, address string, chunkSize int, maxSize int64) *ClamdScanner {
+	if network == "" {
+		network = defaultNetwork
+	}
+	if address == "" {
+		address = defaultAddress
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	return &ClamdScanner{
+		network:   network,
+		address:   address,
+		chunkSize: chunkSize,
+		maxSize:   maxSize,
+	}
+}
+
+// Scan 按 clamd 的 INSTREAM 协议发送 "zINSTREAM\0"、若干个以4字节大端长度开头的块和长度为0的结束块，
+// 然后读取以\0结尾的回复："stream: OK" 为干净，"stream: <特征名称> FOUND" 为感染，以 ERROR 结尾为扫描失败。
+// 超过 StreamMaxLength 时 clamd 回复错误并关闭连接，此时写入失败，以回复中的错误为准；
+// 读取内容失败或超过 maxSize 时 clamd 不会回复，直接返回
+func (s *ClamdScanner) Scan(ctx context.Context, reader io.Reader) (vo.ScanResult, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return vo.ScanResult{}, fmt.Errorf("connect clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return vo.ScanResult{}, err
+		}
+	}
+	// ctx 没有截止时间时也要能被取消：取消后让阻塞的读写立即返回
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	sendErr := s.send(conn, reader)
+	if errors.Is(sendErr, errReadContent) || errors.Is(sendErr, gateway.ErrScanTooLarge) {
+		return vo.ScanResult{}, sendErr
+	}
+	reply, readErr := readReply(conn)
+	if ctx.Err() != nil {
+		return vo.ScanResult{}, ctx.Err()
+	}
+	if readErr != nil {
+		if sendErr != nil {
+			return vo.ScanResult{}, fmt.Errorf("send stream to clamd: %w", sendErr)
+		}
+		return vo.ScanResult{}, fmt.Errorf("read clamd reply: %w", readErr)
+	}
+	result, err := parseReply(reply)
+	if err != nil {
+		return vo.ScanResult{}, err
+	}
+	if sendErr != nil {
+		return vo.ScanResult{}, fmt.Errorf("send stream to clamd: %w", sendErr)
+	}
+	return result, nil
+}
+
+// send 发送 INSTREAM 命令和全部内容，读取 reader 失败或超过 maxSize 时不发送结束块，clamd 不会给出结论
+func (s *ClamdScanner) send(conn net.Conn, reader io.Reader) error {
+	writer := bufio.NewWriterSize(conn, s.chunkSize+4)
+	if _, err := writer.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+	chunk := make([]byte, s.chunkSize)
+	var length [4]byte
+	var total int64
+	for {
+		n, err := io.ReadFull(reader, chunk)
+		if total += int64(n); s.maxSize > 0 && total > s.maxSize {
+			return fmt.Errorf("%w: more than %d bytes", gateway.ErrScanTooLarge, s.maxSize)
+		}
+		if n > 0 {
+			binary.BigEndian.PutUint32(length[:], uint32(n))
+			if _, werr := writer.Write(length[:]); werr != nil {
+				return werr
+			}
+			if _, werr := writer.Write(chunk[:n]); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", errReadContent, err)
+		}
+	}
+	binary.BigEndian.PutUint32(length[:], 0)
+	if _, err := writer.Write(length[:]); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// readReply 读取以\0结尾的回复，连接关闭前没有\0时以已读到的内容为准
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(io.LimitReader(conn, maxReplySize)).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimSuffix(reply, "\x00")), nil
+}
+
+// parseReply 解析 INSTREAM 的回复
+func parseReply(reply string) (vo.ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return vo.NewCleanScanResult(), nil
+	case strings.HasSuffix(result, " FOUND"):
+		signature := strings.TrimSpace(strings.TrimSuffix(result, " FOUND"))
+		if signature == "" {
+			return vo.ScanResult{}, fmt.Errorf("clamd reply without signature: %q", reply)
+		}
+		return vo.NewInfectedScanResult(signature), nil
+	case strings.HasPrefix(result, sizeLimitReply):
+		return vo.ScanResult{}, fmt.Errorf("%w: clamd StreamMaxLength exceeded", gateway.ErrScanTooLarge)
+	case strings.HasSuffix(result, " ERROR"):
+		return vo.ScanResult{}, fmt.Errorf("clamd: %s", strings.TrimSuffix(result, " ERROR"))
+	}
+	return vo.ScanResult{}, fmt.Errorf("unexpected clamd reply: %q", reply)
+}
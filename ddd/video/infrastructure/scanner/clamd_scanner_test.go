@@ -0,0 +1,250 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go-video/ddd/video/domain/gateway"
+)
+
+// fakeClamd 按 INSTREAM 协议接收内容的 clamd，收到结束块后回复 reply；
+// limit 大于0时收到超过 limit 字节的内容即回复超限，模拟 StreamMaxLength
+type fakeClamd struct {
+	listener net.Listener
+	reply    string
+	limit    int
+	received chan []byte
+}
+
+func startFakeClamd(t *testing.T, reply string, limit int) *fakeClamd {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	clamd := &fakeClamd{listener: listener, reply: reply, limit: limit, received: make(chan []byte, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go clamd.serve()
+	return clamd
+}
+
+func (c *fakeClamd) serve() {
+	conn, err := c.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	var content bytes.Buffer
+	defer func() { c.received <- content.Bytes() }()
+
+	command := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+	var length [4]byte
+	for {
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			// 没有结束块，clamd 不回复
+			return
+		}
+		n := binary.BigEndian.Uint32(length[:])
+		if n == 0 {
+			conn.Write([]byte(c.reply + "\x00"))
+			return
+		}
+		if _, err := io.CopyN(&content, conn, int64(n)); err != nil {
+			return
+		}
+		if c.limit > 0 && content.Len() > c.limit {
+			conn.Write([]byte(sizeLimitReply + ". ERROR\x00"))
+			// 读完剩余内容再关闭，避免未读数据触发 RST 使客户端读不到回复
+			io.Copy(io.Discard, conn)
+			return
+		}
+	}
+}
+
+// failingReader 读出 content 后返回 err
+type failingReader struct {
+	content io.Reader
+	err     error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, r.err
+	}
+	return n, err
+}
+
+func TestClamdScannerScan(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 100)
+	errDisk := errors.New("disk failure")
+	tests := []struct {
+		name          string
+		reply         string
+		limit         int
+		maxSize       int64
+		reader        io.Reader
+		wantInfected  bool
+		wantSignature string
+		wantErr       error
+		wantAnyErr    bool
+		wantReceived  []byte
+	}{
+		{
+			name:         "clean",
+			reply:        "stream: OK",
+			reader:       bytes.NewReader(content),
+			wantReceived: content,
+		},
+		{
+			name:          "found",
+			reply:         "stream: Eicar-Test-Signature FOUND",
+			reader:        bytes.NewReader(content),
+			wantInfected:  true,
+			wantSignature: "Eicar-Test-Signature",
+			wantReceived:  content,
+		},
+		{
+			name:       "error",
+			reply:      "stream: Can't allocate memory ERROR",
+			reader:     bytes.NewReader(content),
+			wantAnyErr: true,
+		},
+		{
+			name:       "unexpected reply",
+			reply:      "stream: maybe",
+			reader:     bytes.NewReader(content),
+			wantAnyErr: true,
+		},
+		{
+			name:    "clamd size limit",
+			reply:   "stream: OK",
+			limit:   100,
+			reader:  bytes.NewReader(content),
+			wantErr: gateway.ErrScanTooLarge,
+		},
+		{
+			name:    "local size limit",
+			reply:   "stream: OK",
+			maxSize: 100,
+			reader:  bytes.NewReader(content),
+			wantErr: gateway.ErrScanTooLarge,
+		},
+		{
+			name:    "read failure",
+			reply:   "stream: OK",
+			reader:  &failingReader{content: bytes.NewReader(content[:10]), err: errDisk},
+			wantErr: errDisk,
+		},
+		{
+			name:         "empty content",
+			reply:        "stream: OK",
+			reader:       bytes.NewReader(nil),
+			wantReceived: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clamd := startFakeClamd(t, tt.reply, tt.limit)
+			scanner := NewClamdScanner("tcp", clamd.listener.Addr().String(), 64, tt.maxSize)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			started := time.Now()
+			result, err := scanner.Scan(ctx, tt.reader)
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Fatalf("Scan took %s, should not wait for the deadline", elapsed)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Scan error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAnyErr:
+				if err == nil || errors.Is(err, gateway.ErrScanTooLarge) {
+					t.Fatalf("Scan error = %v, want a scan failure", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Scan error = %v", err)
+				}
+				if result.Infected() != tt.wantInfected || result.Signature() != tt.wantSignature {
+					t.Fatalf("Scan = infected %v signature %q, want %v %q", result.Infected(), result.Signature(),
+						tt.wantInfected, tt.wantSignature)
+				}
+			}
+			if tt.wantReceived != nil {
+				select {
+				case received := <-clamd.received:
+					if !bytes.Equal(received, tt.wantReceived) {
+						t.Fatalf("clamd received %d bytes, want %d", len(received), len(tt.wantReceived))
+					}
+				case <-time.After(time.Second):
+					t.Fatal("clamd did not finish")
+				}
+			}
+		})
+	}
+}
+
+func TestClamdScannerCanceled(t *testing.T) {
+	// 不回复的 clamd：收到结束块后保持连接
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+
+	scanner := NewClamdScanner("tcp", listener.Addr().String(), 0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if _, err := scanner.Scan(ctx, bytes.NewReader([]byte("content"))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Scan error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		reply         string
+		wantInfected  bool
+		wantSignature string
+		wantErr       bool
+	}{
+		{reply: "stream: OK"},
+		{reply: "OK"},
+		{reply: "stream: Win.Test.EICAR_HDB-1 FOUND", wantInfected: true, wantSignature: "Win.Test.EICAR_HDB-1"},
+		{reply: "stream:  FOUND", wantErr: true},
+		{reply: "INSTREAM size limit exceeded. ERROR", wantErr: true},
+		{reply: "stream: lstat() failed ERROR", wantErr: true},
+		{reply: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			result, err := parseReply(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseReply(%q) error = %v, wantErr %v", tt.reply, err, tt.wantErr)
+			}
+			if err == nil && (result.Infected() != tt.wantInfected || result.Signature() != tt.wantSignature) {
+				t.Fatalf("parseReply(%q) = infected %v signature %q", tt.reply, result.Infected(), result.Signature())
+			}
+		})
+	}
+}
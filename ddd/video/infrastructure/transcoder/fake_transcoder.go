@@ -0,0 +1,209 @@
+package transcoder
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-video/ddd/video/domain/vo"
+)
+
+// FakeTranscoder 不依赖ffmpeg的转码实现：输出为源文件的副本，媒体信息按档位计算。
+// 用于没有ffmpeg的开发环境和测试，Failures 中的档位转码时返回对应错误
+type FakeTranscoder struct {
+	// Source Probe 返回的源文件媒体信息
+	Source vo.MediaInfo
+	// Delay 每次转码的耗时，期间可以被取消
+	Delay time.Duration
+	// Failures 档位名称到转码错误的映射
+	Failures map[string]error
+}
+
+// fakeCodecs 模拟转码输出的编码
+const fakeCodecs = "avc1.64001f,mp4a.40.2"
+
+// NewFakeTranscoder 创建源文件为10秒1080p的模拟转码实例
+func NewFakeTranscoder() *FakeTranscoder {
+	return &FakeTranscoder{
+		Source: vo.NewMediaInfo(10*time.Second, 1920, 1080, fakeCodecs),
+	}
+}
+
+// Probe 返回 Source，文件不存在时返回错误
+func (t *FakeTranscoder) Probe(ctx context.Context, input string) (vo.MediaInfo, error) {
+	if _, err := os.Stat(input); err != nil {
+		return vo.MediaInfo{}, err
+	}
+	return t.Source, nil
+}
+
+// Transcode 复制源文件，按一半和全部时长各回调一次进度
+func (t *FakeTranscoder) Transcode(ctx context.Context, input, output string, profile vo.RenditionProfile,
+	progress func(processed time.Duration)) (vo.MediaInfo, error) {
+	if err, ok := t.Failures[profile.Name()]; ok {
+		return vo.MediaInfo{}, err
+	}
+	if progress != nil {
+		progress(t.Source.Duration() / 2)
+	}
+	select {
+	case <-ctx.Done():
+		return vo.MediaInfo{}, ctx.Err()
+	case <-time.After(t.Delay):
+	}
+	if err := copyFile(input, output); err != nil {
+		return vo.MediaInfo{}, err
+	}
+	if progress != nil {
+		progress(t.Source.Duration())
+	}
+	height := min(t.Source.Height(), profile.Height())
+	width := t.Source.Width()
+	if t.Source.Height() > 0 {
+		width = t.Source.Width() * height / t.Source.Height() / 2 * 2
+	}
+	return vo.NewMediaInfo(t.Source.Duration(), width, height, fakeCodecs), nil
+}
+
+// Segment 把整个文件按时长均分为分片
+func (t *FakeTranscoder) Segment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.MediaSegment, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	return t.split(data, outputDir, segmentDuration, func(i int) string {
+		return fmt.Sprintf("seg_%05d.ts", i)
+	})
+}
+
+// Fragment 把文件按字节分给视频（九成）和音频（一成）两条轨道，每条轨道各写一个初始化分片，
+// 其余字节像 Segment 一样按时长均分为媒体分片
+func (t *FakeTranscoder) Fragment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.FragmentedTrack, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	split := len(data) * 9 / 10
+	tracks := make([]vo.FragmentedTrack, 0, 2)
+	for _, track := range []struct {
+		contentType string
+		data        []byte
+	}{{vo.DASHVideo, data[:split]}, {vo.DASHAudio, data[split:]}} {
+		if err := os.WriteFile(filepath.Join(outputDir, vo.DASHInitialization(track.contentType)), nil, 0o644); err != nil {
+			return nil, err
+		}
+		segments, err := t.split(track.data, outputDir, segmentDuration, func(i int) string {
+			return vo.DASHSegment(track.contentType, i+1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, vo.NewFragmentedTrack(track.contentType, segments))
+	}
+	return tracks, nil
+}
+
+// Snapshot 写出一张按 Source 比例缩放的纯色图片，颜色随 offset 变化；offset 超出时长时返回错误
+func (t *FakeTranscoder) Snapshot(ctx context.Context, input, output string, offset time.Duration, width int) error {
+	if _, err := os.Stat(input); err != nil {
+		return err
+	}
+	if offset >= t.Source.Duration() {
+		return fmt.Errorf("fake snapshot: offset %s beyond duration %s", offset, t.Source.Duration())
+	}
+	return t.writeFrame(output, offset, width)
+}
+
+// Thumbnails 按 Source 时长每隔 interval 写出一张纯色图片
+func (t *FakeTranscoder) Thumbnails(ctx context.Context, input, outputDir string, interval time.Duration, width int) ([]string, error) {
+	if _, err := os.Stat(input); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	count := max(int((t.Source.Duration()+interval-1)/interval), 1)
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("thumb_%05d.jpg", i+1)
+		if err := t.writeFrame(filepath.Join(outputDir, name), time.Duration(i)*interval, width); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// writeFrame 写出代表 offset 处画面的纯色JPEG
+func (t *FakeTranscoder) writeFrame(output string, offset time.Duration, width int) error {
+	height := width * 9 / 16
+	if t.Source.Width() > 0 {
+		height = max(width*t.Source.Height()/t.Source.Width()/2*2, 2)
+	}
+	frame := image.NewRGBA(image.Rect(0, 0, width, height))
+	shade := uint8(offset * 255 / max(t.Source.Duration(), 1))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.RGBA{R: shade, G: 128, B: 255 - shade, A: 255}), image.Point{}, draw.Src)
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(file, frame, nil); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// split 把数据按字节均分为 Source 时长除以 segmentDuration 个分片，最后一个分片时长为余下的部分
+func (t *FakeTranscoder) split(data []byte, outputDir string, segmentDuration time.Duration, filename func(i int) string) ([]vo.MediaSegment, error) {
+	count := 1
+	if segmentDuration > 0 {
+		count = max(int((t.Source.Duration()+segmentDuration-1)/segmentDuration), 1)
+	}
+	segments := make([]vo.MediaSegment, 0, count)
+	remaining := t.Source.Duration()
+	for i := 0; i < count; i++ {
+		name := filename(i)
+		chunk := data[len(data)*i/count : len(data)*(i+1)/count]
+		if err := os.WriteFile(filepath.Join(outputDir, name), chunk, 0o644); err != nil {
+			return nil, err
+		}
+		duration := min(segmentDuration, remaining)
+		if i == count-1 {
+			duration = remaining
+		}
+		remaining -= duration
+		segments = append(segments, vo.NewMediaSegment(name, duration))
+	}
+	return segments, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
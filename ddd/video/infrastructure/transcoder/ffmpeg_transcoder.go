@@ -0,0 +1,446 @@
+package transcoder
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-video/ddd/video/domain/gateway"
+	"go-video/ddd/video/domain/vo"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+)
+
+const (
+	// driverFake 只复制源文件的转码实现
+	driverFake = "fake"
+	// stderrTailSize 转码失败时错误信息中保留的ffmpeg输出长度
+	stderrTailSize = 2048
+	// keyframeInterval 转码输出的关键帧间隔（秒），分片只能在关键帧处切分，各档位的关键帧对齐才能无缝切换码率
+	keyframeInterval = 2
+	// segmentListName 切分时ffmpeg写出的分片列表文件
+	segmentListName = "segments.csv"
+	// jpegQScale 截图的JPEG质量（ffmpeg -q:v，2-31，越小质量越高）
+	jpegQScale = 3
+)
+
+// h264ProfileIDC ffprobe 输出的H.264 profile 对应的 RFC 6381 profile_idc 和约束标志
+var h264ProfileIDC = map[string][2]int{
+	"Constrained Baseline": {0x42, 0xe0},
+	"Baseline":             {0x42, 0x00},
+	"Main":                 {0x4d, 0x00},
+	"High":                 {0x64, 0x00},
+}
+
+// aacObjectType ffprobe 输出的AAC profile 对应的 RFC 6381 对象类型
+var aacObjectType = map[string]int{
+	"LC":       2,
+	"HE-AAC":   5,
+	"HE-AACv2": 29,
+}
+
+var (
+	transcoderOnce      sync.Once
+	singletonTranscoder gateway.Transcoder
+)
+
+// DefaultTranscoder 获取按全局配置创建的转码实例，driver 为 fake 时使用 FakeTranscoder
+func DefaultTranscoder() gateway.Transcoder {
+	assert.NotCircular()
+	transcoderOnce.Do(func() {
+		var transcodeConfig config.TranscodeConfig
+		if cfg := config.GetGlobalConfig(); cfg != nil {
+			transcodeConfig = cfg.Transcode
+		}
+		if transcodeConfig.Driver == driverFake {
+			singletonTranscoder = NewFakeTranscoder()
+			return
+		}
+		singletonTranscoder = NewFFmpegTranscoder(transcodeConfig.FFmpegPath, transcodeConfig.FFprobePath)
+	})
+	assert.NotNil(singletonTranscoder)
+	return singletonTranscoder
+}
+
+// FFmpegTranscoder 调用ffmpeg/ffprobe进程转码，输出H.264/AAC的mp4
+type FFmpegTranscoder struct {
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegTranscoder 创建ffmpeg转码实例，路径为空时从PATH中查找
+func NewFFmpegTranscoder(ffmpegPath, ffprobePath string) *FFmpegTranscoder {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	if ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+	return &FFmpegTranscoder{
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+// probeStream ffprobe 输出的一条流
+type probeStream struct {
+	CodecType string `json:"codec_type"`
+	CodecName string `json:"codec_name"`
+	Profile   string `json:"profile"`
+	Level     int    `json:"level"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// probeOutput ffprobe -of json 的输出
+type probeOutput struct {
+	Streams []probeStream `json:"streams"`
+	Format  struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// stream 第一条指定类型的流，没有时返回nil
+func (p *probeOutput) stream(codecType string) *probeStream {
+	for i := range p.Streams {
+		if p.Streams[i].CodecType == codecType {
+			return &p.Streams[i]
+		}
+	}
+	return nil
+}
+
+// duration 文件时长，无法解析时为0
+func (p *probeOutput) duration() time.Duration {
+	seconds, err := strconv.ParseFloat(p.Format.Duration, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Probe 读取第一条视频流的分辨率、文件时长，以及第一条视频流和音频流的编码
+func (t *FFmpegTranscoder) Probe(ctx context.Context, input string) (vo.MediaInfo, error) {
+	probe, err := t.probe(ctx, input)
+	if err != nil {
+		return vo.MediaInfo{}, err
+	}
+	video := probe.stream("video")
+	if video == nil {
+		return vo.MediaInfo{}, fmt.Errorf("ffprobe: no video stream in %s", input)
+	}
+	return vo.NewMediaInfo(probe.duration(), video.Width, video.Height, rfc6381Codecs(video, probe.stream("audio"))), nil
+}
+
+// probe 执行ffprobe读取全部流和文件时长
+func (t *FFmpegTranscoder) probe(ctx context.Context, input string) (*probeOutput, error) {
+	cmd := exec.CommandContext(ctx, t.ffprobePath,
+		"-v", "error",
+		"-show_entries", "stream=codec_type,codec_name,profile,level,width,height:format=duration",
+		"-of", "json",
+		input)
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w: %s", err, stderr.String())
+	}
+	var probe probeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+	return &probe, nil
+}
+
+// rfc6381Codecs 生成 CODECS 属性，视频不是H.264或profile无法识别时返回空
+func rfc6381Codecs(video, audio *probeStream) string {
+	idc, ok := h264ProfileIDC[video.Profile]
+	if video.CodecName != "h264" || !ok || video.Level <= 0 {
+		return ""
+	}
+	codecs := fmt.Sprintf("avc1.%02x%02x%02x", idc[0], idc[1], video.Level)
+	if audio != nil && audio.CodecName == "aac" {
+		objectType, ok := aacObjectType[audio.Profile]
+		if !ok {
+			objectType = aacObjectType["LC"]
+		}
+		codecs += fmt.Sprintf(",mp4a.40.%d", objectType)
+	}
+	return codecs
+}
+
+// Transcode 缩放到档位高度（不放大）并按档位码率编码，进度从 -progress 输出中读取
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, input, output string, profile vo.RenditionProfile,
+	progress func(processed time.Duration)) (vo.MediaInfo, error) {
+	cmd := exec.CommandContext(ctx, t.ffmpegPath, transcodeArgs(input, output, profile)...)
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return vo.MediaInfo{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return vo.MediaInfo{}, fmt.Errorf("ffmpeg: %w", err)
+	}
+	readProgress(stdout, progress)
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return vo.MediaInfo{}, ctx.Err()
+		}
+		return vo.MediaInfo{}, fmt.Errorf("ffmpeg: %w: %s", err, stderr.String())
+	}
+	return t.Probe(ctx, output)
+}
+
+// Segment 切分为MPEG-TS分片，分片时长以ffmpeg写出的分片列表为准
+func (t *FFmpegTranscoder) Segment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.MediaSegment, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	listPath := filepath.Join(outputDir, segmentListName)
+	cmd := exec.CommandContext(ctx, t.ffmpegPath,
+		"-hide_banner", "-nostdin", "-y",
+		"-i", input,
+		"-map", "0", "-c", "copy", "-bsf:v", "h264_mp4toannexb",
+		"-f", "segment",
+		"-segment_time", strconv.FormatFloat(segmentDuration.Seconds(), 'f', 3, 64),
+		"-segment_format", "mpegts",
+		"-segment_list", listPath, "-segment_list_type", "csv",
+		filepath.Join(outputDir, "seg_%05d.ts"))
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffmpeg segment: %w: %s", err, stderr.String())
+	}
+	defer os.Remove(listPath)
+	return readSegmentList(listPath)
+}
+
+// Fragment 用ffmpeg的dash封装分别切分视频和音频，ffmpeg同时写出的mpd不使用；
+// 关键帧间隔固定，除最后一个分片外每个分片的时长都是segmentDuration
+func (t *FFmpegTranscoder) Fragment(ctx context.Context, input, outputDir string, segmentDuration time.Duration) ([]vo.FragmentedTrack, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	probe, err := t.probe(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	contentTypes := []string{vo.DASHVideo}
+	if probe.stream("audio") != nil {
+		contentTypes = append(contentTypes, vo.DASHAudio)
+	}
+	tracks := make([]vo.FragmentedTrack, 0, len(contentTypes))
+	for _, contentType := range contentTypes {
+		manifest := filepath.Join(outputDir, contentType+".mpd")
+		cmd := exec.CommandContext(ctx, t.ffmpegPath,
+			"-hide_banner", "-nostdin", "-y",
+			"-i", input,
+			"-map", fmt.Sprintf("0:%c:0", contentType[0]), "-c", "copy",
+			"-f", "dash",
+			"-seg_duration", strconv.FormatFloat(segmentDuration.Seconds(), 'f', 3, 64),
+			"-use_template", "1", "-use_timeline", "0",
+			"-init_seg_name", vo.DASHInitialization(contentType),
+			"-media_seg_name", vo.DASHSegmentTemplate(contentType),
+			manifest)
+		stderr := &tailBuffer{limit: stderrTailSize}
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("ffmpeg fragment %s: %w: %s", contentType, err, stderr.String())
+		}
+		_ = os.Remove(manifest)
+		segments, err := fragmentSegments(outputDir, contentType, segmentDuration, probe.duration())
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, vo.NewFragmentedTrack(contentType, segments))
+	}
+	return tracks, nil
+}
+
+// Snapshot 在输入前定位到 offset 后解码一帧，宽度保持偶数
+func (t *FFmpegTranscoder) Snapshot(ctx context.Context, input, output string, offset time.Duration, width int) error {
+	cmd := exec.CommandContext(ctx, t.ffmpegPath,
+		"-hide_banner", "-nostdin", "-y",
+		"-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64),
+		"-i", input,
+		"-map", "0:v:0", "-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-q:v", strconv.Itoa(jpegQScale),
+		output)
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ffmpeg snapshot: %w: %s", err, stderr.String())
+	}
+	if _, err := os.Stat(output); err != nil {
+		return fmt.Errorf("ffmpeg snapshot: no frame at %s: %w", offset, err)
+	}
+	return nil
+}
+
+// Thumbnails 用fps滤镜每隔 interval 取一帧，文件按编号顺序即为时间顺序
+func (t *FFmpegTranscoder) Thumbnails(ctx context.Context, input, outputDir string, interval time.Duration, width int) ([]string, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, t.ffmpegPath,
+		"-hide_banner", "-nostdin", "-y",
+		"-i", input,
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=1/%s,scale=%d:-2", strconv.FormatFloat(interval.Seconds(), 'f', 3, 64), width),
+		"-q:v", strconv.Itoa(jpegQScale),
+		filepath.Join(outputDir, "thumb_%05d.jpg"))
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffmpeg thumbnails: %w: %s", err, stderr.String())
+	}
+	var names []string
+	for number := 1; ; number++ {
+		name := fmt.Sprintf("thumb_%05d.jpg", number)
+		if _, err := os.Stat(filepath.Join(outputDir, name)); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// fragmentSegments 按编号依次查找轨道的媒体分片，最后一个分片的时长为余下的部分
+func fragmentSegments(outputDir, contentType string, segmentDuration, duration time.Duration) ([]vo.MediaSegment, error) {
+	if _, err := os.Stat(filepath.Join(outputDir, vo.DASHInitialization(contentType))); err != nil {
+		return nil, fmt.Errorf("fragment %s: %w", contentType, err)
+	}
+	var segments []vo.MediaSegment
+	remaining := duration
+	for number := 1; ; number++ {
+		filename := vo.DASHSegment(contentType, number)
+		if _, err := os.Stat(filepath.Join(outputDir, filename)); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		segments = append(segments, vo.NewMediaSegment(filename, min(segmentDuration, remaining)))
+		remaining = max(remaining-segmentDuration, 0)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("fragment %s: no segments", contentType)
+	}
+	last := len(segments) - 1
+	segments[last] = vo.NewMediaSegment(segments[last].Filename(), segments[last].Duration()+remaining)
+	return segments, nil
+}
+
+// readSegmentList 读取 -segment_list_type csv 的输出，每行为 文件名,开始时间,结束时间
+func readSegmentList(listPath string) ([]vo.MediaSegment, error) {
+	file, err := os.Open(listPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("segment list: %w", err)
+	}
+	segments := make([]vo.MediaSegment, 0, len(records))
+	for _, record := range records {
+		if len(record) < 3 {
+			return nil, fmt.Errorf("segment list: malformed record %v", record)
+		}
+		start, startErr := strconv.ParseFloat(record[1], 64)
+		end, endErr := strconv.ParseFloat(record[2], 64)
+		if startErr != nil || endErr != nil || end < start {
+			return nil, fmt.Errorf("segment list: malformed record %v", record)
+		}
+		segments = append(segments, vo.NewMediaSegment(record[0], time.Duration((end-start)*float64(time.Second))))
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("segment list: no segments")
+	}
+	return segments, nil
+}
+
+// transcodeArgs ffmpeg参数：只取第一条视频流和第一条音频流（可以没有音频），
+// 宽度按比例缩放并保持偶数，高度取源高度和档位高度中较小的偶数；固定间隔插入关键帧且不在场景切换处额外插入
+func transcodeArgs(input, output string, profile vo.RenditionProfile) []string {
+	videoBitrate := profile.VideoBitrate()
+	return []string{
+		"-hide_banner", "-nostdin", "-y",
+		"-i", input,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", fmt.Sprintf("scale=-2:trunc(min(ih\\,%d)/2)*2", profile.Height()),
+		"-c:v", "libx264", "-preset", "veryfast", "-pix_fmt", "yuv420p",
+		"-b:v", fmt.Sprintf("%dk", videoBitrate),
+		"-maxrate", fmt.Sprintf("%dk", videoBitrate*107/100),
+		"-bufsize", fmt.Sprintf("%dk", videoBitrate*2),
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", keyframeInterval), "-sc_threshold", "0",
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", profile.AudioBitrate()), "-ac", "2",
+		"-f", "mp4",
+		"-progress", "pipe:1", "-nostats",
+		output,
+	}
+}
+
+// readProgress 读取 -progress 输出直到进程关闭标准输出，out_time_us（旧版本为 out_time_ms，单位同样是微秒）为已处理的时长
+func readProgress(r io.Reader, progress func(processed time.Duration)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || (key != "out_time_us" && key != "out_time_ms") {
+			continue
+		}
+		us, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || us < 0 {
+			continue
+		}
+		if progress != nil {
+			progress(time.Duration(us) * time.Microsecond)
+		}
+	}
+	// 扫描出错时继续读完，避免ffmpeg写满管道后阻塞
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// tailBuffer 只保留最后 limit 个字节的输出
+type tailBuffer struct {
+	limit int
+	buf   []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return strings.TrimSpace(string(b.buf))
+}
@@ -3,7 +3,9 @@ package video
 import (
 	"sync"
 
+	"go-video/ddd/video/adapter/handler"
 	"go-video/ddd/video/adapter/http"
+	"go-video/ddd/video/adapter/job"
 	"go-video/pkg/assert"
 	"go-video/pkg/manager"
 )
@@ -31,8 +33,21 @@ func DefaultVideoPlugin() *VideoPlugin {
 	return singletonVideoPlugin
 }
 
-// init 包初始化函数，注册视频控制器插件
+// init 包初始化函数，注册视频控制器插件、定时对账、过期源文件清理、处理流水线、恶意软件扫描、faststart、转码、封面和直播组件以及对应的事件处理器
 func init() {
 	// 注册视频控制器插件到管理器
 	manager.RegisterControllerPlugin(&http.VideoControllerPlugin{})
+	manager.RegisterComponentPlugin(&job.ReconcileComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.SourcePurgeComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.TranscodeComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.ArtworkComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.FaststartComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.ScanComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.PipelineComponentPlugin{})
+	manager.RegisterComponentPlugin(&job.LiveComponentPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.TranscodeEventHandlerPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.ArtworkEventHandlerPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.FaststartEventHandlerPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.ScanEventHandlerPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.PipelineEventHandlerPlugin{})
 }
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"go-video/ddd/webhook/application/app"
+	"go-video/ddd/webhook/application/cqe"
+	"go-video/ddd/webhook/domain/vo"
+	"go-video/pkg/event"
+	"go-video/pkg/manager"
+)
+
+// webhookEventTypes 领域事件到对外事件类型的映射，未列出的领域事件不对外通知
+var webhookEventTypes = map[event.Type]vo.EventType{
+	event.TypeVideoUploaded:     vo.EventTypeUploadCompleted,
+	event.TypeVideoUploadFailed: vo.EventTypeUploadFailed,
+	event.TypeVideoDeleted:      vo.EventTypeVideoDeleted,
+	event.TypeUserRegistered:    vo.EventTypeUserRegistered,
+}
+
+// DomainEventHandlerPlugin 把领域事件转为事件订阅投递的处理器插件
+type DomainEventHandlerPlugin struct {
+}
+
+func (p *DomainEventHandlerPlugin) Name() string {
+	return "webhookEventHandlerPlugin"
+}
+
+func (p *DomainEventHandlerPlugin) MustCreateEventHandler() manager.EventHandler {
+	return &domainEventHandler{
+		webhookApp: app.DefaultWebhookApp(),
+	}
+}
+
+// domainEventHandler 为订阅方创建投递，投递以领域事件ID去重，重复分发不会重复通知
+type domainEventHandler struct {
+	webhookApp app.WebhookApp
+}
+
+func (h *domainEventHandler) EventTypes() []event.Type {
+	types := make([]event.Type, 0, len(webhookEventTypes))
+	for eventType := range webhookEventTypes {
+		types = append(types, eventType)
+	}
+	return types
+}
+
+// Handle 事件数据原样作为投递请求体的 data 字段
+func (h *domainEventHandler) Handle(ctx context.Context, e *event.Event) error {
+	eventType, ok := webhookEventTypes[e.Type]
+	if !ok {
+		return nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(e.Payload, &data); err != nil {
+		return err
+	}
+	return h.webhookApp.Publish(ctx, &cqe.PublishEventCommand{
+		EventUUID:  e.ID,
+		EventType:  eventType.Value(),
+		OccurredAt: e.OccurredAt,
+		Data:       data,
+	})
+}
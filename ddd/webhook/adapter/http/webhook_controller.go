@@ -0,0 +1,193 @@
+package http
+
+import (
+	"sync"
+
+	"go-video/ddd/webhook/application/app"
+	"go-video/ddd/webhook/application/cqe"
+	"go-video/pkg/assert"
+	"go-video/pkg/errno"
+	"go-video/pkg/manager"
+	"go-video/pkg/middleware"
+	"go-video/pkg/restapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	webhookControllerOnce      sync.Once
+	singletonWebhookController WebhookController
+)
+
+// WebhookControllerPlugin 事件订阅控制器插件
+type WebhookControllerPlugin struct {
+}
+
+func (p *WebhookControllerPlugin) Name() string {
+	return "webhookControllerPlugin"
+}
+
+func (p *WebhookControllerPlugin) MustCreateController() manager.Controller {
+	return DefaultWebhookController()
+}
+
+type WebhookController interface {
+	manager.Controller
+	CreateSubscription(ctx *gin.Context)
+	ListSubscriptions(ctx *gin.Context)
+	UpdateSubscription(ctx *gin.Context)
+	DeleteSubscription(ctx *gin.Context)
+	ListDeliveries(ctx *gin.Context)
+	GetDelivery(ctx *gin.Context)
+	Redeliver(ctx *gin.Context)
+}
+
+type webhookControllerImpl struct {
+	webhookApp app.WebhookApp
+}
+
+func DefaultWebhookController() WebhookController {
+	assert.NotCircular()
+	webhookControllerOnce.Do(func() {
+		singletonWebhookController = &webhookControllerImpl{
+			webhookApp: app.DefaultWebhookApp(),
+		}
+	})
+	assert.NotNil(singletonWebhookController)
+	return singletonWebhookController
+}
+
+// RegisterOpenApi 注册开放API
+func (c *webhookControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+}
+
+// RegisterInnerApi 注册内部API
+func (c *webhookControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+}
+
+// RegisterDebugApi 注册调试API
+func (c *webhookControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+}
+
+// RegisterOpsApi 注册运维API
+func (c *webhookControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+	// 订阅会收到全平台的事件，只对管理员开放
+	webhooks := router.Group("/v1/webhooks", middleware.AuthRequired(), middleware.AdminRequired())
+	{
+		webhooks.POST("/subscriptions", c.CreateSubscription)
+		webhooks.GET("/subscriptions", c.ListSubscriptions)
+		webhooks.PUT("/subscriptions/:id", c.UpdateSubscription)
+		webhooks.DELETE("/subscriptions/:id", c.DeleteSubscription)
+		webhooks.GET("/deliveries", c.ListDeliveries)
+		webhooks.GET("/deliveries/:id", c.GetDelivery)
+		webhooks.POST("/deliveries/:id/redeliver", c.Redeliver)
+	}
+}
+
+// CreateSubscription 创建订阅
+func (c *webhookControllerImpl) CreateSubscription(ctx *gin.Context) {
+	var cmd cqe.CreateSubscriptionCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.webhookApp.CreateSubscription(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// ListSubscriptions 订阅列表
+func (c *webhookControllerImpl) ListSubscriptions(ctx *gin.Context) {
+	result, err := c.webhookApp.ListSubscriptions(ctx.Request.Context(), &cqe.ListSubscriptionsQuery{
+		OperatorUUID: middleware.MustGetCurrentUserUUID(ctx),
+	})
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// UpdateSubscription 修改订阅
+func (c *webhookControllerImpl) UpdateSubscription(ctx *gin.Context) {
+	var cmd cqe.UpdateSubscriptionCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "body"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	cmd.SubscriptionUUID = ctx.Param("id")
+	result, err := c.webhookApp.UpdateSubscription(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// DeleteSubscription 删除订阅
+func (c *webhookControllerImpl) DeleteSubscription(ctx *gin.Context) {
+	var cmd cqe.DeleteSubscriptionCommand
+	if err := ctx.ShouldBindUri(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	if err := c.webhookApp.DeleteSubscription(ctx.Request.Context(), &cmd); err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, nil)
+}
+
+// ListDeliveries 投递记录列表，status=dead 查看死信
+func (c *webhookControllerImpl) ListDeliveries(ctx *gin.Context) {
+	var query cqe.ListDeliveriesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "query"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.webhookApp.ListDeliveries(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// GetDelivery 投递详情
+func (c *webhookControllerImpl) GetDelivery(ctx *gin.Context) {
+	var query cqe.GetDeliveryQuery
+	if err := ctx.ShouldBindUri(&query); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	query.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.webhookApp.GetDelivery(ctx.Request.Context(), &query)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
+
+// Redeliver 重新投递
+func (c *webhookControllerImpl) Redeliver(ctx *gin.Context) {
+	var cmd cqe.RedeliverCommand
+	if err := ctx.ShouldBindUri(&cmd); err != nil {
+		restapi.Failed(ctx, errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "id"))
+		return
+	}
+	cmd.OperatorUUID = middleware.MustGetCurrentUserUUID(ctx)
+	result, err := c.webhookApp.Redeliver(ctx.Request.Context(), &cmd)
+	if err != nil {
+		restapi.Failed(ctx, err)
+		return
+	}
+	restapi.Success(ctx, result)
+}
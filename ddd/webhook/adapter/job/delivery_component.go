@@ -0,0 +1,95 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-video/ddd/webhook/application/app"
+	"go-video/pkg/logger"
+	"go-video/pkg/manager"
+)
+
+// defaultDeliveryPollInterval 未配置时扫描到期投递的间隔
+const defaultDeliveryPollInterval = 5 * time.Second
+
+// DeliveryComponentPlugin 事件投递组件插件
+type DeliveryComponentPlugin struct {
+}
+
+func (p *DeliveryComponentPlugin) Name() string {
+	return "webhookDeliveryComponentPlugin"
+}
+
+func (p *DeliveryComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	var interval time.Duration
+	if deps != nil && deps.Config != nil {
+		interval = deps.Config.Webhook.PollInterval
+	}
+	if interval <= 0 {
+		interval = defaultDeliveryPollInterval
+	}
+	return &deliveryComponent{
+		interval:   interval,
+		webhookApp: app.DefaultWebhookApp(),
+	}
+}
+
+// deliveryComponent 定时发送到期的投递（新事件和等待重试的投递），多实例部署时通过领取避免重复发送
+type deliveryComponent struct {
+	interval   time.Duration
+	webhookApp app.WebhookApp
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *deliveryComponent) GetName() string {
+	return "webhookDeliveryComponent"
+}
+
+// Start 启动定时投递
+func (c *deliveryComponent) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.loop(ctx)
+	logger.Info(fmt.Sprintf("webhook delivery component started, interval: %s", c.interval))
+	return nil
+}
+
+// Stop 停止定时投递，等待正在发送的投递退出
+func (c *deliveryComponent) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *deliveryComponent) loop(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 一轮领满时说明还有积压，立即继续
+			for {
+				sent, err := c.webhookApp.DeliverDue(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.Error(fmt.Sprintf("webhook delivery failed after %d deliveries: %v", sent, err))
+					}
+					break
+				}
+				if sent < app.DeliveryBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
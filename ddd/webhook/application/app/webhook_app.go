@@ -0,0 +1,461 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-video/ddd/webhook/application/cqe"
+	"go-video/ddd/webhook/application/dto"
+	"go-video/ddd/webhook/domain/entity"
+	"go-video/ddd/webhook/domain/gateway"
+	"go-video/ddd/webhook/domain/repo"
+	"go-video/ddd/webhook/domain/vo"
+	"go-video/ddd/webhook/infrastructure/database/persistence"
+	"go-video/ddd/webhook/infrastructure/sender"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/errno"
+	"go-video/pkg/logger"
+	"go-video/pkg/netguard"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWebhookTimeout 未配置时单次投递的超时
+	defaultWebhookTimeout = 10 * time.Second
+	// defaultWebhookMaxAttempts 未配置时进入死信前的最多投递次数
+	defaultWebhookMaxAttempts = 8
+	// defaultWebhookRetryBaseDelay 未配置时第一次失败后的重试间隔
+	defaultWebhookRetryBaseDelay = 30 * time.Second
+	// defaultWebhookRetryMaxDelay 未配置时重试间隔的上限
+	defaultWebhookRetryMaxDelay = time.Hour
+	// DeliveryBatchSize 每轮最多领取的投递数
+	DeliveryBatchSize = 50
+	// deliveryConcurrency 同时发送的投递数
+	deliveryConcurrency = 8
+	// webhookSecretBytes 自动生成的签名密钥字节数
+	webhookSecretBytes = 32
+)
+
+var (
+	onceWebhookApp      sync.Once
+	singletonWebhookApp WebhookApp
+)
+
+// WebhookApp 事件订阅和投递应用服务
+type WebhookApp interface {
+	CreateSubscription(ctx context.Context, cmd *cqe.CreateSubscriptionCommand) (*dto.SubscriptionDto, error)
+	ListSubscriptions(ctx context.Context, query *cqe.ListSubscriptionsQuery) ([]*dto.SubscriptionDto, error)
+	UpdateSubscription(ctx context.Context, cmd *cqe.UpdateSubscriptionCommand) (*dto.SubscriptionDto, error)
+	DeleteSubscription(ctx context.Context, cmd *cqe.DeleteSubscriptionCommand) error
+	Publish(ctx context.Context, cmd *cqe.PublishEventCommand) error
+	DeliverDue(ctx context.Context) (int, error)
+	ListDeliveries(ctx context.Context, query *cqe.ListDeliveriesQuery) (*dto.DeliveryListDto, error)
+	GetDelivery(ctx context.Context, query *cqe.GetDeliveryQuery) (*dto.DeliveryDetailDto, error)
+	Redeliver(ctx context.Context, cmd *cqe.RedeliverCommand) (*dto.DeliveryDto, error)
+}
+
+type webhookApp struct {
+	webhookRepo repo.WebhookRepository
+	sender      gateway.Sender
+}
+
+func DefaultWebhookApp() WebhookApp {
+	assert.NotCircular()
+	onceWebhookApp.Do(func() {
+		singletonWebhookApp = &webhookApp{
+			webhookRepo: persistence.NewWebhookRepository(),
+			sender:      sender.DefaultSender(),
+		}
+	})
+	assert.NotNil(singletonWebhookApp)
+	return singletonWebhookApp
+}
+
+// CreateSubscription 创建订阅，返回的签名密钥只展示这一次
+func (a *webhookApp) CreateSubscription(ctx context.Context, cmd *cqe.CreateSubscriptionCommand) (*dto.SubscriptionDto, error) {
+	eventTypes, err := cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	if err := a.checkURL(cmd.URL); err != nil {
+		return nil, err
+	}
+	secret := cmd.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, errno.NewSimpleBizError(errno.ErrInternalServer, err)
+		}
+	}
+	subscription := entity.DefaultSubscription(cmd.URL, secret, eventTypes, cmd.Description, cmd.OperatorUUID)
+	if err := a.webhookRepo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	result := toSubscriptionDto(subscription)
+	result.Secret = secret
+	return result, nil
+}
+
+// ListSubscriptions 列出全部订阅
+func (a *webhookApp) ListSubscriptions(ctx context.Context, query *cqe.ListSubscriptionsQuery) ([]*dto.SubscriptionDto, error) {
+	if !config.IsAdmin(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	subscriptions, err := a.webhookRepo.FindSubscriptions(ctx)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	result := make([]*dto.SubscriptionDto, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, toSubscriptionDto(subscription))
+	}
+	return result, nil
+}
+
+// UpdateSubscription 修改订阅的地址、事件类型、备注或启用状态
+func (a *webhookApp) UpdateSubscription(ctx context.Context, cmd *cqe.UpdateSubscriptionCommand) (*dto.SubscriptionDto, error) {
+	eventTypes, err := cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	subscription, err := a.findSubscription(ctx, cmd.SubscriptionUUID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.URL != nil {
+		if err := a.checkURL(*cmd.URL); err != nil {
+			return nil, err
+		}
+		subscription.SetURL(*cmd.URL)
+	}
+	if eventTypes != nil {
+		subscription.SetEventTypes(eventTypes)
+	}
+	if cmd.Description != nil {
+		subscription.SetDescription(*cmd.Description)
+	}
+	if cmd.Active != nil {
+		subscription.SetActive(*cmd.Active)
+	}
+	if err := a.webhookRepo.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toSubscriptionDto(subscription), nil
+}
+
+// DeleteSubscription 删除订阅，尚未成功的投递转为死信
+func (a *webhookApp) DeleteSubscription(ctx context.Context, cmd *cqe.DeleteSubscriptionCommand) error {
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	if _, err := a.findSubscription(ctx, cmd.SubscriptionUUID); err != nil {
+		return err
+	}
+	if err := a.webhookRepo.DeleteSubscription(ctx, cmd.SubscriptionUUID); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return nil
+}
+
+// Publish 为订阅了该事件的每个启用中的订阅创建一条投递，由后台任务异步发送
+func (a *webhookApp) Publish(ctx context.Context, cmd *cqe.PublishEventCommand) error {
+	eventType, ok := vo.NewEventType(cmd.EventType)
+	if !ok {
+		return errno.NewSimpleBizError(errno.ErrWebhookEventType, nil, cmd.EventType)
+	}
+	subscriptions, err := a.webhookRepo.FindSubscriptions(ctx)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	event := entity.DefaultEvent(eventType, cmd.Data)
+	if cmd.EventUUID != "" {
+		event = entity.NewEvent(cmd.EventUUID, eventType, cmd.OccurredAt, cmd.Data)
+	}
+	payload, err := event.Payload()
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrInternalServer, err)
+	}
+	var deliveries []*entity.Delivery
+	for _, subscription := range subscriptions {
+		if subscription.Active() && subscription.Subscribes(eventType) {
+			deliveries = append(deliveries, entity.DefaultDelivery(subscription.UUID(), event, payload))
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := a.webhookRepo.SaveDeliveries(ctx, deliveries); err != nil {
+		return errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	logger.Info(fmt.Sprintf("webhook event %s (%s) queued for %d subscriptions", event.UUID(), eventType.Value(), len(deliveries)))
+	return nil
+}
+
+// DeliverDue 领取并发送到期的投递，返回本轮发送的数量
+func (a *webhookApp) DeliverDue(ctx context.Context) (int, error) {
+	cfg := webhookConfig()
+	policy := vo.NewRetryPolicy(cfg.MaxAttempts, cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+	now := time.Now()
+	deliveries, err := a.webhookRepo.FindDueDeliveries(ctx, now, DeliveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deliveryConcurrency)
+	sent := 0
+	for _, delivery := range deliveries {
+		// 租约覆盖一次请求的超时，进程在发送中退出时租约到期后重新投递
+		claimed, err := a.webhookRepo.ClaimDelivery(ctx, delivery, now.Add(2*cfg.Timeout))
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+		sent++
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery *entity.Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			a.deliver(ctx, delivery, policy)
+		}(delivery)
+	}
+	wg.Wait()
+	return sent, nil
+}
+
+// deliver 发送一条投递并记录结果
+func (a *webhookApp) deliver(ctx context.Context, delivery *entity.Delivery, policy vo.RetryPolicy) {
+	subscription, err := a.webhookRepo.FindSubscription(ctx, delivery.SubscriptionUuid())
+	if err != nil {
+		logger.Error(fmt.Sprintf("webhook delivery %s: find subscription failed: %v", delivery.UUID(), err))
+		return
+	}
+
+	startedAt := time.Now()
+	statusCode := 0
+	if subscription == nil {
+		// 订阅在领取后被删除，直接进入死信
+		err = errors.New("subscription deleted")
+		policy = vo.NewRetryPolicy(0, 0, 0)
+	} else {
+		headers := map[string]string{
+			vo.TimestampHeader: strconv.FormatInt(startedAt.Unix(), 10),
+			vo.SignatureHeader: vo.Signature(subscription.Secret(), startedAt, delivery.Payload()),
+			vo.EventHeader:     delivery.EventType().Value(),
+			vo.DeliveryHeader:  delivery.UUID(),
+		}
+		statusCode, err = a.sender.Send(ctx, subscription.URL(), headers, delivery.Payload())
+		if err == nil && (statusCode < 200 || statusCode > 299) {
+			err = fmt.Errorf("subscriber responded %d", statusCode)
+		}
+		if ctx.Err() != nil {
+			// 服务停止时中断的请求不计入投递次数，租约到期后重新投递
+			return
+		}
+	}
+	attempt := delivery.RecordAttempt(statusCode, err, startedAt, time.Since(startedAt), policy)
+	if err := a.webhookRepo.UpdateDelivery(context.WithoutCancel(ctx), delivery, attempt); err != nil {
+		logger.Error(fmt.Sprintf("webhook delivery %s: save attempt failed: %v", delivery.UUID(), err))
+		return
+	}
+	if delivery.Status() == vo.DeliveryStatusDead {
+		logger.Error(fmt.Sprintf("webhook delivery %s dead after %d attempts: %s", delivery.UUID(), delivery.Attempts(), delivery.LastError()))
+	}
+}
+
+// ListDeliveries 分页查询投递记录，status=dead 即死信列表
+func (a *webhookApp) ListDeliveries(ctx context.Context, query *cqe.ListDeliveriesQuery) (*dto.DeliveryListDto, error) {
+	if !config.IsAdmin(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	var statusFilter *vo.DeliveryStatus
+	if query.Status != "" {
+		status, ok := vo.NewDeliveryStatus(query.Status)
+		if !ok {
+			return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "status")
+		}
+		statusFilter = &status
+	}
+	page := vo.NewPage(query.Page, query.PageSize)
+	deliveries, total, err := a.webhookRepo.FindDeliveries(ctx, statusFilter, query.SubscriptionUUID, page)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	result := &dto.DeliveryListDto{
+		Deliveries: make([]*dto.DeliveryDto, 0, len(deliveries)),
+		Total:      total,
+		Page:       page.Page(),
+		PageSize:   page.PageSize(),
+	}
+	for _, delivery := range deliveries {
+		result.Deliveries = append(result.Deliveries, toDeliveryDto(delivery))
+	}
+	return result, nil
+}
+
+// GetDelivery 查询投递详情和全部投递尝试
+func (a *webhookApp) GetDelivery(ctx context.Context, query *cqe.GetDeliveryQuery) (*dto.DeliveryDetailDto, error) {
+	if !config.IsAdmin(query.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	delivery, err := a.findDelivery(ctx, query.DeliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := a.webhookRepo.FindAttempts(ctx, delivery.UUID())
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	result := &dto.DeliveryDetailDto{
+		DeliveryDto: toDeliveryDto(delivery),
+		Payload:     string(delivery.Payload()),
+		AttemptLog:  make([]*dto.DeliveryAttemptDto, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		result.AttemptLog = append(result.AttemptLog, &dto.DeliveryAttemptDto{
+			Attempt:    attempt.Attempt(),
+			StatusCode: attempt.StatusCode(),
+			Error:      attempt.ErrorMsg(),
+			DurationMs: attempt.Duration().Milliseconds(),
+			CreatedAt:  attempt.CreatedAt(),
+		})
+	}
+	return result, nil
+}
+
+// Redeliver 重新投递（包括死信和已成功的投递），发送与原投递相同的请求体
+func (a *webhookApp) Redeliver(ctx context.Context, cmd *cqe.RedeliverCommand) (*dto.DeliveryDto, error) {
+	if !config.IsAdmin(cmd.OperatorUUID) {
+		return nil, errno.NewSimpleBizError(errno.ErrForbidden, nil)
+	}
+	delivery, err := a.findDelivery(ctx, cmd.DeliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.findSubscription(ctx, delivery.SubscriptionUuid()); err != nil {
+		return nil, err
+	}
+	delivery.Redeliver()
+	if err := a.webhookRepo.UpdateDelivery(ctx, delivery, nil); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	return toDeliveryDto(delivery), nil
+}
+
+// checkURL 按访问策略校验投递地址
+func (a *webhookApp) checkURL(rawURL string) error {
+	err := a.sender.CheckURL(rawURL)
+	if err == nil {
+		return nil
+	}
+	var denied *netguard.DeniedError
+	if errors.As(err, &denied) {
+		return errno.NewSimpleBizError(errno.ErrWebhookURLNotAllowed, err, denied.Reason)
+	}
+	return errno.NewSimpleBizError(errno.ErrParameterInvalid, err, "url")
+}
+
+func (a *webhookApp) findSubscription(ctx context.Context, subscriptionUUID string) (*entity.Subscription, error) {
+	subscription, err := a.webhookRepo.FindSubscription(ctx, subscriptionUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if subscription == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrWebhookSubscriptionMissing, nil)
+	}
+	return subscription, nil
+}
+
+func (a *webhookApp) findDelivery(ctx context.Context, deliveryUUID string) (*entity.Delivery, error) {
+	delivery, err := a.webhookRepo.FindDelivery(ctx, deliveryUUID)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrDatabase, err)
+	}
+	if delivery == nil {
+		return nil, errno.NewSimpleBizError(errno.ErrWebhookDeliveryNotFound, nil)
+	}
+	return delivery, nil
+}
+
+func toSubscriptionDto(subscription *entity.Subscription) *dto.SubscriptionDto {
+	eventTypes := make([]string, 0, len(subscription.EventTypes()))
+	for _, eventType := range subscription.EventTypes() {
+		eventTypes = append(eventTypes, eventType.Value())
+	}
+	return &dto.SubscriptionDto{
+		SubscriptionUUID: subscription.UUID(),
+		URL:              subscription.URL(),
+		EventTypes:       eventTypes,
+		Description:      subscription.Description(),
+		Active:           subscription.Active(),
+		CreatedBy:        subscription.CreatedBy(),
+		CreatedAt:        subscription.CreatedAt(),
+	}
+}
+
+func toDeliveryDto(delivery *entity.Delivery) *dto.DeliveryDto {
+	return &dto.DeliveryDto{
+		DeliveryUUID:     delivery.UUID(),
+		SubscriptionUUID: delivery.SubscriptionUuid(),
+		EventUUID:        delivery.EventUuid(),
+		EventType:        delivery.EventType().Value(),
+		Status:           delivery.Status().Value(),
+		Attempts:         delivery.Attempts(),
+		NextAttemptAt:    delivery.NextAttemptAt(),
+		LastStatusCode:   delivery.LastStatusCode(),
+		LastError:        delivery.LastError(),
+		DeliveredAt:      delivery.DeliveredAt(),
+		CreatedAt:        delivery.CreatedAt(),
+	}
+}
+
+// generateSecret 生成随机签名密钥
+func generateSecret() (string, error) {
+	buf := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// webhookConfig 读取投递配置，未配置的项使用默认值
+func webhookConfig() config.WebhookConfig {
+	cfg := config.WebhookConfig{
+		Timeout:        defaultWebhookTimeout,
+		MaxAttempts:    defaultWebhookMaxAttempts,
+		RetryBaseDelay: defaultWebhookRetryBaseDelay,
+		RetryMaxDelay:  defaultWebhookRetryMaxDelay,
+	}
+	global := config.GetGlobalConfig()
+	if global == nil {
+		return cfg
+	}
+	if global.Webhook.Timeout > 0 {
+		cfg.Timeout = global.Webhook.Timeout
+	}
+	if global.Webhook.MaxAttempts > 0 {
+		cfg.MaxAttempts = global.Webhook.MaxAttempts
+	}
+	if global.Webhook.RetryBaseDelay > 0 {
+		cfg.RetryBaseDelay = global.Webhook.RetryBaseDelay
+	}
+	if global.Webhook.RetryMaxDelay > 0 {
+		cfg.RetryMaxDelay = global.Webhook.RetryMaxDelay
+	}
+	return cfg
+}
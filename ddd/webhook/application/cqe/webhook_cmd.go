@@ -0,0 +1,147 @@
+package cqe
+
+import (
+	"go-video/ddd/webhook/domain/vo"
+	"go-video/pkg/errno"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWebhookURLLength 投递地址最大长度
+	maxWebhookURLLength = 1024
+	// maxWebhookDescriptionLength 订阅备注最大长度
+	maxWebhookDescriptionLength = 255
+	// minWebhookSecretLength 自定义签名密钥最小长度
+	minWebhookSecretLength = 16
+	// maxWebhookSecretLength 自定义签名密钥最大长度
+	maxWebhookSecretLength = 128
+)
+
+// CreateSubscriptionCommand 创建订阅命令，Secret 为空时自动生成
+type CreateSubscriptionCommand struct {
+	OperatorUUID string   `json:"-"`
+	URL          string   `json:"url"`
+	Secret       string   `json:"secret"`
+	EventTypes   []string `json:"event_types"`
+	Description  string   `json:"description"`
+}
+
+// Validate 校验订阅参数
+func (c *CreateSubscriptionCommand) Validate() ([]vo.EventType, error) {
+	if len(c.OperatorUUID) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.Secret != "" && (len(c.Secret) < minWebhookSecretLength || len(c.Secret) > maxWebhookSecretLength) {
+		return nil, errno.NewSimpleBizError(errno.ErrParameterInvalid, nil, "secret")
+	}
+	if err := validateURL(c.URL); err != nil {
+		return nil, err
+	}
+	if len([]rune(c.Description)) > maxWebhookDescriptionLength {
+		return nil, errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	return parseEventTypes(c.EventTypes)
+}
+
+// UpdateSubscriptionCommand 修改订阅命令，为nil的字段保持不变，签名密钥不可修改
+type UpdateSubscriptionCommand struct {
+	OperatorUUID     string    `json:"-"`
+	SubscriptionUUID string    `json:"-"`
+	URL              *string   `json:"url"`
+	EventTypes       *[]string `json:"event_types"`
+	Description      *string   `json:"description"`
+	Active           *bool     `json:"active"`
+}
+
+// Validate 校验修改的字段，未修改事件类型时返回nil
+func (c *UpdateSubscriptionCommand) Validate() ([]vo.EventType, error) {
+	if len(c.OperatorUUID) == 0 || len(c.SubscriptionUUID) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if c.URL != nil {
+		if err := validateURL(*c.URL); err != nil {
+			return nil, err
+		}
+	}
+	if c.Description != nil && len([]rune(*c.Description)) > maxWebhookDescriptionLength {
+		return nil, errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	if c.EventTypes == nil {
+		return nil, nil
+	}
+	return parseEventTypes(*c.EventTypes)
+}
+
+// DeleteSubscriptionCommand 删除订阅命令
+type DeleteSubscriptionCommand struct {
+	OperatorUUID     string `uri:"-"`
+	SubscriptionUUID string `uri:"id" binding:"required"`
+}
+
+// ListSubscriptionsQuery 订阅列表查询
+type ListSubscriptionsQuery struct {
+	OperatorUUID string `form:"-"`
+}
+
+// PublishEventCommand 发布事件命令，Data 为事件数据，会原样放入请求体的 data 字段。
+// EventUUID 为领域事件ID，同一事件重复发布时每个订阅只投递一次；为空时生成新的事件ID
+type PublishEventCommand struct {
+	EventUUID  string
+	EventType  string
+	OccurredAt time.Time
+	Data       map[string]interface{}
+}
+
+// ListDeliveriesQuery 投递记录查询
+type ListDeliveriesQuery struct {
+	OperatorUUID     string `form:"-"`
+	Status           string `form:"status"`            // pending/succeeded/dead，为空表示全部
+	SubscriptionUUID string `form:"subscription_uuid"` // 为空表示全部订阅
+	Page             int    `form:"page"`
+	PageSize         int    `form:"page_size"`
+}
+
+// GetDeliveryQuery 投递详情查询
+type GetDeliveryQuery struct {
+	OperatorUUID string `uri:"-"`
+	DeliveryUUID string `uri:"id" binding:"required"`
+}
+
+// RedeliverCommand 重新投递命令
+type RedeliverCommand struct {
+	OperatorUUID string `uri:"-"`
+	DeliveryUUID string `uri:"id" binding:"required"`
+}
+
+// validateURL 只校验长度，协议、主机名和网段由访问策略校验
+func validateURL(url string) error {
+	if len(strings.TrimSpace(url)) == 0 {
+		return errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	if len(url) > maxWebhookURLLength {
+		return errno.NewSimpleBizError(errno.ErrParamTooLong, nil)
+	}
+	return nil
+}
+
+// parseEventTypes 解析并去重事件类型，至少订阅一种
+func parseEventTypes(values []string) ([]vo.EventType, error) {
+	if len(values) == 0 {
+		return nil, errno.NewSimpleBizError(errno.ErrMissingParam, nil)
+	}
+	eventTypes := make([]vo.EventType, 0, len(values))
+	seen := make(map[vo.EventType]struct{}, len(values))
+	for _, value := range values {
+		eventType, ok := vo.NewEventType(strings.TrimSpace(value))
+		if !ok {
+			return nil, errno.NewSimpleBizError(errno.ErrWebhookEventType, nil, value)
+		}
+		if _, dup := seen[eventType]; dup {
+			continue
+		}
+		seen[eventType] = struct{}{}
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes, nil
+}
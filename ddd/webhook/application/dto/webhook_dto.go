@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// SubscriptionDto 事件订阅，签名密钥只在创建时返回
+type SubscriptionDto struct {
+	SubscriptionUUID string     `json:"subscription_uuid"`
+	URL              string     `json:"url"`
+	Secret           string     `json:"secret,omitempty"`
+	EventTypes       []string   `json:"event_types"`
+	Description      string     `json:"description"`
+	Active           bool       `json:"active"`
+	CreatedBy        string     `json:"created_by"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+}
+
+// DeliveryDto 投递记录
+type DeliveryDto struct {
+	DeliveryUUID     string     `json:"delivery_uuid"`
+	SubscriptionUUID string     `json:"subscription_uuid"`
+	EventUUID        string     `json:"event_uuid"`
+	EventType        string     `json:"event_type"`
+	Status           string     `json:"status"`
+	Attempts         int        `json:"attempts"`
+	NextAttemptAt    *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode   int        `json:"last_status_code,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+}
+
+// DeliveryListDto 投递记录列表
+type DeliveryListDto struct {
+	Deliveries []*DeliveryDto `json:"deliveries"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+}
+
+// DeliveryAttemptDto 一次投递尝试
+type DeliveryAttemptDto struct {
+	Attempt    int        `json:"attempt"`
+	StatusCode int        `json:"status_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+// DeliveryDetailDto 投递详情，包含请求体和全部投递尝试
+type DeliveryDetailDto struct {
+	*DeliveryDto
+	Payload    string                `json:"payload"`
+	AttemptLog []*DeliveryAttemptDto `json:"attempt_log"`
+}
@@ -0,0 +1,220 @@
+package entity
+
+import (
+	"go-video/ddd/webhook/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxDeliveryErrorLength 记录的失败原因最大长度
+const maxDeliveryErrorLength = 500
+
+// Delivery 一个事件到一个订阅的投递，请求体在创建时确定，重试和重新投递都发送相同的内容
+type Delivery struct {
+	uuid             string
+	subscriptionUuid string
+	eventUuid        string
+	eventType        vo.EventType
+	payload          []byte
+	status           vo.DeliveryStatus
+	attempts         int
+	nextAttemptAt    *time.Time
+	lastStatusCode   int
+	lastError        string
+	deliveredAt      *time.Time
+	createdAt        *time.Time
+}
+
+// DefaultDelivery 为订阅创建事件的投递，立即可以投递
+func DefaultDelivery(subscriptionUuid string, event *Event, payload []byte) *Delivery {
+	now := time.Now()
+	return &Delivery{
+		uuid:             uuid.New().String(),
+		subscriptionUuid: subscriptionUuid,
+		eventUuid:        event.UUID(),
+		eventType:        event.EventType(),
+		payload:          payload,
+		status:           vo.DeliveryStatusPending,
+		nextAttemptAt:    &now,
+	}
+}
+
+// NewDelivery 创建投递（用于从数据库加载）
+func NewDelivery(uuid, subscriptionUuid, eventUuid string, eventType vo.EventType, payload []byte, status vo.DeliveryStatus,
+	attempts int, nextAttemptAt *time.Time, lastStatusCode int, lastError string, deliveredAt, createdAt *time.Time) *Delivery {
+	return &Delivery{
+		uuid:             uuid,
+		subscriptionUuid: subscriptionUuid,
+		eventUuid:        eventUuid,
+		eventType:        eventType,
+		payload:          payload,
+		status:           status,
+		attempts:         attempts,
+		nextAttemptAt:    nextAttemptAt,
+		lastStatusCode:   lastStatusCode,
+		lastError:        lastError,
+		deliveredAt:      deliveredAt,
+		createdAt:        createdAt,
+	}
+}
+
+// UUID 获取投递UUID
+func (d *Delivery) UUID() string {
+	return d.uuid
+}
+
+// SubscriptionUuid 获取订阅UUID
+func (d *Delivery) SubscriptionUuid() string {
+	return d.subscriptionUuid
+}
+
+// EventUuid 获取事件UUID
+func (d *Delivery) EventUuid() string {
+	return d.eventUuid
+}
+
+// EventType 获取事件类型
+func (d *Delivery) EventType() vo.EventType {
+	return d.eventType
+}
+
+// Payload 获取请求体
+func (d *Delivery) Payload() []byte {
+	return d.payload
+}
+
+// Status 获取投递状态
+func (d *Delivery) Status() vo.DeliveryStatus {
+	return d.status
+}
+
+// Attempts 获取已投递次数
+func (d *Delivery) Attempts() int {
+	return d.attempts
+}
+
+// NextAttemptAt 获取下一次投递时间，成功或进入死信后为nil
+func (d *Delivery) NextAttemptAt() *time.Time {
+	return d.nextAttemptAt
+}
+
+// LastStatusCode 获取最近一次投递的HTTP状态码，请求未完成为0
+func (d *Delivery) LastStatusCode() int {
+	return d.lastStatusCode
+}
+
+// LastError 获取最近一次投递的失败原因
+func (d *Delivery) LastError() string {
+	return d.lastError
+}
+
+// DeliveredAt 获取投递成功时间
+func (d *Delivery) DeliveredAt() *time.Time {
+	return d.deliveredAt
+}
+
+// CreatedAt 获取创建时间
+func (d *Delivery) CreatedAt() *time.Time {
+	return d.createdAt
+}
+
+// RecordAttempt 记录一次投递结果：2xx为成功；失败时按重试策略安排下一次投递，次数用尽后进入死信
+func (d *Delivery) RecordAttempt(statusCode int, sendErr error, startedAt time.Time, duration time.Duration, policy vo.RetryPolicy) *DeliveryAttempt {
+	d.attempts++
+	d.lastStatusCode = statusCode
+	d.lastError = ""
+	if sendErr != nil {
+		d.lastError = truncate(sendErr.Error(), maxDeliveryErrorLength)
+	}
+
+	finishedAt := startedAt.Add(duration)
+	switch {
+	case sendErr == nil && statusCode >= 200 && statusCode <= 299:
+		d.status = vo.DeliveryStatusSucceeded
+		d.deliveredAt = &finishedAt
+		d.nextAttemptAt = nil
+	case d.attempts >= policy.MaxAttempts():
+		d.status = vo.DeliveryStatusDead
+		d.nextAttemptAt = nil
+	default:
+		next := finishedAt.Add(policy.Delay(d.attempts))
+		d.nextAttemptAt = &next
+	}
+	return &DeliveryAttempt{
+		deliveryUuid: d.uuid,
+		attempt:      d.attempts,
+		statusCode:   statusCode,
+		errorMsg:     d.lastError,
+		duration:     duration,
+		createdAt:    &startedAt,
+	}
+}
+
+// Redeliver 手动重新投递：重新开始计算重试次数，立即可以投递
+func (d *Delivery) Redeliver() {
+	now := time.Now()
+	d.status = vo.DeliveryStatusPending
+	d.attempts = 0
+	d.nextAttemptAt = &now
+}
+
+// DeliveryAttempt 一次投递尝试的记录
+type DeliveryAttempt struct {
+	deliveryUuid string
+	attempt      int
+	statusCode   int
+	errorMsg     string
+	duration     time.Duration
+	createdAt    *time.Time
+}
+
+// NewDeliveryAttempt 创建投递尝试记录（用于从数据库加载）
+func NewDeliveryAttempt(deliveryUuid string, attempt, statusCode int, errorMsg string, duration time.Duration, createdAt *time.Time) *DeliveryAttempt {
+	return &DeliveryAttempt{
+		deliveryUuid: deliveryUuid,
+		attempt:      attempt,
+		statusCode:   statusCode,
+		errorMsg:     errorMsg,
+		duration:     duration,
+		createdAt:    createdAt,
+	}
+}
+
+// DeliveryUuid 获取投递UUID
+func (a *DeliveryAttempt) DeliveryUuid() string {
+	return a.deliveryUuid
+}
+
+// Attempt 获取第几次投递，重新投递后从1重新计数
+func (a *DeliveryAttempt) Attempt() int {
+	return a.attempt
+}
+
+// StatusCode 获取HTTP状态码，请求未完成为0
+func (a *DeliveryAttempt) StatusCode() int {
+	return a.statusCode
+}
+
+// ErrorMsg 获取失败原因
+func (a *DeliveryAttempt) ErrorMsg() string {
+	return a.errorMsg
+}
+
+// Duration 获取请求耗时
+func (a *DeliveryAttempt) Duration() time.Duration {
+	return a.duration
+}
+
+// CreatedAt 获取投递时间
+func (a *DeliveryAttempt) CreatedAt() *time.Time {
+	return a.createdAt
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
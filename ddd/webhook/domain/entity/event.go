@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/json"
+	"go-video/ddd/webhook/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event 对外通知的事件
+type Event struct {
+	uuid       string
+	eventType  vo.EventType
+	occurredAt time.Time
+	data       map[string]interface{}
+}
+
+// DefaultEvent 创建刚发生的事件
+func DefaultEvent(eventType vo.EventType, data map[string]interface{}) *Event {
+	return &Event{
+		uuid:       uuid.New().String(),
+		eventType:  eventType,
+		occurredAt: time.Now(),
+		data:       data,
+	}
+}
+
+// NewEvent 创建指定ID和发生时间的事件，用于转发领域事件
+func NewEvent(uuid string, eventType vo.EventType, occurredAt time.Time, data map[string]interface{}) *Event {
+	return &Event{
+		uuid:       uuid,
+		eventType:  eventType,
+		occurredAt: occurredAt,
+		data:       data,
+	}
+}
+
+// UUID 获取事件UUID
+func (e *Event) UUID() string {
+	return e.uuid
+}
+
+// EventType 获取事件类型
+func (e *Event) EventType() vo.EventType {
+	return e.eventType
+}
+
+// Payload 投递的请求体：{"id", "type", "occurred_at", "data"}
+func (e *Event) Payload() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":          e.uuid,
+		"type":        e.eventType.Value(),
+		"occurred_at": e.occurredAt.UTC().Format(time.RFC3339),
+		"data":        e.data,
+	})
+}
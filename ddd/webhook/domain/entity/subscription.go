@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"go-video/ddd/webhook/domain/vo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription 事件订阅，事件发生时向 url 投递签名的POST请求
+type Subscription struct {
+	uuid        string
+	url         string
+	secret      string
+	eventTypes  []vo.EventType
+	description string
+	active      bool
+	createdBy   string
+	createdAt   *time.Time
+}
+
+// DefaultSubscription 创建新的订阅，默认启用
+func DefaultSubscription(url, secret string, eventTypes []vo.EventType, description, createdBy string) *Subscription {
+	return &Subscription{
+		uuid:        uuid.New().String(),
+		url:         url,
+		secret:      secret,
+		eventTypes:  eventTypes,
+		description: description,
+		active:      true,
+		createdBy:   createdBy,
+	}
+}
+
+// NewSubscription 创建订阅（用于从数据库加载）
+func NewSubscription(uuid, url, secret string, eventTypes []vo.EventType, description string, active bool, createdBy string,
+	createdAt *time.Time) *Subscription {
+	return &Subscription{
+		uuid:        uuid,
+		url:         url,
+		secret:      secret,
+		eventTypes:  eventTypes,
+		description: description,
+		active:      active,
+		createdBy:   createdBy,
+		createdAt:   createdAt,
+	}
+}
+
+// UUID 获取订阅UUID
+func (s *Subscription) UUID() string {
+	return s.uuid
+}
+
+// URL 获取投递地址
+func (s *Subscription) URL() string {
+	return s.url
+}
+
+// Secret 获取签名密钥
+func (s *Subscription) Secret() string {
+	return s.secret
+}
+
+// EventTypes 获取订阅的事件类型
+func (s *Subscription) EventTypes() []vo.EventType {
+	return s.eventTypes
+}
+
+// Description 获取备注
+func (s *Subscription) Description() string {
+	return s.description
+}
+
+// Active 是否启用
+func (s *Subscription) Active() bool {
+	return s.active
+}
+
+// CreatedBy 获取创建人UUID
+func (s *Subscription) CreatedBy() string {
+	return s.createdBy
+}
+
+// CreatedAt 获取创建时间
+func (s *Subscription) CreatedAt() *time.Time {
+	return s.createdAt
+}
+
+// Subscribes 是否订阅了该事件
+func (s *Subscription) Subscribes(eventType vo.EventType) bool {
+	for _, subscribed := range s.eventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SetURL 修改投递地址
+func (s *Subscription) SetURL(url string) {
+	s.url = url
+}
+
+// SetEventTypes 修改订阅的事件类型
+func (s *Subscription) SetEventTypes(eventTypes []vo.EventType) {
+	s.eventTypes = eventTypes
+}
+
+// SetDescription 修改备注
+func (s *Subscription) SetDescription(description string) {
+	s.description = description
+}
+
+// SetActive 启用或停用，停用后不再为新事件创建投递，已创建的投递照常重试
+func (s *Subscription) SetActive(active bool) {
+	s.active = active
+}
@@ -0,0 +1,11 @@
+package gateway
+
+import "context"
+
+// Sender 投递请求发送
+type Sender interface {
+	// CheckURL 校验投递地址的协议、主机名和网段
+	CheckURL(rawURL string) error
+	// Send 发送POST请求，返回HTTP状态码；请求未完成时返回错误
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"go-video/ddd/webhook/domain/entity"
+	"go-video/ddd/webhook/domain/vo"
+	"time"
+)
+
+// WebhookRepository 事件订阅和投递仓储接口
+type WebhookRepository interface {
+	// SaveSubscription 保存新的订阅
+	SaveSubscription(ctx context.Context, subscription *entity.Subscription) error
+	// UpdateSubscription 更新订阅的地址、事件类型、备注和启用状态
+	UpdateSubscription(ctx context.Context, subscription *entity.Subscription) error
+	// DeleteSubscription 删除订阅，未完成的投递不再重试
+	DeleteSubscription(ctx context.Context, subscriptionUUID string) error
+	// FindSubscription 根据UUID查找订阅，不存在时返回nil
+	FindSubscription(ctx context.Context, subscriptionUUID string) (*entity.Subscription, error)
+	// FindSubscriptions 查找全部订阅
+	FindSubscriptions(ctx context.Context) ([]*entity.Subscription, error)
+
+	// SaveDeliveries 保存新的投递
+	SaveDeliveries(ctx context.Context, deliveries []*entity.Delivery) error
+	// FindDueDeliveries 查找到期待投递的记录，最早到期的在前
+	FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*entity.Delivery, error)
+	// ClaimDelivery 以比较并交换的方式把下一次投递时间推迟到 leaseUntil，成功返回true。
+	// 多个实例同时扫描时只有一个能领取；领取后进程退出，租约到期后会被重新投递
+	ClaimDelivery(ctx context.Context, delivery *entity.Delivery, leaseUntil time.Time) (bool, error)
+	// UpdateDelivery 在一个事务中更新投递状态并写入投递尝试记录（可为nil）
+	UpdateDelivery(ctx context.Context, delivery *entity.Delivery, attempt *entity.DeliveryAttempt) error
+	// FindDelivery 根据UUID查找投递，不存在时返回nil
+	FindDelivery(ctx context.Context, deliveryUUID string) (*entity.Delivery, error)
+	// FindDeliveries 分页查找投递，最新的在前，过滤条件为空表示不限制
+	FindDeliveries(ctx context.Context, status *vo.DeliveryStatus, subscriptionUUID string, page *vo.Page) ([]*entity.Delivery, int64, error)
+	// FindAttempts 查找投递的全部尝试记录，按时间先后排序
+	FindAttempts(ctx context.Context, deliveryUUID string) ([]*entity.DeliveryAttempt, error)
+}
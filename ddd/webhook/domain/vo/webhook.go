@@ -0,0 +1,149 @@
+package vo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// EventType 事件类型
+type EventType struct {
+	value string
+}
+
+var (
+	// EventTypeUploadCompleted 视频源文件上传完成
+	EventTypeUploadCompleted = EventType{
+		"upload.completed",
+	}
+	// EventTypeUploadFailed 视频源文件上传失败
+	EventTypeUploadFailed = EventType{
+		"upload.failed",
+	}
+	// EventTypeVideoDeleted 视频被删除
+	EventTypeVideoDeleted = EventType{
+		"video.deleted",
+	}
+	// EventTypeUserRegistered 新用户注册
+	EventTypeUserRegistered = EventType{
+		"user.registered",
+	}
+)
+
+var EventTypes = []EventType{
+	EventTypeUploadCompleted,
+	EventTypeUploadFailed,
+	EventTypeVideoDeleted,
+	EventTypeUserRegistered,
+}
+
+// NewEventType 根据字符串创建事件类型
+func NewEventType(value string) (EventType, bool) {
+	for _, eventType := range EventTypes {
+		if eventType.value == value {
+			return eventType, true
+		}
+	}
+	return EventType{}, false
+}
+
+// Value 返回类型的字符串值
+func (t EventType) Value() string {
+	return t.value
+}
+
+// DeliveryStatus 投递状态
+type DeliveryStatus struct {
+	value string
+}
+
+var (
+	// DeliveryStatusPending 等待投递或等待重试
+	DeliveryStatusPending = DeliveryStatus{
+		"pending",
+	}
+	// DeliveryStatusSucceeded 下游返回2xx
+	DeliveryStatusSucceeded = DeliveryStatus{
+		"succeeded",
+	}
+	// DeliveryStatusDead 重试次数用尽，进入死信，只能手动重新投递
+	DeliveryStatusDead = DeliveryStatus{
+		"dead",
+	}
+)
+
+var DeliveryStatuses = []DeliveryStatus{
+	DeliveryStatusPending,
+	DeliveryStatusSucceeded,
+	DeliveryStatusDead,
+}
+
+// NewDeliveryStatus 根据字符串创建投递状态
+func NewDeliveryStatus(value string) (DeliveryStatus, bool) {
+	for _, status := range DeliveryStatuses {
+		if status.value == value {
+			return status, true
+		}
+	}
+	return DeliveryStatus{}, false
+}
+
+// Value 返回状态的字符串值
+func (s DeliveryStatus) Value() string {
+	return s.value
+}
+
+// RetryPolicy 投递重试策略：第n次失败后等待 baseDelay * 2^(n-1)，不超过 maxDelay
+type RetryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// NewRetryPolicy 创建重试策略
+func NewRetryPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) RetryPolicy {
+	return RetryPolicy{
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+	}
+}
+
+// MaxAttempts 获取最多投递次数
+func (p RetryPolicy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+// Delay 第 attempts 次投递失败后到下一次投递的等待时间
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < attempts && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.maxDelay {
+		return p.maxDelay
+	}
+	return delay
+}
+
+const (
+	// SignatureHeader 签名请求头，值为 sha256=<HMAC-SHA256(secret, 时间戳 + "." + 请求体) 的十六进制>
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader 签名时间戳请求头（Unix秒），接收方应拒绝时间相差过大的请求以防重放
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader 事件类型请求头
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader 投递UUID请求头，重试和重新投递时不变，接收方可据此去重
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Signature 计算请求签名
+func Signature(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,108 @@
+package convertor
+
+import (
+	"go-video/ddd/webhook/domain/entity"
+	"go-video/ddd/webhook/domain/vo"
+	"go-video/ddd/webhook/infrastructure/database/po"
+	"strings"
+	"time"
+)
+
+// WebhookConvertor 订阅和投递转换器
+type WebhookConvertor struct{}
+
+// NewWebhookConvertor 创建订阅和投递转换器实例
+func NewWebhookConvertor() *WebhookConvertor {
+	return &WebhookConvertor{}
+}
+
+// SubscriptionToPO 订阅实体转PO
+func (c *WebhookConvertor) SubscriptionToPO(subscription *entity.Subscription) *po.SubscriptionPo {
+	if subscription == nil {
+		return nil
+	}
+	eventTypes := make([]string, 0, len(subscription.EventTypes()))
+	for _, eventType := range subscription.EventTypes() {
+		eventTypes = append(eventTypes, eventType.Value())
+	}
+	return &po.SubscriptionPo{
+		UUID:        subscription.UUID(),
+		URL:         subscription.URL(),
+		Secret:      subscription.Secret(),
+		EventTypes:  strings.Join(eventTypes, ","),
+		Description: subscription.Description(),
+		Active:      subscription.Active(),
+		CreatedBy:   subscription.CreatedBy(),
+	}
+}
+
+// POToSubscription 订阅PO转实体，忽略无法识别的事件类型
+func (c *WebhookConvertor) POToSubscription(subscriptionPo *po.SubscriptionPo) *entity.Subscription {
+	if subscriptionPo == nil {
+		return nil
+	}
+	var eventTypes []vo.EventType
+	for _, value := range strings.Split(subscriptionPo.EventTypes, ",") {
+		if eventType, ok := vo.NewEventType(value); ok {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return entity.NewSubscription(subscriptionPo.UUID, subscriptionPo.URL, subscriptionPo.Secret, eventTypes,
+		subscriptionPo.Description, subscriptionPo.Active, subscriptionPo.CreatedBy, subscriptionPo.CreatedAt)
+}
+
+// DeliveryToPO 投递实体转PO
+func (c *WebhookConvertor) DeliveryToPO(delivery *entity.Delivery) *po.DeliveryPo {
+	if delivery == nil {
+		return nil
+	}
+	return &po.DeliveryPo{
+		UUID:             delivery.UUID(),
+		SubscriptionUUID: delivery.SubscriptionUuid(),
+		EventUUID:        delivery.EventUuid(),
+		EventType:        delivery.EventType().Value(),
+		Payload:          string(delivery.Payload()),
+		Status:           delivery.Status().Value(),
+		Attempts:         delivery.Attempts(),
+		NextAttemptAt:    delivery.NextAttemptAt(),
+		LastStatusCode:   delivery.LastStatusCode(),
+		LastError:        delivery.LastError(),
+		DeliveredAt:      delivery.DeliveredAt(),
+	}
+}
+
+// POToDelivery 投递PO转实体
+func (c *WebhookConvertor) POToDelivery(deliveryPo *po.DeliveryPo) *entity.Delivery {
+	if deliveryPo == nil {
+		return nil
+	}
+	eventType, _ := vo.NewEventType(deliveryPo.EventType)
+	status, _ := vo.NewDeliveryStatus(deliveryPo.Status)
+	return entity.NewDelivery(deliveryPo.UUID, deliveryPo.SubscriptionUUID, deliveryPo.EventUUID, eventType,
+		[]byte(deliveryPo.Payload), status, deliveryPo.Attempts, deliveryPo.NextAttemptAt, deliveryPo.LastStatusCode,
+		deliveryPo.LastError, deliveryPo.DeliveredAt, deliveryPo.CreatedAt)
+}
+
+// AttemptToPO 投递尝试实体转PO
+func (c *WebhookConvertor) AttemptToPO(attempt *entity.DeliveryAttempt) *po.DeliveryAttemptPo {
+	if attempt == nil {
+		return nil
+	}
+	return &po.DeliveryAttemptPo{
+		BaseModel:    po.BaseModel{CreatedAt: attempt.CreatedAt()},
+		DeliveryUUID: attempt.DeliveryUuid(),
+		Attempt:      attempt.Attempt(),
+		StatusCode:   attempt.StatusCode(),
+		ErrorMsg:     attempt.ErrorMsg(),
+		DurationMs:   attempt.Duration().Milliseconds(),
+	}
+}
+
+// POToAttempt 投递尝试PO转实体
+func (c *WebhookConvertor) POToAttempt(attemptPo *po.DeliveryAttemptPo) *entity.DeliveryAttempt {
+	if attemptPo == nil {
+		return nil
+	}
+	return entity.NewDeliveryAttempt(attemptPo.DeliveryUUID, attemptPo.Attempt, attemptPo.StatusCode, attemptPo.ErrorMsg,
+		time.Duration(attemptPo.DurationMs)*time.Millisecond, attemptPo.CreatedAt)
+}
@@ -0,0 +1,174 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"go-video/ddd/internal/resource"
+	"go-video/ddd/webhook/infrastructure/database/po"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookDao struct {
+	db *gorm.DB
+}
+
+func NewWebhookDao() *WebhookDao {
+	return &WebhookDao{
+		db: resource.DefaultMysqlResource().MainDB(),
+	}
+}
+
+// CreateSubscription 创建订阅
+func (d *WebhookDao) CreateSubscription(ctx context.Context, subscriptionPo *po.SubscriptionPo) error {
+	return d.db.WithContext(ctx).Create(subscriptionPo).Error
+}
+
+// UpdateSubscription 更新订阅的地址、事件类型、备注和启用状态
+func (d *WebhookDao) UpdateSubscription(ctx context.Context, subscriptionPo *po.SubscriptionPo) error {
+	return d.db.WithContext(ctx).Model(&po.SubscriptionPo{}).
+		Where("uuid = ? AND is_deleted = 0", subscriptionPo.UUID).
+		Updates(map[string]interface{}{
+			"url":         subscriptionPo.URL,
+			"event_types": subscriptionPo.EventTypes,
+			"description": subscriptionPo.Description,
+			"active":      subscriptionPo.Active,
+		}).Error
+}
+
+// DeleteSubscription 软删除订阅，并把未完成的投递转为死信
+func (d *WebhookDao) DeleteSubscription(ctx context.Context, uuid string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.SubscriptionPo{}).
+			Where("uuid = ? AND is_deleted = 0", uuid).
+			Update("is_deleted", 1).Error; err != nil {
+			return err
+		}
+		return tx.Model(&po.DeliveryPo{}).
+			Where("subscription_uuid = ? AND status = ? AND is_deleted = 0", uuid, "pending").
+			Updates(map[string]interface{}{
+				"status":          "dead",
+				"next_attempt_at": nil,
+				"last_error":      "subscription deleted",
+			}).Error
+	})
+}
+
+// GetSubscription 根据UUID获取订阅
+func (d *WebhookDao) GetSubscription(ctx context.Context, uuid string) (*po.SubscriptionPo, error) {
+	var subscriptionPo po.SubscriptionPo
+	err := d.db.WithContext(ctx).First(&subscriptionPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscriptionPo, nil
+}
+
+// GetSubscriptions 获取全部订阅
+func (d *WebhookDao) GetSubscriptions(ctx context.Context) ([]*po.SubscriptionPo, error) {
+	var subscriptionPos []*po.SubscriptionPo
+	err := d.db.WithContext(ctx).
+		Where("is_deleted = 0").
+		Order("id ASC").
+		Find(&subscriptionPos).Error
+	return subscriptionPos, err
+}
+
+// CreateDeliveries 批量创建投递
+func (d *WebhookDao) CreateDeliveries(ctx context.Context, deliveryPos []*po.DeliveryPo) error {
+	if len(deliveryPos) == 0 {
+		return nil
+	}
+	// 同一事件重复发布时（领域事件至少投递一次）已存在的投递保持不变
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveryPos).Error
+}
+
+// GetDueDeliveries 获取到期待投递的记录
+func (d *WebhookDao) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*po.DeliveryPo, error) {
+	var deliveryPos []*po.DeliveryPo
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ? AND is_deleted = 0", "pending", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveryPos).Error
+	return deliveryPos, err
+}
+
+// ClaimDelivery 仅当下一次投递时间未被其他实例修改时推迟到 leaseUntil
+func (d *WebhookDao) ClaimDelivery(ctx context.Context, uuid string, nextAttemptAt *time.Time, leaseUntil time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&po.DeliveryPo{}).
+		Where("uuid = ? AND status = ? AND next_attempt_at = ? AND is_deleted = 0", uuid, "pending", nextAttemptAt).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateDelivery 在一个事务中更新投递状态并写入投递尝试记录
+func (d *WebhookDao) UpdateDelivery(ctx context.Context, deliveryPo *po.DeliveryPo, attemptPo *po.DeliveryAttemptPo) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.DeliveryPo{}).
+			Where("uuid = ? AND is_deleted = 0", deliveryPo.UUID).
+			Updates(map[string]interface{}{
+				"status":           deliveryPo.Status,
+				"attempts":         deliveryPo.Attempts,
+				"next_attempt_at":  deliveryPo.NextAttemptAt,
+				"last_status_code": deliveryPo.LastStatusCode,
+				"last_error":       deliveryPo.LastError,
+				"delivered_at":     deliveryPo.DeliveredAt,
+			}).Error; err != nil {
+			return err
+		}
+		if attemptPo == nil {
+			return nil
+		}
+		return tx.Create(attemptPo).Error
+	})
+}
+
+// GetDelivery 根据UUID获取投递
+func (d *WebhookDao) GetDelivery(ctx context.Context, uuid string) (*po.DeliveryPo, error) {
+	var deliveryPo po.DeliveryPo
+	err := d.db.WithContext(ctx).First(&deliveryPo, "uuid = ? AND is_deleted = 0", uuid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deliveryPo, nil
+}
+
+// GetDeliveries 分页获取投递，status 和 subscriptionUUID 为空表示不限制
+func (d *WebhookDao) GetDeliveries(ctx context.Context, status, subscriptionUUID string, offset, limit int) ([]*po.DeliveryPo, int64, error) {
+	query := d.db.WithContext(ctx).Model(&po.DeliveryPo{}).Where("is_deleted = 0")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if subscriptionUUID != "" {
+		query = query.Where("subscription_uuid = ?", subscriptionUUID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveryPos []*po.DeliveryPo
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveryPos).Error
+	return deliveryPos, total, err
+}
+
+// GetAttempts 获取投递的全部尝试记录
+func (d *WebhookDao) GetAttempts(ctx context.Context, deliveryUUID string) ([]*po.DeliveryAttemptPo, error) {
+	var attemptPos []*po.DeliveryAttemptPo
+	err := d.db.WithContext(ctx).
+		Where("delivery_uuid = ? AND is_deleted = 0", deliveryUUID).
+		Order("id ASC").
+		Find(&attemptPos).Error
+	return attemptPos, err
+}
@@ -0,0 +1,134 @@
+package persistence
+
+import (
+	"context"
+	"go-video/ddd/webhook/domain/entity"
+	"go-video/ddd/webhook/domain/repo"
+	"go-video/ddd/webhook/domain/vo"
+	"go-video/ddd/webhook/infrastructure/database/convertor"
+	"go-video/ddd/webhook/infrastructure/database/dao"
+	"go-video/ddd/webhook/infrastructure/database/po"
+	"time"
+)
+
+// webhookRepositoryImpl 订阅和投递仓储实现
+type webhookRepositoryImpl struct {
+	webhookDao       *dao.WebhookDao
+	webhookConvertor *convertor.WebhookConvertor
+}
+
+// NewWebhookRepository 创建订阅和投递仓储实例（支持依赖注入）
+func NewWebhookRepository() repo.WebhookRepository {
+	return &webhookRepositoryImpl{
+		webhookDao:       dao.NewWebhookDao(),
+		webhookConvertor: convertor.NewWebhookConvertor(),
+	}
+}
+
+// SaveSubscription 保存新的订阅
+func (r *webhookRepositoryImpl) SaveSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	return r.webhookDao.CreateSubscription(ctx, r.webhookConvertor.SubscriptionToPO(subscription))
+}
+
+// UpdateSubscription 更新订阅
+func (r *webhookRepositoryImpl) UpdateSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	return r.webhookDao.UpdateSubscription(ctx, r.webhookConvertor.SubscriptionToPO(subscription))
+}
+
+// DeleteSubscription 删除订阅
+func (r *webhookRepositoryImpl) DeleteSubscription(ctx context.Context, subscriptionUUID string) error {
+	return r.webhookDao.DeleteSubscription(ctx, subscriptionUUID)
+}
+
+// FindSubscription 根据UUID查找订阅
+func (r *webhookRepositoryImpl) FindSubscription(ctx context.Context, subscriptionUUID string) (*entity.Subscription, error) {
+	subscriptionPo, err := r.webhookDao.GetSubscription(ctx, subscriptionUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.webhookConvertor.POToSubscription(subscriptionPo), nil
+}
+
+// FindSubscriptions 查找全部订阅
+func (r *webhookRepositoryImpl) FindSubscriptions(ctx context.Context) ([]*entity.Subscription, error) {
+	subscriptionPos, err := r.webhookDao.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := make([]*entity.Subscription, 0, len(subscriptionPos))
+	for _, subscriptionPo := range subscriptionPos {
+		subscriptions = append(subscriptions, r.webhookConvertor.POToSubscription(subscriptionPo))
+	}
+	return subscriptions, nil
+}
+
+// SaveDeliveries 保存新的投递
+func (r *webhookRepositoryImpl) SaveDeliveries(ctx context.Context, deliveries []*entity.Delivery) error {
+	deliveryPos := make([]*po.DeliveryPo, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryPos = append(deliveryPos, r.webhookConvertor.DeliveryToPO(delivery))
+	}
+	return r.webhookDao.CreateDeliveries(ctx, deliveryPos)
+}
+
+// FindDueDeliveries 查找到期待投递的记录
+func (r *webhookRepositoryImpl) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*entity.Delivery, error) {
+	deliveryPos, err := r.webhookDao.GetDueDeliveries(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDeliveries(deliveryPos), nil
+}
+
+// ClaimDelivery 领取投递
+func (r *webhookRepositoryImpl) ClaimDelivery(ctx context.Context, delivery *entity.Delivery, leaseUntil time.Time) (bool, error) {
+	return r.webhookDao.ClaimDelivery(ctx, delivery.UUID(), delivery.NextAttemptAt(), leaseUntil)
+}
+
+// UpdateDelivery 更新投递状态并写入投递尝试记录
+func (r *webhookRepositoryImpl) UpdateDelivery(ctx context.Context, delivery *entity.Delivery, attempt *entity.DeliveryAttempt) error {
+	return r.webhookDao.UpdateDelivery(ctx, r.webhookConvertor.DeliveryToPO(delivery), r.webhookConvertor.AttemptToPO(attempt))
+}
+
+// FindDelivery 根据UUID查找投递
+func (r *webhookRepositoryImpl) FindDelivery(ctx context.Context, deliveryUUID string) (*entity.Delivery, error) {
+	deliveryPo, err := r.webhookDao.GetDelivery(ctx, deliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+	return r.webhookConvertor.POToDelivery(deliveryPo), nil
+}
+
+// FindDeliveries 分页查找投递
+func (r *webhookRepositoryImpl) FindDeliveries(ctx context.Context, status *vo.DeliveryStatus, subscriptionUUID string, page *vo.Page) ([]*entity.Delivery, int64, error) {
+	statusValue := ""
+	if status != nil {
+		statusValue = status.Value()
+	}
+	deliveryPos, total, err := r.webhookDao.GetDeliveries(ctx, statusValue, subscriptionUUID, page.Offset(), page.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toDeliveries(deliveryPos), total, nil
+}
+
+// FindAttempts 查找投递的全部尝试记录
+func (r *webhookRepositoryImpl) FindAttempts(ctx context.Context, deliveryUUID string) ([]*entity.DeliveryAttempt, error) {
+	attemptPos, err := r.webhookDao.GetAttempts(ctx, deliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]*entity.DeliveryAttempt, 0, len(attemptPos))
+	for _, attemptPo := range attemptPos {
+		attempts = append(attempts, r.webhookConvertor.POToAttempt(attemptPo))
+	}
+	return attempts, nil
+}
+
+func (r *webhookRepositoryImpl) toDeliveries(deliveryPos []*po.DeliveryPo) []*entity.Delivery {
+	deliveries := make([]*entity.Delivery, 0, len(deliveryPos))
+	for _, deliveryPo := range deliveryPos {
+		deliveries = append(deliveries, r.webhookConvertor.POToDelivery(deliveryPo))
+	}
+	return deliveries
+}
@@ -0,0 +1,53 @@
+package po
+
+import "time"
+
+type SubscriptionPo struct {
+	BaseModel
+
+	UUID        string `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	URL         string `gorm:"size:1024;not null;column:url" json:"url"`
+	Secret      string `gorm:"size:128;not null;column:secret" json:"-"`
+	EventTypes  string `gorm:"size:255;not null;column:event_types" json:"event_types"` // 逗号分隔
+	Description string `gorm:"size:255;column:description" json:"description"`
+	Active      bool   `gorm:"not null;column:active" json:"active"`
+	CreatedBy   string `gorm:"size:36;not null;column:created_by" json:"created_by"`
+}
+
+func (s *SubscriptionPo) TableName() string {
+	return "webhook_subscription"
+}
+
+type DeliveryPo struct {
+	BaseModel
+
+	UUID             string     `gorm:"uniqueIndex;size:36;not null;column:uuid" json:"uuid"`
+	SubscriptionUUID string     `gorm:"uniqueIndex:uk_subscription_event;size:36;not null;column:subscription_uuid" json:"subscription_uuid"`
+	EventUUID        string     `gorm:"uniqueIndex:uk_subscription_event;size:36;not null;column:event_uuid" json:"event_uuid"`
+	EventType        string     `gorm:"size:50;not null;column:event_type" json:"event_type"`
+	Payload          string     `gorm:"type:text;not null;column:payload" json:"payload"`
+	Status           string     `gorm:"index;size:20;not null;column:status" json:"status"`
+	Attempts         int        `gorm:"not null;column:attempts" json:"attempts"`
+	NextAttemptAt    *time.Time `gorm:"index;column:next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode   int        `gorm:"column:last_status_code" json:"last_status_code"`
+	LastError        string     `gorm:"size:500;column:last_error" json:"last_error"`
+	DeliveredAt      *time.Time `gorm:"column:delivered_at" json:"delivered_at"`
+}
+
+func (d *DeliveryPo) TableName() string {
+	return "webhook_delivery"
+}
+
+type DeliveryAttemptPo struct {
+	BaseModel
+
+	DeliveryUUID string `gorm:"index;size:36;not null;column:delivery_uuid" json:"delivery_uuid"`
+	Attempt      int    `gorm:"not null;column:attempt" json:"attempt"`
+	StatusCode   int    `gorm:"column:status_code" json:"status_code"`
+	ErrorMsg     string `gorm:"size:500;column:error_msg" json:"error_msg"`
+	DurationMs   int64  `gorm:"column:duration_ms" json:"duration_ms"`
+}
+
+func (a *DeliveryAttemptPo) TableName() string {
+	return "webhook_delivery_attempt"
+}
@@ -0,0 +1,106 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go-video/ddd/webhook/domain/gateway"
+	"go-video/pkg/assert"
+	"go-video/pkg/config"
+	"go-video/pkg/netguard"
+)
+
+const (
+	// defaultTimeout 未配置时单次投递的超时
+	defaultTimeout = 10 * time.Second
+	// maxDiscardBody 读取并丢弃的响应体上限，读完响应体才能复用连接
+	maxDiscardBody = 64 << 10
+)
+
+var (
+	senderOnce      sync.Once
+	singletonSender gateway.Sender
+)
+
+// HTTPSender 发送投递请求，投递地址经过访问策略校验，不跟随重定向
+type HTTPSender struct {
+	policy *netguard.Policy
+	client *http.Client
+}
+
+// DefaultSender 获取按全局配置创建的投递请求发送实例
+func DefaultSender() gateway.Sender {
+	assert.NotCircular()
+	senderOnce.Do(func() {
+		singletonSender = mustNewHTTPSender()
+	})
+	assert.NotNil(singletonSender)
+	return singletonSender
+}
+
+// NewHTTPSender 创建投递请求发送实例，timeout为整个请求的超时
+func NewHTTPSender(policy *netguard.Policy, timeout time.Duration) *HTTPSender {
+	client := policy.Client(timeout)
+	// 3xx视为投递失败，避免订阅方通过重定向把签名请求转发到其他地址
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &HTTPSender{
+		policy: policy,
+		client: client,
+	}
+}
+
+func mustNewHTTPSender() *HTTPSender {
+	var webhookConfig config.WebhookConfig
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		webhookConfig = cfg.Webhook
+	}
+	policy, err := netguard.NewPolicy(webhookConfig.AllowedSchemes, webhookConfig.AllowedHosts, webhookConfig.AllowedNetworks)
+	if err != nil {
+		panic(fmt.Sprintf("invalid webhook config: %v", err))
+	}
+	timeout := webhookConfig.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return NewHTTPSender(policy, timeout)
+}
+
+// CheckURL 校验URL格式、协议和主机名
+func (s *HTTPSender) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &netguard.DeniedError{Target: rawURL, Reason: err.Error()}
+	}
+	return s.policy.CheckURL(u)
+}
+
+// Send 发送POST请求并返回状态码
+func (s *HTTPSender) Send(ctx context.Context, rawURL string, headers map[string]string, body []byte) (int, error) {
+	if err := s.CheckURL(rawURL); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-video-webhook/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardBody))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"go-video/ddd/webhook/adapter/handler"
+	"go-video/ddd/webhook/adapter/http"
+	"go-video/ddd/webhook/adapter/job"
+	"go-video/pkg/manager"
+)
+
+// init 包初始化函数，注册事件订阅控制器插件、事件投递组件和领域事件处理器
+func init() {
+	// 注册事件订阅控制器插件到管理器
+	manager.RegisterControllerPlugin(&http.WebhookControllerPlugin{})
+	manager.RegisterComponentPlugin(&job.DeliveryComponentPlugin{})
+	manager.RegisterEventHandlerPlugin(&handler.DomainEventHandlerPlugin{})
+}
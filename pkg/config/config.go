@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/spf13/viper"
@@ -15,6 +17,25 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
 	Minio    MinioConfig    `mapstructure:"minio"`
+	Admin    AdminConfig    `mapstructure:"admin"`
+
+	Moderation ModerationConfig `mapstructure:"moderation"`
+	Report     ReportConfig     `mapstructure:"report"`
+	Reconcile  ReconcileConfig  `mapstructure:"reconcile"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+
+	SourceVersion SourceVersionConfig `mapstructure:"source_version"`
+	Import        ImportConfig        `mapstructure:"import"`
+	Export        ExportConfig        `mapstructure:"export"`
+	Webhook       WebhookConfig       `mapstructure:"webhook"`
+	EventBus      EventBusConfig      `mapstructure:"event_bus"`
+	Transcode     TranscodeConfig     `mapstructure:"transcode"`
+	Playback      PlaybackConfig      `mapstructure:"playback"`
+	Artwork       ArtworkConfig       `mapstructure:"artwork"`
+	Faststart     FaststartConfig     `mapstructure:"faststart"`
+	Pipeline      PipelineConfig      `mapstructure:"pipeline"`
+	Scan          ScanConfig          `mapstructure:"scan"`
+	Live          LiveConfig          `mapstructure:"live"`
 }
 
 // ServerConfig 服务器配置
@@ -58,6 +79,201 @@ type MinioConfig struct {
 	BucketName      string `mapstructure:"bucket_name"`
 }
 
+// AdminConfig 管理员配置：运维接口（对账、密钥轮换、数据导出、webhook、处理流水线等）只允许管理员访问
+type AdminConfig struct {
+	Users []string `mapstructure:"users"` // 管理员的用户UUID，为空表示没有管理员
+}
+
+// ModerationConfig 内容审核配置
+type ModerationConfig struct {
+	Mode       string        `mapstructure:"mode"`       // off: 不审核；pre_publish: 上传完成后需审核通过才公开
+	ClaimTTL   time.Duration `mapstructure:"claim_ttl"`  // 审核认领的锁定时长，超时后其他审核员可重新认领
+	Moderators []string      `mapstructure:"moderators"` // 允许审核的用户UUID，为空表示只有管理员可以审核
+}
+
+// ReportConfig 用户举报配置
+type ReportConfig struct {
+	RateLimit  int           `mapstructure:"rate_limit"`  // 时间窗口内单个用户最多提交的举报数
+	RateWindow time.Duration `mapstructure:"rate_window"` // 限流时间窗口
+}
+
+// ReconcileConfig 对象存储与数据库对账配置
+type ReconcileConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`      // 是否启用定时对账
+	Interval    time.Duration `mapstructure:"interval"`     // 定时对账间隔
+	GracePeriod time.Duration `mapstructure:"grace_period"` // 宽限期，比它新的对象和记录不做处理，避免误伤进行中的上传
+	DryRun      bool          `mapstructure:"dry_run"`      // 定时对账只生成报告，不删除对象也不修改状态
+}
+
+// EncryptionConfig 视频静态加密配置
+type EncryptionConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`       // 是否加密新上传的视频
+	ActiveKeyID string            `mapstructure:"active_key_id"` // 包装新数据密钥使用的主密钥ID
+	MasterKeys  map[string]string `mapstructure:"master_keys"`   // 主密钥ID -> base64编码的32字节密钥，ID会被转为小写；轮换后旧密钥需保留到重新包装完成
+	ChunkSize   int               `mapstructure:"chunk_size"`    // 加密分块大小（字节），只影响新上传的对象
+}
+
+// SourceVersionConfig 视频源文件版本配置
+type SourceVersionConfig struct {
+	Retention     time.Duration `mapstructure:"retention"`      // 被替换的源文件保留时长，保留期内可以回滚
+	PurgeEnabled  bool          `mapstructure:"purge_enabled"`  // 是否定时清理超过保留期的源文件
+	PurgeInterval time.Duration `mapstructure:"purge_interval"` // 定时清理间隔
+}
+
+// ImportConfig 从远程URL导入视频配置
+type ImportConfig struct {
+	AllowedSchemes  []string      `mapstructure:"allowed_schemes"`  // 允许的协议，为空时只允许https
+	AllowedHosts    []string      `mapstructure:"allowed_hosts"`    // 允许的主机名，支持 *.example.com，为空时不限制主机名
+	AllowedNetworks []string      `mapstructure:"allowed_networks"` // 允许访问的内网网段（CIDR或IP），其余内网和保留地址一律禁止
+	MaxSize         int64         `mapstructure:"max_size"`         // 单个文件大小上限（字节）
+	Timeout         time.Duration `mapstructure:"timeout"`          // 单次导入的总超时，包含下载和写入存储
+}
+
+// ExportConfig 个人数据导出配置
+type ExportConfig struct {
+	RateWindow    time.Duration `mapstructure:"rate_window"`     // 同一用户两次自助导出的最小间隔
+	LinkTTL       time.Duration `mapstructure:"link_ttl"`        // 导出包下载链接的有效期，每次查询时重新签发
+	InlineMaxSize int64         `mapstructure:"inline_max_size"` // 源文件超过该大小（字节）时不打包，改为附带预签名链接；加密的源文件始终打包
+	SourceLinkTTL time.Duration `mapstructure:"source_link_ttl"` // 源文件预签名链接的有效期，不能超过7天
+	Timeout       time.Duration `mapstructure:"timeout"`         // 单次导出的总超时
+}
+
+// WebhookConfig 事件通知（webhook）配置
+type WebhookConfig struct {
+	AllowedSchemes  []string      `mapstructure:"allowed_schemes"`  // 允许的协议，为空时只允许https
+	AllowedHosts    []string      `mapstructure:"allowed_hosts"`    // 允许的主机名，支持 *.example.com，为空时不限制主机名
+	AllowedNetworks []string      `mapstructure:"allowed_networks"` // 允许投递的内网网段（CIDR或IP），其余内网和保留地址一律禁止
+	Timeout         time.Duration `mapstructure:"timeout"`          // 单次投递的超时
+	MaxAttempts     int           `mapstructure:"max_attempts"`     // 最多投递次数，全部失败后进入死信
+	RetryBaseDelay  time.Duration `mapstructure:"retry_base_delay"` // 首次重试的等待时间，之后每次翻倍
+	RetryMaxDelay   time.Duration `mapstructure:"retry_max_delay"`  // 重试等待时间上限
+	PollInterval    time.Duration `mapstructure:"poll_interval"`    // 投递任务扫描待投递记录的间隔
+}
+
+// EventBusConfig 领域事件发件箱分发配置
+type EventBusConfig struct {
+	PollInterval   time.Duration `mapstructure:"poll_interval"`    // 扫描发件箱的间隔
+	MaxAttempts    int           `mapstructure:"max_attempts"`     // 最多分发次数，全部失败后标记为failed
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"` // 首次重试的等待时间，之后每次翻倍
+	RetryMaxDelay  time.Duration `mapstructure:"retry_max_delay"`  // 重试等待时间上限
+	HandlerTimeout time.Duration `mapstructure:"handler_timeout"`  // 单个处理器处理一个事件的超时
+}
+
+// TranscodeConfig 视频转码配置
+type TranscodeConfig struct {
+	Enabled         bool              `mapstructure:"enabled"`          // 是否转码，关闭时上传完成后直接进入可播放状态
+	Driver          string            `mapstructure:"driver"`           // ffmpeg，或 fake（只复制源文件，用于没有ffmpeg的开发和测试环境）
+	FFmpegPath      string            `mapstructure:"ffmpeg_path"`      // 为空时从PATH中查找
+	FFprobePath     string            `mapstructure:"ffprobe_path"`     // 为空时从PATH中查找
+	WorkDir         string            `mapstructure:"work_dir"`         // 源文件和转码输出的临时目录，为空时使用系统临时目录
+	Concurrency     int               `mapstructure:"concurrency"`      // 每个实例同时转码的档位数
+	PollInterval    time.Duration     `mapstructure:"poll_interval"`    // 扫描待转码档位的间隔
+	MaxAttempts     int               `mapstructure:"max_attempts"`     // 每个档位最多转码次数，之后标记为失败
+	RetryDelay      time.Duration     `mapstructure:"retry_delay"`      // 失败后的重试间隔，随次数线性增长
+	Timeout         time.Duration     `mapstructure:"timeout"`          // 单个档位的转码超时
+	SegmentDuration time.Duration     `mapstructure:"segment_duration"` // HLS和DASH分片的目标时长，应为2秒（关键帧间隔）的整数倍
+	Ladder          []RenditionConfig `mapstructure:"ladder"`           // 码率阶梯，为空时使用默认的 1080p/720p/480p
+	HLSEncryption   bool              `mapstructure:"hls_encryption"`   // HLS分片使用AES-128加密，密钥经由密钥接口发放，需要配置 encryption 主密钥；开启后不提供DASH
+	KeyRotation     int               `mapstructure:"key_rotation"`     // 加密时每个密钥使用的分片数，为0时使用默认值10
+}
+
+// RenditionConfig 码率阶梯中的一档
+type RenditionConfig struct {
+	Name         string `mapstructure:"name"`          // 档位名称，例如 720p
+	Height       int    `mapstructure:"height"`        // 输出高度上限，源视频更低时不放大
+	VideoBitrate int    `mapstructure:"video_bitrate"` // 视频码率（kbps）
+	AudioBitrate int    `mapstructure:"audio_bitrate"` // 音频码率（kbps）
+	Required     bool   `mapstructure:"required"`      // 该档位转码成功后视频才进入可播放状态
+}
+
+// PlaybackConfig 播放配置
+type PlaybackConfig struct {
+	SigningSecret   string        `mapstructure:"signing_secret"`    // DASH分片地址和分享令牌的签名密钥，为空时使用 jwt.secret
+	SignedURLExpiry time.Duration `mapstructure:"signed_url_expiry"` // DASH分片地址的有效期，清单不会重新获取，应覆盖一次观看的时长
+	ShareTokenTTL   time.Duration `mapstructure:"share_token_ttl"`   // 分享令牌的有效期，持有令牌可以不登录获取播放列表和HLS密钥
+}
+
+// ArtworkConfig 封面和缩略图配置，截图使用 transcode 配置的ffmpeg
+type ArtworkConfig struct {
+	Enabled           bool            `mapstructure:"enabled"`            // 上传完成和替换源文件后是否生成候选封面和缩略图
+	PosterOffsets     []time.Duration `mapstructure:"poster_offsets"`     // 候选封面的截取时间点，超出视频时长的忽略
+	PosterWidth       int             `mapstructure:"poster_width"`       // 候选封面的宽度
+	ThumbnailInterval time.Duration   `mapstructure:"thumbnail_interval"` // 拖动预览缩略图的间隔
+	ThumbnailWidth    int             `mapstructure:"thumbnail_width"`    // 缩略图的宽度
+	SpriteColumns     int             `mapstructure:"sprite_columns"`     // 雪碧图每行的缩略图数
+	SpriteRows        int             `mapstructure:"sprite_rows"`        // 雪碧图的行数
+	CoverMaxSize      int64           `mapstructure:"cover_max_size"`     // 自定义封面的文件大小上限（字节）
+	CoverMaxWidth     int             `mapstructure:"cover_max_width"`    // 自定义封面缩小到的最大宽度
+	CoverMaxHeight    int             `mapstructure:"cover_max_height"`   // 自定义封面缩小到的最大高度
+	Concurrency       int             `mapstructure:"concurrency"`        // 每个实例同时生成的视频数
+	PollInterval      time.Duration   `mapstructure:"poll_interval"`      // 扫描待生成记录的间隔
+	MaxAttempts       int             `mapstructure:"max_attempts"`       // 最多生成次数，之后标记为失败
+	RetryDelay        time.Duration   `mapstructure:"retry_delay"`        // 失败后的重试间隔，随次数线性增长
+	Timeout           time.Duration   `mapstructure:"timeout"`            // 单次生成的超时
+	WorkDir           string          `mapstructure:"work_dir"`           // 源文件和截图的临时目录，为空时使用系统临时目录
+}
+
+// FaststartConfig 上传完成后改写 MP4 源文件：把 moov 移到文件开头，使浏览器可以边下载边播放；
+// 同时清除拍摄位置、设备型号等元数据，上传时可以选择保留
+type FaststartConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 上传完成后是否改写源文件
+	KeepMetadata bool          `mapstructure:"keep_metadata"` // 是否对所有上传保留源文件元数据，默认清除
+	Concurrency  int           `mapstructure:"concurrency"`   // 每个实例同时改写的文件数
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 扫描待处理记录的间隔
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // 最多处理次数，之后标记为失败
+	RetryDelay   time.Duration `mapstructure:"retry_delay"`   // 失败后的重试间隔，随次数线性增长
+	Timeout      time.Duration `mapstructure:"timeout"`       // 单个文件下载、改写和上传的超时
+	WorkDir      string        `mapstructure:"work_dir"`      // 改写时的临时目录，需要两倍源文件大小的空间，为空时使用系统临时目录
+}
+
+// ScanConfig 上传文件的恶意软件扫描：作为处理流水线的第一步，扫描干净后才转码发布，
+// 发现恶意软件时源文件移到隔离前缀下并封禁视频
+type ScanConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 上传完成后是否扫描源文件
+	Driver       string        `mapstructure:"driver"`        // clamd 或 noop（不扫描，全部视为干净）
+	Network      string        `mapstructure:"network"`       // 连接 clamd 的网络类型：tcp 或 unix
+	Address      string        `mapstructure:"address"`       // clamd 地址，例如 127.0.0.1:3310 或 /var/run/clamav/clamd.ctl
+	ChunkSize    int           `mapstructure:"chunk_size"`    // INSTREAM 每块发送的字节数
+	MaxSize      int64         `mapstructure:"max_size"`      // 单个文件的扫描上限（字节），应与 clamd.conf 的 StreamMaxLength 一致，超过时直接失败；为0时由 clamd 判断
+	Concurrency  int           `mapstructure:"concurrency"`   // 每个实例同时扫描的文件数
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 扫描待处理记录的间隔
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // 最多扫描次数，之后标记为失败
+	RetryDelay   time.Duration `mapstructure:"retry_delay"`   // 失败后的重试间隔，随次数线性增长
+	Timeout      time.Duration `mapstructure:"timeout"`       // 单个文件的扫描超时
+}
+
+// LiveConfig 直播：编码器以 HTTP PUT 推送 HLS 分片和播放列表，观众拉取滑动窗口的直播播放列表，
+// 直播结束后分片拼接为普通视频进入处理流水线
+type LiveConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`            // 是否允许创建直播和推流
+	WindowSize       int           `mapstructure:"window_size"`        // 观众播放列表中的分片数
+	MaxSegmentSize   int64         `mapstructure:"max_segment_size"`   // 单个分片的最大字节数
+	MaxPlaylistSize  int64         `mapstructure:"max_playlist_size"`  // 编码器推送的播放列表的最大字节数
+	IdleTimeout      time.Duration `mapstructure:"idle_timeout"`       // 直播中超过该时间没有推流时自动结束
+	SegmentURLExpiry time.Duration `mapstructure:"segment_url_expiry"` // 观众播放列表中分片预签名地址的有效期
+	PollInterval     time.Duration `mapstructure:"poll_interval"`      // 检查空闲直播和待转换直播的间隔
+	MaxAttempts      int           `mapstructure:"max_attempts"`       // 转换为视频的最多尝试次数
+	RetryDelay       time.Duration `mapstructure:"retry_delay"`        // 转换失败后的重试间隔，随次数线性增长
+	Timeout          time.Duration `mapstructure:"timeout"`            // 单次转换的超时
+}
+
+// PipelineConfig 上传完成后的处理流水线：按声明的依赖依次调度 faststart、转码、封面等步骤，记录每一步的状态并重试失败的步骤
+type PipelineConfig struct {
+	PollInterval time.Duration        `mapstructure:"poll_interval"` // 推进流水线的间隔，步骤完成后最多经过该间隔开始后续步骤
+	BatchSize    int                  `mapstructure:"batch_size"`    // 每轮推进的视频数
+	MaxAttempts  int                  `mapstructure:"max_attempts"`  // 步骤未声明时的最多执行次数，每次执行内部还有各自的重试
+	RetryDelay   time.Duration        `mapstructure:"retry_delay"`   // 步骤失败后的重试间隔，随次数线性增长
+	Steps        []PipelineStepConfig `mapstructure:"steps"`         // 流水线定义，为空时使用默认的 faststart -> transcode、artwork
+}
+
+// PipelineStepConfig 流水线中的一个步骤
+type PipelineStepConfig struct {
+	Name        string   `mapstructure:"name"`         // faststart、transcode 或 artwork
+	DependsOn   []string `mapstructure:"depends_on"`   // 依赖的步骤，必须在该步骤之前声明
+	MaxAttempts int      `mapstructure:"max_attempts"` // 最多执行次数，为0时使用流水线的默认值
+	Optional    bool     `mapstructure:"optional"`     // 失败时不阻塞依赖它的步骤
+}
+
 // JWTConfig JWT配置
 type JWTConfig struct {
 	Secret            string        `mapstructure:"secret"`
@@ -96,10 +312,23 @@ func Load(configPath string) (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
+// Validate 检查相互依赖的配置项，不满足时拒绝启动
+func (c *Config) Validate() error {
+	// 静态加密只覆盖源文件和转码后的MP4，HLS和DASH分片以对象存储直连地址提供，只能依靠HLS加密保护画面
+	if c.Encryption.Enabled && c.Transcode.Enabled && !c.Transcode.HLSEncryption {
+		return errors.New("encryption.enabled requires transcode.hls_encryption, " +
+			"otherwise HLS and DASH segments are stored in plaintext")
+	}
+	return nil
+}
+
 // GetDSN 获取数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
@@ -111,6 +340,28 @@ func (c *RedisConfig) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// IsAdmin 检查用户是否为管理员，未配置管理员名单时没有人是管理员
+func (c *AdminConfig) IsAdmin(userUUID string) bool {
+	return userUUID != "" && slices.Contains(c.Users, userUUID)
+}
+
+// IsModerator 检查用户是否在审核员名单中，未配置审核员名单时没有人是审核员
+func (c *ModerationConfig) IsModerator(userUUID string) bool {
+	return userUUID != "" && slices.Contains(c.Moderators, userUUID)
+}
+
+// IsAdmin 检查用户是否为管理员，未加载配置时拒绝
+func IsAdmin(userUUID string) bool {
+	cfg := GetGlobalConfig()
+	return cfg != nil && cfg.Admin.IsAdmin(userUUID)
+}
+
+// IsModerator 检查用户是否有审核权限：审核员名单中的用户和管理员，未加载配置时拒绝
+func IsModerator(userUUID string) bool {
+	cfg := GetGlobalConfig()
+	return cfg != nil && (cfg.Moderation.IsModerator(userUUID) || cfg.Admin.IsAdmin(userUUID))
+}
+
 // GetMinioEndpoint 获取MinIO端点
 func (c *MinioConfig) GetMinioEndpoint() string {
 	return c.Endpoint
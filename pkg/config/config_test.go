@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		encryption    bool
+		transcode     bool
+		hlsEncryption bool
+		wantErr       bool
+	}{
+		{name: "no encryption"},
+		{name: "no encryption with transcoding", transcode: true},
+		{name: "encryption without transcoding", encryption: true},
+		{name: "encryption with encrypted hls", encryption: true, transcode: true, hlsEncryption: true},
+		{name: "encryption with clear hls", encryption: true, transcode: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Encryption: EncryptionConfig{Enabled: tt.encryption},
+				Transcode:  TranscodeConfig{Enabled: tt.transcode, HLSEncryption: tt.hlsEncryption},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package dash
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ContentType DASH 清单的内容类型
+const ContentType = "application/dash+xml"
+
+const (
+	// namespace MPD 的 XML 命名空间
+	namespace = "urn:mpeg:dash:schema:mpd:2011"
+	// profileLive 使用 SegmentTemplate 寻址的 ISO BMFF 分片
+	profileLive = "urn:mpeg:dash:profile:isoff-live:2011"
+	// audioChannelScheme 声道配置的描述方案
+	audioChannelScheme = "urn:mpeg:dash:23003:3:audio_channel_configuration:2011"
+	// timescale SegmentTemplate 的时间单位（毫秒）
+	timescale = 1000
+)
+
+// Representation 一个码率的一条轨道，初始化分片和媒体分片的地址由 SegmentTemplate 给出
+type Representation struct {
+	ID              string        // 在清单内唯一，不能包含空白字符
+	Bandwidth       int           // 峰值码率（bit/s）
+	Codecs          string        // RFC 6381 编码，为空时不输出
+	Width           int           // 视频宽度，音频为0
+	Height          int           // 视频高度，音频为0
+	AudioChannels   int           // 音频声道数，视频为0
+	Initialization  string        // 初始化分片地址
+	Media           string        // 媒体分片地址模板，包含 $Number$ 标识符
+	SegmentDuration time.Duration // 媒体分片的标称时长
+}
+
+// MPD 点播清单：一个 Period，视频和音频各一个 AdaptationSet
+type MPD struct {
+	Duration      time.Duration // 媒体时长
+	MinBufferTime time.Duration // 开始播放前需要缓冲的时长
+	Video         []Representation
+	Audio         []Representation
+}
+
+// mpdXML 以下结构对应 DASH-MPD.xsd 中用到的元素，属性按 schema 中的名称输出
+type mpdXML struct {
+	XMLName                   xml.Name    `xml:"MPD"`
+	Namespace                 string      `xml:"xmlns,attr"`
+	Profiles                  string      `xml:"profiles,attr"`
+	Type                      string      `xml:"type,attr"`
+	MediaPresentationDuration string      `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string      `xml:"minBufferTime,attr"`
+	Periods                   []periodXML `xml:"Period"`
+}
+
+type periodXML struct {
+	ID             string             `xml:"id,attr"`
+	Start          string             `xml:"start,attr"`
+	AdaptationSets []adaptationSetXML `xml:"AdaptationSet"`
+}
+
+type adaptationSetXML struct {
+	ID               int                 `xml:"id,attr"`
+	ContentType      string              `xml:"contentType,attr"`
+	MimeType         string              `xml:"mimeType,attr"`
+	SegmentAlignment bool                `xml:"segmentAlignment,attr"`
+	StartWithSAP     int                 `xml:"startWithSAP,attr"`
+	Representations  []representationXML `xml:"Representation"`
+}
+
+type representationXML struct {
+	ID                        string             `xml:"id,attr"`
+	Bandwidth                 int                `xml:"bandwidth,attr"`
+	Codecs                    string             `xml:"codecs,attr,omitempty"`
+	Width                     int                `xml:"width,attr,omitempty"`
+	Height                    int                `xml:"height,attr,omitempty"`
+	AudioChannelConfiguration *descriptorXML     `xml:"AudioChannelConfiguration,omitempty"`
+	SegmentTemplate           segmentTemplateXML `xml:"SegmentTemplate"`
+}
+
+type descriptorXML struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+type segmentTemplateXML struct {
+	Timescale      int    `xml:"timescale,attr"`
+	Duration       int64  `xml:"duration,attr"`
+	StartNumber    int    `xml:"startNumber,attr"`
+	Initialization string `xml:"initialization,attr"`
+	Media          string `xml:"media,attr"`
+}
+
+// Validate 按 MPD schema 的结构校验：静态清单必须有时长和最短缓冲时长，Period 至少有一个 AdaptationSet，
+// AdaptationSet 至少有一个 Representation，Representation 的 id 唯一且不含空白、bandwidth 为正，
+// SegmentTemplate 有初始化地址和包含 $Number$ 的媒体地址模板
+func (m *MPD) Validate() error {
+	if m.Duration <= 0 {
+		return errors.New("mpd: mediaPresentationDuration is required for a static presentation")
+	}
+	if m.MinBufferTime <= 0 {
+		return errors.New("mpd: minBufferTime is required")
+	}
+	if len(m.Video) == 0 {
+		return errors.New("mpd: video adaptation set has no representations")
+	}
+	ids := make(map[string]bool, len(m.Video)+len(m.Audio))
+	for _, representations := range [][]Representation{m.Video, m.Audio} {
+		for _, representation := range representations {
+			if err := representation.validate(); err != nil {
+				return err
+			}
+			if ids[representation.ID] {
+				return fmt.Errorf("mpd: duplicate representation id %q", representation.ID)
+			}
+			ids[representation.ID] = true
+		}
+	}
+	for _, representation := range m.Video {
+		if representation.Width <= 0 || representation.Height <= 0 {
+			return fmt.Errorf("mpd: video representation %q has no dimensions", representation.ID)
+		}
+	}
+	return nil
+}
+
+func (r *Representation) validate() error {
+	if r.ID == "" || strings.ContainsAny(r.ID, " \t\r\n") {
+		return fmt.Errorf("mpd: invalid representation id %q", r.ID)
+	}
+	if r.Bandwidth <= 0 {
+		return fmt.Errorf("mpd: representation %q has no bandwidth", r.ID)
+	}
+	if r.Initialization == "" || !strings.Contains(r.Media, "$Number") {
+		return fmt.Errorf("mpd: representation %q has no segment template", r.ID)
+	}
+	if r.SegmentDuration <= 0 {
+		return fmt.Errorf("mpd: representation %q has no segment duration", r.ID)
+	}
+	return nil
+}
+
+// Encode 校验后生成 mpd 文本，Representation 按给定顺序输出
+func (m *MPD) Encode() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	period := periodXML{ID: "0", Start: duration(0)}
+	period.AdaptationSets = append(period.AdaptationSets, adaptationSet(0, "video", m.Video))
+	if len(m.Audio) > 0 {
+		period.AdaptationSets = append(period.AdaptationSets, adaptationSet(1, "audio", m.Audio))
+	}
+	mpd := mpdXML{
+		Namespace:                 namespace,
+		Profiles:                  profileLive,
+		Type:                      "static",
+		MediaPresentationDuration: duration(m.Duration),
+		MinBufferTime:             duration(m.MinBufferTime),
+		Periods:                   []periodXML{period},
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(mpd); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func adaptationSet(id int, contentType string, representations []Representation) adaptationSetXML {
+	set := adaptationSetXML{
+		ID:               id,
+		ContentType:      contentType,
+		MimeType:         contentType + "/mp4",
+		SegmentAlignment: true,
+		StartWithSAP:     1,
+	}
+	for _, representation := range representations {
+		element := representationXML{
+			ID:        representation.ID,
+			Bandwidth: representation.Bandwidth,
+			Codecs:    representation.Codecs,
+			Width:     representation.Width,
+			Height:    representation.Height,
+			SegmentTemplate: segmentTemplateXML{
+				Timescale:      timescale,
+				Duration:       representation.SegmentDuration.Milliseconds(),
+				StartNumber:    1,
+				Initialization: representation.Initialization,
+				Media:          representation.Media,
+			},
+		}
+		if representation.AudioChannels > 0 {
+			element.AudioChannelConfiguration = &descriptorXML{
+				SchemeIDURI: audioChannelScheme,
+				Value:       fmt.Sprint(representation.AudioChannels),
+			}
+		}
+		set.Representations = append(set.Representations, element)
+	}
+	return set
+}
+
+// duration xs:duration 格式的时长，精确到毫秒
+func duration(d time.Duration) string {
+	return fmt.Sprintf("PT%.3fS", d.Seconds())
+}
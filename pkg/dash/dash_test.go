@@ -0,0 +1,167 @@
+package dash
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMPD() *MPD {
+	return &MPD{
+		Duration:      125500 * time.Millisecond,
+		MinBufferTime: 2 * time.Second,
+		Video: []Representation{
+			{ID: "video_720p", Bandwidth: 3_000_000, Codecs: "avc1.64001f", Width: 1280, Height: 720,
+				Initialization: "720p/init.mp4", Media: "720p/seg_$Number%05d$.m4s", SegmentDuration: 4 * time.Second},
+			{ID: "video_360p", Bandwidth: 800_000, Codecs: "avc1.4d401e", Width: 640, Height: 360,
+				Initialization: "360p/init.mp4", Media: "360p/seg_$Number$.m4s", SegmentDuration: 4 * time.Second},
+		},
+		Audio: []Representation{
+			{ID: "audio", Bandwidth: 128_000, Codecs: "mp4a.40.2", AudioChannels: 2,
+				Initialization: "audio/init.mp4", Media: "audio/seg_$Number$.m4s", SegmentDuration: 4 * time.Second},
+		},
+	}
+}
+
+func TestMPDEncodeStructure(t *testing.T) {
+	content, err := testMPD().Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte(xml.Header)) {
+		t.Fatalf("missing XML header:\n%s", content)
+	}
+	var mpd mpdXML
+	if err := xml.Unmarshal(content, &mpd); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if mpd.XMLName.Space != namespace || mpd.XMLName.Local != "MPD" {
+		t.Fatalf("root element = %+v", mpd.XMLName)
+	}
+	if mpd.Profiles != profileLive || mpd.Type != "static" {
+		t.Fatalf("profiles %q type %q", mpd.Profiles, mpd.Type)
+	}
+	if mpd.MediaPresentationDuration != "PT125.500S" || mpd.MinBufferTime != "PT2.000S" {
+		t.Fatalf("durations %q %q", mpd.MediaPresentationDuration, mpd.MinBufferTime)
+	}
+	if len(mpd.Periods) != 1 || mpd.Periods[0].Start != "PT0.000S" || len(mpd.Periods[0].AdaptationSets) != 2 {
+		t.Fatalf("periods = %+v", mpd.Periods)
+	}
+
+	video, audio := mpd.Periods[0].AdaptationSets[0], mpd.Periods[0].AdaptationSets[1]
+	sets := []struct {
+		set         adaptationSetXML
+		id          int
+		contentType string
+		want        []Representation
+	}{
+		{set: video, id: 0, contentType: "video", want: testMPD().Video},
+		{set: audio, id: 1, contentType: "audio", want: testMPD().Audio},
+	}
+	for _, tt := range sets {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if tt.set.ID != tt.id || tt.set.ContentType != tt.contentType || tt.set.MimeType != tt.contentType+"/mp4" {
+				t.Fatalf("adaptation set = %+v", tt.set)
+			}
+			if !tt.set.SegmentAlignment || tt.set.StartWithSAP != 1 {
+				t.Fatalf("segmentAlignment %v startWithSAP %d", tt.set.SegmentAlignment, tt.set.StartWithSAP)
+			}
+			if len(tt.set.Representations) != len(tt.want) {
+				t.Fatalf("%d representations, want %d", len(tt.set.Representations), len(tt.want))
+			}
+			for i, got := range tt.set.Representations {
+				want := tt.want[i]
+				if got.ID != want.ID || got.Bandwidth != want.Bandwidth || got.Codecs != want.Codecs ||
+					got.Width != want.Width || got.Height != want.Height {
+					t.Fatalf("representation %d = %+v, want %+v", i, got, want)
+				}
+				template := got.SegmentTemplate
+				if template.Timescale != timescale || template.Duration != want.SegmentDuration.Milliseconds() ||
+					template.StartNumber != 1 || template.Initialization != want.Initialization || template.Media != want.Media {
+					t.Fatalf("representation %q segment template = %+v", got.ID, template)
+				}
+				switch {
+				case want.AudioChannels == 0 && got.AudioChannelConfiguration != nil:
+					t.Fatalf("video representation %q has audio channels", got.ID)
+				case want.AudioChannels > 0 && (got.AudioChannelConfiguration == nil ||
+					got.AudioChannelConfiguration.SchemeIDURI != audioChannelScheme ||
+					got.AudioChannelConfiguration.Value != "2"):
+					t.Fatalf("audio representation %q channels = %+v", got.ID, got.AudioChannelConfiguration)
+				}
+			}
+		})
+	}
+
+	// 为空的属性不输出，例如音频的宽高
+	if strings.Contains(string(content), `width="0"`) || strings.Contains(string(content), `codecs=""`) {
+		t.Fatalf("empty attributes should be omitted:\n%s", content)
+	}
+}
+
+func TestMPDEncodeVideoOnly(t *testing.T) {
+	mpd := testMPD()
+	mpd.Audio = nil
+	content, err := mpd.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var parsed mpdXML
+	if err := xml.Unmarshal(content, &parsed); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if sets := parsed.Periods[0].AdaptationSets; len(sets) != 1 || sets[0].ContentType != "video" {
+		t.Fatalf("adaptation sets = %+v", sets)
+	}
+}
+
+func TestMPDValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *MPD)
+	}{
+		{name: "no duration", modify: func(m *MPD) { m.Duration = 0 }},
+		{name: "no min buffer time", modify: func(m *MPD) { m.MinBufferTime = 0 }},
+		{name: "no video", modify: func(m *MPD) { m.Video = nil }},
+		{name: "empty id", modify: func(m *MPD) { m.Video[0].ID = "" }},
+		{name: "id with whitespace", modify: func(m *MPD) { m.Video[0].ID = "video 720p" }},
+		{name: "duplicate id across sets", modify: func(m *MPD) { m.Audio[0].ID = m.Video[1].ID }},
+		{name: "no bandwidth", modify: func(m *MPD) { m.Audio[0].Bandwidth = 0 }},
+		{name: "no initialization", modify: func(m *MPD) { m.Video[0].Initialization = "" }},
+		{name: "media without number", modify: func(m *MPD) { m.Video[0].Media = "720p/seg.m4s" }},
+		{name: "no segment duration", modify: func(m *MPD) { m.Audio[0].SegmentDuration = 0 }},
+		{name: "video without dimensions", modify: func(m *MPD) { m.Video[1].Height = 0 }},
+	}
+	if err := testMPD().Validate(); err != nil {
+		t.Fatalf("Validate valid MPD: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mpd := testMPD()
+			tt.modify(mpd)
+			if err := mpd.Validate(); err == nil {
+				t.Fatal("Validate should fail")
+			}
+			if _, err := mpd.Encode(); err == nil {
+				t.Fatal("Encode should fail")
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "PT0.000S"},
+		{d: 1500 * time.Millisecond, want: "PT1.500S"},
+		{d: time.Hour + 1234*time.Millisecond, want: "PT3601.234S"},
+	}
+	for _, tt := range tests {
+		if got := duration(tt.d); got != tt.want {
+			t.Fatalf("duration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package envelope
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultChunkSize 默认明文分块大小
+	DefaultChunkSize = 64 * 1024
+	// Overhead 每个分块的认证标签长度
+	Overhead = 16
+)
+
+// 分块格式：明文按 chunkSize 切分，每块独立使用 AES-256-GCM 加密，密文块依次拼接，不含文件头。
+// 第 i 块的 nonce 为块序号，附加数据为 块序号 || 是否最后一块，
+// 因此块不能被重排、截断或拼接。每个对象使用独立的数据密钥，nonce 不会重复。
+// 空对象也会产生一个只有认证标签的最后一块。
+
+// EncryptedSize 计算明文加密后的长度
+func EncryptedSize(plainSize int64, chunkSize int) int64 {
+	return plainSize + ChunkCount(plainSize, chunkSize)*Overhead
+}
+
+// PlainSize 根据密文长度计算明文长度
+func PlainSize(encryptedSize int64, chunkSize int) (int64, error) {
+	encChunk := int64(chunkSize + Overhead)
+	chunks := (encryptedSize + encChunk - 1) / encChunk
+	if chunks == 0 || encryptedSize-(chunks-1)*encChunk < Overhead {
+		return 0, fmt.Errorf("envelope: invalid encrypted size %d", encryptedSize)
+	}
+	return encryptedSize - chunks*Overhead, nil
+}
+
+// CipherRange 明文区间对应的密文区间
+type CipherRange struct {
+	Offset     int64 // 密文起始偏移，总是落在块边界上
+	Length     int64 // 密文长度
+	FirstChunk int64 // 第一个密文块的序号
+	Skip       int64 // 解密后需要丢弃的明文字节数
+}
+
+// RangeFor 计算读取明文 [offset, offset+length) 需要获取的密文区间，length必须大于0
+func RangeFor(offset, length, plainSize int64, chunkSize int) (CipherRange, error) {
+	if offset < 0 || length <= 0 || offset+length > plainSize {
+		return CipherRange{}, fmt.Errorf("envelope: range %d+%d out of bounds %d", offset, length, plainSize)
+	}
+	cs := int64(chunkSize)
+	encChunk := cs + Overhead
+	first := offset / cs
+	last := (offset + length - 1) / cs
+	start := first * encChunk
+	end := (last + 1) * encChunk
+	if total := EncryptedSize(plainSize, chunkSize); end > total {
+		end = total
+	}
+	return CipherRange{
+		Offset:     start,
+		Length:     end - start,
+		FirstChunk: first,
+		Skip:       offset - first*cs,
+	}, nil
+}
+
+// NewEncryptReader 返回读取时输出 src 加密结果的 Reader
+func NewEncryptReader(src io.Reader, dataKey []byte, chunkSize int) (io.Reader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		src:    bufio.NewReader(src),
+		aead:   aead,
+		plain:  make([]byte, chunkSize),
+		sealed: make([]byte, 0, chunkSize+Overhead),
+	}, nil
+}
+
+type encryptReader struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	plain  []byte
+	sealed []byte
+	out    []byte
+	index  uint64
+	done   bool
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNext(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealNext() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		// 刚好读满一块时需要预读一个字节才能判断是否为最后一块
+		if _, peekErr := r.src.Peek(1); errors.Is(peekErr, io.EOF) {
+			final = true
+		} else if peekErr != nil {
+			return peekErr
+		}
+	}
+	r.out = r.aead.Seal(r.sealed[:0], chunkNonce(r.index), r.plain[:n], chunkAAD(r.index, final))
+	r.index++
+	r.done = final
+	return nil
+}
+
+// NewDecryptReader 返回解密分块密文的 Reader。src 必须从 firstChunk 块的边界开始，
+// totalChunks 为整个对象的块数，用于识别最后一块。读完最后一块后返回 io.EOF，
+// src 在最后一块之前结束（即使落在块边界上）视为密文被截断，返回 ErrAuthentication
+func NewDecryptReader(src io.Reader, dataKey []byte, chunkSize int, firstChunk, totalChunks int64) (io.Reader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:    src,
+		aead:   aead,
+		sealed: make([]byte, chunkSize+Overhead),
+		plain:  make([]byte, 0, chunkSize),
+		index:  uint64(firstChunk),
+		last:   uint64(totalChunks - 1),
+	}, nil
+}
+
+type decryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	sealed []byte
+	plain  []byte
+	out    []byte
+	index  uint64
+	last   uint64
+	err    error
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.openNext()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openNext() error {
+	if r.index > r.last {
+		return io.EOF
+	}
+	n, err := io.ReadFull(r.src, r.sealed)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: chunk %d missing: %w", ErrAuthentication, r.index, io.ErrUnexpectedEOF)
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	plain, openErr := r.aead.Open(r.plain[:0], chunkNonce(r.index), r.sealed[:n], chunkAAD(r.index, r.index == r.last))
+	if openErr != nil {
+		return fmt.Errorf("%w: chunk %d", ErrAuthentication, r.index)
+	}
+	r.out = plain
+	r.index++
+	return nil
+}
+
+// NewRangeReader 解密 RangeFor 返回的密文区间，只输出请求的明文 [offset, offset+length)
+func NewRangeReader(src io.Reader, dataKey []byte, chunkSize int, plainSize int64, cr CipherRange, length int64) (io.Reader, error) {
+	reader, err := NewDecryptReader(src, dataKey, chunkSize, cr.FirstChunk, ChunkCount(plainSize, chunkSize))
+	if err != nil {
+		return nil, err
+	}
+	if cr.Skip > 0 {
+		if _, err := io.CopyN(io.Discard, reader, cr.Skip); err != nil {
+			return nil, err
+		}
+	}
+	return io.LimitReader(reader, length), nil
+}
+
+// ChunkCount 计算明文对应的块数
+func ChunkCount(plainSize int64, chunkSize int) int64 {
+	if plainSize <= 0 {
+		return 1
+	}
+	cs := int64(chunkSize)
+	return (plainSize + cs - 1) / cs
+}
+
+func chunkNonce(index uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], index)
+	return nonce
+}
+
+func chunkAAD(index uint64, final bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, index)
+	if final {
+		aad[8] = 1
+	}
+	return aad
+}
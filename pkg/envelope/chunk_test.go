@@ -0,0 +1,177 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+const testChunkSize = 16
+
+func testDataKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func encrypt(t *testing.T, plain, key []byte) []byte {
+	t.Helper()
+	reader, err := NewEncryptReader(bytes.NewReader(plain), key, testChunkSize)
+	if err != nil {
+		t.Fatalf("NewEncryptReader: %v", err)
+	}
+	sealed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if int64(len(sealed)) != EncryptedSize(int64(len(plain)), testChunkSize) {
+		t.Fatalf("encrypted %d bytes, EncryptedSize = %d", len(sealed), EncryptedSize(int64(len(plain)), testChunkSize))
+	}
+	return sealed
+}
+
+func decrypt(sealed, key []byte, plainSize int64) ([]byte, error) {
+	reader, err := NewDecryptReader(bytes.NewReader(sealed), key, testChunkSize, 0, ChunkCount(plainSize, testChunkSize))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func TestDecryptReaderRoundTrip(t *testing.T) {
+	key := testDataKey(t)
+	for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3 * testChunkSize, 3*testChunkSize + 5} {
+		plain := bytes.Repeat([]byte{'a'}, size)
+		got, err := decrypt(encrypt(t, plain, key), key, int64(size))
+		if err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: decrypted %q", size, got)
+		}
+	}
+}
+
+func TestDecryptReaderRejectsModifiedCiphertext(t *testing.T) {
+	key := testDataKey(t)
+	plain := bytes.Repeat([]byte("0123456789"), 5)
+	sealed := encrypt(t, plain, key)
+	encChunk := testChunkSize + Overhead
+	tests := []struct {
+		name   string
+		sealed func() []byte
+	}{
+		{
+			name:   "truncated on chunk boundary",
+			sealed: func() []byte { return sealed[:2*encChunk] },
+		},
+		{
+			name:   "truncated to first chunk",
+			sealed: func() []byte { return sealed[:encChunk] },
+		},
+		{
+			name:   "truncated inside chunk",
+			sealed: func() []byte { return sealed[:2*encChunk+5] },
+		},
+		{
+			name:   "empty",
+			sealed: func() []byte { return nil },
+		},
+		{
+			name: "flipped byte",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[encChunk+3] ^= 1
+				return modified
+			},
+		},
+		{
+			name: "chunks swapped",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				copy(modified[:encChunk], sealed[encChunk:2*encChunk])
+				copy(modified[encChunk:2*encChunk], sealed[:encChunk])
+				return modified
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decrypt(tt.sealed(), key, int64(len(plain))); !errors.Is(err, ErrAuthentication) {
+				t.Fatalf("decrypt error = %v, want ErrAuthentication", err)
+			}
+		})
+	}
+
+	t.Run("truncated reports unexpected EOF", func(t *testing.T) {
+		if _, err := decrypt(sealed[:2*encChunk], key, int64(len(plain))); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("decrypt error = %v, want io.ErrUnexpectedEOF", err)
+		}
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		if _, err := decrypt(sealed, testDataKey(t), int64(len(plain))); !errors.Is(err, ErrAuthentication) {
+			t.Fatalf("decrypt error = %v, want ErrAuthentication", err)
+		}
+	})
+}
+
+func TestRangeReader(t *testing.T) {
+	key := testDataKey(t)
+	plain := make([]byte, 5*testChunkSize+7)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	sealed := encrypt(t, plain, key)
+	tests := []struct {
+		name           string
+		offset, length int64
+	}{
+		{name: "whole object", offset: 0, length: int64(len(plain))},
+		{name: "first byte", offset: 0, length: 1},
+		{name: "inside one chunk", offset: 3, length: 5},
+		{name: "across chunks", offset: testChunkSize - 2, length: testChunkSize + 4},
+		{name: "chunk aligned", offset: 2 * testChunkSize, length: testChunkSize},
+		{name: "tail", offset: int64(len(plain)) - 3, length: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := RangeFor(tt.offset, tt.length, int64(len(plain)), testChunkSize)
+			if err != nil {
+				t.Fatalf("RangeFor: %v", err)
+			}
+			src := bytes.NewReader(sealed[cr.Offset : cr.Offset+cr.Length])
+			reader, err := NewRangeReader(src, key, testChunkSize, int64(len(plain)), cr, tt.length)
+			if err != nil {
+				t.Fatalf("NewRangeReader: %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("read range: %v", err)
+			}
+			if want := plain[tt.offset : tt.offset+tt.length]; !bytes.Equal(got, want) {
+				t.Fatalf("range = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if _, err := RangeFor(int64(len(plain))-1, 2, int64(len(plain)), testChunkSize); err == nil {
+		t.Fatal("RangeFor out of bounds should fail")
+	}
+}
+
+func TestPlainSize(t *testing.T) {
+	for _, size := range []int64{0, 1, testChunkSize, testChunkSize + 1, 10 * testChunkSize} {
+		got, err := PlainSize(EncryptedSize(size, testChunkSize), testChunkSize)
+		if err != nil || got != size {
+			t.Fatalf("PlainSize(EncryptedSize(%d)) = %d, %v", size, got, err)
+		}
+	}
+	if _, err := PlainSize(Overhead-1, testChunkSize); err == nil {
+		t.Fatal("PlainSize of a partial tag should fail")
+	}
+}
@@ -0,0 +1,117 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize 主密钥和数据密钥的长度（AES-256）
+const KeySize = 32
+
+var (
+	// ErrUnknownKey 找不到对应ID的主密钥
+	ErrUnknownKey = errors.New("envelope: unknown master key")
+	// ErrAuthentication 密文或包装后的密钥校验失败（被篡改或使用了错误的密钥）
+	ErrAuthentication = errors.New("envelope: message authentication failed")
+)
+
+// Keyring 主密钥环：用当前主密钥包装新的数据密钥，用密钥ID找到旧主密钥解包，支持主密钥轮换
+type Keyring struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewKeyring 根据配置创建密钥环，masterKeys 为 密钥ID -> base64编码的32字节主密钥
+func NewKeyring(activeKeyID string, masterKeys map[string]string) (*Keyring, error) {
+	if activeKeyID == "" {
+		return nil, errors.New("envelope: active key id is required")
+	}
+	keys := make(map[string][]byte, len(masterKeys))
+	for keyID, encoded := range masterKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: master key %s: %w", keyID, err)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("envelope: master key %s must be %d bytes, got %d", keyID, KeySize, len(key))
+		}
+		keys[keyID] = key
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("envelope: active key %s is not configured", activeKeyID)
+	}
+	return &Keyring{
+		activeKeyID: activeKeyID,
+		keys:        keys,
+	}, nil
+}
+
+// ActiveKeyID 当前用于包装新数据密钥的主密钥ID
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// GenerateDataKey 生成随机数据密钥，并用当前主密钥包装
+func (k *Keyring) GenerateDataKey() (dataKey []byte, keyID string, wrapped []byte, err error) {
+	dataKey = make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", nil, err
+	}
+	keyID, wrapped, err = k.Wrap(dataKey)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return dataKey, keyID, wrapped, nil
+}
+
+// Wrap 用当前主密钥包装数据密钥，结果为 nonce || AES-GCM密文
+func (k *Keyring) Wrap(dataKey []byte) (keyID string, wrapped []byte, err error) {
+	aead, err := newGCM(k.keys[k.activeKeyID])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return k.activeKeyID, aead.Seal(nonce, nonce, dataKey, wrapAAD(k.activeKeyID)), nil
+}
+
+// Unwrap 用指定ID的主密钥解包数据密钥
+func (k *Keyring) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	masterKey, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrAuthentication
+	}
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, ciphertext, wrapAAD(keyID))
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return dataKey, nil
+}
+
+// wrapAAD 把密钥ID绑定到包装结果上，防止替换元数据中的密钥ID
+func wrapAAD(keyID string) []byte {
+	return []byte("go-video/envelope/" + keyID)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -20,6 +20,7 @@ var (
 
 	ErrParameterInvalid = &Errno{Code: 400, Message: "Invalid parameter %s"}
 	ErrUnauthorized     = &Errno{Code: 401, Message: "Unauthorized"}
+	ErrForbidden        = &Errno{Code: 403, Message: "Forbidden"}
 	ErrNotFound         = &Errno{Code: 404, Message: "Not found"}
 
 	ErrInternalServer = &Errno{Code: 500, Message: "Internal server error"}
@@ -31,4 +32,78 @@ var (
 	ErrParamTooLong       = &Errno{Code: 20002, Message: "Parameter too long"}
 	ErrVideoTooLarge      = &Errno{Code: 20003, Message: "Video file too large"}
 	ErrVideoFormatInvalid = &Errno{Code: 20004, Message: "Invalid video format"}
+	ErrVideoNotFound      = &Errno{Code: 20005, Message: "Video not found"}
+	ErrCaptionInvalid     = &Errno{Code: 20006, Message: "Invalid caption file: %s"}
+	ErrCaptionNotFound    = &Errno{Code: 20007, Message: "Caption track not found"}
+	ErrChapterInvalid     = &Errno{Code: 20008, Message: "Invalid chapters: %s"}
+	ErrReviewClaimed      = &Errno{Code: 20009, Message: "Video is being reviewed by another moderator"}
+	ErrReviewNotClaimed   = &Errno{Code: 20010, Message: "Video is not pending review or not claimed by you"}
+	ErrReportDuplicate    = &Errno{Code: 20011, Message: "You already have an open report for this target"}
+	ErrReportRateLimited  = &Errno{Code: 20012, Message: "Too many reports, please try again later"}
+	ErrReportTarget       = &Errno{Code: 20013, Message: "Report target not found"}
+	ErrReportNotFound     = &Errno{Code: 20014, Message: "No open reports for this target"}
+	ErrReportAction       = &Errno{Code: 20015, Message: "Action %s is not applicable to this target"}
+	ErrIllegalTransition  = &Errno{Code: 20016, Message: "Illegal status transition: %s"}
+	ErrReconcileRunning   = &Errno{Code: 20017, Message: "Reconciliation is already running"}
+	ErrEncryptionDisabled = &Errno{Code: 20018, Message: "Encryption master keys are not configured"}
+	ErrRangeInvalid       = &Errno{Code: 20019, Message: "Requested range not satisfiable"}
+	ErrRewrapRunning      = &Errno{Code: 20020, Message: "Key rewrap is already running"}
+	ErrChecksumMismatch   = &Errno{Code: 20021, Message: "Checksum mismatch: %s"}
+	ErrChecksumMissing    = &Errno{Code: 20022, Message: "No checksum recorded for this video"}
+
+	// 源文件版本
+	ErrSourceNotReplaceable  = &Errno{Code: 20023, Message: "Source can only be replaced or rolled back when the video is ready"}
+	ErrSourceVersionNotFound = &Errno{Code: 20024, Message: "Source version not found"}
+	ErrSourceVersionExpired  = &Errno{Code: 20025, Message: "Source version %d can no longer be restored"}
+
+	// 批量上传
+	ErrBatchTooLarge    = &Errno{Code: 20026, Message: "At most %d files can be uploaded in one batch"}
+	ErrBatchNotFound    = &Errno{Code: 20027, Message: "Upload batch not found"}
+	ErrBatchItemInvalid = &Errno{Code: 20028, Message: "Invalid batch item: %s"}
+
+	// 远程导入
+	ErrImportURLNotAllowed = &Errno{Code: 20029, Message: "URL is not allowed for import: %s"}
+	ErrUploadTaskNotFound  = &Errno{Code: 20030, Message: "Upload task not found"}
+
+	// 视频库迁移
+	ErrImportOwnerNotFound = &Errno{Code: 20031, Message: "Owner %s not found"}
+
+	// 个人数据导出
+	ErrExportRateLimited = &Errno{Code: 20032, Message: "An export was requested recently, please try again later"}
+	ErrExportNotFound    = &Errno{Code: 20033, Message: "Export not found"}
+
+	// 事件通知
+	ErrWebhookURLNotAllowed       = &Errno{Code: 20034, Message: "Webhook URL is not allowed: %s"}
+	ErrWebhookEventType           = &Errno{Code: 20035, Message: "Unknown event type: %s"}
+	ErrWebhookSubscriptionMissing = &Errno{Code: 20036, Message: "Webhook subscription not found"}
+	ErrWebhookDeliveryNotFound    = &Errno{Code: 20037, Message: "Webhook delivery not found"}
+
+	// 视频转码
+	ErrTranscodeDisabled   = &Errno{Code: 20038, Message: "Transcoding is disabled"}
+	ErrTranscodeNotActive  = &Errno{Code: 20039, Message: "Video has no transcoding in progress"}
+	ErrTranscodeNotAllowed = &Errno{Code: 20040, Message: "Video can only be transcoded again when it is ready or failed"}
+	ErrRenditionNotFound   = &Errno{Code: 20041, Message: "Rendition %s not found"}
+	ErrPlaylistNotFound    = &Errno{Code: 20042, Message: "Playlist not found"}
+	ErrManifestNotFound    = &Errno{Code: 20043, Message: "Manifest not found"}
+	ErrSegmentURLInvalid   = &Errno{Code: 20044, Message: "Segment URL is invalid or expired"}
+	ErrContentKeyNotFound  = &Errno{Code: 20045, Message: "Content key not found"}
+	ErrShareTokenInvalid   = &Errno{Code: 20046, Message: "Share token is invalid or expired"}
+
+	// 封面和缩略图
+	ErrArtworkNotFound        = &Errno{Code: 20047, Message: "Artwork not found"}
+	ErrPosterCandidateInvalid = &Errno{Code: 20048, Message: "Poster candidate %d does not exist"}
+	ErrCoverInvalid           = &Errno{Code: 20049, Message: "Invalid cover image: %s"}
+	ErrPosterChanged          = &Errno{Code: 20050, Message: "Poster was changed by another request, please retry"}
+	ErrPipelineNotFound       = &Errno{Code: 20051, Message: "Processing pipeline not found"}
+	ErrPipelineStepNotFound   = &Errno{Code: 20052, Message: "Pipeline step %s does not exist"}
+	ErrPipelineStepNotFailed  = &Errno{Code: 20053, Message: "Pipeline step %s has not failed"}
+
+	// 直播
+	ErrLiveDisabled        = &Errno{Code: 20054, Message: "Live streaming is disabled"}
+	ErrLiveStreamNotFound  = &Errno{Code: 20055, Message: "Live stream not found"}
+	ErrStreamKeyInvalid    = &Errno{Code: 20056, Message: "Stream key is invalid"}
+	ErrLiveStreamEnded     = &Errno{Code: 20057, Message: "Live stream has ended"}
+	ErrLiveStreamNotLive   = &Errno{Code: 20058, Message: "Live stream is not live"}
+	ErrLiveIngestInvalid   = &Errno{Code: 20059, Message: "Invalid live ingest: %s"}
+	ErrLiveSegmentNotFound = &Errno{Code: 20060, Message: "Live segment not found"}
 )
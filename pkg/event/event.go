@@ -0,0 +1,171 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type 领域事件类型
+type Type string
+
+const (
+	// TypeVideoUploaded 视频源文件上传完成（uploading -> uploaded）
+	TypeVideoUploaded Type = "video.uploaded"
+	// TypeVideoUploadFailed 视频源文件上传失败（uploading -> failed）
+	TypeVideoUploadFailed Type = "video.upload_failed"
+	// TypeVideoDeleted 视频被所有者删除
+	TypeVideoDeleted Type = "video.deleted"
+	// TypeUserRegistered 新用户注册
+	TypeUserRegistered Type = "user.registered"
+	// TypeUserDisabled 用户被禁用
+	TypeUserDisabled Type = "user.disabled"
+)
+
+// Payload 领域事件数据，每种事件类型对应一个结构体
+type Payload interface {
+	// EventType 事件类型
+	EventType() Type
+	// AggregateID 产生事件的聚合根UUID
+	AggregateID() string
+}
+
+// VideoUploaded 视频源文件上传完成
+type VideoUploaded struct {
+	VideoUUID string `json:"video_uuid"`
+	UserUUID  string `json:"user_uuid"`
+	Title     string `json:"title"`
+	FileSize  int64  `json:"file_size"`
+	Format    string `json:"format"`
+	SHA256    string `json:"sha256"`
+}
+
+func (e *VideoUploaded) EventType() Type {
+	return TypeVideoUploaded
+}
+
+func (e *VideoUploaded) AggregateID() string {
+	return e.VideoUUID
+}
+
+// VideoUploadFailed 视频源文件上传失败
+type VideoUploadFailed struct {
+	VideoUUID string `json:"video_uuid"`
+	UserUUID  string `json:"user_uuid"`
+	Title     string `json:"title"`
+	Reason    string `json:"reason"`
+}
+
+func (e *VideoUploadFailed) EventType() Type {
+	return TypeVideoUploadFailed
+}
+
+func (e *VideoUploadFailed) AggregateID() string {
+	return e.VideoUUID
+}
+
+// VideoDeleted 视频被删除
+type VideoDeleted struct {
+	VideoUUID string `json:"video_uuid"`
+	UserUUID  string `json:"user_uuid"`
+	Title     string `json:"title"`
+}
+
+func (e *VideoDeleted) EventType() Type {
+	return TypeVideoDeleted
+}
+
+func (e *VideoDeleted) AggregateID() string {
+	return e.VideoUUID
+}
+
+// UserRegistered 新用户注册，不包含邮箱等联系方式
+type UserRegistered struct {
+	UserUUID string `json:"user_uuid"`
+	Username string `json:"username"`
+}
+
+func (e *UserRegistered) EventType() Type {
+	return TypeUserRegistered
+}
+
+func (e *UserRegistered) AggregateID() string {
+	return e.UserUUID
+}
+
+// UserDisabled 用户被禁用
+type UserDisabled struct {
+	UserUUID string `json:"user_uuid"`
+}
+
+func (e *UserDisabled) EventType() Type {
+	return TypeUserDisabled
+}
+
+func (e *UserDisabled) AggregateID() string {
+	return e.UserUUID
+}
+
+// Event 领域事件信封，ID 在事件产生时生成，重复投递时不变，处理器据此去重
+type Event struct {
+	ID          string
+	Type        Type
+	AggregateID string
+	OccurredAt  time.Time
+	Payload     json.RawMessage
+}
+
+// New 为事件数据创建信封
+func New(payload Payload) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", payload.EventType(), err)
+	}
+	return &Event{
+		ID:          uuid.New().String(),
+		Type:        payload.EventType(),
+		AggregateID: payload.AggregateID(),
+		OccurredAt:  time.Now(),
+		Payload:     data,
+	}, nil
+}
+
+// NewAll 为多个事件数据创建信封
+func NewAll(payloads []Payload) ([]*Event, error) {
+	events := make([]*Event, 0, len(payloads))
+	for _, payload := range payloads {
+		e, err := New(payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Decode 把事件数据解析到对应类型的结构体，类型不一致时返回错误
+func (e *Event) Decode(payload Payload) error {
+	if payload.EventType() != e.Type {
+		return fmt.Errorf("event %s is %s, not %s", e.ID, e.Type, payload.EventType())
+	}
+	return json.Unmarshal(e.Payload, payload)
+}
+
+// Recorder 聚合根记录尚未持久化的领域事件，由仓储在保存状态的同一事务中取出写入发件箱
+type Recorder struct {
+	pending []Payload
+}
+
+// Record 记录一个领域事件
+func (r *Recorder) Record(payload Payload) {
+	r.pending = append(r.pending, payload)
+}
+
+// PullEvents 取出并清空已记录的领域事件
+func (r *Recorder) PullEvents() []Payload {
+	pending := r.pending
+	r.pending = nil
+	return pending
+}
@@ -0,0 +1,31 @@
+package event
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Appender 事件发件箱的写入接口，由事件总线模块实现并在初始化时注册，分发由事件总线的任务完成
+type Appender interface {
+	// Append 在调用方的事务中写入事件，事务提交后异步分发；同一事件重复写入时忽略
+	Append(tx *gorm.DB, events ...*Event) error
+}
+
+var appender Appender
+
+// RegisterAppender 注册发件箱的实现
+func RegisterAppender(a Appender) {
+	appender = a
+}
+
+// Append 在调用方的事务中把事件写入发件箱，事务提交后由分发任务异步分发
+func Append(tx *gorm.DB, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if appender == nil {
+		return errors.New("event: outbox appender not registered")
+	}
+	return appender.Append(tx, events...)
+}
@@ -0,0 +1,49 @@
+package event
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// recordingAppender 记录写入的事件
+type recordingAppender struct {
+	events []*Event
+}
+
+func (a *recordingAppender) Append(tx *gorm.DB, events ...*Event) error {
+	a.events = append(a.events, events...)
+	return nil
+}
+
+func TestAppend(t *testing.T) {
+	e, err := New(&UserDisabled{UserUUID: "user-1"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	recorder := &recordingAppender{}
+	tests := []struct {
+		name       string
+		appender   Appender
+		events     []*Event
+		wantErr    bool
+		wantEvents int
+	}{
+		{name: "no events", appender: nil},
+		{name: "not registered", appender: nil, events: []*Event{e}, wantErr: true},
+		{name: "registered", appender: recorder, events: []*Event{e}, wantEvents: 1},
+	}
+	defer RegisterAppender(appender)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.events = nil
+			RegisterAppender(tt.appender)
+			if err := Append(nil, tt.events...); (err != nil) != tt.wantErr {
+				t.Fatalf("Append error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(recorder.events) != tt.wantEvents {
+				t.Fatalf("appended %d events, want %d", len(recorder.events), tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ContentType HLS 播放列表的内容类型
+const ContentType = "application/vnd.apple.mpegurl"
+
+// version 生成的播放列表使用的协议版本：EXTINF 为浮点数
+const version = 3
+
+// KeySize AES-128 内容密钥的字节数
+const KeySize = 16
+
+// uriAttribute 标签中的 URI 属性，例如 EXT-X-KEY、EXT-X-MAP、EXT-X-MEDIA
+var uriAttribute = regexp.MustCompile(`URI="([^"]*)"`)
+
+// Segment 媒体分片
+type Segment struct {
+	URI      string        // 分片地址，通常是相对媒体播放列表的文件名
+	Duration time.Duration // 分片时长
+	Size     int64         // 分片字节数，用于计算码率
+	KeyURI   string        // 分片使用 AES-128 加密时密钥的地址，为空表示不加密
+}
+
+// MediaPlaylist 点播媒体播放列表
+type MediaPlaylist struct {
+	Segments []Segment
+}
+
+// TargetDuration 最长分片时长向上取整的秒数
+func (p *MediaPlaylist) TargetDuration() int {
+	var longest time.Duration
+	for _, segment := range p.Segments {
+		longest = max(longest, segment.Duration)
+	}
+	return int(math.Ceil(longest.Seconds()))
+}
+
+// PeakBandwidth 码率最高的分片的码率（bit/s），作为 BANDWIDTH 属性
+func (p *MediaPlaylist) PeakBandwidth() int {
+	peak := 0
+	for _, segment := range p.Segments {
+		if segment.Duration > 0 {
+			peak = max(peak, int(math.Ceil(float64(segment.Size*8)/segment.Duration.Seconds())))
+		}
+	}
+	return peak
+}
+
+// AverageBandwidth 全部分片的平均码率（bit/s），作为 AVERAGE-BANDWIDTH 属性
+func (p *MediaPlaylist) AverageBandwidth() int {
+	var size int64
+	var duration time.Duration
+	for _, segment := range p.Segments {
+		size += segment.Size
+		duration += segment.Duration
+	}
+	if duration <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(size*8) / duration.Seconds()))
+}
+
+// Encode 生成 m3u8 文本
+func (p *MediaPlaylist) Encode() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#EXTM3U\n#EXT-X-VERSION:%d\n", version)
+	buf.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&buf, "#EXT-X-TARGETDURATION:%d\n", p.TargetDuration())
+	buf.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	keyURI := ""
+	for _, segment := range p.Segments {
+		if segment.KeyURI != keyURI {
+			if segment.KeyURI == "" {
+				buf.WriteString("#EXT-X-KEY:METHOD=NONE\n")
+			} else {
+				fmt.Fprintf(&buf, "#EXT-X-KEY:METHOD=AES-128,URI=\"%s\"\n", segment.KeyURI)
+			}
+			keyURI = segment.KeyURI
+		}
+		fmt.Fprintf(&buf, "#EXTINF:%.6f,\n%s\n", segment.Duration.Seconds(), segment.URI)
+	}
+	buf.WriteString("#EXT-X-ENDLIST\n")
+	return buf.Bytes()
+}
+
+// EncryptSegment 按 EXT-X-KEY METHOD=AES-128 加密分片：AES-128-CBC，PKCS#7 填充。
+// 播放列表不输出 IV 属性，IV 为分片的媒体序列号（128位大端），sequence 即分片在列表中的下标
+func EncryptSegment(plain, key []byte, sequence int) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("hls: key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	sealed := make([]byte, len(plain)+padding)
+	copy(sealed, plain)
+	for i := len(plain); i < len(sealed); i++ {
+		sealed[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(sealed, sealed)
+	return sealed, nil
+}
+
+// Variant 主播放列表中的一个码率
+type Variant struct {
+	URI              string // 媒体播放列表地址
+	Bandwidth        int    // 峰值码率（bit/s）
+	AverageBandwidth int    // 平均码率（bit/s），为0时不输出
+	Width            int
+	Height           int
+	Codecs           string // RFC 6381 编码，例如 avc1.64001f,mp4a.40.2，为空时不输出
+}
+
+// MasterPlaylist 主播放列表
+type MasterPlaylist struct {
+	Variants []Variant
+}
+
+// Encode 生成 m3u8 文本，码率按给定顺序输出
+func (p *MasterPlaylist) Encode() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#EXTM3U\n#EXT-X-VERSION:%d\n", version)
+	buf.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	for _, variant := range p.Variants {
+		attributes := []string{fmt.Sprintf("BANDWIDTH=%d", variant.Bandwidth)}
+		if variant.AverageBandwidth > 0 {
+			attributes = append(attributes, fmt.Sprintf("AVERAGE-BANDWIDTH=%d", variant.AverageBandwidth))
+		}
+		if variant.Width > 0 && variant.Height > 0 {
+			attributes = append(attributes, fmt.Sprintf("RESOLUTION=%dx%d", variant.Width, variant.Height))
+		}
+		if variant.Codecs != "" {
+			attributes = append(attributes, `CODECS="`+variant.Codecs+`"`)
+		}
+		fmt.Fprintf(&buf, "#EXT-X-STREAM-INF:%s\n%s\n", strings.Join(attributes, ","), variant.URI)
+	}
+	return buf.Bytes()
+}
+
+// RewriteURIs 逐个替换播放列表中的地址：不以#开头的非空行，以及标签中的 URI 属性；其他内容原样保留
+func RewriteURIs(playlist []byte, rewrite func(uri string) (string, error)) ([]byte, error) {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case !strings.HasPrefix(trimmed, "#"):
+			uri, err := rewrite(trimmed)
+			if err != nil {
+				return nil, err
+			}
+			line = uri
+		case strings.HasPrefix(trimmed, "#EXT"):
+			var rewriteErr error
+			line = uriAttribute.ReplaceAllStringFunc(line, func(attribute string) string {
+				uri, err := rewrite(uriAttribute.FindStringSubmatch(attribute)[1])
+				if err != nil {
+					rewriteErr = err
+					return attribute
+				}
+				return `URI="` + uri + `"`
+			})
+			if rewriteErr != nil {
+				return nil, rewriteErr
+			}
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
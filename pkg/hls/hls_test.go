@@ -0,0 +1,272 @@
+package hls
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMediaPlaylistEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist MediaPlaylist
+		want     string
+	}{
+		{
+			name:     "empty",
+			playlist: MediaPlaylist{},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:0\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-ENDLIST\n",
+		},
+		{
+			name: "clear segments",
+			playlist: MediaPlaylist{Segments: []Segment{
+				{URI: "seg_0.ts", Duration: 6 * time.Second},
+				{URI: "seg_1.ts", Duration: 6006 * time.Millisecond},
+				{URI: "seg_2.ts", Duration: 1500 * time.Millisecond},
+			}},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:7\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n" +
+				"#EXTINF:6.000000,\nseg_0.ts\n" +
+				"#EXTINF:6.006000,\nseg_1.ts\n" +
+				"#EXTINF:1.500000,\nseg_2.ts\n" +
+				"#EXT-X-ENDLIST\n",
+		},
+		{
+			name: "key rotation",
+			playlist: MediaPlaylist{Segments: []Segment{
+				{URI: "seg_0.ts", Duration: 4 * time.Second, KeyURI: "key/0"},
+				{URI: "seg_1.ts", Duration: 4 * time.Second, KeyURI: "key/0"},
+				{URI: "seg_2.ts", Duration: 4 * time.Second, KeyURI: "key/1"},
+				{URI: "seg_3.ts", Duration: 4 * time.Second},
+			}},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:4\n" +
+				"#EXT-X-MEDIA-SEQUENCE:0\n" +
+				"#EXT-X-KEY:METHOD=AES-128,URI=\"key/0\"\n" +
+				"#EXTINF:4.000000,\nseg_0.ts\n" +
+				"#EXTINF:4.000000,\nseg_1.ts\n" +
+				"#EXT-X-KEY:METHOD=AES-128,URI=\"key/1\"\n" +
+				"#EXTINF:4.000000,\nseg_2.ts\n" +
+				"#EXT-X-KEY:METHOD=NONE\n" +
+				"#EXTINF:4.000000,\nseg_3.ts\n" +
+				"#EXT-X-ENDLIST\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.playlist.Encode()); got != tt.want {
+				t.Fatalf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaPlaylistBandwidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		segments    []Segment
+		wantPeak    int
+		wantAverage int
+	}{
+		{name: "empty"},
+		{
+			name: "single segment",
+			segments: []Segment{
+				{Duration: 2 * time.Second, Size: 250_000},
+			},
+			wantPeak:    1_000_000,
+			wantAverage: 1_000_000,
+		},
+		{
+			name: "peak and average differ",
+			segments: []Segment{
+				{Duration: 4 * time.Second, Size: 500_000},
+				{Duration: 2 * time.Second, Size: 500_000},
+			},
+			wantPeak:    2_000_000,
+			wantAverage: 1_333_334,
+		},
+		{
+			name: "zero duration ignored for peak",
+			segments: []Segment{
+				{Duration: 0, Size: 100},
+				{Duration: time.Second, Size: 100},
+			},
+			wantPeak:    800,
+			wantAverage: 1600,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist := &MediaPlaylist{Segments: tt.segments}
+			if got := playlist.PeakBandwidth(); got != tt.wantPeak {
+				t.Fatalf("PeakBandwidth() = %d, want %d", got, tt.wantPeak)
+			}
+			if got := playlist.AverageBandwidth(); got != tt.wantAverage {
+				t.Fatalf("AverageBandwidth() = %d, want %d", got, tt.wantAverage)
+			}
+		})
+	}
+}
+
+func TestMasterPlaylistEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		want     string
+	}{
+		{
+			name: "empty",
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-INDEPENDENT-SEGMENTS\n",
+		},
+		{
+			name: "all attributes in given order",
+			variants: []Variant{
+				{URI: "720p/index.m3u8", Bandwidth: 3_000_000, AverageBandwidth: 2_500_000, Width: 1280, Height: 720,
+					Codecs: "avc1.64001f,mp4a.40.2"},
+				{URI: "360p/index.m3u8", Bandwidth: 800_000, Width: 640, Height: 360},
+			},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-INDEPENDENT-SEGMENTS\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=3000000,AVERAGE-BANDWIDTH=2500000,RESOLUTION=1280x720," +
+				"CODECS=\"avc1.64001f,mp4a.40.2\"\n720p/index.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360\n360p/index.m3u8\n",
+		},
+		{
+			name: "resolution needs both dimensions",
+			variants: []Variant{
+				{URI: "audio/index.m3u8", Bandwidth: 128_000, Width: 640},
+			},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-INDEPENDENT-SEGMENTS\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=128000\naudio/index.m3u8\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist := &MasterPlaylist{Variants: tt.variants}
+			if got := string(playlist.Encode()); got != tt.want {
+				t.Fatalf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncryptSegment(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	tests := []struct {
+		name     string
+		plain    []byte
+		sequence int
+	}{
+		{name: "empty", plain: nil, sequence: 0},
+		{name: "partial block", plain: []byte("segment"), sequence: 1},
+		{name: "full block", plain: bytes.Repeat([]byte{0x47}, aes.BlockSize), sequence: 2},
+		{name: "ts packets", plain: bytes.Repeat([]byte{0x47}, 188*7), sequence: 1 << 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := EncryptSegment(tt.plain, key, tt.sequence)
+			if err != nil {
+				t.Fatalf("EncryptSegment: %v", err)
+			}
+			if len(sealed)%aes.BlockSize != 0 || len(sealed) <= len(tt.plain) {
+				t.Fatalf("sealed %d bytes for %d plain bytes", len(sealed), len(tt.plain))
+			}
+			// 播放器按媒体序列号推导 IV 后解密
+			block, _ := aes.NewCipher(key)
+			iv := make([]byte, aes.BlockSize)
+			binary.BigEndian.PutUint64(iv[8:], uint64(tt.sequence))
+			opened := bytes.Clone(sealed)
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(opened, opened)
+			padding := int(opened[len(opened)-1])
+			if padding < 1 || padding > aes.BlockSize {
+				t.Fatalf("invalid padding %d", padding)
+			}
+			if got := opened[:len(opened)-padding]; !bytes.Equal(got, tt.plain) {
+				t.Fatalf("decrypted %q, want %q", got, tt.plain)
+			}
+		})
+	}
+
+	if _, err := EncryptSegment([]byte("segment"), key[:8], 0); err == nil {
+		t.Fatal("EncryptSegment with a short key should fail")
+	}
+}
+
+func TestRewriteURIs(t *testing.T) {
+	prefix := func(uri string) (string, error) { return "https://cdn.example.com/" + uri, nil }
+	tests := []struct {
+		name     string
+		playlist string
+		rewrite  func(string) (string, error)
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "segments and key",
+			playlist: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key/0\"\n#EXTINF:4.000000,\nseg_0.ts\n#EXT-X-ENDLIST\n",
+			rewrite:  prefix,
+			want: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"https://cdn.example.com/key/0\"\n" +
+				"#EXTINF:4.000000,\nhttps://cdn.example.com/seg_0.ts\n#EXT-X-ENDLIST\n",
+		},
+		{
+			name:     "master playlist with CRLF and comments",
+			playlist: "#EXTM3U\r\n# comment URI=\"x\"\r\n#EXT-X-STREAM-INF:BANDWIDTH=1\r\n  720p/index.m3u8  \r\n\r\n",
+			rewrite:  prefix,
+			want: "#EXTM3U\n# comment URI=\"x\"\n#EXT-X-STREAM-INF:BANDWIDTH=1\n" +
+				"https://cdn.example.com/720p/index.m3u8\n\n",
+		},
+		{
+			name:     "map attribute",
+			playlist: "#EXT-X-MAP:URI=\"init.mp4\",BYTERANGE=\"720@0\"\n",
+			rewrite:  prefix,
+			want:     "#EXT-X-MAP:URI=\"https://cdn.example.com/init.mp4\",BYTERANGE=\"720@0\"\n",
+		},
+		{
+			name:     "segment error",
+			playlist: "#EXTM3U\nseg_0.ts\n",
+			rewrite:  func(string) (string, error) { return "", errors.New("denied") },
+			wantErr:  true,
+		},
+		{
+			name:     "attribute error",
+			playlist: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key/0\"\n",
+			rewrite:  func(string) (string, error) { return "", errors.New("denied") },
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RewriteURIs([]byte(tt.playlist), tt.rewrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RewriteURIs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.want {
+				t.Fatalf("RewriteURIs =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodedPlaylistsParse(t *testing.T) {
+	// 点播播放列表可以被直播解析器读回，时长保留到微秒
+	segments := []Segment{
+		{URI: "seg_0.ts", Duration: 3003 * time.Millisecond},
+		{URI: "seg_1.ts", Duration: 1234567 * time.Microsecond},
+	}
+	parsed, err := ParseMediaPlaylist((&MediaPlaylist{Segments: segments}).Encode())
+	if err != nil {
+		t.Fatalf("ParseMediaPlaylist: %v", err)
+	}
+	if !parsed.Ended || len(parsed.Segments) != len(segments) {
+		t.Fatalf("parsed %+v", parsed)
+	}
+	for i, segment := range parsed.Segments {
+		if segment.URI != segments[i].URI || segment.Duration != segments[i].Duration {
+			t.Fatalf("segment %d = %+v, want %+v", i, segment, segments[i])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LivePlaylist 直播的滑动窗口媒体播放列表，只包含最近的若干个分片
+type LivePlaylist struct {
+	MediaSequence int       // 第一个分片的媒体序列号，窗口滑动时递增
+	Segments      []Segment // 窗口内的分片，按播放顺序
+	Ended         bool      // 直播已结束，输出 EXT-X-ENDLIST，播放器播完窗口后停止刷新
+}
+
+// TargetDuration 最长分片时长向上取整的秒数，没有分片时为1
+func (p *LivePlaylist) TargetDuration() int {
+	var longest time.Duration
+	for _, segment := range p.Segments {
+		longest = max(longest, segment.Duration)
+	}
+	return max(int(math.Ceil(longest.Seconds())), 1)
+}
+
+// Encode 生成 m3u8 文本：不输出 EXT-X-PLAYLIST-TYPE，播放器按 TARGETDURATION 定期重新拉取
+func (p *LivePlaylist) Encode() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#EXTM3U\n#EXT-X-VERSION:%d\n", version)
+	fmt.Fprintf(&buf, "#EXT-X-TARGETDURATION:%d\n", p.TargetDuration())
+	fmt.Fprintf(&buf, "#EXT-X-MEDIA-SEQUENCE:%d\n", p.MediaSequence)
+	for _, segment := range p.Segments {
+		fmt.Fprintf(&buf, "#EXTINF:%.6f,\n%s\n", segment.Duration.Seconds(), segment.URI)
+	}
+	if p.Ended {
+		buf.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return buf.Bytes()
+}
+
+// ParsedPlaylist 从编码器推送的媒体播放列表中解析出的分片
+type ParsedPlaylist struct {
+	Segments []Segment // 分片地址和时长，按播放顺序
+	Ended    bool      // 包含 EXT-X-ENDLIST
+}
+
+// ParseMediaPlaylist 解析媒体播放列表中的分片地址和 EXTINF 时长，不支持主播放列表和加密分片
+func ParseMediaPlaylist(content []byte) (*ParsedPlaylist, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")) != "#EXTM3U" {
+		return nil, fmt.Errorf("hls: playlist does not start with #EXTM3U")
+	}
+	playlist := &ParsedPlaylist{}
+	var duration time.Duration
+	pending := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || seconds < 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+				return nil, fmt.Errorf("hls: invalid EXTINF %q", line)
+			}
+			duration = time.Duration(math.Round(seconds*1e6)) * time.Microsecond
+			pending = true
+		case line == "#EXT-X-ENDLIST":
+			playlist.Ended = true
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF"):
+			return nil, fmt.Errorf("hls: master playlists are not supported")
+		case strings.HasPrefix(line, "#EXT-X-KEY") && !strings.Contains(line, "METHOD=NONE"):
+			return nil, fmt.Errorf("hls: encrypted segments are not supported")
+		case strings.HasPrefix(line, "#"):
+		default:
+			if !pending {
+				return nil, fmt.Errorf("hls: segment %q has no EXTINF", line)
+			}
+			playlist.Segments = append(playlist.Segments, Segment{URI: line, Duration: duration})
+			pending = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
@@ -0,0 +1,105 @@
+package hls
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLivePlaylistEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist LivePlaylist
+		want     string
+	}{
+		{
+			name:     "no segments yet",
+			playlist: LivePlaylist{},
+			want:     "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:1\n#EXT-X-MEDIA-SEQUENCE:0\n",
+		},
+		{
+			name: "sliding window",
+			playlist: LivePlaylist{MediaSequence: 42, Segments: []Segment{
+				{URI: "seg_42.ts", Duration: 2 * time.Second},
+				{URI: "seg_43.ts", Duration: 2100 * time.Millisecond},
+			}},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:3\n#EXT-X-MEDIA-SEQUENCE:42\n" +
+				"#EXTINF:2.000000,\nseg_42.ts\n#EXTINF:2.100000,\nseg_43.ts\n",
+		},
+		{
+			name: "ended",
+			playlist: LivePlaylist{MediaSequence: 7, Ended: true, Segments: []Segment{
+				{URI: "seg_7.ts", Duration: 500 * time.Millisecond},
+			}},
+			want: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:1\n#EXT-X-MEDIA-SEQUENCE:7\n" +
+				"#EXTINF:0.500000,\nseg_7.ts\n#EXT-X-ENDLIST\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.playlist.Encode()); got != tt.want {
+				t.Fatalf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMediaPlaylist(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      []Segment
+		wantEnded bool
+		wantErr   bool
+	}{
+		{
+			name: "live window",
+			content: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:10\n" +
+				"#EXTINF:2.002,\nseg_10.ts\n#EXTINF:1.9999995,title\nseg_11.ts\n",
+			want: []Segment{
+				{URI: "seg_10.ts", Duration: 2002 * time.Millisecond},
+				{URI: "seg_11.ts", Duration: 2 * time.Second},
+			},
+		},
+		{
+			name:      "ended with BOM, CRLF and blank lines",
+			content:   "\ufeff#EXTM3U\r\n\r\n#EXTINF:4,\r\nseg_0.ts\r\n#EXT-X-KEY:METHOD=NONE\r\n#EXT-X-ENDLIST\r\n",
+			want:      []Segment{{URI: "seg_0.ts", Duration: 4 * time.Second}},
+			wantEnded: true,
+		},
+		{
+			name:    "empty playlist",
+			content: "#EXTM3U\n",
+		},
+		{name: "missing header", content: "#EXTINF:4,\nseg_0.ts\n", wantErr: true},
+		{name: "empty content", content: "", wantErr: true},
+		{name: "segment without EXTINF", content: "#EXTM3U\nseg_0.ts\n", wantErr: true},
+		{name: "negative duration", content: "#EXTM3U\n#EXTINF:-1,\nseg_0.ts\n", wantErr: true},
+		{name: "non numeric duration", content: "#EXTM3U\n#EXTINF:abc,\nseg_0.ts\n", wantErr: true},
+		{name: "infinite duration", content: "#EXTM3U\n#EXTINF:Inf,\nseg_0.ts\n", wantErr: true},
+		{name: "master playlist", content: "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\nindex.m3u8\n", wantErr: true},
+		{
+			name:    "encrypted segments",
+			content: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key\"\n#EXTINF:4,\nseg_0.ts\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMediaPlaylist([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMediaPlaylist error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Ended != tt.wantEnded || len(got.Segments) != len(tt.want) {
+				t.Fatalf("ParseMediaPlaylist = %+v, want segments %+v ended %v", got, tt.want, tt.wantEnded)
+			}
+			for i, segment := range got.Segments {
+				if segment != tt.want[i] {
+					t.Fatalf("segment %d = %+v, want %+v", i, segment, tt.want[i])
+				}
+			}
+		})
+	}
+}
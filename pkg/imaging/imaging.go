@@ -0,0 +1,132 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// ContentTypeJPEG 生成的图片统一为JPEG
+const ContentTypeJPEG = "image/jpeg"
+
+// DefaultQuality JPEG 编码质量
+const DefaultQuality = 85
+
+var (
+	// ErrUnsupportedFormat 不是 JPEG、PNG 或 GIF 图片
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	// ErrTooLarge 图片像素数超过上限
+	ErrTooLarge = errors.New("imaging: image dimensions exceed the limit")
+)
+
+// supportedFormats 接受的图片格式（标准库解码器注册的名称）
+var supportedFormats = map[string]bool{"jpeg": true, "png": true, "gif": true}
+
+// Decode 解码 JPEG、PNG 或 GIF 图片（GIF 取第一帧）。先读取图片头，宽高乘积超过 maxPixels 时不解码像素，
+// 避免很小的文件解码出巨大的图片；maxPixels 为0时不限制
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !supportedFormats[format] {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("imaging: invalid dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: decode %s: %w", format, err)
+	}
+	return img, nil
+}
+
+// Fit 等比缩小到不超过 maxWidth x maxHeight，不放大；透明部分以白色填充
+func Fit(src image.Image, maxWidth, maxHeight int) *image.RGBA {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if width > maxWidth {
+		height = max(height*maxWidth/width, 1)
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width = max(width*maxHeight/height, 1)
+		height = maxHeight
+	}
+	return Resize(src, width, height)
+}
+
+// Resize 缩放到 width x height，透明部分以白色填充。缩小时每个目标像素取对应源区域的平均值，放大时取最近的源像素
+func Resize(src image.Image, width, height int) *image.RGBA {
+	flat := flatten(src)
+	sw, sh := flat.Bounds().Dx(), flat.Bounds().Dy()
+	if sw == width && sh == height {
+		return flat
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		sy0 := dy * sh / height
+		sy1 := max((dy+1)*sh/height, sy0+1)
+		for dx := 0; dx < width; dx++ {
+			sx0 := dx * sw / width
+			sx1 := max((dx+1)*sw/width, sx0+1)
+			var r, g, b, n int
+			for sy := sy0; sy < sy1; sy++ {
+				offset := flat.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += int(flat.Pix[offset])
+					g += int(flat.Pix[offset+1])
+					b += int(flat.Pix[offset+2])
+					offset += 4
+					n++
+				}
+			}
+			offset := dst.PixOffset(dx, dy)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 0xff
+		}
+	}
+	return dst
+}
+
+// Sprite 把缩略图按行排列到一张图上，每行 columns 张，不足 tileWidth x tileHeight 的缩略图会被缩放
+func Sprite(tiles []image.Image, columns, tileWidth, tileHeight int) *image.RGBA {
+	columns = max(min(columns, len(tiles)), 1)
+	rows := (len(tiles) + columns - 1) / columns
+	sprite := image.NewRGBA(image.Rect(0, 0, columns*tileWidth, max(rows, 1)*tileHeight))
+	draw.Draw(sprite, sprite.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	for i, tile := range tiles {
+		if tile.Bounds().Dx() != tileWidth || tile.Bounds().Dy() != tileHeight {
+			tile = Resize(tile, tileWidth, tileHeight)
+		}
+		at := image.Pt(i%columns*tileWidth, i/columns*tileHeight)
+		draw.Draw(sprite, image.Rectangle{Min: at, Max: at.Add(image.Pt(tileWidth, tileHeight))}, tile, tile.Bounds().Min, draw.Src)
+	}
+	return sprite
+}
+
+// EncodeJPEG 编码为JPEG
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten 转为从原点开始的不透明RGBA图片
+func flatten(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,162 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// solid 指定尺寸的纯色图片
+func solid(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		maxWidth, maxHeight   int
+		wantWidth, wantHeight int
+	}{
+		{name: "landscape 16:9", width: 1920, height: 1080, maxWidth: 1280, maxHeight: 720, wantWidth: 1280, wantHeight: 720},
+		{name: "wide landscape limited by width", width: 4000, height: 1000, maxWidth: 1920, maxHeight: 1080, wantWidth: 1920, wantHeight: 480},
+		{name: "landscape limited by height", width: 1600, height: 1200, maxWidth: 1920, maxHeight: 600, wantWidth: 800, wantHeight: 600},
+		{name: "portrait 9:16", width: 1080, height: 1920, maxWidth: 1920, maxHeight: 1080, wantWidth: 607, wantHeight: 1080},
+		{name: "portrait limited by both", width: 3000, height: 4000, maxWidth: 1920, maxHeight: 1080, wantWidth: 810, wantHeight: 1080},
+		{name: "tall portrait", width: 1000, height: 4000, maxWidth: 1280, maxHeight: 720, wantWidth: 180, wantHeight: 720},
+		{name: "square into landscape box", width: 2000, height: 2000, maxWidth: 1920, maxHeight: 1080, wantWidth: 1080, wantHeight: 1080},
+		{name: "smaller image not enlarged", width: 640, height: 480, maxWidth: 1920, maxHeight: 1080, wantWidth: 640, wantHeight: 480},
+		{name: "extreme ratio keeps one pixel", width: 5000, height: 1, maxWidth: 100, maxHeight: 100, wantWidth: 100, wantHeight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(solid(tt.width, tt.height, color.White), tt.maxWidth, tt.maxHeight).Bounds()
+			if got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Fatalf("Fit(%dx%d, %dx%d) = %dx%d, want %dx%d", tt.width, tt.height, tt.maxWidth, tt.maxHeight,
+					got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestSprite(t *testing.T) {
+	const tileWidth, tileHeight = 16, 9
+	tileColor := func(i int) color.RGBA {
+		return color.RGBA{R: uint8(40 * (i + 1)), G: uint8(i), B: 0x80, A: 0xff}
+	}
+	tiles := func(n int) []image.Image {
+		images := make([]image.Image, n)
+		for i := range images {
+			images[i] = solid(tileWidth, tileHeight, tileColor(i))
+		}
+		return images
+	}
+	tests := []struct {
+		name                  string
+		tiles                 int
+		columns               int
+		wantWidth, wantHeight int
+		wantColumns           int
+	}{
+		{name: "full grid", tiles: 6, columns: 3, wantWidth: 48, wantHeight: 18, wantColumns: 3},
+		{name: "partial last row", tiles: 5, columns: 3, wantWidth: 48, wantHeight: 18, wantColumns: 3},
+		{name: "single row", tiles: 4, columns: 4, wantWidth: 64, wantHeight: 9, wantColumns: 4},
+		{name: "fewer tiles than columns", tiles: 2, columns: 10, wantWidth: 32, wantHeight: 9, wantColumns: 2},
+		{name: "single column", tiles: 3, columns: 1, wantWidth: 16, wantHeight: 27, wantColumns: 1},
+		{name: "non-positive columns", tiles: 2, columns: 0, wantWidth: 16, wantHeight: 18, wantColumns: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sprite := Sprite(tiles(tt.tiles), tt.columns, tileWidth, tileHeight)
+			if got := sprite.Bounds(); got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Fatalf("Sprite size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+			// 第 i 张缩略图位于第 i/columns 行、第 i%columns 列，检查四个角
+			for i := 0; i < tt.tiles; i++ {
+				x0, y0 := i%tt.wantColumns*tileWidth, i/tt.wantColumns*tileHeight
+				for _, p := range []image.Point{{x0, y0}, {x0 + tileWidth - 1, y0}, {x0, y0 + tileHeight - 1}, {x0 + tileWidth - 1, y0 + tileHeight - 1}} {
+					if got := sprite.RGBAAt(p.X, p.Y); got != tileColor(i) {
+						t.Fatalf("pixel %v = %v, want tile %d color %v", p, got, i, tileColor(i))
+					}
+				}
+			}
+			// 最后一行的空位为黑色
+			for i := tt.tiles; i%tt.wantColumns != 0; i++ {
+				x0, y0 := i%tt.wantColumns*tileWidth, i/tt.wantColumns*tileHeight
+				if got := sprite.RGBAAt(x0, y0); got != (color.RGBA{A: 0xff}) {
+					t.Fatalf("empty slot %d = %v, want black", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSpriteResizesMismatchedTiles(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	// 竖屏帧和横屏帧被缩放到同一尺寸，不会越过格子
+	sprite := Sprite([]image.Image{solid(9, 16, red), solid(32, 18, blue)}, 2, 16, 9)
+	if got := sprite.Bounds(); got.Dx() != 32 || got.Dy() != 9 {
+		t.Fatalf("Sprite size = %dx%d, want 32x9", got.Dx(), got.Dy())
+	}
+	for _, tt := range []struct {
+		x    int
+		want color.RGBA
+	}{{0, red}, {15, red}, {16, blue}, {31, blue}} {
+		if got := sprite.RGBAAt(tt.x, 8); got != tt.want {
+			t.Fatalf("pixel (%d, 8) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestResizeAveragesAndFlattens(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 200, A: 0xff})
+	src.SetRGBA(1, 0, color.RGBA{R: 100, A: 0xff})
+	if got := Resize(src, 1, 1).RGBAAt(0, 0); got != (color.RGBA{R: 150, A: 0xff}) {
+		t.Fatalf("downscaled pixel = %v, want average", got)
+	}
+	transparent := image.NewRGBA(image.Rect(5, 5, 7, 7))
+	if got := Resize(transparent, 4, 4).RGBAAt(3, 3); got != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Fatalf("transparent pixel = %v, want white", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := encodePNG(t, solid(40, 30, color.White))
+	img, err := Decode(data, 40*30)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got := img.Bounds(); got.Dx() != 40 || got.Dy() != 30 {
+		t.Fatalf("Decode size = %dx%d, want 40x30", got.Dx(), got.Dy())
+	}
+	if _, err := Decode(data, 40*30-1); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Decode over the pixel limit error = %v, want ErrTooLarge", err)
+	}
+	if _, err := Decode([]byte("<svg></svg>"), 0); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Decode svg error = %v, want ErrUnsupportedFormat", err)
+	}
+	jpegData, err := EncodeJPEG(solid(8, 8, color.Black), DefaultQuality)
+	if err != nil {
+		t.Fatalf("EncodeJPEG: %v", err)
+	}
+	if _, err := Decode(jpegData, 0); err != nil {
+		t.Fatalf("Decode jpeg: %v", err)
+	}
+}
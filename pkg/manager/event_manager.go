@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"go-video/pkg/event"
+)
+
+type (
+	// EventHandlerPlugin 领域事件处理器插件
+	EventHandlerPlugin interface {
+		// Name 返回插件的名称，不同 EventHandlerPlugin 的名称不能相同；
+		// 名称同时作为处理器的去重标识，修改后已处理过的事件会被重新处理
+		Name() string
+
+		// MustCreateEventHandler 创建 EventHandler，如果创建失败需要 panic
+		MustCreateEventHandler() EventHandler
+	}
+
+	// EventHandler 领域事件处理器。事件至少分发一次，同一事件可能重复到达，处理器需要以 event.ID 去重或保证幂等
+	EventHandler interface {
+		// EventTypes 订阅的事件类型
+		EventTypes() []event.Type
+
+		// Handle 处理事件，返回错误时该事件稍后重新分发给这个处理器
+		Handle(ctx context.Context, e *event.Event) error
+	}
+)
+
+var (
+	eventHandlerPlugins = map[string]EventHandlerPlugin{}
+)
+
+// RegisterEventHandlerPlugin 注册领域事件处理器插件
+func RegisterEventHandlerPlugin(p EventHandlerPlugin) {
+	if p.Name() == "" {
+		panic(fmt.Errorf("%T: empty name", p))
+	}
+
+	existedPlugin, existed := eventHandlerPlugins[p.Name()]
+	if existed {
+		panic(fmt.Errorf("%T and %T got same name: %s", p, existedPlugin, p.Name()))
+	}
+
+	eventHandlerPlugins[p.Name()] = p
+}
+
+// MustCreateEventHandlers 创建已注册的全部领域事件处理器，键为插件名称，如果失败则 panic
+func MustCreateEventHandlers() map[string]EventHandler {
+	handlers := make(map[string]EventHandler, len(eventHandlerPlugins))
+	for name, p := range eventHandlerPlugins {
+		handlers[name] = p.MustCreateEventHandler()
+	}
+	return handlers
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-video/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRequired 管理员权限中间件，需挂在认证中间件之后，用户不在管理员名单中时拒绝访问
+// 用法: router.Group("/v1/reconcile", middleware.AuthRequired(), middleware.AdminRequired())
+func AdminRequired() gin.HandlerFunc {
+	return roleRequired(config.IsAdmin, "需要管理员权限")
+}
+
+// ModeratorRequired 审核权限中间件，需挂在认证中间件之后，审核员和管理员可以访问
+func ModeratorRequired() gin.HandlerFunc {
+	return roleRequired(config.IsModerator, "需要审核权限")
+}
+
+// roleRequired 检查当前用户是否具有角色，没有登录信息时按未授权处理
+func roleRequired(hasRole func(userUUID string) bool, reason string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok || userUUID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    http.StatusUnauthorized,
+				"message": "未授权",
+				"error":   "缺少登录信息",
+			})
+			c.Abort()
+			return
+		}
+		if !hasRole(userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "禁止访问",
+				"error":   reason,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
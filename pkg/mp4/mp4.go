@@ -0,0 +1,557 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// MaxMoovSize moov 需要整体读入内存改写，超过该大小的文件不处理
+const MaxMoovSize = 64 << 20
+
+var (
+	// ErrNotMP4 不是 MP4/QuickTime 文件，或者缺少 moov、mdat
+	ErrNotMP4 = errors.New("mp4: not an mp4 file")
+	// ErrUnsupported 文件结构无法安全改写（压缩的 moov、分片 MP4、moov 过大等）
+	ErrUnsupported = errors.New("mp4: unsupported file layout")
+)
+
+// xmpUUID 顶层 uuid box 中 XMP 元数据的扩展类型，XMP 中可能带有拍摄位置和设备信息
+var xmpUUID = []byte{0xbe, 0x7a, 0xcf, 0xcb, 0x97, 0xa9, 0x42, 0xe8, 0x9c, 0x71, 0x99, 0x94, 0x91, 0xe3, 0xaf, 0xac}
+
+// containerTypes 改写块偏移和清除元数据时需要进入的容器box，其余box按原始字节保留
+var containerTypes = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true}
+
+// metadataTypes moov 和 trak 中需要整体清除的元数据box：udta 中有 ©xyz（位置）、©mak/©mod（设备）和自定义原子，
+// QuickTime 的 meta 以 keys 保存 com.apple.quicktime.location.ISO6709、make、model 等
+var metadataTypes = map[string]bool{"udta": true, "meta": true}
+
+// Options 改写选项
+type Options struct {
+	// Faststart 把位于 mdat 之后的 moov 移到第一个 mdat 之前
+	Faststart bool
+	// StripMetadata 清除 udta、meta 和 XMP 元数据box，媒体数据保持不变
+	StripMetadata bool
+}
+
+// Result 改写结果
+type Result struct {
+	// Rewritten 文件是否需要改写，为false时 Rewrite 不写入任何内容
+	Rewritten bool
+	// Relocated moov 是否被移到 mdat 之前
+	Relocated bool
+	// Stripped 被清除的元数据，例如 moov/udta/©xyz、moov/meta/com.apple.quicktime.make、uuid/xmp
+	Stripped []string
+}
+
+// Box 顶层box在文件中的位置
+type Box struct {
+	Type   string
+	Offset int64
+	Size   int64
+}
+
+// End 获取box结束的位置
+func (b Box) End() int64 {
+	return b.Offset + b.Size
+}
+
+// ReadBoxes 读取文件的顶层box。box长度越界或类型不是可打印字符时返回 ErrNotMP4
+func ReadBoxes(r io.ReaderAt, size int64) ([]Box, error) {
+	var boxes []Box
+	header := make([]byte, 16)
+	for offset := int64(0); offset < size; {
+		if size-offset < 8 {
+			return nil, ErrNotMP4
+		}
+		n, err := r.ReadAt(header, offset)
+		if n < 8 {
+			if err == nil || err == io.EOF {
+				err = ErrNotMP4
+			}
+			return nil, err
+		}
+		boxSize, headerSize := int64(binary.BigEndian.Uint32(header[0:4])), int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - offset
+		case 1:
+			if n < 16 {
+				return nil, ErrNotMP4
+			}
+			boxSize, headerSize = int64(binary.BigEndian.Uint64(header[8:16])), 16
+		}
+		boxType := string(header[4:8])
+		if !isBoxType(boxType) || boxSize < headerSize || boxSize > size-offset {
+			return nil, ErrNotMP4
+		}
+		boxes = append(boxes, Box{Type: boxType, Offset: offset, Size: boxSize})
+		offset += boxSize
+	}
+	return boxes, nil
+}
+
+// IsFaststart moov 是否已经在第一个 mdat 之前；缺少 moov 或 mdat 时返回 ErrNotMP4
+func IsFaststart(boxes []Box) (bool, error) {
+	moov, mdat := find(boxes, "moov"), find(boxes, "mdat")
+	if moov < 0 || mdat < 0 {
+		return false, ErrNotMP4
+	}
+	return moov < mdat, nil
+}
+
+// Inspect 按选项分析文件需要做的改写，不写入任何内容；只读取顶层box头、moov 和顶层 uuid box 的头部
+func Inspect(src io.ReaderAt, size int64, opts Options) (*Result, error) {
+	p, err := newPlan(src, size, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+// Rewrite 按选项改写文件写入dst：moov 移到 mdat 之前、清除元数据box，并按新的布局改写 stco/co64 中的块偏移。
+// mdat 等其余box按原始顺序从src流式复制，只有 moov 读入内存；32位偏移放不下时 stco 升级为 co64。
+// 不需要改写时不写入任何内容，返回的 Rewritten 为false
+func Rewrite(dst io.Writer, src io.ReaderAt, size int64, opts Options) (*Result, error) {
+	p, err := newPlan(src, size, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !p.result.Rewritten {
+		return p.result, nil
+	}
+	if err := p.relocate(); err != nil {
+		return nil, err
+	}
+	moov := p.moov.encode()
+	for i, box := range p.boxes {
+		if i == p.insertAt {
+			if _, err := dst.Write(moov); err != nil {
+				return nil, err
+			}
+		}
+		if box.Type == "moov" || p.removed[i] {
+			continue
+		}
+		if _, err := io.Copy(dst, io.NewSectionReader(src, box.Offset, box.Size)); err != nil {
+			return nil, err
+		}
+	}
+	return p.result, nil
+}
+
+// plan 一次改写的布局：moov 写在 boxes[insertAt] 之前，removed 中的顶层box不再写入
+type plan struct {
+	boxes    []Box
+	moov     *node
+	insertAt int
+	removed  map[int]bool
+	result   *Result
+}
+
+func newPlan(src io.ReaderAt, size int64, opts Options) (*plan, error) {
+	boxes, err := ReadBoxes(src, size)
+	if err != nil {
+		return nil, err
+	}
+	faststart, err := IsFaststart(boxes)
+	if err != nil {
+		return nil, err
+	}
+	if find(boxes, "moof") >= 0 {
+		return nil, ErrUnsupported
+	}
+	moovIndex := find(boxes, "moov")
+	if boxes[moovIndex].Size > MaxMoovSize {
+		return nil, ErrUnsupported
+	}
+	data := make([]byte, boxes[moovIndex].Size)
+	if _, err := src.ReadAt(data, boxes[moovIndex].Offset); err != nil {
+		return nil, err
+	}
+	moov, err := parseBox(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &plan{boxes: boxes, moov: moov, insertAt: moovIndex, removed: map[int]bool{}, result: &Result{}}
+	if opts.Faststart && !faststart {
+		p.insertAt = find(boxes, "mdat")
+		p.result.Relocated = true
+	}
+	if opts.StripMetadata {
+		p.result.Stripped = moov.strip("moov")
+		for i, box := range boxes {
+			stripped, err := topLevelMetadata(src, box)
+			if err != nil {
+				return nil, err
+			}
+			if stripped != "" {
+				p.removed[i] = true
+				p.result.Stripped = append(p.result.Stripped, stripped)
+			}
+		}
+	}
+	p.result.Rewritten = p.result.Relocated || len(p.result.Stripped) > 0
+	return p, nil
+}
+
+// relocate 按新的布局改写全部块偏移；偏移超出32位时把全部 stco 升级为 co64 后重新计算
+func (p *plan) relocate() error {
+	tables := p.moov.collect("stco", "co64")
+	for _, table := range tables {
+		if err := table.validateOffsets(); err != nil {
+			return err
+		}
+	}
+	originals := make([][]uint64, len(tables))
+	for i, table := range tables {
+		originals[i] = table.offsets()
+	}
+	for upgraded := false; ; upgraded = true {
+		starts := p.starts(int64(len(p.moov.encode())))
+		overflow := false
+		for i, table := range tables {
+			moved := make([]uint64, len(originals[i]))
+			for j, offset := range originals[i] {
+				target, err := p.translate(starts, offset)
+				if err != nil {
+					return err
+				}
+				moved[j] = target
+				overflow = overflow || (table.typ == "stco" && target > math.MaxUint32)
+			}
+			if overflow {
+				break
+			}
+			table.setOffsets(moved)
+		}
+		if !overflow {
+			return nil
+		}
+		if upgraded {
+			return ErrUnsupported
+		}
+		for i, table := range tables {
+			table.typ = "co64"
+			table.setOffsets(originals[i])
+		}
+	}
+}
+
+// starts 计算改写后每个保留的顶层box的起始位置
+func (p *plan) starts(moovSize int64) []int64 {
+	starts := make([]int64, len(p.boxes))
+	position := int64(0)
+	for i, box := range p.boxes {
+		if i == p.insertAt {
+			position += moovSize
+		}
+		if box.Type == "moov" || p.removed[i] {
+			continue
+		}
+		starts[i] = position
+		position += box.Size
+	}
+	return starts
+}
+
+// translate 把原文件中的偏移换算到新文件中
+func (p *plan) translate(starts []int64, offset uint64) (uint64, error) {
+	for i, box := range p.boxes {
+		if box.Type != "moov" && !p.removed[i] && int64(offset) >= box.Offset && int64(offset) < box.End() {
+			return uint64(starts[i] + int64(offset) - box.Offset), nil
+		}
+	}
+	return 0, fmt.Errorf("mp4: chunk offset %d outside of media data", offset)
+}
+
+// topLevelMetadata 顶层的 udta、meta 和 XMP uuid box 返回其名称，其他box返回空
+func topLevelMetadata(src io.ReaderAt, box Box) (string, error) {
+	switch box.Type {
+	case "udta", "meta":
+		return box.Type, nil
+	case "uuid":
+		header := make([]byte, 8+len(xmpUUID))
+		if box.Size < int64(len(header)) {
+			return "", nil
+		}
+		if _, err := src.ReadAt(header, box.Offset); err != nil {
+			return "", err
+		}
+		if bytes.Equal(header[8:], xmpUUID) {
+			return "uuid/xmp", nil
+		}
+	}
+	return "", nil
+}
+
+// node 读入内存的box，容器box只保存子box
+type node struct {
+	typ      string
+	payload  []byte
+	children []*node
+}
+
+// parseBox 解析一个完整的box（含box头）
+func parseBox(data []byte) (*node, error) {
+	nodes, err := parseBoxes(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, ErrNotMP4
+	}
+	return nodes[0], nil
+}
+
+func parseBoxes(data []byte) ([]*node, error) {
+	var nodes []*node
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, ErrNotMP4
+		}
+		size, headerSize := uint64(binary.BigEndian.Uint32(data[0:4])), uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, ErrNotMP4
+			}
+			size, headerSize = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		typ := string(data[4:8])
+		if !isBoxType(typ) || size < headerSize || size > uint64(len(data)) {
+			return nil, ErrNotMP4
+		}
+		if typ == "cmov" {
+			return nil, ErrUnsupported
+		}
+		n := &node{typ: typ}
+		if containerTypes[typ] {
+			children, err := parseBoxes(data[headerSize:size])
+			if err != nil {
+				return nil, err
+			}
+			n.children = children
+		} else {
+			n.payload = data[headerSize:size]
+		}
+		nodes = append(nodes, n)
+		data = data[size:]
+	}
+	return nodes, nil
+}
+
+// encode 编码box，长度超过32位时使用64位长度
+func (n *node) encode() []byte {
+	var content bytes.Buffer
+	if n.children != nil {
+		for _, child := range n.children {
+			content.Write(child.encode())
+		}
+	} else {
+		content.Write(n.payload)
+	}
+	var out bytes.Buffer
+	if size := uint64(content.Len()) + 8; size <= math.MaxUint32 {
+		_ = binary.Write(&out, binary.BigEndian, uint32(size))
+		out.WriteString(n.typ)
+	} else {
+		_ = binary.Write(&out, binary.BigEndian, uint32(1))
+		out.WriteString(n.typ)
+		_ = binary.Write(&out, binary.BigEndian, size+8)
+	}
+	out.Write(content.Bytes())
+	return out.Bytes()
+}
+
+// collect 深度优先收集指定类型的box
+func (n *node) collect(types ...string) []*node {
+	var found []*node
+	for _, child := range n.children {
+		for _, typ := range types {
+			if child.typ == typ {
+				found = append(found, child)
+			}
+		}
+		found = append(found, child.collect(types...)...)
+	}
+	return found
+}
+
+// strip 递归删除容器中的元数据box，返回被删除的元数据名称
+func (n *node) strip(path string) []string {
+	var stripped []string
+	kept := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		childPath := path + "/" + child.typ
+		switch {
+		case metadataTypes[child.typ]:
+			stripped = append(stripped, describeMetadata(childPath, child)...)
+			continue
+		case child.children != nil:
+			stripped = append(stripped, child.strip(childPath)...)
+		}
+		kept = append(kept, child)
+	}
+	n.children = kept
+	return stripped
+}
+
+// describeMetadata 列出元数据box中的条目：udta 列出其中的原子，QuickTime meta 列出 keys 中的键名；
+// 无法解析时只返回box本身
+func describeMetadata(path string, metadata *node) []string {
+	items, err := parseItems(metadata.payload)
+	if metadata.typ == "meta" && (err != nil || len(items) == 0) && len(metadata.payload) >= 4 {
+		// ISO 的 meta 是 FullBox，子box前有4字节 version 和 flags
+		items, err = parseItems(metadata.payload[4:])
+	}
+	if err != nil || len(items) == 0 {
+		return []string{path}
+	}
+	if metadata.typ == "meta" {
+		for _, item := range items {
+			if item.typ != "keys" {
+				continue
+			}
+			keys := parseKeys(item.payload)
+			if len(keys) == 0 {
+				break
+			}
+			described := make([]string, 0, len(keys))
+			for _, key := range keys {
+				described = append(described, path+"/"+key)
+			}
+			return described
+		}
+		return []string{path}
+	}
+	described := make([]string, 0, len(items))
+	for _, item := range items {
+		described = append(described, path+"/"+displayType(item.typ))
+	}
+	return described
+}
+
+// parseItems 解析元数据box中的子box，不检查类型（©xyz 等类型含有非ASCII字符）
+func parseItems(data []byte) ([]*node, error) {
+	var items []*node
+	for len(data) > 0 {
+		// udta 末尾可能有4字节的0作为结束标记
+		if len(data) == 4 && binary.BigEndian.Uint32(data) == 0 {
+			break
+		}
+		if len(data) < 8 {
+			return nil, ErrNotMP4
+		}
+		size := binary.BigEndian.Uint32(data[0:4])
+		if size < 8 || uint64(size) > uint64(len(data)) {
+			return nil, ErrNotMP4
+		}
+		items = append(items, &node{typ: string(data[4:8]), payload: data[8:size]})
+		data = data[size:]
+	}
+	return items, nil
+}
+
+// parseKeys 解析 QuickTime 元数据的 keys box：4字节 version/flags、4字节条目数，每个条目为长度、命名空间和键名
+func parseKeys(payload []byte) []string {
+	if len(payload) < 8 {
+		return nil
+	}
+	count := binary.BigEndian.Uint32(payload[4:8])
+	data := payload[8:]
+	var keys []string
+	for i := uint32(0); i < count && len(data) >= 8; i++ {
+		size := binary.BigEndian.Uint32(data[0:4])
+		if size < 8 || uint64(size) > uint64(len(data)) {
+			break
+		}
+		keys = append(keys, strings.ToValidUTF8(string(data[8:size]), "?"))
+		data = data[size:]
+	}
+	return keys
+}
+
+// displayType 把 QuickTime 以 0xA9 开头的原子类型显示为 ©
+func displayType(typ string) string {
+	if len(typ) == 4 && typ[0] == 0xa9 {
+		return "©" + strings.ToValidUTF8(typ[1:], "?")
+	}
+	return strings.ToValidUTF8(typ, "?")
+}
+
+// validateOffsets 检查 stco/co64 的条目数与长度是否一致
+func (n *node) validateOffsets() error {
+	if len(n.payload) < 8 {
+		return ErrNotMP4
+	}
+	width := uint64(4)
+	if n.typ == "co64" {
+		width = 8
+	}
+	count := uint64(binary.BigEndian.Uint32(n.payload[4:8]))
+	if uint64(len(n.payload)-8) < count*width {
+		return ErrNotMP4
+	}
+	return nil
+}
+
+// offsets 读取 stco/co64 中的块偏移
+func (n *node) offsets() []uint64 {
+	count := binary.BigEndian.Uint32(n.payload[4:8])
+	offsets := make([]uint64, count)
+	for i := range offsets {
+		if n.typ == "co64" {
+			offsets[i] = binary.BigEndian.Uint64(n.payload[8+i*8:])
+		} else {
+			offsets[i] = uint64(binary.BigEndian.Uint32(n.payload[8+i*4:]))
+		}
+	}
+	return offsets
+}
+
+// setOffsets 按box当前的类型重写块偏移，保留 version 和 flags
+func (n *node) setOffsets(offsets []uint64) {
+	width := 4
+	if n.typ == "co64" {
+		width = 8
+	}
+	payload := make([]byte, 8+len(offsets)*width)
+	copy(payload, n.payload[:4])
+	binary.BigEndian.PutUint32(payload[4:8], uint32(len(offsets)))
+	for i, offset := range offsets {
+		if width == 8 {
+			binary.BigEndian.PutUint64(payload[8+i*8:], offset)
+		} else {
+			binary.BigEndian.PutUint32(payload[8+i*4:], uint32(offset))
+		}
+	}
+	n.payload = payload
+}
+
+// find 获取第一个指定类型的顶层box的下标，不存在时返回-1
+func find(boxes []Box, typ string) int {
+	for i, box := range boxes {
+		if box.Type == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// isBoxType box类型为4个可打印ASCII字符（QuickTime 的 ©xyz 类型只出现在 udta 内部）
+func isBoxType(typ string) bool {
+	for i := 0; i < len(typ); i++ {
+		if typ[i] < 0x20 || typ[i] > 0x7e {
+			return false
+		}
+	}
+	return len(typ) == 4
+}
@@ -0,0 +1,504 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// box 编码一个box，payload 依次拼接
+func box(typ string, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(content)))
+	return append(append(out, typ...), content...)
+}
+
+// fullBox 编码 version 和 flags 为0的 FullBox
+func fullBox(typ string, payload ...[]byte) []byte {
+	return box(typ, append([][]byte{make([]byte, 4)}, payload...)...)
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+// chunkTable 编码 stco，wide 为true时编码 co64
+func chunkTable(wide bool, offsets ...uint64) []byte {
+	entries := u32(uint32(len(offsets)))
+	for _, offset := range offsets {
+		if wide {
+			entries = binary.BigEndian.AppendUint64(entries, offset)
+		} else {
+			entries = binary.BigEndian.AppendUint32(entries, uint32(offset))
+		}
+	}
+	if wide {
+		return fullBox("co64", entries)
+	}
+	return fullBox("stco", entries)
+}
+
+func trak(table []byte, extra ...[]byte) []byte {
+	stbl := box("stbl", fullBox("stsd", u32(0)), table)
+	mdia := box("mdia", fullBox("mdhd", make([]byte, 20)), box("minf", stbl))
+	return box("trak", append([][]byte{fullBox("tkhd", make([]byte, 80)), mdia}, extra...)...)
+}
+
+// quickTimeString udta 中 ©xyz 等原子的内容：2字节长度、2字节语言和文本
+func quickTimeString(text string) []byte {
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(text)))
+	return append(binary.BigEndian.AppendUint16(out, 0x15c7), text...)
+}
+
+// mdtaKey keys box 中的一个键
+func mdtaKey(name string) []byte {
+	return append(append(u32(uint32(8+len(name))), "mdta"...), name...)
+}
+
+// testFile 测试文件的布局
+type testFile struct {
+	faststart bool // moov 在 mdat 之前
+	metadata  bool // moov 和 trak 中带有 udta、meta
+	xmp       bool // mdat 之前有顶层 XMP uuid box
+	wide      bool // 块偏移使用 co64
+}
+
+// testChunks 写入 mdat 的块，前两个属于视频轨道，最后一个属于音频轨道
+var testChunks = [][]byte{[]byte("video-chunk-0"), []byte("video-chunk-1"), []byte("audio-chunk-0")}
+
+// build 生成测试文件，块偏移指向 mdat 中的 testChunks
+func (f testFile) build() []byte {
+	ftyp := box("ftyp", []byte("isom"), u32(0x200), []byte("isomiso2avc1mp41"))
+	free := box("free", make([]byte, 8))
+	var xmp []byte
+	if f.xmp {
+		xmp = box("uuid", xmpUUID, []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`))
+	}
+	var payload []byte
+	var positions []uint64
+	for _, chunk := range testChunks {
+		payload = append(payload, "pad"...)
+		positions = append(positions, uint64(len(payload)))
+		payload = append(payload, chunk...)
+	}
+	mdat := box("mdat", payload)
+
+	moov := func(base uint64) []byte {
+		offsets := make([]uint64, len(positions))
+		for i, position := range positions {
+			offsets[i] = base + position
+		}
+		var videoExtra, audioExtra, moovExtra [][]byte
+		if f.metadata {
+			videoExtra = append(videoExtra, box("udta", box("\xa9nam", quickTimeString("clip"))))
+			audioExtra = append(audioExtra, fullBox("meta", fullBox("hdlr", u32(0), []byte("mdir"), make([]byte, 13)), box("ilst")))
+			moovExtra = append(moovExtra,
+				box("udta", box("\xa9xyz", quickTimeString("+37.7749-122.4194/")), box("\xa9mak", quickTimeString("Apple")), u32(0)),
+				box("meta",
+					fullBox("hdlr", u32(0), []byte("mdta"), make([]byte, 13)),
+					fullBox("keys", u32(2), mdtaKey("com.apple.quicktime.location.ISO6709"), mdtaKey("com.apple.quicktime.make")),
+					box("ilst")))
+		}
+		children := [][]byte{
+			fullBox("mvhd", make([]byte, 96)),
+			trak(chunkTable(f.wide, offsets[:2]...), videoExtra...),
+			trak(chunkTable(f.wide, offsets[2:]...), audioExtra...),
+		}
+		return box("moov", append(children, moovExtra...)...)
+	}
+
+	head := bytes.Join([][]byte{ftyp, xmp, free}, nil)
+	if f.faststart {
+		// 块偏移的宽度固定，moov 的长度与偏移的取值无关
+		base := uint64(len(head)+len(moov(0))) + 8
+		return bytes.Join([][]byte{head, moov(base), mdat}, nil)
+	}
+	base := uint64(len(head)) + 8
+	return bytes.Join([][]byte{head, mdat, moov(base)}, nil)
+}
+
+// chunkOffsets 按轨道顺序读取文件中全部 stco/co64 的块偏移和表的类型
+func chunkOffsets(t *testing.T, file []byte) ([]uint64, []string) {
+	t.Helper()
+	boxes, err := ReadBoxes(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("ReadBoxes: %v", err)
+	}
+	moovIndex := find(boxes, "moov")
+	if moovIndex < 0 {
+		t.Fatal("no moov")
+	}
+	moov, err := parseBox(file[boxes[moovIndex].Offset:boxes[moovIndex].End()])
+	if err != nil {
+		t.Fatalf("parse moov: %v", err)
+	}
+	var offsets []uint64
+	var types []string
+	for _, table := range moov.collect("stco", "co64") {
+		offsets = append(offsets, table.offsets()...)
+		types = append(types, table.typ)
+	}
+	return offsets, types
+}
+
+// checkChunks 检查块偏移指向的内容仍然是原来的块
+func checkChunks(t *testing.T, file []byte) {
+	t.Helper()
+	offsets, _ := chunkOffsets(t, file)
+	if len(offsets) != len(testChunks) {
+		t.Fatalf("%d chunk offsets, want %d", len(offsets), len(testChunks))
+	}
+	for i, offset := range offsets {
+		chunk := testChunks[i]
+		if offset+uint64(len(chunk)) > uint64(len(file)) || !bytes.Equal(file[offset:offset+uint64(len(chunk))], chunk) {
+			t.Fatalf("chunk %d at offset %d does not point to %q", i, offset, chunk)
+		}
+	}
+}
+
+// checkGolden 与 testdata 中的期望输出比较，-update 时重新生成
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", strings.ReplaceAll(name, " ", "_")+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("output differs from %s (%d bytes, want %d); run go test -update after checking the change", path, len(got), len(want))
+	}
+}
+
+// allMetadata testFile 中 metadata 和 xmp 带有的全部元数据
+var allMetadata = []string{
+	"moov/trak/udta/©nam",
+	"moov/trak/meta",
+	"moov/udta/©xyz",
+	"moov/udta/©mak",
+	"moov/meta/com.apple.quicktime.location.ISO6709",
+	"moov/meta/com.apple.quicktime.make",
+	"uuid/xmp",
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          testFile
+		opts          Options
+		wantRewritten bool
+		wantRelocated bool
+		wantStripped  []string
+	}{
+		{
+			name:          "faststart",
+			file:          testFile{},
+			opts:          Options{Faststart: true},
+			wantRewritten: true,
+			wantRelocated: true,
+		},
+		{
+			name:          "faststart keeps metadata",
+			file:          testFile{metadata: true, xmp: true},
+			opts:          Options{Faststart: true},
+			wantRewritten: true,
+			wantRelocated: true,
+		},
+		{
+			name:          "faststart co64",
+			file:          testFile{wide: true},
+			opts:          Options{Faststart: true},
+			wantRewritten: true,
+			wantRelocated: true,
+		},
+		{
+			name:          "strip metadata",
+			file:          testFile{faststart: true, metadata: true, xmp: true},
+			opts:          Options{StripMetadata: true},
+			wantRewritten: true,
+			wantStripped:  allMetadata,
+		},
+		{
+			name:          "faststart and strip metadata",
+			file:          testFile{metadata: true, xmp: true},
+			opts:          Options{Faststart: true, StripMetadata: true},
+			wantRewritten: true,
+			wantRelocated: true,
+			wantStripped:  allMetadata,
+		},
+		{
+			name:          "strip xmp only",
+			file:          testFile{faststart: true, xmp: true},
+			opts:          Options{StripMetadata: true},
+			wantRewritten: true,
+			wantStripped:  []string{"uuid/xmp"},
+		},
+		{
+			name: "nothing to strip",
+			file: testFile{faststart: true},
+			opts: Options{Faststart: true, StripMetadata: true},
+		},
+		{
+			name: "already faststart",
+			file: testFile{faststart: true},
+			opts: Options{Faststart: true},
+		},
+		{
+			name: "no options",
+			file: testFile{metadata: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := tt.file.build()
+			checkChunks(t, src)
+			inspected, err := Inspect(bytes.NewReader(src), int64(len(src)), tt.opts)
+			if err != nil {
+				t.Fatalf("Inspect: %v", err)
+			}
+			var dst bytes.Buffer
+			result, err := Rewrite(&dst, bytes.NewReader(src), int64(len(src)), tt.opts)
+			if err != nil {
+				t.Fatalf("Rewrite: %v", err)
+			}
+			if !reflect.DeepEqual(inspected, result) {
+				t.Fatalf("Inspect = %+v, Rewrite = %+v", inspected, result)
+			}
+			if result.Rewritten != tt.wantRewritten || result.Relocated != tt.wantRelocated ||
+				!reflect.DeepEqual(result.Stripped, tt.wantStripped) {
+				t.Fatalf("Rewrite = %+v, want rewritten %v relocated %v stripped %q",
+					result, tt.wantRewritten, tt.wantRelocated, tt.wantStripped)
+			}
+			if !tt.wantRewritten {
+				if dst.Len() != 0 {
+					t.Fatalf("wrote %d bytes without rewriting", dst.Len())
+				}
+				return
+			}
+
+			out := dst.Bytes()
+			checkChunks(t, out)
+			boxes, err := ReadBoxes(bytes.NewReader(out), int64(len(out)))
+			if err != nil {
+				t.Fatalf("ReadBoxes output: %v", err)
+			}
+			if faststart, err := IsFaststart(boxes); err != nil || faststart != (tt.opts.Faststart || tt.file.faststart) {
+				t.Fatalf("IsFaststart output = %v, %v", faststart, err)
+			}
+			if _, types := chunkOffsets(t, out); tt.file.wide != (types[0] == "co64") {
+				t.Fatalf("chunk tables %q", types)
+			}
+			if tt.opts.StripMetadata {
+				for _, leaked := range []string{"udta", "meta", "+37.7749-122.4194/", "Apple", string(xmpUUID)} {
+					if bytes.Contains(out, []byte(leaked)) {
+						t.Fatalf("output still contains %q", leaked)
+					}
+				}
+			}
+			checkGolden(t, tt.name, out)
+		})
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	ftyp := box("ftyp", []byte("isom"), u32(0))
+	mdat := box("mdat", []byte("media data"))
+	tests := []struct {
+		name    string
+		file    []byte
+		wantErr error
+	}{
+		{name: "not mp4", file: []byte("definitely not an mp4 file"), wantErr: ErrNotMP4},
+		{name: "empty", file: nil, wantErr: ErrNotMP4},
+		{name: "no moov", file: bytes.Join([][]byte{ftyp, mdat}, nil), wantErr: ErrNotMP4},
+		{name: "no mdat", file: bytes.Join([][]byte{ftyp, box("moov", trak(chunkTable(false)))}, nil), wantErr: ErrNotMP4},
+		{name: "truncated", file: bytes.Join([][]byte{ftyp, mdat, box("moov")}, nil)[:len(ftyp)+len(mdat)+4], wantErr: ErrNotMP4},
+		{name: "invalid box type", file: bytes.Join([][]byte{ftyp, box("m\x00at"), box("moov")}, nil), wantErr: ErrNotMP4},
+		{
+			name:    "fragmented",
+			file:    bytes.Join([][]byte{ftyp, box("moov", trak(chunkTable(false))), box("moof"), mdat}, nil),
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "compressed moov",
+			file:    bytes.Join([][]byte{ftyp, mdat, box("moov", box("cmov", box("dcom", []byte("zlib"))))}, nil),
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "chunk count exceeds table",
+			file:    bytes.Join([][]byte{ftyp, mdat, box("moov", trak(fullBox("stco", u32(5), u32(24))))}, nil),
+			wantErr: ErrNotMP4,
+		},
+		{
+			name: "chunk offset outside media data",
+			file: bytes.Join([][]byte{ftyp, mdat, box("moov", trak(chunkTable(false, 1<<20)))}, nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Rewrite(io.Discard, bytes.NewReader(tt.file), int64(len(tt.file)), Options{Faststart: true})
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("Rewrite error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadBoxes(t *testing.T) {
+	large := binary.BigEndian.AppendUint32(nil, 1)
+	large = append(large, "mdat"...)
+	large = binary.BigEndian.AppendUint64(large, 20)
+	large = append(large, "abcd"...)
+	tests := []struct {
+		name string
+		file []byte
+		want []Box
+	}{
+		{
+			name: "compact headers",
+			file: bytes.Join([][]byte{box("ftyp", []byte("isom")), box("moov")}, nil),
+			want: []Box{{Type: "ftyp", Offset: 0, Size: 12}, {Type: "moov", Offset: 12, Size: 8}},
+		},
+		{
+			name: "64-bit size",
+			file: bytes.Join([][]byte{box("ftyp"), large}, nil),
+			want: []Box{{Type: "ftyp", Offset: 0, Size: 8}, {Type: "mdat", Offset: 8, Size: 20}},
+		},
+		{
+			name: "last box extends to end of file",
+			file: bytes.Join([][]byte{box("ftyp"), u32(0), []byte("mdat"), []byte("rest of file")}, nil),
+			want: []Box{{Type: "ftyp", Offset: 0, Size: 8}, {Type: "mdat", Offset: 8, Size: 20}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadBoxes(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if err != nil {
+				t.Fatalf("ReadBoxes: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ReadBoxes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sparseFile 只保存非零区域的大文件，其余字节为0
+type sparseFile struct {
+	size  int64
+	parts map[int64][]byte
+}
+
+func (f *sparseFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if remaining := f.size - off; int64(n) > remaining {
+		n = int(remaining)
+	}
+	clear(p[:n])
+	for start, data := range f.parts {
+		from, to := max(start, off), min(start+int64(len(data)), off+int64(n))
+		if from < to {
+			copy(p[from-off:to-off], data[from-start:to-start])
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// edgeWriter 只保留写入内容的开头和结尾
+type edgeWriter struct {
+	limit   int
+	written int64
+	head    []byte
+	tail    []byte
+}
+
+func (w *edgeWriter) Write(p []byte) (int, error) {
+	if room := w.limit - len(w.head); room > 0 {
+		w.head = append(w.head, p[:min(room, len(p))]...)
+	}
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > w.limit {
+		w.tail = append(w.tail[:0], w.tail[len(w.tail)-w.limit:]...)
+	}
+	w.written += int64(len(p))
+	return len(p), nil
+}
+
+func TestRewritePromotesToCo64(t *testing.T) {
+	if testing.Short() {
+		t.Skip("copies a 4GiB media data box")
+	}
+	// mdat 跨过4GiB，最后一个块的偏移刚好能用32位表示，moov 移到前面后超出32位
+	ftyp := box("ftyp", []byte("isom"), u32(0))
+	mdatOffset := int64(len(ftyp))
+	mdatSize := int64(math.MaxUint32) + 64
+	first, last := uint64(mdatOffset+16), uint64(math.MaxUint32-16)
+	mdatHeader := append(append(u32(1), "mdat"...), binary.BigEndian.AppendUint64(nil, uint64(mdatSize))...)
+	moov := box("moov", fullBox("mvhd", make([]byte, 96)), trak(chunkTable(false, first, last)))
+	file := &sparseFile{
+		size: mdatOffset + mdatSize + int64(len(moov)),
+		parts: map[int64][]byte{
+			0:                     ftyp,
+			mdatOffset:            mdatHeader,
+			int64(first):          testChunks[0],
+			int64(last):           testChunks[1],
+			mdatOffset + mdatSize: moov,
+		},
+	}
+
+	dst := &edgeWriter{limit: 64 << 10}
+	result, err := Rewrite(dst, file, file.size, Options{Faststart: true})
+	if err != nil {
+		t.Fatalf("Rewrite: %v", err)
+	}
+	if !result.Relocated || dst.written != file.size+8 {
+		t.Fatalf("Rewrite = %+v, wrote %d bytes, want %d", result, dst.written, file.size+8)
+	}
+
+	// stco 的两个条目升级为 co64 后 moov 增加8字节
+	newMoovSize := int64(len(moov)) + 8
+	newMoov, err := parseBox(dst.head[mdatOffset : mdatOffset+newMoovSize])
+	if err != nil {
+		t.Fatalf("parse moov: %v", err)
+	}
+	tables := newMoov.collect("stco", "co64")
+	if len(tables) != 1 || tables[0].typ != "co64" {
+		t.Fatalf("chunk tables = %+v, want one co64", tables)
+	}
+	offsets := tables[0].offsets()
+	if want := []uint64{first + uint64(newMoovSize), last + uint64(newMoovSize)}; !reflect.DeepEqual(offsets, want) {
+		t.Fatalf("offsets = %v, want %v", offsets, want)
+	}
+	if offsets[1] <= math.MaxUint32 {
+		t.Fatalf("last offset %d should need 64 bits", offsets[1])
+	}
+	if got := dst.head[offsets[0] : offsets[0]+uint64(len(testChunks[0]))]; !bytes.Equal(got, testChunks[0]) {
+		t.Fatalf("first chunk = %q", got)
+	}
+	tailStart := uint64(dst.written) - uint64(len(dst.tail))
+	if got := dst.tail[offsets[1]-tailStart : offsets[1]-tailStart+uint64(len(testChunks[1]))]; !bytes.Equal(got, testChunks[1]) {
+		t.Fatalf("last chunk = %q", got)
+	}
+	checkGolden(t, "co64 promotion moov", dst.head[mdatOffset:mdatOffset+newMoovSize])
+}
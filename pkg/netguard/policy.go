@@ -0,0 +1,210 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxRedirects 跟随重定向的最大次数，每次重定向都重新校验目标地址
+const maxRedirects = 5
+
+// reservedNetworks 除标准库识别的私有、回环、链路本地地址外，同样禁止访问的保留网段
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",          // 本网络
+	"100.64.0.0/10",      // 运营商级NAT
+	"192.0.0.0/24",       // IETF协议分配
+	"198.18.0.0/15",      // 基准测试
+	"240.0.0.0/4",        // 保留
+	"64:ff9b::/96",       // NAT64，可映射到内网IPv4
+	"2001:db8::/32",      // 文档
+	"100::/64",           // 丢弃
+	"255.255.255.255/32", // 广播
+)
+
+// DeniedError 地址不允许访问
+type DeniedError struct {
+	Target string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("netguard: %s is not allowed: %s", e.Target, e.Reason)
+}
+
+// Policy 出站请求的访问策略，防止服务端请求伪造（SSRF）：
+// 校验协议和主机名白名单，并在建立连接时校验实际连接的IP，内网和保留地址只有在网段白名单中才允许访问
+type Policy struct {
+	schemes  map[string]struct{}
+	hosts    []string
+	networks []*net.IPNet
+}
+
+// NewPolicy 创建访问策略。
+// schemes 为允许的协议，为空时只允许 https；
+// hosts 为允许的主机名，支持 *.example.com 匹配子域名，为空时不限制主机名；
+// networks 为允许访问的内网网段（CIDR或单个IP），未列出的内网和保留地址一律拒绝
+func NewPolicy(schemes, hosts, networks []string) (*Policy, error) {
+	p := &Policy{schemes: make(map[string]struct{})}
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	for _, scheme := range schemes {
+		p.schemes[strings.ToLower(scheme)] = struct{}{}
+	}
+	for _, host := range hosts {
+		p.hosts = append(p.hosts, strings.ToLower(strings.TrimSuffix(host, ".")))
+	}
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			ip := net.ParseIP(network)
+			if ip == nil {
+				return nil, fmt.Errorf("netguard: invalid network %q", network)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			p.networks = append(p.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, fmt.Errorf("netguard: invalid network %q: %w", network, err)
+		}
+		p.networks = append(p.networks, ipNet)
+	}
+	return p, nil
+}
+
+// CheckURL 校验URL的协议和主机名，主机为IP字面量时同时校验IP
+func (p *Policy) CheckURL(u *url.URL) error {
+	if _, ok := p.schemes[strings.ToLower(u.Scheme)]; !ok {
+		return &DeniedError{Target: u.Redacted(), Reason: "scheme " + u.Scheme + " is not allowed"}
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return &DeniedError{Target: u.Redacted(), Reason: "host is missing"}
+	}
+	if !p.hostAllowed(host) {
+		return &DeniedError{Target: u.Redacted(), Reason: "host " + host + " is not in the allowlist"}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return p.CheckIP(ip)
+	}
+	return nil
+}
+
+// CheckIP 校验IP，内网和保留地址需要在网段白名单中
+func (p *Policy) CheckIP(ip net.IP) error {
+	if !isInternal(ip) {
+		return nil
+	}
+	for _, network := range p.networks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return &DeniedError{Target: ip.String(), Reason: "internal address"}
+}
+
+// DialContext 返回校验实际连接IP的拨号函数：先解析域名，只连接通过校验的IP，避免DNS重绑定绕过校验
+func (p *Policy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var denied error
+		for _, ipAddr := range addrs {
+			if err := p.CheckIP(ipAddr.IP); err != nil {
+				denied = err
+				continue
+			}
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr.IP.String(), port))
+			if err != nil {
+				denied = err
+				continue
+			}
+			return conn, nil
+		}
+		if denied == nil {
+			denied = &DeniedError{Target: host, Reason: "no address"}
+		}
+		return nil, denied
+	}
+}
+
+// Client 创建遵守访问策略的HTTP客户端：不使用代理，重定向目标同样校验，timeout为整个请求（含读取响应体）的超时
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           p.DialContext(dialer),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("netguard: too many redirects")
+			}
+			return p.CheckURL(req.URL)
+		},
+	}
+}
+
+// hostAllowed 未配置主机名白名单时允许任意主机名
+func (p *Policy) hostAllowed(host string) bool {
+	if len(p.hosts) == 0 {
+		return true
+	}
+	for _, pattern := range p.hosts {
+		if pattern == host {
+			return true
+		}
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInternal 是否为内网、回环、链路本地（含云厂商元数据地址）、组播或保留地址
+func isInternal(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,98 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestPolicyCheckURL(t *testing.T) {
+	policy, err := NewPolicy(nil, []string{"videos.example.com", "*.cdn.example.com"}, []string{"10.1.0.0/16", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	open, err := NewPolicy([]string{"http", "HTTPS"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	tests := []struct {
+		name       string
+		policy     *Policy
+		rawURL     string
+		wantDenied bool
+	}{
+		{name: "allowed host", policy: policy, rawURL: "https://videos.example.com/a.mp4"},
+		{name: "allowed host with trailing dot", policy: policy, rawURL: "https://VIDEOS.example.com./a.mp4"},
+		{name: "wildcard subdomain", policy: policy, rawURL: "https://eu.cdn.example.com/a.mp4"},
+		{name: "wildcard does not match apex", policy: policy, rawURL: "https://cdn.example.com/a.mp4", wantDenied: true},
+		{name: "suffix is not a subdomain", policy: policy, rawURL: "https://evilvideos.example.com/a.mp4", wantDenied: true},
+		{name: "host not in allowlist", policy: policy, rawURL: "https://example.org/a.mp4", wantDenied: true},
+		{name: "http denied by default", policy: policy, rawURL: "http://videos.example.com/a.mp4", wantDenied: true},
+		{name: "missing host", policy: open, rawURL: "http:///a.mp4", wantDenied: true},
+		{name: "scheme case insensitive", policy: open, rawURL: "HTTPS://example.org/a.mp4"},
+		{name: "public ip literal", policy: open, rawURL: "http://93.184.216.34/a.mp4"},
+		{name: "loopback literal", policy: open, rawURL: "http://127.0.0.1:8080/a.mp4", wantDenied: true},
+		{name: "metadata literal", policy: open, rawURL: "http://169.254.169.254/latest/meta-data/", wantDenied: true},
+		{name: "ipv6 loopback literal", policy: open, rawURL: "http://[::1]/a.mp4", wantDenied: true},
+		{name: "ftp", policy: open, rawURL: "ftp://example.org/a.mp4", wantDenied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.rawURL)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.rawURL, err)
+			}
+			err = tt.policy.CheckURL(u)
+			var denied *DeniedError
+			if tt.wantDenied != errors.As(err, &denied) || (!tt.wantDenied && err != nil) {
+				t.Fatalf("CheckURL(%q) = %v, wantDenied %v", tt.rawURL, err, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckIP(t *testing.T) {
+	policy, err := NewPolicy(nil, nil, []string{"10.1.0.0/16", "192.168.1.5", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	tests := []struct {
+		ip         string
+		wantDenied bool
+	}{
+		{ip: "93.184.216.34"},
+		{ip: "2606:2800:220:1::1"},
+		{ip: "10.1.2.3"},
+		{ip: "192.168.1.5"},
+		{ip: "fd00::1"},
+		{ip: "10.2.0.1", wantDenied: true},
+		{ip: "192.168.1.6", wantDenied: true},
+		{ip: "127.0.0.1", wantDenied: true},
+		{ip: "0.0.0.0", wantDenied: true},
+		{ip: "169.254.169.254", wantDenied: true},
+		{ip: "100.64.0.1", wantDenied: true},
+		{ip: "198.18.0.1", wantDenied: true},
+		{ip: "224.0.0.1", wantDenied: true},
+		{ip: "255.255.255.255", wantDenied: true},
+		{ip: "::1", wantDenied: true},
+		{ip: "::ffff:127.0.0.1", wantDenied: true},
+		{ip: "fe80::1", wantDenied: true},
+		{ip: "64:ff9b::a9fe:a9fe", wantDenied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if err := policy.CheckIP(net.ParseIP(tt.ip)); (err != nil) != tt.wantDenied {
+				t.Fatalf("CheckIP(%s) = %v, wantDenied %v", tt.ip, err, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestNewPolicyInvalidNetwork(t *testing.T) {
+	for _, network := range []string{"not-an-ip", "10.0.0.0/33", ""} {
+		if _, err := NewPolicy(nil, nil, []string{network}); err == nil {
+			t.Fatalf("NewPolicy(%q) should fail", network)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package subtitle
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format 字幕文件格式
+type Format string
+
+const (
+	FormatSRT    Format = "srt"
+	FormatWebVTT Format = "vtt"
+)
+
+// maxLineErrors 单个文件最多收集的错误行数，避免超大错误文件撑爆响应
+const maxLineErrors = 20
+
+// Cue 字幕条目
+type Cue struct {
+	ID       string        // 条目标识（SRT序号或WebVTT identifier）
+	Start    time.Duration // 开始时间
+	End      time.Duration // 结束时间
+	Settings string        // WebVTT cue settings，例如 "align:start"
+	Text     string        // 文本内容，多行以\n分隔
+}
+
+// LineError 带行号的解析错误
+type LineError struct {
+	Line    int
+	Message string
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// SyntaxError 字幕语法错误，包含所有出错行
+type SyntaxError struct {
+	Errors []LineError
+}
+
+func (e *SyntaxError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, le := range e.Errors {
+		msgs = append(msgs, le.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// DetectFormat 根据文件名后缀识别字幕格式
+func DetectFormat(filename string) (Format, bool) {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".srt"):
+		return FormatSRT, true
+	case strings.HasSuffix(lower, ".vtt"):
+		return FormatWebVTT, true
+	}
+	return "", false
+}
+
+// Parse 按指定格式解析字幕
+func Parse(r io.Reader, format Format) ([]Cue, error) {
+	switch format {
+	case FormatSRT:
+		return ParseSRT(r)
+	case FormatWebVTT:
+		return ParseWebVTT(r)
+	}
+	return nil, fmt.Errorf("unsupported subtitle format: %s", format)
+}
+
+// line 带行号的文本行
+type line struct {
+	no   int
+	text string
+}
+
+// readBlocks 读取全部行并按空行切分为块
+func readBlocks(r io.Reader) ([][]line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var blocks [][]line
+	var current []line
+	no := 0
+	for scanner.Scan() {
+		no++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if no == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line{no: no, text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks, nil
+}
+
+// errorCollector 收集行错误
+type errorCollector struct {
+	errs []LineError
+}
+
+func (c *errorCollector) add(no int, format string, args ...interface{}) {
+	if len(c.errs) < maxLineErrors {
+		c.errs = append(c.errs, LineError{Line: no, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (c *errorCollector) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return &SyntaxError{Errors: c.errs}
+}
+
+// ParseSRT 解析SRT字幕
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	blocks, err := readBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cues []Cue
+	collector := &errorCollector{}
+	for _, block := range blocks {
+		idx := 0
+		id := ""
+		// 序号行可选，但如果存在必须为数字
+		if !strings.Contains(block[0].text, "-->") {
+			id = strings.TrimSpace(block[0].text)
+			if _, err := strconv.Atoi(id); err != nil {
+				collector.add(block[0].no, "invalid cue index %q", id)
+				continue
+			}
+			idx++
+		}
+		if idx >= len(block) {
+			collector.add(block[0].no, "missing timing line")
+			continue
+		}
+
+		timing := block[idx]
+		start, end, _, err := parseTimingLine(timing.text, true)
+		if err != nil {
+			collector.add(timing.no, "%s", err.Error())
+			continue
+		}
+
+		texts := make([]string, 0, len(block)-idx-1)
+		for _, l := range block[idx+1:] {
+			texts = append(texts, l.text)
+		}
+		cues = append(cues, Cue{ID: id, Start: start, End: end, Text: strings.Join(texts, "\n")})
+	}
+
+	if err := collector.err(); err != nil {
+		return nil, err
+	}
+	if len(cues) == 0 {
+		return nil, &SyntaxError{Errors: []LineError{{Line: 1, Message: "no cues found"}}}
+	}
+	return cues, nil
+}
+
+// ParseWebVTT 解析WebVTT字幕
+func ParseWebVTT(r io.Reader) ([]Cue, error) {
+	blocks, err := readBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, &SyntaxError{Errors: []LineError{{Line: 1, Message: "missing WEBVTT header"}}}
+	}
+
+	header := blocks[0][0]
+	if header.no != 1 || !isWebVTTSignature(header.text) {
+		return nil, &SyntaxError{Errors: []LineError{{Line: header.no, Message: "missing WEBVTT header"}}}
+	}
+
+	var cues []Cue
+	collector := &errorCollector{}
+	for _, block := range blocks[1:] {
+		first := block[0].text
+		if strings.HasPrefix(first, "NOTE") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+
+		idx := 0
+		id := ""
+		if !strings.Contains(first, "-->") {
+			id = first
+			idx++
+		}
+		if idx >= len(block) {
+			collector.add(block[0].no, "missing timing line")
+			continue
+		}
+
+		timing := block[idx]
+		start, end, settings, err := parseTimingLine(timing.text, false)
+		if err != nil {
+			collector.add(timing.no, "%s", err.Error())
+			continue
+		}
+
+		texts := make([]string, 0, len(block)-idx-1)
+		for _, l := range block[idx+1:] {
+			if strings.Contains(l.text, "-->") {
+				collector.add(l.no, "cue text must not contain \"-->\"")
+				continue
+			}
+			texts = append(texts, l.text)
+		}
+		cues = append(cues, Cue{ID: id, Start: start, End: end, Settings: settings, Text: strings.Join(texts, "\n")})
+	}
+
+	if err := collector.err(); err != nil {
+		return nil, err
+	}
+	if len(cues) == 0 {
+		return nil, &SyntaxError{Errors: []LineError{{Line: 1, Message: "no cues found"}}}
+	}
+	return cues, nil
+}
+
+func isWebVTTSignature(text string) bool {
+	if !strings.HasPrefix(text, "WEBVTT") {
+		return false
+	}
+	rest := text[len("WEBVTT"):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// parseTimingLine 解析 "start --> end [settings]" 时间行
+func parseTimingLine(text string, srt bool) (time.Duration, time.Duration, string, error) {
+	parts := strings.SplitN(text, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, "", fmt.Errorf("malformed timing line %q", text)
+	}
+
+	startStr := strings.TrimSpace(parts[0])
+	rest := strings.Fields(parts[1])
+	if len(rest) == 0 {
+		return 0, 0, "", fmt.Errorf("malformed timing line %q: missing end time", text)
+	}
+
+	start, err := parseTimestamp(startStr, srt)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid start time %q: %v", startStr, err)
+	}
+	end, err := parseTimestamp(rest[0], srt)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid end time %q: %v", rest[0], err)
+	}
+	if end <= start {
+		return 0, 0, "", fmt.Errorf("end time %s is not after start time %s", rest[0], startStr)
+	}
+
+	settings := ""
+	// SRT 的坐标扩展（X1: Y1: ...）不属于 WebVTT settings，直接丢弃
+	if !srt {
+		settings = strings.Join(rest[1:], " ")
+	}
+	return start, end, settings, nil
+}
+
+// parseTimestamp 解析时间戳：SRT 为 HH:MM:SS,mmm，WebVTT 为 [HH:]MM:SS.mmm
+func parseTimestamp(s string, srt bool) (time.Duration, error) {
+	sep := "."
+	if srt {
+		sep = ","
+		// 兼容部分工具导出的点号毫秒分隔符
+		if !strings.Contains(s, ",") && strings.Contains(s, ".") {
+			sep = "."
+		}
+	}
+
+	mainPart, msPart, ok := strings.Cut(s, sep)
+	if !ok || len(msPart) != 3 {
+		return 0, fmt.Errorf("milliseconds must be 3 digits separated by %q", sep)
+	}
+
+	fields := strings.Split(mainPart, ":")
+	if srt && len(fields) != 3 {
+		return 0, fmt.Errorf("expected HH:MM:SS")
+	}
+	if len(fields) != 2 && len(fields) != 3 {
+		return 0, fmt.Errorf("expected [HH:]MM:SS")
+	}
+
+	var hours, minutes, seconds int
+	var err error
+	if len(fields) == 3 {
+		if hours, err = parseDigits(fields[0], 2, false); err != nil {
+			return 0, err
+		}
+		fields = fields[1:]
+	}
+	if minutes, err = parseDigits(fields[0], 2, true); err != nil {
+		return 0, err
+	}
+	if seconds, err = parseDigits(fields[1], 2, true); err != nil {
+		return 0, err
+	}
+	if minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("minutes and seconds must be less than 60")
+	}
+	ms, err := parseDigits(msPart, 3, true)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(ms)*time.Millisecond, nil
+}
+
+// parseDigits 解析纯数字字段，exact 为 true 时位数必须严格相等，否则为最少位数
+func parseDigits(s string, width int, exact bool) (int, error) {
+	if len(s) < width || (exact && len(s) != width) {
+		return 0, fmt.Errorf("field %q must have %d digits", s, width)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("field %q must be numeric", s)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// FormatTimestamp 格式化为 WebVTT 时间戳 HH:MM:SS.mmm
+func FormatTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	hours := ms / 3600000
+	ms -= hours * 3600000
+	minutes := ms / 60000
+	ms -= minutes * 60000
+	seconds := ms / 1000
+	ms -= seconds * 1000
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, ms)
+}
+
+// WriteWebVTT 将字幕条目输出为 WebVTT 格式
+func WriteWebVTT(w io.Writer, cues []Cue) error {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		buf.WriteString("\n")
+		if id := sanitizeLine(cue.ID); id != "" {
+			buf.WriteString(id)
+			buf.WriteString("\n")
+		}
+		buf.WriteString(FormatTimestamp(cue.Start))
+		buf.WriteString(" --> ")
+		buf.WriteString(FormatTimestamp(cue.End))
+		if cue.Settings != "" {
+			buf.WriteString(" ")
+			buf.WriteString(cue.Settings)
+		}
+		buf.WriteString("\n")
+		for _, l := range strings.Split(cue.Text, "\n") {
+			// 空行会提前结束 cue，"-->" 会被误认为时间行
+			l = sanitizeLine(l)
+			if l == "" {
+				continue
+			}
+			buf.WriteString(l)
+			buf.WriteString("\n")
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// sanitizeLine 清理 WebVTT 中不允许出现的内容
+func sanitizeLine(s string) string {
+	s = strings.TrimRight(s, "\r")
+	s = strings.ReplaceAll(s, "-->", "->")
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	return s
+}
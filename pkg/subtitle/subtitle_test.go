@@ -0,0 +1,220 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestParseValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		want    []Cue
+	}{
+		{
+			name:    "srt",
+			format:  FormatSRT,
+			content: "1\n00:00:01,000 --> 00:00:02,500\nHello\n\n2\n00:01:00,250 --> 01:00:00,000\nSecond\n",
+			want: []Cue{
+				{ID: "1", Start: ms(1000), End: ms(2500), Text: "Hello"},
+				{ID: "2", Start: ms(60250), End: time.Hour, Text: "Second"},
+			},
+		},
+		{
+			name:   "srt with bom, crlf and multi-line cues",
+			format: FormatSRT,
+			content: "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nfirst line\r\nsecond line\r\n\r\n\r\n" +
+				"2\r\n00:00:03,000 --> 00:00:04,000\r\nlast\r\n",
+			want: []Cue{
+				{ID: "1", Start: ms(1000), End: ms(2000), Text: "first line\nsecond line"},
+				{ID: "2", Start: ms(3000), End: ms(4000), Text: "last"},
+			},
+		},
+		{
+			name:    "srt without index, dot separator and coordinates",
+			format:  FormatSRT,
+			content: "00:00:01.000 --> 00:00:02.000 X1:10 X2:20 Y1:30 Y2:40\nHello\n",
+			want:    []Cue{{Start: ms(1000), End: ms(2000), Text: "Hello"}},
+		},
+		{
+			name:   "webvtt",
+			format: FormatWebVTT,
+			content: "WEBVTT\n\n00:01.000 --> 00:02.500\nHello\n\n" +
+				"intro\n01:00:00.000 --> 01:00:01.000 align:start line:0\nWith settings\n",
+			want: []Cue{
+				{Start: ms(1000), End: ms(2500), Text: "Hello"},
+				{ID: "intro", Start: time.Hour, End: time.Hour + time.Second, Settings: "align:start line:0", Text: "With settings"},
+			},
+		},
+		{
+			name:   "webvtt with header metadata, note and style blocks",
+			format: FormatWebVTT,
+			content: "\ufeffWEBVTT - Episode 1\r\nKind: captions\r\nLanguage: en\r\n\r\n" +
+				"NOTE written by hand\r\nspans two lines\r\n\r\n" +
+				"STYLE\r\n::cue { color: yellow }\r\n\r\n" +
+				"1\r\n00:00:01.000 --> 00:00:02.000\r\n<v Alice>Hi\r\n<v Bob>Hello\r\n\r\n" +
+				"NOTE between cues\r\n\r\n" +
+				"00:00:05.000 --> 00:00:06.000\r\nBye\r\n",
+			want: []Cue{
+				{ID: "1", Start: ms(1000), End: ms(2000), Text: "<v Alice>Hi\n<v Bob>Hello"},
+				{Start: ms(5000), End: ms(6000), Text: "Bye"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues, err := Parse(strings.NewReader(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(cues, tt.want) {
+				t.Fatalf("Parse =\n%+v\nwant\n%+v", cues, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		content   string
+		wantLines []int
+		wantMsg   string
+	}{
+		{
+			name:      "srt milliseconds too short",
+			format:    FormatSRT,
+			content:   "1\n00:00:01,00 --> 00:00:02,000\nHello\n",
+			wantLines: []int{2},
+			wantMsg:   "invalid start time",
+		},
+		{
+			name:      "srt missing hours",
+			format:    FormatSRT,
+			content:   "1\n00:01,000 --> 00:02,000\nHello\n",
+			wantLines: []int{2},
+			wantMsg:   "expected HH:MM:SS",
+		},
+		{
+			name:      "srt end before start",
+			format:    FormatSRT,
+			content:   "1\n00:00:01,000 --> 00:00:02,000\nok\n\n2\n00:00:05,000 --> 00:00:04,000\nbackwards\n",
+			wantLines: []int{6},
+			wantMsg:   "is not after start time",
+		},
+		{
+			name:      "srt invalid index",
+			format:    FormatSRT,
+			content:   "one\n00:00:01,000 --> 00:00:02,000\nHello\n",
+			wantLines: []int{1},
+			wantMsg:   "invalid cue index",
+		},
+		{
+			name:      "srt every bad line reported",
+			format:    FormatSRT,
+			content:   "1\n00:00:01,000 --> 00:00:0x,000\na\n\n2\n00:00:02,000 --> 00:00:03,000\nb\n\n3\n00:00:09,000 --> 00:00:09,000\nc\n",
+			wantLines: []int{2, 10},
+		},
+		{
+			name:      "srt empty",
+			format:    FormatSRT,
+			content:   "\n\n",
+			wantLines: []int{1},
+			wantMsg:   "no cues found",
+		},
+		{
+			name:      "webvtt missing header",
+			format:    FormatWebVTT,
+			content:   "00:01.000 --> 00:02.000\nHello\n",
+			wantLines: []int{1},
+			wantMsg:   "missing WEBVTT header",
+		},
+		{
+			name:      "webvtt header not on the first line",
+			format:    FormatWebVTT,
+			content:   "\nWEBVTT\n\n00:01.000 --> 00:02.000\nHello\n",
+			wantLines: []int{2},
+			wantMsg:   "missing WEBVTT header",
+		},
+		{
+			name:      "webvtt seconds out of range",
+			format:    FormatWebVTT,
+			content:   "WEBVTT\n\n00:01.000 --> 00:02.000\nok\n\n00:61.000 --> 01:02.000\nbad\n",
+			wantLines: []int{6},
+			wantMsg:   "less than 60",
+		},
+		{
+			name:      "webvtt end equals start",
+			format:    FormatWebVTT,
+			content:   "WEBVTT\n\nid\n00:00:03.000 --> 00:00:03.000\nzero length\n",
+			wantLines: []int{4},
+			wantMsg:   "is not after start time",
+		},
+		{
+			name:      "webvtt missing end time",
+			format:    FormatWebVTT,
+			content:   "WEBVTT\n\n00:01.000 -->\nHello\n",
+			wantLines: []int{3},
+			wantMsg:   "missing end time",
+		},
+		{
+			name:      "webvtt arrow in cue text",
+			format:    FormatWebVTT,
+			content:   "WEBVTT\n\n00:01.000 --> 00:02.000\nfirst\nnot --> timing\n",
+			wantLines: []int{5},
+			wantMsg:   "must not contain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content), tt.format)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse error = %v, want *SyntaxError", err)
+			}
+			var lines []int
+			for _, lineErr := range syntaxErr.Errors {
+				lines = append(lines, lineErr.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Fatalf("error lines = %v, want %v (%v)", lines, tt.wantLines, err)
+			}
+			if !strings.Contains(err.Error(), "line ") || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("error = %q, want line number and %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWriteWebVTT(t *testing.T) {
+	cues := []Cue{
+		{ID: "1", Start: ms(1000), End: ms(2500), Settings: "align:start", Text: "Hello\n\nworld"},
+		{ID: "2 --> 3", Start: time.Hour + ms(1), End: time.Hour + ms(2), Text: "a --> b"},
+	}
+	var buf bytes.Buffer
+	if err := WriteWebVTT(&buf, cues); err != nil {
+		t.Fatalf("WriteWebVTT: %v", err)
+	}
+	want := "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500 align:start\nHello\nworld\n\n" +
+		"2 -> 3\n01:00:00.001 --> 01:00:00.002\na -> b\n"
+	if buf.String() != want {
+		t.Fatalf("WriteWebVTT =\n%s\nwant\n%s", buf.String(), want)
+	}
+	parsed, err := ParseWebVTT(&buf)
+	if err != nil {
+		t.Fatalf("ParseWebVTT: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Text != "Hello\nworld" || parsed[1].Text != "a -> b" {
+		t.Fatalf("roundtrip = %+v", parsed)
+	}
+}